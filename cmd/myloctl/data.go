@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"fiber-gorm-api/internal/exports"
+	"fiber-gorm-api/internal/imports"
+	"fiber-gorm-api/internal/models"
+	"fiber-gorm-api/internal/repository"
+	"fiber-gorm-api/internal/storage"
+)
+
+// importSubscribers queues the file like POST /admin/imports and runs the import right away
+func importSubscribers(ctx context.Context, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	path := fs.String("file", "", "CSV or JSON file of subscribers (required)")
+	format := fs.String("format", "", "csv or json (default: from the file extension)")
+	org := fs.Uint("org", uint(models.DefaultOrgID), "organization ID")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if *path == "" {
+		return errors.New("missing -file")
+	}
+	if *format == "" {
+		*format = imports.FormatOf(*path)
+	}
+	if !slices.Contains(imports.Formats, *format) {
+		return errors.New("unknown format, expected csv or json")
+	}
+	f, err := os.Open(*path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	conn := connect()
+	if _, err := findOrganization(ctx, conn, *org); err != nil {
+		return err
+	}
+	job := models.ImportJob{OrgID: *org, Format: *format, FileName: filepath.Base(*path), RequestedBy: actor}
+	if err := imports.Enqueue(ctx, conn, &job, f, info.Size()); err != nil {
+		return err
+	}
+	done, err := imports.RunJob(conn, job.ID)
+	if err != nil {
+		return fmt.Errorf("import %d failed: %w", job.ID, err)
+	}
+	fmt.Fprintf(out, "Import %d: %d rows, %d created, %d updated, %d failed\n",
+		done.ID, done.TotalRows, done.CreatedRows, done.UpdatedRows, done.FailedRows)
+	if done.ErrorReport != "" {
+		url, expires, err := imports.ErrorReportURL(ctx, done, time.Now())
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Failed rows: %s (until %s)\n", url, expires.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// exportSubscribers queues an export like POST /admin/exports, runs it right away and prints
+// its download link, or copies the file to -out
+func exportSubscribers(ctx context.Context, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := fs.String("format", models.ExportFormatCSV, "csv or json")
+	org := fs.Uint("org", uint(models.DefaultOrgID), "organization ID")
+	statuses := fs.String("status", "", "comma separated statuses to export (default: all)")
+	verified := fs.String("verified", "", "true or false: only subscribers with (without) a verified email")
+	subscriberType := fs.String("subscriber-type", "", "only subscribers with this subscriber_type")
+	frequency := fs.String("frequency", "", "only subscribers of a subscriber_type with this frequency")
+	channel := fs.String("channel", "", "only subscribers of a subscriber_type with this channel")
+	dest := fs.String("out", "", "copy the file there instead of printing its download link")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if !slices.Contains(exports.Formats, *format) {
+		return errors.New("unknown format, expected csv or json")
+	}
+	filter := repository.SubscriberFilter{
+		Verified:       *verified,
+		SubscriberType: *subscriberType,
+		Frequency:      *frequency,
+		Channel:        *channel,
+	}
+	if *statuses != "" {
+		filter.Statuses = strings.Split(*statuses, ",")
+	}
+	if _, err := filter.Scope(); err != nil {
+		return err
+	}
+
+	conn := connect()
+	if _, err := findOrganization(ctx, conn, *org); err != nil {
+		return err
+	}
+	job := models.ExportJob{
+		OrgID:       *org,
+		Format:      *format,
+		Filters:     exports.FilterMap(filter),
+		Status:      models.ExportPending,
+		RequestedBy: actor,
+	}
+	if err := conn.WithContext(ctx).Create(&job).Error; err != nil {
+		return err
+	}
+	done, err := exports.RunJob(conn, job.ID)
+	if err != nil {
+		return fmt.Errorf("export %d failed: %w", job.ID, err)
+	}
+
+	if *dest != "" {
+		if err := copyFile(ctx, done.File, *dest); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Export %d: %d subscribers written to %s\n", done.ID, done.RowCount, *dest)
+		return nil
+	}
+	url, expires, err := exports.DownloadURL(ctx, done, time.Now())
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Export %d: %d subscribers\nDownload: %s (until %s)\n", done.ID, done.RowCount, url, expires.Format(time.RFC3339))
+	return nil
+}
+
+// copyFile copies the stored file key to the local path dest
+func copyFile(ctx context.Context, key, dest string) error {
+	r, err := storage.Default.Open(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	f, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
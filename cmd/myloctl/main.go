@@ -0,0 +1,103 @@
+// Command myloctl runs common operations against the database and Redis of a deployment: it
+// reads the same environment as the API (DB_*, REDIS_*, STORAGE_*, ...) and goes through the
+// same internal packages, so an admin created or a CSV imported here behaves exactly as
+// through the API.
+//
+//	myloctl <command> [flags]
+//
+// Run myloctl help for the commands, myloctl <command> -h for their flags.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"fiber-gorm-api/internal/db"
+
+	"gorm.io/gorm"
+)
+
+// command is a myloctl subcommand
+type command struct {
+	summary string
+	run     func(ctx context.Context, args []string, out io.Writer) error
+}
+
+var commands = map[string]command{
+	"migrate":        {"create or update the database schema", migrate},
+	"create-admin":   {"make an email an admin of an organization", createAdmin},
+	"issue-api-key":  {"create an API key and print it", issueAPIKey},
+	"import":         {"import subscribers from a CSV or JSON file", importSubscribers},
+	"export":         {"export subscribers and print the download link", exportSubscribers},
+	"flush-sessions": {"sign out everyone, or one admin", flushSessions},
+	"seed":           {"fill a development database with fake data", seedData},
+}
+
+// connect opens the admin connection of the API; tests swap it for a SQLite database
+var connect = func() *gorm.DB { return db.Connect(true) }
+
+// errUsage is returned for bad flags, whose problem the flag set already printed
+var errUsage = errors.New("usage")
+
+func main() {
+	err := run(context.Background(), os.Args[1:], os.Stdout, os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		if !errors.Is(err, errUsage) {
+			fmt.Fprintln(os.Stderr, "myloctl:", err)
+		}
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string, out, errOut io.Writer) error {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		usage(out)
+		return nil
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		usage(errOut)
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+	return cmd.run(ctx, args[1:], out)
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: myloctl <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-16s %s\n", name, commands[name].summary)
+	}
+}
+
+// parse reads the flags of a command, writing its usage and problems to stderr. -h returns
+// flag.ErrHelp, other problems errUsage.
+func parse(fs *flag.FlagSet, args []string) error {
+	fs.SetOutput(os.Stderr)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return errUsage
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "unexpected arguments: %v\n", fs.Args())
+		fs.Usage()
+		return errUsage
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"fiber-gorm-api/internal/db"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
+	redisclient "fiber-gorm-api/internal/redis"
+	"fiber-gorm-api/internal/session"
+	"fiber-gorm-api/internal/storage"
+
+	"gorm.io/gorm"
+)
+
+func setup(t *testing.T) *gorm.DB {
+	t.Helper()
+	conn, err := db.OpenSQLite(filepath.Join(t.TempDir(), "myloctl.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	originalConnect, originalStore := connect, storage.Default
+	t.Cleanup(func() { connect, storage.Default = originalConnect, originalStore })
+	connect = func() *gorm.DB { return conn }
+	storage.Default = storage.NewLocal(t.TempDir())
+	return conn
+}
+
+// myloctl runs a command and returns what it printed
+func myloctl(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	var out bytes.Buffer
+	err := run(context.Background(), args, &out, &out)
+	return out.String(), err
+}
+
+func TestCommands(t *testing.T) {
+	conn := setup(t)
+
+	t.Run("Unknown command", func(t *testing.T) {
+		if _, err := myloctl(t, "launch-rockets"); err == nil {
+			t.Error("Expected an error")
+		}
+		if out, err := myloctl(t, "help"); err != nil || !strings.Contains(out, "create-admin") {
+			t.Errorf("Expected the commands listed, got %q (%v)", out, err)
+		}
+	})
+
+	t.Run("create-admin", func(t *testing.T) {
+		if _, err := myloctl(t, "create-admin", "-email", "ops@example.com", "-role", "editor"); err != nil {
+			t.Fatal(err)
+		}
+		var admin models.AdminUser
+		if err := conn.Where("email = ?", "ops@example.com").First(&admin).Error; err != nil || admin.Role != models.RoleEditor {
+			t.Errorf("Expected an editor, got %+v (%v)", admin, err)
+		}
+		if _, err := myloctl(t, "create-admin", "-email", "ops@example.com"); err == nil {
+			t.Error("Expected an existing admin refused")
+		}
+		if _, err := myloctl(t, "create-admin", "-email", "new@example.com", "-org", "42"); err == nil {
+			t.Error("Expected an unknown organization refused")
+		}
+	})
+
+	t.Run("issue-api-key", func(t *testing.T) {
+		if _, err := myloctl(t, "issue-api-key", "-name", "backup", "-scopes", "read,launch"); err == nil {
+			t.Error("Expected an unknown scope refused")
+		}
+		out, err := myloctl(t, "issue-api-key", "-name", "backup", "-scopes", "read,pii", "-expires-in", "24h")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var key models.ApiKey
+		conn.Where("key_hash = ?", middleware.HashAPIKey(strings.TrimSpace(out))).First(&key)
+		if key.Name != "backup" || key.Scopes != "read,pii" || key.ExpiresAt == nil || key.CreatedBy != actor {
+			t.Errorf("Unexpected key %+v", key)
+		}
+	})
+
+	t.Run("import and export", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "subscribers.csv")
+		os.WriteFile(file, []byte("email,name\nada@example.com,Ada\nbroken,Nobody\n"), 0o600)
+		out, err := myloctl(t, "import", "-file", file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(out, "2 rows, 1 created, 0 updated, 1 failed") || !strings.Contains(out, "Failed rows: ") {
+			t.Errorf("Unexpected output %q", out)
+		}
+
+		dest := filepath.Join(t.TempDir(), "export.csv")
+		if _, err := myloctl(t, "export", "-out", dest); err != nil {
+			t.Fatal(err)
+		}
+		exported, _ := os.ReadFile(dest)
+		if !strings.Contains(string(exported), "ada@example.com") {
+			t.Errorf("Expected the imported subscriber exported, got %q", exported)
+		}
+		if _, err := myloctl(t, "export", "-status", "sleeping"); err == nil {
+			t.Error("Expected an unknown status refused")
+		}
+	})
+
+	t.Run("seed", func(t *testing.T) {
+		t.Setenv("API_ENV", "production")
+		if _, err := myloctl(t, "seed", "-subscribers", "5"); err == nil {
+			t.Error("Expected seeding refused in production")
+		}
+		t.Setenv("API_ENV", "development")
+		out, err := myloctl(t, "seed", "-subscribers", "5", "-admins", "1")
+		if err != nil || !strings.Contains(out, "5 subscribers") {
+			t.Errorf("Unexpected output %q (%v)", out, err)
+		}
+	})
+
+	t.Run("flush-sessions", func(t *testing.T) {
+		t.Setenv("REDIS_HOST", "")
+		redisclient.InitRedis("session")
+		a, _ := session.Create(redisclient.Ctx, "ada@example.com", models.DefaultOrgID, "", "")
+		b, _ := session.Create(redisclient.Ctx, "grace@example.com", models.DefaultOrgID, "", "")
+
+		if _, err := myloctl(t, "flush-sessions", "-email", "ada@example.com"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := session.Get(redisclient.Ctx, a.ID); err == nil {
+			t.Error("Expected Ada signed out")
+		}
+		if _, err := session.Get(redisclient.Ctx, b.ID); err != nil {
+			t.Error("Expected Grace still signed in")
+		}
+		if _, err := myloctl(t, "flush-sessions"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := session.Get(redisclient.Ctx, b.ID); err == nil {
+			t.Error("Expected everyone signed out")
+		}
+	})
+}
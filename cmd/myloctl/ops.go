@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+
+	"fiber-gorm-api/internal/db"
+	redisclient "fiber-gorm-api/internal/redis"
+	"fiber-gorm-api/internal/session"
+)
+
+// migrate applies migrations/migration.sql with psql, as docker-compose does on start: the
+// script is idempotent from the second run on, statements already applied report an error and
+// the rest goes on. SQLite creates its schema when it connects.
+func migrate(ctx context.Context, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	file := fs.String("file", "migrations/migration.sql", "SQL script to apply")
+	user := fs.String("user", "postgres", "Postgres superuser applying it, whose password is read from PGPASSWORD")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+
+	if os.Getenv("DB_DRIVER") == db.DriverSQLite {
+		connect()
+		fmt.Fprintln(out, "SQLite schema is up to date")
+		return nil
+	}
+	psqlArgs := []string{"-U", *user, "-d", "postgres", "-f", *file}
+	if host := os.Getenv("DB_HOST"); host != "" {
+		psqlArgs = append(psqlArgs, "-h", host)
+	}
+	if port := os.Getenv("DB_PORT"); port != "" {
+		psqlArgs = append(psqlArgs, "-p", port)
+	}
+	cmd := exec.CommandContext(ctx, "psql", psqlArgs...)
+	cmd.Stdout, cmd.Stderr = out, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("psql: %w", err)
+	}
+	return nil
+}
+
+// flushSessions deletes sessions from the session store: those of one admin, or everyone's.
+// Signed out admins sign in again; trusted devices and API keys are left alone. Sessions kept in
+// the memory of the API (SESSION_STORE=memory) are out of its reach.
+func flushSessions(ctx context.Context, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("flush-sessions", flag.ContinueOnError)
+	email := fs.String("email", "", "only sign out this admin")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+
+	redisclient.InitRedis("session")
+	session.Use(session.NewFromEnv(connect))
+	if *email != "" {
+		if err := session.RevokeAllForEmail(ctx, *email); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Signed out %s\n", *email)
+		return nil
+	}
+	n, err := session.RevokeAll(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Deleted %d sessions\n", n)
+	return nil
+}
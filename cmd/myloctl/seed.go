@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"fiber-gorm-api/internal/models"
+	"fiber-gorm-api/internal/seed"
+)
+
+// seedEnvironments are the API_ENV values seed runs in without -force
+var seedEnvironments = map[string]bool{"development": true, "test": true}
+
+// seedData fills a development database with fake admins and subscribers
+func seedData(ctx context.Context, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("seed", flag.ContinueOnError)
+	subscribers := fs.Int("subscribers", 500, "how many subscribers to add")
+	admins := fs.Int("admins", 3, "how many admins to add (admin, editor and viewer in turn)")
+	org := fs.Uint("org", uint(models.DefaultOrgID), "organization ID")
+	randomSeed := fs.Int64("seed", 0, "random seed, to get the same data again (random when 0)")
+	force := fs.Bool("force", false, "seed even though API_ENV isn't development or test")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if *subscribers < 0 || *admins < 0 {
+		return errors.New("-subscribers and -admins can't be negative")
+	}
+	if env := os.Getenv("API_ENV"); !seedEnvironments[env] && !*force {
+		return fmt.Errorf("refusing to seed with API_ENV=%q, use -force if this really is a development database", env)
+	}
+
+	conn := connect()
+	if _, err := findOrganization(ctx, conn, *org); err != nil {
+		return err
+	}
+	result, err := seed.Run(ctx, conn, seed.Options{
+		OrgID:       *org,
+		Subscribers: *subscribers,
+		Admins:      *admins,
+		Seed:        *randomSeed,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Seeded organization %d: %d subscribers, %d notes, %d admins\n", *org, result.Subscribers, result.Notes, result.Admins)
+	return nil
+}
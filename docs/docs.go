@@ -22,36 +22,62 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/admin/subscribers": {
+        "/.well-known/jwks.json": {
             "get": {
-                "description": "Returns a list of all subscribers, including their subscriber_types",
+                "description": "Public keys verifying admin JWTs (RS256/EdDSA), by kid, including retired keys whose tokens are still valid. Empty with HS256.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "subscribers"
+                    "signin"
                 ],
-                "summary": "Get all subscribers",
+                "summary": "JSON Web Key Set",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.JWKSResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/api-keys": {
+            "get": {
+                "description": "Lists all API keys, including revoked and expired ones. Secrets are never returned.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "api-keys"
+                ],
+                "summary": "List API keys",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.Subscriber"
+                                "$ref": "#/definitions/dto.ApiKeyResponse"
                             }
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     }
                 }
             },
             "post": {
-                "description": "Creates a new subscriber record, optionally with multiple subscriber_types. Validates email \u0026 name.",
+                "description": "Creates a key for machine-to-machine access to /admin via the X-API-Key header. Keys without the pii (or admin) scope see masked emails. The key is only returned once.",
                 "consumes": [
                     "application/json"
                 ],
@@ -59,17 +85,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "subscribers"
+                    "api-keys"
                 ],
-                "summary": "Create a new subscriber",
+                "summary": "Create an API key",
                 "parameters": [
                     {
-                        "description": "Subscriber info (with subscriber_types optional)",
-                        "name": "subscriber",
+                        "description": "Name, scopes (read, write, pii, admin) and optional expiry",
+                        "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.Subscriber"
+                            "$ref": "#/definitions/dto.CreateApiKeyRequest"
                         }
                     }
                 ],
@@ -77,38 +103,180 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.Subscriber"
+                            "$ref": "#/definitions/dto.CreatedApiKeyResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/api-keys/{id}": {
+            "delete": {
+                "description": "Revokes an API key immediately. The record is kept for auditing.",
+                "tags": [
+                    "api-keys"
+                ],
+                "summary": "Revoke an API key",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "API key ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
                         "schema": {
                             "type": "string"
                         }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/admin/subscribers/{id}": {
+        "/admin/campaigns": {
             "get": {
-                "description": "Gets subscriber by id, including all subscriber_types",
+                "description": "Lists the campaigns of the organization, newest first, optionally those in one status.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "subscribers"
+                    "campaigns"
                 ],
-                "summary": "Get a single subscriber",
+                "summary": "List campaigns",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "draft, scheduled, sending, testing, paused, sent or cancelled",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.CampaignResponse"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Creates an email campaign to the active members of segment_id (see /admin/segments), or of filter without one; an empty filter targets every active subscriber. Members are computed when each run starts.\nsend_at schedules it once, as an RFC 3339 time or a local 2025-07-01T09:00 read in timezone (an IANA name, UTC by default). recurrence, a cron expression read in timezone like 0 9 * * MON (or @weekly), sends it again at each occurrence, from send_at or else the next one; runs must be at least an hour apart.\nWithout either the campaign is a draft, until updated with one. Invalid schedules are rejected with code invalid_schedule.\nsubject_b and test_percent (2 to 50) A/B test the subject of each run: that share of the audience gets subject or subject_b, then test_hours later (4 by default, 72 at most) the one opened at the highest rate goes to the rest. Invalid tests are rejected with code invalid_ab_test; results are at /admin/campaigns/{id}/stats.\nevent_id announces a local event of the organization (see /admin/local-events, code unknown_event): each email carries its calendar invite, as of when it's sent.\nThe scheduler's dispatcher queues the emails of due runs; a Redis lock per campaign and a record of each recipient of a run keep anyone from being sent a run twice.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "campaigns"
+                ],
+                "summary": "Create a campaign",
+                "parameters": [
+                    {
+                        "description": "Campaign",
+                        "name": "campaign",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CampaignRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CampaignResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.FilterErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dto.InvalidSubscriberTypesResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/campaigns/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "campaigns"
+                ],
+                "summary": "Get a campaign",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Subscriber ID",
+                        "description": "Campaign ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -118,25 +286,31 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Subscriber"
+                            "$ref": "#/definitions/dto.CampaignResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     }
                 }
             },
             "put": {
-                "description": "Updates subscriber by id. If subscriber_types are provided, it overwrites them. Validates email \u0026 name.",
+                "description": "Replaces the content, audience and schedule of a draft, scheduled or paused campaign, validated as on create (409 with code invalid_campaign_status otherwise). A paused campaign stays paused; the others are scheduled, or drafts without a schedule.",
                 "consumes": [
                     "application/json"
                 ],
@@ -144,24 +318,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "subscribers"
+                    "campaigns"
                 ],
-                "summary": "Update a subscriber",
+                "summary": "Update a campaign",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Subscriber ID",
+                        "description": "Campaign ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Subscriber info (subscriber_types optional)",
-                        "name": "subscriber",
+                        "description": "Campaign",
+                        "name": "campaign",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.Subscriber"
+                            "$ref": "#/definitions/dto.CampaignRequest"
                         }
                     }
                 ],
@@ -169,39 +343,51 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Subscriber"
+                            "$ref": "#/definitions/dto.CampaignResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/dto.FilterErrorResponse"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dto.InvalidSubscriberTypesResponse"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     }
                 }
             },
             "delete": {
-                "description": "Deletes subscriber by id (and associated subscriber_types).",
+                "description": "Deletes a campaign, its record of recipients and their opens and clicks, unless it is sending: pause or cancel it first (409). Emails of it still queued are dropped. Its short links keep working, no longer attributed to it.",
                 "tags": [
-                    "subscribers"
+                    "campaigns"
                 ],
-                "summary": "Delete a subscriber",
+                "summary": "Delete a campaign",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Subscriber ID",
+                        "description": "Campaign ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -217,210 +403,10025 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/signin/request": {
+        "/admin/campaigns/{id}/cancel": {
             "post": {
-                "description": "Takes an email, generates a 6-digit code, stores in Redis, sends via SendGrid",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Stops a campaign for good (409 once sent or cancelled). Emails of it still queued are dropped.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "signin"
+                    "campaigns"
                 ],
-                "summary": "Request Sign In",
+                "summary": "Cancel a campaign",
                 "parameters": [
                     {
-                        "description": "e.g. { \\",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
+                        "type": "integer",
+                        "description": "Campaign ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Code sent",
+                        "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/dto.CampaignResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/signin/verify": {
+        "/admin/campaigns/{id}/pause": {
             "post": {
-                "description": "Takes an email and 6-digit code. If valid, generate JWT \u0026 store session in redis",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Stops a scheduled, sending or testing campaign (409 otherwise). A run being queued stops after its current batch, an A/B test before sending its winner; emails already queued still go out.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "signin"
+                    "campaigns"
                 ],
-                "summary": "Verify Sign In Code",
+                "summary": "Pause a campaign",
                 "parameters": [
                     {
-                        "description": "e.g. { \\",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
+                        "type": "integer",
+                        "description": "Campaign ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "JWT returned",
+                        "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/dto.CampaignResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/signup/subscribers": {
+        "/admin/campaigns/{id}/resume": {
             "post": {
-                "description": "Creates a new subscriber record, optionally with multiple subscriber_types. Validates email \u0026 name.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Schedules a paused campaign again (409 otherwise). A run it was paused during is picked up where it stopped, without sending anyone the run twice; a recurring campaign that missed occurrences waits for the next one.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "subscribers"
+                    "campaigns"
                 ],
-                "summary": "Create a new subscriber",
+                "summary": "Resume a campaign",
                 "parameters": [
                     {
-                        "description": "Subscriber info (with subscriber_types optional)",
-                        "name": "subscriber",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.Subscriber"
-                        }
+                        "type": "integer",
+                        "description": "Campaign ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Subscriber"
+                            "$ref": "#/definitions/dto.CampaignResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/campaigns/{id}/stats": {
+            "get": {
+                "description": "Counts the emails the current run of a campaign queued, how many recipients opened them and clicked a link, and the clicks on each link of its HTML. Opens are reported by the tracking pixel of each email, or a click when images are blocked; bots and mail security scanners are left out.\nFor an A/B tested campaign, ab_test has the opens of each subject in the test group, the winner once the test is over, and the opens of the remainder it was sent to.\nshort_links counts the clicks on the short links attributed to the campaign (see /admin/short-links), over all its runs and wherever they were shared.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "campaigns"
+                ],
+                "summary": "Get the stats of a campaign",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Campaign ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CampaignStatsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/email-dead-letters": {
+            "get": {
+                "description": "Lists the sign-in emails (codes and links) the background queue gave up on, oldest first: SendGrid kept failing for OUTBOX_MAX_ATTEMPTS tries, refused them, or they expired waiting. What they carried is not kept.\nlimit (max 500), offset and cursor page through them like the subscriber list; sort is id or created_at.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "email-dead-letters"
+                ],
+                "summary": "List emails given up on",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page size (max 500)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Rows to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "id (default) or created_at",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Opaque cursor from X-Next-Cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.EmailDeadLetterResponse"
+                            }
+                        },
+                        "headers": {
+                            "X-Next-Cursor": {
+                                "type": "string",
+                                "description": "Cursor for the next page, absent on the last page"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/email-templates": {
+            "get": {
+                "description": "Lists the email templates of the organization.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "email-templates"
+                ],
+                "summary": "List email templates",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.EmailTemplateResponse"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Creates an email template of the organization. Subject, text and HTML are Go templates filled with variables, e.g. {{.FirstName}}; the HTML escapes them.\nA template needs a subject (code missing_subject) and a text or HTML (code missing_content). One that doesn't parse is answered 422 with code invalid_template and where the error is.\nWith format markdown or mjml, the HTML is compiled from source (code missing_source without one) into a layout for any screen width; a source that doesn't compile is answered 422 with part source and its line and column.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "email-templates"
+                ],
+                "summary": "Create an email template",
+                "parameters": [
+                    {
+                        "description": "Template",
+                        "name": "template",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.EmailTemplateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.EmailTemplateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dto.TemplateErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/email-templates/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "email-templates"
+                ],
+                "summary": "Get an email template",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Template ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.EmailTemplateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replaces an email template, validated as on create.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "email-templates"
+                ],
+                "summary": "Update an email template",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Template ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Template",
+                        "name": "template",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.EmailTemplateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.EmailTemplateResponse"
                         }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dto.TemplateErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "tags": [
+                    "email-templates"
+                ],
+                "summary": "Delete an email template",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Template ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/email-templates/{id}/preview": {
+            "post": {
+                "description": "Renders an email template with sample variables (Name, FirstName, Email, PreferencesURL), overridden and completed by those of the body, and returns its subject, text and HTML.\nA variable the template uses but isn't given, or a template that doesn't render, is answered 422 with code invalid_template and where the error is.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "email-templates"
+                ],
+                "summary": "Preview an email template",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Template ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Variables",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/dto.EmailTemplateRenderRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.EmailTemplatePreviewResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dto.TemplateErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/email-templates/{id}/test-send": {
+            "post": {
+                "description": "Renders an email template as POST /admin/email-templates/{id}/preview does and emails it, its subject prefixed with [Test], to the signed-in admin. API keys, which have no address, are answered 401.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "email-templates"
+                ],
+                "summary": "Send an email template to yourself",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Template ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Variables",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/dto.EmailTemplateRenderRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.EmailTemplateTestSendResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dto.TemplateErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/emails": {
+            "get": {
+                "description": "Lists the emails the API sent, or failed to send, across organizations, oldest first, with their status from the SendGrid webhook.\nPages of 50 by default: limit (max 500), offset and cursor page through them like the subscriber list.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "emails"
+                ],
+                "summary": "List sent emails",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only emails to this address (case insensitive)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only emails of this type: signin_code, magic_link, invitation, confirmation, preferences, admin_notification, campaign or template_test",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only emails with this status: sent, failed, deferred, delivered, bounce or dropped",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only emails sent at or after this RFC 3339 time",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only emails sent before this RFC 3339 time",
+                        "name": "until",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50, max 500)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Rows to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort key: id (default), created_at or updated_at",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Opaque cursor from X-Next-Cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.EmailLogResponse"
+                            }
+                        },
+                        "headers": {
+                            "X-Next-Cursor": {
+                                "type": "string",
+                                "description": "Cursor for the next page, absent on the last page"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/events": {
+            "get": {
+                "description": "Streams the subscriber.created, subscriber.updated and subscriber.deleted events of the caller's organization as text/event-stream, and the import.progress and import.finished events of its imports and campaign.progress of its campaign runs.\nEach message's event is the topic and its data a JSON object with id, topic, subscriber_id, import_id or campaign_id and run, status, the total, processed and failed rows of imports or the emails a run queued (processed) and occurred_at (no PII, refetch what you display).\nEvents are published once the write is committed, within one outbox drain (OUTBOX_DRAIN_SCHEDULE), and imports and campaign runs after each batch; those have no id. Missed events are not replayed on reconnect.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "events"
+                ],
+                "summary": "Live subscriber changes (Server-Sent Events)",
+                "responses": {
+                    "200": {
+                        "description": "Event stream",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/exports": {
+            "post": {
+                "description": "Queues an export of the organization's subscribers matching the filters (the same as GET /admin/subscribers, filters.query being a filter expression), or the members of segment_id (see /admin/segments), as CSV or JSON. The export worker writes the file in the background; poll GET /admin/exports/{id} for its status and download link.\nFiles are deleted EXPORT_TTL_HOURS (24 by default) after they're written. Exports contain raw emails, so this needs the pii scope.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "exports"
+                ],
+                "summary": "Export subscribers",
+                "parameters": [
+                    {
+                        "description": "Format and filters",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateExportRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ExportJobResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/exports/{id}": {
+            "get": {
+                "description": "Returns the status of an export. Once done, download_url is a signed link to the file valid for STORAGE_LINK_TTL_MINUTES (60 by default, at most until the file expires); every call returns a fresh one.\nWith S3 storage the link downloads straight from the bucket, otherwise from GET /admin/exports/{id}/download.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "exports"
+                ],
+                "summary": "Get an export",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Export ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ExportJobResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/exports/{id}/download": {
+            "get": {
+                "description": "Sends the file of a finished export kept on local storage. No sign in: the signed link from GET /admin/exports/{id} is the credential, until it expires.",
+                "produces": [
+                    "text/csv",
+                    "application/json"
+                ],
+                "tags": [
+                    "exports"
+                ],
+                "summary": "Download an export",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Export ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Link expiry (Unix time), from download_url",
+                        "name": "expires",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Link signature, from download_url",
+                        "name": "signature",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object"
+                        }
+                    },
+                    "403": {
+                        "description": "code: invalid_signature, also once the link expired",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "The file was deleted",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/graphql": {
+            "post": {
+                "description": "Queries subscribers (filterable, cursor paginated, with nested subscriber_types and delivery events), subscriber_type counts and the dashboard stats in one request.\nThe schema is in internal/graph/schema.graphqls and can be introspected. Results are scoped to the caller's organization; emails are masked without the pii scope.\nQuery errors are returned in the errors array with status 200, invalid documents with 422.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "graphql"
+                ],
+                "summary": "Admin GraphQL endpoint",
+                "parameters": [
+                    {
+                        "description": "GraphQL query and variables",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.GraphQLRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.GraphQLResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dto.GraphQLResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/imports": {
+            "post": {
+                "description": "Uploads a CSV or JSON file of subscribers for the import worker to apply in the background: a row whose email is already a subscriber of the organization updates it, any other creates an active subscriber. Poll GET /admin/imports/{id} for the progress.\nCSV files need a header with an email column; name, locale and subscriber_types (name:frequency:channel triplets separated by semicolons) are optional and other columns are ignored. JSON files are an array of objects like the body of POST /admin/subscribers. Exports can be imported back as they are.\nFiles are at most IMPORT_MAX_UPLOAD_MB (20 by default). Imports write raw emails, so this needs the pii scope.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "imports"
+                ],
+                "summary": "Import subscribers",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "The subscribers",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "csv or json, from the file extension if empty",
+                        "name": "format",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ImportJobResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/imports/{id}": {
+            "get": {
+                "description": "Returns the progress of an import: total_rows once the file is read through, then processed_rows, created_rows, updated_rows and failed_rows, saved after each batch of IMPORT_BATCH_SIZE rows (500 by default).\nOnce finished with failed rows, error_report_url is a signed link to a CSV of them (row, email, error) valid for STORAGE_LINK_TTL_MINUTES (60 by default); every call returns a fresh one. The job and its report are deleted IMPORT_TTL_HOURS (72 by default) after it finished.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "imports"
+                ],
+                "summary": "Get an import",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Import ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ImportJobResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/imports/{id}/errors": {
+            "get": {
+                "description": "Sends the CSV of the rows of an import that failed (row, email, error), kept on local storage. No sign in: the signed link from GET /admin/imports/{id} is the credential, until it expires.",
+                "produces": [
+                    "text/csv"
+                ],
+                "tags": [
+                    "imports"
+                ],
+                "summary": "Download the error report of an import",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Import ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Link expiry (Unix time), from error_report_url",
+                        "name": "expires",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Link signature, from error_report_url",
+                        "name": "signature",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object"
+                        }
+                    },
+                    "403": {
+                        "description": "code: invalid_signature, also once the link expired",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "The file was deleted",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/integrations": {
+            "get": {
+                "description": "Lists the mailing list providers the organization is connected to, with the lists kept in sync. API keys are never returned, only their last characters.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "integrations"
+                ],
+                "summary": "List integrations",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.IntegrationResponse"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/integrations/{provider}": {
+            "get": {
+                "description": "Returns the integration with a provider, the lists kept in sync and the outcome of the last sync.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "integrations"
+                ],
+                "summary": "Get an integration",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "enum": [
+                            "mailchimp"
+                        ],
+                        "description": "",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.IntegrationResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Connects the organization to its account at a mailing list provider, or changes the API key or pauses the sync (enabled=false) of an existing integration. A new API key is checked with the provider first.\nThe sync worker runs on INTEGRATIONS_SYNC_SCHEDULE (every 15 minutes by default) once lists are mapped with PUT /admin/integrations/{provider}/lists.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "integrations"
+                ],
+                "summary": "Connect a provider",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "enum": [
+                            "mailchimp"
+                        ],
+                        "description": "",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "API key and whether the sync runs",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UpdateIntegrationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.IntegrationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "code: invalid_credentials when the provider rejects the API key",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Unknown provider",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "code: provider_unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes the integration, its API key and its lists. Nothing is deleted at the provider.",
+                "tags": [
+                    "integrations"
+                ],
+                "summary": "Disconnect a provider",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "enum": [
+                            "mailchimp"
+                        ],
+                        "description": "",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/integrations/{provider}/lists": {
+            "put": {
+                "description": "Replaces the lists kept in sync with the provider. Each gets the active subscribers with its subscriber_type, or all active subscribers without one; a list mapped before keeps how far its sync got, a new one starts with every subscriber.\nUnsubscribes from a list at the provider unsubscribe the subscribers of the organization with the address. An empty array stops the sync.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "integrations"
+                ],
+                "summary": "Map the lists of an integration",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "enum": [
+                            "mailchimp"
+                        ],
+                        "description": "",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Lists",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UpdateIntegrationListsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.IntegrationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "code: invalid_subscriber_type",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/invitations": {
+            "get": {
+                "description": "Lists the organization's invitations, newest first, with their status.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "invitations"
+                ],
+                "summary": "List invitations",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.InvitationResponse"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/invitations/{id}": {
+            "delete": {
+                "description": "The link stops working immediately. Accepted invitations can't be revoked; remove the admin instead.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "invitations"
+                ],
+                "summary": "Revoke an invitation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Invitation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.InvitationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/invitations/{token}": {
+            "get": {
+                "description": "Public: shows the invitee which organization and role the emailed link grants.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "invitations"
+                ],
+                "summary": "Preview an invitation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token from the invitation link",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.InvitationPreviewResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Accepted, revoked or expired",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Public: creates the admin with the invited role and signs them in. The link is single-use;\nholding it proves control of the invited mailbox.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "invitations"
+                ],
+                "summary": "Accept an invitation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token from the invitation link",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.TokenResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Accepted, revoked or expired",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/local-events": {
+            "get": {
+                "description": "Lists the local events of the organization by start, those not over yet with upcoming=true.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "local-events"
+                ],
+                "summary": "List local events",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Only events not over yet",
+                        "name": "upcoming",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.EventResponse"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Creates a local event of the organization, such as a market day, announced by campaigns with its calendar invite (event_id of /admin/campaigns) and served at calendar_url.\nstarts_at and ends_at are RFC 3339 times, or local 2025-07-05T09:00 read in timezone (an IANA name, UTC by default); ends_at must be after starts_at (code invalid_time). url, the page of the event, is an http(s) URL (code invalid_url).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "local-events"
+                ],
+                "summary": "Create a local event",
+                "parameters": [
+                    {
+                        "description": "Event",
+                        "name": "event",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.EventRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.EventResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/local-events/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "local-events"
+                ],
+                "summary": "Get a local event",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Event ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.EventResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replaces the details and times of a local event, validated as on create. Its sequence is incremented, so calendars that added its invite replace it when they fetch it again; emails already sent keep the old one.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "local-events"
+                ],
+                "summary": "Update a local event",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Event ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Event",
+                        "name": "event",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.EventRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.EventResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes a local event; its invite is then answered 404. Campaigns announcing it are kept, sent without an invite.",
+                "tags": [
+                    "local-events"
+                ],
+                "summary": "Delete a local event",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Event ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/organizations": {
+            "get": {
+                "description": "Platform admins see every organization, everyone else only their own.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "List organizations",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.OrganizationResponse"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Creates a new organization (local community). Platform admins only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "Create an organization",
+                "parameters": [
+                    {
+                        "description": "Name and unique slug",
+                        "name": "organization",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.OrganizationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.OrganizationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/organizations/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "Get an organization",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.OrganizationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Renames an organization or changes its slug. Platform admins only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "Update an organization",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Name and unique slug",
+                        "name": "organization",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.OrganizationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.OrganizationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes an empty organization (no subscribers, API keys or admins). The default organization can't be deleted. Platform admins only.",
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "Delete an organization",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/organizations/{id}/members": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "List an organization's admins",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.AdminUserResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Assigns a sign-in email to the organization with a role. The admin's next sign-in is scoped to both.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "Add an admin to an organization",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Admin email and role",
+                        "name": "member",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.AddAdminUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.AdminUserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/organizations/{id}/members/{memberId}": {
+            "delete": {
+                "description": "Existing sessions keep their organization until they expire or are revoked.",
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "Remove an admin from an organization",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Admin user ID",
+                        "name": "memberId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/rest-hooks": {
+            "get": {
+                "description": "Lists the REST hooks subscribed in the organization.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rest-hooks"
+                ],
+                "summary": "List REST hooks",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.RestHookResponse"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Subscribes target_url to a subscriber event, following the REST Hooks convention of Zapier. Every event of the organization is POSTed to it as a dto.RestHookPayload, with the subscriber as it is when delivered; failed deliveries are retried with backoff, and a target answering 410 Gone is unsubscribed.\nPayloads hold raw emails, so this needs the pii scope.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rest-hooks"
+                ],
+                "summary": "Subscribe a REST hook",
+                "parameters": [
+                    {
+                        "description": "Target URL and event",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateRestHookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.RestHookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/rest-hooks/sample": {
+            "get": {
+                "description": "Returns payloads of an event for the most recent subscribers of the organization (a made-up one when there are none yet), the way deliveries look. No-code tools such as Zapier poll it to show the fields while a zap is set up.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rest-hooks"
+                ],
+                "summary": "Sample REST hook payloads",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "enum": [
+                            "subscriber.created",
+                            "subscriber.updated",
+                            "subscriber.deleted"
+                        ],
+                        "description": "",
+                        "name": "event",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.RestHookPayload"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/rest-hooks/{id}": {
+            "delete": {
+                "description": "Deletes a REST hook; events still queued for it are dropped.",
+                "tags": [
+                    "rest-hooks"
+                ],
+                "summary": "Unsubscribe a REST hook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Hook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/review-queue": {
+            "get": {
+                "description": "Lists the organization's public signups the spam checks quarantined, oldest first, with their spam score and the points of each check that fired.\nlimit (max 500), offset and cursor page through the queue like the subscriber list.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "review-queue"
+                ],
+                "summary": "List quarantined signups",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page size (max 500)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Rows to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Opaque cursor from X-Next-Cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.ReviewQueueItem"
+                            }
+                        },
+                        "headers": {
+                            "X-Next-Cursor": {
+                                "type": "string",
+                                "description": "Cursor for the next page, absent on the last page"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/review-queue/{id}/approve": {
+            "post": {
+                "description": "Lets a quarantined signup through as an approved revision: active, or with SIGNUP_DOUBLE_OPT_IN=true pending and emailed the confirmation link it was held back from.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "review-queue"
+                ],
+                "summary": "Approve a quarantined signup",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscriber ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SubscriberResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "code: not_quarantined",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/review-queue/{id}/reject": {
+            "post": {
+                "description": "Deletes a quarantined signup. Like any deletion it can be undone with POST /admin/subscribers/{id}/undelete until it's purged.",
+                "tags": [
+                    "review-queue"
+                ],
+                "summary": "Reject a quarantined signup",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscriber ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "code: not_quarantined",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/segments": {
+            "get": {
+                "description": "Lists the segments of the organization.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "segments"
+                ],
+                "summary": "List segments",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.SegmentResponse"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Saves an audience of the organization: a filter by status, verification, subscriber_type preferences, subscriber_types held, metadata tags, signup dates and a filter expression (query, see POST /admin/segments/validate). Names are unique within an organization.\nTarget it with GET /admin/subscribers?segment={id} and POST /admin/exports (segment_id); its members are computed each time.\nsubscriber_types must be listed by GET /admin/subscriber-types (422); other invalid filters are rejected with code invalid_filter, with the position of the part of the query at fault if that's the cause.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "segments"
+                ],
+                "summary": "Create a segment",
+                "parameters": [
+                    {
+                        "description": "Segment definition",
+                        "name": "segment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.SegmentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SegmentResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.FilterErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dto.InvalidSubscriberTypesResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/segments/preview": {
+            "post": {
+                "description": "Counts the subscribers a filter would hold, in total and by status, to tune a segment before saving it. The filter is validated as on create.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "segments"
+                ],
+                "summary": "Preview a segment filter",
+                "parameters": [
+                    {
+                        "description": "Segment filter",
+                        "name": "filter",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.SegmentFilter"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SegmentPreviewResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.FilterErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dto.InvalidSubscriberTypesResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/segments/validate": {
+            "post": {
+                "description": "Checks a filter expression, as taken by segments (filter.query), exports (filters.query) and the filter parameter of GET /admin/subscribers and /admin/subscribers/search, e.g. \"type:shopper AND created_at\u003e2024-01-01 AND NOT tag:vip\".\nConditions are field, operator (: or = for equality, !=, \u003e, \u003e=, \u003c, \u003c=) and value, double-quoted if it holds spaces or parentheses. They're combined with AND (also implied between conditions), OR, NOT and parentheses.\nFields: status, verified, type (alias subscriber_type, must be listed by GET /admin/subscriber-types), frequency, channel, tag, email and name (* matching any text), country, locale, utm_source, utm_medium, utm_campaign, referrer, spam_score, created_at and updated_at (a date compares whole days, or an RFC 3339 time).\nAn invalid expression isn't an error: the response points at the part at fault, its position and length counting characters from 0.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "segments"
+                ],
+                "summary": "Validate a filter expression",
+                "parameters": [
+                    {
+                        "description": "Filter expression",
+                        "name": "query",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.FilterValidationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.FilterValidationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/segments/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "segments"
+                ],
+                "summary": "Get a segment",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Segment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SegmentResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replaces the name, description and filter of a segment, validated as on create. Exports already queued keep the filter they were queued with.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "segments"
+                ],
+                "summary": "Update a segment",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Segment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Segment definition",
+                        "name": "segment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.SegmentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SegmentResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.FilterErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dto.InvalidSubscriberTypesResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes a segment. Its subscribers are kept, and exports already queued for it still run. Segments of campaigns not sent or cancelled yet can't be deleted (409 with code segment_in_use).",
+                "tags": [
+                    "segments"
+                ],
+                "summary": "Delete a segment",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Segment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/segments/{id}/preview": {
+            "get": {
+                "description": "Counts the subscribers a segment holds now, in total and by status.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "segments"
+                ],
+                "summary": "Preview a segment",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Segment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SegmentPreviewResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/sessions": {
+            "get": {
+                "description": "Lists every active session (device) of the authenticated user, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sessions"
+                ],
+                "summary": "List active sessions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.SessionResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/sessions/{id}": {
+            "delete": {
+                "description": "Signs out one device of the authenticated user. Revoking the current session signs you out.",
+                "tags": [
+                    "sessions"
+                ],
+                "summary": "Revoke a session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/short-links": {
+            "get": {
+                "description": "Lists the short links of the organization with their clicks, those of a campaign with campaign_id.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "short-links"
+                ],
+                "summary": "List short links",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Campaign the clicks are attributed to",
+                        "name": "campaign_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.ShortLinkResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Creates a short link of the organization, /l/{code} redirecting to url (an http(s) URL, code invalid_url). Codes are 3 to 32 lowercase letters, digits and dashes (code invalid_code), unique across organizations, and generated when omitted.\ncampaign_id attributes its clicks to a campaign of the organization (code unknown_campaign), see GET /admin/campaigns/{id}/stats. Short links in the HTML of a campaign aren't rewritten by its click tracking.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "short-links"
+                ],
+                "summary": "Create a short link",
+                "parameters": [
+                    {
+                        "description": "Short link",
+                        "name": "link",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ShortLinkRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ShortLinkResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/short-links/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "short-links"
+                ],
+                "summary": "Get a short link",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Short link ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ShortLinkResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Changes the code, URL and campaign of a short link, validated as on create; the code is kept when omitted. Its clicks are kept. Links handed out with the old code stop working.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "short-links"
+                ],
+                "summary": "Update a short link",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Short link ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Short link",
+                        "name": "link",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ShortLinkRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ShortLinkResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes a short link; it's then answered 404.",
+                "tags": [
+                    "short-links"
+                ],
+                "summary": "Delete a short link",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Short link ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/signup-forms": {
+            "get": {
+                "description": "Lists the signup forms of the organization.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "signup-forms"
+                ],
+                "summary": "List signup forms",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.SignupFormResponse"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Creates a public signup form of the organization, submitted to POST /signup/forms/{slug}/submit. Slugs are unique within an organization.\nsubscriber_types must be listed by GET /admin/subscriber-types. Custom fields are text, number, checkbox or select (with options); their names can't be those of the submission's own parameters (code invalid_field).\nsuccess_redirect must be an http(s) URL (code invalid_redirect). captcha defaults to true.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "signup-forms"
+                ],
+                "summary": "Create a signup form",
+                "parameters": [
+                    {
+                        "description": "Form definition",
+                        "name": "form",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.SignupFormRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SignupFormResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dto.InvalidSubscriberTypesResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/signup-forms/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "signup-forms"
+                ],
+                "summary": "Get a signup form",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Form ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SignupFormResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replaces the definition of a signup form, validated as on create. Subscribers who already submitted it keep their metadata.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "signup-forms"
+                ],
+                "summary": "Update a signup form",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Form ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Form definition",
+                        "name": "form",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.SignupFormRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SignupFormResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dto.InvalidSubscriberTypesResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes a signup form; its submissions are then answered 404. Subscribers who submitted it are kept.",
+                "tags": [
+                    "signup-forms"
+                ],
+                "summary": "Delete a signup form",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Form ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/stats": {
+            "get": {
+                "description": "Subscriber counts by type, signup growth per day / week / month, unsubscribe rate and the most recent signups.\nUnsubscribed counts subscribers with status unsubscribed, which includes anonymized ones. Cached in the Redis entity DB when caching is enabled.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stats"
+                ],
+                "summary": "Admin dashboard stats",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.StatsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/stats/geo": {
+            "get": {
+                "description": "Counts the public signups of the organization by country and by region, and its admin activity by country.\nRecords are located when written, from the client IP and the MaxMind DB at GEOIP_DB_PATH; those that weren't aren't counted.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stats"
+                ],
+                "summary": "Geographic breakdown",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.GeoStatsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/stats/referrals": {
+            "get": {
+                "description": "Counts the subscribers of the organization who signed up with the referral code (ref) of another, how many of them are active, and how many subscribers referred them.\nThe leaderboard ranks the limit (10 by default) subscribers who referred the most. since and until filter the referees by their signup. Callers without the pii scope see masked emails.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stats"
+                ],
+                "summary": "Referral leaderboard",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Leaderboard size, at most 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only referees created at or after this RFC 3339 time",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only referees created before this RFC 3339 time",
+                        "name": "until",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ReferralStatsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/stats/sessions": {
+            "get": {
+                "description": "Counts the active admin sessions of the organization by device type (desktop, mobile, tablet, bot, other), OS, browser and country.\nClients are parsed from the User-Agent at sign-in. Countries need the MaxMind DB at GEOIP_DB_PATH: without it they're all unknown.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stats"
+                ],
+                "summary": "Active session stats",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SessionStatsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/stats/sources": {
+            "get": {
+                "description": "Counts the subscribers of the organization by the channel they signed up from: utm_source (else the referrer host, else none), utm_medium, utm_campaign and referrer, as sent to POST /signup/subscribers.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stats"
+                ],
+                "summary": "Signups by source",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only subscribers created at or after this RFC 3339 time",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only subscribers created before this RFC 3339 time",
+                        "name": "until",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SourceStatsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/subscriber-types": {
+            "get": {
+                "description": "Lists the names subscriber_types may take, in alphabetical order. Subscriber writes with other names are rejected with code invalid_subscriber_type.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriber-types"
+                ],
+                "summary": "List subscriber types",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.SubscriberTypeDefinitionResponse"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Allows a new subscriber_types name, for every organization. Names are 2 to 32 lowercase letters, digits or underscores, starting with a letter. Platform admins only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriber-types"
+                ],
+                "summary": "Add a subscriber type",
+                "parameters": [
+                    {
+                        "description": "Name and description",
+                        "name": "type",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateSubscriberTypeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SubscriberTypeDefinitionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/subscriber-types/{name}": {
+            "delete": {
+                "description": "Disallows a subscriber_types name. Types still held by subscribers (deleted ones included) can't be removed. Platform admins only.",
+                "tags": [
+                    "subscriber-types"
+                ],
+                "summary": "Remove a subscriber type",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Type name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "code: type_in_use",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/subscribers": {
+            "get": {
+                "description": "Returns a list of all subscribers. Relations are only embedded when asked for: include=subscriber_types,notes (unknown ones are rejected with code invalid_include).\nPass limit (with offset, or with the opaque cursor from the X-Next-Cursor header) to page through the list.\nCursor pagination is stable while rows are added or removed mid-iteration. Other reads return the number of matching subscribers in X-Total-Count.\nFilter by status and verified to only target deliverable addresses, and by subscriber_type, frequency and channel to target a campaign (all three apply to the same subscriber_type).\nsegment targets a saved segment (see /admin/segments) instead, or on top of the other filters; unknown ids are rejected with code unknown_segment.\nfilter narrows the list with an expression, e.g. filter=type:shopper AND NOT tag:vip (see POST /admin/segments/validate); invalid ones are rejected with code invalid_filter and the position of the part at fault.\nfields picks the fields returned, e.g. fields=id,email for a sync that only needs addresses; a relation listed there is embedded too. Unknown fields are rejected with code invalid_fields.\nLast-Modified is when any of the organization's subscribers last changed, rounded up to the second and only sent once that second is over; send it back in If-Modified-Since to get a 304 when nothing did.\nAccept: application/xml or application/msgpack returns the list as XML (\u003csubscribers\u003e\u003citem\u003e…\u003c/item\u003e\u003c/subscribers\u003e) or MessagePack instead of JSON.",
+                "produces": [
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "subscribers"
+                ],
+                "summary": "Get all subscribers",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comma separated statuses: pending, active, bounced, unsubscribed, quarantined",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only subscribers with (true) or without (false) a verified email",
+                        "name": "verified",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only subscribers having this subscriber_type",
+                        "name": "subscriber_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only subscribers with a subscriber_type at this frequency: daily, weekly or monthly",
+                        "name": "frequency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only subscribers with a subscriber_type on this channel: email or sms",
+                        "name": "channel",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only the members of this segment",
+                        "name": "segment",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter expression, e.g. type:shopper AND NOT tag:vip, see POST /admin/segments/validate",
+                        "name": "filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50 when paginating, max 500)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Rows to skip (offset pagination)",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Opaque cursor from a previous X-Next-Cursor header",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort key: id (default), created_at or updated_at",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated fields to return, e.g. id,email,created_at (default all)",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated relations to embed: subscriber_types, notes (default none)",
+                        "name": "include",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last-Modified from a previous read",
+                        "name": "If-Modified-Since",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.SubscriberResponse"
+                            }
+                        },
+                        "headers": {
+                            "X-Next-Cursor": {
+                                "type": "string",
+                                "description": "Cursor for the next page, absent on the last page"
+                            },
+                            "X-Total-Count": {
+                                "type": "int",
+                                "description": "Subscribers matching the filters, absent on cursor pages"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "When the organization's subscribers last changed, whichever page or filter is read"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Creates a new subscriber record, optionally with multiple subscriber_types. Validates email \u0026 name.\nDisposable email domains are rejected with code disposable_email; with EMAIL_MX_CHECK on, domains without mail servers with code undeliverable_email_domain.\nsubscriber_types must be listed by GET /admin/subscriber-types; unknown names are rejected with 422 listing them and the accepted ones.\nSubscribers created by admins start out active. metadata is a free-form JSON object of at most 16KB (code metadata_too_large).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "subscribers"
+                ],
+                "summary": "Create a new subscriber",
+                "parameters": [
+                    {
+                        "description": "Subscriber info (with subscriber_types optional)",
+                        "name": "subscriber",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateSubscriberRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SubscriberResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dto.InvalidSubscriberTypesResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/subscribers/batch": {
+            "post": {
+                "description": "Applies up to 500 operations in a single transaction. Either all succeed (200, committed=true)\nor everything is rolled back (422, committed=false) and the failing items carry an error.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscribers"
+                ],
+                "summary": "Batch create/update/delete subscribers",
+                "parameters": [
+                    {
+                        "description": "Operations, applied in order",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.BatchRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BatchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BatchResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/subscribers/search": {
+            "get": {
+                "description": "Partial / fuzzy matching on email and name (pg_trgm), ordered by relevance. On SQLite substring matches only, newest first. Optionally filtered by subscriber_type and its frequency and channel, and by a filter expression.\nAnswers 304 when If-Modified-Since is at or after the Last-Modified of the organization's subscribers.",
+                "produces": [
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "subscribers"
+                ],
+                "summary": "Search subscribers",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search term (matched against email and name)",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only return subscribers having this subscriber_type",
+                        "name": "subscriber_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only subscribers with a subscriber_type at this frequency: daily, weekly or monthly",
+                        "name": "frequency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only subscribers with a subscriber_type on this channel: email or sms",
+                        "name": "channel",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated statuses: pending, active, bounced, unsubscribed, quarantined",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only subscribers with (true) or without (false) a verified email",
+                        "name": "verified",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter expression, e.g. type:shopper AND NOT tag:vip, see POST /admin/segments/validate",
+                        "name": "filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Max results (default 50, max 200)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated fields to return, e.g. id,email,created_at (default all)",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated relations to embed: subscriber_types, notes (default none)",
+                        "name": "include",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last-Modified from a previous search",
+                        "name": "If-Modified-Since",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.SubscriberResponse"
+                            }
+                        },
+                        "headers": {
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "When the organization's subscribers last changed"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/subscribers/{id}": {
+            "get": {
+                "description": "Gets subscriber by id, including all subscriber_types, or only the fields listed in fields (unknown ones are rejected with code invalid_fields).\ninclude picks the relations embedded instead: e.g. include=subscriber_types,notes, or an empty include for none (unknown ones are rejected with code invalid_include).\nThe ETag header identifies this revision; send it back in If-None-Match to get a 304 when unchanged.\nAccept: application/xml or application/msgpack returns the subscriber as XML (\u003csubscriber\u003e…\u003c/subscriber\u003e) or MessagePack instead of JSON.",
+                "produces": [
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "subscribers"
+                ],
+                "summary": "Get a single subscriber",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscriber ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated fields to return, e.g. id,email,created_at (default all)",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated relations to embed: subscriber_types, notes (default subscriber_types, none with fields)",
+                        "name": "include",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag from a previous read",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SubscriberResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Current revision of the subscriber"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Updates subscriber by id. If subscriber_types or metadata are provided, they overwrite the stored ones. Validates email \u0026 name.\nIf-Match must carry the ETag of the revision being edited, so concurrent edits are not silently lost.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "subscribers"
+                ],
+                "summary": "Update a subscriber",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscriber ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag from GET /admin/subscribers/{id}",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Subscriber info (subscriber_types optional)",
+                        "name": "subscriber",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UpdateSubscriberRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SubscriberResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New revision of the subscriber"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "code: version_conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "code: precondition_failed",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dto.InvalidSubscriberTypesResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "code: if_match_required",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes subscriber by id (and associated subscriber_types).\nThe subscriber is soft-deleted: POST /admin/subscribers/{id}/undelete brings it back with its subscriber_types, notes and history until it's purged for good after SUBSCRIBER_RETENTION_DAYS.",
+                "tags": [
+                    "subscribers"
+                ],
+                "summary": "Delete a subscriber",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscriber ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/subscribers/{id}/anonymize": {
+            "post": {
+                "description": "Irreversibly scrubs the subscriber's email, name and metadata and the address of the emails sent to them, deletes their passkeys, trusted devices, sessions, delivery history, notes and revision history. The record and its subscriber_types are kept so aggregate stats stay correct.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscribers"
+                ],
+                "summary": "Anonymize a subscriber (right to be forgotten)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscriber ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SubscriberResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/subscribers/{id}/delivery-events": {
+            "get": {
+                "description": "Lists the email delivery events (delivered, bounce, spam report, unsubscribe, ...) received for a subscriber, newest first.\nCallers without the pii scope don't see the provider's reason text.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscribers"
+                ],
+                "summary": "Subscriber delivery history",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscriber ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.DeliveryEventResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/subscribers/{id}/emails": {
+            "get": {
+                "description": "Lists the emails sent to the subscriber's address, or that failed to send, newest first, with their status from the SendGrid webhook.\nEmails are logged per address: those sent to it for another organization, or to sign in, are listed too.\nCallers without the pii scope get the address masked and no send errors.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscribers"
+                ],
+                "summary": "Emails sent to a subscriber",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscriber ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.EmailLogResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/subscribers/{id}/gdpr-export": {
+            "get": {
+                "description": "Returns a JSON archive of all data held about a subscriber: the record, its subscriber_types, registered passkeys, active sessions, email delivery history, the emails sent to them and admin notes. Requires the pii scope.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscribers"
+                ],
+                "summary": "GDPR data export",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscriber ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.GDPRExport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/subscribers/{id}/history": {
+            "get": {
+                "description": "Lists the revisions of a subscriber, newest first: who made each write, the subscriber before and after it, and the changed fields for rendering a diff (metadata keys as metadata.\u003ckey\u003e).\nPages hold limit revisions (50 by default); pass the id of the last revision received as before to get the next page. Callers without the pii scope see masked emails.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscribers"
+                ],
+                "summary": "Subscriber revision history",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscriber ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, at most 500",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only revisions older than this revision id",
+                        "name": "before",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.SubscriberRevisionResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/subscribers/{id}/history/{revision}/restore": {
+            "post": {
+                "description": "Applies the state the subscriber had after the given revision (email, name, status, verification, metadata and subscriber_types) and records the rollback as a new restored revision.\nA bounce or opt-out since the revision is kept: unsubscribed and bounced subscribers keep their status.\nRevisions holding subscriber_types that were removed since are rejected with 422.",
+                "produces": [
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "subscribers"
+                ],
+                "summary": "Roll a subscriber back to a revision",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscriber ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Revision ID",
+                        "name": "revision",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SubscriberResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "code: version_conflict or anonymized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dto.InvalidSubscriberTypesResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/subscribers/{id}/merge": {
+            "post": {
+                "description": "Folds the subscriber source_id into the subscriber of the path: its subscriber_types, notes and email delivery history move over, metadata is combined (the target's values win) and the source is soft-deleted.\nemail_rule picks the address kept, with its verification and status: target (default), source, or verified, the verified address (the target's when both or neither are).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "subscribers"
+                ],
+                "summary": "Merge a duplicate subscriber",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Target subscriber ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Source subscriber and email rule",
+                        "name": "merge",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.MergeSubscribersRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SubscriberResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "code: merge_into_self or invalid_email_rule",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "code: version_conflict or anonymized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/subscribers/{id}/notes": {
+            "get": {
+                "description": "Lists the notes recorded about a subscriber, newest first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscribers"
+                ],
+                "summary": "List a subscriber's notes",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscriber ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.SubscriberNoteResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Records free text about a subscriber (support context, call summaries, ...), authored by the caller. Text is required and at most 10000 characters.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscribers"
+                ],
+                "summary": "Add a note to a subscriber",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscriber ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Note",
+                        "name": "note",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateSubscriberNoteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SubscriberNoteResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/subscribers/{id}/notes/{noteId}": {
+            "delete": {
+                "description": "Deletes one note of a subscriber.",
+                "tags": [
+                    "subscribers"
+                ],
+                "summary": "Delete a subscriber's note",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscriber ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Note ID",
+                        "name": "noteId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/subscribers/{id}/preferences-link": {
+            "post": {
+                "description": "Returns a link, valid for PREFERENCES_LINK_TTL_DAYS (30 by default), letting the subscriber change their name, subscriber_types and subscription themselves, e.g. to include in a campaign footer.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscribers"
+                ],
+                "summary": "Create a preferences link",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscriber ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PreferencesLinkResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "code: anonymized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/subscribers/{id}/referrals": {
+            "get": {
+                "description": "Returns the referral code a subscriber shares, the subscriber who referred them, and the signups made with their code, newest first, with how many of those are active.\nPages hold limit referees (50 by default); pass the id of the last referee received as before to get the next page. Callers without the pii scope see masked emails.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscribers"
+                ],
+                "summary": "Subscriber referral history",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscriber ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, at most 500",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only referees older than this subscriber id",
+                        "name": "before",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SubscriberReferralsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/subscribers/{id}/resend-confirmation": {
+            "post": {
+                "description": "Emails a pending subscriber a new double opt-in link, invalidating the previous one, e.g. when it never arrived.\nA subscriber can get one every SUBSCRIBER_RESEND_COOLDOWN_MINUTES and SUBSCRIBER_MAX_RESENDS_PER_DAY a day.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscribers"
+                ],
+                "summary": "Resend a confirmation email",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscriber ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "code: not_pending",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "code: resend_too_soon or resend_limit_reached, with Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/subscribers/{id}/undelete": {
+            "post": {
+                "description": "Brings back a subscriber deleted less than SUBSCRIBER_RETENTION_DAYS ago, with the subscriber_types, notes and history it had, as an undeleted revision.\nSubscribers deleted longer ago are due to be purged and get a 410 with code undo_window_expired.",
+                "produces": [
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "subscribers"
+                ],
+                "summary": "Undelete a subscriber",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscriber ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SubscriberResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Current revision of the subscriber"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Unknown or not deleted",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "code: undo_window_expired",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/trusted-devices": {
+            "get": {
+                "description": "Lists the devices the authenticated user chose to remember at sign-in, which skip the code until they expire, most recently used first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sessions"
+                ],
+                "summary": "List trusted devices",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.TrustedDeviceResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Forgets every trusted device of the authenticated user, e.g. after losing one",
+                "tags": [
+                    "sessions"
+                ],
+                "summary": "Revoke every trusted device",
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/trusted-devices/{id}": {
+            "delete": {
+                "description": "Forgets one trusted device of the authenticated user: it needs a code again. Sessions it already has are revoked separately (DELETE /admin/sessions/{id}).",
+                "tags": [
+                    "sessions"
+                ],
+                "summary": "Revoke a trusted device",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Trusted device ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/invite": {
+            "post": {
+                "description": "Emails a single-use invitation link to join the caller's organization with the given role.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "invitations"
+                ],
+                "summary": "Invite an admin",
+                "parameters": [
+                    {
+                        "description": "Invitee email and role (admin, editor, viewer)",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.InviteAdminRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.InvitationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/ws": {
+            "get": {
+                "description": "Upgrades to a WebSocket pushing the events of the caller's organization as JSON text messages with id, topic, subscriber_id, import_id or campaign_id and run, status, the total, processed and failed rows of imports or the emails a run queued (processed) and occurred_at (e.g. subscriber.created for new signups, import.progress and import.finished for imports, campaign.progress for campaign sends).\nBrowsers authenticate with new WebSocket(url, [\"bearer\", jwt]); other clients send the usual Authorization or X-API-Key header. Messages sent by the client are ignored.",
+                "tags": [
+                    "events"
+                ],
+                "summary": "Real-time admin notifications (WebSocket)",
+                "responses": {
+                    "101": {
+                        "description": "Switching protocols",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "426": {
+                        "description": "code: upgrade_required",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/events/{id}/calendar.ics": {
+            "get": {
+                "description": "Serves the iCalendar (RFC 5545) invite of a local event, to add it to a calendar. Its UID stays the same and its SEQUENCE grows as the event is updated, so calendars replace the copy they have.",
+                "produces": [
+                    "text/calendar"
+                ],
+                "tags": [
+                    "local-events"
+                ],
+                "summary": "Calendar invite of an event",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Event ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/l/{code}": {
+            "get": {
+                "description": "Redirects to the URL of the short link of code, in any case (see /admin/short-links), counting the click unless it comes from a bot or a mail security scanner.",
+                "tags": [
+                    "short-links"
+                ],
+                "summary": "Short link",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Code of the short link",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "302": {
+                        "description": "Found"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/preferences": {
+            "post": {
+                "description": "Emails the subscriber with the address, in the organization named by X-Org / ?org=, a link to their preferences page (GET/PUT /preferences/{token}), valid for PREFERENCES_LINK_TTL_DAYS (30 by default).\nThe answer is the same whether or not the address is subscribed, and at most one email is sent every 5 minutes.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "preferences"
+                ],
+                "summary": "Email a preferences link",
+                "parameters": [
+                    {
+                        "description": "Subscriber address",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.RequestPreferencesLinkRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/dto.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/preferences/{token}": {
+            "get": {
+                "description": "Returns the masked address, name, subscription status and subscriber_types, with their frequency and channel, of the subscriber a preferences link was issued to. No sign in: the emailed token is the credential.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "preferences"
+                ],
+                "summary": "View subscription preferences",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token from the preferences link",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PreferencesResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "code: invalid_token, also once the link expired",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Lets the subscriber behind a preferences link change their name, replace their subscriber_types (with each one's frequency and channel) and unsubscribe or subscribe again. Omitted fields are left untouched.\nSubscribing again only reactivates an unsubscribed address: a bounced one stays bounced and a pending one still has to confirm.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "preferences"
+                ],
+                "summary": "Update subscription preferences",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token from the preferences link",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Changes",
+                        "name": "preferences",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UpdatePreferencesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PreferencesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "code: invalid_frequency or invalid_channel",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "code: invalid_token, also once the link expired",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "code: version_conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "code: invalid_subscriber_type",
+                        "schema": {
+                            "$ref": "#/definitions/dto.InvalidSubscriberTypesResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/signin/magic/{token}": {
+            "get": {
+                "description": "Opened from the email sent by /signin/request with method link. Consumes the single-use token, creates the session and redirects to SIGNIN_MAGIC_LINK_REDIRECT_URL#token=\u003cJWT\u003e.\nAn unknown, expired or already used link redirects to SIGNIN_MAGIC_LINK_REDIRECT_URL#error=invalid_link.",
+                "tags": [
+                    "signin"
+                ],
+                "summary": "Sign in with a magic link",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token from the emailed link",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "302": {
+                        "description": "Redirect with the token, or the error, in the fragment",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/signin/oauth/{provider}/callback": {
+            "get": {
+                "description": "Completes the code flow, maps the provider's verified email to a session and mints the session JWT.\nRedirects to OAUTH_SUCCESS_REDIRECT_URL#token=... when configured, otherwise responds with the token.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "signin"
+                ],
+                "summary": "OAuth sign in callback",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Provider name (google, github)",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization code",
+                        "name": "code",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "State returned by /start",
+                        "name": "state",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "JWT returned",
+                        "schema": {
+                            "$ref": "#/definitions/dto.TokenResponse"
+                        }
+                    },
+                    "302": {
+                        "description": "Redirect with token in fragment",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/signin/oauth/{provider}/start": {
+            "get": {
+                "description": "Redirects to the identity provider (google or github). State, nonce and PKCE verifier are kept in Redis.",
+                "tags": [
+                    "signin"
+                ],
+                "summary": "Start OAuth sign in",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Provider name (google, github)",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "302": {
+                        "description": "Redirect to provider",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/signin/remembered-email": {
+            "get": {
+                "description": "Returns the email last saved with PUT /signin/remembered-email on this browser, for the sign-in form to prefill.\nA cookie signed with a previous key of SIGNIN_REMEMBER_EMAIL_PREVIOUS_KEYS is accepted and signed again with SIGNIN_REMEMBER_EMAIL_KEY.\nCross-origin frontends send the cookie with credentials (CORS_SIGNIN_ALLOW_CREDENTIALS=true).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "signin"
+                ],
+                "summary": "Email remembered by this browser",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.RememberedEmailResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "code: not_remembered, no email or a cookie that isn't ours",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Saves the email in a signed, HTTP-only cookie for SIGNIN_REMEMBER_EMAIL_DAYS (365 by default), read back by GET /signin/remembered-email.\nThe cookie only holds the address: it doesn't sign in.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "signin"
+                ],
+                "summary": "Remember an email on this browser",
+                "parameters": [
+                    {
+                        "description": "Email to remember",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.RememberEmailRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.RememberedEmailResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Expires the cookie set by PUT /signin/remembered-email, e.g. to sign in with another address.",
+                "tags": [
+                    "signin"
+                ],
+                "summary": "Forget the email remembered by this browser",
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/signin/request": {
+            "post": {
+                "description": "Takes an email or a phone, generates a code, stores in Redis, sends via SendGrid or, for channel sms, a Twilio text message.\nCodes are SIGNIN_CODE_LENGTH (6 to 10, 6 by default) digits, or digits and uppercase letters with SIGNIN_CODE_ALPHABET=alphanumeric, valid for SIGNIN_CODE_TTL_MINUTES (5 by default).\nEmails are queued and sent in the background, retried while SendGrid is down: the response doesn't wait for them.\nPhones are in E.164 form (+15551234567). A phone signs in as itself: admin roles stay with email addresses.\nWith method link, a single-use link to /signin/magic/{token} is emailed instead, valid for SIGNIN_MAGIC_LINK_TTL_MINUTES (15 by default).\nA device trusted by this email or phone (trust_device at /signin/verify, cookie or X-Device-Token) gets a dto.TokenResponse right away instead.\nCodes per address and hour are limited, separately for email (SIGNIN_EMAIL_REQUESTS_PER_HOUR, 10 by default, links included) and sms (SIGNIN_SMS_REQUESTS_PER_HOUR, 3 by default).\nWith SIGNIN_CODE_RECIPIENTS=known, only the addresses of admins and subscribers are emailed; others get the same 200, and every email request is answered after SIGNIN_RESPONSE_FLOOR_MS (500 by default), so neither tells them apart.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "signin"
+                ],
+                "summary": "Request Sign In",
+                "parameters": [
+                    {
+                        "description": "Email or phone to send the code to",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.SignInRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Code sent (dto.TokenResponse for a trusted device)",
+                        "schema": {
+                            "$ref": "#/definitions/dto.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "code: invalid_phone, invalid_channel or invalid_method",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "code: too_many_requests",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "code: service_unavailable, with Retry-After, while Redis is down",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/signin/verify": {
+            "post": {
+                "description": "Takes the email or phone a code was sent to and the code, letters in either case. If valid, generate JWT \u0026 store session in redis.\nAfter too many wrong codes the code is invalidated and verification is locked for a while.\nWith trust_device the device is remembered for TRUSTED_DEVICE_TTL_DAYS (30 by default): a cookie is set and device_token returned, for apps to send back in X-Device-Token.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "signin"
+                ],
+                "summary": "Verify Sign In Code",
+                "parameters": [
+                    {
+                        "description": "Email or phone and the code that was sent to it",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.VerifySignInRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "JWT returned",
+                        "schema": {
+                            "$ref": "#/definitions/dto.TokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "code: invalid_phone",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "code: invalid_code",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "423": {
+                        "description": "code: verification_locked",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "code: too_many_attempts",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "code: service_unavailable, with Retry-After, while Redis is down",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/signin/webauthn/login/begin": {
+            "post": {
+                "description": "Takes an email with registered passkeys and returns the PublicKeyCredentialRequestOptions to pass to navigator.credentials.get().",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "signin"
+                ],
+                "summary": "Begin passkey sign in",
+                "parameters": [
+                    {
+                        "description": "Email to sign in as",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.SignInRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "protocol.CredentialAssertion",
+                        "schema": {
+                            "type": "object"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/signin/webauthn/login/finish": {
+            "post": {
+                "description": "Verifies the assertion returned by navigator.credentials.get(). If valid, generate JWT \u0026 store session in redis",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "signin"
+                ],
+                "summary": "Finish passkey sign in",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Email passed to /signin/webauthn/login/begin",
+                        "name": "email",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "PublicKeyCredential from the browser",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "JWT returned",
+                        "schema": {
+                            "$ref": "#/definitions/dto.TokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/signin/webauthn/register/begin": {
+            "post": {
+                "description": "Starts registering a passkey for the signed-in user. Returns the PublicKeyCredentialCreationOptions to pass to navigator.credentials.create().",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "signin"
+                ],
+                "summary": "Begin passkey registration",
+                "responses": {
+                    "200": {
+                        "description": "protocol.CredentialCreation",
+                        "schema": {
+                            "type": "object"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/signin/webauthn/register/finish": {
+            "post": {
+                "description": "Verifies the attestation returned by navigator.credentials.create() and stores the new passkey.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "signin"
+                ],
+                "summary": "Finish passkey registration",
+                "parameters": [
+                    {
+                        "description": "PublicKeyCredential from the browser",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/signup/forms/{slug}/submit": {
+            "post": {
+                "description": "Signs up to the signup form of slug (see /admin/signup-forms), in the organization named by X-Org / ?org=. Accepts JSON, or an HTML form post where each custom field is a top-level value and subscriber_types may repeat.\nsubscriber_types must be among those of the form (422 otherwise), all of them when omitted. Custom fields are checked against the form: unknown_field, missing_field (also for an unchecked required checkbox) or invalid_field, and stored in the subscriber's metadata.\nOtherwise the same as POST /signup/subscribers: double opt-in, spam quarantine, attribution and referral code (ref), honeypot, and a captcha token unless the form has its captcha off.\nHTML form posts are redirected (303) to the form's success_redirect once subscribed, when it has one.",
+                "consumes": [
+                    "application/json",
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscribers"
+                ],
+                "summary": "Submit a signup form",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Form slug",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Subscriber info and custom field values",
+                        "name": "submission",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.SignupFormSubmission"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SignupFormSubmitResponse"
+                        }
+                    },
+                    "303": {
+                        "description": "See Other",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dto.InvalidSubscriberTypesResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/signup/subscribers": {
+            "post": {
+                "description": "Public signup, same body and validation as the admin create; metadata is ignored.\nWith SIGNUP_DOUBLE_OPT_IN=true the subscriber starts out pending and is emailed a confirmation link (see /signup/subscribers/confirm).\nA captcha token (captcha_token body field or X-Captcha-Token header) is required when captcha is enabled, and a filled honeypot field is rejected.\nutm_source, utm_medium, utm_campaign and referrer record the channel the signup came from, see /admin/stats/sources; an invalid referrer is rejected with code invalid_attribution.\nref (or ?ref=) is the referral_code of the subscriber who referred the signup, see /admin/stats/referrals; unknown codes are ignored.\nSignups scoring SPAM_QUARANTINE_SCORE (70 by default) or more on the spam checks (IP velocity, gibberish name, domain reputation) are created quarantined, and emailed nothing until an admin reviews them.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "subscribers"
+                ],
+                "summary": "Sign up as a subscriber",
+                "parameters": [
+                    {
+                        "description": "Subscriber info (with subscriber_types optional)",
+                        "name": "subscriber",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateSubscriberRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SubscriberResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dto.InvalidSubscriberTypesResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/signup/subscribers/confirm": {
+            "post": {
+                "description": "Marks the subscriber behind an emailed confirmation token as active with a verified email. Tokens are single-use.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscribers"
+                ],
+                "summary": "Confirm a signup (double opt-in)",
+                "parameters": [
+                    {
+                        "description": "Token from the confirmation link",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ConfirmSubscriberRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ConfirmSubscriberResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "code: invalid_token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "code: confirmation_expired",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/signup/widget/{slug}": {
+            "get": {
+                "description": "Serves the signup form of slug (see /admin/signup-forms) for partner sites to embed, in the organization named by X-Org / ?org=.\nBy default an HTML page to show in an iframe: it may be framed by the signup origins and CORS_SIGNUP_PARTNER_ORIGINS, and forwards the utm_* and ref params of its own URL.\nformat=json returns the form's config instead, for a script of the partner site to render it, and callback wraps it in JSONP.\nThe captcha is rendered with CAPTCHA_SITE_KEY when the form requires one.",
+                "produces": [
+                    "text/html",
+                    "application/json",
+                    "application/javascript"
+                ],
+                "tags": [
+                    "subscribers"
+                ],
+                "summary": "Signup widget",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Form slug",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "enum": [
+                            "html",
+                            "json"
+                        ],
+                        "description": "",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "JSONP callback, a JavaScript identifier",
+                        "name": "callback",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SignupWidgetConfig"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/t/c/{token}": {
+            "get": {
+                "description": "The links of campaign emails point here, recording a click (and an open) of the recipient its token names, then redirecting to the link. Bots, mail security scanners and tokens older than TRACKING_TOKEN_TTL_DAYS aren't counted, but still redirected.",
+                "tags": [
+                    "tracking"
+                ],
+                "summary": "Campaign link",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token of the recipient and the link",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "302": {
+                        "description": "Found"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/t/o/{token}": {
+            "get": {
+                "description": "The 1x1 GIF at the end of campaign emails, recording an open of the recipient its token names (see /admin/campaigns/{id}/stats). Bots, mail security scanners and tokens older than TRACKING_TOKEN_TTL_DAYS aren't counted. The pixel is served whatever the token, never cached.",
+                "produces": [
+                    "image/gif"
+                ],
+                "tags": [
+                    "tracking"
+                ],
+                "summary": "Campaign open pixel",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token of the recipient",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/sendgrid": {
+            "post": {
+                "description": "Receives SendGrid's signed Event Webhook batches. Every event is stored in the subscriber's delivery history;\nhard bounces and spam reports mark subscribers bounced, unsubscribes mark them unsubscribed.\nDeferred, delivered, bounce and dropped events update the status of the email in the email log (GET /admin/emails).\nRequires SENDGRID_WEBHOOK_PUBLIC_KEY. Retried deliveries are deduplicated by sg_event_id.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "SendGrid event webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Base64 ECDSA signature",
+                        "name": "X-Twilio-Email-Event-Webhook-Signature",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Signed timestamp",
+                        "name": "X-Twilio-Email-Event-Webhook-Timestamp",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Event batch",
+                        "name": "events",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.SendGridEvent"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "code: invalid_signature",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "code: webhook_not_configured",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "dto.AddAdminUserRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "staff@example.com"
+                },
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "admin",
+                        "editor",
+                        "viewer"
+                    ],
+                    "example": "editor"
+                }
+            }
+        },
+        "dto.AdminUserResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "staff@example.com"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "org_id": {
+                    "type": "integer"
+                },
+                "role": {
+                    "type": "string",
+                    "example": "editor"
+                }
+            }
+        },
+        "dto.ApiKeyResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "org_id": {
+                    "type": "integer"
+                },
+                "prefix": {
+                    "type": "string",
+                    "example": "mylo_AbCdEfG"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "dto.BatchOperation": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "op": {
+                    "type": "string",
+                    "enum": [
+                        "create",
+                        "update",
+                        "delete"
+                    ],
+                    "example": "update"
+                },
+                "subscriber": {
+                    "$ref": "#/definitions/dto.UpdateSubscriberRequest"
+                }
+            }
+        },
+        "dto.BatchRequest": {
+            "type": "object",
+            "properties": {
+                "operations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.BatchOperation"
+                    }
+                }
+            }
+        },
+        "dto.BatchResponse": {
+            "type": "object",
+            "properties": {
+                "committed": {
+                    "type": "boolean"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.BatchResult"
+                    }
+                }
+            }
+        },
+        "dto.BatchResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "op": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "subscriber": {
+                    "$ref": "#/definitions/dto.SubscriberResponse"
+                }
+            }
+        },
+        "dto.CampaignABTestStats": {
+            "type": "object",
+            "properties": {
+                "ends_at": {
+                    "type": "string"
+                },
+                "remainder": {
+                    "$ref": "#/definitions/dto.CampaignVariantStats"
+                },
+                "test_percent": {
+                    "type": "integer",
+                    "example": 20
+                },
+                "variants": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.CampaignVariantStats"
+                    }
+                },
+                "winner": {
+                    "type": "string",
+                    "enum": [
+                        "a",
+                        "b"
+                    ],
+                    "example": "b"
+                }
+            }
+        },
+        "dto.CampaignCount": {
+            "type": "object",
+            "properties": {
+                "campaign": {
+                    "type": "string",
+                    "example": "spring-meetup"
+                },
+                "count": {
+                    "type": "integer",
+                    "example": 9
+                },
+                "source": {
+                    "type": "string",
+                    "example": "newsletter"
+                }
+            }
+        },
+        "dto.CampaignLinkStats": {
+            "type": "object",
+            "properties": {
+                "clicks": {
+                    "type": "integer",
+                    "example": 14
+                },
+                "recipients": {
+                    "type": "integer",
+                    "example": 11
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://market.example.com/this-week"
+                }
+            }
+        },
+        "dto.CampaignRequest": {
+            "type": "object",
+            "properties": {
+                "event_id": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "filter": {
+                    "$ref": "#/definitions/dto.SegmentFilter"
+                },
+                "html": {
+                    "type": "string",
+                    "example": "\u003cp\u003eHello! Here's what happened this week...\u003c/p\u003e"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Weekly roundup"
+                },
+                "recurrence": {
+                    "type": "string",
+                    "example": "0 9 * * MON"
+                },
+                "segment_id": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "send_at": {
+                    "description": "SendAt is an RFC 3339 time, or a local date and time read in Timezone",
+                    "type": "string",
+                    "example": "2025-07-01T09:00"
+                },
+                "subject": {
+                    "type": "string",
+                    "example": "This week at the market"
+                },
+                "subject_b": {
+                    "type": "string",
+                    "example": "5 things you missed this week"
+                },
+                "test_hours": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "test_percent": {
+                    "type": "integer",
+                    "example": 20
+                },
+                "text": {
+                    "type": "string",
+                    "example": "Hello! Here's what happened this week..."
+                },
+                "timezone": {
+                    "type": "string",
+                    "example": "Europe/Paris"
+                }
+            }
+        },
+        "dto.CampaignResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string",
+                    "example": "admin@example.com"
+                },
+                "error": {
+                    "type": "string",
+                    "example": "segment not found"
+                },
+                "event_id": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "filter": {
+                    "$ref": "#/definitions/dto.SegmentFilter"
+                },
+                "html": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_run_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Weekly roundup"
+                },
+                "next_run_at": {
+                    "type": "string"
+                },
+                "recurrence": {
+                    "type": "string",
+                    "example": "0 9 * * MON"
+                },
+                "run": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "segment_id": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "sent_count": {
+                    "type": "integer",
+                    "example": 1250
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "draft",
+                        "scheduled",
+                        "sending",
+                        "testing",
+                        "paused",
+                        "sent",
+                        "cancelled"
+                    ],
+                    "example": "scheduled"
+                },
+                "subject": {
+                    "type": "string",
+                    "example": "This week at the market"
+                },
+                "subject_b": {
+                    "type": "string",
+                    "example": "5 things you missed this week"
+                },
+                "test_ends_at": {
+                    "description": "TestEndsAt is when the A/B test of the current run picks its winner, the subject sent to the rest of the audience (\"a\" is subject, \"b\" subject_b)",
+                    "type": "string"
+                },
+                "test_hours": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "test_percent": {
+                    "type": "integer",
+                    "example": 20
+                },
+                "text": {
+                    "type": "string"
+                },
+                "timezone": {
+                    "type": "string",
+                    "example": "Europe/Paris"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "winner": {
+                    "type": "string",
+                    "enum": [
+                        "a",
+                        "b"
+                    ],
+                    "example": "b"
+                }
+            }
+        },
+        "dto.CampaignSendResponse": {
+            "type": "object",
+            "properties": {
+                "campaign_id": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "clicked_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "ada@example.com"
+                },
+                "opened_at": {
+                    "type": "string"
+                },
+                "run": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "sent_at": {
+                    "type": "string"
+                },
+                "test": {
+                    "type": "boolean"
+                },
+                "variant": {
+                    "type": "string",
+                    "enum": [
+                        "a",
+                        "b"
+                    ],
+                    "example": "a"
+                }
+            }
+        },
+        "dto.CampaignShortLinkStats": {
+            "type": "object",
+            "properties": {
+                "clicks": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "code": {
+                    "type": "string",
+                    "example": "spring-market"
+                },
+                "short_url": {
+                    "type": "string",
+                    "example": "https://go.mylocal.ing/l/spring-market"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://market.example.org/spring"
+                }
+            }
+        },
+        "dto.CampaignStatsResponse": {
+            "type": "object",
+            "properties": {
+                "ab_test": {
+                    "$ref": "#/definitions/dto.CampaignABTestStats"
+                },
+                "campaign_id": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "click_rate": {
+                    "type": "number",
+                    "example": 0.08
+                },
+                "clicked": {
+                    "type": "integer",
+                    "example": 20
+                },
+                "links": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.CampaignLinkStats"
+                    }
+                },
+                "open_rate": {
+                    "type": "number",
+                    "example": 0.32
+                },
+                "opened": {
+                    "type": "integer",
+                    "example": 80
+                },
+                "run": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "sent": {
+                    "type": "integer",
+                    "example": 250
+                },
+                "short_links": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.CampaignShortLinkStats"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "testing"
+                },
+                "total_sent": {
+                    "type": "integer",
+                    "example": 1250
+                }
+            }
+        },
+        "dto.CampaignVariantStats": {
+            "type": "object",
+            "properties": {
+                "click_rate": {
+                    "type": "number",
+                    "example": 0.08
+                },
+                "clicked": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "open_rate": {
+                    "type": "number",
+                    "example": 0.32
+                },
+                "opened": {
+                    "type": "integer",
+                    "example": 40
+                },
+                "sent": {
+                    "type": "integer",
+                    "example": 125
+                },
+                "subject": {
+                    "type": "string",
+                    "example": "This week at the market"
+                },
+                "variant": {
+                    "type": "string",
+                    "enum": [
+                        "a",
+                        "b"
+                    ],
+                    "example": "a"
+                }
+            }
+        },
+        "dto.ConfirmSubscriberRequest": {
+            "type": "object",
+            "properties": {
+                "token": {
+                    "type": "string",
+                    "example": "kq3X...emailed-token"
+                }
+            }
+        },
+        "dto.ConfirmSubscriberResponse": {
+            "type": "object",
+            "properties": {
+                "email_verified_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "active"
+                }
+            }
+        },
+        "dto.CreateApiKeyRequest": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "nightly export"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "read"
+                    ]
+                }
+            }
+        },
+        "dto.CreateExportRequest": {
+            "type": "object",
+            "properties": {
+                "filters": {
+                    "$ref": "#/definitions/dto.ExportFilters"
+                },
+                "format": {
+                    "type": "string",
+                    "enum": [
+                        "csv",
+                        "json"
+                    ],
+                    "example": "csv"
+                },
+                "segment_id": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "dto.CreateRestHookRequest": {
+            "type": "object",
+            "properties": {
+                "event": {
+                    "type": "string",
+                    "enum": [
+                        "subscriber.created",
+                        "subscriber.updated",
+                        "subscriber.deleted"
+                    ],
+                    "example": "subscriber.created"
+                },
+                "target_url": {
+                    "description": "TargetURL receives a POST of every event; it must be https",
+                    "type": "string",
+                    "example": "https://hooks.zapier.com/hooks/standard/123/abc/"
+                }
+            }
+        },
+        "dto.CreateSubscriberNoteRequest": {
+            "type": "object",
+            "properties": {
+                "text": {
+                    "type": "string",
+                    "example": "Asked to only receive the monthly digest"
+                }
+            }
+        },
+        "dto.CreateSubscriberRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "user@example.com"
+                },
+                "locale": {
+                    "description": "Locale is the language of the emails the subscriber gets; a public signup without one uses the request's Accept-Language, an admin create en",
+                    "type": "string",
+                    "enum": [
+                        "en",
+                        "fr",
+                        "es"
+                    ],
+                    "example": "fr"
+                },
+                "metadata": {
+                    "description": "Metadata is only stored for admin requests; public signups can't set it",
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Jane Doe"
+                },
+                "ref": {
+                    "description": "Ref is the referral_code of the subscriber who referred a public signup, also read from ?ref=; admin creates ignore it",
+                    "type": "string",
+                    "example": "k7m2q9xw4d"
+                },
+                "referrer": {
+                    "type": "string",
+                    "example": "https://forum.example.org/t/meetup"
+                },
+                "subscriber_types": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.SubscriberTypeRequest"
+                    }
+                },
+                "utm_campaign": {
+                    "type": "string",
+                    "example": "spring-meetup"
+                },
+                "utm_medium": {
+                    "type": "string",
+                    "example": "email"
+                },
+                "utm_source": {
+                    "description": "UTMSource, UTMMedium, UTMCampaign and Referrer attribute a public signup to the channel it came from (see GET /admin/stats/sources); admin creates ignore them. Only the host of the referrer is kept.",
+                    "type": "string",
+                    "example": "newsletter"
+                }
+            }
+        },
+        "dto.CreateSubscriberTypeRequest": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "Helps out at local events"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "volunteer"
+                }
+            }
+        },
+        "dto.CreatedApiKeyResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "org_id": {
+                    "type": "integer"
+                },
+                "prefix": {
+                    "type": "string",
+                    "example": "mylo_AbCdEfG"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "dto.DeliveryEventResponse": {
+            "type": "object",
+            "properties": {
+                "event": {
+                    "type": "string",
+                    "example": "bounce"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "occurred_at": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "550 5.1.1 The email account does not exist"
+                },
+                "sg_message_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.EmailAttachmentResponse": {
+            "type": "object",
+            "properties": {
+                "content_type": {
+                    "type": "string",
+                    "example": "text/calendar; method=REQUEST"
+                },
+                "filename": {
+                    "type": "string",
+                    "example": "market-day.ics"
+                },
+                "sha256": {
+                    "type": "string",
+                    "example": "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+                },
+                "size": {
+                    "type": "integer",
+                    "example": 912
+                }
+            }
+        },
+        "dto.EmailDeadLetterResponse": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string",
+                    "enum": [
+                        "signin_code",
+                        "magic_link"
+                    ],
+                    "example": "signin_code"
+                },
+                "last_error": {
+                    "type": "string",
+                    "example": "sendgrid temporarily unavailable: sendgrid returned server error (503): "
+                },
+                "outbox_event_id": {
+                    "type": "integer"
+                },
+                "to_email": {
+                    "type": "string",
+                    "example": "ada@example.com"
+                }
+            }
+        },
+        "dto.EmailLogResponse": {
+            "type": "object",
+            "properties": {
+                "attachments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.EmailAttachmentResponse"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "description": "Error is why the send failed, left out for callers without the pii scope",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "message_id": {
+                    "description": "MessageID is SendGrid's X-Message-Id, empty when the send failed",
+                    "type": "string",
+                    "example": "W86EgYT6SQKk0lRflfLRsA"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "sent",
+                        "failed",
+                        "deferred",
+                        "delivered",
+                        "bounce",
+                        "dropped"
+                    ],
+                    "example": "delivered"
+                },
+                "to_email": {
+                    "description": "ToEmail is masked for callers without the pii scope",
+                    "type": "string",
+                    "example": "ada@example.com"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "signin_code",
+                        "magic_link",
+                        "invitation",
+                        "confirmation",
+                        "preferences",
+                        "admin_notification",
+                        "campaign",
+                        "template_test"
+                    ],
+                    "example": "confirmation"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.EmailTemplatePreviewResponse": {
+            "type": "object",
+            "properties": {
+                "html": {
+                    "type": "string",
+                    "example": "\u003cp\u003eHi Jane Doe, the market opens at 9.\u003c/p\u003e"
+                },
+                "subject": {
+                    "type": "string",
+                    "example": "See you Saturday, Jane"
+                },
+                "text": {
+                    "type": "string",
+                    "example": "Hi Jane Doe, the market opens at 9."
+                }
+            }
+        },
+        "dto.EmailTemplateRenderRequest": {
+            "type": "object",
+            "properties": {
+                "variables": {
+                    "type": "object",
+                    "additionalProperties": true
+                }
+            }
+        },
+        "dto.EmailTemplateRequest": {
+            "type": "object",
+            "properties": {
+                "format": {
+                    "type": "string",
+                    "enum": [
+                        "html",
+                        "markdown",
+                        "mjml"
+                    ],
+                    "example": "markdown"
+                },
+                "html": {
+                    "type": "string",
+                    "example": "\u003cp\u003eHi {{.Name}}, the market opens at 9.\u003c/p\u003e"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Market day"
+                },
+                "source": {
+                    "type": "string",
+                    "example": "Hi **{{.Name}}**, the market opens at 9."
+                },
+                "subject": {
+                    "type": "string",
+                    "example": "See you Saturday, {{.FirstName}}"
+                },
+                "text": {
+                    "type": "string",
+                    "example": "Hi {{.Name}}, the market opens at 9."
+                }
+            }
+        },
+        "dto.EmailTemplateResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string",
+                    "example": "admin@example.com"
+                },
+                "format": {
+                    "type": "string",
+                    "enum": [
+                        "html",
+                        "markdown",
+                        "mjml"
+                    ],
+                    "example": "markdown"
+                },
+                "html": {
+                    "type": "string",
+                    "example": "\u003cp\u003eHi {{.Name}}, the market opens at 9.\u003c/p\u003e"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Market day"
+                },
+                "source": {
+                    "type": "string",
+                    "example": "Hi **{{.Name}}**, the market opens at 9."
+                },
+                "subject": {
+                    "type": "string",
+                    "example": "See you Saturday, {{.FirstName}}"
+                },
+                "text": {
+                    "type": "string",
+                    "example": "Hi {{.Name}}, the market opens at 9."
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.EmailTemplateTestSendResponse": {
+            "type": "object",
+            "properties": {
+                "subject": {
+                    "type": "string",
+                    "example": "[Test] See you Saturday, Jane"
+                },
+                "to": {
+                    "type": "string",
+                    "example": "admin@example.com"
+                }
+            }
+        },
+        "dto.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Code is a stable machine-readable identifier, only set for errors clients are expected to branch on",
+                    "type": "string",
+                    "example": "too_many_attempts"
+                },
+                "error": {
+                    "type": "string",
+                    "example": "Invalid request body"
+                }
+            }
+        },
+        "dto.EventRequest": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "Fresh produce, crafts and music on the square."
+                },
+                "ends_at": {
+                    "type": "string",
+                    "example": "2025-07-05T14:00"
+                },
+                "location": {
+                    "type": "string",
+                    "example": "Place du Marché, Lyon"
+                },
+                "starts_at": {
+                    "type": "string",
+                    "example": "2025-07-05T09:00"
+                },
+                "timezone": {
+                    "type": "string",
+                    "example": "Europe/Paris"
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Summer market day"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://market.example.org/summer"
+                }
+            }
+        },
+        "dto.EventResponse": {
+            "type": "object",
+            "properties": {
+                "calendar_url": {
+                    "description": "CalendarURL is the public link to its invite, under TRACKING_BASE_URL",
+                    "type": "string",
+                    "example": "https://api.mylocal.ing/events/7/calendar.ics"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string",
+                    "example": "admin@example.com"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Fresh produce, crafts and music on the square."
+                },
+                "ends_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "location": {
+                    "type": "string",
+                    "example": "Place du Marché, Lyon"
+                },
+                "sequence": {
+                    "description": "Sequence counts the updates of the event, for calendars to replace its invite",
+                    "type": "integer",
+                    "example": 1
+                },
+                "starts_at": {
+                    "type": "string"
+                },
+                "timezone": {
+                    "type": "string",
+                    "example": "Europe/Paris"
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Summer market day"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://market.example.org/summer"
+                }
+            }
+        },
+        "dto.ExportFilters": {
+            "type": "object",
+            "properties": {
+                "channel": {
+                    "type": "string",
+                    "enum": [
+                        "email",
+                        "sms"
+                    ],
+                    "example": "email"
+                },
+                "frequency": {
+                    "type": "string",
+                    "enum": [
+                        "daily",
+                        "weekly",
+                        "monthly"
+                    ],
+                    "example": "monthly"
+                },
+                "query": {
+                    "description": "Query is a filter expression, like the filter parameter of GET /admin/subscribers",
+                    "type": "string",
+                    "example": "type:shopper AND NOT tag:vip"
+                },
+                "status": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "active",
+                        "pending"
+                    ]
+                },
+                "subscriber_type": {
+                    "type": "string",
+                    "example": "donor"
+                },
+                "verified": {
+                    "type": "string",
+                    "enum": [
+                        "true",
+                        "false"
+                    ],
+                    "example": "true"
+                }
+            }
+        },
+        "dto.ExportJobResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "download_expires_at": {
+                    "type": "string"
+                },
+                "download_url": {
+                    "description": "DownloadURL is a signed link to the file, valid until DownloadExpiresAt",
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "description": "ExpiresAt is when the file is deleted",
+                    "type": "string"
+                },
+                "filters": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "format": {
+                    "type": "string",
+                    "enum": [
+                        "csv",
+                        "json"
+                    ],
+                    "example": "csv"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "org_id": {
+                    "type": "integer"
+                },
+                "requested_by": {
+                    "type": "string",
+                    "example": "admin@example.com"
+                },
+                "row_count": {
+                    "type": "integer",
+                    "example": 1250
+                },
+                "segment_id": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "pending",
+                        "running",
+                        "done",
+                        "failed"
+                    ],
+                    "example": "done"
+                }
+            }
+        },
+        "dto.FilterError": {
+            "type": "object",
+            "properties": {
+                "length": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Unknown field tga"
+                },
+                "position": {
+                    "type": "integer",
+                    "example": 35
+                }
+            }
+        },
+        "dto.FilterErrorResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "invalid_filter"
+                },
+                "error": {
+                    "type": "string",
+                    "example": "Unknown field tga"
+                },
+                "length": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "position": {
+                    "type": "integer",
+                    "example": 35
+                }
+            }
+        },
+        "dto.FilterValidationRequest": {
+            "type": "object",
+            "properties": {
+                "query": {
+                    "type": "string",
+                    "example": "type:shopper AND created_at\u003e2024-01-01 AND NOT tag:vip"
+                }
+            }
+        },
+        "dto.FilterValidationResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "$ref": "#/definitions/dto.FilterError"
+                },
+                "valid": {
+                    "type": "boolean",
+                    "example": false
+                }
+            }
+        },
+        "dto.GDPRExport": {
+            "type": "object",
+            "properties": {
+                "campaign_sends": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.CampaignSendResponse"
+                    }
+                },
+                "delivery_events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.DeliveryEventResponse"
+                    }
+                },
+                "emails": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.EmailLogResponse"
+                    }
+                },
+                "generated_at": {
+                    "type": "string"
+                },
+                "notes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.SubscriberNoteResponse"
+                    }
+                },
+                "passkeys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.PasskeySummary"
+                    }
+                },
+                "sessions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.SessionResponse"
+                    }
+                },
+                "subscriber": {
+                    "$ref": "#/definitions/dto.SubscriberResponse"
+                }
+            }
+        },
+        "dto.GeoCount": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "example": 18
+                },
+                "country": {
+                    "type": "string",
+                    "example": "NZ"
+                },
+                "region": {
+                    "type": "string",
+                    "example": "AUK"
+                }
+            }
+        },
+        "dto.GeoStatsResponse": {
+            "type": "object",
+            "properties": {
+                "activity_by_country": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.GeoCount"
+                    }
+                },
+                "generated_at": {
+                    "type": "string"
+                },
+                "geoip_enabled": {
+                    "type": "boolean"
+                },
+                "signups_by_country": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.GeoCount"
+                    }
+                },
+                "signups_by_region": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.GeoCount"
+                    }
+                }
+            }
+        },
+        "dto.GraphQLError": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "Invalid status: gone"
+                },
+                "path": {
+                    "type": "array",
+                    "items": {}
+                }
+            }
+        },
+        "dto.GraphQLRequest": {
+            "type": "object",
+            "properties": {
+                "operationName": {
+                    "type": "string"
+                },
+                "query": {
+                    "type": "string",
+                    "example": "{ stats { totalSubscribers } }"
+                },
+                "variables": {
+                    "type": "object",
+                    "additionalProperties": true
+                }
+            }
+        },
+        "dto.GraphQLResponse": {
+            "type": "object",
+            "properties": {
+                "data": {},
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.GraphQLError"
+                    }
+                }
+            }
+        },
+        "dto.GrowthPoint": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "period": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.ImportJobResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_rows": {
+                    "type": "integer",
+                    "example": 480
+                },
+                "error": {
+                    "type": "string"
+                },
+                "error_report_expires_at": {
+                    "type": "string"
+                },
+                "error_report_url": {
+                    "description": "ErrorReportURL is a signed link to a CSV of the failed rows (row, email, error), valid until ErrorReportExpiresAt",
+                    "type": "string"
+                },
+                "expires_at": {
+                    "description": "ExpiresAt is when the job and its error report are deleted",
+                    "type": "string"
+                },
+                "failed_rows": {
+                    "description": "FailedRows are the errors so far, listed in the error report",
+                    "type": "integer",
+                    "example": 8
+                },
+                "file_name": {
+                    "type": "string",
+                    "example": "subscribers.csv"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "format": {
+                    "type": "string",
+                    "enum": [
+                        "csv",
+                        "json"
+                    ],
+                    "example": "csv"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "org_id": {
+                    "type": "integer"
+                },
+                "processed_rows": {
+                    "type": "integer",
+                    "example": 500
+                },
+                "requested_by": {
+                    "type": "string",
+                    "example": "admin@example.com"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "pending",
+                        "running",
+                        "done",
+                        "failed"
+                    ],
+                    "example": "running"
+                },
+                "total_rows": {
+                    "description": "TotalRows is known once the worker has read the file through",
+                    "type": "integer",
+                    "example": 1250
+                },
+                "updated_rows": {
+                    "type": "integer",
+                    "example": 12
+                }
+            }
+        },
+        "dto.IntegrationListRequest": {
+            "type": "object",
+            "properties": {
+                "remote_id": {
+                    "description": "RemoteID is the id of the list at the provider (the audience id of Mailchimp)",
+                    "type": "string",
+                    "example": "a1b2c3d4e5"
+                },
+                "subscriber_type": {
+                    "description": "SubscriberType limits the list to the subscribers with that subscriber_type",
+                    "type": "string",
+                    "example": "donor"
+                }
+            }
+        },
+        "dto.IntegrationListResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "pulled_at": {
+                    "type": "string"
+                },
+                "pushed_at": {
+                    "type": "string"
+                },
+                "remote_id": {
+                    "type": "string",
+                    "example": "a1b2c3d4e5"
+                },
+                "subscriber_type": {
+                    "type": "string",
+                    "example": "donor"
+                }
+            }
+        },
+        "dto.IntegrationResponse": {
+            "type": "object",
+            "properties": {
+                "api_key_hint": {
+                    "description": "APIKeyHint is the end of the API key",
+                    "type": "string",
+                    "example": "…us21"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string",
+                    "example": "admin@example.com"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "last_sync_at": {
+                    "type": "string"
+                },
+                "lists": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.IntegrationListResponse"
+                    }
+                },
+                "org_id": {
+                    "type": "integer"
+                },
+                "provider": {
+                    "type": "string",
+                    "enum": [
+                        "mailchimp"
+                    ],
+                    "example": "mailchimp"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.InvalidSubscriberTypesResponse": {
+            "type": "object",
+            "properties": {
+                "accepted": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "business",
+                        "champion",
+                        "developer",
+                        "donor",
+                        "driver",
+                        "shopper"
+                    ]
+                },
+                "code": {
+                    "type": "string",
+                    "example": "invalid_subscriber_type"
+                },
+                "error": {
+                    "type": "string",
+                    "example": "unknown subscriber_type shoper, accepted are business, champion, developer, donor, driver, shopper"
+                },
+                "invalid": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "shoper"
+                    ]
+                }
+            }
+        },
+        "dto.InvitationPreviewResponse": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "staff@example.com"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "organization_name": {
+                    "type": "string",
+                    "example": "myLocal Springfield"
+                },
+                "role": {
+                    "type": "string",
+                    "example": "editor"
+                }
+            }
+        },
+        "dto.InvitationResponse": {
+            "type": "object",
+            "properties": {
+                "accepted_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "staff@example.com"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "invited_by": {
+                    "type": "string"
+                },
+                "org_id": {
+                    "type": "integer"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string",
+                    "example": "editor"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "pending",
+                        "accepted",
+                        "revoked",
+                        "expired"
+                    ],
+                    "example": "pending"
+                }
+            }
+        },
+        "dto.InviteAdminRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "staff@example.com"
+                },
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "admin",
+                        "editor",
+                        "viewer"
+                    ],
+                    "example": "editor"
+                }
+            }
+        },
+        "dto.JWK": {
+            "type": "object",
+            "properties": {
+                "alg": {
+                    "type": "string",
+                    "example": "EdDSA"
+                },
+                "crv": {
+                    "type": "string",
+                    "example": "Ed25519"
+                },
+                "e": {
+                    "type": "string"
+                },
+                "kid": {
+                    "type": "string",
+                    "example": "3kZ0n1JZ7qB5v2Y0a6sXh8W4cD9eF1gH2iJ3kL4mN5o"
+                },
+                "kty": {
+                    "type": "string",
+                    "example": "OKP"
+                },
+                "n": {
+                    "type": "string"
+                },
+                "use": {
+                    "type": "string",
+                    "example": "sig"
+                },
+                "x": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.JWKSResponse": {
+            "type": "object",
+            "properties": {
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.JWK"
+                    }
+                }
+            }
+        },
+        "dto.MergeSubscribersRequest": {
+            "type": "object",
+            "properties": {
+                "email_rule": {
+                    "type": "string",
+                    "enum": [
+                        "target",
+                        "source",
+                        "verified"
+                    ],
+                    "example": "verified"
+                },
+                "source_id": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "dto.MessageResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.OrganizationRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "myLocal Springfield"
+                },
+                "slug": {
+                    "type": "string",
+                    "example": "springfield"
+                }
+            }
+        },
+        "dto.OrganizationResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "myLocal Springfield"
+                },
+                "slug": {
+                    "type": "string",
+                    "example": "springfield"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.PasskeySummary": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.PreferencesLinkResponse": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://signup.mylocal.ing/preferences/kq3X...token"
+                }
+            }
+        },
+        "dto.PreferencesResponse": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "j***@example.com"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Jane Doe"
+                },
+                "referral_code": {
+                    "description": "ReferralCode is passed as ?ref= on the signup links the subscriber shares",
+                    "type": "string",
+                    "example": "k7m2q9xw4d"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "pending",
+                        "active",
+                        "bounced",
+                        "unsubscribed",
+                        "quarantined"
+                    ],
+                    "example": "active"
+                },
+                "subscribed": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "subscriber_types": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.SubscriberTypePreferences"
+                    }
+                }
+            }
+        },
+        "dto.ReferralStatsResponse": {
+            "type": "object",
+            "properties": {
+                "active_referred": {
+                    "type": "integer",
+                    "example": 35
+                },
+                "generated_at": {
+                    "type": "string"
+                },
+                "leaderboard": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ReferrerCount"
+                    }
+                },
+                "referrers": {
+                    "type": "integer",
+                    "example": 9
+                },
+                "total_referred": {
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
+        "dto.ReferralSubscriber": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "user@example.com"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Jane Doe"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "pending",
+                        "active",
+                        "bounced",
+                        "unsubscribed",
+                        "quarantined"
+                    ],
+                    "example": "active"
+                }
+            }
+        },
+        "dto.ReferrerCount": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "integer",
+                    "example": 6
+                },
+                "email": {
+                    "type": "string",
+                    "example": "user@example.com"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Jane Doe"
+                },
+                "referral_code": {
+                    "type": "string",
+                    "example": "k7m2q9xw4d"
+                },
+                "referrals": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "subscriber_id": {
+                    "type": "integer",
+                    "example": 12
+                }
+            }
+        },
+        "dto.RememberEmailRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "user@example.com"
+                }
+            }
+        },
+        "dto.RememberedEmailResponse": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "user@example.com"
+                }
+            }
+        },
+        "dto.RequestPreferencesLinkRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "user@example.com"
+                }
+            }
+        },
+        "dto.RestHookPayload": {
+            "type": "object",
+            "properties": {
+                "event": {
+                    "type": "string",
+                    "enum": [
+                        "subscriber.created",
+                        "subscriber.updated",
+                        "subscriber.deleted"
+                    ],
+                    "example": "subscriber.created"
+                },
+                "id": {
+                    "description": "ID is the same on every retry of a delivery, so targets can drop duplicates (the subscriber's id in samples)",
+                    "type": "integer",
+                    "example": 1042
+                },
+                "occurred_at": {
+                    "type": "string"
+                },
+                "subscriber": {
+                    "$ref": "#/definitions/dto.SubscriberResponse"
+                }
+            }
+        },
+        "dto.RestHookResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string",
+                    "example": "zapier@example.com"
+                },
+                "event": {
+                    "type": "string",
+                    "enum": [
+                        "subscriber.created",
+                        "subscriber.updated",
+                        "subscriber.deleted"
+                    ],
+                    "example": "subscriber.created"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "target_url": {
+                    "type": "string",
+                    "example": "https://hooks.zapier.com/hooks/standard/123/abc/"
+                }
+            }
+        },
+        "dto.ReviewQueueItem": {
+            "type": "object",
+            "properties": {
+                "anonymized_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "user@example.com"
+                },
+                "email_verified_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "locale": {
+                    "type": "string",
+                    "enum": [
+                        "en",
+                        "fr",
+                        "es"
+                    ],
+                    "example": "en"
+                },
+                "metadata": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Jane Doe"
+                },
+                "notes": {
+                    "description": "Notes are only embedded on ?include=notes, newest first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.SubscriberNoteResponse"
+                    }
+                },
+                "org_id": {
+                    "type": "integer"
+                },
+                "referral_code": {
+                    "description": "ReferralCode is shared by the subscriber as the ref of the signups they refer",
+                    "type": "string",
+                    "example": "k7m2q9xw4d"
+                },
+                "referred_by_id": {
+                    "description": "ReferredByID is the subscriber whose referral code they signed up with",
+                    "type": "integer",
+                    "example": 12
+                },
+                "spam_score": {
+                    "type": "integer",
+                    "example": 90
+                },
+                "spam_signals": {
+                    "description": "SpamSignals are the points of each check that fired, by check name",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    },
+                    "example": "ip_velocity:50,gibberish_name:40"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "pending",
+                        "active",
+                        "bounced",
+                        "unsubscribed",
+                        "quarantined"
+                    ],
+                    "example": "active"
+                },
+                "subscriber_types": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.SubscriberTypeResponse"
+                    }
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "dto.SegmentFilter": {
+            "type": "object",
+            "properties": {
+                "channel": {
+                    "type": "string",
+                    "enum": [
+                        "email",
+                        "sms"
+                    ],
+                    "example": "email"
+                },
+                "created_after": {
+                    "description": "CreatedAfter and CreatedBefore bound the signup date, inclusive and exclusive",
+                    "type": "string",
+                    "example": "2025-01-01T00:00:00Z"
+                },
+                "created_before": {
+                    "type": "string",
+                    "example": "2025-07-01T00:00:00Z"
+                },
+                "frequency": {
+                    "type": "string",
+                    "enum": [
+                        "daily",
+                        "weekly",
+                        "monthly"
+                    ],
+                    "example": "monthly"
+                },
+                "query": {
+                    "description": "Query is a filter expression, see POST /admin/segments/validate",
+                    "type": "string",
+                    "example": "type:shopper AND created_at\u003e2024-01-01 AND NOT tag:vip"
+                },
+                "status": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "active"
+                    ]
+                },
+                "subscriber_type": {
+                    "type": "string",
+                    "example": "donor"
+                },
+                "subscriber_types": {
+                    "description": "SubscriberTypes keeps subscribers holding any of them",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "shopper",
+                        "volunteer"
+                    ]
+                },
+                "tags": {
+                    "description": "Tags keeps subscribers with any of them in the \"tags\" array of their metadata",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "vip",
+                        "market-2025"
+                    ]
+                },
+                "verified": {
+                    "type": "string",
+                    "enum": [
+                        "true",
+                        "false"
+                    ],
+                    "example": "true"
+                }
+            }
+        },
+        "dto.SegmentPreviewResponse": {
+            "type": "object",
+            "properties": {
+                "by_status": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.StatusCount"
+                    }
+                },
+                "generated_at": {
+                    "type": "string"
+                },
+                "members": {
+                    "type": "integer",
+                    "example": 342
+                }
+            }
+        },
+        "dto.SegmentRequest": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "Donors who signed up at the summer markets"
+                },
+                "filter": {
+                    "$ref": "#/definitions/dto.SegmentFilter"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Summer donors"
+                }
+            }
+        },
+        "dto.SegmentResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string",
+                    "example": "admin@example.com"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Donors who signed up at the summer markets"
+                },
+                "filter": {
+                    "$ref": "#/definitions/dto.SegmentFilter"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Summer donors"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.SendGridEvent": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "event": {
+                    "type": "string",
+                    "example": "bounce"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "sg_event_id": {
+                    "type": "string"
+                },
+                "sg_message_id": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "integer",
+                    "example": 1700000000
+                },
+                "type": {
+                    "description": "bounce events: \"bounce\" (hard) or \"blocked\" (soft)",
+                    "type": "string",
+                    "example": "bounce"
+                }
+            }
+        },
+        "dto.SessionCount": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "name": {
+                    "type": "string",
+                    "example": "mobile"
+                }
+            }
+        },
+        "dto.SessionResponse": {
+            "type": "object",
+            "properties": {
+                "browser": {
+                    "type": "string",
+                    "example": "Firefox"
+                },
+                "country": {
+                    "type": "string",
+                    "example": "NZ"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "current": {
+                    "type": "boolean"
+                },
+                "device": {
+                    "type": "string",
+                    "example": "desktop"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "ip": {
+                    "type": "string"
+                },
+                "last_seen_at": {
+                    "type": "string"
+                },
+                "os": {
+                    "type": "string",
+                    "example": "macOS"
+                },
+                "region": {
+                    "type": "string",
+                    "example": "AUK"
+                },
+                "user_agent": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.SessionStatsResponse": {
+            "type": "object",
+            "properties": {
+                "active_sessions": {
+                    "type": "integer",
+                    "example": 30
+                },
+                "by_browser": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.SessionCount"
+                    }
+                },
+                "by_country": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.SessionCount"
+                    }
+                },
+                "by_device": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.SessionCount"
+                    }
+                },
+                "by_os": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.SessionCount"
+                    }
+                },
+                "generated_at": {
+                    "type": "string"
+                },
+                "geoip_enabled": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "dto.ShortLinkRequest": {
+            "type": "object",
+            "properties": {
+                "campaign_id": {
+                    "description": "CampaignID attributes the clicks of the link to a campaign of the organization",
+                    "type": "integer",
+                    "example": 12
+                },
+                "code": {
+                    "description": "Code is the path of the link under /l/, generated when omitted on create",
+                    "type": "string",
+                    "example": "spring-market"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://market.example.org/spring?utm_source=newsletter"
+                }
+            }
+        },
+        "dto.ShortLinkResponse": {
+            "type": "object",
+            "properties": {
+                "campaign_id": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "clicks": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "code": {
+                    "type": "string",
+                    "example": "spring-market"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string",
+                    "example": "admin@example.com"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_clicked_at": {
+                    "type": "string"
+                },
+                "short_url": {
+                    "description": "ShortURL is the link to hand out, under SHORT_LINK_BASE_URL",
+                    "type": "string",
+                    "example": "https://go.mylocal.ing/l/spring-market"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://market.example.org/spring?utm_source=newsletter"
+                }
+            }
+        },
+        "dto.SignInRequest": {
+            "type": "object",
+            "properties": {
+                "channel": {
+                    "type": "string",
+                    "enum": [
+                        "email",
+                        "sms"
+                    ],
+                    "example": "email"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "user@example.com"
+                },
+                "method": {
+                    "type": "string",
+                    "enum": [
+                        "code",
+                        "link"
+                    ],
+                    "example": "code"
+                },
+                "phone": {
+                    "type": "string",
+                    "example": "+15551234567"
+                }
+            }
+        },
+        "dto.SignupFormField": {
+            "type": "object",
+            "properties": {
+                "label": {
+                    "type": "string",
+                    "example": "Postcode"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "postcode"
+                },
+                "options": {
+                    "description": "the values of a select",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "north",
+                        "south"
+                    ]
+                },
+                "required": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "text",
+                        "number",
+                        "checkbox",
+                        "select"
+                    ],
+                    "example": "text"
+                }
+            }
+        },
+        "dto.SignupFormRequest": {
+            "type": "object",
+            "properties": {
+                "captcha": {
+                    "description": "Captcha requires a captcha token when CAPTCHA_PROVIDER is set, true when omitted",
+                    "type": "boolean",
+                    "example": true
+                },
+                "fields": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.SignupFormField"
+                    }
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Farmers market stand"
+                },
+                "slug": {
+                    "type": "string",
+                    "example": "farmers-market"
+                },
+                "subscriber_types": {
+                    "description": "SubscriberTypes a submission may pick among, all of them when it doesn't pick",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "shopper",
+                        "volunteer"
+                    ]
+                },
+                "success_redirect": {
+                    "description": "SuccessRedirect is where browsers posting the form as HTML are sent once subscribed",
+                    "type": "string",
+                    "example": "https://market.example.org/thanks"
+                }
+            }
+        },
+        "dto.SignupFormResponse": {
+            "type": "object",
+            "properties": {
+                "captcha": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string",
+                    "example": "admin@example.com"
+                },
+                "fields": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.SignupFormField"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Farmers market stand"
+                },
+                "slug": {
+                    "type": "string",
+                    "example": "farmers-market"
+                },
+                "subscriber_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "shopper",
+                        "volunteer"
+                    ]
+                },
+                "success_redirect": {
+                    "type": "string",
+                    "example": "https://market.example.org/thanks"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.SignupFormSubmission": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "user@example.com"
+                },
+                "fields": {
+                    "description": "Fields holds the values of the custom fields of the form, by name",
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "locale": {
+                    "type": "string",
+                    "enum": [
+                        "en",
+                        "fr",
+                        "es"
+                    ],
+                    "example": "fr"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Jane Doe"
+                },
+                "ref": {
+                    "type": "string",
+                    "example": "k7m2q9xw4d"
+                },
+                "referrer": {
+                    "type": "string",
+                    "example": "https://forum.example.org/t/meetup"
+                },
+                "subscriber_types": {
+                    "description": "SubscriberTypes picks among those of the form, all of them when empty",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "shopper"
+                    ]
+                },
+                "utm_campaign": {
+                    "type": "string",
+                    "example": "spring-meetup"
+                },
+                "utm_medium": {
+                    "type": "string",
+                    "example": "email"
+                },
+                "utm_source": {
+                    "type": "string",
+                    "example": "newsletter"
+                }
+            }
+        },
+        "dto.SignupFormSubmitResponse": {
+            "type": "object",
+            "properties": {
+                "redirect_url": {
+                    "type": "string",
+                    "example": "https://market.example.org/thanks"
+                },
+                "status": {
+                    "description": "Status is the subscriber's: pending until the emailed link is opened with double opt-in",
+                    "type": "string",
+                    "example": "active"
+                }
+            }
+        },
+        "dto.SignupWidgetCaptcha": {
+            "type": "object",
+            "properties": {
+                "provider": {
+                    "type": "string",
+                    "enum": [
+                        "hcaptcha",
+                        "turnstile"
+                    ],
+                    "example": "turnstile"
+                },
+                "script": {
+                    "type": "string",
+                    "example": "https://challenges.cloudflare.com/turnstile/v0/api.js"
+                },
+                "site_key": {
+                    "type": "string",
+                    "example": "0x4AAAAAAA..."
+                }
+            }
+        },
+        "dto.SignupWidgetConfig": {
+            "type": "object",
+            "properties": {
+                "captcha": {
+                    "description": "Captcha is set when submissions need a captcha token",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.SignupWidgetCaptcha"
+                        }
+                    ]
+                },
+                "fields": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.SignupFormField"
+                    }
+                },
+                "honeypot_field": {
+                    "description": "HoneypotField is a field to render hidden and post empty",
+                    "type": "string",
+                    "example": "website"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Farmers market stand"
+                },
+                "slug": {
+                    "type": "string",
+                    "example": "farmers-market"
+                },
+                "submit_url": {
+                    "description": "SubmitURL is where the form is posted, see POST /signup/forms/{slug}/submit",
+                    "type": "string",
+                    "example": "https://api.mylocal.ing/signup/forms/farmers-market/submit?org=market"
+                },
+                "subscriber_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "shopper",
+                        "volunteer"
+                    ]
+                }
+            }
+        },
+        "dto.SourceCount": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "example": 25
+                },
+                "name": {
+                    "type": "string",
+                    "example": "newsletter"
+                }
+            }
+        },
+        "dto.SourceStatsResponse": {
+            "type": "object",
+            "properties": {
+                "by_campaign": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.CampaignCount"
+                    }
+                },
+                "by_medium": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.SourceCount"
+                    }
+                },
+                "by_referrer": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.SourceCount"
+                    }
+                },
+                "by_source": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.SourceCount"
+                    }
+                },
+                "generated_at": {
+                    "type": "string"
+                },
+                "total_signups": {
+                    "type": "integer",
+                    "example": 120
+                }
+            }
+        },
+        "dto.StatsResponse": {
+            "type": "object",
+            "properties": {
+                "active_subscribers": {
+                    "type": "integer",
+                    "example": 1150
+                },
+                "by_type": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.TypeCount"
+                    }
+                },
+                "generated_at": {
+                    "type": "string"
+                },
+                "growth": {
+                    "$ref": "#/definitions/dto.SubscriberGrowth"
+                },
+                "recent_signups": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.SubscriberResponse"
                     }
+                },
+                "total_subscribers": {
+                    "type": "integer",
+                    "example": 1200
+                },
+                "unsubscribe_rate": {
+                    "type": "number",
+                    "example": 0.0417
+                },
+                "unsubscribed_subscribers": {
+                    "type": "integer",
+                    "example": 50
                 }
             }
-        }
-    },
-    "definitions": {
-        "models.Subscriber": {
+        },
+        "dto.StatusCount": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "example": 310
+                },
+                "status": {
+                    "type": "string",
+                    "example": "active"
+                }
+            }
+        },
+        "dto.SubscriberChange": {
+            "type": "object",
+            "properties": {
+                "after": {},
+                "before": {},
+                "field": {
+                    "type": "string",
+                    "example": "name"
+                }
+            }
+        },
+        "dto.SubscriberGrowth": {
+            "type": "object",
+            "properties": {
+                "daily": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.GrowthPoint"
+                    }
+                },
+                "monthly": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.GrowthPoint"
+                    }
+                },
+                "weekly": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.GrowthPoint"
+                    }
+                }
+            }
+        },
+        "dto.SubscriberNoteResponse": {
+            "type": "object",
+            "properties": {
+                "author": {
+                    "type": "string",
+                    "example": "admin@mylocal.ing"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "text": {
+                    "type": "string",
+                    "example": "Asked to only receive the monthly digest"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.SubscriberReferralsResponse": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "integer",
+                    "example": 6
+                },
+                "referees": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ReferralSubscriber"
+                    }
+                },
+                "referral_code": {
+                    "type": "string",
+                    "example": "k7m2q9xw4d"
+                },
+                "referrals": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "referred_by": {
+                    "$ref": "#/definitions/dto.ReferralSubscriber"
+                }
+            }
+        },
+        "dto.SubscriberResponse": {
             "type": "object",
             "properties": {
+                "anonymized_at": {
+                    "type": "string"
+                },
                 "created_at": {
                     "type": "string"
                 },
                 "email": {
+                    "type": "string",
+                    "example": "user@example.com"
+                },
+                "email_verified_at": {
                     "type": "string"
                 },
                 "id": {
                     "type": "integer"
                 },
+                "locale": {
+                    "type": "string",
+                    "enum": [
+                        "en",
+                        "fr",
+                        "es"
+                    ],
+                    "example": "en"
+                },
+                "metadata": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
                 "name": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "Jane Doe"
+                },
+                "notes": {
+                    "description": "Notes are only embedded on ?include=notes, newest first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.SubscriberNoteResponse"
+                    }
+                },
+                "org_id": {
+                    "type": "integer"
+                },
+                "referral_code": {
+                    "description": "ReferralCode is shared by the subscriber as the ref of the signups they refer",
+                    "type": "string",
+                    "example": "k7m2q9xw4d"
+                },
+                "referred_by_id": {
+                    "description": "ReferredByID is the subscriber whose referral code they signed up with",
+                    "type": "integer",
+                    "example": 12
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "pending",
+                        "active",
+                        "bounced",
+                        "unsubscribed",
+                        "quarantined"
+                    ],
+                    "example": "active"
                 },
                 "subscriber_types": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.SubscriberType"
+                        "$ref": "#/definitions/dto.SubscriberTypeResponse"
                     }
                 },
                 "updated_at": {
                     "type": "string"
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 3
                 }
             }
         },
-        "models.SubscriberType": {
+        "dto.SubscriberRevisionResponse": {
             "type": "object",
             "properties": {
+                "action": {
+                    "type": "string",
+                    "enum": [
+                        "created",
+                        "updated",
+                        "confirmed",
+                        "merged",
+                        "delivery",
+                        "anonymized",
+                        "restored",
+                        "undeleted",
+                        "approved"
+                    ],
+                    "example": "updated"
+                },
+                "after": {
+                    "$ref": "#/definitions/models.SubscriberSnapshot"
+                },
+                "author": {
+                    "type": "string",
+                    "example": "admin@mylocal.ing"
+                },
+                "before": {
+                    "$ref": "#/definitions/models.SubscriberSnapshot"
+                },
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.SubscriberChange"
+                    }
+                },
                 "created_at": {
                     "type": "string"
                 },
                 "id": {
                     "type": "integer"
                 },
+                "version": {
+                    "type": "integer",
+                    "example": 4
+                }
+            }
+        },
+        "dto.SubscriberTypeDefinitionResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Helps out at local events"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "volunteer"
+                }
+            }
+        },
+        "dto.SubscriberTypePreferences": {
+            "type": "object",
+            "properties": {
+                "channel": {
+                    "type": "string",
+                    "enum": [
+                        "email",
+                        "sms"
+                    ],
+                    "example": "email"
+                },
+                "frequency": {
+                    "type": "string",
+                    "enum": [
+                        "daily",
+                        "weekly",
+                        "monthly"
+                    ],
+                    "example": "weekly"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "shopper"
+                }
+            }
+        },
+        "dto.SubscriberTypeRequest": {
+            "type": "object",
+            "properties": {
+                "channel": {
+                    "type": "string",
+                    "enum": [
+                        "email",
+                        "sms"
+                    ],
+                    "example": "email"
+                },
+                "frequency": {
+                    "type": "string",
+                    "enum": [
+                        "daily",
+                        "weekly",
+                        "monthly"
+                    ],
+                    "example": "weekly"
+                },
                 "name": {
-                    "description": "references the custom ENUM",
+                    "type": "string",
+                    "example": "shopper"
+                }
+            }
+        },
+        "dto.SubscriberTypeResponse": {
+            "type": "object",
+            "properties": {
+                "channel": {
+                    "type": "string",
+                    "enum": [
+                        "email",
+                        "sms"
+                    ],
+                    "example": "email"
+                },
+                "created_at": {
                     "type": "string"
                 },
-                "subscriber_id": {
+                "frequency": {
+                    "type": "string",
+                    "enum": [
+                        "daily",
+                        "weekly",
+                        "monthly"
+                    ],
+                    "example": "weekly"
+                },
+                "id": {
                     "type": "integer"
                 },
+                "name": {
+                    "type": "string",
+                    "example": "shopper"
+                },
                 "updated_at": {
                     "type": "string"
                 }
             }
+        },
+        "dto.TemplateErrorResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "invalid_template"
+                },
+                "column": {
+                    "type": "integer",
+                    "example": 14
+                },
+                "error": {
+                    "type": "string",
+                    "example": "map has no entry for key \\"
+                },
+                "line": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "part": {
+                    "type": "string",
+                    "enum": [
+                        "subject",
+                        "text",
+                        "html",
+                        "source"
+                    ],
+                    "example": "html"
+                }
+            }
+        },
+        "dto.TokenResponse": {
+            "type": "object",
+            "properties": {
+                "device_token": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.TrustedDeviceResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "ip": {
+                    "type": "string"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "user_agent": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.TypeCount": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "name": {
+                    "type": "string",
+                    "example": "shopper"
+                }
+            }
+        },
+        "dto.UpdateIntegrationListsRequest": {
+            "type": "object",
+            "properties": {
+                "lists": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.IntegrationListRequest"
+                    }
+                }
+            }
+        },
+        "dto.UpdateIntegrationRequest": {
+            "type": "object",
+            "properties": {
+                "api_key": {
+                    "type": "string",
+                    "example": "0123456789abcdef0123456789abcdef-us21"
+                },
+                "enabled": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "dto.UpdatePreferencesRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "Jane Doe"
+                },
+                "subscribed": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "subscriber_types": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.SubscriberTypeRequest"
+                    }
+                }
+            }
+        },
+        "dto.UpdateSubscriberRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "user@example.com"
+                },
+                "locale": {
+                    "type": "string",
+                    "enum": [
+                        "en",
+                        "fr",
+                        "es"
+                    ],
+                    "example": "fr"
+                },
+                "metadata": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Jane Doe"
+                },
+                "subscriber_types": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.SubscriberTypeRequest"
+                    }
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "dto.VerifySignInRequest": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "123456"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "user@example.com"
+                },
+                "phone": {
+                    "type": "string",
+                    "example": "+15551234567"
+                },
+                "trust_device": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "models.JSONMap": {
+            "type": "object",
+            "additionalProperties": true
+        },
+        "models.SubscriberSnapshot": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "email_verified_at": {
+                    "type": "string"
+                },
+                "metadata": {
+                    "$ref": "#/definitions/models.JSONMap"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "subscriber_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
         }
     }
 }`
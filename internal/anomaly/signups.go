@@ -0,0 +1,162 @@
+package anomaly
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"fiber-gorm-api/internal/models"
+	"fiber-gorm-api/internal/notify"
+	redisclient "fiber-gorm-api/internal/redis"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultWindowHours = 24
+	defaultMultiple    = 5
+	// a quiet list going from 1 to 6 signups an hour isn't a flood
+	defaultMinSignups = 20
+)
+
+var httpClient = &http.Client{Timeout: 5 * time.Second}
+
+// SpikeAlert is the JSON body posted to SIGNUP_ALERT_WEBHOOK_URL
+type SpikeAlert struct {
+	Event           string    `json:"event"`
+	Hour            time.Time `json:"hour"`
+	Signups         int64     `json:"signups"`
+	TrailingAverage float64   `json:"trailing_average"`
+	Multiple        float64   `json:"multiple"`
+}
+
+// Redis key counting the public signups in the hour of t
+func signupsKey(t time.Time) string {
+	return "signup_rate:" + t.UTC().Format("2006010215")
+}
+
+// Redis key present once the spike of the hour of t was alerted on
+func alertedKey(t time.Time) string {
+	return "signup_alert:" + t.UTC().Format("2006010215")
+}
+
+// windowHours is how many full hours the trailing average covers (SIGNUP_ALERT_WINDOW_HOURS)
+func windowHours() int {
+	if n, err := strconv.Atoi(os.Getenv("SIGNUP_ALERT_WINDOW_HOURS")); err == nil && n > 0 {
+		return n
+	}
+	return defaultWindowHours
+}
+
+// multiple is how many times the trailing average an hour must reach to alert (SIGNUP_ALERT_MULTIPLE)
+func multiple() float64 {
+	if f, err := strconv.ParseFloat(os.Getenv("SIGNUP_ALERT_MULTIPLE"), 64); err == nil && f > 0 {
+		return f
+	}
+	return defaultMultiple
+}
+
+// minSignups is the fewest signups an hour that can alert, whatever the average (SIGNUP_ALERT_MIN_SIGNUPS)
+func minSignups() int64 {
+	if n, err := strconv.Atoi(os.Getenv("SIGNUP_ALERT_MIN_SIGNUPS")); err == nil && n > 0 {
+		return int64(n)
+	}
+	return defaultMinSignups
+}
+
+// CountSignup counts a public signup towards the rate of the current hour. Counters are kept
+// as long as the trailing average needs them. Without Redis nothing is counted.
+func CountSignup(ctx context.Context) {
+	if !redisclient.Configured() {
+		return
+	}
+	ttl := time.Duration(windowHours()+1) * time.Hour
+	if _, err := redisclient.Increment(ctx, signupsKey(time.Now()), ttl); err != nil {
+		log.Printf("[WARN] Could not count signup: %v", err)
+	}
+}
+
+// signups reads the count of the hour of t, 0 when nobody signed up
+func signups(ctx context.Context, t time.Time) int64 {
+	value, err := redisclient.GetValue(ctx, signupsKey(t))
+	if err != nil {
+		return 0
+	}
+	n, _ := strconv.ParseInt(value, 10, 64)
+	return n
+}
+
+// CheckSignups compares the signups of the hour of now with the average of the hours before it
+// and, the first time in the hour they reach SIGNUP_ALERT_MULTIPLE times it, alerts the platform
+// admins and the webhook. It reports whether an alert was raised.
+func CheckSignups(ctx context.Context, db *gorm.DB, now time.Time) (bool, error) {
+	current := signups(ctx, now)
+	if current < minSignups() {
+		return false, nil
+	}
+	hours := windowHours()
+	var total int64
+	for i := 1; i <= hours; i++ {
+		total += signups(ctx, now.Add(-time.Duration(i)*time.Hour))
+	}
+	average := float64(total) / float64(hours)
+	if float64(current) < multiple()*average {
+		return false, nil
+	}
+
+	// one alert per hour, however often the check runs
+	alerts, err := redisclient.Increment(ctx, alertedKey(now), 2*time.Hour)
+	if err != nil || alerts > 1 {
+		return false, err
+	}
+
+	alert := SpikeAlert{
+		Event:           "signup_spike",
+		Hour:            now.UTC().Truncate(time.Hour),
+		Signups:         current,
+		TrailingAverage: average,
+		Multiple:        multiple(),
+	}
+	err = notify.Record(db.WithContext(ctx), &models.AdminActivity{
+		OrgID: models.DefaultOrgID,
+		Kind:  notify.KindSignupSpike,
+		Summary: fmt.Sprintf("%d signups since %s, %.1f an hour on average over the previous %d hours",
+			current, alert.Hour.Format(time.RFC3339), average, hours),
+	})
+	if err != nil {
+		return true, err
+	}
+	return true, postWebhook(ctx, alert)
+}
+
+// postWebhook posts alert to SIGNUP_ALERT_WEBHOOK_URL, if set
+func postWebhook(ctx context.Context, alert SpikeAlert) error {
+	url := os.Getenv("SIGNUP_ALERT_WEBHOOK_URL")
+	if url == "" {
+		return nil
+	}
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("signup alert webhook returned %d", resp.StatusCode)
+	}
+	return nil
+}
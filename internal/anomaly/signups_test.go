@@ -0,0 +1,72 @@
+package anomaly
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"fiber-gorm-api/internal/models"
+	redisclient "fiber-gorm-api/internal/redis"
+	"fiber-gorm-api/internal/testutil/testenv"
+)
+
+func TestCheckSignups(t *testing.T) {
+	t.Setenv("REDIS_HOST", "")
+	t.Setenv("ADMIN_NOTIFICATIONS", "off")
+	t.Setenv("SIGNUP_ALERT_MIN_SIGNUPS", "10")
+	redisclient.InitRedis("session")
+	conn := testenv.SQLite(t)
+
+	var alerts []SpikeAlert
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var alert SpikeAlert
+		json.NewDecoder(r.Body).Decode(&alert)
+		alerts = append(alerts, alert)
+	}))
+	defer hook.Close()
+	t.Setenv("SIGNUP_ALERT_WEBHOOK_URL", hook.URL)
+
+	ctx := context.Background()
+	// a fixed past hour, so counters of the real current hour don't interfere
+	now := time.Date(2020, 3, 1, 12, 30, 0, 0, time.UTC)
+	setSignups := func(hoursAgo, n int) {
+		key := signupsKey(now.Add(-time.Duration(hoursAgo) * time.Hour))
+		if err := redisclient.SetValue(ctx, key, strconv.Itoa(n), time.Hour); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 4 signups an hour over the last day
+	for h := 1; h <= defaultWindowHours; h++ {
+		setSignups(h, 4)
+	}
+
+	t.Run("Normal rate doesn't alert", func(t *testing.T) {
+		setSignups(0, 12)
+		if alerted, err := CheckSignups(ctx, conn, now); err != nil || alerted {
+			t.Fatalf("Expected no alert for 3x the average, got %t (%v)", alerted, err)
+		}
+	})
+
+	t.Run("Spike alerts once", func(t *testing.T) {
+		setSignups(0, 40)
+		if alerted, err := CheckSignups(ctx, conn, now); err != nil || !alerted {
+			t.Fatalf("Expected an alert for 10x the average, got %t (%v)", alerted, err)
+		}
+		if len(alerts) != 1 || alerts[0].Signups != 40 || alerts[0].TrailingAverage != 4 {
+			t.Fatalf("Expected one webhook call for 40 signups, got %+v", alerts)
+		}
+		var activities int64
+		conn.Model(&models.AdminActivity{}).Where("kind = ?", "signup_spike").Count(&activities)
+		if activities != 1 {
+			t.Errorf("Expected the spike recorded for the admins, got %d", activities)
+		}
+
+		if alerted, _ := CheckSignups(ctx, conn, now.Add(10*time.Minute)); alerted {
+			t.Error("Expected a single alert per hour")
+		}
+	})
+}
@@ -0,0 +1,110 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	redisclient "fiber-gorm-api/internal/redis"
+)
+
+const defaultTTL = 60 * time.Second
+
+// listGenerationKey is bumped on every subscriber write; list cache keys embed it,
+// so one INCR invalidates every cached list/query at once.
+const listGenerationKey = "cache:subscribers:gen"
+
+var ttl = defaultTTL
+
+// Enabled reports whether read caching is on (CACHE_ENABLED=true and the entity DB is connected)
+func Enabled() bool {
+	return redisclient.EntityRdb != nil
+}
+
+// Init connects the Redis entity DB when CACHE_ENABLED=true. CACHE_TTL_SECONDS overrides the 60s default.
+func Init() {
+	if os.Getenv("CACHE_ENABLED") != "true" {
+		return
+	}
+	if n, err := strconv.Atoi(os.Getenv("CACHE_TTL_SECONDS")); err == nil && n > 0 {
+		ttl = time.Duration(n) * time.Second
+	}
+	redisclient.InitRedis("entity")
+	log.Printf("Subscriber read cache enabled, ttl %s", ttl)
+}
+
+// SubscriberKey is the cache key of a single subscriber
+func SubscriberKey(id uint) string {
+	return "cache:subscriber:" + strconv.FormatUint(uint64(id), 10)
+}
+
+// SubscriberListKey is the cache key of a subscriber list/query, scoped to the current generation
+func SubscriberListKey(ctx context.Context, query string) string {
+	gen, _ := redisclient.EntityRdb.Get(ctx, listGenerationKey).Result()
+	sum := sha256.Sum256([]byte(query))
+	return "cache:subscribers:" + gen + ":" + hex.EncodeToString(sum[:8])
+}
+
+// StatsKey is the cache key of an organization's dashboard stats, dropped on any subscriber write
+func StatsKey(ctx context.Context, orgID uint) string {
+	gen, _ := redisclient.EntityRdb.Get(ctx, listGenerationKey).Result()
+	return "cache:stats:" + gen + ":" + strconv.FormatUint(uint64(orgID), 10)
+}
+
+// LastModifiedKey is the cache key of when an organization's subscribers last changed,
+// dropped on any subscriber write
+func LastModifiedKey(ctx context.Context, orgID uint) string {
+	gen, _ := redisclient.EntityRdb.Get(ctx, listGenerationKey).Result()
+	return "cache:subscribers:modified:" + gen + ":" + strconv.FormatUint(uint64(orgID), 10)
+}
+
+// Get loads a cached value into dest, reporting whether it was a hit
+func Get(ctx context.Context, key string, dest interface{}) bool {
+	if !Enabled() {
+		return false
+	}
+	raw, err := redisclient.EntityRdb.Get(ctx, key).Bytes()
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(raw, dest) == nil
+}
+
+// Set stores a value with the configured TTL. Failures are logged, never returned:
+// the cache must not break reads.
+func Set(ctx context.Context, key string, value interface{}) {
+	if !Enabled() {
+		return
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+	if err := redisclient.EntityRdb.Set(ctx, key, raw, ttl).Err(); err != nil {
+		log.Printf("[WARN] cache set %s failed: %v", key, err)
+	}
+}
+
+// InvalidateSubscriber drops a subscriber's cached record and every cached list.
+// Request handlers ignore the error; the outbox worker retries on it.
+func InvalidateSubscriber(ctx context.Context, ids ...uint) error {
+	if !Enabled() {
+		return nil
+	}
+	for _, id := range ids {
+		if err := redisclient.EntityRdb.Del(ctx, SubscriberKey(id)).Err(); err != nil {
+			log.Printf("[WARN] cache invalidation failed: %v", err)
+			return err
+		}
+	}
+	if err := redisclient.EntityRdb.Incr(ctx, listGenerationKey).Err(); err != nil {
+		log.Printf("[WARN] cache invalidation failed: %v", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+// Package calendar writes local events (models.Event) as iCalendar files (RFC 5545): the invite
+// served at /events/{id}/calendar.ics and attached to the campaigns announcing the event.
+// Invites are published (METHOD:PUBLISH) rather than sent to attendees, so calendars add the
+// event without asking anyone to RSVP; its UID and SEQUENCE let them replace it when it changes.
+package calendar
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"fiber-gorm-api/internal/models"
+	sendgridservice "fiber-gorm-api/internal/services"
+)
+
+const (
+	defaultBaseURL = "http://localhost:3517"
+	// ContentType is the content type of invites
+	ContentType = "text/calendar; charset=utf-8"
+	// attachmentType is that of invites attached to emails, with their method for mail clients
+	attachmentType = "text/calendar; method=PUBLISH; charset=utf-8"
+	productID      = "-//mylo-ing//api//EN"
+	// maxLine is the length in octets lines are folded at, CRLF excluded
+	maxLine = 75
+	// maxSlug bounds the part of the filename of an invite made from its title
+	maxSlug = 50
+)
+
+// BaseURL is the public URL of the API invites are served under: TRACKING_BASE_URL
+func BaseURL() string {
+	if base := strings.TrimSuffix(os.Getenv("TRACKING_BASE_URL"), "/"); base != "" {
+		return base
+	}
+	return defaultBaseURL
+}
+
+// URL returns the link to the invite of the event id
+func URL(id uint) string {
+	return BaseURL() + "/events/" + strconv.FormatUint(uint64(id), 10) + "/calendar.ics"
+}
+
+// UID is the unique and stable identifier of the event e across calendars
+func UID(e models.Event) string {
+	host := "localhost"
+	if u, err := url.Parse(BaseURL()); err == nil && u.Hostname() != "" {
+		host = u.Hostname()
+	}
+	return fmt.Sprintf("event-%d@%s", e.ID, host)
+}
+
+var nonSlug = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Filename is the name of the invite of e, made from its title
+func Filename(e models.Event) string {
+	slug := strings.Trim(nonSlug.ReplaceAllString(strings.ToLower(e.Title), "-"), "-")
+	if len(slug) > maxSlug {
+		slug = strings.TrimRight(slug[:maxSlug], "-")
+	}
+	if slug == "" {
+		slug = "event"
+	}
+	return slug + ".ics"
+}
+
+// ICS returns the invite of e, stamped at now
+func ICS(e models.Event, now time.Time) []byte {
+	var b strings.Builder
+	line := func(name, value string) {
+		b.WriteString(fold(name + ":" + value))
+	}
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", productID)
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	line("BEGIN", "VEVENT")
+	line("UID", UID(e))
+	line("SEQUENCE", strconv.Itoa(e.Sequence))
+	line("DTSTAMP", dateTime(now))
+	if !e.CreatedAt.IsZero() {
+		line("CREATED", dateTime(e.CreatedAt))
+	}
+	if !e.UpdatedAt.IsZero() {
+		line("LAST-MODIFIED", dateTime(e.UpdatedAt))
+	}
+	line("DTSTART", dateTime(e.StartsAt))
+	line("DTEND", dateTime(e.EndsAt))
+	line("SUMMARY", text(e.Title))
+	if e.Description != "" {
+		line("DESCRIPTION", text(e.Description))
+	}
+	if e.Location != "" {
+		line("LOCATION", text(e.Location))
+	}
+	if e.URL != "" {
+		line("URL", e.URL)
+	}
+	line("STATUS", "CONFIRMED")
+	line("TRANSP", "OPAQUE")
+	line("END", "VEVENT")
+	line("END", "VCALENDAR")
+	return []byte(b.String())
+}
+
+// Attachment returns the invite of e as the attachment of an email sent at now
+func Attachment(e models.Event, now time.Time) sendgridservice.Attachment {
+	return sendgridservice.Attachment{
+		Filename:    Filename(e),
+		ContentType: attachmentType,
+		Content:     ICS(e, now),
+	}
+}
+
+// dateTime is t in UTC, the form of DATE-TIME needing no VTIMEZONE
+func dateTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// textEscaper escapes the characters of TEXT values: backslashes, semicolons, commas and line
+// breaks
+var textEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+
+func text(s string) string {
+	return textEscaper.Replace(s)
+}
+
+// fold ends a content line with CRLF, folded into lines of at most maxLine octets continued by a
+// space, without splitting characters
+func fold(line string) string {
+	var b strings.Builder
+	limit := maxLine
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+		// continuation lines start with the space
+		limit = maxLine - 1
+	}
+	b.WriteString(line + "\r\n")
+	return b.String()
+}
@@ -0,0 +1,82 @@
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"fiber-gorm-api/internal/models"
+	sendgridservice "fiber-gorm-api/internal/services"
+)
+
+func TestICS(t *testing.T) {
+	t.Setenv("TRACKING_BASE_URL", "https://api.example.com/")
+	paris, _ := time.LoadLocation("Europe/Paris")
+	event := models.Event{
+		ID:          12,
+		Title:       "Market day, summer edition",
+		Description: "Fresh produce; crafts\nand music \\o/",
+		Location:    "Place du Marché, Lyon",
+		URL:         "https://market.example.org/summer",
+		StartsAt:    time.Date(2025, 7, 5, 9, 0, 0, 0, paris),
+		EndsAt:      time.Date(2025, 7, 5, 14, 0, 0, 0, paris),
+		Sequence:    2,
+	}
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	ics := string(ICS(event, now))
+
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//mylo-ing//api//EN\r\n",
+		"METHOD:PUBLISH\r\n",
+		"UID:event-12@api.example.com\r\n",
+		"SEQUENCE:2\r\n",
+		"DTSTAMP:20250701T120000Z\r\n",
+		"DTSTART:20250705T070000Z\r\n",
+		"DTEND:20250705T120000Z\r\n",
+		"SUMMARY:Market day\\, summer edition\r\n",
+		"DESCRIPTION:Fresh produce\\; crafts\\nand music \\\\o/\r\n",
+		"LOCATION:Place du Marché\\, Lyon\r\n",
+		"URL:https://market.example.org/summer\r\n",
+		"END:VEVENT\r\nEND:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("Expected %q in\n%s", want, ics)
+		}
+	}
+	if strings.Contains(strings.ReplaceAll(ics, "\r\n", ""), "\n") {
+		t.Error("Expected every line to end with CRLF")
+	}
+	if strings.Contains(ics, "CREATED") {
+		t.Error("Expected no CREATED for an event not saved")
+	}
+}
+
+func TestFold(t *testing.T) {
+	line := "DESCRIPTION:" + strings.Repeat("é", 100)
+	folded := fold(line)
+	for _, l := range strings.Split(strings.TrimSuffix(folded, "\r\n"), "\r\n") {
+		if len(l) > maxLine {
+			t.Errorf("Expected lines of at most %d octets, got %d", maxLine, len(l))
+		}
+		if !strings.HasPrefix(l, "DESCRIPTION") && !strings.HasPrefix(l, " é") {
+			t.Errorf("Expected a continuation line starting with a space and a whole character, got %q", l)
+		}
+	}
+	if unfolded := strings.ReplaceAll(strings.TrimSuffix(folded, "\r\n"), "\r\n ", ""); unfolded != line {
+		t.Errorf("Expected the line back when unfolded, got %q", unfolded)
+	}
+}
+
+func TestAttachment(t *testing.T) {
+	event := models.Event{ID: 3, Title: "  Été: Jazz & Wine! ", StartsAt: time.Now(), EndsAt: time.Now().Add(time.Hour)}
+	attachment := Attachment(event, time.Now())
+	if attachment.Filename != "t-jazz-wine.ics" {
+		t.Errorf("Unexpected filename %q", attachment.Filename)
+	}
+	if err := sendgridservice.ValidateAttachments([]sendgridservice.Attachment{attachment}); err != nil {
+		t.Errorf("Expected the invite accepted as an attachment, got %v", err)
+	}
+	if name := Filename(models.Event{Title: "!!!"}); name != "event.ics" {
+		t.Errorf("Expected a default filename, got %q", name)
+	}
+}
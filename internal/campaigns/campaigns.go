@@ -0,0 +1,552 @@
+// Package campaigns sends email campaigns in the background: the scheduler's dispatcher starts
+// the runs that are due and queues an outbox event per recipient, which the outbox worker sends.
+// A Redis lock keeps two dispatchers from running the same campaign at once, and the unique
+// CampaignSend of each recipient of a run keeps anyone from being queued it twice even then.
+//
+// Runs of A/B tested campaigns go in two steps: the test group is queued first, then once the
+// test is over the dispatcher picks the subject opened most, as counted by the tracking pixel of
+// each email, and queues the remainder with it. The live admin clients are told how many emails
+// a run queued after each batch.
+//
+// A campaign announcing a local event attaches its calendar invite to each email, written when
+// the email is sent so a rescheduled event goes out with its new times.
+package campaigns
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"strconv"
+	"time"
+
+	"fiber-gorm-api/internal/calendar"
+	"fiber-gorm-api/internal/models"
+	"fiber-gorm-api/internal/outbox"
+	"fiber-gorm-api/internal/realtime"
+	redisclient "fiber-gorm-api/internal/redis"
+	"fiber-gorm-api/internal/repository"
+	sendgridservice "fiber-gorm-api/internal/services"
+
+	"github.com/robfig/cron/v3"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TopicSend is the outbox topic of the email of one CampaignSend
+const TopicSend = "campaign.send"
+
+const (
+	// batchSize is how many recipients are queued per transaction; pausing takes effect between
+	// batches
+	batchSize = 500
+	// lockTTL bounds how long a dispatcher that died keeps others from picking its run up. It's
+	// extended after every batch.
+	lockTTL = 5 * time.Minute
+	// MinInterval is the shortest time allowed between two runs of a recurring campaign
+	MinInterval = time.Hour
+)
+
+// Subjects of an A/B test, VariantA being Subject and VariantB SubjectB
+const (
+	VariantA = "a"
+	VariantB = "b"
+)
+
+// ErrLocked is returned by Dispatch when another dispatcher is running the campaign
+var ErrLocked = errors.New("campaign locked by another dispatcher")
+
+// Email is the payload of TopicSend
+type Email struct {
+	SendID uint `json:"send_id"`
+}
+
+// Location returns the time zone named tz, UTC when empty
+func Location(tz string) (*time.Location, error) {
+	if tz == "" || tz == "UTC" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil || tz == "Local" {
+		return nil, fmt.Errorf("Invalid timezone %s, expected an IANA name like Europe/Paris", tz)
+	}
+	return loc, nil
+}
+
+// ParseSendAt reads a send time: an RFC 3339 time, or a local date and time
+// (2006-01-02T15:04, seconds optional) read in tz
+func ParseSendAt(value, tz string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	loc, err := Location(tz)
+	if err != nil {
+		return time.Time{}, err
+	}
+	for _, layout := range []string{"2006-01-02T15:04:05", "2006-01-02T15:04"} {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("Invalid send_at %s, expected an RFC 3339 time or a local 2006-01-02T15:04", value)
+}
+
+// NextRun returns the first occurrence after t of recurrence, a cron expression (or a
+// descriptor like @weekly) read in tz. Recurrences with any two runs less than MinInterval apart
+// are rejected: the gaps of a schedule vary with the hours, days and months its runs fall on, so
+// they're checked over the year of runs from the next one, which goes through every month and
+// time change.
+func NextRun(recurrence, tz string, after time.Time) (time.Time, error) {
+	loc, err := Location(tz)
+	if err != nil {
+		return time.Time{}, err
+	}
+	schedule, err := cron.ParseStandard(recurrence)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Invalid recurrence %s, expected a cron expression like 0 9 * * MON", recurrence)
+	}
+	next := schedule.Next(after.In(loc))
+	if next.IsZero() {
+		return next, fmt.Errorf("Invalid recurrence %s, it never runs", recurrence)
+	}
+	for run, end := next, next.AddDate(1, 0, 0); run.Before(end); {
+		following := schedule.Next(run)
+		if following.IsZero() {
+			break
+		}
+		if following.Sub(run) < MinInterval {
+			return time.Time{}, fmt.Errorf("Invalid recurrence %s, runs must be at least %s apart", recurrence, MinInterval)
+		}
+		run = following
+	}
+	return next.UTC(), nil
+}
+
+// lockKey is the Redis lock of the runs of a campaign
+func lockKey(id uint) string {
+	return "campaign_lock:" + strconv.FormatUint(uint64(id), 10)
+}
+
+// ProcessDue runs the campaigns due at now, those a dispatcher left sending and those whose A/B
+// test is over, and returns how many emails it queued. Several dispatchers may call it at once:
+// each campaign is run by a single one at a time.
+func ProcessDue(conn *gorm.DB, now time.Time) (int, error) {
+	var due []models.Campaign
+	err := conn.Where("(status = ? AND next_run_at <= ?) OR status = ? OR (status = ? AND test_ends_at <= ?)",
+		models.CampaignScheduled, now, models.CampaignSending, models.CampaignTesting, now).
+		Order("next_run_at, id").
+		Find(&due).Error
+	if err != nil {
+		return 0, err
+	}
+
+	queued := 0
+	for i := range due {
+		n, err := Dispatch(conn, &due[i], now)
+		queued += n
+		if err != nil && !errors.Is(err, ErrLocked) {
+			log.Printf("[WARN] Campaigns: campaign %d failed: %v", due[i].ID, err)
+		}
+	}
+	return queued, nil
+}
+
+// Dispatch starts the run of a due campaign, picks up the one it's sending, or sends the winner
+// of its A/B test to the remainder, and queues the email of every recipient not queued yet,
+// returning how many. A run stops between batches once the campaign is paused, until Resume; one
+// whose audience can't be read pauses the campaign with the error. Without Redis nothing is
+// sent, rather than risking double sends.
+func Dispatch(conn *gorm.DB, campaign *models.Campaign, now time.Time) (int, error) {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return 0, err
+	}
+	key, value := lockKey(campaign.ID), hex.EncodeToString(token)
+	locked, err := redisclient.SetIfAbsent(redisclient.Ctx, key, value, lockTTL)
+	if err != nil {
+		return 0, fmt.Errorf("locking: %w", err)
+	}
+	if !locked {
+		return 0, ErrLocked
+	}
+	defer redisclient.DeleteIfValue(redisclient.Ctx, key, value)
+
+	switch campaign.Status {
+	case models.CampaignScheduled:
+		// the status check makes a campaign paused or cancelled meanwhile look not due
+		res := conn.Model(&models.Campaign{}).
+			Where("id = ? AND status = ? AND next_run_at <= ?", campaign.ID, models.CampaignScheduled, now).
+			Updates(map[string]interface{}{
+				"status":       models.CampaignSending,
+				"run":          gorm.Expr("run + 1"),
+				"last_run_at":  now,
+				"next_run_at":  nil,
+				"error":        "",
+				"winner":       "",
+				"test_ends_at": nil,
+			})
+		if res.Error != nil || res.RowsAffected == 0 {
+			return 0, res.Error
+		}
+	case models.CampaignTesting:
+		winner, err := pickWinner(conn, campaign)
+		if err != nil {
+			return 0, err
+		}
+		res := conn.Model(&models.Campaign{}).
+			Where("id = ? AND status = ? AND test_ends_at <= ?", campaign.ID, models.CampaignTesting, now).
+			Updates(map[string]interface{}{"status": models.CampaignSending, "winner": winner})
+		if res.Error != nil || res.RowsAffected == 0 {
+			return 0, res.Error
+		}
+	}
+	if err := conn.First(campaign, campaign.ID).Error; err != nil {
+		return 0, err
+	}
+	if campaign.Status != models.CampaignSending {
+		return 0, nil
+	}
+
+	queued, done, err := queue(conn, campaign, key)
+	if err != nil {
+		conn.Model(&models.Campaign{}).Where("id = ? AND status = ?", campaign.ID, models.CampaignSending).
+			Updates(map[string]interface{}{"status": models.CampaignPaused, "error": err.Error()})
+		return queued, err
+	}
+	if !done {
+		return queued, nil
+	}
+	if campaign.TestPercent > 0 && campaign.Winner == "" {
+		return queued, wait(conn, campaign, now)
+	}
+	return queued, finish(conn, campaign)
+}
+
+// audience returns the scope of the subscribers a campaign is sent to: the members of its
+// segment, or of its filter without one
+func audience(conn *gorm.DB, campaign *models.Campaign) (func(*gorm.DB) *gorm.DB, error) {
+	stored := campaign.Filter
+	if campaign.SegmentID != nil {
+		var segment models.Segment
+		if err := conn.Where("org_id = ?", campaign.OrgID).First(&segment, *campaign.SegmentID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errors.New("segment not found")
+			}
+			return nil, err
+		}
+		stored = segment.Filter
+	}
+	filter, err := repository.FilterFromMap(stored)
+	if err != nil {
+		return nil, fmt.Errorf("reading filter: %w", err)
+	}
+	return filter.Scope()
+}
+
+// testVariant reports whether a subscriber is in the test group of the current run of an A/B
+// tested campaign, and the subject they get. The split hashes the subscriber with the run, so
+// it's the same when a run is picked up again but differs from one run to the next.
+func testVariant(campaign *models.Campaign, subscriberID uint) (string, bool) {
+	h := fnv.New32a()
+	fmt.Fprintf(h, "%d:%d:%d", campaign.ID, campaign.Run, subscriberID)
+	sum := h.Sum32()
+	if int(sum%100) >= campaign.TestPercent {
+		return "", false
+	}
+	if (sum/100)%2 == 0 {
+		return VariantA, true
+	}
+	return VariantB, true
+}
+
+// queue records a CampaignSend and queues its email for every active subscriber of the audience
+// not queued in this run yet, a batch at a time: only those of the test group while an A/B test
+// has no winner yet. It stops early, reporting done false, once the campaign isn't sending
+// anymore.
+func queue(conn *gorm.DB, campaign *models.Campaign, key string) (queued int, done bool, err error) {
+	scope, err := audience(conn, campaign)
+	if err != nil {
+		return 0, false, err
+	}
+	if err := registerLinks(conn, campaign); err != nil {
+		return 0, false, err
+	}
+	testing := campaign.TestPercent > 0 && campaign.Winner == ""
+	variant := campaign.Winner
+	if variant == "" {
+		variant = VariantA
+	}
+	var last uint
+	for {
+		var status string
+		if err := conn.Model(&models.Campaign{}).Where("id = ?", campaign.ID).Select("status").Scan(&status).Error; err != nil {
+			return queued, false, err
+		}
+		if status != models.CampaignSending {
+			return queued, false, nil
+		}
+
+		var batch []models.Subscriber
+		err := conn.Model(&models.Subscriber{}).
+			Select("subscribers.id, subscribers.org_id, subscribers.email").
+			Where("subscribers.org_id = ? AND subscribers.status = ?", campaign.OrgID, models.SubscriberStatusActive).
+			Scopes(scope).
+			Where("subscribers.id > ? AND NOT EXISTS (SELECT 1 FROM campaign_sends WHERE campaign_sends.campaign_id = ? "+
+				"AND campaign_sends.run = ? AND campaign_sends.subscriber_id = subscribers.id)", last, campaign.ID, campaign.Run).
+			Order("subscribers.id").Limit(batchSize).
+			Find(&batch).Error
+		if err != nil {
+			return queued, false, err
+		}
+		if len(batch) == 0 {
+			return queued, true, nil
+		}
+		last = batch[len(batch)-1].ID
+
+		n := 0
+		err = conn.Transaction(func(tx *gorm.DB) error {
+			for _, s := range batch {
+				send := models.CampaignSend{CampaignID: campaign.ID, Run: campaign.Run, SubscriberID: s.ID, Email: s.Email, Variant: variant}
+				if testing {
+					v, ok := testVariant(campaign, s.ID)
+					if !ok {
+						continue
+					}
+					send.Variant, send.Test = v, true
+				}
+				res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&send)
+				if res.Error != nil {
+					return res.Error
+				}
+				if res.RowsAffected == 0 {
+					continue
+				}
+				if err := outbox.Enqueue(tx, TopicSend, campaign.OrgID, Email{SendID: send.ID}); err != nil {
+					return err
+				}
+				n++
+			}
+			return tx.Model(&models.Campaign{}).Where("id = ?", campaign.ID).
+				Update("sent_count", gorm.Expr("sent_count + ?", n)).Error
+		})
+		if err != nil {
+			return queued, false, err
+		}
+		queued += n
+		redisclient.Expire(redisclient.Ctx, key, lockTTL)
+		publishProgress(conn, campaign)
+	}
+}
+
+// publishProgress tells the live clients of the organization how many emails the current run of
+// campaign queued so far. GET /admin/campaigns/:id has its sent_count too, so a failure is only
+// logged.
+func publishProgress(conn *gorm.DB, campaign *models.Campaign) {
+	var sends int64
+	err := conn.Model(&models.CampaignSend{}).Where("campaign_id = ? AND run = ?", campaign.ID, campaign.Run).Count(&sends).Error
+	if err == nil {
+		err = realtime.Publish(redisclient.Ctx, campaign.OrgID, realtime.Event{
+			Topic:      realtime.TopicCampaignProgress,
+			CampaignID: campaign.ID,
+			Run:        campaign.Run,
+			Status:     models.CampaignSending,
+			Processed:  int(sends),
+			OccurredAt: time.Now(),
+		})
+	}
+	if err != nil {
+		log.Printf("[WARN] Campaigns: publishing the progress of campaign %d failed: %v", campaign.ID, err)
+	}
+}
+
+// wait ends the first step of the run of an A/B tested campaign, its test group being queued:
+// the winner is picked TestHours later. A run picked up again keeps the end of its test.
+func wait(conn *gorm.DB, campaign *models.Campaign, now time.Time) error {
+	ends := now.Add(time.Duration(campaign.TestHours) * time.Hour)
+	if campaign.TestEndsAt != nil {
+		ends = *campaign.TestEndsAt
+	}
+	return conn.Model(&models.Campaign{}).
+		Where("id = ? AND status = ?", campaign.ID, models.CampaignSending).
+		Updates(map[string]interface{}{"status": models.CampaignTesting, "test_ends_at": ends}).Error
+}
+
+// VariantStats counts the recipients of a subject in a run of a campaign, in its A/B test group
+// or not, and how many of them opened the email and clicked in it
+type VariantStats struct {
+	Variant string
+	Test    bool
+	Sent    int
+	Opened  int
+	Clicked int
+}
+
+// RunStats counts the recipients of a run of a campaign, their opens and clicks, by subject and
+// whether they were in the test group
+func RunStats(conn *gorm.DB, campaignID uint, run int) ([]VariantStats, error) {
+	var stats []VariantStats
+	err := conn.Model(&models.CampaignSend{}).
+		Select("variant, test, COUNT(*) AS sent, COUNT(opened_at) AS opened, COUNT(clicked_at) AS clicked").
+		Where("campaign_id = ? AND run = ?", campaignID, run).
+		Group("variant, test").
+		Order("test DESC, variant").
+		Scan(&stats).Error
+	return stats, err
+}
+
+// pickWinner returns the subject the test group of the current run of a campaign opened at the
+// highest rate, A on a tie
+func pickWinner(conn *gorm.DB, campaign *models.Campaign) (string, error) {
+	stats, err := RunStats(conn, campaign.ID, campaign.Run)
+	if err != nil {
+		return "", err
+	}
+	rates := map[string]float64{}
+	for _, s := range stats {
+		if s.Test && s.Sent > 0 {
+			rates[s.Variant] = float64(s.Opened) / float64(s.Sent)
+		}
+	}
+	if rates[VariantB] > rates[VariantA] {
+		return VariantB, nil
+	}
+	return VariantA, nil
+}
+
+// finish ends the run of a campaign: a recurring one is scheduled for its next occurrence, a
+// one-off one is sent
+func finish(conn *gorm.DB, campaign *models.Campaign) error {
+	updates := map[string]interface{}{"status": models.CampaignSent}
+	if campaign.Recurrence != "" {
+		next, err := NextRun(campaign.Recurrence, campaign.Timezone, time.Now())
+		if err != nil {
+			updates = map[string]interface{}{"status": models.CampaignPaused, "error": err.Error()}
+		} else {
+			updates = map[string]interface{}{"status": models.CampaignScheduled, "next_run_at": next}
+		}
+	}
+	return conn.Model(&models.Campaign{}).
+		Where("id = ? AND status = ?", campaign.ID, models.CampaignSending).
+		Updates(updates).Error
+}
+
+// Pause stops a scheduled, sending or testing campaign, a run being queued after its current
+// batch. It reports false when the campaign was none of them.
+func Pause(conn *gorm.DB, id uint) (bool, error) {
+	res := conn.Model(&models.Campaign{}).
+		Where("id = ? AND status IN ?", id, []string{models.CampaignScheduled, models.CampaignSending, models.CampaignTesting}).
+		Update("status", models.CampaignPaused)
+	return res.RowsAffected > 0, res.Error
+}
+
+// Resume schedules a paused campaign again, reporting false when it wasn't paused. A run it was
+// paused during is picked up where it stopped, an A/B test keeping its end; a recurring campaign
+// that missed occurrences meanwhile waits for the next one rather than catching up.
+func Resume(conn *gorm.DB, campaign *models.Campaign, now time.Time) (bool, error) {
+	updates := map[string]interface{}{"status": models.CampaignScheduled, "error": ""}
+	switch {
+	case campaign.NextRunAt == nil && campaign.Run > 0:
+		updates["status"] = models.CampaignSending
+	case campaign.NextRunAt == nil:
+		updates["status"] = models.CampaignDraft
+	case campaign.Recurrence != "" && campaign.NextRunAt.Before(now):
+		next, err := NextRun(campaign.Recurrence, campaign.Timezone, now)
+		if err != nil {
+			return false, err
+		}
+		updates["next_run_at"] = next
+	}
+	res := conn.Model(&models.Campaign{}).
+		Where("id = ? AND status = ?", campaign.ID, models.CampaignPaused).
+		Updates(updates)
+	return res.RowsAffected > 0, res.Error
+}
+
+// Cancel stops a campaign for good, reporting false when it was already sent or cancelled.
+// Emails of it still queued are dropped.
+func Cancel(conn *gorm.DB, id uint) (bool, error) {
+	res := conn.Model(&models.Campaign{}).
+		Where("id = ? AND status IN ?", id, []string{models.CampaignDraft, models.CampaignScheduled, models.CampaignSending,
+			models.CampaignTesting, models.CampaignPaused}).
+		Updates(map[string]interface{}{"status": models.CampaignCancelled, "next_run_at": nil})
+	return res.RowsAffected > 0, res.Error
+}
+
+// Deliver sends the email of a TopicSend event, with the subject of its A/B test variant, its
+// links tracked and a pixel counting its opens. Emails of a cancelled campaign, or to a
+// subscriber who isn't active anymore, are dropped; those the provider refused fail permanently.
+func Deliver(conn *gorm.DB) outbox.Handler {
+	return func(event models.OutboxEvent) error {
+		var email Email
+		if err := json.Unmarshal([]byte(event.Payload), &email); err != nil {
+			return outbox.Permanent(err)
+		}
+		var send models.CampaignSend
+		if err := conn.First(&send, email.SendID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+		var campaign models.Campaign
+		if err := conn.First(&campaign, send.CampaignID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+		if campaign.Status == models.CampaignCancelled {
+			return nil
+		}
+		var active int64
+		if err := conn.Model(&models.Subscriber{}).
+			Where("id = ? AND status = ?", send.SubscriberID, models.SubscriberStatusActive).
+			Count(&active).Error; err != nil {
+			return err
+		}
+		if active == 0 {
+			return nil
+		}
+
+		subject := campaign.Subject
+		if send.Variant == VariantB && campaign.SubjectB != "" {
+			subject = campaign.SubjectB
+		}
+		now := time.Now()
+		body, err := trackedBody(conn, &campaign, &send, now)
+		if err != nil {
+			return err
+		}
+		attachments, err := eventInvite(conn, &campaign, now)
+		if err != nil {
+			return err
+		}
+		err = sendgridservice.SendCampaignEmailFunc(send.Email, subject, campaign.Text, body, attachments...)
+		if err != nil && !sendgridservice.Retryable(err) {
+			return outbox.Permanent(err)
+		}
+		if err != nil {
+			return err
+		}
+		return conn.Model(&send).Update("sent_at", now).Error
+	}
+}
+
+// eventInvite returns the calendar invite of the event campaign announces, none when it doesn't
+// or the event was deleted
+func eventInvite(conn *gorm.DB, campaign *models.Campaign, now time.Time) ([]sendgridservice.Attachment, error) {
+	if campaign.EventID == nil {
+		return nil, nil
+	}
+	var event models.Event
+	if err := conn.First(&event, *campaign.EventID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return []sendgridservice.Attachment{calendar.Attachment(event, now)}, nil
+}
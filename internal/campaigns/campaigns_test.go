@@ -0,0 +1,464 @@
+package campaigns
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"fiber-gorm-api/internal/models"
+	"fiber-gorm-api/internal/realtime"
+	redisclient "fiber-gorm-api/internal/redis"
+	sendgridservice "fiber-gorm-api/internal/services"
+	"fiber-gorm-api/internal/testutil/testenv"
+	"fiber-gorm-api/internal/tracking"
+)
+
+func TestNextRun(t *testing.T) {
+	// Tuesday July 1st 2025, noon UTC: next Monday 9:00 in Paris is 7:00 UTC (summer time)
+	after := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	next, err := NextRun("0 9 * * MON", "Europe/Paris", after)
+	if err != nil || !next.Equal(time.Date(2025, 7, 7, 7, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected 2025-07-07T07:00Z, got %v (%v)", next, err)
+	}
+	if next.Location() != time.UTC {
+		t.Errorf("Expected the next run in UTC, got %v", next.Location())
+	}
+	// in winter it's 8:00 UTC
+	next, _ = NextRun("0 9 * * MON", "Europe/Paris", time.Date(2025, 12, 2, 12, 0, 0, 0, time.UTC))
+	if !next.Equal(time.Date(2025, 12, 8, 8, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected 2025-12-08T08:00Z, got %v", next)
+	}
+	if next, err := NextRun("@weekly", "", after); err != nil || next.Weekday() != time.Sunday {
+		t.Errorf("Expected @weekly on Sunday, got %v (%v)", next, err)
+	}
+
+	for _, invalid := range []struct{ recurrence, tz string }{
+		{"every monday", "UTC"},
+		{"@every 5m", "UTC"},
+		{"*/10 * * * *", "UTC"},
+		{"0 9 * * MON", "Mars/Olympus"},
+		{"0 9 * * MON", "Local"},
+	} {
+		if _, err := NextRun(invalid.recurrence, invalid.tz, after); err == nil {
+			t.Errorf("%s in %s: expected an error", invalid.recurrence, invalid.tz)
+		}
+	}
+
+	// runs unevenly apart: 08:50 to 10:00 is long enough, 10:00 to 10:50 isn't
+	if _, err := NextRun("0,50 8,10 * * *", "UTC", time.Date(2025, 7, 1, 8, 10, 0, 0, time.UTC)); err == nil {
+		t.Error("Expected an error for runs 50 minutes apart after the next one")
+	}
+}
+
+func TestParseSendAt(t *testing.T) {
+	at, err := ParseSendAt("2025-07-01T09:00", "America/New_York")
+	if err != nil || !at.Equal(time.Date(2025, 7, 1, 13, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected 9:00 in New York read as 13:00Z, got %v (%v)", at, err)
+	}
+	at, err = ParseSendAt("2025-07-01T09:00:00+02:00", "America/New_York")
+	if err != nil || !at.Equal(time.Date(2025, 7, 1, 7, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected an RFC 3339 time kept as is, got %v (%v)", at, err)
+	}
+	if _, err := ParseSendAt("tomorrow", "UTC"); err == nil {
+		t.Error("Expected an error for an unreadable time")
+	}
+}
+
+func TestDispatch(t *testing.T) {
+	t.Setenv("REDIS_HOST", "")
+	redisclient.InitRedis("session")
+	conn := testenv.SQLite(t)
+
+	for _, s := range []models.Subscriber{
+		{OrgID: models.DefaultOrgID, Email: "ada@example.com", Status: models.SubscriberStatusActive},
+		{OrgID: models.DefaultOrgID, Email: "grace@example.com", Status: models.SubscriberStatusActive},
+		{OrgID: models.DefaultOrgID, Email: "gone@example.com", Status: models.SubscriberStatusUnsubscribed},
+		{OrgID: models.DefaultOrgID + 1, Email: "other@example.com", Status: models.SubscriberStatusActive},
+	} {
+		if err := conn.Create(&s).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	now := time.Now().UTC()
+	past := now.Add(-time.Minute)
+	create := func(c models.Campaign) models.Campaign {
+		t.Helper()
+		c.OrgID, c.Name, c.Subject, c.Text = models.DefaultOrgID, "Roundup", "This week", "Hello"
+		if c.Status == "" {
+			c.Status = models.CampaignScheduled
+		}
+		if c.Timezone == "" {
+			c.Timezone = "UTC"
+		}
+		if err := conn.Create(&c).Error; err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+	reload := func(c models.Campaign) models.Campaign {
+		t.Helper()
+		var fresh models.Campaign
+		conn.First(&fresh, c.ID)
+		return fresh
+	}
+	sends := func(c models.Campaign) int64 {
+		var n int64
+		conn.Model(&models.CampaignSend{}).Where("campaign_id = ?", c.ID).Count(&n)
+		return n
+	}
+
+	t.Run("Queues each active subscriber once", func(t *testing.T) {
+		campaign := create(models.Campaign{NextRunAt: &past})
+		queued, err := ProcessDue(conn, now)
+		if err != nil || queued != 2 {
+			t.Fatalf("Expected 2 emails queued, got %d (%v)", queued, err)
+		}
+		campaign = reload(campaign)
+		if campaign.Status != models.CampaignSent || campaign.Run != 1 || campaign.SentCount != 2 || campaign.NextRunAt != nil {
+			t.Errorf("Expected the campaign sent once to 2, got %+v", campaign)
+		}
+		var events int64
+		conn.Model(&models.OutboxEvent{}).Where("topic = ?", TopicSend).Count(&events)
+		if events != 2 || sends(campaign) != 2 {
+			t.Errorf("Expected 2 sends and events, got %d and %d", sends(campaign), events)
+		}
+
+		// a dispatcher that died mid-run leaves the campaign sending; picking it up again
+		// queues no one twice
+		conn.Model(&campaign).Update("status", models.CampaignSending)
+		if queued, err := ProcessDue(conn, now); err != nil || queued != 0 {
+			t.Errorf("Expected nothing queued again, got %d (%v)", queued, err)
+		}
+		if sends(campaign) != 2 || reload(campaign).Status != models.CampaignSent {
+			t.Errorf("Expected the run finished without new sends, got %d", sends(campaign))
+		}
+	})
+
+	t.Run("Publishes the progress of a run", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		pubsub := realtime.Subscribe(ctx, models.DefaultOrgID)
+		defer pubsub.Close()
+		// the subscription is only live once confirmed
+		if _, err := pubsub.Receive(ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		campaign := create(models.Campaign{NextRunAt: &past})
+		if queued, err := Dispatch(conn, &campaign, now); err != nil || queued != 2 {
+			t.Fatalf("Expected 2 emails queued, got %d (%v)", queued, err)
+		}
+		select {
+		case msg := <-pubsub.Channel():
+			event, err := realtime.Decode(msg.Payload)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if event.Topic != realtime.TopicCampaignProgress || event.CampaignID != campaign.ID || event.Run != 1 ||
+				event.Status != models.CampaignSending || event.Processed != 2 {
+				t.Errorf("Expected the 2 emails of run 1 queued, got %+v", event)
+			}
+		case <-ctx.Done():
+			t.Fatal("Expected a progress event, got none")
+		}
+	})
+
+	t.Run("Skips campaigns another dispatcher holds", func(t *testing.T) {
+		campaign := create(models.Campaign{NextRunAt: &past})
+		if ok, err := redisclient.SetIfAbsent(redisclient.Ctx, lockKey(campaign.ID), "other", time.Minute); err != nil || !ok {
+			t.Fatalf("Expected the lock taken, got %t (%v)", ok, err)
+		}
+		if _, err := Dispatch(conn, &campaign, now); !errors.Is(err, ErrLocked) {
+			t.Errorf("Expected ErrLocked, got %v", err)
+		}
+		if reload(campaign).Status != models.CampaignScheduled || sends(campaign) != 0 {
+			t.Error("Expected the campaign left scheduled")
+		}
+		// released only by its holder
+		redisclient.DeleteIfValue(redisclient.Ctx, lockKey(campaign.ID), "mine")
+		if _, err := Dispatch(conn, &campaign, now); !errors.Is(err, ErrLocked) {
+			t.Errorf("Expected the lock kept, got %v", err)
+		}
+		redisclient.DeleteIfValue(redisclient.Ctx, lockKey(campaign.ID), "other")
+		if queued, err := Dispatch(conn, &campaign, now); err != nil || queued != 2 {
+			t.Errorf("Expected 2 emails queued once released, got %d (%v)", queued, err)
+		}
+	})
+
+	t.Run("Reschedules recurring campaigns", func(t *testing.T) {
+		campaign := create(models.Campaign{NextRunAt: &past, Recurrence: "0 9 * * MON", Timezone: "Europe/Paris"})
+		if _, err := ProcessDue(conn, now); err != nil {
+			t.Fatal(err)
+		}
+		campaign = reload(campaign)
+		if campaign.Status != models.CampaignScheduled || campaign.Run != 1 || campaign.NextRunAt == nil || !campaign.NextRunAt.After(now) {
+			t.Fatalf("Expected the next run scheduled, got %+v", campaign)
+		}
+		if in := campaign.NextRunAt.In(mustLocation(t, "Europe/Paris")); in.Weekday() != time.Monday || in.Hour() != 9 {
+			t.Errorf("Expected Monday 9:00 in Paris, got %v", in)
+		}
+
+		// the next run goes to everyone again
+		next := *campaign.NextRunAt
+		if _, err := ProcessDue(conn, next); err != nil {
+			t.Fatal(err)
+		}
+		if campaign = reload(campaign); campaign.Run != 2 || sends(campaign) != 4 {
+			t.Errorf("Expected a second run to 2, got run %d with %d sends", campaign.Run, sends(campaign))
+		}
+	})
+
+	t.Run("Paused campaigns wait for resume", func(t *testing.T) {
+		campaign := create(models.Campaign{NextRunAt: &past})
+		if ok, err := Pause(conn, campaign.ID); err != nil || !ok {
+			t.Fatalf("Expected the campaign paused, got %t (%v)", ok, err)
+		}
+		if ok, _ := Pause(conn, campaign.ID); ok {
+			t.Error("Expected pausing twice refused")
+		}
+		ProcessDue(conn, now)
+		if sends(campaign) != 0 {
+			t.Error("Expected nothing sent while paused")
+		}
+		campaign = reload(campaign)
+		if ok, err := Resume(conn, &campaign, now); err != nil || !ok {
+			t.Fatalf("Expected the campaign resumed, got %t (%v)", ok, err)
+		}
+		if reload(campaign).Status != models.CampaignScheduled {
+			t.Errorf("Expected the campaign scheduled again, got %s", reload(campaign).Status)
+		}
+		ProcessDue(conn, now)
+		if sends(campaign) != 2 {
+			t.Errorf("Expected the campaign sent once resumed, got %d sends", sends(campaign))
+		}
+
+		// a run paused midway is picked up where it stopped
+		campaign = create(models.Campaign{Status: models.CampaignPaused, Run: 1})
+		conn.Create(&models.CampaignSend{CampaignID: campaign.ID, Run: 1, SubscriberID: 1, Email: "ada@example.com"})
+		if ok, _ := Resume(conn, &campaign, now); !ok || reload(campaign).Status != models.CampaignSending {
+			t.Fatalf("Expected the interrupted run resumed, got %s", reload(campaign).Status)
+		}
+		if queued, _ := ProcessDue(conn, now); queued != 1 || sends(campaign) != 2 {
+			t.Errorf("Expected only the rest queued, got %d", queued)
+		}
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		campaign := create(models.Campaign{NextRunAt: &past})
+		if ok, err := Cancel(conn, campaign.ID); err != nil || !ok {
+			t.Fatalf("Expected the campaign cancelled, got %t (%v)", ok, err)
+		}
+		if ok, _ := Cancel(conn, campaign.ID); ok {
+			t.Error("Expected cancelling twice refused")
+		}
+		campaign = reload(campaign)
+		if ok, _ := Resume(conn, &campaign, now); ok {
+			t.Error("Expected a cancelled campaign not resumable")
+		}
+		ProcessDue(conn, now)
+		if sends(campaign) != 0 {
+			t.Error("Expected nothing sent once cancelled")
+		}
+	})
+
+	t.Run("A/B tests the subject, then sends the winner", func(t *testing.T) {
+		org := models.DefaultOrgID + 2
+		for i := 0; i < 200; i++ {
+			conn.Create(&models.Subscriber{OrgID: org, Email: fmt.Sprintf("ab-%d@example.com", i), Status: models.SubscriberStatusActive})
+		}
+		campaign := models.Campaign{OrgID: org, Name: "A/B", Subject: "Subject A", SubjectB: "Subject B", Text: "Hello",
+			Status: models.CampaignScheduled, Timezone: "UTC", NextRunAt: &past, TestPercent: 20, TestHours: 4}
+		conn.Create(&campaign)
+
+		queued, err := ProcessDue(conn, now)
+		if err != nil || queued < 20 || queued > 60 {
+			t.Fatalf("Expected about 40 emails to the test group, got %d (%v)", queued, err)
+		}
+		campaign = reload(campaign)
+		if campaign.Status != models.CampaignTesting || campaign.TestEndsAt == nil || campaign.TestEndsAt.Sub(now.Add(4*time.Hour)).Abs() > time.Second {
+			t.Fatalf("Expected the campaign testing for 4 hours, got %s until %v", campaign.Status, campaign.TestEndsAt)
+		}
+		var test []models.CampaignSend
+		conn.Where("campaign_id = ?", campaign.ID).Find(&test)
+		byVariant := map[string]int{}
+		for _, send := range test {
+			if !send.Test {
+				t.Fatalf("Expected only the test group queued, got %+v", send)
+			}
+			byVariant[send.Variant]++
+			// B gets opened
+			if send.Variant == VariantB {
+				RecordOpen(conn, send.ID, "Mozilla/5.0", now)
+			}
+		}
+		if byVariant[VariantA] == 0 || byVariant[VariantB] == 0 {
+			t.Fatalf("Expected both subjects tested, got %v", byVariant)
+		}
+
+		// not before the test is over
+		if queued, _ := Dispatch(conn, &campaign, now.Add(time.Hour)); queued != 0 || reload(campaign).Status != models.CampaignTesting {
+			t.Errorf("Expected the test still running, got %d queued", queued)
+		}
+		campaign = reload(campaign)
+		if queued, err := Dispatch(conn, &campaign, now.Add(5*time.Hour)); err != nil || queued != 200-len(test) {
+			t.Errorf("Expected the remainder queued, got %d (%v)", queued, err)
+		}
+		campaign = reload(campaign)
+		if campaign.Status != models.CampaignSent || campaign.Winner != VariantB || campaign.SentCount != 200 {
+			t.Errorf("Expected B sent to everyone else, got %s won by %q, %d sent", campaign.Status, campaign.Winner, campaign.SentCount)
+		}
+
+		stats, err := RunStats(conn, campaign.ID, campaign.Run)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []VariantStats{
+			{Variant: VariantA, Test: true, Sent: byVariant[VariantA]},
+			{Variant: VariantB, Test: true, Sent: byVariant[VariantB], Opened: byVariant[VariantB]},
+			{Variant: VariantB, Sent: 200 - len(test)},
+		}
+		if fmt.Sprint(stats) != fmt.Sprint(want) {
+			t.Errorf("Expected stats %v, got %v", want, stats)
+		}
+	})
+
+	t.Run("Deliver", func(t *testing.T) {
+		var sent []string
+		var body string
+		var invites []sendgridservice.Attachment
+		original := sendgridservice.SendCampaignEmailFunc
+		t.Cleanup(func() { sendgridservice.SendCampaignEmailFunc = original })
+		sendgridservice.SendCampaignEmailFunc = func(toEmail, subject, plainText, htmlContent string, attachments ...sendgridservice.Attachment) error {
+			sent = append(sent, toEmail+" "+subject)
+			body = htmlContent
+			invites = attachments
+			return nil
+		}
+
+		campaign := create(models.Campaign{NextRunAt: &past})
+		ProcessDue(conn, now)
+		var queued []models.CampaignSend
+		conn.Where("campaign_id = ?", campaign.ID).Order("id").Find(&queued)
+		deliver := Deliver(conn)
+		event := func(send models.CampaignSend) models.OutboxEvent {
+			raw, _ := json.Marshal(Email{SendID: send.ID})
+			return models.OutboxEvent{Topic: TopicSend, Payload: string(raw)}
+		}
+
+		if err := deliver(event(queued[0])); err != nil || len(sent) != 1 || sent[0] != "ada@example.com This week" {
+			t.Errorf("Expected the email sent, got %v (%v)", sent, err)
+		}
+		if !strings.Contains(body, `<img src="`+tracking.URL(tracking.KindOpen, queued[0].ID, 0, time.Now())+`"`) {
+			t.Errorf("Expected the tracking pixel in the email, got %s", body)
+		}
+		if reload := queued[0]; conn.First(&reload, reload.ID).Error != nil || reload.SentAt == nil {
+			t.Error("Expected the email recorded as sent")
+		}
+
+		// variant B gets subject B
+		conn.Model(&campaign).Update("subject_b", "Subject B")
+		conn.Model(&queued[1]).Update("variant", VariantB)
+		if err := deliver(event(queued[1])); err != nil || len(sent) != 2 || sent[1] != "grace@example.com Subject B" {
+			t.Errorf("Expected subject B sent, got %v (%v)", sent, err)
+		}
+		if len(invites) != 0 {
+			t.Errorf("Expected no invite without an event, got %v", invites)
+		}
+
+		// a campaign announcing an event carries its invite, unless it was deleted
+		market := models.Event{OrgID: models.DefaultOrgID, Title: "Market day", StartsAt: now.Add(48 * time.Hour), EndsAt: now.Add(52 * time.Hour), Timezone: "UTC"}
+		conn.Create(&market)
+		conn.Model(&campaign).Update("event_id", market.ID)
+		if err := deliver(event(queued[0])); err != nil || len(invites) != 1 || invites[0].Filename != "market-day.ics" ||
+			!strings.Contains(string(invites[0].Content), "SUMMARY:Market day") {
+			t.Errorf("Expected the invite of the event attached, got %v (%v)", invites, err)
+		}
+		conn.Delete(&market)
+		if err := deliver(event(queued[0])); err != nil || len(invites) != 0 {
+			t.Errorf("Expected the email sent without the invite of a deleted event, got %v (%v)", invites, err)
+		}
+
+		conn.Model(&campaign).Update("status", models.CampaignCancelled)
+		if err := deliver(event(queued[0])); err != nil || len(sent) != 4 {
+			t.Errorf("Expected the email of a cancelled campaign dropped, got %v (%v)", sent, err)
+		}
+	})
+
+	t.Run("Tracks links and clicks", func(t *testing.T) {
+		var body string
+		original := sendgridservice.SendCampaignEmailFunc
+		t.Cleanup(func() { sendgridservice.SendCampaignEmailFunc = original })
+		sendgridservice.SendCampaignEmailFunc = func(toEmail, subject, plainText, htmlContent string, attachments ...sendgridservice.Attachment) error {
+			body = htmlContent
+			return nil
+		}
+
+		campaign := create(models.Campaign{NextRunAt: &past})
+		conn.Model(&campaign).Update("html", `<p><a href="https://example.com/a?x=1&amp;y=2">A</a> <a href='https://example.com/b'>B</a> <a href="mailto:hi@example.com">Mail</a></p>`)
+		ProcessDue(conn, now)
+		var links []models.CampaignLink
+		conn.Where("campaign_id = ?", campaign.ID).Order("id").Find(&links)
+		if len(links) != 2 || links[0].URL != "https://example.com/a?x=1&y=2" || links[1].URL != "https://example.com/b" {
+			t.Fatalf("Expected the 2 http links registered, got %+v", links)
+		}
+
+		var queued []models.CampaignSend
+		conn.Where("campaign_id = ?", campaign.ID).Order("id").Find(&queued)
+		raw, _ := json.Marshal(Email{SendID: queued[0].ID})
+		if err := Deliver(conn)(models.OutboxEvent{Topic: TopicSend, Payload: string(raw)}); err != nil {
+			t.Fatal(err)
+		}
+		sentAt := time.Now()
+		for _, link := range links {
+			if want := `href="` + tracking.URL(tracking.KindClick, queued[0].ID, link.ID, sentAt) + `"`; !strings.Contains(body, want) {
+				t.Errorf("Expected %s tracked, got %s", link.URL, body)
+			}
+		}
+		if !strings.Contains(body, `href="mailto:hi@example.com"`) {
+			t.Errorf("Expected the mailto link left as is, got %s", body)
+		}
+
+		// a scanner following the links as soon as the email arrives isn't counted
+		RecordClick(conn, queued[0].ID, links[0].ID, "Mozilla/5.0", sentAt)
+		if stats, _ := RunLinkStats(conn, campaign.ID, 1); len(stats) != 0 {
+			t.Errorf("Expected no click right after sending, got %+v", stats)
+		}
+
+		later := sentAt.Add(time.Minute)
+		RecordClick(conn, queued[0].ID, links[1].ID, "Mozilla/5.0", later)
+		RecordClick(conn, queued[0].ID, links[1].ID, "Mozilla/5.0", later.Add(time.Minute))
+		RecordClick(conn, queued[1].ID, links[1].ID, "Mozilla/5.0", later)
+		RecordClick(conn, queued[1].ID, links[0].ID, "Mozilla/5.0", later)
+		stats, err := RunLinkStats(conn, campaign.ID, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []LinkStats{
+			{LinkID: links[1].ID, URL: links[1].URL, Clicks: 3, Recipients: 2},
+			{LinkID: links[0].ID, URL: links[0].URL, Clicks: 1, Recipients: 1},
+		}
+		if fmt.Sprint(stats) != fmt.Sprint(want) {
+			t.Errorf("Expected link stats %v, got %v", want, stats)
+		}
+
+		// a click is an open too
+		variants, _ := RunStats(conn, campaign.ID, 1)
+		if len(variants) != 1 || variants[0].Opened != 2 || variants[0].Clicked != 2 {
+			t.Errorf("Expected 2 recipients opened and clicked, got %+v", variants)
+		}
+	})
+}
+
+func mustLocation(t *testing.T, tz string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return loc
+}
@@ -0,0 +1,178 @@
+package campaigns
+
+import (
+	"errors"
+	"html"
+	"regexp"
+	"strings"
+	"time"
+
+	"fiber-gorm-api/internal/models"
+	sendgridservice "fiber-gorm-api/internal/services"
+	"fiber-gorm-api/internal/shortlinks"
+	"fiber-gorm-api/internal/tracking"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// maxLinkLength bounds the links tracked, longer ones being left as they are
+	maxLinkLength = 2048
+	// minHumanDelay is how soon after an email was sent its opens and clicks are taken for the
+	// link scanners of mail security gateways rather than the recipient's
+	minHumanDelay = 5 * time.Second
+	maxUserAgent  = 255
+)
+
+// closingBody is the end of the body of an HTML email, before which the tracking pixel goes
+var closingBody = regexp.MustCompile(`(?i)</body\s*>`)
+
+// trackedLink matches the http(s) links of an HTML email, in double or single quotes
+var trackedLink = regexp.MustCompile(`(?i)\bhref\s*=\s*(?:"(https?://[^"]*)"|'(https?://[^']*)')`)
+
+// linksOf returns the distinct links of an HTML email that can be tracked, unescaped. Short
+// links count their own clicks, and stay as they are for their branding.
+func linksOf(body string) []string {
+	var links []string
+	seen := map[string]bool{}
+	for _, m := range trackedLink.FindAllStringSubmatch(body, -1) {
+		link := html.UnescapeString(m[1] + m[2])
+		if len(link) > maxLinkLength || seen[link] || shortlinks.CodeOf(link) != "" {
+			continue
+		}
+		seen[link] = true
+		links = append(links, link)
+	}
+	return links
+}
+
+// registerLinks records the links of the HTML of a campaign, which Deliver then tracks
+func registerLinks(conn *gorm.DB, campaign *models.Campaign) error {
+	for _, link := range linksOf(campaign.HTML) {
+		err := conn.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.CampaignLink{CampaignID: campaign.ID, URL: link}).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// trackLinks points the links of an HTML email registered in links (by URL) at their click
+// tracking URL for the recipient sendID
+func trackLinks(body string, links map[string]uint, sendID uint, now time.Time) string {
+	return trackedLink.ReplaceAllStringFunc(body, func(match string) string {
+		m := trackedLink.FindStringSubmatch(match)
+		id, ok := links[html.UnescapeString(m[1]+m[2])]
+		if !ok {
+			return match
+		}
+		return `href="` + html.EscapeString(tracking.URL(tracking.KindClick, sendID, id, now)) + `"`
+	})
+}
+
+// withPixel returns the HTML of an email, made from its text when there's none, with the
+// tracking pixel src at the end of its body
+func withPixel(htmlBody, text, src string) string {
+	if htmlBody == "" {
+		htmlBody = sendgridservice.TextHTML(text)
+	}
+	img := `<img src="` + html.EscapeString(src) + `" width="1" height="1" alt="" style="border:0">`
+	matches := closingBody.FindAllStringIndex(htmlBody, -1)
+	if len(matches) == 0 {
+		return htmlBody + img
+	}
+	at := matches[len(matches)-1][0]
+	return htmlBody[:at] + img + htmlBody[at:]
+}
+
+// trackedBody returns the HTML of the email of a recipient, its links tracked and the pixel
+// added. Only the links of the HTML are tracked, not those of the text.
+func trackedBody(conn *gorm.DB, campaign *models.Campaign, send *models.CampaignSend, now time.Time) (string, error) {
+	body := campaign.HTML
+	if body != "" {
+		var links []models.CampaignLink
+		if err := conn.Where("campaign_id = ?", campaign.ID).Find(&links).Error; err != nil {
+			return "", err
+		}
+		byURL := make(map[string]uint, len(links))
+		for _, link := range links {
+			byURL[link.URL] = link.ID
+		}
+		body = trackLinks(body, byURL, send.ID, now)
+	}
+	return withPixel(body, campaign.Text, tracking.URL(tracking.KindOpen, send.ID, 0, now)), nil
+}
+
+// RecordOpen records an open of the email of the recipient sendID by userAgent, the caller
+// having left bots out
+func RecordOpen(conn *gorm.DB, sendID uint, userAgent string, now time.Time) error {
+	return record(conn, sendID, nil, models.CampaignEventOpen, userAgent, now)
+}
+
+// RecordClick records a click of the recipient sendID on the link linkID of its email, which
+// also counts as an open
+func RecordClick(conn *gorm.DB, sendID, linkID uint, userAgent string, now time.Time) error {
+	return record(conn, sendID, &linkID, models.CampaignEventClick, userAgent, now)
+}
+
+func record(conn *gorm.DB, sendID uint, linkID *uint, eventType, userAgent string, now time.Time) error {
+	var send models.CampaignSend
+	if err := conn.First(&send, sendID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if send.SentAt != nil && now.Sub(*send.SentAt) < minHumanDelay {
+		return nil
+	}
+	if len(userAgent) > maxUserAgent {
+		userAgent = strings.ToValidUTF8(userAgent[:maxUserAgent], "")
+	}
+
+	return conn.Transaction(func(tx *gorm.DB) error {
+		event := models.CampaignEvent{
+			CampaignID: send.CampaignID,
+			Run:        send.Run,
+			SendID:     send.ID,
+			Type:       eventType,
+			LinkID:     linkID,
+			UserAgent:  userAgent,
+		}
+		if err := tx.Create(&event).Error; err != nil {
+			return err
+		}
+		err := tx.Model(&models.CampaignSend{}).Where("id = ? AND opened_at IS NULL", send.ID).
+			Update("opened_at", now).Error
+		if err != nil || eventType != models.CampaignEventClick {
+			return err
+		}
+		return tx.Model(&models.CampaignSend{}).Where("id = ? AND clicked_at IS NULL", send.ID).
+			Update("clicked_at", now).Error
+	})
+}
+
+// LinkStats counts the clicks on a link of a campaign in a run, and the recipients who clicked
+type LinkStats struct {
+	LinkID     uint
+	URL        string
+	Clicks     int
+	Recipients int
+}
+
+// RunLinkStats counts the clicks on each link of a campaign in a run, most clicked first
+func RunLinkStats(conn *gorm.DB, campaignID uint, run int) ([]LinkStats, error) {
+	var stats []LinkStats
+	err := conn.Model(&models.CampaignEvent{}).
+		Select("campaign_links.id AS link_id, campaign_links.url, COUNT(*) AS clicks, "+
+			"COUNT(DISTINCT campaign_events.send_id) AS recipients").
+		Joins("JOIN campaign_links ON campaign_links.id = campaign_events.link_id").
+		Where("campaign_events.campaign_id = ? AND campaign_events.run = ? AND campaign_events.type = ?",
+			campaignID, run, models.CampaignEventClick).
+		Group("campaign_links.id, campaign_links.url").
+		Order("clicks DESC, campaign_links.id").
+		Scan(&stats).Error
+	return stats, err
+}
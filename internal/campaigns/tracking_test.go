@@ -0,0 +1,40 @@
+package campaigns
+
+import (
+	"testing"
+	"time"
+
+	"fiber-gorm-api/internal/tracking"
+)
+
+func TestWithPixel(t *testing.T) {
+	img := `<img src="https://api.example.com/t/o/x?a=1&amp;b=2" width="1" height="1" alt="" style="border:0">`
+	for _, c := range []struct{ html, text, want string }{
+		{"<html><body><p>Hi</p></BODY></html>", "", "<html><body><p>Hi</p>" + img + "</BODY></html>"},
+		{"<p>Hi</p>", "", "<p>Hi</p>" + img},
+		{"", "Hi <you>\nBye", "Hi &lt;you&gt;<br>Bye" + img},
+	} {
+		if got := withPixel(c.html, c.text, "https://api.example.com/t/o/x?a=1&b=2"); got != c.want {
+			t.Errorf("Expected %s, got %s", c.want, got)
+		}
+	}
+}
+
+func TestTrackLinks(t *testing.T) {
+	t.Setenv("TRACKING_BASE_URL", "https://api.example.com")
+	t.Setenv("SHORT_LINK_BASE_URL", "https://go.example.com")
+	body := `<a href="https://example.com/a?x=1&amp;y=2">A</a><a HREF = 'https://example.com/b'>B</a>` +
+		`<a href="https://example.com/a?x=1&amp;y=2">A again</a><a href="/relative">R</a><a href="https://go.example.com/l/spring">S</a>`
+	links := linksOf(body)
+	if len(links) != 2 || links[0] != "https://example.com/a?x=1&y=2" || links[1] != "https://example.com/b" {
+		t.Fatalf("Expected the 2 distinct links, got %v", links)
+	}
+
+	now := time.Now()
+	got := trackLinks(body, map[string]uint{links[0]: 3}, 9, now)
+	a := `<a href="https://api.example.com/t/c/` + tracking.Token(tracking.KindClick, 9, 3, now) + `">`
+	want := a + `A</a><a HREF = 'https://example.com/b'>B</a>` + a + `A again</a><a href="/relative">R</a><a href="https://go.example.com/l/spring">S</a>`
+	if got != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+}
@@ -1,15 +1,82 @@
 package db
 
 import (
+	"database/sql"
 	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
 
+	"fiber-gorm-api/internal/models"
+
+	"github.com/glebarez/sqlite"
+	"github.com/uptrace/opentelemetry-go-extra/otelgorm"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/plugin/dbresolver"
+)
+
+// Database backends selected with DB_DRIVER
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// defaultSQLitePath is the SQLite file without DB_NAME; tests keep theirs in memory instead of
+// leaving a file in each package directory
+const defaultSQLitePath = "mylocal.db"
+
+// Postgres connection pool defaults, overridden with DB_MAX_OPEN_CONNS, DB_MAX_IDLE_CONNS,
+// DB_CONN_MAX_LIFETIME_MINUTES and DB_CONN_MAX_IDLE_TIME_MINUTES
+const (
+	defaultMaxOpenConns    = 25
+	defaultMaxIdleConns    = 10
+	defaultConnMaxLifetime = 30 * time.Minute
+	defaultConnMaxIdleTime = 5 * time.Minute
+)
+
+// replicaResolver names the dbresolver of the DB_REPLICA_DSN read replica
+const replicaResolver = "replica"
+
+var (
+	sqliteDB   *gorm.DB
+	sqliteOnce sync.Once
 )
 
+// inUse is the database Connect returns while set with Use
+var inUse *gorm.DB
+
+// Connect opens the database selected by DB_DRIVER: Postgres (the default) as the admin or
+// worker user, or SQLite for tests and small deployments.
 func Connect(admin bool) *gorm.DB {
+	if inUse != nil {
+		return inUse
+	}
+	switch driver := os.Getenv("DB_DRIVER"); driver {
+	case "", DriverPostgres:
+		return connectPostgres(admin)
+	case DriverSQLite:
+		return connectSQLite()
+	default:
+		log.Fatalf("Unknown DB_DRIVER %q, expected postgres or sqlite", driver)
+		return nil
+	}
+}
+
+// Use makes Connect return conn, for the admin and worker users alike, until restore is called.
+// Tests use it to register routes, which connect on their own, on a database of theirs; it
+// isn't safe for tests running in parallel.
+func Use(conn *gorm.DB) (restore func()) {
+	previous := inUse
+	inUse = conn
+	return func() { inUse = previous }
+}
+
+func connectPostgres(admin bool) *gorm.DB {
 	var user string
 	var password string
 	if admin {
@@ -28,11 +95,218 @@ func Connect(admin bool) *gorm.DB {
 		"host=%s user=%s password=%s dbname=%s port=%s sslmode=%s",
 		host, user, password, dbname, port, sslmode,
 	)
+	// how many prepared statements pgx keeps per connection, 512 by default
+	if n, err := strconv.Atoi(os.Getenv("DB_STATEMENT_CACHE_CAPACITY")); err == nil && n >= 0 {
+		dsn += fmt.Sprintf(" statement_cache_capacity=%d", n)
+	}
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	db, err := gorm.Open(postgres.Open(dsn), gormConfig())
+	if err != nil {
+		log.Fatalf("Failed to connect to DB: %v", err)
+	}
+	sqlDB, err := db.DB()
 	if err != nil {
 		log.Fatalf("Failed to connect to DB: %v", err)
 	}
+	tunePool(sqlDB)
+	instrument(db)
+	if replicaDSN := os.Getenv("DB_REPLICA_DSN"); replicaDSN != "" {
+		if err := useReplica(db, postgres.Open(replicaDSN)); err != nil {
+			log.Fatalf("Failed to connect to the DB replica: %v", err)
+		}
+	}
 
 	return db
 }
+
+// gormConfig is the GORM configuration of every connection. Statements are prepared once per
+// connection and reused, which spares the database parsing and planning them on every call;
+// DB_PREPARE_STMT=false turns it off, e.g. behind PgBouncer in transaction mode.
+func gormConfig() *gorm.Config {
+	return &gorm.Config{PrepareStmt: os.Getenv("DB_PREPARE_STMT") != "false"}
+}
+
+// tunePool sizes a Postgres connection pool. Prepared statements live on their connection, so
+// idle connections are kept rather than reopened and reprepared on the next burst.
+func tunePool(pool *sql.DB) {
+	pool.SetMaxOpenConns(envInt("DB_MAX_OPEN_CONNS", defaultMaxOpenConns))
+	pool.SetMaxIdleConns(envInt("DB_MAX_IDLE_CONNS", defaultMaxIdleConns))
+	pool.SetConnMaxLifetime(envMinutes("DB_CONN_MAX_LIFETIME_MINUTES", defaultConnMaxLifetime))
+	pool.SetConnMaxIdleTime(envMinutes("DB_CONN_MAX_IDLE_TIME_MINUTES", defaultConnMaxIdleTime))
+}
+
+// envInt reads the positive integer in the environment variable name, def when unset or invalid
+func envInt(name string, def int) int {
+	if n, err := strconv.Atoi(os.Getenv(name)); err == nil && n > 0 {
+		return n
+	}
+	return def
+}
+
+// envMinutes reads the positive number of minutes in the environment variable name, def when
+// unset or invalid
+func envMinutes(name string, def time.Duration) time.Duration {
+	return time.Duration(envInt(name, int(def/time.Minute))) * time.Minute
+}
+
+// useReplica registers replica as the read replica of db. Queries only go to it through
+// Replica: everything else, reads included, stays on the primary so nothing reads a replica
+// lagging behind its own writes.
+func useReplica(db *gorm.DB, replica gorm.Dialector) error {
+	resolver := dbresolver.Register(dbresolver.Config{
+		Replicas: []gorm.Dialector{replica},
+	}, replicaResolver)
+	if err := db.Use(resolver); err != nil {
+		return err
+	}
+	// the replica's pool is sized like the primary's
+	return resolver.Call(func(pool gorm.ConnPool) error {
+		if sqlDB, ok := pool.(*sql.DB); ok {
+			tunePool(sqlDB)
+		}
+		return nil
+	})
+}
+
+// Replica returns a session of db whose reads go to the read replica when DB_REPLICA_DSN is
+// set, db itself otherwise. Writes and transactions always stay on the primary. Meant for the
+// reads that may trail the latest writes a little: lists, searches and exports.
+func Replica(db *gorm.DB) *gorm.DB {
+	if _, ok := db.Config.Plugins[(&dbresolver.DBResolver{}).Name()]; !ok {
+		return db
+	}
+	return db.Clauses(dbresolver.Use(replicaResolver)).Session(&gorm.Session{})
+}
+
+// connectSQLite opens the SQLite file at DB_NAME (":memory:" keeps it in memory, as tests do
+// without one) once per process and creates the schema, which Postgres gets from migrations/migration.sql.
+// SQLite has no users, so the admin and worker connections are the same.
+func connectSQLite() *gorm.DB {
+	sqliteOnce.Do(func() {
+		path := os.Getenv("DB_NAME")
+		if path == "" {
+			path = defaultSQLitePath
+			if testing.Testing() {
+				path = ":memory:"
+			}
+		}
+
+		db, err := OpenSQLite(path)
+		if err != nil {
+			log.Fatalf("Failed to open SQLite DB: %v", err)
+		}
+		log.Printf("Connected to SQLite at %s", path)
+		sqliteDB = db
+	})
+	return sqliteDB
+}
+
+// OpenSQLite opens the SQLite database at path and creates its schema. Connect shares a single
+// one per process, benchmarks open their own.
+func OpenSQLite(path string) (*gorm.DB, error) {
+	db, err := gorm.Open(sqlite.Open(path), gormConfig())
+	if err != nil {
+		return nil, err
+	}
+	// one writer at a time, and an in-memory database only lives on its connection
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(1)
+	instrument(db)
+
+	if err := migrateSQLite(db); err != nil {
+		return nil, fmt.Errorf("migrating: %w", err)
+	}
+	return db, nil
+}
+
+// instrument adds a span per query to the trace of its context. Query arguments are left
+// out, they hold subscribers' emails and names.
+func instrument(db *gorm.DB) {
+	if err := db.Use(otelgorm.NewPlugin(otelgorm.WithoutQueryVariables())); err != nil {
+		log.Printf("[WARN] Could not trace DB queries: %v", err)
+	}
+}
+
+// migrateSQLite creates the tables of every model, the default organization and the default
+// subscriber types
+func migrateSQLite(db *gorm.DB) error {
+	if err := db.AutoMigrate(
+		&models.Organization{},
+		&models.AdminUser{},
+		&models.AdminInvitation{},
+		&models.Subscriber{},
+		&models.SubscriberTypeDefinition{},
+		&models.SubscriberType{},
+		&models.SubscriberNote{},
+		&models.SubscriberRevision{},
+		&models.DeliveryEvent{},
+		&models.ApiKey{},
+		&models.WebAuthnCredential{},
+		&models.TrustedDevice{},
+		&models.OutboxEvent{},
+		&models.AdminActivity{},
+		&models.ExportJob{},
+		&models.ImportJob{},
+		&models.Integration{},
+		&models.IntegrationList{},
+		&models.RestHook{},
+		&models.SignupForm{},
+		&models.Segment{},
+		&models.Campaign{},
+		&models.CampaignSend{},
+		&models.CampaignLink{},
+		&models.CampaignEvent{},
+		&models.ShortLink{},
+		&models.EmailTemplate{},
+		&models.Event{},
+		&models.EmailDeadLetter{},
+		&models.EmailLog{},
+		&models.Session{},
+	); err != nil {
+		return err
+	}
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.Organization{ID: models.DefaultOrgID, Name: "myLocal", Slug: "default"}).Error; err != nil {
+		return err
+	}
+	types := make([]models.SubscriberTypeDefinition, len(models.DefaultSubscriberTypeNames))
+	for i, name := range models.DefaultSubscriberTypeNames {
+		types[i] = models.SubscriberTypeDefinition{Name: name}
+	}
+	return db.Clauses(clause.OnConflict{DoNothing: true}).Create(&types).Error
+}
+
+// IsSQLite reports whether db runs on the SQLite backend
+func IsSQLite(db *gorm.DB) bool {
+	return db.Dialector.Name() == DriverSQLite
+}
+
+// ILike returns a case-insensitive "column LIKE ?" condition for the backend of db. Patterns
+// escape wildcards with a backslash, Postgres' default and explicit on SQLite, whose LIKE
+// already ignores (ASCII) case.
+func ILike(db *gorm.DB, column string) string {
+	if IsSQLite(db) {
+		return column + ` LIKE ? ESCAPE '\'`
+	}
+	return column + " ILIKE ?"
+}
+
+// TruncTime returns an expression truncating column to the start of its day, week (Monday,
+// like Postgres' date_trunc) or month. On SQLite the result is a "YYYY-MM-DD" string.
+func TruncTime(db *gorm.DB, unit, column string) string {
+	if !IsSQLite(db) {
+		return fmt.Sprintf("date_trunc('%s', %s)", unit, column)
+	}
+	switch unit {
+	case "week":
+		// 'weekday 0' moves forward to Sunday, six days back is that week's Monday
+		return fmt.Sprintf("date(%s, 'weekday 0', '-6 days')", column)
+	case "month":
+		return fmt.Sprintf("date(%s, 'start of month')", column)
+	default:
+		return fmt.Sprintf("date(%s)", column)
+	}
+}
@@ -0,0 +1,131 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"fiber-gorm-api/internal/models"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestSQLite(t *testing.T) {
+	t.Setenv("DB_DRIVER", DriverSQLite)
+	t.Setenv("DB_NAME", ":memory:")
+	db := Connect(true)
+	if !IsSQLite(db) {
+		t.Fatalf("Expected the SQLite backend, got %s", db.Dialector.Name())
+	}
+	if Connect(false) != db {
+		t.Errorf("Expected one SQLite connection per process")
+	}
+
+	var org models.Organization
+	if err := db.First(&org, models.DefaultOrgID).Error; err != nil || org.Slug != "default" {
+		t.Fatalf("Expected the default organization to be seeded, got %+v (%v)", org, err)
+	}
+
+	ctx := context.Background()
+	failed := errors.New("rolled back")
+	err := Tx(ctx, db, func(tx *gorm.DB) error {
+		if err := tx.Create(&models.Subscriber{OrgID: models.DefaultOrgID, Email: "tx@example.com", Name: "Tx"}).Error; err != nil {
+			return err
+		}
+		return failed
+	})
+	if !errors.Is(err, failed) {
+		t.Fatalf("Expected the error of fn, got %v", err)
+	}
+	var count int64
+	db.Model(&models.Subscriber{}).Where("email = ?", "tx@example.com").Count(&count)
+	if count != 0 {
+		t.Errorf("Expected the insert to be rolled back, found %d rows", count)
+	}
+
+	if err := Tx(ctx, db, func(tx *gorm.DB) error {
+		return tx.Create(&models.Subscriber{OrgID: models.DefaultOrgID, Email: "tx@example.com", Name: "Tx"}).Error
+	}); err != nil {
+		t.Fatalf("Expected the transaction to commit, got %v", err)
+	}
+	db.Model(&models.Subscriber{}).Where("email = ?", "tx@example.com").Count(&count)
+	if count != 1 {
+		t.Errorf("Expected the insert to be committed, found %d rows", count)
+	}
+
+	db.Model(&models.Subscriber{}).Where(ILike(db, "email"), "%TX@%").Count(&count)
+	if count != 1 {
+		t.Errorf("Expected a case-insensitive match, found %d rows", count)
+	}
+	db.Model(&models.Subscriber{}).Where(ILike(db, "email"), `%\_%`).Count(&count)
+	if count != 0 {
+		t.Errorf("Expected an escaped wildcard to match literally, found %d rows", count)
+	}
+}
+
+func TestTruncTimeSQLite(t *testing.T) {
+	t.Setenv("DB_DRIVER", DriverSQLite)
+	t.Setenv("DB_NAME", ":memory:")
+	db := Connect(true)
+
+	// Wednesday
+	at := time.Date(2025, 1, 15, 13, 45, 0, 0, time.UTC)
+	cases := map[string]string{"day": "2025-01-15", "week": "2025-01-13", "month": "2025-01-01"}
+	for unit, expected := range cases {
+		var got string
+		if err := db.Raw("SELECT "+TruncTime(db, unit, "?"), at).Scan(&got).Error; err != nil {
+			t.Fatalf("%s: %v", unit, err)
+		}
+		if got != expected {
+			t.Errorf("%s: expected %s, got %s", unit, expected, got)
+		}
+	}
+}
+
+func TestReplica(t *testing.T) {
+	dir := t.TempDir()
+	open := func(name string) *gorm.DB {
+		db, err := gorm.Open(sqlite.Open(filepath.Join(dir, name)), &gorm.Config{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := db.AutoMigrate(&models.Subscriber{}); err != nil {
+			t.Fatal(err)
+		}
+		return db
+	}
+	primary := open("primary.db")
+	replica := open("replica.db")
+	if err := replica.Create(&models.Subscriber{OrgID: models.DefaultOrgID, Email: "replica@example.com", Name: "Replica"}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if Replica(primary) != primary {
+		t.Errorf("Expected the primary without a replica")
+	}
+	if err := useReplica(primary, sqlite.Open(filepath.Join(dir, "replica.db"))); err != nil {
+		t.Fatal(err)
+	}
+
+	// writes through the replica session still land on the primary
+	if err := Replica(primary).Create(&models.Subscriber{OrgID: models.DefaultOrgID, Email: "primary@example.com", Name: "Primary"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	emails := func(db *gorm.DB) []string {
+		var emails []string
+		if err := db.Model(&models.Subscriber{}).Order("email").Pluck("email", &emails).Error; err != nil {
+			t.Fatal(err)
+		}
+		return emails
+	}
+	if got := emails(primary); !slices.Equal(got, []string{"primary@example.com"}) {
+		t.Errorf("Expected plain reads on the primary, got %v", got)
+	}
+	if got := emails(Replica(primary)); !slices.Equal(got, []string{"replica@example.com"}) {
+		t.Errorf("Expected replica reads on the replica, got %v", got)
+	}
+}
@@ -0,0 +1,14 @@
+package db
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// Tx runs fn in a transaction bound to ctx. It commits when fn returns nil and rolls back
+// when fn returns an error or panics (the panic is re-raised). Called with a transaction
+// it nests as a savepoint, so helpers can use Tx whether or not their caller already does.
+func Tx(ctx context.Context, db *gorm.DB, fn func(tx *gorm.DB) error) error {
+	return db.WithContext(ctx).Transaction(fn)
+}
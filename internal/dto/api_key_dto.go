@@ -0,0 +1,54 @@
+package dto
+
+import (
+	"time"
+
+	"fiber-gorm-api/internal/models"
+)
+
+// CreateApiKeyRequest is the body accepted by POST /admin/api-keys.
+type CreateApiKeyRequest struct {
+	Name      string     `json:"name" example:"nightly export"`
+	Scopes    []string   `json:"scopes" example:"read"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// ApiKeyResponse describes an API key without its secret.
+type ApiKeyResponse struct {
+	ID         uint       `json:"id"`
+	OrgID      uint       `json:"org_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix" example:"mylo_AbCdEfG"`
+	Scopes     []string   `json:"scopes"`
+	CreatedBy  string     `json:"created_by"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// CreatedApiKeyResponse is returned once, on creation, and is the only time the key is readable.
+type CreatedApiKeyResponse struct {
+	ApiKeyResponse
+	Key string `json:"key"`
+}
+
+// NewApiKeyResponse maps an ApiKey to its response DTO.
+func NewApiKeyResponse(k models.ApiKey) ApiKeyResponse {
+	scopes := k.ScopeList()
+	if scopes == nil {
+		scopes = []string{}
+	}
+	return ApiKeyResponse{
+		ID:         k.ID,
+		OrgID:      k.OrgID,
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		Scopes:     scopes,
+		CreatedBy:  k.CreatedBy,
+		ExpiresAt:  k.ExpiresAt,
+		LastUsedAt: k.LastUsedAt,
+		RevokedAt:  k.RevokedAt,
+		CreatedAt:  k.CreatedAt,
+	}
+}
@@ -0,0 +1,37 @@
+package dto
+
+// Batch operation kinds.
+const (
+	BatchCreate = "create"
+	BatchUpdate = "update"
+	BatchDelete = "delete"
+)
+
+// BatchOperation is one item of POST /admin/subscribers/batch.
+// ID is required for update/delete, Subscriber for create/update.
+type BatchOperation struct {
+	Op         string                   `json:"op" example:"update" enums:"create,update,delete"`
+	ID         uint                     `json:"id,omitempty"`
+	Subscriber *UpdateSubscriberRequest `json:"subscriber,omitempty"`
+}
+
+// BatchRequest is the body accepted by POST /admin/subscribers/batch.
+type BatchRequest struct {
+	Operations []BatchOperation `json:"operations"`
+}
+
+// BatchResult reports the outcome of one operation, in request order.
+type BatchResult struct {
+	Index      int                 `json:"index"`
+	Op         string              `json:"op"`
+	Status     int                 `json:"status" example:"200"`
+	Error      string              `json:"error,omitempty"`
+	Subscriber *SubscriberResponse `json:"subscriber,omitempty"`
+}
+
+// BatchResponse is returned by POST /admin/subscribers/batch. When Committed is false
+// every operation was rolled back; the failing ones carry an error.
+type BatchResponse struct {
+	Committed bool          `json:"committed"`
+	Results   []BatchResult `json:"results"`
+}
@@ -0,0 +1,204 @@
+package dto
+
+import (
+	"strings"
+	"time"
+
+	"fiber-gorm-api/internal/models"
+)
+
+// CampaignRequest is the body accepted by POST /admin/campaigns and PUT /admin/campaigns/{id}.
+// The campaign goes to the active members of SegmentID, or of Filter without one. SendAt
+// schedules it once; Recurrence, a cron expression, at each of its occurrences from SendAt, or
+// from now without one. Both are read in Timezone. With SubjectB and TestPercent, each run A/B
+// tests the subjects on that share of the audience, sending the one opened most to the rest
+// TestHours later. With EventID, it announces a local event, each email carrying its calendar
+// invite.
+type CampaignRequest struct {
+	Name        string        `json:"name" example:"Weekly roundup"`
+	Subject     string        `json:"subject" example:"This week at the market"`
+	SubjectB    string        `json:"subject_b,omitempty" example:"5 things you missed this week"`
+	TestPercent int           `json:"test_percent,omitempty" example:"20"`
+	TestHours   int           `json:"test_hours,omitempty" example:"4"`
+	Text        string        `json:"text" example:"Hello! Here's what happened this week..."`
+	HTML        string        `json:"html,omitempty" example:"<p>Hello! Here's what happened this week...</p>"`
+	SegmentID   *uint         `json:"segment_id,omitempty" example:"3"`
+	Filter      SegmentFilter `json:"filter"`
+	// SendAt is an RFC 3339 time, or a local date and time read in Timezone
+	SendAt     string `json:"send_at,omitempty" example:"2025-07-01T09:00"`
+	Timezone   string `json:"timezone,omitempty" example:"Europe/Paris"`
+	Recurrence string `json:"recurrence,omitempty" example:"0 9 * * MON"`
+	EventID    *uint  `json:"event_id,omitempty" example:"7"`
+}
+
+// ToModel maps the request to a Campaign, without its organization, filter and schedule, which
+// are checked and set by the handler
+func (r CampaignRequest) ToModel() models.Campaign {
+	timezone := strings.TrimSpace(r.Timezone)
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	return models.Campaign{
+		Name:        strings.TrimSpace(r.Name),
+		Subject:     strings.TrimSpace(r.Subject),
+		SubjectB:    strings.TrimSpace(r.SubjectB),
+		TestPercent: r.TestPercent,
+		TestHours:   r.TestHours,
+		Text:        r.Text,
+		HTML:        r.HTML,
+		SegmentID:   r.SegmentID,
+		Timezone:    timezone,
+		Recurrence:  strings.TrimSpace(r.Recurrence),
+		EventID:     r.EventID,
+	}
+}
+
+// CampaignResponse describes a campaign. NextRunAt is in UTC, Timezone being what its schedule
+// is read in.
+type CampaignResponse struct {
+	ID          uint   `json:"id"`
+	Name        string `json:"name" example:"Weekly roundup"`
+	Subject     string `json:"subject" example:"This week at the market"`
+	SubjectB    string `json:"subject_b,omitempty" example:"5 things you missed this week"`
+	TestPercent int    `json:"test_percent,omitempty" example:"20"`
+	TestHours   int    `json:"test_hours,omitempty" example:"4"`
+	// TestEndsAt is when the A/B test of the current run picks its winner, the subject sent to
+	// the rest of the audience ("a" is subject, "b" subject_b)
+	TestEndsAt *time.Time    `json:"test_ends_at,omitempty"`
+	Winner     string        `json:"winner,omitempty" example:"b" enums:"a,b"`
+	Text       string        `json:"text"`
+	HTML       string        `json:"html,omitempty"`
+	SegmentID  *uint         `json:"segment_id,omitempty" example:"3"`
+	Filter     SegmentFilter `json:"filter"`
+	EventID    *uint         `json:"event_id,omitempty" example:"7"`
+	Status     string        `json:"status" example:"scheduled" enums:"draft,scheduled,sending,testing,paused,sent,cancelled"`
+	Timezone   string        `json:"timezone" example:"Europe/Paris"`
+	Recurrence string        `json:"recurrence,omitempty" example:"0 9 * * MON"`
+	NextRunAt  *time.Time    `json:"next_run_at,omitempty"`
+	Run        int           `json:"run" example:"4"`
+	LastRunAt  *time.Time    `json:"last_run_at,omitempty"`
+	SentCount  int           `json:"sent_count" example:"1250"`
+	Error      string        `json:"error,omitempty" example:"segment not found"`
+	CreatedBy  string        `json:"created_by" example:"admin@example.com"`
+	CreatedAt  time.Time     `json:"created_at"`
+	UpdatedAt  time.Time     `json:"updated_at"`
+}
+
+// NewCampaignResponse maps a Campaign to its response DTO.
+func NewCampaignResponse(c models.Campaign) CampaignResponse {
+	return CampaignResponse{
+		ID:          c.ID,
+		Name:        c.Name,
+		Subject:     c.Subject,
+		SubjectB:    c.SubjectB,
+		TestPercent: c.TestPercent,
+		TestHours:   c.TestHours,
+		TestEndsAt:  c.TestEndsAt,
+		Winner:      c.Winner,
+		Text:        c.Text,
+		HTML:        c.HTML,
+		SegmentID:   c.SegmentID,
+		Filter:      segmentFilterOf(c.Filter),
+		EventID:     c.EventID,
+		Status:      c.Status,
+		Timezone:    c.Timezone,
+		Recurrence:  c.Recurrence,
+		NextRunAt:   c.NextRunAt,
+		Run:         c.Run,
+		LastRunAt:   c.LastRunAt,
+		SentCount:   c.SentCount,
+		Error:       c.Error,
+		CreatedBy:   c.CreatedBy,
+		CreatedAt:   c.CreatedAt,
+		UpdatedAt:   c.UpdatedAt,
+	}
+}
+
+// CampaignSendResponse is the email of a run of a campaign to one subscriber. Variant is the
+// subject it got, Test whether it was part of the test group of an A/B test.
+type CampaignSendResponse struct {
+	CampaignID uint       `json:"campaign_id" example:"3"`
+	Run        int        `json:"run" example:"2"`
+	Email      string     `json:"email" example:"ada@example.com"`
+	Variant    string     `json:"variant" example:"a" enums:"a,b"`
+	Test       bool       `json:"test"`
+	SentAt     *time.Time `json:"sent_at,omitempty"`
+	OpenedAt   *time.Time `json:"opened_at,omitempty"`
+	ClickedAt  *time.Time `json:"clicked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// NewCampaignSendResponses maps campaign sends to response DTOs.
+func NewCampaignSendResponses(sends []models.CampaignSend) []CampaignSendResponse {
+	out := make([]CampaignSendResponse, len(sends))
+	for i, s := range sends {
+		out[i] = CampaignSendResponse{
+			CampaignID: s.CampaignID,
+			Run:        s.Run,
+			Email:      s.Email,
+			Variant:    s.Variant,
+			Test:       s.Test,
+			SentAt:     s.SentAt,
+			OpenedAt:   s.OpenedAt,
+			ClickedAt:  s.ClickedAt,
+			CreatedAt:  s.CreatedAt,
+		}
+	}
+	return out
+}
+
+// CampaignVariantStats counts the recipients of a subject, and those who opened and clicked
+type CampaignVariantStats struct {
+	Variant   string  `json:"variant" example:"a" enums:"a,b"`
+	Subject   string  `json:"subject" example:"This week at the market"`
+	Sent      int     `json:"sent" example:"125"`
+	Opened    int     `json:"opened" example:"40"`
+	OpenRate  float64 `json:"open_rate" example:"0.32"`
+	Clicked   int     `json:"clicked" example:"10"`
+	ClickRate float64 `json:"click_rate" example:"0.08"`
+}
+
+// CampaignShortLinkStats counts the clicks on a short link attributed to a campaign, over all its
+// runs and wherever the link was shared
+type CampaignShortLinkStats struct {
+	Code     string `json:"code" example:"spring-market"`
+	ShortURL string `json:"short_url" example:"https://go.mylocal.ing/l/spring-market"`
+	URL      string `json:"url" example:"https://market.example.org/spring"`
+	Clicks   int    `json:"clicks" example:"42"`
+}
+
+// CampaignLinkStats counts the clicks on a tracked link, and the recipients who clicked it
+type CampaignLinkStats struct {
+	URL        string `json:"url" example:"https://market.example.com/this-week"`
+	Clicks     int    `json:"clicks" example:"14"`
+	Recipients int    `json:"recipients" example:"11"`
+}
+
+// CampaignABTestStats is the result of the A/B test of a run: the opens of each subject in the
+// test group, and of the remainder once the winner was sent to it
+type CampaignABTestStats struct {
+	TestPercent int                    `json:"test_percent" example:"20"`
+	EndsAt      *time.Time             `json:"ends_at,omitempty"`
+	Winner      string                 `json:"winner,omitempty" example:"b" enums:"a,b"`
+	Variants    []CampaignVariantStats `json:"variants"`
+	Remainder   *CampaignVariantStats  `json:"remainder,omitempty"`
+}
+
+// CampaignStatsResponse counts the emails queued by the current run of a campaign and how many
+// recipients opened them (as reported by the tracking pixel, or a click) and clicked a link, bots
+// left out. TotalSent counts the emails of every run, ShortLinks the clicks on the short links
+// attributed to the campaign.
+type CampaignStatsResponse struct {
+	CampaignID uint                     `json:"campaign_id" example:"7"`
+	Status     string                   `json:"status" example:"testing"`
+	Run        int                      `json:"run" example:"4"`
+	Sent       int                      `json:"sent" example:"250"`
+	Opened     int                      `json:"opened" example:"80"`
+	OpenRate   float64                  `json:"open_rate" example:"0.32"`
+	Clicked    int                      `json:"clicked" example:"20"`
+	ClickRate  float64                  `json:"click_rate" example:"0.08"`
+	TotalSent  int                      `json:"total_sent" example:"1250"`
+	Links      []CampaignLinkStats      `json:"links"`
+	ShortLinks []CampaignShortLinkStats `json:"short_links"`
+	ABTest     *CampaignABTestStats     `json:"ab_test,omitempty"`
+}
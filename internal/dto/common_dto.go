@@ -0,0 +1,38 @@
+package dto
+
+import "encoding/json"
+
+// ErrorResponse is the body returned with every 4xx/5xx response.
+type ErrorResponse struct {
+	Error string `json:"error" example:"Invalid request body"`
+	// Code is a stable machine-readable identifier, only set for errors clients are expected to branch on
+	Code string `json:"code,omitempty" example:"too_many_attempts"`
+}
+
+// Envelope wraps JSON responses for clients that negotiate it (see middleware.Envelope). Data
+// is the usual body, null for errors, which are listed in Errors instead.
+type Envelope struct {
+	Data   json.RawMessage `json:"data" swaggertype:"object"`
+	Meta   EnvelopeMeta    `json:"meta"`
+	Errors []EnvelopeError `json:"errors,omitempty"`
+}
+
+// EnvelopeMeta describes the response an Envelope wraps.
+type EnvelopeMeta struct {
+	RequestID string `json:"request_id" example:"3f2c1b9e-8d4a-4f6e-9a51-0c7d2e8b4a10"`
+	Status    int    `json:"status" example:"200"`
+	// NextCursor is the cursor of the following page of a list, also sent as X-Next-Cursor
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// EnvelopeError is an error of an Envelope: the ErrorResponse fields, the others in Details.
+type EnvelopeError struct {
+	Message string                 `json:"message" example:"Invalid request body"`
+	Code    string                 `json:"code,omitempty" example:"too_many_attempts"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// MessageResponse is a plain informational response.
+type MessageResponse struct {
+	Message string `json:"message"`
+}
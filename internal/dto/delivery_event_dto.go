@@ -0,0 +1,43 @@
+package dto
+
+import (
+	"time"
+
+	"fiber-gorm-api/internal/models"
+)
+
+// SendGridEvent is one entry of the JSON array POSTed by the SendGrid Event Webhook.
+// Only the fields we store are decoded.
+type SendGridEvent struct {
+	Email       string `json:"email"`
+	Event       string `json:"event" example:"bounce"`
+	Type        string `json:"type,omitempty" example:"bounce"` // bounce events: "bounce" (hard) or "blocked" (soft)
+	Reason      string `json:"reason,omitempty"`
+	Timestamp   int64  `json:"timestamp" example:"1700000000"`
+	SGEventID   string `json:"sg_event_id"`
+	SGMessageID string `json:"sg_message_id,omitempty"`
+}
+
+// DeliveryEventResponse is the public representation of a delivery event.
+type DeliveryEventResponse struct {
+	ID          uint      `json:"id"`
+	Event       string    `json:"event" example:"bounce"`
+	Reason      string    `json:"reason,omitempty" example:"550 5.1.1 The email account does not exist"`
+	SGMessageID string    `json:"sg_message_id,omitempty"`
+	OccurredAt  time.Time `json:"occurred_at"`
+}
+
+// NewDeliveryEventResponses maps delivery events to response DTOs.
+func NewDeliveryEventResponses(events []models.DeliveryEvent) []DeliveryEventResponse {
+	out := make([]DeliveryEventResponse, len(events))
+	for i, e := range events {
+		out[i] = DeliveryEventResponse{
+			ID:          e.ID,
+			Event:       e.Event,
+			Reason:      e.Reason,
+			SGMessageID: e.SGMessageID,
+			OccurredAt:  e.OccurredAt,
+		}
+	}
+	return out
+}
@@ -0,0 +1,31 @@
+package dto
+
+import (
+	"time"
+
+	"fiber-gorm-api/internal/models"
+)
+
+// EmailDeadLetterResponse describes a queued email given up on.
+type EmailDeadLetterResponse struct {
+	ID            uint      `json:"id"`
+	OutboxEventID uint      `json:"outbox_event_id"`
+	Kind          string    `json:"kind" example:"signin_code" enums:"signin_code,magic_link"`
+	ToEmail       string    `json:"to_email" example:"ada@example.com"`
+	Attempts      int       `json:"attempts" example:"10"`
+	LastError     string    `json:"last_error" example:"sendgrid temporarily unavailable: sendgrid returned server error (503): "`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// NewEmailDeadLetterResponse maps an EmailDeadLetter to its response DTO.
+func NewEmailDeadLetterResponse(l models.EmailDeadLetter) EmailDeadLetterResponse {
+	return EmailDeadLetterResponse{
+		ID:            l.ID,
+		OutboxEventID: l.OutboxEventID,
+		Kind:          l.Kind,
+		ToEmail:       l.ToEmail,
+		Attempts:      l.Attempts,
+		LastError:     l.LastError,
+		CreatedAt:     l.CreatedAt,
+	}
+}
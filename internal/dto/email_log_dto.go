@@ -0,0 +1,53 @@
+package dto
+
+import (
+	"time"
+
+	"fiber-gorm-api/internal/models"
+)
+
+// EmailLogResponse describes an email the API sent, or tried to.
+type EmailLogResponse struct {
+	ID   uint   `json:"id"`
+	Type string `json:"type" example:"confirmation" enums:"signin_code,magic_link,invitation,confirmation,preferences,admin_notification,campaign,template_test"`
+	// ToEmail is masked for callers without the pii scope
+	ToEmail string `json:"to_email" example:"ada@example.com"`
+	// MessageID is SendGrid's X-Message-Id, empty when the send failed
+	MessageID string `json:"message_id,omitempty" example:"W86EgYT6SQKk0lRflfLRsA"`
+	Status    string `json:"status" example:"delivered" enums:"sent,failed,deferred,delivered,bounce,dropped"`
+	// Error is why the send failed, left out for callers without the pii scope
+	Error       string                    `json:"error,omitempty"`
+	Attachments []EmailAttachmentResponse `json:"attachments"`
+	CreatedAt   time.Time                 `json:"created_at"`
+	UpdatedAt   time.Time                 `json:"updated_at"`
+}
+
+// EmailAttachmentResponse describes a file sent with an email; its content isn't kept.
+type EmailAttachmentResponse struct {
+	Filename    string `json:"filename" example:"market-day.ics"`
+	ContentType string `json:"content_type" example:"text/calendar; method=REQUEST"`
+	Size        int    `json:"size" example:"912"`
+	SHA256      string `json:"sha256" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+}
+
+// NewEmailLogResponses maps logged emails to response DTOs.
+func NewEmailLogResponses(logs []models.EmailLog) []EmailLogResponse {
+	out := make([]EmailLogResponse, len(logs))
+	for i, l := range logs {
+		out[i] = EmailLogResponse{
+			ID:          l.ID,
+			Type:        l.Type,
+			ToEmail:     l.ToEmail,
+			MessageID:   l.MessageID,
+			Status:      l.Status,
+			Error:       l.Error,
+			Attachments: make([]EmailAttachmentResponse, len(l.Attachments)),
+			CreatedAt:   l.CreatedAt,
+			UpdatedAt:   l.UpdatedAt,
+		}
+		for j, a := range l.Attachments {
+			out[i].Attachments[j] = EmailAttachmentResponse(a)
+		}
+	}
+	return out
+}
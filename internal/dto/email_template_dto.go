@@ -0,0 +1,92 @@
+package dto
+
+import (
+	"strings"
+	"time"
+
+	"fiber-gorm-api/internal/models"
+)
+
+// EmailTemplateRequest is the body accepted by POST /admin/email-templates and
+// PUT /admin/email-templates/{id}. Subject, text and HTML are Go templates, e.g.
+// "Hello {{.FirstName}}", the HTML escaping the variables it shows. With format markdown or
+// mjml, the HTML is compiled from source instead, and the text of Markdown defaults to it.
+type EmailTemplateRequest struct {
+	Name    string `json:"name" example:"Market day"`
+	Subject string `json:"subject" example:"See you Saturday, {{.FirstName}}"`
+	Text    string `json:"text" example:"Hi {{.Name}}, the market opens at 9."`
+	HTML    string `json:"html,omitempty" example:"<p>Hi {{.Name}}, the market opens at 9.</p>"`
+	Format  string `json:"format,omitempty" example:"markdown" enums:"html,markdown,mjml"`
+	Source  string `json:"source,omitempty" example:"Hi **{{.Name}}**, the market opens at 9."`
+}
+
+// ToModel maps the request to an EmailTemplate, without its organization
+func (r EmailTemplateRequest) ToModel() models.EmailTemplate {
+	return models.EmailTemplate{
+		Name:    strings.TrimSpace(r.Name),
+		Subject: strings.TrimSpace(r.Subject),
+		Text:    r.Text,
+		HTML:    r.HTML,
+		Format:  strings.ToLower(strings.TrimSpace(r.Format)),
+		Source:  r.Source,
+	}
+}
+
+// EmailTemplateResponse describes an email template.
+type EmailTemplateResponse struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name" example:"Market day"`
+	Subject   string    `json:"subject" example:"See you Saturday, {{.FirstName}}"`
+	Text      string    `json:"text" example:"Hi {{.Name}}, the market opens at 9."`
+	HTML      string    `json:"html,omitempty" example:"<p>Hi {{.Name}}, the market opens at 9.</p>"`
+	Format    string    `json:"format" example:"markdown" enums:"html,markdown,mjml"`
+	Source    string    `json:"source,omitempty" example:"Hi **{{.Name}}**, the market opens at 9."`
+	CreatedBy string    `json:"created_by" example:"admin@example.com"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewEmailTemplateResponse maps an EmailTemplate to its response DTO.
+func NewEmailTemplateResponse(t models.EmailTemplate) EmailTemplateResponse {
+	return EmailTemplateResponse{
+		ID:        t.ID,
+		Name:      t.Name,
+		Subject:   t.Subject,
+		Text:      t.Text,
+		HTML:      t.HTML,
+		Format:    t.Format,
+		Source:    t.Source,
+		CreatedBy: t.CreatedBy,
+		CreatedAt: t.CreatedAt,
+		UpdatedAt: t.UpdatedAt,
+	}
+}
+
+// EmailTemplateRenderRequest is the optional body of POST /admin/email-templates/{id}/preview and
+// /test-send. Variables override the sample ones (Name, FirstName, Email, PreferencesURL).
+type EmailTemplateRenderRequest struct {
+	Variables map[string]interface{} `json:"variables,omitempty"`
+}
+
+// EmailTemplatePreviewResponse is a template rendered with variables.
+type EmailTemplatePreviewResponse struct {
+	Subject string `json:"subject" example:"See you Saturday, Jane"`
+	Text    string `json:"text" example:"Hi Jane Doe, the market opens at 9."`
+	HTML    string `json:"html" example:"<p>Hi Jane Doe, the market opens at 9.</p>"`
+}
+
+// EmailTemplateTestSendResponse tells where the test email of a template was sent.
+type EmailTemplateTestSendResponse struct {
+	To      string `json:"to" example:"admin@example.com"`
+	Subject string `json:"subject" example:"[Test] See you Saturday, Jane"`
+}
+
+// TemplateErrorResponse is returned for a template that doesn't parse or render, with where the
+// error is. Line and column are 1-based, omitted when unknown.
+type TemplateErrorResponse struct {
+	Error  string `json:"error" example:"map has no entry for key \"Nme\""`
+	Code   string `json:"code" example:"invalid_template"`
+	Part   string `json:"part" example:"html" enums:"subject,text,html,source"`
+	Line   int    `json:"line,omitempty" example:"3"`
+	Column int    `json:"column,omitempty" example:"14"`
+}
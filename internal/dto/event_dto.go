@@ -0,0 +1,76 @@
+package dto
+
+import (
+	"strings"
+	"time"
+
+	"fiber-gorm-api/internal/calendar"
+	"fiber-gorm-api/internal/models"
+)
+
+// EventRequest is the body accepted by POST /admin/local-events and PUT /admin/local-events/{id}.
+// StartsAt and EndsAt are RFC 3339 times, or local dates and times read in Timezone.
+type EventRequest struct {
+	Title       string `json:"title" example:"Summer market day"`
+	Description string `json:"description,omitempty" example:"Fresh produce, crafts and music on the square."`
+	Location    string `json:"location,omitempty" example:"Place du Marché, Lyon"`
+	URL         string `json:"url,omitempty" example:"https://market.example.org/summer"`
+	StartsAt    string `json:"starts_at" example:"2025-07-05T09:00"`
+	EndsAt      string `json:"ends_at" example:"2025-07-05T14:00"`
+	Timezone    string `json:"timezone,omitempty" example:"Europe/Paris"`
+}
+
+// ToModel maps the request to an Event, without its organization and times, which are checked
+// and set by the handler
+func (r EventRequest) ToModel() models.Event {
+	timezone := strings.TrimSpace(r.Timezone)
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	return models.Event{
+		Title:       strings.TrimSpace(r.Title),
+		Description: strings.TrimSpace(r.Description),
+		Location:    strings.TrimSpace(r.Location),
+		URL:         strings.TrimSpace(r.URL),
+		Timezone:    timezone,
+	}
+}
+
+// EventResponse describes a local event. StartsAt and EndsAt are in UTC, Timezone being where
+// the event takes place.
+type EventResponse struct {
+	ID          uint      `json:"id"`
+	Title       string    `json:"title" example:"Summer market day"`
+	Description string    `json:"description,omitempty" example:"Fresh produce, crafts and music on the square."`
+	Location    string    `json:"location,omitempty" example:"Place du Marché, Lyon"`
+	URL         string    `json:"url,omitempty" example:"https://market.example.org/summer"`
+	StartsAt    time.Time `json:"starts_at"`
+	EndsAt      time.Time `json:"ends_at"`
+	Timezone    string    `json:"timezone" example:"Europe/Paris"`
+	// Sequence counts the updates of the event, for calendars to replace its invite
+	Sequence int `json:"sequence" example:"1"`
+	// CalendarURL is the public link to its invite, under TRACKING_BASE_URL
+	CalendarURL string    `json:"calendar_url" example:"https://api.mylocal.ing/events/7/calendar.ics"`
+	CreatedBy   string    `json:"created_by" example:"admin@example.com"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// NewEventResponse maps an Event to its response DTO.
+func NewEventResponse(e models.Event) EventResponse {
+	return EventResponse{
+		ID:          e.ID,
+		Title:       e.Title,
+		Description: e.Description,
+		Location:    e.Location,
+		URL:         e.URL,
+		StartsAt:    e.StartsAt,
+		EndsAt:      e.EndsAt,
+		Timezone:    e.Timezone,
+		Sequence:    e.Sequence,
+		CalendarURL: calendar.URL(e.ID),
+		CreatedBy:   e.CreatedBy,
+		CreatedAt:   e.CreatedAt,
+		UpdatedAt:   e.UpdatedAt,
+	}
+}
@@ -0,0 +1,71 @@
+package dto
+
+import (
+	"time"
+
+	"fiber-gorm-api/internal/models"
+)
+
+// ExportFilters picks the subscribers of an export, like the query parameters of
+// GET /admin/subscribers. Empty fields don't filter.
+type ExportFilters struct {
+	Status         []string `json:"status,omitempty" example:"active,pending"`
+	Verified       string   `json:"verified,omitempty" example:"true" enums:"true,false"`
+	SubscriberType string   `json:"subscriber_type,omitempty" example:"donor"`
+	Frequency      string   `json:"frequency,omitempty" example:"monthly" enums:"daily,weekly,monthly"`
+	Channel        string   `json:"channel,omitempty" example:"email" enums:"email,sms"`
+	// Query is a filter expression, like the filter parameter of GET /admin/subscribers
+	Query string `json:"query,omitempty" example:"type:shopper AND NOT tag:vip"`
+}
+
+// CreateExportRequest is the body accepted by POST /admin/exports. SegmentID exports the
+// members of a segment instead of those of Filters, and can't be combined with them.
+type CreateExportRequest struct {
+	Format    string        `json:"format" example:"csv" enums:"csv,json"`
+	Filters   ExportFilters `json:"filters"`
+	SegmentID *uint         `json:"segment_id,omitempty" example:"3"`
+}
+
+// ExportJobResponse describes an export job. DownloadURL is only set once the file is ready.
+type ExportJobResponse struct {
+	ID          uint                   `json:"id"`
+	OrgID       uint                   `json:"org_id"`
+	Format      string                 `json:"format" example:"csv" enums:"csv,json"`
+	Filters     map[string]interface{} `json:"filters"`
+	SegmentID   *uint                  `json:"segment_id,omitempty" example:"3"`
+	Status      string                 `json:"status" example:"done" enums:"pending,running,done,failed"`
+	RequestedBy string                 `json:"requested_by" example:"admin@example.com"`
+	RowCount    int                    `json:"row_count" example:"1250"`
+	Error       string                 `json:"error,omitempty"`
+	CreatedAt   time.Time              `json:"created_at"`
+	StartedAt   *time.Time             `json:"started_at,omitempty"`
+	FinishedAt  *time.Time             `json:"finished_at,omitempty"`
+	// ExpiresAt is when the file is deleted
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// DownloadURL is a signed link to the file, valid until DownloadExpiresAt
+	DownloadURL       string     `json:"download_url,omitempty"`
+	DownloadExpiresAt *time.Time `json:"download_expires_at,omitempty"`
+}
+
+// NewExportJobResponse maps an ExportJob to its response DTO, without a download link.
+func NewExportJobResponse(j models.ExportJob) ExportJobResponse {
+	filters := map[string]interface{}(j.Filters)
+	if filters == nil {
+		filters = map[string]interface{}{}
+	}
+	return ExportJobResponse{
+		ID:          j.ID,
+		OrgID:       j.OrgID,
+		Format:      j.Format,
+		Filters:     filters,
+		SegmentID:   j.SegmentID,
+		Status:      j.Status,
+		RequestedBy: j.RequestedBy,
+		RowCount:    j.RowCount,
+		Error:       j.Error,
+		CreatedAt:   j.CreatedAt,
+		StartedAt:   j.StartedAt,
+		FinishedAt:  j.FinishedAt,
+		ExpiresAt:   j.ExpiresAt,
+	}
+}
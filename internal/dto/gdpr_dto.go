@@ -0,0 +1,21 @@
+package dto
+
+import "time"
+
+// PasskeySummary describes a registered passkey without its key material.
+type PasskeySummary struct {
+	ID        uint      `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// GDPRExport is the complete archive of personal data held about a subscriber.
+type GDPRExport struct {
+	GeneratedAt   time.Time                `json:"generated_at"`
+	Subscriber    SubscriberResponse       `json:"subscriber"`
+	Passkeys      []PasskeySummary         `json:"passkeys"`
+	Sessions      []SessionResponse        `json:"sessions"`
+	Deliveries    []DeliveryEventResponse  `json:"delivery_events"`
+	CampaignSends []CampaignSendResponse   `json:"campaign_sends"`
+	Emails        []EmailLogResponse       `json:"emails"`
+	Notes         []SubscriberNoteResponse `json:"notes"`
+}
@@ -0,0 +1,20 @@
+package dto
+
+// GraphQLRequest is the body accepted by POST /admin/graphql.
+type GraphQLRequest struct {
+	Query         string                 `json:"query" example:"{ stats { totalSubscribers } }"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// GraphQLError is one entry of the errors of a GraphQL response.
+type GraphQLError struct {
+	Message string        `json:"message" example:"Invalid status: gone"`
+	Path    []interface{} `json:"path,omitempty"`
+}
+
+// GraphQLResponse is returned by POST /admin/graphql. Data holds exactly the selected fields.
+type GraphQLResponse struct {
+	Data   interface{}    `json:"data"`
+	Errors []GraphQLError `json:"errors,omitempty"`
+}
@@ -0,0 +1,57 @@
+package dto
+
+import (
+	"time"
+
+	"fiber-gorm-api/internal/models"
+)
+
+// ImportJobResponse describes an import job and its progress. ErrorReportURL is only set once
+// the job is finished and some rows failed.
+type ImportJobResponse struct {
+	ID          uint   `json:"id"`
+	OrgID       uint   `json:"org_id"`
+	Format      string `json:"format" example:"csv" enums:"csv,json"`
+	FileName    string `json:"file_name" example:"subscribers.csv"`
+	Status      string `json:"status" example:"running" enums:"pending,running,done,failed"`
+	RequestedBy string `json:"requested_by" example:"admin@example.com"`
+	// TotalRows is known once the worker has read the file through
+	TotalRows     int `json:"total_rows" example:"1250"`
+	ProcessedRows int `json:"processed_rows" example:"500"`
+	CreatedRows   int `json:"created_rows" example:"480"`
+	UpdatedRows   int `json:"updated_rows" example:"12"`
+	// FailedRows are the errors so far, listed in the error report
+	FailedRows int        `json:"failed_rows" example:"8"`
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	// ExpiresAt is when the job and its error report are deleted
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// ErrorReportURL is a signed link to a CSV of the failed rows (row, email, error), valid
+	// until ErrorReportExpiresAt
+	ErrorReportURL       string     `json:"error_report_url,omitempty"`
+	ErrorReportExpiresAt *time.Time `json:"error_report_expires_at,omitempty"`
+}
+
+// NewImportJobResponse maps an ImportJob to its response DTO, without an error report link.
+func NewImportJobResponse(j models.ImportJob) ImportJobResponse {
+	return ImportJobResponse{
+		ID:            j.ID,
+		OrgID:         j.OrgID,
+		Format:        j.Format,
+		FileName:      j.FileName,
+		Status:        j.Status,
+		RequestedBy:   j.RequestedBy,
+		TotalRows:     j.TotalRows,
+		ProcessedRows: j.ProcessedRows,
+		CreatedRows:   j.CreatedRows,
+		UpdatedRows:   j.UpdatedRows,
+		FailedRows:    j.FailedRows,
+		Error:         j.Error,
+		CreatedAt:     j.CreatedAt,
+		StartedAt:     j.StartedAt,
+		FinishedAt:    j.FinishedAt,
+		ExpiresAt:     j.ExpiresAt,
+	}
+}
@@ -0,0 +1,82 @@
+package dto
+
+import (
+	"time"
+
+	"fiber-gorm-api/internal/models"
+)
+
+// UpdateIntegrationRequest is the body accepted by PUT /admin/integrations/{provider}. APIKey
+// is required to connect a provider and optional afterwards, keeping the current one.
+type UpdateIntegrationRequest struct {
+	APIKey  string `json:"api_key,omitempty" example:"0123456789abcdef0123456789abcdef-us21"`
+	Enabled *bool  `json:"enabled,omitempty" example:"true"`
+}
+
+// IntegrationListRequest maps a list at the provider to the subscribers synced with it.
+type IntegrationListRequest struct {
+	// RemoteID is the id of the list at the provider (the audience id of Mailchimp)
+	RemoteID string `json:"remote_id" example:"a1b2c3d4e5"`
+	// SubscriberType limits the list to the subscribers with that subscriber_type
+	SubscriberType string `json:"subscriber_type,omitempty" example:"donor"`
+}
+
+// UpdateIntegrationListsRequest is the body accepted by PUT /admin/integrations/{provider}/lists.
+type UpdateIntegrationListsRequest struct {
+	Lists []IntegrationListRequest `json:"lists"`
+}
+
+// IntegrationListResponse describes a list kept in sync, and how far the sync got.
+type IntegrationListResponse struct {
+	ID             uint       `json:"id"`
+	RemoteID       string     `json:"remote_id" example:"a1b2c3d4e5"`
+	SubscriberType string     `json:"subscriber_type,omitempty" example:"donor"`
+	PushedAt       *time.Time `json:"pushed_at,omitempty"`
+	PulledAt       *time.Time `json:"pulled_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// IntegrationResponse describes an integration without its API key.
+type IntegrationResponse struct {
+	ID       uint   `json:"id"`
+	OrgID    uint   `json:"org_id"`
+	Provider string `json:"provider" example:"mailchimp" enums:"mailchimp"`
+	// APIKeyHint is the end of the API key
+	APIKeyHint string                    `json:"api_key_hint" example:"…us21"`
+	Enabled    bool                      `json:"enabled"`
+	CreatedBy  string                    `json:"created_by" example:"admin@example.com"`
+	LastSyncAt *time.Time                `json:"last_sync_at,omitempty"`
+	LastError  string                    `json:"last_error,omitempty"`
+	Lists      []IntegrationListResponse `json:"lists"`
+	CreatedAt  time.Time                 `json:"created_at"`
+	UpdatedAt  time.Time                 `json:"updated_at"`
+}
+
+// NewIntegrationResponse maps an Integration (with preloaded Lists) to its response DTO, given
+// the hint of its API key.
+func NewIntegrationResponse(i models.Integration, keyHint string) IntegrationResponse {
+	lists := make([]IntegrationListResponse, len(i.Lists))
+	for n, l := range i.Lists {
+		lists[n] = IntegrationListResponse{
+			ID:             l.ID,
+			RemoteID:       l.RemoteID,
+			SubscriberType: l.SubscriberType,
+			PushedAt:       l.PushedAt,
+			PulledAt:       l.PulledAt,
+			CreatedAt:      l.CreatedAt,
+		}
+	}
+	return IntegrationResponse{
+		ID:         i.ID,
+		OrgID:      i.OrgID,
+		Provider:   i.Provider,
+		APIKeyHint: keyHint,
+		Enabled:    i.Enabled,
+		CreatedBy:  i.CreatedBy,
+		LastSyncAt: i.LastSyncAt,
+		LastError:  i.LastError,
+		Lists:      lists,
+		CreatedAt:  i.CreatedAt,
+		UpdatedAt:  i.UpdatedAt,
+	}
+}
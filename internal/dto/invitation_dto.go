@@ -0,0 +1,60 @@
+package dto
+
+import (
+	"time"
+
+	"fiber-gorm-api/internal/models"
+)
+
+// InviteAdminRequest is the body accepted by POST /admin/users/invite.
+type InviteAdminRequest struct {
+	Email string `json:"email" example:"staff@example.com"`
+	Role  string `json:"role" example:"editor" enums:"admin,editor,viewer"`
+}
+
+// InvitationResponse describes an invitation without its token.
+type InvitationResponse struct {
+	ID         uint       `json:"id"`
+	OrgID      uint       `json:"org_id"`
+	Email      string     `json:"email" example:"staff@example.com"`
+	Role       string     `json:"role" example:"editor"`
+	Status     string     `json:"status" example:"pending" enums:"pending,accepted,revoked,expired"`
+	InvitedBy  string     `json:"invited_by"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	AcceptedAt *time.Time `json:"accepted_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// InvitationPreviewResponse is what the invitee sees before accepting.
+type InvitationPreviewResponse struct {
+	Email            string    `json:"email" example:"staff@example.com"`
+	Role             string    `json:"role" example:"editor"`
+	OrganizationName string    `json:"organization_name" example:"myLocal Springfield"`
+	ExpiresAt        time.Time `json:"expires_at"`
+}
+
+// NewInvitationResponse maps an AdminInvitation to its response DTO.
+func NewInvitationResponse(i models.AdminInvitation) InvitationResponse {
+	status := "pending"
+	switch {
+	case i.AcceptedAt != nil:
+		status = "accepted"
+	case i.RevokedAt != nil:
+		status = "revoked"
+	case !time.Now().Before(i.ExpiresAt):
+		status = "expired"
+	}
+	return InvitationResponse{
+		ID:         i.ID,
+		OrgID:      i.OrgID,
+		Email:      i.Email,
+		Role:       i.Role,
+		Status:     status,
+		InvitedBy:  i.InvitedBy,
+		ExpiresAt:  i.ExpiresAt,
+		AcceptedAt: i.AcceptedAt,
+		RevokedAt:  i.RevokedAt,
+		CreatedAt:  i.CreatedAt,
+	}
+}
@@ -0,0 +1,18 @@
+package dto
+
+// JWK is a public JSON Web Key (RFC 7517), RSA or Ed25519.
+type JWK struct {
+	Kty string `json:"kty" example:"OKP"`
+	Kid string `json:"kid" example:"3kZ0n1JZ7qB5v2Y0a6sXh8W4cD9eF1gH2iJ3kL4mN5o"`
+	Use string `json:"use,omitempty" example:"sig"`
+	Alg string `json:"alg,omitempty" example:"EdDSA"`
+	Crv string `json:"crv,omitempty" example:"Ed25519"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKSResponse is the key set returned by GET /.well-known/jwks.json.
+type JWKSResponse struct {
+	Keys []JWK `json:"keys"`
+}
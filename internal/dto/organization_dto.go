@@ -0,0 +1,60 @@
+package dto
+
+import (
+	"time"
+
+	"fiber-gorm-api/internal/models"
+)
+
+// OrganizationRequest is the body accepted by POST and PUT /admin/organizations.
+type OrganizationRequest struct {
+	Name string `json:"name" example:"myLocal Springfield"`
+	Slug string `json:"slug" example:"springfield"`
+}
+
+// OrganizationResponse is the public representation of an organization.
+type OrganizationResponse struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name" example:"myLocal Springfield"`
+	Slug      string    `json:"slug" example:"springfield"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// AddAdminUserRequest is the body accepted by POST /admin/organizations/{id}/members.
+// Role defaults to admin.
+type AddAdminUserRequest struct {
+	Email string `json:"email" example:"staff@example.com"`
+	Role  string `json:"role,omitempty" example:"editor" enums:"admin,editor,viewer"`
+}
+
+// AdminUserResponse is an admin assigned to an organization.
+type AdminUserResponse struct {
+	ID        uint      `json:"id"`
+	OrgID     uint      `json:"org_id"`
+	Email     string    `json:"email" example:"staff@example.com"`
+	Role      string    `json:"role" example:"editor"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewOrganizationResponse maps an Organization to its response DTO.
+func NewOrganizationResponse(o models.Organization) OrganizationResponse {
+	return OrganizationResponse{
+		ID:        o.ID,
+		Name:      o.Name,
+		Slug:      o.Slug,
+		CreatedAt: o.CreatedAt,
+		UpdatedAt: o.UpdatedAt,
+	}
+}
+
+// NewAdminUserResponse maps an AdminUser to its response DTO.
+func NewAdminUserResponse(a models.AdminUser) AdminUserResponse {
+	return AdminUserResponse{
+		ID:        a.ID,
+		OrgID:     a.OrgID,
+		Email:     a.Email,
+		Role:      a.Role,
+		CreatedAt: a.CreatedAt,
+	}
+}
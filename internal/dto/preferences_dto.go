@@ -0,0 +1,73 @@
+package dto
+
+import (
+	"time"
+
+	"fiber-gorm-api/internal/models"
+)
+
+// SubscriberTypePreferences is how often, and on which channel, a subscriber hears about one
+// of their subscriber_types.
+type SubscriberTypePreferences struct {
+	Name      string `json:"name" example:"shopper"`
+	Frequency string `json:"frequency" example:"weekly" enums:"daily,weekly,monthly"`
+	Channel   string `json:"channel" example:"email" enums:"email,sms"`
+}
+
+// PreferencesResponse is what a subscriber sees on their preferences page. The address is
+// masked, as the link may be forwarded.
+type PreferencesResponse struct {
+	Email           string                      `json:"email" example:"j***@example.com"`
+	Name            string                      `json:"name" example:"Jane Doe"`
+	Status          string                      `json:"status" example:"active" enums:"pending,active,bounced,unsubscribed,quarantined"`
+	Subscribed      bool                        `json:"subscribed" example:"true"`
+	SubscriberTypes []SubscriberTypePreferences `json:"subscriber_types"`
+	// ReferralCode is passed as ?ref= on the signup links the subscriber shares
+	ReferralCode string `json:"referral_code,omitempty" example:"k7m2q9xw4d"`
+}
+
+// UpdatePreferencesRequest is the body accepted by PUT /preferences/{token}. Omitted fields are
+// left untouched. subscriber_types replaces the subscriber's types (an empty array removes them
+// all); types kept keep the frequency and channel they're sent without. subscribed false
+// unsubscribes, true subscribes again after an unsubscribe.
+type UpdatePreferencesRequest struct {
+	Name            *string                 `json:"name,omitempty" example:"Jane Doe"`
+	SubscriberTypes []SubscriberTypeRequest `json:"subscriber_types,omitempty"`
+	Subscribed      *bool                   `json:"subscribed,omitempty" example:"false"`
+}
+
+// RequestPreferencesLinkRequest is the body accepted by POST /preferences.
+type RequestPreferencesLinkRequest struct {
+	Email string `json:"email" example:"user@example.com"`
+}
+
+// PreferencesLinkResponse is a link to a subscriber's preferences page.
+type PreferencesLinkResponse struct {
+	URL       string    `json:"url" example:"https://signup.mylocal.ing/preferences/kq3X...token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// TypeModels maps the requested subscriber_types onto models; nil when omitted so they're left
+// untouched
+func (r UpdatePreferencesRequest) TypeModels() []models.SubscriberType {
+	return toSubscriberTypeModels(r.SubscriberTypes)
+}
+
+// NewPreferencesResponse converts a subscriber into its preferences page
+func NewPreferencesResponse(s models.Subscriber) PreferencesResponse {
+	types := make([]SubscriberTypePreferences, len(s.SubscriberTypes))
+	for i, t := range s.SubscriberTypes {
+		types[i] = SubscriberTypePreferences{Name: t.Name, Frequency: t.Frequency, Channel: t.Channel}
+	}
+	resp := PreferencesResponse{
+		Email:           MaskEmail(s.Email),
+		Name:            s.Name,
+		Status:          s.Status,
+		Subscribed:      s.Status != models.SubscriberStatusUnsubscribed,
+		SubscriberTypes: types,
+	}
+	if s.ReferralCode != nil {
+		resp.ReferralCode = *s.ReferralCode
+	}
+	return resp
+}
@@ -0,0 +1,95 @@
+package dto
+
+import "strings"
+
+// MaskEmail keeps the first character of the local part and the domain, e.g. j***@example.com
+func MaskEmail(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 1 {
+		return "***"
+	}
+	return email[:1] + "***" + email[at:]
+}
+
+// Redacted returns a copy with personal data masked, for callers without the pii scope.
+func (r SubscriberResponse) Redacted() SubscriberResponse {
+	r.Email = MaskEmail(r.Email)
+	return r
+}
+
+// RedactSubscribers masks personal data in a list of subscribers.
+func RedactSubscribers(in []SubscriberResponse) []SubscriberResponse {
+	out := make([]SubscriberResponse, len(in))
+	for i, r := range in {
+		out[i] = r.Redacted()
+	}
+	return out
+}
+
+// Redacted drops the provider's free-text reason, which often quotes the recipient address.
+func (r DeliveryEventResponse) Redacted() DeliveryEventResponse {
+	r.Reason = ""
+	return r
+}
+
+// Redacted masks the recipient and drops the send error, which often quotes it.
+func (r EmailLogResponse) Redacted() EmailLogResponse {
+	r.ToEmail = MaskEmail(r.ToEmail)
+	r.Error = ""
+	return r
+}
+
+// Redacted masks the addresses in both snapshots and in the email change.
+func (r SubscriberRevisionResponse) Redacted() SubscriberRevisionResponse {
+	if r.Before != nil {
+		before := *r.Before
+		before.Email = MaskEmail(before.Email)
+		r.Before = &before
+	}
+	r.After.Email = MaskEmail(r.After.Email)
+	changes := make([]SubscriberChange, len(r.Changes))
+	for i, change := range r.Changes {
+		if change.Field == "email" {
+			change.Before = maskIfSet(change.Before.(string))
+			change.After = maskIfSet(change.After.(string))
+		}
+		changes[i] = change
+	}
+	r.Changes = changes
+	return r
+}
+
+// maskIfSet masks email, leaving a missing address empty
+func maskIfSet(email string) string {
+	if email == "" {
+		return ""
+	}
+	return MaskEmail(email)
+}
+
+// Redacted masks the addresses of the referrers.
+func (r ReferralStatsResponse) Redacted() ReferralStatsResponse {
+	leaderboard := make([]ReferrerCount, len(r.Leaderboard))
+	for i, entry := range r.Leaderboard {
+		entry.Email = maskIfSet(entry.Email)
+		leaderboard[i] = entry
+	}
+	r.Leaderboard = leaderboard
+	return r
+}
+
+// Redacted masks the addresses of the referrer and the referees.
+func (r SubscriberReferralsResponse) Redacted() SubscriberReferralsResponse {
+	if r.ReferredBy != nil {
+		referredBy := *r.ReferredBy
+		referredBy.Email = MaskEmail(referredBy.Email)
+		r.ReferredBy = &referredBy
+	}
+	referees := make([]ReferralSubscriber, len(r.Referees))
+	for i, referee := range r.Referees {
+		referee.Email = MaskEmail(referee.Email)
+		referees[i] = referee
+	}
+	r.Referees = referees
+	return r
+}
@@ -0,0 +1,58 @@
+package dto
+
+import (
+	"time"
+
+	"fiber-gorm-api/internal/models"
+)
+
+// ReferralStatsResponse is returned by GET /admin/stats/referrals: the subscribers of the
+// organization created in the period with another's referral code, and the subscribers who
+// referred the most of them. Active counts the referees confirmed and still subscribed.
+type ReferralStatsResponse struct {
+	TotalReferred  int64           `json:"total_referred" example:"42"`
+	ActiveReferred int64           `json:"active_referred" example:"35"`
+	Referrers      int64           `json:"referrers" example:"9"`
+	Leaderboard    []ReferrerCount `json:"leaderboard"`
+	GeneratedAt    time.Time       `json:"generated_at"`
+}
+
+// ReferrerCount is a subscriber of the referral leaderboard and the signups they referred.
+type ReferrerCount struct {
+	SubscriberID uint   `json:"subscriber_id" example:"12"`
+	Name         string `json:"name" example:"Jane Doe"`
+	Email        string `json:"email" example:"user@example.com"`
+	ReferralCode string `json:"referral_code" example:"k7m2q9xw4d"`
+	Referrals    int64  `json:"referrals" example:"7"`
+	Active       int64  `json:"active" example:"6"`
+}
+
+// SubscriberReferralsResponse is returned by GET /admin/subscribers/{id}/referrals: the code the
+// subscriber shares, who referred them, and the signups they referred, newest first.
+type SubscriberReferralsResponse struct {
+	ReferralCode string               `json:"referral_code" example:"k7m2q9xw4d"`
+	ReferredBy   *ReferralSubscriber  `json:"referred_by,omitempty"`
+	Referrals    int64                `json:"referrals" example:"7"`
+	Active       int64                `json:"active" example:"6"`
+	Referees     []ReferralSubscriber `json:"referees"`
+}
+
+// ReferralSubscriber is the referrer or a referee of a subscriber.
+type ReferralSubscriber struct {
+	ID        uint      `json:"id" example:"12"`
+	Name      string    `json:"name" example:"Jane Doe"`
+	Email     string    `json:"email" example:"user@example.com"`
+	Status    string    `json:"status" example:"active" enums:"pending,active,bounced,unsubscribed,quarantined"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewReferralSubscriber maps a Subscriber to its referral summary.
+func NewReferralSubscriber(s models.Subscriber) ReferralSubscriber {
+	return ReferralSubscriber{
+		ID:        s.ID,
+		Name:      s.Name,
+		Email:     s.Email,
+		Status:    s.Status,
+		CreatedAt: s.CreatedAt,
+	}
+}
@@ -0,0 +1,44 @@
+package dto
+
+import (
+	"time"
+
+	"fiber-gorm-api/internal/models"
+)
+
+// CreateRestHookRequest is the body accepted by POST /admin/rest-hooks, as sent by Zapier.
+type CreateRestHookRequest struct {
+	// TargetURL receives a POST of every event; it must be https
+	TargetURL string `json:"target_url" example:"https://hooks.zapier.com/hooks/standard/123/abc/"`
+	Event     string `json:"event" example:"subscriber.created" enums:"subscriber.created,subscriber.updated,subscriber.deleted"`
+}
+
+// RestHookResponse describes a REST hook subscription.
+type RestHookResponse struct {
+	ID        uint      `json:"id"`
+	Event     string    `json:"event" example:"subscriber.created" enums:"subscriber.created,subscriber.updated,subscriber.deleted"`
+	TargetURL string    `json:"target_url" example:"https://hooks.zapier.com/hooks/standard/123/abc/"`
+	CreatedBy string    `json:"created_by" example:"zapier@example.com"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// RestHookPayload is the body POSTed to the target of a REST hook, and an item of the samples.
+type RestHookPayload struct {
+	// ID is the same on every retry of a delivery, so targets can drop duplicates (the
+	// subscriber's id in samples)
+	ID         uint               `json:"id" example:"1042"`
+	Event      string             `json:"event" example:"subscriber.created" enums:"subscriber.created,subscriber.updated,subscriber.deleted"`
+	OccurredAt time.Time          `json:"occurred_at"`
+	Subscriber SubscriberResponse `json:"subscriber"`
+}
+
+// NewRestHookResponse maps a RestHook to its response DTO.
+func NewRestHookResponse(h models.RestHook) RestHookResponse {
+	return RestHookResponse{
+		ID:        h.ID,
+		Event:     h.Event,
+		TargetURL: h.TargetURL,
+		CreatedBy: h.CreatedBy,
+		CreatedAt: h.CreatedAt,
+	}
+}
@@ -0,0 +1,10 @@
+package dto
+
+// ReviewQueueItem is a quarantined signup listed by GET /admin/review-queue, with what the spam
+// checks made of it.
+type ReviewQueueItem struct {
+	SubscriberResponse
+	SpamScore int `json:"spam_score" example:"90"`
+	// SpamSignals are the points of each check that fired, by check name
+	SpamSignals map[string]interface{} `json:"spam_signals" swaggertype:"object,integer" example:"ip_velocity:50,gibberish_name:40"`
+}
@@ -0,0 +1,120 @@
+package dto
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"fiber-gorm-api/internal/models"
+)
+
+// SegmentFilter picks the members of a segment, like the query parameters of
+// GET /admin/subscribers plus the subscriber_types held, metadata tags, signup dates and a
+// filter expression. Empty fields don't filter; every field set must match.
+type SegmentFilter struct {
+	Status         []string `json:"status,omitempty" example:"active"`
+	Verified       string   `json:"verified,omitempty" example:"true" enums:"true,false"`
+	SubscriberType string   `json:"subscriber_type,omitempty" example:"donor"`
+	Frequency      string   `json:"frequency,omitempty" example:"monthly" enums:"daily,weekly,monthly"`
+	Channel        string   `json:"channel,omitempty" example:"email" enums:"email,sms"`
+	// SubscriberTypes keeps subscribers holding any of them
+	SubscriberTypes []string `json:"subscriber_types,omitempty" example:"shopper,volunteer"`
+	// Tags keeps subscribers with any of them in the "tags" array of their metadata
+	Tags []string `json:"tags,omitempty" example:"vip,market-2025"`
+	// CreatedAfter and CreatedBefore bound the signup date, inclusive and exclusive
+	CreatedAfter  *time.Time `json:"created_after,omitempty" example:"2025-01-01T00:00:00Z"`
+	CreatedBefore *time.Time `json:"created_before,omitempty" example:"2025-07-01T00:00:00Z"`
+	// Query is a filter expression, see POST /admin/segments/validate
+	Query string `json:"query,omitempty" example:"type:shopper AND created_at>2024-01-01 AND NOT tag:vip"`
+}
+
+// SegmentRequest is the body accepted by POST /admin/segments and PUT /admin/segments/{id}.
+type SegmentRequest struct {
+	Name        string        `json:"name" example:"Summer donors"`
+	Description string        `json:"description,omitempty" example:"Donors who signed up at the summer markets"`
+	Filter      SegmentFilter `json:"filter"`
+}
+
+// ToModel maps the request to a Segment, without its organization or filter, which is checked
+// and stored by the handler
+func (r SegmentRequest) ToModel() models.Segment {
+	return models.Segment{
+		Name:        strings.TrimSpace(r.Name),
+		Description: strings.TrimSpace(r.Description),
+	}
+}
+
+// SegmentResponse describes a segment.
+type SegmentResponse struct {
+	ID          uint          `json:"id"`
+	Name        string        `json:"name" example:"Summer donors"`
+	Description string        `json:"description,omitempty" example:"Donors who signed up at the summer markets"`
+	Filter      SegmentFilter `json:"filter"`
+	CreatedBy   string        `json:"created_by" example:"admin@example.com"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+}
+
+// segmentFilterOf decodes a filter stored as JSON by a segment or campaign
+func segmentFilterOf(m models.JSONMap) SegmentFilter {
+	var filter SegmentFilter
+	if raw, err := json.Marshal(m); err == nil {
+		json.Unmarshal(raw, &filter)
+	}
+	return filter
+}
+
+// NewSegmentResponse maps a Segment to its response DTO.
+func NewSegmentResponse(s models.Segment) SegmentResponse {
+	return SegmentResponse{
+		ID:          s.ID,
+		Name:        s.Name,
+		Description: s.Description,
+		Filter:      segmentFilterOf(s.Filter),
+		CreatedBy:   s.CreatedBy,
+		CreatedAt:   s.CreatedAt,
+		UpdatedAt:   s.UpdatedAt,
+	}
+}
+
+// StatusCount is the number of subscribers with one status.
+type StatusCount struct {
+	Status string `json:"status" example:"active"`
+	Count  int64  `json:"count" example:"310"`
+}
+
+// SegmentPreviewResponse is the number of subscribers a segment holds now, also broken down by
+// status, most common first.
+type SegmentPreviewResponse struct {
+	Members     int64         `json:"members" example:"342"`
+	ByStatus    []StatusCount `json:"by_status"`
+	GeneratedAt time.Time     `json:"generated_at"`
+}
+
+// FilterValidationRequest is the body accepted by POST /admin/segments/validate.
+type FilterValidationRequest struct {
+	Query string `json:"query" example:"type:shopper AND created_at>2024-01-01 AND NOT tag:vip"`
+}
+
+// FilterError points at the part of a filter expression at fault, Position and Length counting
+// characters from 0.
+type FilterError struct {
+	Message  string `json:"message" example:"Unknown field tga"`
+	Position int    `json:"position" example:"35"`
+	Length   int    `json:"length" example:"3"`
+}
+
+// FilterValidationResponse tells whether a filter expression is valid, and if not why.
+type FilterValidationResponse struct {
+	Valid bool         `json:"valid" example:"false"`
+	Error *FilterError `json:"error,omitempty"`
+}
+
+// FilterErrorResponse is the 400 of the endpoints taking a filter expression it can't accept,
+// Position and Length pointing at the part at fault.
+type FilterErrorResponse struct {
+	Error    string `json:"error" example:"Unknown field tga"`
+	Code     string `json:"code,omitempty" example:"invalid_filter"`
+	Position int    `json:"position" example:"35"`
+	Length   int    `json:"length" example:"3"`
+}
@@ -0,0 +1,75 @@
+package dto
+
+import (
+	"time"
+
+	"fiber-gorm-api/internal/models"
+	"fiber-gorm-api/internal/session"
+)
+
+// SessionResponse describes one signed-in device of the current user.
+type SessionResponse struct {
+	ID         string    `json:"id"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"user_agent"`
+	Device     string    `json:"device" example:"desktop"`
+	OS         string    `json:"os" example:"macOS"`
+	Browser    string    `json:"browser" example:"Firefox"`
+	Country    string    `json:"country,omitempty" example:"NZ"`
+	Region     string    `json:"region,omitempty" example:"AUK"`
+	Current    bool      `json:"current"`
+}
+
+// TrustedDeviceResponse describes one device of the current user that signs in without a code.
+type TrustedDeviceResponse struct {
+	ID         uint      `json:"id"`
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"user_agent"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// NewTrustedDeviceResponses maps trusted devices to response DTOs.
+func NewTrustedDeviceResponses(devices []models.TrustedDevice) []TrustedDeviceResponse {
+	out := make([]TrustedDeviceResponse, len(devices))
+	for i, d := range devices {
+		out[i] = TrustedDeviceResponse{
+			ID:         d.ID,
+			IP:         d.IP,
+			UserAgent:  d.UserAgent,
+			CreatedAt:  d.CreatedAt,
+			LastUsedAt: d.LastUsedAt,
+			ExpiresAt:  d.ExpiresAt,
+		}
+	}
+	return out
+}
+
+// NewSessionResponses maps sessions to response DTOs, flagging the one with currentID.
+func NewSessionResponses(sessions []session.Session, currentID string) []SessionResponse {
+	out := make([]SessionResponse, len(sessions))
+	for i, s := range sessions {
+		lastSeen := s.LastSeenAt
+		if lastSeen.IsZero() {
+			lastSeen = s.CreatedAt // stored before activity was tracked
+		}
+		client := s.Client()
+		out[i] = SessionResponse{
+			ID:         s.ID,
+			CreatedAt:  s.CreatedAt,
+			LastSeenAt: lastSeen,
+			IP:         s.IP,
+			UserAgent:  s.UserAgent,
+			Device:     client.Device,
+			OS:         client.OS,
+			Browser:    client.Browser,
+			Country:    s.Country,
+			Region:     s.Region,
+			Current:    s.ID == currentID,
+		}
+	}
+	return out
+}
@@ -0,0 +1,58 @@
+package dto
+
+import (
+	"strings"
+	"time"
+
+	"fiber-gorm-api/internal/models"
+	"fiber-gorm-api/internal/shortlinks"
+)
+
+// ShortLinkRequest is the body accepted by POST /admin/short-links and PUT /admin/short-links/{id}.
+type ShortLinkRequest struct {
+	// Code is the path of the link under /l/, generated when omitted on create
+	Code string `json:"code,omitempty" example:"spring-market"`
+	URL  string `json:"url" example:"https://market.example.org/spring?utm_source=newsletter"`
+	// CampaignID attributes the clicks of the link to a campaign of the organization
+	CampaignID *uint `json:"campaign_id,omitempty" example:"12"`
+}
+
+// ToModel maps the request to a ShortLink, without its organization
+func (r ShortLinkRequest) ToModel() models.ShortLink {
+	return models.ShortLink{
+		Code:       strings.ToLower(strings.TrimSpace(r.Code)),
+		URL:        strings.TrimSpace(r.URL),
+		CampaignID: r.CampaignID,
+	}
+}
+
+// ShortLinkResponse describes a short link.
+type ShortLinkResponse struct {
+	ID   uint   `json:"id"`
+	Code string `json:"code" example:"spring-market"`
+	// ShortURL is the link to hand out, under SHORT_LINK_BASE_URL
+	ShortURL      string     `json:"short_url" example:"https://go.mylocal.ing/l/spring-market"`
+	URL           string     `json:"url" example:"https://market.example.org/spring?utm_source=newsletter"`
+	CampaignID    *uint      `json:"campaign_id,omitempty" example:"12"`
+	Clicks        int        `json:"clicks" example:"42"`
+	LastClickedAt *time.Time `json:"last_clicked_at,omitempty"`
+	CreatedBy     string     `json:"created_by" example:"admin@example.com"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// NewShortLinkResponse maps a ShortLink to its response DTO.
+func NewShortLinkResponse(l models.ShortLink) ShortLinkResponse {
+	return ShortLinkResponse{
+		ID:            l.ID,
+		Code:          l.Code,
+		ShortURL:      shortlinks.URL(l.Code),
+		URL:           l.URL,
+		CampaignID:    l.CampaignID,
+		Clicks:        l.Clicks,
+		LastClickedAt: l.LastClickedAt,
+		CreatedBy:     l.CreatedBy,
+		CreatedAt:     l.CreatedAt,
+		UpdatedAt:     l.UpdatedAt,
+	}
+}
@@ -0,0 +1,37 @@
+package dto
+
+// SignInRequest is the body accepted by POST /signin/request. The code is emailed, or texted
+// when channel is sms (the default when only a phone is given). Method link emails a
+// single-use sign-in link instead of a code.
+type SignInRequest struct {
+	Email   string `json:"email,omitempty" example:"user@example.com"`
+	Phone   string `json:"phone,omitempty" example:"+15551234567"`
+	Channel string `json:"channel,omitempty" example:"email" enums:"email,sms"`
+	Method  string `json:"method,omitempty" example:"code" enums:"code,link"`
+}
+
+// VerifySignInRequest is the body accepted by POST /signin/verify, with the email or the phone
+// the code was sent to. TrustDevice remembers the device so it signs in without a code next time.
+type VerifySignInRequest struct {
+	Email       string `json:"email,omitempty" example:"user@example.com"`
+	Phone       string `json:"phone,omitempty" example:"+15551234567"`
+	Code        string `json:"code" example:"123456"`
+	TrustDevice bool   `json:"trust_device,omitempty" example:"true"`
+}
+
+// TokenResponse carries a freshly minted session JWT, and the device token of a device
+// trusted by this sign-in.
+type TokenResponse struct {
+	Token       string `json:"token"`
+	DeviceToken string `json:"device_token,omitempty"`
+}
+
+// RememberEmailRequest is the body accepted by PUT /signin/remembered-email
+type RememberEmailRequest struct {
+	Email string `json:"email" example:"user@example.com"`
+}
+
+// RememberedEmailResponse is the email a browser remembers, to prefill the sign-in form
+type RememberedEmailResponse struct {
+	Email string `json:"email" example:"user@example.com"`
+}
@@ -0,0 +1,140 @@
+package dto
+
+import (
+	"strings"
+	"time"
+
+	"fiber-gorm-api/internal/models"
+)
+
+// SignupFormField is a custom field of a signup form. Its value is stored under name in the
+// metadata of the subscribers submitting the form.
+type SignupFormField struct {
+	Name     string   `json:"name" example:"postcode"`
+	Label    string   `json:"label,omitempty" example:"Postcode"`
+	Type     string   `json:"type" example:"text" enums:"text,number,checkbox,select"`
+	Required bool     `json:"required" example:"true"`
+	Options  []string `json:"options,omitempty" example:"north,south"` // the values of a select
+}
+
+// SignupFormRequest is the body accepted by POST /admin/signup-forms and PUT /admin/signup-forms/{id}.
+type SignupFormRequest struct {
+	Slug string `json:"slug" example:"farmers-market"`
+	Name string `json:"name" example:"Farmers market stand"`
+	// SubscriberTypes a submission may pick among, all of them when it doesn't pick
+	SubscriberTypes []string          `json:"subscriber_types" example:"shopper,volunteer"`
+	Fields          []SignupFormField `json:"fields"`
+	// SuccessRedirect is where browsers posting the form as HTML are sent once subscribed
+	SuccessRedirect string `json:"success_redirect,omitempty" example:"https://market.example.org/thanks"`
+	// Captcha requires a captcha token when CAPTCHA_PROVIDER is set, true when omitted
+	Captcha *bool `json:"captcha,omitempty" example:"true"`
+}
+
+// ToModel maps the request to a SignupForm, without its organization
+func (r SignupFormRequest) ToModel() models.SignupForm {
+	types := make([]string, 0, len(r.SubscriberTypes))
+	for _, name := range r.SubscriberTypes {
+		if name = strings.TrimSpace(name); name != "" {
+			types = append(types, name)
+		}
+	}
+	fields := make(models.SignupFormFields, len(r.Fields))
+	for i, f := range r.Fields {
+		fields[i] = models.SignupFormField{
+			Name:     strings.TrimSpace(f.Name),
+			Label:    strings.TrimSpace(f.Label),
+			Type:     f.Type,
+			Required: f.Required,
+			Options:  f.Options,
+		}
+	}
+	return models.SignupForm{
+		Slug:            strings.ToLower(strings.TrimSpace(r.Slug)),
+		Name:            strings.TrimSpace(r.Name),
+		SubscriberTypes: strings.Join(types, ","),
+		Fields:          fields,
+		SuccessRedirect: strings.TrimSpace(r.SuccessRedirect),
+		Captcha:         r.Captcha == nil || *r.Captcha,
+	}
+}
+
+// SignupFormResponse describes a signup form.
+type SignupFormResponse struct {
+	ID              uint              `json:"id"`
+	Slug            string            `json:"slug" example:"farmers-market"`
+	Name            string            `json:"name" example:"Farmers market stand"`
+	SubscriberTypes []string          `json:"subscriber_types" example:"shopper,volunteer"`
+	Fields          []SignupFormField `json:"fields"`
+	SuccessRedirect string            `json:"success_redirect,omitempty" example:"https://market.example.org/thanks"`
+	Captcha         bool              `json:"captcha" example:"true"`
+	CreatedBy       string            `json:"created_by" example:"admin@example.com"`
+	CreatedAt       time.Time         `json:"created_at"`
+	UpdatedAt       time.Time         `json:"updated_at"`
+}
+
+// NewSignupFormResponse maps a SignupForm to its response DTO.
+func NewSignupFormResponse(f models.SignupForm) SignupFormResponse {
+	fields := make([]SignupFormField, len(f.Fields))
+	for i, field := range f.Fields {
+		fields[i] = SignupFormField(field)
+	}
+	return SignupFormResponse{
+		ID:              f.ID,
+		Slug:            f.Slug,
+		Name:            f.Name,
+		SubscriberTypes: f.TypeNames(),
+		Fields:          fields,
+		SuccessRedirect: f.SuccessRedirect,
+		Captcha:         f.Captcha,
+		CreatedBy:       f.CreatedBy,
+		CreatedAt:       f.CreatedAt,
+		UpdatedAt:       f.UpdatedAt,
+	}
+}
+
+// SignupFormSubmission is the JSON body accepted by POST /signup/forms/{slug}/submit. HTML forms
+// post the same names url-encoded, with each custom field a top-level value and
+// subscriber_types repeated.
+type SignupFormSubmission struct {
+	Email string `json:"email" example:"user@example.com"`
+	Name  string `json:"name" example:"Jane Doe"`
+	// SubscriberTypes picks among those of the form, all of them when empty
+	SubscriberTypes []string `json:"subscriber_types,omitempty" example:"shopper"`
+	// Fields holds the values of the custom fields of the form, by name
+	Fields      map[string]interface{} `json:"fields,omitempty"`
+	Locale      string                 `json:"locale,omitempty" example:"fr" enums:"en,fr,es"`
+	UTMSource   string                 `json:"utm_source,omitempty" example:"newsletter"`
+	UTMMedium   string                 `json:"utm_medium,omitempty" example:"email"`
+	UTMCampaign string                 `json:"utm_campaign,omitempty" example:"spring-meetup"`
+	Referrer    string                 `json:"referrer,omitempty" example:"https://forum.example.org/t/meetup"`
+	Ref         string                 `json:"ref,omitempty" example:"k7m2q9xw4d"`
+}
+
+// SignupFormSubmitResponse is returned to JSON submissions of a signup form.
+type SignupFormSubmitResponse struct {
+	// Status is the subscriber's: pending until the emailed link is opened with double opt-in
+	Status      string `json:"status" example:"active"`
+	RedirectURL string `json:"redirect_url,omitempty" example:"https://market.example.org/thanks"`
+}
+
+// SignupWidgetConfig is returned by GET /signup/widget/{slug}?format=json, for partner sites
+// rendering a signup form with their own script.
+type SignupWidgetConfig struct {
+	Slug string `json:"slug" example:"farmers-market"`
+	Name string `json:"name" example:"Farmers market stand"`
+	// SubmitURL is where the form is posted, see POST /signup/forms/{slug}/submit
+	SubmitURL       string            `json:"submit_url" example:"https://api.mylocal.ing/signup/forms/farmers-market/submit?org=market"`
+	SubscriberTypes []string          `json:"subscriber_types" example:"shopper,volunteer"`
+	Fields          []SignupFormField `json:"fields"`
+	// Captcha is set when submissions need a captcha token
+	Captcha *SignupWidgetCaptcha `json:"captcha,omitempty"`
+	// HoneypotField is a field to render hidden and post empty
+	HoneypotField string `json:"honeypot_field,omitempty" example:"website"`
+}
+
+// SignupWidgetCaptcha is the captcha a signup widget renders, its token posted as captcha_token.
+type SignupWidgetCaptcha struct {
+	Provider string `json:"provider" example:"turnstile" enums:"hcaptcha,turnstile"`
+	SiteKey  string `json:"site_key" example:"0x4AAAAAAA..."`
+	Script   string `json:"script" example:"https://challenges.cloudflare.com/turnstile/v0/api.js"`
+}
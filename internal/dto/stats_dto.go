@@ -0,0 +1,100 @@
+package dto
+
+import "time"
+
+// TypeCount is the number of subscribers holding one subscriber_type.
+type TypeCount struct {
+	Name  string `json:"name" example:"shopper"`
+	Count int64  `json:"count" example:"42"`
+}
+
+// GrowthPoint is the number of signups within one day / week / month bucket.
+type GrowthPoint struct {
+	Period time.Time `json:"period"`
+	Count  int64     `json:"count" example:"7"`
+}
+
+// SubscriberGrowth holds signup counts bucketed per day (last 30 days),
+// week (last 12 weeks) and month (last 12 months).
+type SubscriberGrowth struct {
+	Daily   []GrowthPoint `json:"daily"`
+	Weekly  []GrowthPoint `json:"weekly"`
+	Monthly []GrowthPoint `json:"monthly"`
+}
+
+// StatsResponse is returned by GET /admin/stats to power the admin dashboard.
+// Unsubscribed subscribers have status unsubscribed (anonymized ones included); UnsubscribeRate is their share of all subscribers.
+type StatsResponse struct {
+	TotalSubscribers        int64                `json:"total_subscribers" example:"1200"`
+	ActiveSubscribers       int64                `json:"active_subscribers" example:"1150"`
+	UnsubscribedSubscribers int64                `json:"unsubscribed_subscribers" example:"50"`
+	UnsubscribeRate         float64              `json:"unsubscribe_rate" example:"0.0417"`
+	ByType                  []TypeCount          `json:"by_type"`
+	Growth                  SubscriberGrowth     `json:"growth"`
+	RecentSignups           []SubscriberResponse `json:"recent_signups"`
+	GeneratedAt             time.Time            `json:"generated_at"`
+}
+
+// SessionCount is the number of active sessions sharing one device type, OS, browser or country.
+type SessionCount struct {
+	Name  string `json:"name" example:"mobile"`
+	Count int64  `json:"count" example:"12"`
+}
+
+// SessionStatsResponse is returned by GET /admin/stats/sessions: the active sessions of the
+// organization's admins, broken down by client and, with GeoIP enabled, country. Each breakdown
+// is sorted by count, values that couldn't be told counted as "unknown".
+type SessionStatsResponse struct {
+	ActiveSessions int64          `json:"active_sessions" example:"30"`
+	ByDevice       []SessionCount `json:"by_device"`
+	ByOS           []SessionCount `json:"by_os"`
+	ByBrowser      []SessionCount `json:"by_browser"`
+	ByCountry      []SessionCount `json:"by_country"`
+	GeoIPEnabled   bool           `json:"geoip_enabled"`
+	GeneratedAt    time.Time      `json:"generated_at"`
+}
+
+// GeoCount is the number of records located in one country, or one region of it.
+type GeoCount struct {
+	Country string `json:"country" example:"NZ"`
+	Region  string `json:"region,omitempty" example:"AUK"`
+	Count   int64  `json:"count" example:"18"`
+}
+
+// GeoStatsResponse is returned by GET /admin/stats/geo: where the organization's public signups
+// and admin activity came from, most common first. Only records located by GeoIP are counted:
+// admin-created subscribers and records from before GEOIP_DB_PATH was set aren't.
+type GeoStatsResponse struct {
+	GeoIPEnabled      bool       `json:"geoip_enabled"`
+	SignupsByCountry  []GeoCount `json:"signups_by_country"`
+	SignupsByRegion   []GeoCount `json:"signups_by_region"`
+	ActivityByCountry []GeoCount `json:"activity_by_country"`
+	GeneratedAt       time.Time  `json:"generated_at"`
+}
+
+// SourceCount is the number of signups attributed to one source, medium or referrer.
+type SourceCount struct {
+	Name  string `json:"name" example:"newsletter"`
+	Count int64  `json:"count" example:"25"`
+}
+
+// CampaignCount is the number of signups of one utm_campaign of a utm_source.
+type CampaignCount struct {
+	Source   string `json:"source" example:"newsletter"`
+	Campaign string `json:"campaign" example:"spring-meetup"`
+	Count    int64  `json:"count" example:"9"`
+}
+
+// SourceStatsResponse is returned by GET /admin/stats/sources: the subscribers of the
+// organization created in the period by the channel they came from, most common first.
+// BySource is the utm_source, else the referrer host, else none (direct signups, but also admin
+// creates and imports); ByMedium the utm_medium or none. ByCampaign and ByReferrer only count
+// subscribers with one.
+type SourceStatsResponse struct {
+	TotalSignups int64           `json:"total_signups" example:"120"`
+	BySource     []SourceCount   `json:"by_source"`
+	ByMedium     []SourceCount   `json:"by_medium"`
+	ByCampaign   []CampaignCount `json:"by_campaign"`
+	ByReferrer   []SourceCount   `json:"by_referrer"`
+	GeneratedAt  time.Time       `json:"generated_at"`
+}
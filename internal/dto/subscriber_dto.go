@@ -0,0 +1,229 @@
+package dto
+
+import (
+	"time"
+
+	"fiber-gorm-api/internal/models"
+)
+
+// SubscriberTypeRequest is the only part of a subscriber_type a client may set.
+// SubscriberID, ID and timestamps are always assigned server-side. Omitted preferences
+// keep their current value, weekly by email for a new type.
+type SubscriberTypeRequest struct {
+	Name      string `json:"name" example:"shopper"`
+	Frequency string `json:"frequency,omitempty" example:"weekly" enums:"daily,weekly,monthly"`
+	Channel   string `json:"channel,omitempty" example:"email" enums:"email,sms"`
+}
+
+// CreateSubscriberRequest is the body accepted by POST /admin/subscribers and /signup/subscribers.
+type CreateSubscriberRequest struct {
+	Email           string                  `json:"email" example:"user@example.com"`
+	Name            string                  `json:"name" example:"Jane Doe"`
+	SubscriberTypes []SubscriberTypeRequest `json:"subscriber_types,omitempty"`
+	// Metadata is only stored for admin requests; public signups can't set it
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+	// Locale is the language of the emails the subscriber gets; a public signup without one
+	// uses the request's Accept-Language, an admin create en
+	Locale string `json:"locale,omitempty" example:"fr" enums:"en,fr,es"`
+	// UTMSource, UTMMedium, UTMCampaign and Referrer attribute a public signup to the channel it
+	// came from (see GET /admin/stats/sources); admin creates ignore them. Only the host of the
+	// referrer is kept.
+	UTMSource   string `json:"utm_source,omitempty" example:"newsletter"`
+	UTMMedium   string `json:"utm_medium,omitempty" example:"email"`
+	UTMCampaign string `json:"utm_campaign,omitempty" example:"spring-meetup"`
+	Referrer    string `json:"referrer,omitempty" example:"https://forum.example.org/t/meetup"`
+	// Ref is the referral_code of the subscriber who referred a public signup, also read from
+	// ?ref=; admin creates ignore it
+	Ref string `json:"ref,omitempty" example:"k7m2q9xw4d"`
+}
+
+// UpdateSubscriberRequest is the body accepted by PUT /admin/subscribers/{id}.
+// Omitting subscriber_types, metadata or locale leaves them untouched, an empty array (object) clears them.
+// Version, when given, must equal the stored version or the update is rejected with 409.
+type UpdateSubscriberRequest struct {
+	Email           string                  `json:"email" example:"user@example.com"`
+	Name            string                  `json:"name" example:"Jane Doe"`
+	SubscriberTypes []SubscriberTypeRequest `json:"subscriber_types,omitempty"`
+	Metadata        map[string]interface{}  `json:"metadata,omitempty"`
+	Locale          string                  `json:"locale,omitempty" example:"fr" enums:"en,fr,es"`
+	Version         *int                    `json:"version,omitempty" example:"3"`
+}
+
+// MergeSubscribersRequest is the body accepted by POST /admin/subscribers/{id}/merge.
+// EmailRule picks whose address the merged subscriber keeps: target (default), source, or
+// verified, the verified one. Version, when given, must equal the target's stored version.
+type MergeSubscribersRequest struct {
+	SourceID  uint   `json:"source_id" example:"42"`
+	EmailRule string `json:"email_rule,omitempty" example:"verified" enums:"target,source,verified"`
+	Version   *int   `json:"version,omitempty" example:"3"`
+}
+
+// ConfirmSubscriberRequest is the body accepted by POST /signup/subscribers/confirm.
+type ConfirmSubscriberRequest struct {
+	Token string `json:"token" example:"kq3X...emailed-token"`
+}
+
+// ConfirmSubscriberResponse is returned once a double opt-in link was confirmed.
+type ConfirmSubscriberResponse struct {
+	Status          string    `json:"status" example:"active"`
+	EmailVerifiedAt time.Time `json:"email_verified_at"`
+}
+
+// SubscriberTypeResponse is the public representation of a subscriber_type.
+type SubscriberTypeResponse struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name" example:"shopper"`
+	Frequency string    `json:"frequency" example:"weekly" enums:"daily,weekly,monthly"`
+	Channel   string    `json:"channel" example:"email" enums:"email,sms"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SubscriberResponse is the public representation of a subscriber.
+type SubscriberResponse struct {
+	ID              uint                     `json:"id"`
+	OrgID           uint                     `json:"org_id"`
+	Email           string                   `json:"email" example:"user@example.com"`
+	Name            string                   `json:"name" example:"Jane Doe"`
+	SubscriberTypes []SubscriberTypeResponse `json:"subscriber_types"`
+	Status          string                   `json:"status" example:"active" enums:"pending,active,bounced,unsubscribed,quarantined"`
+	EmailVerifiedAt *time.Time               `json:"email_verified_at,omitempty"`
+	AnonymizedAt    *time.Time               `json:"anonymized_at,omitempty"`
+	Metadata        map[string]interface{}   `json:"metadata"`
+	Locale          string                   `json:"locale" example:"en" enums:"en,fr,es"`
+	Version         int                      `json:"version" example:"3"`
+	// ReferralCode is shared by the subscriber as the ref of the signups they refer
+	ReferralCode string `json:"referral_code,omitempty" example:"k7m2q9xw4d"`
+	// ReferredByID is the subscriber whose referral code they signed up with
+	ReferredByID *uint     `json:"referred_by_id,omitempty" example:"12"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	// Notes are only embedded on ?include=notes, newest first
+	Notes []SubscriberNoteResponse `json:"notes,omitempty"`
+}
+
+// SubscriberFields lists the fields of SubscriberResponse, by JSON name, ?fields= may pick
+var SubscriberFields = []string{
+	"id", "org_id", "email", "name", "subscriber_types", "status", "email_verified_at",
+	"anonymized_at", "metadata", "locale", "version", "referral_code", "referred_by_id", "created_at",
+	"updated_at", "notes",
+}
+
+// Fields keeps only the named fields of r (see SubscriberFields), for sparse responses
+func (r SubscriberResponse) Fields(names []string) map[string]interface{} {
+	all := map[string]interface{}{
+		"id":                r.ID,
+		"org_id":            r.OrgID,
+		"email":             r.Email,
+		"name":              r.Name,
+		"subscriber_types":  r.SubscriberTypes,
+		"status":            r.Status,
+		"email_verified_at": r.EmailVerifiedAt,
+		"anonymized_at":     r.AnonymizedAt,
+		"metadata":          r.Metadata,
+		"locale":            r.Locale,
+		"version":           r.Version,
+		"referral_code":     r.ReferralCode,
+		"referred_by_id":    r.ReferredByID,
+		"created_at":        r.CreatedAt,
+		"updated_at":        r.UpdatedAt,
+		"notes":             r.Notes,
+	}
+	if r.Notes == nil {
+		all["notes"] = []SubscriberNoteResponse{}
+	}
+	out := make(map[string]interface{}, len(names))
+	for _, name := range names {
+		out[name] = all[name]
+	}
+	return out
+}
+
+// ToModel maps the whitelisted request fields onto a new Subscriber.
+func (r CreateSubscriberRequest) ToModel() models.Subscriber {
+	return models.Subscriber{
+		Email:           r.Email,
+		Name:            r.Name,
+		SubscriberTypes: toSubscriberTypeModels(r.SubscriberTypes),
+		Metadata:        r.Metadata,
+		Locale:          r.Locale,
+	}
+}
+
+// ToModel maps the whitelisted request fields onto a Subscriber. SubscriberTypes and Metadata
+// stay nil when the client didn't send them so callers can tell "unchanged" from "cleared".
+func (r UpdateSubscriberRequest) ToModel() models.Subscriber {
+	return models.Subscriber{
+		Email:           r.Email,
+		Name:            r.Name,
+		SubscriberTypes: toSubscriberTypeModels(r.SubscriberTypes),
+		Metadata:        r.Metadata,
+		Locale:          r.Locale,
+	}
+}
+
+func toSubscriberTypeModels(in []SubscriberTypeRequest) []models.SubscriberType {
+	if in == nil {
+		return nil
+	}
+	out := make([]models.SubscriberType, len(in))
+	for i, t := range in {
+		out[i] = models.SubscriberType{Name: t.Name, Frequency: t.Frequency, Channel: t.Channel}
+	}
+	return out
+}
+
+// NewSubscriberResponse maps a Subscriber (with preloaded SubscriberTypes) to its response DTO.
+func NewSubscriberResponse(s models.Subscriber) SubscriberResponse {
+	types := make([]SubscriberTypeResponse, len(s.SubscriberTypes))
+	for i, t := range s.SubscriberTypes {
+		types[i] = SubscriberTypeResponse{
+			ID:        t.ID,
+			Name:      t.Name,
+			Frequency: t.Frequency,
+			Channel:   t.Channel,
+			CreatedAt: t.CreatedAt,
+			UpdatedAt: t.UpdatedAt,
+		}
+	}
+	resp := SubscriberResponse{
+		ID:              s.ID,
+		OrgID:           s.OrgID,
+		Email:           s.Email,
+		Name:            s.Name,
+		SubscriberTypes: types,
+		Status:          s.Status,
+		EmailVerifiedAt: s.EmailVerifiedAt,
+		AnonymizedAt:    s.AnonymizedAt,
+		Metadata:        metadataOrEmpty(s.Metadata),
+		Locale:          s.Locale,
+		Version:         s.Version,
+		ReferredByID:    s.ReferredByID,
+		CreatedAt:       s.CreatedAt,
+		UpdatedAt:       s.UpdatedAt,
+	}
+	if s.ReferralCode != nil {
+		resp.ReferralCode = *s.ReferralCode
+	}
+	if s.Notes != nil {
+		resp.Notes = NewSubscriberNoteResponses(s.Notes)
+	}
+	return resp
+}
+
+// metadataOrEmpty serializes missing metadata as {} rather than null
+func metadataOrEmpty(m models.JSONMap) map[string]interface{} {
+	if m == nil {
+		return map[string]interface{}{}
+	}
+	return m
+}
+
+// NewSubscriberResponses maps a list of Subscribers to response DTOs.
+func NewSubscriberResponses(subs []models.Subscriber) []SubscriberResponse {
+	out := make([]SubscriberResponse, len(subs))
+	for i, s := range subs {
+		out[i] = NewSubscriberResponse(s)
+	}
+	return out
+}
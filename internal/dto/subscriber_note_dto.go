@@ -0,0 +1,41 @@
+package dto
+
+import (
+	"time"
+
+	"fiber-gorm-api/internal/models"
+)
+
+// CreateSubscriberNoteRequest is the body accepted by POST /admin/subscribers/{id}/notes.
+type CreateSubscriberNoteRequest struct {
+	Text string `json:"text" example:"Asked to only receive the monthly digest"`
+}
+
+// SubscriberNoteResponse is the public representation of a note on a subscriber.
+type SubscriberNoteResponse struct {
+	ID        uint      `json:"id"`
+	Author    string    `json:"author" example:"admin@mylocal.ing"`
+	Text      string    `json:"text" example:"Asked to only receive the monthly digest"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewSubscriberNoteResponse maps a note to its response DTO.
+func NewSubscriberNoteResponse(n models.SubscriberNote) SubscriberNoteResponse {
+	return SubscriberNoteResponse{
+		ID:        n.ID,
+		Author:    n.Author,
+		Text:      n.Text,
+		CreatedAt: n.CreatedAt,
+		UpdatedAt: n.UpdatedAt,
+	}
+}
+
+// NewSubscriberNoteResponses maps notes to response DTOs.
+func NewSubscriberNoteResponses(notes []models.SubscriberNote) []SubscriberNoteResponse {
+	out := make([]SubscriberNoteResponse, len(notes))
+	for i, n := range notes {
+		out[i] = NewSubscriberNoteResponse(n)
+	}
+	return out
+}
@@ -0,0 +1,128 @@
+package dto
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"sort"
+	"time"
+
+	"fiber-gorm-api/internal/models"
+)
+
+// SubscriberChange is one field a revision changed, for rendering a diff. Metadata keys are
+// compared one by one, as metadata.<key>.
+type SubscriberChange struct {
+	Field  string      `json:"field" example:"name"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// SubscriberRevisionResponse is one entry of a subscriber's history.
+type SubscriberRevisionResponse struct {
+	ID        uint                       `json:"id"`
+	Version   int                        `json:"version" example:"4"`
+	Action    string                     `json:"action" example:"updated" enums:"created,updated,confirmed,merged,delivery,anonymized,restored,undeleted,approved"`
+	Author    string                     `json:"author" example:"admin@mylocal.ing"`
+	CreatedAt time.Time                  `json:"created_at"`
+	Before    *models.SubscriberSnapshot `json:"before"`
+	After     models.SubscriberSnapshot  `json:"after"`
+	Changes   []SubscriberChange         `json:"changes"`
+}
+
+// NewSubscriberRevisionResponse decodes the snapshots of a revision and diffs them.
+func NewSubscriberRevisionResponse(r models.SubscriberRevision) (SubscriberRevisionResponse, error) {
+	resp := SubscriberRevisionResponse{
+		ID:        r.ID,
+		Version:   r.Version,
+		Action:    r.Action,
+		Author:    r.Author,
+		CreatedAt: r.CreatedAt,
+	}
+	if err := json.Unmarshal([]byte(r.After), &resp.After); err != nil {
+		return resp, err
+	}
+	if r.Before != nil {
+		resp.Before = &models.SubscriberSnapshot{}
+		if err := json.Unmarshal([]byte(*r.Before), resp.Before); err != nil {
+			return resp, err
+		}
+	}
+	resp.Changes = DiffSnapshots(resp.Before, resp.After)
+	return resp, nil
+}
+
+// DiffSnapshots lists the fields that differ between two states of a subscriber; a nil before
+// (a new subscriber) compares against empty values
+func DiffSnapshots(before *models.SubscriberSnapshot, after models.SubscriberSnapshot) []SubscriberChange {
+	var from models.SubscriberSnapshot
+	if before != nil {
+		from = *before
+	}
+	changes := []SubscriberChange{}
+	add := func(field string, b, a interface{}) {
+		changes = append(changes, SubscriberChange{Field: field, Before: b, After: a})
+	}
+
+	if from.Email != after.Email {
+		add("email", from.Email, after.Email)
+	}
+	if from.Name != after.Name {
+		add("name", from.Name, after.Name)
+	}
+	if from.Status != after.Status {
+		add("status", from.Status, after.Status)
+	}
+	if !sameTime(from.EmailVerifiedAt, after.EmailVerifiedAt) {
+		add("email_verified_at", from.EmailVerifiedAt, after.EmailVerifiedAt)
+	}
+	if !slices.Equal(from.SubscriberTypes, after.SubscriberTypes) {
+		add("subscriber_types", nonNil(from.SubscriberTypes), nonNil(after.SubscriberTypes))
+	}
+
+	keys := map[string]bool{}
+	for k := range from.Metadata {
+		keys[k] = true
+	}
+	for k := range after.Metadata {
+		keys[k] = true
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+	for _, k := range sorted {
+		if b, a := from.Metadata[k], after.Metadata[k]; !reflect.DeepEqual(b, a) {
+			add("metadata."+k, b, a)
+		}
+	}
+	return changes
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+// NewSubscriberRevisionResponses maps revisions to response DTOs.
+func NewSubscriberRevisionResponses(revisions []models.SubscriberRevision) ([]SubscriberRevisionResponse, error) {
+	out := make([]SubscriberRevisionResponse, len(revisions))
+	for i, r := range revisions {
+		resp, err := NewSubscriberRevisionResponse(r)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = resp
+	}
+	return out, nil
+}
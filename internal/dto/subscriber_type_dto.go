@@ -0,0 +1,24 @@
+package dto
+
+import "time"
+
+// CreateSubscriberTypeRequest is the body accepted by POST /admin/subscriber-types.
+type CreateSubscriberTypeRequest struct {
+	Name        string `json:"name" example:"volunteer"`
+	Description string `json:"description,omitempty" example:"Helps out at local events"`
+}
+
+// SubscriberTypeDefinitionResponse is a name subscriber_types may take.
+type SubscriberTypeDefinitionResponse struct {
+	Name        string    `json:"name" example:"volunteer"`
+	Description string    `json:"description" example:"Helps out at local events"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// InvalidSubscriberTypesResponse is the 422 returned for subscriber_types names that aren't defined.
+type InvalidSubscriberTypesResponse struct {
+	Error    string   `json:"error" example:"unknown subscriber_type shoper, accepted are business, champion, developer, donor, driver, shopper"`
+	Code     string   `json:"code" example:"invalid_subscriber_type"`
+	Invalid  []string `json:"invalid" example:"shoper"`
+	Accepted []string `json:"accepted" example:"business,champion,developer,donor,driver,shopper"`
+}
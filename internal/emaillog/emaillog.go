@@ -0,0 +1,84 @@
+// Package emaillog keeps a record of every email the API sends (models.EmailLog), so support can
+// answer "did we send it?": when, of what type, with which files, whether SendGrid took it, and
+// what became of it according to the SendGrid Event Webhook.
+package emaillog
+
+import (
+	"log"
+	"strings"
+
+	"fiber-gorm-api/internal/models"
+	sendgridservice "fiber-gorm-api/internal/services"
+
+	"gorm.io/gorm"
+)
+
+// Install records every email sendgridservice sends, or fails to, in db
+func Install(db *gorm.DB) {
+	sendgridservice.Log = func(sent sendgridservice.SentEmail) {
+		if err := Record(db, sent); err != nil {
+			log.Printf("[WARN] Email log: recording the %s email to %s failed: %v", sent.Type, sent.To, err)
+		}
+	}
+}
+
+// Record logs sent
+func Record(db *gorm.DB, sent sendgridservice.SentEmail) error {
+	entry := models.EmailLog{
+		Type:      sent.Type,
+		ToEmail:   sent.To,
+		MessageID: sent.MessageID,
+		Status:    models.EmailStatusSent,
+	}
+	for _, a := range sent.Attachments {
+		entry.Attachments = append(entry.Attachments, models.EmailAttachment{
+			Filename:    a.Filename,
+			ContentType: a.ContentType,
+			Size:        a.Size,
+			SHA256:      a.SHA256,
+		})
+	}
+	if sent.Err != nil {
+		entry.Status = models.EmailStatusFailed
+		entry.Error = sent.Err.Error()
+	}
+	return db.Create(&entry).Error
+}
+
+// ApplyEvent moves the logged email a webhook event is about along: deferred while SendGrid
+// retries it, then delivered, bounced or dropped. Events arrive out of order at times, so a
+// final status is never replaced. Other events (opens, clicks, ...) are ignored.
+func ApplyEvent(db *gorm.DB, event, sgMessageID string) error {
+	from := []string{models.EmailStatusSent, models.EmailStatusDeferred}
+	var status string
+	switch event {
+	case "deferred":
+		status, from = models.EmailStatusDeferred, []string{models.EmailStatusSent}
+	case "delivered":
+		status = models.EmailStatusDelivered
+	case "bounce":
+		status = models.EmailStatusBounced
+	case "dropped":
+		status = models.EmailStatusDropped
+	default:
+		return nil
+	}
+	id := messageID(sgMessageID)
+	if id == "" {
+		return nil
+	}
+	return db.Model(&models.EmailLog{}).
+		Where("message_id = ? AND status IN ?", id, from).
+		Update("status", status).Error
+}
+
+// messageID is the X-Message-Id of the email an event's sg_message_id is about, which it
+// starts with: "<X-Message-Id>.filter...", or "<X-Message-Id>.recvd-..." for some events
+func messageID(sgMessageID string) string {
+	for _, sep := range []string{".filter", ".recvd"} {
+		if id, _, found := strings.Cut(sgMessageID, sep); found {
+			return id
+		}
+	}
+	return sgMessageID
+}
@@ -0,0 +1,79 @@
+package emaillog
+
+import (
+	"errors"
+	"testing"
+
+	"fiber-gorm-api/internal/models"
+	sendgridservice "fiber-gorm-api/internal/services"
+	"fiber-gorm-api/internal/testutil/testenv"
+)
+
+func TestMessageID(t *testing.T) {
+	cases := map[string]string{
+		"14c5d75ce93.dfd.64b469.filter0001.16648.5515E0B88.0":  "14c5d75ce93.dfd.64b469",
+		"W86EgYT6SQKk0lRflfLRsA.filterdrecv-p3las1-5bf99c48d5": "W86EgYT6SQKk0lRflfLRsA",
+		"W86EgYT6SQKk0lRflfLRsA.recvd-5f8c9b5c9-x9qzn-1-0":     "W86EgYT6SQKk0lRflfLRsA",
+		"W86EgYT6SQKk0lRflfLRsA":                               "W86EgYT6SQKk0lRflfLRsA",
+	}
+	for in, want := range cases {
+		if got := messageID(in); got != want {
+			t.Errorf("messageID(%q) = %q, expected %q", in, got, want)
+		}
+	}
+}
+
+func TestLog(t *testing.T) {
+	conn := testenv.SQLite(t)
+	status := func(id string) string {
+		t.Helper()
+		var entry models.EmailLog
+		if err := conn.Where("to_email = ?", id+"@example.com").First(&entry).Error; err != nil {
+			t.Fatal(err)
+		}
+		return entry.Status
+	}
+
+	Install(conn)
+	t.Cleanup(func() { sendgridservice.Log = nil })
+	sendgridservice.Log(sendgridservice.SentEmail{Type: sendgridservice.EmailTypeConfirmation, To: "ok@example.com", MessageID: "ok"})
+	sendgridservice.Log(sendgridservice.SentEmail{Type: sendgridservice.EmailTypeSignInCode, To: "down@example.com", Err: errors.New("connection refused")})
+	if got := status("ok"); got != models.EmailStatusSent {
+		t.Errorf("Expected the accepted email sent, got %s", got)
+	}
+	var failed models.EmailLog
+	conn.Where("to_email = ?", "down@example.com").First(&failed)
+	if failed.Status != models.EmailStatusFailed || failed.Error != "connection refused" || failed.Type != sendgridservice.EmailTypeSignInCode {
+		t.Errorf("Expected the failed send with its error, got %+v", failed)
+	}
+
+	invite := sendgridservice.Attachment{Filename: "market.ics", ContentType: "text/calendar", Content: []byte("BEGIN:VCALENDAR")}
+	sendgridservice.Log(sendgridservice.SentEmail{Type: sendgridservice.EmailTypeCampaign, To: "invited@example.com", Attachments: []sendgridservice.AttachmentRef{invite.Ref()}})
+	var attached models.EmailLog
+	conn.Where("to_email = ?", "invited@example.com").First(&attached)
+	if len(attached.Attachments) != 1 || attached.Attachments[0].Filename != "market.ics" || attached.Attachments[0].Size != len(invite.Content) || attached.Attachments[0].SHA256 != invite.Ref().SHA256 {
+		t.Errorf("Expected a reference to the attachment logged, got %+v", attached.Attachments)
+	}
+
+	apply := func(event string) {
+		t.Helper()
+		if err := ApplyEvent(conn, event, "ok.filter0001.16648.5515E0B88.0"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	apply("deferred")
+	if got := status("ok"); got != models.EmailStatusDeferred {
+		t.Errorf("Expected deferred, got %s", got)
+	}
+	apply("open")
+	apply("delivered")
+	if got := status("ok"); got != models.EmailStatusDelivered {
+		t.Errorf("Expected delivered, got %s", got)
+	}
+	// a late deferral or bounce doesn't undo the delivery
+	apply("deferred")
+	apply("bounce")
+	if got := status("ok"); got != models.EmailStatusDelivered {
+		t.Errorf("Expected delivered to stay, got %s", got)
+	}
+}
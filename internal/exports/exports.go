@@ -0,0 +1,335 @@
+// Package exports writes subscriber exports in the background: POST /admin/exports queues an
+// ExportJob, the scheduler's export worker claims it and writes the file to storage.Default,
+// and the admin downloads it through a signed link until the file expires.
+package exports
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"fiber-gorm-api/internal/db"
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/models"
+	"fiber-gorm-api/internal/repository"
+	"fiber-gorm-api/internal/storage"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultTTL = 24 * time.Hour
+	// batchSize is how many subscribers are read at a time, so large exports don't sit in memory
+	batchSize = 500
+)
+
+// Formats are the file formats an export may be written in
+var Formats = []string{models.ExportFormatCSV, models.ExportFormatJSON}
+
+// csvHeader are the columns of CSV exports. subscriber_types lists name:frequency:channel
+// triplets separated by semicolons.
+var csvHeader = []string{"id", "email", "name", "status", "locale", "email_verified_at", "created_at", "updated_at", "subscriber_types"}
+
+// contentTypes are the MIME types of the formats
+var contentTypes = map[string]string{
+	models.ExportFormatCSV:  "text/csv; charset=utf-8",
+	models.ExportFormatJSON: "application/json",
+}
+
+// FileName is the name the file of job is downloaded as
+func FileName(job *models.ExportJob) string {
+	return "subscribers-" + job.CreatedAt.UTC().Format("20060102-150405") + "." + job.Format
+}
+
+// TTL is how long a finished export can be downloaded before it's purged (EXPORT_TTL_HOURS)
+func TTL() time.Duration {
+	if n, err := strconv.Atoi(os.Getenv("EXPORT_TTL_HOURS")); err == nil && n > 0 {
+		return time.Duration(n) * time.Hour
+	}
+	return defaultTTL
+}
+
+// FilterMap stores f in an ExportJob
+func FilterMap(f repository.SubscriberFilter) models.JSONMap {
+	return f.Map()
+}
+
+// Filter reads back the filter of job
+func Filter(job *models.ExportJob) (repository.SubscriberFilter, error) {
+	return repository.FilterFromMap(job.Filters)
+}
+
+// ProcessPending runs the pending jobs, oldest first, until none is left, and returns how many
+// it ran. Several workers may call it at once: each job is claimed by a single one.
+func ProcessPending(conn *gorm.DB) (int, error) {
+	ran := 0
+	for {
+		job, err := claim(conn)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ran, nil
+		}
+		if err != nil {
+			return ran, err
+		}
+		ran++
+		if err := Run(conn, job); err != nil {
+			log.Printf("[WARN] Exports: job %d failed: %v", job.ID, err)
+		}
+	}
+}
+
+// claim marks the oldest pending job running and returns it
+func claim(conn *gorm.DB) (*models.ExportJob, error) {
+	for {
+		var job models.ExportJob
+		if err := conn.Where("status = ?", models.ExportPending).Order("id").First(&job).Error; err != nil {
+			return nil, err
+		}
+		ok, err := claimJob(conn, &job)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return &job, nil
+		}
+	}
+}
+
+// claimJob marks job running unless it isn't pending anymore. The status check in the UPDATE
+// makes a job claimed by another worker in the meantime look taken rather than run twice.
+func claimJob(conn *gorm.DB, job *models.ExportJob) (bool, error) {
+	now := time.Now()
+	res := conn.Model(&models.ExportJob{}).
+		Where("id = ? AND status = ?", job.ID, models.ExportPending).
+		Updates(map[string]interface{}{"status": models.ExportRunning, "started_at": now})
+	if res.Error != nil || res.RowsAffected == 0 {
+		return false, res.Error
+	}
+	job.Status, job.StartedAt = models.ExportRunning, &now
+	return true, nil
+}
+
+// RunJob runs the pending job id right away rather than waiting for the worker, for myloctl.
+// It returns gorm.ErrRecordNotFound when no pending job has the id.
+func RunJob(conn *gorm.DB, id uint) (*models.ExportJob, error) {
+	var job models.ExportJob
+	if err := conn.First(&job, id).Error; err != nil {
+		return nil, err
+	}
+	ok, err := claimJob(conn, &job)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	err = Run(conn, &job)
+	if reloadErr := conn.First(&job, id).Error; reloadErr != nil && err == nil {
+		err = reloadErr
+	}
+	return &job, err
+}
+
+// Run writes the file of a claimed job and saves the job done, or failed with the error
+func Run(conn *gorm.DB, job *models.ExportJob) error {
+	rows, key, err := write(conn, job)
+	now := time.Now()
+	updates := map[string]interface{}{"finished_at": now}
+	if err != nil {
+		updates["status"] = models.ExportFailed
+		updates["error"] = err.Error()
+	} else {
+		updates["status"] = models.ExportDone
+		updates["row_count"] = rows
+		updates["file"] = key
+		updates["expires_at"] = now.Add(TTL())
+	}
+	if saveErr := conn.Model(job).Updates(updates).Error; saveErr != nil {
+		if key != "" {
+			storage.Default.Delete(context.Background(), key)
+		}
+		return saveErr
+	}
+	return err
+}
+
+// write exports the subscribers matching the job to storage and returns how many it wrote and
+// the key of the file. The file is spooled to a temporary file first so the store gets its size
+// up front and a failed export leaves nothing behind.
+func write(conn *gorm.DB, job *models.ExportJob) (int, string, error) {
+	filter, err := Filter(job)
+	if err != nil {
+		return 0, "", fmt.Errorf("reading filters: %w", err)
+	}
+	scope, err := filter.Scope()
+	if err != nil {
+		return 0, "", err
+	}
+	f, err := os.CreateTemp("", fmt.Sprintf("export-%d-*", job.ID))
+	if err != nil {
+		return 0, "", err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	buf := bufio.NewWriter(f)
+	var enc encoder
+	switch job.Format {
+	case models.ExportFormatCSV:
+		enc = newCSVEncoder(buf)
+	case models.ExportFormatJSON:
+		enc = &jsonEncoder{w: buf}
+	default:
+		return 0, "", fmt.Errorf("unknown format %q", job.Format)
+	}
+
+	rows := 0
+	var batch []models.Subscriber
+	res := db.Replica(conn).
+		Where("subscribers.org_id = ?", job.OrgID).
+		Scopes(scope).
+		Preload("SubscriberTypes").
+		FindInBatches(&batch, batchSize, func(_ *gorm.DB, _ int) error {
+			for _, s := range batch {
+				if err := enc.encode(dto.NewSubscriberResponse(s)); err != nil {
+					return err
+				}
+			}
+			rows += len(batch)
+			return nil
+		})
+	if res.Error != nil {
+		return 0, "", res.Error
+	}
+	if err := enc.close(); err != nil {
+		return 0, "", err
+	}
+	if err := buf.Flush(); err != nil {
+		return 0, "", err
+	}
+	size, err := f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, "", err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return 0, "", err
+	}
+
+	key := fmt.Sprintf("%s%d.%s", storage.PrefixExports, job.ID, job.Format)
+	if err := storage.Default.Put(context.Background(), key, f, size, contentTypes[job.Format]); err != nil {
+		return 0, "", err
+	}
+	return rows, key, nil
+}
+
+// encoder writes subscribers one at a time in an export format
+type encoder interface {
+	encode(s dto.SubscriberResponse) error
+	// close writes what follows the last subscriber
+	close() error
+}
+
+type csvEncoder struct {
+	w      *csv.Writer
+	header bool
+}
+
+func newCSVEncoder(w io.Writer) *csvEncoder {
+	return &csvEncoder{w: csv.NewWriter(w)}
+}
+
+func (e *csvEncoder) encode(s dto.SubscriberResponse) error {
+	if !e.header {
+		e.header = true
+		if err := e.w.Write(csvHeader); err != nil {
+			return err
+		}
+	}
+	types := make([]string, len(s.SubscriberTypes))
+	for i, t := range s.SubscriberTypes {
+		types[i] = t.Name + ":" + t.Frequency + ":" + t.Channel
+	}
+	verified := ""
+	if s.EmailVerifiedAt != nil {
+		verified = s.EmailVerifiedAt.UTC().Format(time.RFC3339)
+	}
+	return e.w.Write([]string{
+		strconv.FormatUint(uint64(s.ID), 10), s.Email, s.Name, s.Status, s.Locale, verified,
+		s.CreatedAt.UTC().Format(time.RFC3339), s.UpdatedAt.UTC().Format(time.RFC3339),
+		strings.Join(types, ";"),
+	})
+}
+
+func (e *csvEncoder) close() error {
+	// an empty export still has its header
+	if !e.header {
+		if err := e.w.Write(csvHeader); err != nil {
+			return err
+		}
+	}
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// jsonEncoder writes an array of dto.SubscriberResponse, the shape of GET /admin/subscribers
+type jsonEncoder struct {
+	w     io.Writer
+	count int
+}
+
+func (e *jsonEncoder) encode(s dto.SubscriberResponse) error {
+	sep := ",\n"
+	if e.count == 0 {
+		sep = "[\n"
+	}
+	e.count++
+	raw, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(e.w, sep); err != nil {
+		return err
+	}
+	_, err = e.w.Write(raw)
+	return err
+}
+
+func (e *jsonEncoder) close() error {
+	end := "\n]\n"
+	if e.count == 0 {
+		end = "[]\n"
+	}
+	_, err := io.WriteString(e.w, end)
+	return err
+}
+
+// Purge deletes the files and rows of exports that expired before now, and of failed exports
+// older than TTL, returning how many jobs it deleted
+func Purge(conn *gorm.DB, now time.Time) (int64, error) {
+	var expired []models.ExportJob
+	err := conn.Where("expires_at < ? OR (status = ? AND finished_at < ?)", now, models.ExportFailed, now.Add(-TTL())).
+		Find(&expired).Error
+	if err != nil || len(expired) == 0 {
+		return 0, err
+	}
+	ids := make([]uint, len(expired))
+	for i, job := range expired {
+		ids[i] = job.ID
+		if job.File != "" {
+			if err := storage.Default.Delete(context.Background(), job.File); err != nil {
+				return 0, err
+			}
+		}
+	}
+	res := conn.Where("id IN ?", ids).Delete(&models.ExportJob{})
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,162 @@
+package exports
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/models"
+	"fiber-gorm-api/internal/repository"
+	"fiber-gorm-api/internal/storage"
+	"fiber-gorm-api/internal/testutil/testenv"
+)
+
+func TestProcessPending(t *testing.T) {
+	testenv.LocalStorage(t)
+	conn := testenv.SQLite(t)
+	for _, s := range []models.Subscriber{
+		{OrgID: models.DefaultOrgID, Email: "ada@example.com", Name: "Ada", Status: models.SubscriberStatusActive,
+			SubscriberTypes: []models.SubscriberType{{Name: "donor", Frequency: "monthly", Channel: "email"}}},
+		{OrgID: models.DefaultOrgID, Email: "grace@example.com", Name: "Grace, Hopper", Status: models.SubscriberStatusActive},
+		{OrgID: models.DefaultOrgID, Email: "pending@example.com", Status: models.SubscriberStatusPending},
+		{OrgID: models.DefaultOrgID + 1, Email: "other-org@example.com", Status: models.SubscriberStatusActive},
+	} {
+		if err := conn.Create(&s).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	active := FilterMap(repository.SubscriberFilter{Statuses: []string{models.SubscriberStatusActive}})
+	csvJob := models.ExportJob{OrgID: models.DefaultOrgID, Format: models.ExportFormatCSV, Filters: active, Status: models.ExportPending}
+	jsonJob := models.ExportJob{OrgID: models.DefaultOrgID, Format: models.ExportFormatJSON, Status: models.ExportPending}
+	badJob := models.ExportJob{OrgID: models.DefaultOrgID, Format: models.ExportFormatCSV, Filters: models.JSONMap{"status": []string{"nope"}}, Status: models.ExportPending}
+	for _, job := range []*models.ExportJob{&csvJob, &jsonJob, &badJob} {
+		if err := conn.Create(job).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ran, err := ProcessPending(conn)
+	if err != nil || ran != 3 {
+		t.Fatalf("Expected 3 jobs run, got %d (%v)", ran, err)
+	}
+	if ran, _ := ProcessPending(conn); ran != 0 {
+		t.Errorf("Expected finished jobs to be left alone, ran %d", ran)
+	}
+
+	t.Run("CSV with filters", func(t *testing.T) {
+		conn.First(&csvJob, csvJob.ID)
+		if csvJob.Status != models.ExportDone || csvJob.RowCount != 2 || csvJob.ExpiresAt == nil {
+			t.Fatalf("Expected a done job of 2 rows, got %+v", csvJob)
+		}
+		f, err := storage.Default.Open(context.Background(), csvJob.File)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		records, err := csv.NewReader(f).ReadAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(records) != 3 || records[0][1] != "email" {
+			t.Fatalf("Expected a header and 2 rows, got %v", records)
+		}
+		if records[1][1] != "ada@example.com" || records[1][8] != "donor:monthly:email" {
+			t.Errorf("Expected Ada with her subscriber_types, got %v", records[1])
+		}
+		if records[2][2] != "Grace, Hopper" {
+			t.Errorf("Expected the comma in the name quoted, got %v", records[2])
+		}
+	})
+
+	t.Run("JSON of the organization only", func(t *testing.T) {
+		conn.First(&jsonJob, jsonJob.ID)
+		f, err := storage.Default.Open(context.Background(), jsonJob.File)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		raw, _ := io.ReadAll(f)
+		var subs []dto.SubscriberResponse
+		if err := json.Unmarshal(raw, &subs); err != nil {
+			t.Fatalf("Expected a JSON array, got %s (%v)", raw, err)
+		}
+		if len(subs) != 3 || jsonJob.RowCount != 3 {
+			t.Fatalf("Expected the 3 subscribers of the organization, got %d (row_count %d)", len(subs), jsonJob.RowCount)
+		}
+		for _, s := range subs {
+			if s.OrgID != models.DefaultOrgID {
+				t.Errorf("Expected no subscriber of another organization, got %s", s.Email)
+			}
+		}
+	})
+
+	t.Run("Invalid filters fail", func(t *testing.T) {
+		conn.First(&badJob, badJob.ID)
+		if badJob.Status != models.ExportFailed || badJob.Error == "" || badJob.File != "" {
+			t.Errorf("Expected a failed job with its error, got %+v", badJob)
+		}
+	})
+
+	t.Run("Purge", func(t *testing.T) {
+		if n, err := Purge(conn, time.Now()); err != nil || n != 0 {
+			t.Fatalf("Expected nothing expired yet, purged %d (%v)", n, err)
+		}
+		n, err := Purge(conn, time.Now().Add(TTL()+time.Minute))
+		if err != nil || n != 3 {
+			t.Fatalf("Expected the 3 jobs purged, got %d (%v)", n, err)
+		}
+		if _, err := storage.Default.Open(context.Background(), csvJob.File); !errors.Is(err, storage.ErrNotFound) {
+			t.Errorf("Expected the file deleted, got %v", err)
+		}
+	})
+}
+
+func TestDownloadURL(t *testing.T) {
+	t.Setenv("STORAGE_SIGNING_KEY", "test-key")
+	testenv.LocalStorage(t)
+
+	ctx := context.Background()
+	now := time.Now()
+	fileExpires := now.Add(24 * time.Hour)
+	job := &models.ExportJob{ID: 7, Format: models.ExportFormatCSV, File: "exports/7.csv", ExpiresAt: &fileExpires}
+
+	link, expires, err := DownloadURL(ctx, job, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := now.Add(storage.LinkTTL()).Truncate(time.Second); !expires.Equal(want) {
+		t.Errorf("Expected the link to expire at %s, got %s", want, expires)
+	}
+	u, err := url.Parse(link)
+	if err != nil || u.Path != "/admin/exports/7/download" {
+		t.Fatalf("Unexpected link %q (%v)", link, err)
+	}
+	q := u.Query()
+	if !storage.VerifyLink(job.File, q.Get("expires"), q.Get("signature"), now) {
+		t.Error("Expected the link to verify")
+	}
+	if storage.VerifyLink("exports/8.csv", q.Get("expires"), q.Get("signature"), now) {
+		t.Error("Expected the signature not to verify for another export")
+	}
+	later := strconv.FormatInt(now.Add(48*time.Hour).Unix(), 10)
+	if storage.VerifyLink(job.File, later, q.Get("signature"), now) {
+		t.Error("Expected an extended expiry not to verify")
+	}
+	if storage.VerifyLink(job.File, q.Get("expires"), q.Get("signature"), now.Add(2*time.Hour)) {
+		t.Error("Expected the link to expire")
+	}
+
+	// never valid past the file
+	fileExpires = now.Add(time.Minute)
+	if _, expires, _ := DownloadURL(ctx, job, now); expires.After(fileExpires) {
+		t.Errorf("Expected the link to expire with the file, got %s", expires)
+	}
+}
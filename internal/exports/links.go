@@ -0,0 +1,25 @@
+package exports
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"fiber-gorm-api/internal/models"
+	"fiber-gorm-api/internal/storage"
+)
+
+const defaultDownloadBaseURL = "http://localhost:3517/admin/exports/"
+
+// DownloadURL returns a signed link to the file of the finished job (see storage.DownloadURL),
+// valid until the returned time. EXPORT_DOWNLOAD_URL is the public base URL of /admin/exports/
+// for links served by the API.
+func DownloadURL(ctx context.Context, job *models.ExportJob, now time.Time) (string, time.Time, error) {
+	base := os.Getenv("EXPORT_DOWNLOAD_URL")
+	if base == "" {
+		base = defaultDownloadBaseURL
+	}
+	apiURL := fmt.Sprintf("%s%d/download", base, job.ID)
+	return storage.DownloadURL(ctx, storage.Default, job.File, FileName(job), apiURL, job.ExpiresAt, now)
+}
@@ -0,0 +1,299 @@
+// Package filterexpr parses the subscriber filter expressions of segments, search and exports,
+// e.g. `type:shopper AND created_at>2024-01-01 AND NOT tag:vip`, into a tree the repository
+// compiles to SQL. It knows nothing of the fields: the repository checks them and binds every
+// value as a query parameter, never splicing it into the SQL.
+//
+// The grammar, keywords being case-insensitive:
+//
+//	expr  = and { "OR" and }
+//	and   = unary { [ "AND" ] unary }
+//	unary = "NOT" unary | "(" expr ")" | cond
+//	cond  = field op value
+//	op    = ":" | "=" | "!=" | ">" | ">=" | "<" | "<="
+//
+// A field is made of letters, digits and underscores. A value runs up to the next space or
+// parenthesis, or is double-quoted with \" and \\ escapes.
+package filterexpr
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// MaxLength bounds the length of an expression, in characters
+const MaxLength = 1000
+
+// maxDepth bounds the nesting of NOTs and parentheses
+const maxDepth = 32
+
+// Error rejects an expression, pointing at the part at fault
+type Error struct {
+	Message string
+	Pos     int // offset of the part in characters, from 0
+	Len     int // its length in characters, 0 at the end of the expression
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s (at position %d)", e.Message, e.Pos)
+}
+
+// Node is a node of a parsed expression: an And, Or, Not or Cond
+type Node interface{ node() }
+
+// And matches what both Left and Right match
+type And struct{ Left, Right Node }
+
+// Or matches what either Left or Right matches
+type Or struct{ Left, Right Node }
+
+// Not matches what X doesn't
+type Not struct{ X Node }
+
+// Cond compares a field to a value, e.g. created_at>2024-01-01
+type Cond struct {
+	Field string // lowercased
+	Op    string
+	Value string // unquoted
+	// positions in characters: the condition starts with its field
+	Pos, End           int
+	ValuePos, ValueEnd int
+	fieldLen           int
+}
+
+func (*And) node()  {}
+func (*Or) node()   {}
+func (*Not) node()  {}
+func (*Cond) node() {}
+
+// Errorf rejects the whole of c
+func (c *Cond) Errorf(format string, args ...interface{}) *Error {
+	return &Error{Message: fmt.Sprintf(format, args...), Pos: c.Pos, Len: c.End - c.Pos}
+}
+
+// FieldErrorf rejects the field of c
+func (c *Cond) FieldErrorf(format string, args ...interface{}) *Error {
+	return &Error{Message: fmt.Sprintf(format, args...), Pos: c.Pos, Len: c.fieldLen}
+}
+
+// ValueErrorf rejects the value of c
+func (c *Cond) ValueErrorf(format string, args ...interface{}) *Error {
+	return &Error{Message: fmt.Sprintf(format, args...), Pos: c.ValuePos, Len: c.ValueEnd - c.ValuePos}
+}
+
+// Conds returns the conditions of n, left to right
+func Conds(n Node) []*Cond {
+	switch n := n.(type) {
+	case *And:
+		return append(Conds(n.Left), Conds(n.Right)...)
+	case *Or:
+		return append(Conds(n.Left), Conds(n.Right)...)
+	case *Not:
+		return Conds(n.X)
+	case *Cond:
+		return []*Cond{n}
+	}
+	return nil
+}
+
+// Parse parses expr. Errors are *Error.
+func Parse(expr string) (Node, error) {
+	p := &parser{src: []rune(expr)}
+	if len(p.src) > MaxLength {
+		return nil, &Error{Message: fmt.Sprintf("Expression longer than %d characters", MaxLength), Pos: MaxLength}
+	}
+	n, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if p.skipSpace(); p.pos < len(p.src) {
+		// or() only stops early at a closing parenthesis
+		return nil, p.errorf(1, "Unexpected )")
+	}
+	return n, nil
+}
+
+type parser struct {
+	src   []rune
+	pos   int
+	depth int
+}
+
+func (p *parser) errorf(length int, format string, args ...interface{}) *Error {
+	return &Error{Message: fmt.Sprintf(format, args...), Pos: p.pos, Len: length}
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.src) && unicode.IsSpace(p.src[p.pos]) {
+		p.pos++
+	}
+}
+
+func isFieldRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func isOpRune(r rune) bool {
+	return strings.ContainsRune(":=!<>", r)
+}
+
+// word returns the field name or keyword at the current position, without consuming it
+func (p *parser) word() string {
+	end := p.pos
+	for end < len(p.src) && isFieldRune(p.src[end]) {
+		end++
+	}
+	return string(p.src[p.pos:end])
+}
+
+// atKeyword reports whether the keyword kw is at the current position, and not the field of a
+// condition
+func (p *parser) atKeyword(kw string) bool {
+	p.skipSpace()
+	w := p.word()
+	if !strings.EqualFold(w, kw) {
+		return false
+	}
+	end := p.pos + len([]rune(w))
+	for end < len(p.src) && unicode.IsSpace(p.src[end]) {
+		end++
+	}
+	return end == len(p.src) || !isOpRune(p.src[end])
+}
+
+// keyword consumes the keyword kw if it's at the current position
+func (p *parser) keyword(kw string) bool {
+	if !p.atKeyword(kw) {
+		return false
+	}
+	p.pos += len([]rune(kw))
+	return true
+}
+
+func (p *parser) or() (Node, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("OR") {
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = &Or{Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) and() (Node, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		// conditions side by side are ANDed
+		if !p.keyword("AND") {
+			p.skipSpace()
+			if p.pos == len(p.src) || p.src[p.pos] == ')' || p.atKeyword("OR") {
+				return left, nil
+			}
+		}
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		left = &And{Left: left, Right: right}
+	}
+}
+
+func (p *parser) unary() (Node, error) {
+	p.skipSpace()
+	if p.depth++; p.depth > maxDepth {
+		return nil, p.errorf(1, "Expression nested more than %d levels deep", maxDepth)
+	}
+	defer func() { p.depth-- }()
+
+	if p.keyword("NOT") {
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &Not{X: x}, nil
+	}
+	p.skipSpace()
+	if p.pos == len(p.src) {
+		return nil, p.errorf(0, "Expected a condition")
+	}
+	switch p.src[p.pos] {
+	case '(':
+		open := p.pos
+		p.pos++
+		n, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if p.skipSpace(); p.pos == len(p.src) {
+			return nil, &Error{Message: "Unclosed parenthesis", Pos: open, Len: 1}
+		}
+		p.pos++ // or() only stops early at a closing parenthesis
+		return n, nil
+	case ')':
+		return nil, p.errorf(1, "Unexpected )")
+	}
+	return p.cond()
+}
+
+func (p *parser) cond() (Node, error) {
+	field := p.word()
+	if field == "" {
+		return nil, p.errorf(1, "Expected a field, e.g. status or tag")
+	}
+	c := &Cond{Field: strings.ToLower(field), Pos: p.pos, fieldLen: len([]rune(field))}
+	p.pos += c.fieldLen
+
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.src) && isOpRune(p.src[p.pos]) {
+		p.pos++
+	}
+	c.Op = string(p.src[start:p.pos])
+	switch c.Op {
+	case ":", "=", "!=", ">", ">=", "<", "<=":
+	case "":
+		return nil, p.errorf(0, "Expected an operator after %s, e.g. %s:value", c.Field, c.Field)
+	default:
+		return nil, &Error{Message: "Unknown operator " + c.Op, Pos: start, Len: p.pos - start}
+	}
+
+	p.skipSpace()
+	c.ValuePos = p.pos
+	if p.pos < len(p.src) && p.src[p.pos] == '"' {
+		var value strings.Builder
+		for p.pos++; ; p.pos++ {
+			if p.pos == len(p.src) {
+				return nil, &Error{Message: "Unterminated string", Pos: c.ValuePos, Len: p.pos - c.ValuePos}
+			}
+			r := p.src[p.pos]
+			if r == '"' {
+				break
+			}
+			if r == '\\' && p.pos+1 < len(p.src) {
+				p.pos++
+				r = p.src[p.pos]
+			}
+			value.WriteRune(r)
+		}
+		p.pos++
+		c.Value = value.String()
+	} else {
+		for p.pos < len(p.src) && !unicode.IsSpace(p.src[p.pos]) && p.src[p.pos] != '(' && p.src[p.pos] != ')' {
+			p.pos++
+		}
+		c.Value = string(p.src[c.ValuePos:p.pos])
+		if c.Value == "" {
+			return nil, p.errorf(0, "Expected a value after %s%s", c.Field, c.Op)
+		}
+	}
+	c.ValueEnd, c.End = p.pos, p.pos
+	return c, nil
+}
@@ -0,0 +1,91 @@
+package filterexpr
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// format writes n back with explicit parentheses
+func format(n Node) string {
+	switch n := n.(type) {
+	case *And:
+		return "(" + format(n.Left) + " AND " + format(n.Right) + ")"
+	case *Or:
+		return "(" + format(n.Left) + " OR " + format(n.Right) + ")"
+	case *Not:
+		return "NOT " + format(n.X)
+	case *Cond:
+		return fmt.Sprintf("%s%s%q", n.Field, n.Op, n.Value)
+	}
+	return "?"
+}
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		expr, want string
+	}{
+		{"type:shopper", `type:"shopper"`},
+		{"type:shopper AND created_at>2024-01-01 AND NOT tag:vip", `((type:"shopper" AND created_at>"2024-01-01") AND NOT tag:"vip")`},
+		{"a:1 OR b:2 AND c:3", `(a:"1" OR (b:"2" AND c:"3"))`},
+		{"(a:1 OR b:2) c:3", `((a:"1" OR b:"2") AND c:"3")`},
+		{"not (a:1 or b!=2)", `NOT (a:"1" OR b!="2")`},
+		{`name:"Ada (the first) \"L\"" Email = *@example.com`, `(name:"Ada (the first) \"L\"" AND email="*@example.com")`},
+		{"created_at>=2024-01-01T00:00:00Z", `created_at>="2024-01-01T00:00:00Z"`},
+		{"and:x", `and:"x"`},
+	}
+	for _, tc := range cases {
+		n, err := Parse(tc.expr)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tc.expr, err)
+			continue
+		}
+		if got := format(n); got != tc.want {
+			t.Errorf("%s: expected %s, got %s", tc.expr, tc.want, got)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	cases := []struct {
+		expr     string
+		pos, len int
+	}{
+		{"", 0, 0},
+		{"type:shopper AND", 16, 0},
+		{"type", 4, 0},
+		{"type:", 5, 0},
+		{"type=>x", 4, 2},
+		{"(type:a OR tag:b", 0, 1},
+		{"type:a)", 6, 1},
+		{`name:"Ada`, 5, 4},
+		{"type:a AND -tag:b", 11, 1},
+		{strings.Repeat("NOT ", maxDepth+1) + "a:1", 4 * maxDepth, 1},
+		{strings.Repeat("a", MaxLength+1), MaxLength, 0},
+	}
+	for _, tc := range cases {
+		_, err := Parse(tc.expr)
+		e, ok := err.(*Error)
+		if !ok {
+			t.Errorf("%q: expected an *Error, got %v", tc.expr, err)
+			continue
+		}
+		if e.Pos != tc.pos || e.Len != tc.len {
+			t.Errorf("%q: expected the error at %d+%d, got %d+%d (%s)", tc.expr, tc.pos, tc.len, e.Pos, e.Len, e.Message)
+		}
+	}
+}
+
+func TestCondErrors(t *testing.T) {
+	n, _ := Parse(`tag:vip AND  créé_le="2024"`)
+	conds := Conds(n)
+	if len(conds) != 2 {
+		t.Fatalf("expected 2 conditions, got %d", len(conds))
+	}
+	if e := conds[1].FieldErrorf("Unknown field"); e.Pos != 13 || e.Len != 7 {
+		t.Errorf("expected the field at 13+7, got %d+%d", e.Pos, e.Len)
+	}
+	if e := conds[1].ValueErrorf("Invalid"); e.Pos != 21 || e.Len != 6 {
+		t.Errorf("expected the value at 21+6, got %d+%d", e.Pos, e.Len)
+	}
+}
@@ -0,0 +1,103 @@
+// Package geoip locates IP addresses with a MaxMind DB file (GeoLite2 or GeoIP2, Country or
+// City) at GEOIP_DB_PATH. It's optional: without the file every address is of unknown location.
+package geoip
+
+import (
+	"context"
+	"log"
+	"net"
+	"os"
+	"sync"
+)
+
+// Location is where an IP address is, fields are empty when unknown
+type Location struct {
+	// Country is the ISO 3166-1 alpha-2 code of the country, e.g. "NZ"
+	Country string `json:"country,omitempty"`
+	// Region is the ISO 3166-2 code of the subdivision within the country, e.g. "AUK" (City DBs
+	// only)
+	Region string `json:"region,omitempty"`
+}
+
+var (
+	mu sync.Mutex
+	// path is the file db was opened from, db is nil when it couldn't be
+	path string
+	db   *reader
+)
+
+// current returns the reader of GEOIP_DB_PATH, opened on first use, nil when unset or unreadable
+func current() *reader {
+	wanted := os.Getenv("GEOIP_DB_PATH")
+	if wanted == "" {
+		return nil
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if wanted != path {
+		path = wanted
+		var err error
+		if db, err = open(wanted); err != nil {
+			log.Printf("[WARN] GeoIP disabled, could not open %s: %v", wanted, err)
+		}
+	}
+	return db
+}
+
+// Enabled reports whether a MaxMind DB is configured and readable
+func Enabled() bool {
+	return current() != nil
+}
+
+// Lookup returns the location of ip, empty when GeoIP isn't enabled, ip isn't an address or the
+// DB doesn't know it
+func Lookup(ip string) Location {
+	r := current()
+	parsed := net.ParseIP(ip)
+	if r == nil || parsed == nil {
+		return Location{}
+	}
+	record, err := r.lookup(parsed)
+	if err != nil {
+		log.Printf("[WARN] GeoIP lookup of %s failed: %v", ip, err)
+		return Location{}
+	}
+	// the country of the address, else that it's registered in (e.g. anycast networks)
+	country := isoCode(record, "country")
+	if country == "" {
+		country = isoCode(record, "registered_country")
+	}
+	location := Location{Country: country}
+	// the largest subdivision comes first
+	m, _ := record.(map[string]interface{})
+	if subdivisions, _ := m["subdivisions"].([]interface{}); len(subdivisions) > 0 {
+		first, _ := subdivisions[0].(map[string]interface{})
+		location.Region, _ = first["iso_code"].(string)
+	}
+	return location
+}
+
+type ipContextKey struct{}
+
+// WithIP attaches the IP address of the client a request comes from to ctx, for the records
+// written while handling it to be located with FromContext
+func WithIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, ipContextKey{}, ip)
+}
+
+// FromContext returns the location of the IP address WithIP attached to ctx, empty if none
+func FromContext(ctx context.Context) Location {
+	ip, _ := ctx.Value(ipContextKey{}).(string)
+	if ip == "" {
+		return Location{}
+	}
+	return Lookup(ip)
+}
+
+// isoCode returns record[field]["iso_code"], "" when missing
+func isoCode(record interface{}, field string) string {
+	m, _ := record.(map[string]interface{})
+	sub, _ := m[field].(map[string]interface{})
+	code, _ := sub["iso_code"].(string)
+	return code
+}
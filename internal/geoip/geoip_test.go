@@ -0,0 +1,136 @@
+package geoip
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Encoders of the few MaxMind DB types test files need, for sizes under 29
+func encString(s string) []byte {
+	return append([]byte{typeString<<5 | byte(len(s))}, s...)
+}
+
+func encUint(kind byte, v uint32) []byte {
+	return []byte{kind<<5 | 4, byte(v >> 24), byte(v >> 16), byte(v >> 8), byte(v)}
+}
+
+func encMap(pairs ...[]byte) []byte {
+	out := []byte{typeMap<<5 | byte(len(pairs)/2)}
+	for _, p := range pairs {
+		out = append(out, p...)
+	}
+	return out
+}
+
+// encArray encodes an array, an extended type: its type follows the control byte, less 7
+func encArray(items ...[]byte) []byte {
+	out := []byte{byte(len(items)), typeArray - 7}
+	for _, item := range items {
+		out = append(out, item...)
+	}
+	return out
+}
+
+// writeDB writes a DB of the given IP version with 24-bit records, holding data for the network
+// of the first bits of prefix, and returns its path
+func writeDB(t *testing.T, ipVersion uint32, prefix net.IP, bits int, data []byte) string {
+	t.Helper()
+	nodeCount := uint32(bits)
+	record := func(v uint32) []byte { return []byte{byte(v >> 16), byte(v >> 8), byte(v)} }
+
+	var file []byte
+	for i := 0; i < bits; i++ {
+		next := uint32(i + 1)
+		if i == bits-1 {
+			next = nodeCount + 16 // the data, first in the data section
+		}
+		left, right := record(next), record(nodeCount)
+		if prefix[i/8]>>(7-uint(i%8))&1 == 1 {
+			left, right = right, left
+		}
+		file = append(append(file, left...), right...)
+	}
+	file = append(file, make([]byte, 16)...)
+	file = append(file, data...)
+	file = append(file, metadataMarker...)
+	file = append(file, encMap(
+		encString("node_count"), encUint(typeUint32, nodeCount),
+		encString("record_size"), encUint(typeUint32, 24),
+		encString("ip_version"), encUint(typeUint32, ipVersion),
+	)...)
+
+	path := filepath.Join(t.TempDir(), "geo.mmdb")
+	if err := os.WriteFile(path, file, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLookup(t *testing.T) {
+	nz := encMap(
+		encString("country"), encMap(encString("iso_code"), encString("NZ")),
+		encString("subdivisions"), encArray(encMap(encString("iso_code"), encString("AUK"))),
+	)
+
+	t.Run("Disabled", func(t *testing.T) {
+		t.Setenv("GEOIP_DB_PATH", "")
+		if Enabled() || Lookup("203.0.113.7") != (Location{}) {
+			t.Error("Expected no location without GEOIP_DB_PATH")
+		}
+	})
+
+	t.Run("Unreadable file", func(t *testing.T) {
+		t.Setenv("GEOIP_DB_PATH", filepath.Join(t.TempDir(), "missing.mmdb"))
+		if Enabled() {
+			t.Error("Expected GeoIP disabled when the file can't be opened")
+		}
+	})
+
+	t.Run("IPv4 DB", func(t *testing.T) {
+		t.Setenv("GEOIP_DB_PATH", writeDB(t, 4, net.ParseIP("203.0.113.0").To4(), 24, nz))
+		if got := Lookup("203.0.113.7"); got != (Location{Country: "NZ", Region: "AUK"}) {
+			t.Errorf("Expected NZ-AUK, got %+v", got)
+		}
+		if got := FromContext(WithIP(context.Background(), "203.0.113.7")); got.Country != "NZ" {
+			t.Errorf("Expected the IP of the context located, got %+v", got)
+		}
+		if got := FromContext(context.Background()); got != (Location{}) {
+			t.Errorf("Expected no location without an IP, got %+v", got)
+		}
+		for _, ip := range []string{"198.51.100.1", "2001:db8::1", "not an ip"} {
+			if got := Lookup(ip); got != (Location{}) {
+				t.Errorf("Expected %s unknown, got %+v", ip, got)
+			}
+		}
+	})
+
+	t.Run("IPv6 DB", func(t *testing.T) {
+		// IPv4 addresses live under ::/96 of IPv6 trees
+		prefix := make(net.IP, net.IPv6len)
+		copy(prefix[12:], net.ParseIP("203.0.113.0").To4())
+		t.Setenv("GEOIP_DB_PATH", writeDB(t, 6, prefix, 120, nz))
+		if got := Lookup("203.0.113.7"); got.Country != "NZ" {
+			t.Errorf("Expected NZ, got %+v", got)
+		}
+		if got := Lookup("2001:db8::1"); got != (Location{}) {
+			t.Errorf("Expected an unknown address, got %+v", got)
+		}
+	})
+
+	t.Run("Pointers", func(t *testing.T) {
+		// registered_country, pointing to the map of country
+		country := encMap(encString("iso_code"), encString("FR"))
+		data := append(country, encMap(encString("registered_country"), []byte{typePointer << 5, 0})...)
+		r := &reader{data: decoder(data)}
+		value, _, err := r.data.decode(len(country), 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if code := isoCode(value, "registered_country"); code != "FR" {
+			t.Errorf("Expected FR through the pointer, got %q", code)
+		}
+	})
+}
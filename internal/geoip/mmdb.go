@@ -0,0 +1,256 @@
+package geoip
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"os"
+)
+
+// A reader of MaxMind DB files (https://maxmind.github.io/MaxMind-DB/), the format of GeoLite2
+// and GeoIP2: a binary search tree over the bits of addresses whose leaves point into a data
+// section of typed values, followed by metadata. Only what lookups need is implemented.
+
+var metadataMarker = []byte("\xab\xcd\xefMaxMind.com")
+
+// maxDepth bounds the nesting of decoded values, so a corrupt file can't recurse forever
+const maxDepth = 32
+
+var errCorrupt = errors.New("geoip: corrupt MaxMind DB")
+
+type reader struct {
+	tree       []byte
+	data       decoder
+	nodeCount  uint
+	recordSize uint
+	ipVersion  uint
+	// ipv4Start is the node IPv4 addresses start from in an IPv6 tree, under ::/96
+	ipv4Start uint
+}
+
+// open reads the MaxMind DB at path
+func open(path string) (*reader, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	at := bytes.LastIndex(buf, metadataMarker)
+	if at < 0 {
+		return nil, fmt.Errorf("geoip: %s isn't a MaxMind DB", path)
+	}
+	raw, _, err := decoder(buf[at+len(metadataMarker):]).decode(0, 0)
+	if err != nil {
+		return nil, err
+	}
+	metadata, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, errCorrupt
+	}
+
+	r := &reader{
+		nodeCount:  uintOf(metadata["node_count"]),
+		recordSize: uintOf(metadata["record_size"]),
+		ipVersion:  uintOf(metadata["ip_version"]),
+	}
+	if r.recordSize != 24 && r.recordSize != 28 && r.recordSize != 32 {
+		return nil, fmt.Errorf("geoip: unsupported record size %d", r.recordSize)
+	}
+	treeSize := int(r.nodeCount * r.recordSize / 4)
+	// the tree and the data section are separated by 16 zero bytes
+	if treeSize+16 > at {
+		return nil, errCorrupt
+	}
+	r.tree = buf[:treeSize]
+	r.data = decoder(buf[treeSize+16 : at])
+
+	if r.ipVersion == 6 {
+		for i := 0; i < 96 && r.ipv4Start < r.nodeCount; i++ {
+			r.ipv4Start = r.record(r.ipv4Start, 0)
+		}
+	}
+	return r, nil
+}
+
+// lookup returns the data of the network holding ip, nil when the DB has none
+func (r *reader) lookup(ip net.IP) (interface{}, error) {
+	node, bits := uint(0), 128
+	if v4 := ip.To4(); v4 != nil {
+		ip, node, bits = v4, r.ipv4Start, 32
+	} else if r.ipVersion == 4 || len(ip) != net.IPv6len {
+		return nil, nil
+	}
+
+	for i := 0; i < bits && node < r.nodeCount; i++ {
+		bit := uint(ip[i/8]>>(7-uint(i%8))) & 1
+		node = r.record(node, bit)
+	}
+	if node <= r.nodeCount {
+		return nil, nil
+	}
+	value, _, err := r.data.decode(int(node-r.nodeCount-16), 0)
+	return value, err
+}
+
+// record returns the left (bit 0) or right (bit 1) record of node
+func (r *reader) record(node, bit uint) uint {
+	switch r.recordSize {
+	case 24:
+		b := r.tree[node*6+bit*3:]
+		return uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])
+	case 28:
+		b := r.tree[node*7:]
+		if bit == 0 {
+			return uint(b[3]>>4)<<24 | uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])
+		}
+		return uint(b[3]&0x0f)<<24 | uint(b[4])<<16 | uint(b[5])<<8 | uint(b[6])
+	default:
+		return uint(binary.BigEndian.Uint32(r.tree[node*8+bit*4:]))
+	}
+}
+
+// decoder decodes the values of a data section, which pointers are relative to
+type decoder []byte
+
+// Data types of the format
+const (
+	typePointer = 1
+	typeString  = 2
+	typeDouble  = 3
+	typeBytes   = 4
+	typeUint16  = 5
+	typeUint32  = 6
+	typeMap     = 7
+	typeInt32   = 8
+	typeUint64  = 9
+	typeUint128 = 10
+	typeArray   = 11
+	typeBool    = 14
+	typeFloat   = 15
+)
+
+// decode returns the value at offset and the offset following it. Maps are
+// map[string]interface{}, arrays []interface{}, unsigned integers uint64 (uint128 its bytes).
+func (d decoder) decode(offset, depth int) (interface{}, int, error) {
+	if depth > maxDepth || offset < 0 || offset >= len(d) {
+		return nil, 0, errCorrupt
+	}
+	ctrl := d[offset]
+	offset++
+	kind := int(ctrl >> 5)
+	if kind == typePointer {
+		return d.decodePointer(ctrl, offset, depth)
+	}
+	if kind == 0 {
+		if offset >= len(d) {
+			return nil, 0, errCorrupt
+		}
+		kind = 7 + int(d[offset])
+		offset++
+	}
+
+	size := int(ctrl & 0x1f)
+	if size >= 29 {
+		n := size - 28
+		if offset+n > len(d) {
+			return nil, 0, errCorrupt
+		}
+		extra := 0
+		for _, b := range d[offset : offset+n] {
+			extra = extra<<8 | int(b)
+		}
+		size = [...]int{29, 285, 65821}[n-1] + extra
+		offset += n
+	}
+
+	switch kind {
+	case typeMap:
+		m := make(map[string]interface{}, size)
+		for i := 0; i < size; i++ {
+			key, next, err := d.decode(offset, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			name, ok := key.(string)
+			if !ok {
+				return nil, 0, errCorrupt
+			}
+			if m[name], offset, err = d.decode(next, depth+1); err != nil {
+				return nil, 0, err
+			}
+		}
+		return m, offset, nil
+	case typeArray:
+		a := make([]interface{}, size)
+		for i := range a {
+			var err error
+			if a[i], offset, err = d.decode(offset, depth+1); err != nil {
+				return nil, 0, err
+			}
+		}
+		return a, offset, nil
+	case typeBool:
+		return size != 0, offset, nil
+	}
+
+	if offset+size > len(d) {
+		return nil, 0, errCorrupt
+	}
+	payload, next := d[offset:offset+size], offset+size
+	switch kind {
+	case typeString:
+		return string(payload), next, nil
+	case typeBytes, typeUint128:
+		return append([]byte(nil), payload...), next, nil
+	case typeUint16, typeUint32, typeUint64:
+		var v uint64
+		for _, b := range payload {
+			v = v<<8 | uint64(b)
+		}
+		return v, next, nil
+	case typeInt32:
+		var v uint32
+		for _, b := range payload {
+			v = v<<8 | uint32(b)
+		}
+		return int64(int32(v)), next, nil
+	case typeDouble:
+		if size != 8 {
+			return nil, 0, errCorrupt
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(payload)), next, nil
+	case typeFloat:
+		if size != 4 {
+			return nil, 0, errCorrupt
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(payload))), next, nil
+	default:
+		return nil, 0, fmt.Errorf("geoip: unsupported data type %d", kind)
+	}
+}
+
+// decodePointer returns the value a pointer refers to and the offset following the pointer
+func (d decoder) decodePointer(ctrl byte, offset, depth int) (interface{}, int, error) {
+	n := int(ctrl>>3)&3 + 1
+	if offset+n > len(d) {
+		return nil, 0, errCorrupt
+	}
+	target := 0
+	if n < 4 {
+		target = int(ctrl & 0x07)
+	}
+	for _, b := range d[offset : offset+n] {
+		target = target<<8 | int(b)
+	}
+	target += [...]int{0, 2048, 526336, 0}[n-1]
+	value, _, err := d.decode(target, depth+1)
+	return value, offset + n, err
+}
+
+// uintOf is v decoded as an unsigned integer, 0 otherwise
+func uintOf(v interface{}) uint {
+	n, _ := v.(uint64)
+	return uint(n)
+}
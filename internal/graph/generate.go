@@ -0,0 +1,5 @@
+// Package graph holds the gqlgen executable schema of the admin GraphQL endpoint.
+// The resolvers live in package handlers next to the REST handlers they share helpers with.
+package graph
+
+//go:generate go run github.com/99designs/gqlgen@v0.17.55 generate --config ../../gqlgen.yml
@@ -0,0 +1,64 @@
+package grpcserver
+
+import (
+	"log"
+	"net"
+	"os"
+
+	"fiber-gorm-api/internal/db"
+	"fiber-gorm-api/internal/handlers"
+	"fiber-gorm-api/internal/middleware"
+	redisclient "fiber-gorm-api/internal/redis"
+	mylopb "fiber-gorm-api/proto"
+
+	"google.golang.org/grpc"
+	"gorm.io/gorm"
+)
+
+const defaultPort = "50051"
+
+// publicMethods are reachable without credentials, like /signin
+var publicMethods = map[string]bool{
+	mylopb.AuthService_RequestSignIn_FullMethodName: true,
+	mylopb.AuthService_VerifySignIn_FullMethodName:  true,
+}
+
+// readOnlyMethods need the read scope, every other authenticated method the write scope
+var readOnlyMethods = map[string]bool{
+	mylopb.SubscriberService_GetSubscriber_FullMethodName:   true,
+	mylopb.SubscriberService_ListSubscribers_FullMethodName: true,
+}
+
+// NewServer returns a gRPC server exposing the subscriber and auth services backed by database
+func NewServer(database *gorm.DB) *grpc.Server {
+	server := grpc.NewServer(grpc.UnaryInterceptor(middleware.GRPCAuth(database, publicMethods, readOnlyMethods)))
+	mylopb.RegisterSubscriberServiceServer(server, handlers.NewSubscriberGRPC(database))
+	signin := handlers.NewSigninHandler(database, redisclient.Current,
+		handlers.NewQueuedEmailSender(database), handlers.NewSessionTokenIssuer(database), handlers.SystemClock)
+	mylopb.RegisterAuthServiceServer(server, handlers.NewAuthGRPC(signin))
+	return server
+}
+
+// Start serves gRPC on GRPC_PORT (default 50051) in the background; "off" disables it
+func Start() {
+	port := os.Getenv("GRPC_PORT")
+	if port == "" {
+		port = defaultPort
+	}
+	if port == "off" {
+		log.Printf("gRPC server disabled")
+		return
+	}
+
+	lis, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		log.Fatalf("gRPC: could not listen on :%s: %v", port, err)
+	}
+	server := NewServer(db.Connect(true))
+	go func() {
+		log.Printf("Starting gRPC server on :%s", port)
+		if err := server.Serve(lis); err != nil {
+			log.Fatalf("gRPC: %v", err)
+		}
+	}()
+}
@@ -0,0 +1,50 @@
+package grpcserver
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	mylopb "fiber-gorm-api/proto"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// dial starts a server on an in-memory listener. Neither case below reaches the database.
+func dial(t *testing.T) *grpc.ClientConn {
+	lis := bufconn.Listen(1 << 20)
+	server := NewServer(nil)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestGRPCServer(t *testing.T) {
+	conn := dial(t)
+
+	t.Run("Subscriber calls without credentials are rejected", func(t *testing.T) {
+		_, err := mylopb.NewSubscriberServiceClient(conn).GetSubscriber(context.Background(), &mylopb.GetSubscriberRequest{Id: 1})
+		if status.Code(err) != codes.Unauthenticated {
+			t.Errorf("Expected Unauthenticated, got %v", err)
+		}
+	})
+
+	t.Run("RequestSignIn is public but needs an email", func(t *testing.T) {
+		_, err := mylopb.NewAuthServiceClient(conn).RequestSignIn(context.Background(), &mylopb.RequestSignInRequest{})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Expected InvalidArgument, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
+	"fiber-gorm-api/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// callerIdentity names whoever is making the request, for created_by style columns
+func callerIdentity(c *fiber.Ctx) string {
+	return middleware.CurrentIdentity(c)
+}
+
+// CreateApiKey godoc
+// @Summary      Create an API key
+// @Description  Creates a key for machine-to-machine access to /admin via the X-API-Key header. Keys without the pii (or admin) scope see masked emails. The key is only returned once.
+// @Tags         api-keys
+// @Accept       json
+// @Produce      json
+// @Param        body  body      dto.CreateApiKeyRequest  true  "Name, scopes (read, write, pii, admin) and optional expiry"
+// @Success      201   {object}  dto.CreatedApiKeyResponse
+// @Failure      400   {object}  dto.ErrorResponse
+// @Failure      403   {object}  dto.ErrorResponse
+// @Failure      500   {object}  dto.ErrorResponse
+// @Router       /admin/api-keys [post]
+func CreateApiKey(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		var req dto.CreateApiKeyRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Unable to parse request body"})
+		}
+		if strings.TrimSpace(req.Name) == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing name"})
+		}
+		if err := service.ValidateAPIKeyScopes(req.Scopes); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "expires_at must be in the future"})
+		}
+
+		key := models.ApiKey{
+			OrgID:     middleware.CurrentOrgID(c),
+			Name:      req.Name,
+			Scopes:    strings.Join(req.Scopes, ","),
+			CreatedBy: callerIdentity(c),
+			ExpiresAt: req.ExpiresAt,
+		}
+		plaintext, err := service.IssueAPIKey(c.UserContext(), db, &key)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not create API key"})
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(dto.CreatedApiKeyResponse{
+			ApiKeyResponse: dto.NewApiKeyResponse(key),
+			Key:            plaintext,
+		})
+	}
+}
+
+// GetAllApiKeys godoc
+// @Summary      List API keys
+// @Description  Lists all API keys, including revoked and expired ones. Secrets are never returned.
+// @Tags         api-keys
+// @Produce      json
+// @Success      200  {array}   dto.ApiKeyResponse
+// @Failure      403  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/api-keys [get]
+func GetAllApiKeys(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		var keys []models.ApiKey
+		if err := db.Scopes(orgScope(c)).Order("id DESC").Find(&keys).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve API keys"})
+		}
+
+		out := make([]dto.ApiKeyResponse, len(keys))
+		for i, k := range keys {
+			out[i] = dto.NewApiKeyResponse(k)
+		}
+		return c.JSON(out)
+	}
+}
+
+// RevokeApiKey godoc
+// @Summary      Revoke an API key
+// @Description  Revokes an API key immediately. The record is kept for auditing.
+// @Tags         api-keys
+// @Param        id   path      int true "API key ID"
+// @Success      204  {string}  string
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      403  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/api-keys/{id} [delete]
+func RevokeApiKey(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		id, err := strconv.Atoi(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid API key ID"})
+		}
+
+		var key models.ApiKey
+		if err := db.Scopes(orgScope(c)).First(&key, id).Error; err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "API key not found"})
+		}
+		if key.RevokedAt == nil {
+			now := time.Now()
+			if err := db.Model(&key).Update("revoked_at", now).Error; err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not revoke API key"})
+			}
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	}
+}
@@ -0,0 +1,448 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"fiber-gorm-api/internal/campaigns"
+	database "fiber-gorm-api/internal/db"
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
+	"fiber-gorm-api/internal/service"
+	"fiber-gorm-api/internal/shortlinks"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+var errSegmentAndFilter = errors.New("pass either segment_id or filter")
+
+// editableCampaignStatuses are those a campaign can be updated in
+var editableCampaignStatuses = []string{models.CampaignDraft, models.CampaignScheduled, models.CampaignPaused}
+
+// parseCampaign reads and validates a create / update body
+func parseCampaign(c *fiber.Ctx, db *gorm.DB) (models.Campaign, error) {
+	var req dto.CampaignRequest
+	if err := c.BodyParser(&req); err != nil {
+		return models.Campaign{}, errUnparsableSegment
+	}
+	campaign := req.ToModel()
+	if err := service.ValidateCampaign(&campaign, req.SendAt, time.Now()); err != nil {
+		return campaign, err
+	}
+	filter, err := parseSegmentFilter(c, db, req.Filter)
+	if err != nil {
+		return campaign, err
+	}
+	campaign.Filter = filter.Map()
+	if campaign.SegmentID != nil {
+		if len(campaign.Filter) > 0 {
+			return campaign, errSegmentAndFilter
+		}
+		if _, _, err := segmentFilter(c, db, *campaign.SegmentID); err != nil {
+			return campaign, err
+		}
+	}
+	if campaign.EventID != nil {
+		var count int64
+		if err := db.Model(&models.Event{}).Scopes(orgScope(c)).Where("id = ?", *campaign.EventID).Count(&count).Error; err != nil {
+			return campaign, err
+		}
+		if count == 0 {
+			return campaign, errUnknownEvent
+		}
+	}
+	return campaign, nil
+}
+
+// campaignInvalid writes the error response of a body parseCampaign rejected
+func campaignInvalid(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, errSegmentAndFilter):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Pass either segment_id or filter"})
+	case errors.Is(err, errUnknownSegment):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Unknown segment", "code": "unknown_segment"})
+	case errors.Is(err, errUnknownEvent):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Unknown event", "code": "unknown_event"})
+	}
+	return segmentInvalid(c, err)
+}
+
+// loadAdminCampaign reads the campaign of the caller's organization named by the id param,
+// returning the status and message of the error response when it can't
+func loadAdminCampaign(c *fiber.Ctx, db *gorm.DB) (models.Campaign, int, string) {
+	var campaign models.Campaign
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return campaign, fiber.StatusBadRequest, "Invalid campaign ID"
+	}
+	if err := db.Scopes(orgScope(c)).First(&campaign, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return campaign, fiber.StatusNotFound, "Campaign not found"
+		}
+		return campaign, fiber.StatusInternalServerError, "Could not retrieve campaign"
+	}
+	return campaign, 0, ""
+}
+
+// campaignStatusConflict writes the 409 of a campaign that can't go through a change in its
+// current status
+func campaignStatusConflict(c *fiber.Ctx, status string) error {
+	return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+		"error": "Not possible while the campaign is " + status,
+		"code":  "invalid_campaign_status",
+	})
+}
+
+// CreateCampaign godoc
+// @Summary      Create a campaign
+// @Description  Creates an email campaign to the active members of segment_id (see /admin/segments), or of filter without one; an empty filter targets every active subscriber. Members are computed when each run starts.
+// @Description  send_at schedules it once, as an RFC 3339 time or a local 2025-07-01T09:00 read in timezone (an IANA name, UTC by default). recurrence, a cron expression read in timezone like 0 9 * * MON (or @weekly), sends it again at each occurrence, from send_at or else the next one; runs must be at least an hour apart.
+// @Description  Without either the campaign is a draft, until updated with one. Invalid schedules are rejected with code invalid_schedule.
+// @Description  subject_b and test_percent (2 to 50) A/B test the subject of each run: that share of the audience gets subject or subject_b, then test_hours later (4 by default, 72 at most) the one opened at the highest rate goes to the rest. Invalid tests are rejected with code invalid_ab_test; results are at /admin/campaigns/{id}/stats.
+// @Description  event_id announces a local event of the organization (see /admin/local-events, code unknown_event): each email carries its calendar invite, as of when it's sent.
+// @Description  The scheduler's dispatcher queues the emails of due runs; a Redis lock per campaign and a record of each recipient of a run keep anyone from being sent a run twice.
+// @Tags         campaigns
+// @Accept       json
+// @Produce      json
+// @Param        campaign  body      dto.CampaignRequest  true  "Campaign"
+// @Success      201       {object}  dto.CampaignResponse
+// @Failure      400       {object}  dto.FilterErrorResponse
+// @Failure      422       {object}  dto.InvalidSubscriberTypesResponse
+// @Failure      500       {object}  dto.ErrorResponse
+// @Router       /admin/campaigns [post]
+func CreateCampaign(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		campaign, err := parseCampaign(c, db)
+		if err != nil {
+			return campaignInvalid(c, err)
+		}
+
+		campaign.OrgID = middleware.CurrentOrgID(c)
+		campaign.CreatedBy = callerIdentity(c)
+		campaign.Status = models.CampaignDraft
+		if campaign.NextRunAt != nil {
+			campaign.Status = models.CampaignScheduled
+		}
+		if err := db.Create(&campaign).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not create campaign"})
+		}
+		c.Location("/admin/campaigns/" + strconv.FormatUint(uint64(campaign.ID), 10))
+		return c.Status(fiber.StatusCreated).JSON(dto.NewCampaignResponse(campaign))
+	}
+}
+
+// GetCampaigns godoc
+// @Summary      List campaigns
+// @Description  Lists the campaigns of the organization, newest first, optionally those in one status.
+// @Tags         campaigns
+// @Produce      json
+// @Param        status  query     string  false  "draft, scheduled, sending, testing, paused, sent or cancelled"
+// @Success      200     {array}   dto.CampaignResponse
+// @Failure      500     {object}  dto.ErrorResponse
+// @Router       /admin/campaigns [get]
+func GetCampaigns(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		query := db.WithContext(c.UserContext()).Scopes(orgScope(c))
+		if status := c.Query("status"); status != "" {
+			query = query.Where("status = ?", status)
+		}
+		var list []models.Campaign
+		if err := query.Order("id DESC").Find(&list).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve campaigns"})
+		}
+		resp := make([]dto.CampaignResponse, len(list))
+		for i, campaign := range list {
+			resp[i] = dto.NewCampaignResponse(campaign)
+		}
+		return c.JSON(resp)
+	}
+}
+
+// GetCampaign godoc
+// @Summary      Get a campaign
+// @Tags         campaigns
+// @Produce      json
+// @Param        id   path      int  true  "Campaign ID"
+// @Success      200  {object}  dto.CampaignResponse
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/campaigns/{id} [get]
+func GetCampaign(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		campaign, status, msg := loadAdminCampaign(c, db.WithContext(c.UserContext()))
+		if status != 0 {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+		return c.JSON(dto.NewCampaignResponse(campaign))
+	}
+}
+
+// UpdateCampaign godoc
+// @Summary      Update a campaign
+// @Description  Replaces the content, audience and schedule of a draft, scheduled or paused campaign, validated as on create (409 with code invalid_campaign_status otherwise). A paused campaign stays paused; the others are scheduled, or drafts without a schedule.
+// @Tags         campaigns
+// @Accept       json
+// @Produce      json
+// @Param        id        path      int                  true  "Campaign ID"
+// @Param        campaign  body      dto.CampaignRequest  true  "Campaign"
+// @Success      200       {object}  dto.CampaignResponse
+// @Failure      400       {object}  dto.FilterErrorResponse
+// @Failure      404       {object}  dto.ErrorResponse
+// @Failure      409       {object}  dto.ErrorResponse
+// @Failure      422       {object}  dto.InvalidSubscriberTypesResponse
+// @Failure      500       {object}  dto.ErrorResponse
+// @Router       /admin/campaigns/{id} [put]
+func UpdateCampaign(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		current, status, msg := loadAdminCampaign(c, db)
+		if status != 0 {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+		campaign, err := parseCampaign(c, db)
+		if err != nil {
+			return campaignInvalid(c, err)
+		}
+
+		campaign.Status = models.CampaignDraft
+		switch {
+		case current.Status == models.CampaignPaused:
+			campaign.Status = models.CampaignPaused
+		case campaign.NextRunAt != nil:
+			campaign.Status = models.CampaignScheduled
+		}
+		// the status check makes a campaign the dispatcher started meanwhile look not editable
+		res := db.Model(&models.Campaign{}).
+			Where("id = ? AND status IN ?", current.ID, editableCampaignStatuses).
+			Select("name", "subject", "subject_b", "test_percent", "test_hours", "text", "html", "segment_id", "filter", "event_id",
+				"status", "timezone", "recurrence", "next_run_at", "updated_at").
+			Updates(&campaign)
+		if res.Error != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not update campaign"})
+		}
+		if res.RowsAffected == 0 {
+			return campaignStatusConflict(c, current.Status)
+		}
+		updated, _, _ := loadAdminCampaign(c, db)
+		return c.JSON(dto.NewCampaignResponse(updated))
+	}
+}
+
+// DeleteCampaign godoc
+// @Summary      Delete a campaign
+// @Description  Deletes a campaign, its record of recipients and their opens and clicks, unless it is sending: pause or cancel it first (409). Emails of it still queued are dropped. Its short links keep working, no longer attributed to it.
+// @Tags         campaigns
+// @Param        id   path  int  true  "Campaign ID"
+// @Success      204  {string}  string
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      409  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/campaigns/{id} [delete]
+func DeleteCampaign(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		campaign, status, msg := loadAdminCampaign(c, db)
+		if status != 0 {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+		deleted := false
+		err := db.Transaction(func(tx *gorm.DB) error {
+			// the status check keeps a run the dispatcher started meanwhile going
+			res := tx.Where("status <> ?", models.CampaignSending).Delete(&campaign)
+			if res.Error != nil || res.RowsAffected == 0 {
+				return res.Error
+			}
+			deleted = true
+			for _, model := range []interface{}{&models.CampaignEvent{}, &models.CampaignLink{}, &models.CampaignSend{}} {
+				if err := tx.Where("campaign_id = ?", campaign.ID).Delete(model).Error; err != nil {
+					return err
+				}
+			}
+			// its short links keep working, no longer attributed
+			return tx.Model(&models.ShortLink{}).Where("campaign_id = ?", campaign.ID).Update("campaign_id", nil).Error
+		})
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not delete campaign"})
+		}
+		if !deleted {
+			return campaignStatusConflict(c, models.CampaignSending)
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	}
+}
+
+// campaignTransition is the handler of an endpoint moving a campaign to another status, apply
+// reporting false when its current status doesn't allow it
+func campaignTransition(db *gorm.DB, apply func(db *gorm.DB, campaign *models.Campaign) (bool, error)) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		campaign, status, msg := loadAdminCampaign(c, db)
+		if status != 0 {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+		ok, err := apply(db, &campaign)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not update campaign"})
+		}
+		if !ok {
+			return campaignStatusConflict(c, campaign.Status)
+		}
+		updated, _, _ := loadAdminCampaign(c, db)
+		return c.JSON(dto.NewCampaignResponse(updated))
+	}
+}
+
+// PauseCampaign godoc
+// @Summary      Pause a campaign
+// @Description  Stops a scheduled, sending or testing campaign (409 otherwise). A run being queued stops after its current batch, an A/B test before sending its winner; emails already queued still go out.
+// @Tags         campaigns
+// @Produce      json
+// @Param        id   path      int  true  "Campaign ID"
+// @Success      200  {object}  dto.CampaignResponse
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      409  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/campaigns/{id}/pause [post]
+func PauseCampaign(db *gorm.DB) fiber.Handler {
+	return campaignTransition(db, func(db *gorm.DB, campaign *models.Campaign) (bool, error) {
+		return campaigns.Pause(db, campaign.ID)
+	})
+}
+
+// ResumeCampaign godoc
+// @Summary      Resume a campaign
+// @Description  Schedules a paused campaign again (409 otherwise). A run it was paused during is picked up where it stopped, without sending anyone the run twice; a recurring campaign that missed occurrences waits for the next one.
+// @Tags         campaigns
+// @Produce      json
+// @Param        id   path      int  true  "Campaign ID"
+// @Success      200  {object}  dto.CampaignResponse
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      409  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/campaigns/{id}/resume [post]
+func ResumeCampaign(db *gorm.DB) fiber.Handler {
+	return campaignTransition(db, func(db *gorm.DB, campaign *models.Campaign) (bool, error) {
+		return campaigns.Resume(db, campaign, time.Now())
+	})
+}
+
+// CancelCampaign godoc
+// @Summary      Cancel a campaign
+// @Description  Stops a campaign for good (409 once sent or cancelled). Emails of it still queued are dropped.
+// @Tags         campaigns
+// @Produce      json
+// @Param        id   path      int  true  "Campaign ID"
+// @Success      200  {object}  dto.CampaignResponse
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      409  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/campaigns/{id}/cancel [post]
+func CancelCampaign(db *gorm.DB) fiber.Handler {
+	return campaignTransition(db, func(db *gorm.DB, campaign *models.Campaign) (bool, error) {
+		return campaigns.Cancel(db, campaign.ID)
+	})
+}
+
+// rate is n out of sent, 0 when nothing was sent
+func rate(n, sent int) float64 {
+	if sent == 0 {
+		return 0
+	}
+	return float64(n) / float64(sent)
+}
+
+// campaignStats maps the counts of the current run of a campaign to its stats
+func campaignStats(campaign models.Campaign, stats []campaigns.VariantStats, links []campaigns.LinkStats, shortLinks []models.ShortLink) dto.CampaignStatsResponse {
+	resp := dto.CampaignStatsResponse{
+		CampaignID: campaign.ID,
+		Status:     campaign.Status,
+		Run:        campaign.Run,
+		TotalSent:  campaign.SentCount,
+		Links:      make([]dto.CampaignLinkStats, len(links)),
+		ShortLinks: make([]dto.CampaignShortLinkStats, len(shortLinks)),
+	}
+	for i, link := range links {
+		resp.Links[i] = dto.CampaignLinkStats{URL: link.URL, Clicks: link.Clicks, Recipients: link.Recipients}
+	}
+	for i, link := range shortLinks {
+		resp.ShortLinks[i] = dto.CampaignShortLinkStats{Code: link.Code, ShortURL: shortlinks.URL(link.Code), URL: link.URL, Clicks: link.Clicks}
+	}
+	subjects := map[string]string{campaigns.VariantA: campaign.Subject, campaigns.VariantB: campaign.SubjectB}
+	var test dto.CampaignABTestStats
+	tested := false
+	for _, s := range stats {
+		resp.Sent += s.Sent
+		resp.Opened += s.Opened
+		resp.Clicked += s.Clicked
+		variant := dto.CampaignVariantStats{
+			Variant:   s.Variant,
+			Subject:   subjects[s.Variant],
+			Sent:      s.Sent,
+			Opened:    s.Opened,
+			OpenRate:  rate(s.Opened, s.Sent),
+			Clicked:   s.Clicked,
+			ClickRate: rate(s.Clicked, s.Sent),
+		}
+		if s.Test {
+			tested = true
+			test.Variants = append(test.Variants, variant)
+		} else {
+			test.Remainder = &variant
+		}
+	}
+	resp.OpenRate = rate(resp.Opened, resp.Sent)
+	resp.ClickRate = rate(resp.Clicked, resp.Sent)
+	if campaign.TestPercent > 0 || tested {
+		test.TestPercent, test.EndsAt, test.Winner = campaign.TestPercent, campaign.TestEndsAt, campaign.Winner
+		if test.Variants == nil {
+			test.Variants = []dto.CampaignVariantStats{}
+		}
+		resp.ABTest = &test
+	}
+	return resp
+}
+
+// GetCampaignStats godoc
+// @Summary      Get the stats of a campaign
+// @Description  Counts the emails the current run of a campaign queued, how many recipients opened them and clicked a link, and the clicks on each link of its HTML. Opens are reported by the tracking pixel of each email, or a click when images are blocked; bots and mail security scanners are left out.
+// @Description  For an A/B tested campaign, ab_test has the opens of each subject in the test group, the winner once the test is over, and the opens of the remainder it was sent to.
+// @Description  short_links counts the clicks on the short links attributed to the campaign (see /admin/short-links), over all its runs and wherever they were shared.
+// @Tags         campaigns
+// @Produce      json
+// @Param        id   path      int  true  "Campaign ID"
+// @Success      200  {object}  dto.CampaignStatsResponse
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/campaigns/{id}/stats [get]
+func GetCampaignStats(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		db := database.Replica(db.WithContext(c.UserContext()))
+		campaign, status, msg := loadAdminCampaign(c, db)
+		if status != 0 {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+		stats, err := campaigns.RunStats(db, campaign.ID, campaign.Run)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve campaign stats"})
+		}
+		links, err := campaigns.RunLinkStats(db, campaign.ID, campaign.Run)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve campaign stats"})
+		}
+		var shortLinks []models.ShortLink
+		if err := db.Where("campaign_id = ?", campaign.ID).Order("clicks DESC, id").Find(&shortLinks).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve campaign stats"})
+		}
+		return c.JSON(campaignStats(campaign, stats, links, shortLinks))
+	}
+}
@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"fiber-gorm-api/internal/cache"
+	database "fiber-gorm-api/internal/db"
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/emaillog"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
+	"fiber-gorm-api/internal/outbox"
+	"fiber-gorm-api/internal/repository"
+	sendgridservice "fiber-gorm-api/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// deliveryEventStatus is the status an event moves a subscriber to, and the statuses it may move from.
+// Events not listed here (delivered, open, soft bounces, ...) are only recorded.
+func deliveryEventStatus(ev dto.SendGridEvent) (string, []string) {
+	switch ev.Event {
+	case models.DeliveryEventBounce:
+		if ev.Type == "blocked" {
+			return "", nil
+		}
+		fallthrough
+	case models.DeliveryEventSpamReport:
+		return models.SubscriberStatusBounced, []string{models.SubscriberStatusPending, models.SubscriberStatusActive}
+	case models.DeliveryEventUnsubscribe, models.DeliveryEventGroupUnsubscribe:
+		return models.SubscriberStatusUnsubscribed, []string{
+			models.SubscriberStatusPending, models.SubscriberStatusActive, models.SubscriberStatusBounced,
+		}
+	}
+	return "", nil
+}
+
+// applyDeliveryEvent records ev for every subscriber with its address (across organizations,
+// a bouncing mailbox bounces everywhere) and updates their status, and that of the email in the
+// email log. Events already stored are skipped.
+// It returns the ids of subscribers whose status changed.
+func applyDeliveryEvent(ctx context.Context, db *gorm.DB, ev dto.SendGridEvent) ([]uint, error) {
+	email := strings.TrimSpace(ev.Email)
+	if email == "" || ev.SGEventID == "" || ev.Event == "" {
+		return nil, nil
+	}
+	if err := emaillog.ApplyEvent(db, ev.Event, ev.SGMessageID); err != nil {
+		return nil, err
+	}
+
+	var subscribers []models.Subscriber
+	if err := db.Where("LOWER(email) = LOWER(?) AND anonymized_at IS NULL", email).Find(&subscribers).Error; err != nil {
+		return nil, err
+	}
+
+	newStatus, from := deliveryEventStatus(ev)
+	var changed []uint
+	err := database.Tx(ctx, db, func(tx *gorm.DB) error {
+		for _, s := range subscribers {
+			event := models.DeliveryEvent{
+				SubscriberID: s.ID,
+				Event:        ev.Event,
+				Reason:       ev.Reason,
+				SGEventID:    ev.SGEventID,
+				SGMessageID:  ev.SGMessageID,
+				OccurredAt:   time.Unix(ev.Timestamp, 0),
+			}
+			res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&event)
+			if res.Error != nil {
+				return res.Error
+			}
+			// skip events already stored by an earlier delivery, and those that don't change the status
+			if res.RowsAffected == 0 || newStatus == "" || !slices.Contains(from, s.Status) {
+				continue
+			}
+			before, err := repository.LoadSnapshot(tx, s.ID)
+			if err != nil {
+				return err
+			}
+			if err := tx.Model(&models.Subscriber{}).
+				Where("id = ? AND status IN ?", s.ID, from).
+				Updates(map[string]interface{}{
+					"status":  newStatus,
+					"version": gorm.Expr("version + 1"),
+				}).Error; err != nil {
+				return err
+			}
+			if err := repository.RecordRevision(ctx, tx, s.ID, models.RevisionDelivery, before); err != nil {
+				return err
+			}
+			s.Status = newStatus
+			if err := outbox.EnqueueSubscriber(tx, outbox.TopicSubscriberUpdated, &s); err != nil {
+				return err
+			}
+			changed = append(changed, s.ID)
+		}
+		return nil
+	})
+	return changed, err
+}
+
+// SendGridWebhook godoc
+// @Summary      SendGrid event webhook
+// @Description  Receives SendGrid's signed Event Webhook batches. Every event is stored in the subscriber's delivery history;
+// @Description  hard bounces and spam reports mark subscribers bounced, unsubscribes mark them unsubscribed.
+// @Description  Deferred, delivered, bounce and dropped events update the status of the email in the email log (GET /admin/emails).
+// @Description  Requires SENDGRID_WEBHOOK_PUBLIC_KEY. Retried deliveries are deduplicated by sg_event_id.
+// @Tags         webhooks
+// @Accept       json
+// @Param        X-Twilio-Email-Event-Webhook-Signature  header  string               true  "Base64 ECDSA signature"
+// @Param        X-Twilio-Email-Event-Webhook-Timestamp  header  string               true  "Signed timestamp"
+// @Param        events                                  body    []dto.SendGridEvent  true  "Event batch"
+// @Success      204  {string}  string
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      401  {object}  dto.ErrorResponse  "code: invalid_signature"
+// @Failure      500  {object}  dto.ErrorResponse
+// @Failure      503  {object}  dto.ErrorResponse  "code: webhook_not_configured"
+// @Router       /webhooks/sendgrid [post]
+func SendGridWebhook(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		err := sendgridservice.VerifyEventSignature(c.Body(),
+			c.Get(sendgridservice.EventSignatureHeader), c.Get(sendgridservice.EventTimestampHeader))
+		if errors.Is(err, sendgridservice.ErrInvalidSignature) {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Invalid signature",
+				"code":  "invalid_signature",
+			})
+		}
+		if err != nil {
+			log.Printf("[ERROR] SendGrid webhook: %v", err)
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"error": "Event webhook is not configured",
+				"code":  "webhook_not_configured",
+			})
+		}
+
+		var events []dto.SendGridEvent
+		if err := json.Unmarshal(c.Body(), &events); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Unable to parse request body"})
+		}
+
+		// status changes are recorded in the subscribers' revisions as made by SendGrid
+		ctx := repository.WithAuthor(c.UserContext(), "sendgrid")
+		var changed []uint
+		var failed error
+		for _, ev := range events {
+			ids, err := applyDeliveryEvent(ctx, db, ev)
+			if err != nil {
+				log.Printf("[ERROR] SendGrid webhook: storing %s event %s: %v", ev.Event, ev.SGEventID, err)
+				failed = err
+				break
+			}
+			changed = append(changed, ids...)
+		}
+		if len(changed) > 0 {
+			cache.InvalidateSubscriber(c.UserContext(), changed...)
+		}
+		if failed != nil {
+			// a 5xx makes SendGrid retry the batch; events stored so far are skipped on retry
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not store events"})
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	}
+}
+
+// GetSubscriberDeliveryEvents godoc
+// @Summary      Subscriber delivery history
+// @Description  Lists the email delivery events (delivered, bounce, spam report, unsubscribe, ...) received for a subscriber, newest first.
+// @Description  Callers without the pii scope don't see the provider's reason text.
+// @Tags         subscribers
+// @Produce      json
+// @Param        id   path      int true "Subscriber ID"
+// @Success      200  {array}   dto.DeliveryEventResponse
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/subscribers/{id}/delivery-events [get]
+func GetSubscriberDeliveryEvents(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		id, err := strconv.Atoi(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid subscriber ID"})
+		}
+
+		var subscriber models.Subscriber
+		if err := db.Scopes(orgScope(c)).First(&subscriber, id).Error; err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Subscriber not found"})
+		}
+
+		var events []models.DeliveryEvent
+		if err := db.Where("subscriber_id = ?", subscriber.ID).
+			Order("occurred_at DESC").Order("id DESC").
+			Find(&events).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve delivery events"})
+		}
+
+		resp := dto.NewDeliveryEventResponses(events)
+		if !middleware.CanSeePII(c) {
+			for i := range resp {
+				resp[i] = resp[i].Redacted()
+			}
+		}
+		return c.JSON(resp)
+	}
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"errors"
+	"time"
+
+	"fiber-gorm-api/internal/storage"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// sendSignedFile sends the object key of storage.Default as an attachment named filename, if
+// the expires and signature query parameters are a link to it from storage.DownloadURL that
+// hasn't expired. An empty key (nothing to download) answers like a bad signature, so links
+// can't be used to find out which jobs exist.
+func sendSignedFile(c *fiber.Ctx, key, filename string) error {
+	if key == "" || !storage.VerifyLink(key, c.Query("expires"), c.Query("signature"), time.Now()) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Download link is invalid or has expired, please ask for a new one",
+			"code":  "invalid_signature",
+		})
+	}
+
+	file, err := storage.Default.Open(c.UserContext(), key)
+	if errors.Is(err, storage.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "File is gone"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not read file"})
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	c.Attachment(filename)
+	// SendStream closes file once it's sent
+	return c.SendStream(file)
+}
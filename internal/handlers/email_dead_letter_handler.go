@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// GetEmailDeadLetters godoc
+// @Summary      List emails given up on
+// @Description  Lists the sign-in emails (codes and links) the background queue gave up on, oldest first: SendGrid kept failing for OUTBOX_MAX_ATTEMPTS tries, refused them, or they expired waiting. What they carried is not kept.
+// @Description  limit (max 500), offset and cursor page through them like the subscriber list; sort is id or created_at.
+// @Tags         email-dead-letters
+// @Produce      json
+// @Param        limit   query     int     false  "Page size (max 500)"
+// @Param        offset  query     int     false  "Rows to skip"
+// @Param        sort    query     string  false  "id (default) or created_at"
+// @Param        cursor  query     string  false  "Opaque cursor from X-Next-Cursor"
+// @Success      200  {array}   dto.EmailDeadLetterResponse
+// @Header       200  {string}  X-Next-Cursor  "Cursor for the next page, absent on the last page"
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      403  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/email-dead-letters [get]
+func GetEmailDeadLetters(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		page, err := parsePageParams(c)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		// dead letters are never updated
+		if page.Sort == "updated_at" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid sort"})
+		}
+
+		var letters []models.EmailDeadLetter
+		if err := page.apply(db.WithContext(c.UserContext())).Find(&letters).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve dead letters"})
+		}
+		if page.Paginated() && len(letters) > page.Limit {
+			letters = letters[:page.Limit]
+			last := letters[len(letters)-1]
+			c.Set(NextCursorHeader, page.nextCursor(last.ID, last.CreatedAt, last.CreatedAt))
+		}
+
+		resp := make([]dto.EmailDeadLetterResponse, len(letters))
+		for i, l := range letters {
+			resp[i] = dto.NewEmailDeadLetterResponse(l)
+		}
+		return c.JSON(resp)
+	}
+}
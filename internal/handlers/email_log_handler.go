@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"strconv"
+	"time"
+
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// GetSubscriberEmails godoc
+// @Summary      Emails sent to a subscriber
+// @Description  Lists the emails sent to the subscriber's address, or that failed to send, newest first, with their status from the SendGrid webhook.
+// @Description  Emails are logged per address: those sent to it for another organization, or to sign in, are listed too.
+// @Description  Callers without the pii scope get the address masked and no send errors.
+// @Tags         subscribers
+// @Produce      json
+// @Param        id   path      int true "Subscriber ID"
+// @Success      200  {array}   dto.EmailLogResponse
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/subscribers/{id}/emails [get]
+func GetSubscriberEmails(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		id, err := strconv.Atoi(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid subscriber ID"})
+		}
+
+		var subscriber models.Subscriber
+		if err := db.Scopes(orgScope(c)).First(&subscriber, id).Error; err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Subscriber not found"})
+		}
+
+		var logs []models.EmailLog
+		if err := db.Where("LOWER(to_email) = LOWER(?)", subscriber.Email).
+			Order("created_at DESC").Order("id DESC").
+			Find(&logs).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve emails"})
+		}
+
+		resp := dto.NewEmailLogResponses(logs)
+		if !middleware.CanSeePII(c) {
+			for i := range resp {
+				resp[i] = resp[i].Redacted()
+			}
+		}
+		return c.JSON(resp)
+	}
+}
+
+// GetEmails godoc
+// @Summary      List sent emails
+// @Description  Lists the emails the API sent, or failed to send, across organizations, oldest first, with their status from the SendGrid webhook.
+// @Description  Pages of 50 by default: limit (max 500), offset and cursor page through them like the subscriber list.
+// @Tags         emails
+// @Produce      json
+// @Param        to      query     string  false  "Only emails to this address (case insensitive)"
+// @Param        type    query     string  false  "Only emails of this type: signin_code, magic_link, invitation, confirmation, preferences, admin_notification, campaign or template_test"
+// @Param        status  query     string  false  "Only emails with this status: sent, failed, deferred, delivered, bounce or dropped"
+// @Param        since   query     string  false  "Only emails sent at or after this RFC 3339 time"
+// @Param        until   query     string  false  "Only emails sent before this RFC 3339 time"
+// @Param        limit   query     int     false  "Page size (default 50, max 500)"
+// @Param        offset  query     int     false  "Rows to skip"
+// @Param        sort    query     string  false  "Sort key: id (default), created_at or updated_at"
+// @Param        cursor  query     string  false  "Opaque cursor from X-Next-Cursor"
+// @Success      200  {array}   dto.EmailLogResponse
+// @Header       200  {string}  X-Next-Cursor  "Cursor for the next page, absent on the last page"
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      403  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/emails [get]
+func GetEmails(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		page, err := parsePageParams(c)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		// the log only grows: never all of it at once
+		if !page.Paginated() {
+			page.Limit = defaultPageLimit
+		}
+
+		query := db.WithContext(c.UserContext()).Model(&models.EmailLog{})
+		if to := c.Query("to"); to != "" {
+			query = query.Where("LOWER(to_email) = LOWER(?)", to)
+		}
+		if emailType := c.Query("type"); emailType != "" {
+			query = query.Where("type = ?", emailType)
+		}
+		if status := c.Query("status"); status != "" {
+			query = query.Where("status = ?", status)
+		}
+		for param, cond := range map[string]string{"since": "created_at >= ?", "until": "created_at < ?"} {
+			value := c.Query(param)
+			if value == "" {
+				continue
+			}
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid " + param + ", expected an RFC 3339 time"})
+			}
+			query = query.Where(cond, t)
+		}
+
+		var logs []models.EmailLog
+		if err := page.apply(query).Find(&logs).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve emails"})
+		}
+		if len(logs) > page.Limit {
+			logs = logs[:page.Limit]
+			last := logs[len(logs)-1]
+			c.Set(NextCursorHeader, page.nextCursor(last.ID, last.CreatedAt, last.UpdatedAt))
+		}
+		return c.JSON(dto.NewEmailLogResponses(logs))
+	}
+}
@@ -0,0 +1,285 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
+	"fiber-gorm-api/internal/service"
+	sendgridservice "fiber-gorm-api/internal/services"
+	"fiber-gorm-api/internal/templates"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+var errUnparsableTemplate = errors.New("unable to parse request body")
+
+// parseEmailTemplate reads and validates a create / update body
+func parseEmailTemplate(c *fiber.Ctx) (models.EmailTemplate, error) {
+	var req dto.EmailTemplateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return models.EmailTemplate{}, errUnparsableTemplate
+	}
+	tmpl := req.ToModel()
+	return tmpl, service.ValidateEmailTemplate(&tmpl)
+}
+
+// emailTemplateInvalid writes the error response of a template rejected on save or render: 422
+// with where the error is for one that doesn't parse or render
+func emailTemplateInvalid(c *fiber.Ctx, err error) error {
+	var tmplErr *templates.Error
+	switch {
+	case errors.Is(err, errUnparsableTemplate):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Unable to parse request body"})
+	case errors.As(err, &tmplErr):
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(dto.TemplateErrorResponse{
+			Error:  tmplErr.Message,
+			Code:   "invalid_template",
+			Part:   tmplErr.Part,
+			Line:   tmplErr.Line,
+			Column: tmplErr.Column,
+		})
+	}
+	return subscriberValidationFailed(c, err)
+}
+
+// loadAdminEmailTemplate reads the template of the caller's organization named by the id param,
+// returning the status and message of the error response when it can't
+func loadAdminEmailTemplate(c *fiber.Ctx, db *gorm.DB) (models.EmailTemplate, int, string) {
+	var tmpl models.EmailTemplate
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return tmpl, fiber.StatusBadRequest, "Invalid template ID"
+	}
+	if err := db.Scopes(orgScope(c)).First(&tmpl, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return tmpl, fiber.StatusNotFound, "Template not found"
+		}
+		return tmpl, fiber.StatusInternalServerError, "Could not retrieve template"
+	}
+	return tmpl, 0, ""
+}
+
+// renderEmailTemplate renders the template of the id param with the sample variables, overridden
+// by those of the body, writing the error response when it can't
+func renderEmailTemplate(c *fiber.Ctx, db *gorm.DB) (templates.Rendered, bool, error) {
+	tmpl, status, msg := loadAdminEmailTemplate(c, db)
+	if status != 0 {
+		return templates.Rendered{}, false, c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+	var req dto.EmailTemplateRenderRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return templates.Rendered{}, false, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Unable to parse request body"})
+		}
+	}
+	vars := make(map[string]interface{}, len(templates.SampleVariables)+len(req.Variables))
+	for k, v := range templates.SampleVariables {
+		vars[k] = v
+	}
+	for k, v := range req.Variables {
+		vars[k] = v
+	}
+	rendered, err := templates.Render(tmpl, vars)
+	if err != nil {
+		return rendered, false, emailTemplateInvalid(c, err)
+	}
+	return rendered, true, nil
+}
+
+// CreateEmailTemplate godoc
+// @Summary      Create an email template
+// @Description  Creates an email template of the organization. Subject, text and HTML are Go templates filled with variables, e.g. {{.FirstName}}; the HTML escapes them.
+// @Description  A template needs a subject (code missing_subject) and a text or HTML (code missing_content). One that doesn't parse is answered 422 with code invalid_template and where the error is.
+// @Description  With format markdown or mjml, the HTML is compiled from source (code missing_source without one) into a layout for any screen width; a source that doesn't compile is answered 422 with part source and its line and column.
+// @Tags         email-templates
+// @Accept       json
+// @Produce      json
+// @Param        template  body      dto.EmailTemplateRequest  true  "Template"
+// @Success      201       {object}  dto.EmailTemplateResponse
+// @Failure      400       {object}  dto.ErrorResponse
+// @Failure      422       {object}  dto.TemplateErrorResponse
+// @Failure      500       {object}  dto.ErrorResponse
+// @Router       /admin/email-templates [post]
+func CreateEmailTemplate(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		tmpl, err := parseEmailTemplate(c)
+		if err != nil {
+			return emailTemplateInvalid(c, err)
+		}
+		tmpl.OrgID = middleware.CurrentOrgID(c)
+		tmpl.CreatedBy = callerIdentity(c)
+		if err := db.WithContext(c.UserContext()).Create(&tmpl).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not create template"})
+		}
+		c.Location("/admin/email-templates/" + strconv.FormatUint(uint64(tmpl.ID), 10))
+		return c.Status(fiber.StatusCreated).JSON(dto.NewEmailTemplateResponse(tmpl))
+	}
+}
+
+// GetEmailTemplates godoc
+// @Summary      List email templates
+// @Description  Lists the email templates of the organization.
+// @Tags         email-templates
+// @Produce      json
+// @Success      200  {array}   dto.EmailTemplateResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/email-templates [get]
+func GetEmailTemplates(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var tmpls []models.EmailTemplate
+		if err := db.WithContext(c.UserContext()).Scopes(orgScope(c)).Order("id").Find(&tmpls).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve templates"})
+		}
+		resp := make([]dto.EmailTemplateResponse, len(tmpls))
+		for i, t := range tmpls {
+			resp[i] = dto.NewEmailTemplateResponse(t)
+		}
+		return c.JSON(resp)
+	}
+}
+
+// GetEmailTemplate godoc
+// @Summary      Get an email template
+// @Tags         email-templates
+// @Produce      json
+// @Param        id   path      int  true  "Template ID"
+// @Success      200  {object}  dto.EmailTemplateResponse
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/email-templates/{id} [get]
+func GetEmailTemplate(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		tmpl, status, msg := loadAdminEmailTemplate(c, db.WithContext(c.UserContext()))
+		if status != 0 {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+		return c.JSON(dto.NewEmailTemplateResponse(tmpl))
+	}
+}
+
+// UpdateEmailTemplate godoc
+// @Summary      Update an email template
+// @Description  Replaces an email template, validated as on create.
+// @Tags         email-templates
+// @Accept       json
+// @Produce      json
+// @Param        id        path      int                       true  "Template ID"
+// @Param        template  body      dto.EmailTemplateRequest  true  "Template"
+// @Success      200       {object}  dto.EmailTemplateResponse
+// @Failure      400       {object}  dto.ErrorResponse
+// @Failure      404       {object}  dto.ErrorResponse
+// @Failure      422       {object}  dto.TemplateErrorResponse
+// @Failure      500       {object}  dto.ErrorResponse
+// @Router       /admin/email-templates/{id} [put]
+func UpdateEmailTemplate(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		current, status, msg := loadAdminEmailTemplate(c, db)
+		if status != 0 {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+		tmpl, err := parseEmailTemplate(c)
+		if err != nil {
+			return emailTemplateInvalid(c, err)
+		}
+		tmpl.ID, tmpl.OrgID = current.ID, current.OrgID
+		tmpl.CreatedBy, tmpl.CreatedAt = current.CreatedBy, current.CreatedAt
+		if err := db.Save(&tmpl).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not update template"})
+		}
+		return c.JSON(dto.NewEmailTemplateResponse(tmpl))
+	}
+}
+
+// DeleteEmailTemplate godoc
+// @Summary      Delete an email template
+// @Tags         email-templates
+// @Param        id   path  int  true  "Template ID"
+// @Success      204  {string}  string
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/email-templates/{id} [delete]
+func DeleteEmailTemplate(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := strconv.Atoi(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid template ID"})
+		}
+		res := db.WithContext(c.UserContext()).Scopes(orgScope(c)).Delete(&models.EmailTemplate{}, id)
+		if res.Error != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not delete template"})
+		}
+		if res.RowsAffected == 0 {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Template not found"})
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	}
+}
+
+// PreviewEmailTemplate godoc
+// @Summary      Preview an email template
+// @Description  Renders an email template with sample variables (Name, FirstName, Email, PreferencesURL), overridden and completed by those of the body, and returns its subject, text and HTML.
+// @Description  A variable the template uses but isn't given, or a template that doesn't render, is answered 422 with code invalid_template and where the error is.
+// @Tags         email-templates
+// @Accept       json
+// @Produce      json
+// @Param        id    path      int                             true   "Template ID"
+// @Param        body  body      dto.EmailTemplateRenderRequest  false  "Variables"
+// @Success      200   {object}  dto.EmailTemplatePreviewResponse
+// @Failure      400   {object}  dto.ErrorResponse
+// @Failure      404   {object}  dto.ErrorResponse
+// @Failure      422   {object}  dto.TemplateErrorResponse
+// @Failure      500   {object}  dto.ErrorResponse
+// @Router       /admin/email-templates/{id}/preview [post]
+func PreviewEmailTemplate(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		rendered, ok, err := renderEmailTemplate(c, db.WithContext(c.UserContext()))
+		if !ok {
+			return err
+		}
+		return c.JSON(dto.EmailTemplatePreviewResponse{Subject: rendered.Subject, Text: rendered.Text, HTML: rendered.HTML})
+	}
+}
+
+// TestSendEmailTemplate godoc
+// @Summary      Send an email template to yourself
+// @Description  Renders an email template as POST /admin/email-templates/{id}/preview does and emails it, its subject prefixed with [Test], to the signed-in admin. API keys, which have no address, are answered 401.
+// @Tags         email-templates
+// @Accept       json
+// @Produce      json
+// @Param        id    path      int                             true   "Template ID"
+// @Param        body  body      dto.EmailTemplateRenderRequest  false  "Variables"
+// @Success      200   {object}  dto.EmailTemplateTestSendResponse
+// @Failure      400   {object}  dto.ErrorResponse
+// @Failure      401   {object}  dto.ErrorResponse
+// @Failure      404   {object}  dto.ErrorResponse
+// @Failure      422   {object}  dto.TemplateErrorResponse
+// @Failure      502   {object}  dto.ErrorResponse
+// @Router       /admin/email-templates/{id}/test-send [post]
+func TestSendEmailTemplate(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		current := middleware.CurrentSession(c)
+		if current == nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Not signed in"})
+		}
+		rendered, ok, err := renderEmailTemplate(c, db.WithContext(c.UserContext()))
+		if !ok {
+			return err
+		}
+
+		subject := "[Test] " + rendered.Subject
+		if err := sendgridservice.SendTemplateTestEmailFunc(current.Email, subject, rendered.Text, rendered.HTML); err != nil {
+			log.Printf("[WARN] Email templates: test send to %s failed: %v", current.Email, err)
+			return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{"error": fmt.Sprintf("Could not send the test email: %v", err)})
+		}
+		return c.JSON(dto.EmailTemplateTestSendResponse{To: current.Email, Subject: subject})
+	}
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// subscriberETag derives a strong entity tag from the row's identity and last modification
+func subscriberETag(id uint, updatedAt time.Time) string {
+	return `"` + strconv.FormatUint(uint64(id), 10) + "-" + strconv.FormatInt(updatedAt.UnixNano(), 36) + `"`
+}
+
+// etagListMatches reports whether etag appears in an If-Match / If-None-Match header value.
+// "*" matches anything. Weak validators only match when weak comparison is allowed (If-None-Match).
+func etagListMatches(header, etag string, weak bool) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if strings.HasPrefix(candidate, "W/") {
+			if !weak {
+				continue
+			}
+			candidate = strings.TrimPrefix(candidate, "W/")
+		}
+		if candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// notModified sets the ETag header and reports whether If-None-Match already holds it
+func notModified(c *fiber.Ctx, etag string) bool {
+	c.Set(fiber.HeaderETag, etag)
+	inm := c.Get(fiber.HeaderIfNoneMatch)
+	return inm != "" && etagListMatches(inm, etag, true)
+}
+
+// notModifiedSince sets the Last-Modified header and reports whether If-Modified-Since is
+// already at or after modified. HTTP dates have second precision, so Last-Modified is modified
+// rounded up to the second, and only sent once that second is over: a write later in the same
+// second would otherwise carry the date the caller already has. A zero modified (nothing was
+// ever written) is never reported unchanged.
+func notModifiedSince(c *fiber.Ctx, modified time.Time) bool {
+	if modified.IsZero() {
+		return false
+	}
+	lastModified := modified.UTC().Truncate(time.Second)
+	if lastModified.Before(modified) {
+		lastModified = lastModified.Add(time.Second)
+	}
+	if !lastModified.After(time.Now()) {
+		c.Set(fiber.HeaderLastModified, lastModified.Format(http.TimeFormat))
+	}
+	// If-None-Match, when sent, takes precedence
+	if c.Get(fiber.HeaderIfNoneMatch) != "" {
+		return false
+	}
+	since, err := http.ParseTime(c.Get(fiber.HeaderIfModifiedSince))
+	return err == nil && !modified.After(since)
+}
+
+// checkIfMatch enforces a matching If-Match header before a write.
+// It writes the 428 / 412 response itself and returns false when the write must not proceed.
+func checkIfMatch(c *fiber.Ctx, etag string) (bool, error) {
+	ifMatch := c.Get(fiber.HeaderIfMatch)
+	if ifMatch == "" {
+		return false, c.Status(fiber.StatusPreconditionRequired).JSON(fiber.Map{
+			"error": "If-Match header is required",
+			"code":  "if_match_required",
+		})
+	}
+	if !etagListMatches(ifMatch, etag, false) {
+		c.Set(fiber.HeaderETag, etag)
+		return false, c.Status(fiber.StatusPreconditionFailed).JSON(fiber.Map{
+			"error": "Subscriber was modified since it was read",
+			"code":  "precondition_failed",
+		})
+	}
+	return true, nil
+}
@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"fiber-gorm-api/internal/calendar"
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
+	"fiber-gorm-api/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+var (
+	errUnparsableEvent = errors.New("unable to parse request body")
+	errUnknownEvent    = errors.New("unknown event")
+)
+
+// parseEvent reads and validates a create / update body
+func parseEvent(c *fiber.Ctx) (models.Event, error) {
+	var req dto.EventRequest
+	if err := c.BodyParser(&req); err != nil {
+		return models.Event{}, errUnparsableEvent
+	}
+	event := req.ToModel()
+	if err := service.ValidateEvent(&event, req.StartsAt, req.EndsAt); err != nil {
+		return event, err
+	}
+	return event, nil
+}
+
+// eventInvalid writes the error response of a body parseEvent rejected
+func eventInvalid(c *fiber.Ctx, err error) error {
+	if errors.Is(err, errUnparsableEvent) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Unable to parse request body"})
+	}
+	return subscriberValidationFailed(c, err)
+}
+
+// loadAdminEvent reads the event of the caller's organization named by the id param, returning
+// the status and message of the error response when it can't
+func loadAdminEvent(c *fiber.Ctx, db *gorm.DB) (models.Event, int, string) {
+	var event models.Event
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return event, fiber.StatusBadRequest, "Invalid event ID"
+	}
+	if err := db.Scopes(orgScope(c)).First(&event, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return event, fiber.StatusNotFound, "Event not found"
+		}
+		return event, fiber.StatusInternalServerError, "Could not retrieve event"
+	}
+	return event, 0, ""
+}
+
+// CreateLocalEvent godoc
+// @Summary      Create a local event
+// @Description  Creates a local event of the organization, such as a market day, announced by campaigns with its calendar invite (event_id of /admin/campaigns) and served at calendar_url.
+// @Description  starts_at and ends_at are RFC 3339 times, or local 2025-07-05T09:00 read in timezone (an IANA name, UTC by default); ends_at must be after starts_at (code invalid_time). url, the page of the event, is an http(s) URL (code invalid_url).
+// @Tags         local-events
+// @Accept       json
+// @Produce      json
+// @Param        event  body      dto.EventRequest  true  "Event"
+// @Success      201    {object}  dto.EventResponse
+// @Failure      400    {object}  dto.ErrorResponse
+// @Failure      500    {object}  dto.ErrorResponse
+// @Router       /admin/local-events [post]
+func CreateLocalEvent(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		event, err := parseEvent(c)
+		if err != nil {
+			return eventInvalid(c, err)
+		}
+		event.OrgID = middleware.CurrentOrgID(c)
+		event.CreatedBy = callerIdentity(c)
+		if err := db.WithContext(c.UserContext()).Create(&event).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not create event"})
+		}
+		c.Location("/admin/local-events/" + strconv.FormatUint(uint64(event.ID), 10))
+		return c.Status(fiber.StatusCreated).JSON(dto.NewEventResponse(event))
+	}
+}
+
+// GetLocalEvents godoc
+// @Summary      List local events
+// @Description  Lists the local events of the organization by start, those not over yet with upcoming=true.
+// @Tags         local-events
+// @Produce      json
+// @Param        upcoming  query     bool  false  "Only events not over yet"
+// @Success      200       {array}   dto.EventResponse
+// @Failure      500       {object}  dto.ErrorResponse
+// @Router       /admin/local-events [get]
+func GetLocalEvents(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		query := db.WithContext(c.UserContext()).Scopes(orgScope(c)).Order("starts_at, id")
+		if c.QueryBool("upcoming") {
+			query = query.Where("ends_at > ?", time.Now().UTC())
+		}
+		var events []models.Event
+		if err := query.Find(&events).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve events"})
+		}
+		resp := make([]dto.EventResponse, len(events))
+		for i, e := range events {
+			resp[i] = dto.NewEventResponse(e)
+		}
+		return c.JSON(resp)
+	}
+}
+
+// GetLocalEvent godoc
+// @Summary      Get a local event
+// @Tags         local-events
+// @Produce      json
+// @Param        id   path      int  true  "Event ID"
+// @Success      200  {object}  dto.EventResponse
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/local-events/{id} [get]
+func GetLocalEvent(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		event, status, msg := loadAdminEvent(c, db.WithContext(c.UserContext()))
+		if status != 0 {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+		return c.JSON(dto.NewEventResponse(event))
+	}
+}
+
+// UpdateLocalEvent godoc
+// @Summary      Update a local event
+// @Description  Replaces the details and times of a local event, validated as on create. Its sequence is incremented, so calendars that added its invite replace it when they fetch it again; emails already sent keep the old one.
+// @Tags         local-events
+// @Accept       json
+// @Produce      json
+// @Param        id     path      int               true  "Event ID"
+// @Param        event  body      dto.EventRequest  true  "Event"
+// @Success      200    {object}  dto.EventResponse
+// @Failure      400    {object}  dto.ErrorResponse
+// @Failure      404    {object}  dto.ErrorResponse
+// @Failure      500    {object}  dto.ErrorResponse
+// @Router       /admin/local-events/{id} [put]
+func UpdateLocalEvent(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		current, status, msg := loadAdminEvent(c, db)
+		if status != 0 {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+		event, err := parseEvent(c)
+		if err != nil {
+			return eventInvalid(c, err)
+		}
+
+		current.Title, current.Description, current.Location, current.URL = event.Title, event.Description, event.Location, event.URL
+		current.StartsAt, current.EndsAt, current.Timezone = event.StartsAt, event.EndsAt, event.Timezone
+		current.Sequence++
+		if err := db.Select("title", "description", "location", "url", "starts_at", "ends_at", "timezone", "sequence", "updated_at").
+			Save(&current).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not update event"})
+		}
+		return c.JSON(dto.NewEventResponse(current))
+	}
+}
+
+// DeleteLocalEvent godoc
+// @Summary      Delete a local event
+// @Description  Deletes a local event; its invite is then answered 404. Campaigns announcing it are kept, sent without an invite.
+// @Tags         local-events
+// @Param        id   path  int  true  "Event ID"
+// @Success      204  {string}  string
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/local-events/{id} [delete]
+func DeleteLocalEvent(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		event, status, msg := loadAdminEvent(c, db)
+		if status != 0 {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Delete(&event).Error; err != nil {
+				return err
+			}
+			return tx.Model(&models.Campaign{}).Where("event_id = ?", event.ID).Update("event_id", nil).Error
+		})
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not delete event"})
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	}
+}
+
+// GetEventCalendar godoc
+// @Summary      Calendar invite of an event
+// @Description  Serves the iCalendar (RFC 5545) invite of a local event, to add it to a calendar. Its UID stays the same and its SEQUENCE grows as the event is updated, so calendars replace the copy they have.
+// @Tags         local-events
+// @Produce      text/calendar
+// @Param        id   path      int  true  "Event ID"
+// @Success      200  {string}  string
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /events/{id}/calendar.ics [get]
+func GetEventCalendar(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid event ID"})
+		}
+		var event models.Event
+		if err := db.WithContext(c.UserContext()).First(&event, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Event not found"})
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve event"})
+		}
+		c.Set(fiber.HeaderContentType, calendar.ContentType)
+		c.Set(fiber.HeaderContentDisposition, `attachment; filename="`+calendar.Filename(event)+`"`)
+		c.Set(fiber.HeaderCacheControl, "no-cache")
+		return c.Send(calendar.ICS(event, time.Now()))
+	}
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"time"
+
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/exports"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
+	"fiber-gorm-api/internal/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// exportResponse describes job, with a fresh download link once its file is ready
+func exportResponse(c *fiber.Ctx, job models.ExportJob) (dto.ExportJobResponse, error) {
+	resp := dto.NewExportJobResponse(job)
+	if job.Status == models.ExportDone {
+		url, expires, err := exports.DownloadURL(c.UserContext(), &job, time.Now())
+		if err != nil {
+			return resp, err
+		}
+		resp.DownloadURL, resp.DownloadExpiresAt = url, &expires
+	}
+	return resp, nil
+}
+
+// CreateExport godoc
+// @Summary      Export subscribers
+// @Description  Queues an export of the organization's subscribers matching the filters (the same as GET /admin/subscribers, filters.query being a filter expression), or the members of segment_id (see /admin/segments), as CSV or JSON. The export worker writes the file in the background; poll GET /admin/exports/{id} for its status and download link.
+// @Description  Files are deleted EXPORT_TTL_HOURS (24 by default) after they're written. Exports contain raw emails, so this needs the pii scope.
+// @Tags         exports
+// @Accept       json
+// @Produce      json
+// @Param        body  body      dto.CreateExportRequest  true  "Format and filters"
+// @Success      202   {object}  dto.ExportJobResponse
+// @Failure      400   {object}  dto.ErrorResponse
+// @Failure      403   {object}  dto.ErrorResponse
+// @Failure      500   {object}  dto.ErrorResponse
+// @Router       /admin/exports [post]
+func CreateExport(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req dto.CreateExportRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+		}
+		if req.Format == "" {
+			req.Format = models.ExportFormatCSV
+		}
+		if !slices.Contains(exports.Formats, req.Format) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid format, expected csv or json"})
+		}
+		filter := repository.SubscriberFilter{
+			Statuses:       req.Filters.Status,
+			Verified:       req.Filters.Verified,
+			SubscriberType: req.Filters.SubscriberType,
+			Frequency:      req.Filters.Frequency,
+			Channel:        req.Filters.Channel,
+			Query:          req.Filters.Query,
+		}
+		if req.SegmentID != nil {
+			if len(filter.Map()) > 0 {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Pass either segment_id or filters"})
+			}
+			var err error
+			_, filter, err = segmentFilter(c, db.WithContext(c.UserContext()), *req.SegmentID)
+			if errors.Is(err, errUnknownSegment) {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Unknown segment", "code": "unknown_segment"})
+			}
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve segment"})
+			}
+		}
+		if _, err := filter.Scope(); err != nil {
+			return filterRejected(c, err)
+		}
+
+		job := models.ExportJob{
+			OrgID:       middleware.CurrentOrgID(c),
+			Format:      req.Format,
+			Filters:     exports.FilterMap(filter),
+			SegmentID:   req.SegmentID,
+			Status:      models.ExportPending,
+			RequestedBy: callerIdentity(c),
+		}
+		if err := db.WithContext(c.UserContext()).Create(&job).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not create export"})
+		}
+		resp, _ := exportResponse(c, job)
+		c.Location("/admin/exports/" + strconv.FormatUint(uint64(job.ID), 10))
+		return c.Status(fiber.StatusAccepted).JSON(resp)
+	}
+}
+
+// GetExport godoc
+// @Summary      Get an export
+// @Description  Returns the status of an export. Once done, download_url is a signed link to the file valid for STORAGE_LINK_TTL_MINUTES (60 by default, at most until the file expires); every call returns a fresh one.
+// @Description  With S3 storage the link downloads straight from the bucket, otherwise from GET /admin/exports/{id}/download.
+// @Tags         exports
+// @Produce      json
+// @Param        id   path      int  true  "Export ID"
+// @Success      200  {object}  dto.ExportJobResponse
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      403  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/exports/{id} [get]
+func GetExport(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := strconv.Atoi(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid export ID"})
+		}
+
+		var job models.ExportJob
+		err = db.WithContext(c.UserContext()).Scopes(orgScope(c)).First(&job, id).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Export not found"})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve export"})
+		}
+		resp, err := exportResponse(c, job)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not sign the download link"})
+		}
+		return c.JSON(resp)
+	}
+}
+
+// DownloadExport godoc
+// @Summary      Download an export
+// @Description  Sends the file of a finished export kept on local storage. No sign in: the signed link from GET /admin/exports/{id} is the credential, until it expires.
+// @Tags         exports
+// @Produce      text/csv
+// @Produce      json
+// @Param        id         path      int     true  "Export ID"
+// @Param        expires    query     int     true  "Link expiry (Unix time), from download_url"
+// @Param        signature  query     string  true  "Link signature, from download_url"
+// @Success      200  {file}    file
+// @Failure      403  {object}  dto.ErrorResponse  "code: invalid_signature, also once the link expired"
+// @Failure      404  {object}  dto.ErrorResponse  "The file was deleted"
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/exports/{id}/download [get]
+func DownloadExport(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var job models.ExportJob
+		id, _ := strconv.Atoi(c.Params("id"))
+		err := db.WithContext(c.UserContext()).Where("status = ?", models.ExportDone).First(&job, id).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve export"})
+		}
+		return sendSignedFile(c, job.File, exports.FileName(&job))
+	}
+}
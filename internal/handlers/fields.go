@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"errors"
+	"slices"
+	"strings"
+
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// subscriberFieldColumns maps the ?fields= names onto the subscribers column each is read
+// from; relations are preloaded instead (see subscriberRelations)
+var subscriberFieldColumns = map[string]string{
+	"id":                "id",
+	"org_id":            "org_id",
+	"email":             "email",
+	"name":              "name",
+	"status":            "status",
+	"email_verified_at": "email_verified_at",
+	"anonymized_at":     "anonymized_at",
+	"metadata":          "metadata",
+	"locale":            "locale",
+	"version":           "version",
+	"referral_code":     "referral_code",
+	"referred_by_id":    "referred_by_id",
+	"created_at":        "created_at",
+	"updated_at":        "updated_at",
+}
+
+// subscriberRelations maps the relations ?include= may embed onto the loader of each
+var subscriberRelations = map[string]func(*gorm.DB, []models.Subscriber) error{
+	"subscriber_types": loadSubscriberTypes,
+	"notes":            loadSubscriberNotes,
+}
+
+// subscriberRelationNames lists the keys of subscriberRelations, for error messages
+var subscriberRelationNames = []string{"subscriber_types", "notes"}
+
+// subscriberKeyColumns are read whatever the fields: the organization check, ETags and
+// pagination cursors are built from them
+var subscriberKeyColumns = []string{"id", "org_id", "created_at", "updated_at"}
+
+// subscriberProjection is the part of the subscriber records a read returns: the fields of
+// ?fields= (nil for all of them) and the relations of ?include=
+type subscriberProjection struct {
+	fields  []string
+	include []string
+}
+
+// subscriberProjectionFor reads ?fields= and ?include= of a subscriber read. Without either
+// the relations in defaults are embedded; an empty ?include= embeds none. A relation named in
+// ?fields= is embedded too, and with ?fields= alone only those are. Errors are projectionErrors.
+func subscriberProjectionFor(c *fiber.Ctx, defaults ...string) (subscriberProjection, error) {
+	var p subscriberProjection
+	if param := c.Query("fields"); param != "" {
+		for _, field := range strings.Split(param, ",") {
+			field = strings.TrimSpace(field)
+			if !slices.Contains(dto.SubscriberFields, field) {
+				return p, &projectionError{
+					message: "Unknown field: " + field + ", expected " + strings.Join(dto.SubscriberFields, ", "),
+					code:    "invalid_fields",
+				}
+			}
+			if !slices.Contains(p.fields, field) {
+				p.fields = append(p.fields, field)
+			}
+		}
+	}
+
+	if p.fields == nil {
+		p.include = slices.Clone(defaults)
+	}
+	if c.Context().QueryArgs().Has("include") {
+		p.include = nil
+		for _, relation := range strings.Split(c.Query("include"), ",") {
+			relation = strings.TrimSpace(relation)
+			if relation == "" {
+				continue
+			}
+			if _, ok := subscriberRelations[relation]; !ok {
+				return p, &projectionError{
+					message: "Unknown relation: " + relation + ", expected " + strings.Join(subscriberRelationNames, ", "),
+					code:    "invalid_include",
+				}
+			}
+			if !slices.Contains(p.include, relation) {
+				p.include = append(p.include, relation)
+			}
+		}
+	}
+	for _, field := range p.fields {
+		if _, ok := subscriberRelations[field]; ok && !slices.Contains(p.include, field) {
+			p.include = append(p.include, field)
+		}
+	}
+	return p, nil
+}
+
+// projectionError is an unknown ?fields= (code invalid_fields) or ?include= (code
+// invalid_include) name
+type projectionError struct {
+	message string
+	code    string
+}
+
+func (e *projectionError) Error() string {
+	return e.message
+}
+
+// invalidProjection writes the 400 of a projectionError
+func invalidProjection(c *fiber.Ctx, err error) error {
+	var invalid *projectionError
+	errors.As(err, &invalid)
+	return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": invalid.message, "code": invalid.code})
+}
+
+// whole reports whether the whole record with its subscriber_types, as cached, is returned
+func (p subscriberProjection) whole() bool {
+	return p.fields == nil && slices.Equal(p.include, []string{"subscriber_types"})
+}
+
+// cached reports whether a cached record, which only embeds subscriber_types, holds all
+// that's asked for
+func (p subscriberProjection) cached() bool {
+	for _, relation := range p.include {
+		if relation != "subscriber_types" {
+			return false
+		}
+	}
+	return true
+}
+
+// columns are the subscribers columns the fields are read from
+func (p subscriberProjection) columns() []string {
+	if p.fields == nil {
+		return []string{"subscribers.*"}
+	}
+	columns := slices.Clone(subscriberKeyColumns)
+	for _, field := range p.fields {
+		if column, ok := subscriberFieldColumns[field]; ok && !slices.Contains(columns, column) {
+			columns = append(columns, column)
+		}
+	}
+	for i, column := range columns {
+		columns[i] = "subscribers." + column
+	}
+	return columns
+}
+
+// scope narrows a subscriber query to the columns the fields are read from
+func (p subscriberProjection) scope(db *gorm.DB) *gorm.DB {
+	if p.fields == nil {
+		return db
+	}
+	return db.Select(p.columns())
+}
+
+// load reads the relations included of subs, one query per relation (and relationBatchSize
+// subscribers) rather than per subscriber
+func (p subscriberProjection) load(db *gorm.DB, subs []models.Subscriber) error {
+	for _, relation := range p.include {
+		if err := subscriberRelations[relation](db, subs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// keys are the JSON names returned: the fields asked for (all but the relations by default)
+// followed by the relations included
+func (p subscriberProjection) keys() []string {
+	keys := slices.Clone(p.fields)
+	if keys == nil {
+		for _, field := range dto.SubscriberFields {
+			if _, ok := subscriberRelations[field]; !ok {
+				keys = append(keys, field)
+			}
+		}
+	}
+	for _, relation := range p.include {
+		if !slices.Contains(keys, relation) {
+			keys = append(keys, relation)
+		}
+	}
+	return keys
+}
+
+// one keeps the part of resp asked for
+func (p subscriberProjection) one(resp dto.SubscriberResponse) interface{} {
+	if p.whole() {
+		return resp
+	}
+	return resp.Fields(p.keys())
+}
+
+// many keeps the part of every response asked for
+func (p subscriberProjection) many(resp []dto.SubscriberResponse) interface{} {
+	if p.whole() {
+		return resp
+	}
+	keys := p.keys()
+	out := make([]map[string]interface{}, len(resp))
+	for i, r := range resp {
+		out[i] = r.Fields(keys)
+	}
+	return out
+}
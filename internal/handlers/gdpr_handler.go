@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"fiber-gorm-api/internal/cache"
+	database "fiber-gorm-api/internal/db"
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/models"
+	"fiber-gorm-api/internal/outbox"
+	"fiber-gorm-api/internal/repository"
+	"fiber-gorm-api/internal/session"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// anonymizedEmail is the placeholder address an anonymized subscriber keeps (the column is NOT NULL)
+func anonymizedEmail(id uint) string {
+	return fmt.Sprintf("anonymized-%d@anonymized.invalid", id)
+}
+
+// ExportSubscriberData godoc
+// @Summary      GDPR data export
+// @Description  Returns a JSON archive of all data held about a subscriber: the record, its subscriber_types, registered passkeys, active sessions, email delivery history, the emails sent to them and admin notes. Requires the pii scope.
+// @Tags         subscribers
+// @Produce      json
+// @Param        id   path      int true "Subscriber ID"
+// @Success      200  {object}  dto.GDPRExport
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      403  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/subscribers/{id}/gdpr-export [get]
+func ExportSubscriberData(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		db := database.Replica(db.WithContext(c.UserContext()))
+		id, err := strconv.Atoi(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid subscriber ID"})
+		}
+
+		var subscriber models.Subscriber
+		if err := db.Scopes(orgScope(c)).Preload("SubscriberTypes").First(&subscriber, id).Error; err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Subscriber not found"})
+		}
+
+		var credentials []models.WebAuthnCredential
+		if err := db.Where("email = ?", subscriber.Email).Find(&credentials).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not load passkeys"})
+		}
+		passkeys := make([]dto.PasskeySummary, len(credentials))
+		for i, cred := range credentials {
+			passkeys[i] = dto.PasskeySummary{ID: cred.ID, CreatedAt: cred.CreatedAt}
+		}
+
+		var events []models.DeliveryEvent
+		if err := db.Where("subscriber_id = ?", subscriber.ID).Order("occurred_at DESC").Find(&events).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not load delivery events"})
+		}
+
+		var logs []models.EmailLog
+		if err := db.Where("LOWER(to_email) = LOWER(?)", subscriber.Email).Order("created_at DESC").Order("id DESC").Find(&logs).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not load emails"})
+		}
+
+		var sends []models.CampaignSend
+		if err := db.Where("subscriber_id = ?", subscriber.ID).Order("created_at DESC").Order("id DESC").Find(&sends).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not load campaign emails"})
+		}
+
+		var notes []models.SubscriberNote
+		if err := db.Where("subscriber_id = ?", subscriber.ID).Order("created_at DESC").Find(&notes).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not load notes"})
+		}
+
+		sessions, err := session.ListForEmail(c.UserContext(), subscriber.Email)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not load sessions"})
+		}
+
+		c.Attachment(fmt.Sprintf("subscriber-%d-export.json", subscriber.ID))
+		return c.JSON(dto.GDPRExport{
+			GeneratedAt:   time.Now().UTC(),
+			Subscriber:    dto.NewSubscriberResponse(subscriber),
+			Passkeys:      passkeys,
+			Sessions:      dto.NewSessionResponses(sessions, ""),
+			Deliveries:    dto.NewDeliveryEventResponses(events),
+			Emails:        dto.NewEmailLogResponses(logs),
+			CampaignSends: dto.NewCampaignSendResponses(sends),
+			Notes:         dto.NewSubscriberNoteResponses(notes),
+		})
+	}
+}
+
+// AnonymizeSubscriber godoc
+// @Summary      Anonymize a subscriber (right to be forgotten)
+// @Description  Irreversibly scrubs the subscriber's email, name and metadata and the address of the emails sent to them, deletes their passkeys, trusted devices, sessions, delivery history, notes and revision history. The record and its subscriber_types are kept so aggregate stats stay correct.
+// @Tags         subscribers
+// @Produce      json
+// @Param        id   path      int true "Subscriber ID"
+// @Success      200  {object}  dto.SubscriberResponse
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      409  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/subscribers/{id}/anonymize [post]
+func AnonymizeSubscriber(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		id, err := strconv.Atoi(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid subscriber ID"})
+		}
+
+		var subscriber models.Subscriber
+		if err := db.Scopes(orgScope(c)).First(&subscriber, id).Error; err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Subscriber not found"})
+		}
+		if subscriber.AnonymizedAt != nil {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Subscriber is already anonymized"})
+		}
+		originalEmail := subscriber.Email
+
+		now := time.Now()
+		err = database.Tx(c.UserContext(), db, func(tx *gorm.DB) error {
+			if err := tx.Where("email = ?", originalEmail).Delete(&models.WebAuthnCredential{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("email = ?", originalEmail).Delete(&models.TrustedDevice{}).Error; err != nil {
+				return err
+			}
+			// bounce reasons quote the address
+			if err := tx.Where("subscriber_id = ?", subscriber.ID).Delete(&models.DeliveryEvent{}).Error; err != nil {
+				return err
+			}
+			// the email log and campaign sends keep their entries for the stats, without the
+			// address or the provider's error quoting it
+			if err := tx.Model(&models.EmailLog{}).Where("LOWER(to_email) = LOWER(?)", originalEmail).
+				Updates(map[string]interface{}{"to_email": anonymizedEmail(subscriber.ID), "error": ""}).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&models.CampaignSend{}).Where("subscriber_id = ?", subscriber.ID).
+				Update("email", anonymizedEmail(subscriber.ID)).Error; err != nil {
+				return err
+			}
+			// notes and metadata are free text about the person, revisions snapshots of it
+			if err := tx.Where("subscriber_id = ?", subscriber.ID).Delete(&models.SubscriberNote{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("subscriber_id = ?", subscriber.ID).Delete(&models.SubscriberRevision{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&subscriber).Updates(map[string]interface{}{
+				"email":         anonymizedEmail(subscriber.ID),
+				"name":          "",
+				"metadata":      models.JSONMap{},
+				"anonymized_at": now,
+				"status":        models.SubscriberStatusUnsubscribed,
+				"version":       gorm.Expr("version + 1"),
+			}).Error; err != nil {
+				return err
+			}
+			if err := repository.RecordRevision(c.UserContext(), tx, subscriber.ID, models.RevisionAnonymized, nil); err != nil {
+				return err
+			}
+			return outbox.EnqueueSubscriber(tx, outbox.TopicSubscriberUpdated, &subscriber)
+		})
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not anonymize subscriber"})
+		}
+
+		cache.InvalidateSubscriber(c.UserContext(), subscriber.ID)
+
+		// sessions live in Redis, outside the transaction
+		if err := session.RevokeAllForEmail(c.UserContext(), originalEmail); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Subscriber anonymized but sessions could not be revoked"})
+		}
+
+		if err := db.Preload("SubscriberTypes").First(&subscriber, subscriber.ID).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch anonymized subscriber"})
+		}
+		return c.JSON(subscriberResponse(c, subscriber))
+	}
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"time"
+
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/geoip"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// GetGeoStats godoc
+// @Summary      Geographic breakdown
+// @Description  Counts the public signups of the organization by country and by region, and its admin activity by country.
+// @Description  Records are located when written, from the client IP and the MaxMind DB at GEOIP_DB_PATH; those that weren't aren't counted.
+// @Tags         stats
+// @Produce      json
+// @Success      200  {object}  dto.GeoStatsResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/stats/geo [get]
+func GetGeoStats(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		orgID := middleware.CurrentOrgID(c)
+		conn := db.WithContext(c.UserContext())
+		stats := dto.GeoStatsResponse{GeoIPEnabled: geoip.Enabled(), GeneratedAt: time.Now()}
+
+		subscribers := conn.Model(&models.Subscriber{}).Scopes(orgIDScope(orgID))
+		var err error
+		if stats.SignupsByCountry, err = countByLocation(subscribers, false); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not compute stats"})
+		}
+		subscribers = conn.Model(&models.Subscriber{}).Scopes(orgIDScope(orgID))
+		if stats.SignupsByRegion, err = countByLocation(subscribers, true); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not compute stats"})
+		}
+		activity := conn.Model(&models.AdminActivity{}).Where("org_id = ?", orgID)
+		if stats.ActivityByCountry, err = countByLocation(activity, false); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not compute stats"})
+		}
+		return c.JSON(stats)
+	}
+}
+
+// countByLocation counts the located rows of query per country, or per region of each country
+// with byRegion, most common first
+func countByLocation(query *gorm.DB, byRegion bool) ([]dto.GeoCount, error) {
+	columns := "country"
+	query = query.Where("country <> ''")
+	if byRegion {
+		columns = "country, region"
+		query = query.Where("region <> ''")
+	}
+	counts := []dto.GeoCount{}
+	err := query.Select(columns + ", COUNT(*) AS count").
+		Group(columns).
+		Order("count DESC, " + columns).
+		Scan(&counts).Error
+	return counts, err
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	database "fiber-gorm-api/internal/db"
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/graph"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/repository"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/executor"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// graphResolver resolves the admin GraphQL schema (see graphql_schema.resolvers.go)
+type graphResolver struct {
+	db *gorm.DB
+}
+
+// graphCaller is what the GraphQL resolvers need to know about the caller of a request
+type graphCaller struct {
+	orgID     uint
+	canSeePII bool
+}
+
+type graphCallerKey struct{}
+
+func graphCallerFrom(ctx context.Context) graphCaller {
+	caller, _ := ctx.Value(graphCallerKey{}).(graphCaller)
+	return caller
+}
+
+// subscribers masks PII in already built responses unless the caller may see it
+func (c graphCaller) subscribers(resp []dto.SubscriberResponse) []dto.SubscriberResponse {
+	if !c.canSeePII {
+		return dto.RedactSubscribers(resp)
+	}
+	return resp
+}
+
+// GraphQL godoc
+// @Summary      Admin GraphQL endpoint
+// @Description  Queries subscribers (filterable, cursor paginated, with nested subscriber_types and delivery events), subscriber_type counts and the dashboard stats in one request.
+// @Description  The schema is in internal/graph/schema.graphqls and can be introspected. Results are scoped to the caller's organization; emails are masked without the pii scope.
+// @Description  Query errors are returned in the errors array with status 200, invalid documents with 422.
+// @Tags         graphql
+// @Accept       json
+// @Produce      json
+// @Param        body  body      dto.GraphQLRequest  true  "GraphQL query and variables"
+// @Success      200   {object}  dto.GraphQLResponse
+// @Failure      400   {object}  dto.ErrorResponse
+// @Failure      422   {object}  dto.GraphQLResponse
+// @Router       /admin/graphql [post]
+func GraphQL(db *gorm.DB) fiber.Handler {
+	exec := executor.New(graph.NewExecutableSchema(graph.Config{Resolvers: &graphResolver{db: db}}))
+	exec.Use(extension.Introspection{})
+
+	return func(c *fiber.Ctx) error {
+		start := graphql.Now()
+		var req dto.GraphQLRequest
+		if err := c.BodyParser(&req); err != nil || strings.TrimSpace(req.Query) == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing query"})
+		}
+		params := graphql.RawParams{
+			Query:         req.Query,
+			OperationName: req.OperationName,
+			Variables:     req.Variables,
+			ReadTime:      graphql.TraceTiming{Start: start, End: graphql.Now()},
+		}
+
+		ctx := context.WithValue(c.UserContext(), graphCallerKey{}, graphCaller{
+			orgID:     middleware.CurrentOrgID(c),
+			canSeePII: middleware.CanSeePII(c),
+		})
+		ctx = graphql.StartOperationTrace(ctx)
+
+		opCtx, errs := exec.CreateOperationContext(ctx, &params)
+		if errs != nil {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(exec.DispatchError(graphql.WithOperationContext(ctx, opCtx), errs))
+		}
+		responses, ctx := exec.DispatchOperation(ctx, opCtx)
+		return c.JSON(responses(ctx))
+	}
+}
+
+// filterSubscribers applies a GraphQL subscriber filter to query
+func filterSubscribers(query *gorm.DB, filter graph.SubscriberFilter) (*gorm.DB, error) {
+	verified := ""
+	if filter.Verified != nil {
+		verified = strconv.FormatBool(*filter.Verified)
+	}
+	scope, err := repository.StatusFilter(filter.Status, verified)
+	if err != nil {
+		return nil, err
+	}
+	query = query.Scopes(scope)
+
+	if filter.SubscriberType != nil {
+		query = query.Where("subscribers.id IN (SELECT subscriber_id FROM subscriber_types WHERE name = ?)", *filter.SubscriberType)
+	}
+	if filter.Search != nil {
+		if q := strings.TrimSpace(*filter.Search); q != "" {
+			pattern := "%" + likeEscaper.Replace(q) + "%"
+			query = query.Where(database.ILike(query, "subscribers.email")+" OR "+database.ILike(query, "subscribers.name"), pattern, pattern)
+		}
+	}
+	if filter.CreatedAfter != nil {
+		query = query.Where("subscribers.created_at >= ?", *filter.CreatedAfter)
+	}
+	if filter.CreatedBefore != nil {
+		query = query.Where("subscribers.created_at < ?", *filter.CreatedBefore)
+	}
+	return query, nil
+}
@@ -0,0 +1,130 @@
+package handlers
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.55
+
+import (
+	"context"
+	"errors"
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/graph"
+	"fiber-gorm-api/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// Subscribers is the resolver for the subscribers field.
+func (r *queryGraphResolver) Subscribers(ctx context.Context, filter *graph.SubscriberFilter, first *int, after *string) (*graph.SubscriberConnection, error) {
+	caller := graphCallerFrom(ctx)
+
+	limit := 0
+	if first != nil {
+		if *first < 1 {
+			return nil, errors.New("Invalid first")
+		}
+		limit = *first
+	}
+	cursor := ""
+	if after != nil {
+		cursor = *after
+	}
+	page, err := pageParamsFor(limit, cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	query := r.db.WithContext(ctx).Scopes(orgIDScope(caller.orgID))
+	if filter != nil {
+		if query, err = filterSubscribers(query, *filter); err != nil {
+			return nil, err
+		}
+	}
+
+	var subscribers []models.Subscriber
+	if err := page.apply(query.Preload("SubscriberTypes")).Find(&subscribers).Error; err != nil {
+		return nil, errors.New("Could not retrieve subscribers")
+	}
+
+	conn := &graph.SubscriberConnection{}
+	if len(subscribers) > page.Limit {
+		subscribers = subscribers[:page.Limit]
+		last := subscribers[len(subscribers)-1]
+		next := page.nextCursor(last.ID, last.CreatedAt, last.UpdatedAt)
+		conn.NextCursor = &next
+	}
+	conn.Nodes = caller.subscribers(dto.NewSubscriberResponses(subscribers))
+	return conn, nil
+}
+
+// Subscriber is the resolver for the subscriber field.
+func (r *queryGraphResolver) Subscriber(ctx context.Context, id uint) (*dto.SubscriberResponse, error) {
+	caller := graphCallerFrom(ctx)
+	var subscriber models.Subscriber
+	err := r.db.WithContext(ctx).Scopes(orgIDScope(caller.orgID)).Preload("SubscriberTypes").First(&subscriber, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.New("Could not retrieve subscriber")
+	}
+	resp := caller.subscribers([]dto.SubscriberResponse{dto.NewSubscriberResponse(subscriber)})[0]
+	return &resp, nil
+}
+
+// SubscriberTypes is the resolver for the subscriberTypes field.
+func (r *queryGraphResolver) SubscriberTypes(ctx context.Context) ([]dto.TypeCount, error) {
+	counts, err := countByType(r.db.WithContext(ctx), graphCallerFrom(ctx).orgID)
+	if err != nil {
+		return nil, errors.New("Could not count subscriber_types")
+	}
+	return counts, nil
+}
+
+// Stats is the resolver for the stats field.
+func (r *queryGraphResolver) Stats(ctx context.Context) (*dto.StatsResponse, error) {
+	caller := graphCallerFrom(ctx)
+	stats, err := loadStats(ctx, r.db, caller.orgID)
+	if err != nil {
+		return nil, errors.New("Could not compute stats")
+	}
+	stats.RecentSignups = caller.subscribers(stats.RecentSignups)
+	return &stats, nil
+}
+
+// DeliveryEvents is the resolver for the deliveryEvents field.
+func (r *subscriberGraphResolver) DeliveryEvents(ctx context.Context, obj *dto.SubscriberResponse, first *int) ([]dto.DeliveryEventResponse, error) {
+	limit := defaultPageLimit
+	if first != nil {
+		if *first < 1 {
+			return nil, errors.New("Invalid first")
+		}
+		limit = min(*first, maxPageLimit)
+	}
+
+	// obj was loaded scoped to the caller's organization
+	var events []models.DeliveryEvent
+	if err := r.db.WithContext(ctx).Where("subscriber_id = ?", obj.ID).
+		Order("occurred_at DESC").Order("id DESC").
+		Limit(limit).
+		Find(&events).Error; err != nil {
+		return nil, errors.New("Could not retrieve delivery events")
+	}
+
+	resp := dto.NewDeliveryEventResponses(events)
+	if !graphCallerFrom(ctx).canSeePII {
+		for i := range resp {
+			resp[i] = resp[i].Redacted()
+		}
+	}
+	return resp, nil
+}
+
+// Query returns graph.QueryResolver implementation.
+func (r *graphResolver) Query() graph.QueryResolver { return &queryGraphResolver{r} }
+
+// Subscriber returns graph.SubscriberResolver implementation.
+func (r *graphResolver) Subscriber() graph.SubscriberResolver { return &subscriberGraphResolver{r} }
+
+type queryGraphResolver struct{ *graphResolver }
+type subscriberGraphResolver struct{ *graphResolver }
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	mylopb "fiber-gorm-api/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// AuthGRPC serves mylopb.AuthService, the email code sign-in of /signin
+type AuthGRPC struct {
+	mylopb.UnimplementedAuthServiceServer
+	signin *SigninHandler
+}
+
+// NewAuthGRPC returns the gRPC sign-in service signing in like signin
+func NewAuthGRPC(signin *SigninHandler) *AuthGRPC {
+	return &AuthGRPC{signin: signin}
+}
+
+func (a *AuthGRPC) RequestSignIn(ctx context.Context, req *mylopb.RequestSignInRequest) (*mylopb.RequestSignInResponse, error) {
+	start := a.signin.clock.Now()
+	if req.GetEmail() == "" {
+		return nil, status.Error(codes.InvalidArgument, "Missing email")
+	}
+	if wait, err := a.signin.throttleSignInRequest(ctx, signInChannelEmail, req.GetEmail()); err != nil {
+		return nil, status.Error(codes.Internal, "Unable to record sign-in request")
+	} else if wait > 0 {
+		return nil, status.Error(codes.ResourceExhausted, "Too many sign-in codes requested, please try again later")
+	}
+	// unknown addresses get the same answer, as late as known ones, without the email
+	if signInKnownOnly() {
+		defer a.signin.waitResponseFloor(ctx, start)
+		known, err := signInAddressKnown(a.signin.db.WithContext(ctx), req.GetEmail())
+		if err != nil {
+			return nil, status.Error(codes.Internal, "Unable to check address")
+		}
+		if !known {
+			return &mylopb.RequestSignInResponse{}, nil
+		}
+	}
+	if err := a.signin.sendSignInCode(ctx, signInChannelEmail, req.GetEmail()); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &mylopb.RequestSignInResponse{}, nil
+}
+
+func (a *AuthGRPC) VerifySignIn(ctx context.Context, req *mylopb.VerifySignInRequest) (*mylopb.VerifySignInResponse, error) {
+	if req.GetEmail() == "" || req.GetCode() == "" {
+		return nil, status.Error(codes.InvalidArgument, "Missing email or code")
+	}
+
+	err := a.signin.checkSignInCode(ctx, req.GetEmail(), req.GetCode())
+	a.signin.countFailedSignIn(ctx, err)
+	switch {
+	case errors.Is(err, errSignInLocked), errors.Is(err, errSignInTooManyAttempts):
+		return nil, status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, errSignInCodeMissing):
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, errSignInCodeInvalid):
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	case err != nil:
+		return nil, status.Error(codes.Internal, "Unable to record failed attempt")
+	}
+
+	ip := ""
+	if p, ok := peer.FromContext(ctx); ok {
+		ip = p.Addr.String()
+		if host, _, err := net.SplitHostPort(ip); err == nil {
+			ip = host
+		}
+	}
+	userAgent := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("user-agent"); len(values) > 0 {
+			userAgent = values[0]
+		}
+	}
+
+	token, err := a.signin.tokens.Issue(ctx, req.GetEmail(), ip, userAgent)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &mylopb.VerifySignInResponse{Token: token}, nil
+}
@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
+	"fiber-gorm-api/internal/repository"
+	"fiber-gorm-api/internal/service"
+	mylopb "fiber-gorm-api/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gorm.io/gorm"
+)
+
+// SubscriberGRPC serves mylopb.SubscriberService with the same validation, org scoping,
+// outbox events and PII masking as the /admin/subscribers routes
+type SubscriberGRPC struct {
+	mylopb.UnimplementedSubscriberServiceServer
+	db  *gorm.DB
+	svc service.SubscriberService
+}
+
+// NewSubscriberGRPC returns the gRPC subscriber service backed by db
+func NewSubscriberGRPC(db *gorm.DB) *SubscriberGRPC {
+	return &SubscriberGRPC{db: db, svc: subscriberService(db)}
+}
+
+func (s *SubscriberGRPC) CreateSubscriber(ctx context.Context, req *mylopb.CreateSubscriberRequest) (*mylopb.Subscriber, error) {
+	caller := middleware.CallerFromContext(ctx)
+	subscriber, err := s.svc.Create(ctx, service.CreateSubscriberInput{
+		OrgID:           caller.OrgID(),
+		Email:           req.GetEmail(),
+		Name:            req.GetName(),
+		SubscriberTypes: grpcSubscriberTypes(req.GetSubscriberTypes()),
+	})
+	if err != nil {
+		return nil, grpcSubscriberError(err, "Could not create subscriber")
+	}
+	return grpcSubscriber(caller, *subscriber), nil
+}
+
+func (s *SubscriberGRPC) GetSubscriber(ctx context.Context, req *mylopb.GetSubscriberRequest) (*mylopb.Subscriber, error) {
+	caller := middleware.CallerFromContext(ctx)
+	subscriber, err := s.svc.Get(ctx, caller.OrgID(), uint(req.GetId()))
+	if err != nil {
+		return nil, grpcSubscriberError(err, "Could not retrieve subscriber")
+	}
+	return grpcSubscriber(caller, *subscriber), nil
+}
+
+func (s *SubscriberGRPC) ListSubscribers(ctx context.Context, req *mylopb.ListSubscribersRequest) (*mylopb.ListSubscribersResponse, error) {
+	caller := middleware.CallerFromContext(ctx)
+	page, err := pageParamsFor(int(req.GetLimit()), req.GetCursor())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	filter, err := repository.StatusFilter(req.GetStatus(), "")
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	var subscribers []models.Subscriber
+	if err := page.apply(s.db.WithContext(ctx).Scopes(orgIDScope(caller.OrgID()), filter).Preload("SubscriberTypes")).Find(&subscribers).Error; err != nil {
+		return nil, status.Error(codes.Internal, "Could not retrieve subscribers")
+	}
+
+	resp := &mylopb.ListSubscribersResponse{}
+	if len(subscribers) > page.Limit {
+		subscribers = subscribers[:page.Limit]
+		last := subscribers[len(subscribers)-1]
+		resp.NextCursor = page.nextCursor(last.ID, last.CreatedAt, last.UpdatedAt)
+	}
+	for _, subscriber := range subscribers {
+		resp.Subscribers = append(resp.Subscribers, grpcSubscriber(caller, subscriber))
+	}
+	return resp, nil
+}
+
+func (s *SubscriberGRPC) UpdateSubscriber(ctx context.Context, req *mylopb.UpdateSubscriberRequest) (*mylopb.Subscriber, error) {
+	caller := middleware.CallerFromContext(ctx)
+	in := service.UpdateSubscriberInput{
+		OrgID: caller.OrgID(),
+		ID:    uint(req.GetId()),
+		Email: req.GetEmail(),
+		Name:  req.GetName(),
+	}
+	if req.Version != nil {
+		version := int(req.GetVersion())
+		in.Version = &version
+	}
+	if req.GetReplaceSubscriberTypes() {
+		in.SubscriberTypes = grpcSubscriberTypes(req.GetSubscriberTypes())
+		if in.SubscriberTypes == nil {
+			in.SubscriberTypes = []models.SubscriberType{}
+		}
+	}
+
+	subscriber, err := s.svc.Update(ctx, in)
+	if err != nil {
+		return nil, grpcSubscriberError(err, "Could not update subscriber")
+	}
+	return grpcSubscriber(caller, *subscriber), nil
+}
+
+func (s *SubscriberGRPC) DeleteSubscriber(ctx context.Context, req *mylopb.DeleteSubscriberRequest) (*mylopb.DeleteSubscriberResponse, error) {
+	caller := middleware.CallerFromContext(ctx)
+	if err := s.svc.Delete(ctx, caller.OrgID(), uint(req.GetId())); err != nil {
+		return nil, grpcSubscriberError(err, "Could not delete subscriber")
+	}
+	return &mylopb.DeleteSubscriberResponse{}, nil
+}
+
+// grpcSubscriberError maps a service error onto a gRPC status, internal failures onto msg
+func grpcSubscriberError(err error, msg string) error {
+	var invalid *service.ValidationError
+	switch {
+	case errors.As(err, &invalid):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, service.ErrNotFound):
+		return status.Error(codes.NotFound, "Subscriber not found")
+	case errors.Is(err, service.ErrVersionConflict):
+		return status.Error(codes.Aborted, "Subscriber was modified by someone else")
+	default:
+		return status.Error(codes.Internal, msg)
+	}
+}
+
+// grpcSubscriberTypes maps subscriber_type names onto models, skipping blank ones
+func grpcSubscriberTypes(names []string) []models.SubscriberType {
+	var types []models.SubscriberType
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			types = append(types, models.SubscriberType{Name: name})
+		}
+	}
+	return types
+}
+
+// grpcSubscriber serializes a subscriber for the caller, masking PII unless it may see it
+func grpcSubscriber(caller middleware.Caller, subscriber models.Subscriber) *mylopb.Subscriber {
+	resp := dto.NewSubscriberResponse(subscriber)
+	if !caller.CanSeePII() {
+		resp = resp.Redacted()
+	}
+
+	out := &mylopb.Subscriber{
+		Id:              uint64(resp.ID),
+		OrgId:           uint64(resp.OrgID),
+		Email:           resp.Email,
+		Name:            resp.Name,
+		Status:          resp.Status,
+		EmailVerifiedAt: grpcTimestamp(resp.EmailVerifiedAt),
+		AnonymizedAt:    grpcTimestamp(resp.AnonymizedAt),
+		Version:         int32(resp.Version),
+		CreatedAt:       timestamppb.New(resp.CreatedAt),
+		UpdatedAt:       timestamppb.New(resp.UpdatedAt),
+	}
+	for _, t := range resp.SubscriberTypes {
+		out.SubscriberTypes = append(out.SubscriberTypes, &mylopb.SubscriberType{Id: uint64(t.ID), Name: t.Name})
+	}
+	return out
+}
+
+func grpcTimestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/imports"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// importResponse describes job, with a fresh error report link once it's finished with errors
+func importResponse(c *fiber.Ctx, job models.ImportJob) (dto.ImportJobResponse, error) {
+	resp := dto.NewImportJobResponse(job)
+	if job.ErrorReport != "" {
+		url, expires, err := imports.ErrorReportURL(c.UserContext(), &job, time.Now())
+		if err != nil {
+			return resp, err
+		}
+		resp.ErrorReportURL, resp.ErrorReportExpiresAt = url, &expires
+	}
+	return resp, nil
+}
+
+// CreateImport godoc
+// @Summary      Import subscribers
+// @Description  Uploads a CSV or JSON file of subscribers for the import worker to apply in the background: a row whose email is already a subscriber of the organization updates it, any other creates an active subscriber. Poll GET /admin/imports/{id} for the progress.
+// @Description  CSV files need a header with an email column; name, locale and subscriber_types (name:frequency:channel triplets separated by semicolons) are optional and other columns are ignored. JSON files are an array of objects like the body of POST /admin/subscribers. Exports can be imported back as they are.
+// @Description  Files are at most IMPORT_MAX_UPLOAD_MB (20 by default). Imports write raw emails, so this needs the pii scope.
+// @Tags         imports
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        file    formData  file    true   "The subscribers"
+// @Param        format  formData  string  false  "csv or json, from the file extension if empty"
+// @Success      202     {object}  dto.ImportJobResponse
+// @Failure      400     {object}  dto.ErrorResponse
+// @Failure      403     {object}  dto.ErrorResponse
+// @Failure      413     {object}  dto.ErrorResponse
+// @Failure      500     {object}  dto.ErrorResponse
+// @Router       /admin/imports [post]
+func CreateImport(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		header, err := c.FormFile("file")
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing file, upload it as the file field of a multipart form"})
+		}
+		if header.Size > int64(imports.MaxUploadBytes()) {
+			return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
+				"error": fmt.Sprintf("File is too large, at most %d MB", imports.MaxUploadBytes()>>20),
+			})
+		}
+		format := c.FormValue("format")
+		if format == "" {
+			format = imports.FormatOf(header.Filename)
+		}
+		if !slices.Contains(imports.Formats, format) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid format, expected csv or json"})
+		}
+
+		file, err := header.Open()
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Could not read the file"})
+		}
+		defer file.Close()
+
+		job := models.ImportJob{
+			OrgID:       middleware.CurrentOrgID(c),
+			Format:      format,
+			FileName:    filepath.Base(header.Filename),
+			RequestedBy: callerIdentity(c),
+		}
+		if err := imports.Enqueue(c.UserContext(), db, &job, file, header.Size); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not create import"})
+		}
+		resp, _ := importResponse(c, job)
+		c.Location("/admin/imports/" + strconv.FormatUint(uint64(job.ID), 10))
+		return c.Status(fiber.StatusAccepted).JSON(resp)
+	}
+}
+
+// GetImport godoc
+// @Summary      Get an import
+// @Description  Returns the progress of an import: total_rows once the file is read through, then processed_rows, created_rows, updated_rows and failed_rows, saved after each batch of IMPORT_BATCH_SIZE rows (500 by default).
+// @Description  Once finished with failed rows, error_report_url is a signed link to a CSV of them (row, email, error) valid for STORAGE_LINK_TTL_MINUTES (60 by default); every call returns a fresh one. The job and its report are deleted IMPORT_TTL_HOURS (72 by default) after it finished.
+// @Tags         imports
+// @Produce      json
+// @Param        id   path      int  true  "Import ID"
+// @Success      200  {object}  dto.ImportJobResponse
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      403  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/imports/{id} [get]
+func GetImport(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := strconv.Atoi(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid import ID"})
+		}
+
+		var job models.ImportJob
+		err = db.WithContext(c.UserContext()).Scopes(orgScope(c)).First(&job, id).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Import not found"})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve import"})
+		}
+		resp, err := importResponse(c, job)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not sign the error report link"})
+		}
+		return c.JSON(resp)
+	}
+}
+
+// DownloadImportErrors godoc
+// @Summary      Download the error report of an import
+// @Description  Sends the CSV of the rows of an import that failed (row, email, error), kept on local storage. No sign in: the signed link from GET /admin/imports/{id} is the credential, until it expires.
+// @Tags         imports
+// @Produce      text/csv
+// @Param        id         path      int     true  "Import ID"
+// @Param        expires    query     int     true  "Link expiry (Unix time), from error_report_url"
+// @Param        signature  query     string  true  "Link signature, from error_report_url"
+// @Success      200  {file}    file
+// @Failure      403  {object}  dto.ErrorResponse  "code: invalid_signature, also once the link expired"
+// @Failure      404  {object}  dto.ErrorResponse  "The file was deleted"
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/imports/{id}/errors [get]
+func DownloadImportErrors(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var job models.ImportJob
+		id, _ := strconv.Atoi(c.Params("id"))
+		err := db.WithContext(c.UserContext()).First(&job, id).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve import"})
+		}
+		return sendSignedFile(c, job.ErrorReport, imports.ReportFileName(&job))
+	}
+}
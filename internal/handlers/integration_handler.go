@@ -0,0 +1,271 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"slices"
+	"strings"
+
+	database "fiber-gorm-api/internal/db"
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/integrations"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
+	"fiber-gorm-api/internal/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// integrationResponse describes integration without its API key
+func integrationResponse(integration models.Integration) dto.IntegrationResponse {
+	return dto.NewIntegrationResponse(integration, integrations.KeyHint(integration.APIKey))
+}
+
+// findIntegration loads the integration of the caller's organization with the :provider param,
+// with its lists, or writes the 404/500 and returns nil
+func findIntegration(c *fiber.Ctx, db *gorm.DB) (*models.Integration, error) {
+	var integration models.Integration
+	err := db.WithContext(c.UserContext()).Scopes(orgScope(c)).
+		Preload("Lists", func(tx *gorm.DB) *gorm.DB { return tx.Order("id") }).
+		Where("provider = ?", c.Params("provider")).
+		First(&integration).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Integration not found"})
+	}
+	if err != nil {
+		return nil, c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve integration"})
+	}
+	return &integration, nil
+}
+
+// GetIntegrations godoc
+// @Summary      List integrations
+// @Description  Lists the mailing list providers the organization is connected to, with the lists kept in sync. API keys are never returned, only their last characters.
+// @Tags         integrations
+// @Produce      json
+// @Success      200  {array}   dto.IntegrationResponse
+// @Failure      403  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/integrations [get]
+func GetIntegrations(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var all []models.Integration
+		if err := db.WithContext(c.UserContext()).Scopes(orgScope(c)).
+			Preload("Lists", func(tx *gorm.DB) *gorm.DB { return tx.Order("id") }).
+			Order("provider").Find(&all).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve integrations"})
+		}
+		resp := make([]dto.IntegrationResponse, len(all))
+		for i, integration := range all {
+			resp[i] = integrationResponse(integration)
+		}
+		return c.JSON(resp)
+	}
+}
+
+// GetIntegration godoc
+// @Summary      Get an integration
+// @Description  Returns the integration with a provider, the lists kept in sync and the outcome of the last sync.
+// @Tags         integrations
+// @Produce      json
+// @Param        provider  path      string  true  "Provider"  Enums(mailchimp)
+// @Success      200       {object}  dto.IntegrationResponse
+// @Failure      403       {object}  dto.ErrorResponse
+// @Failure      404       {object}  dto.ErrorResponse
+// @Failure      500       {object}  dto.ErrorResponse
+// @Router       /admin/integrations/{provider} [get]
+func GetIntegration(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		integration, err := findIntegration(c, db)
+		if integration == nil {
+			return err
+		}
+		return c.JSON(integrationResponse(*integration))
+	}
+}
+
+// PutIntegration godoc
+// @Summary      Connect a provider
+// @Description  Connects the organization to its account at a mailing list provider, or changes the API key or pauses the sync (enabled=false) of an existing integration. A new API key is checked with the provider first.
+// @Description  The sync worker runs on INTEGRATIONS_SYNC_SCHEDULE (every 15 minutes by default) once lists are mapped with PUT /admin/integrations/{provider}/lists.
+// @Tags         integrations
+// @Accept       json
+// @Produce      json
+// @Param        provider  path      string                        true  "Provider"  Enums(mailchimp)
+// @Param        body      body      dto.UpdateIntegrationRequest  true  "API key and whether the sync runs"
+// @Success      200       {object}  dto.IntegrationResponse
+// @Failure      400       {object}  dto.ErrorResponse  "code: invalid_credentials when the provider rejects the API key"
+// @Failure      403       {object}  dto.ErrorResponse
+// @Failure      404       {object}  dto.ErrorResponse  "Unknown provider"
+// @Failure      500       {object}  dto.ErrorResponse
+// @Failure      502       {object}  dto.ErrorResponse  "code: provider_unavailable"
+// @Router       /admin/integrations/{provider} [put]
+func PutIntegration(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		provider := c.Params("provider")
+		if !integrations.IsProvider(provider) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Unknown provider"})
+		}
+		var req dto.UpdateIntegrationRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Unable to parse request body"})
+		}
+		req.APIKey = strings.TrimSpace(req.APIKey)
+
+		integration := models.Integration{OrgID: middleware.CurrentOrgID(c), Provider: provider, Enabled: true, CreatedBy: callerIdentity(c)}
+		err := db.Scopes(orgScope(c)).Where("provider = ?", provider).First(&integration).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve integration"})
+		}
+		if integration.ID == 0 && req.APIKey == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing api_key"})
+		}
+
+		if req.APIKey != "" {
+			connector, err := integrations.NewConnector(provider, req.APIKey)
+			if err == nil {
+				err = connector.Ping(c.UserContext())
+			}
+			if errors.Is(err, integrations.ErrInvalidCredentials) {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error(), "code": "invalid_credentials"})
+			}
+			if err != nil {
+				log.Printf("[WARN] Integrations: checking a %s API key failed: %v", provider, err)
+				return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{
+					"error": "Could not reach the provider to check the API key, please try again",
+					"code":  "provider_unavailable",
+				})
+			}
+			integration.APIKey = req.APIKey
+		}
+		if req.Enabled != nil {
+			integration.Enabled = *req.Enabled
+		}
+		if integration.ID == 0 {
+			err = db.Create(&integration).Error
+		} else {
+			// not Save: a sync running meanwhile owns syncing_since and last_*
+			err = db.Model(&integration).Updates(map[string]interface{}{"api_key": integration.APIKey, "enabled": integration.Enabled}).Error
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not save integration"})
+		}
+		if err := db.Where("integration_id = ?", integration.ID).Order("id").Find(&integration.Lists).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve integration"})
+		}
+		return c.JSON(integrationResponse(integration))
+	}
+}
+
+// PutIntegrationLists godoc
+// @Summary      Map the lists of an integration
+// @Description  Replaces the lists kept in sync with the provider. Each gets the active subscribers with its subscriber_type, or all active subscribers without one; a list mapped before keeps how far its sync got, a new one starts with every subscriber.
+// @Description  Unsubscribes from a list at the provider unsubscribe the subscribers of the organization with the address. An empty array stops the sync.
+// @Tags         integrations
+// @Accept       json
+// @Produce      json
+// @Param        provider  path      string                             true  "Provider"  Enums(mailchimp)
+// @Param        body      body      dto.UpdateIntegrationListsRequest  true  "Lists"
+// @Success      200       {object}  dto.IntegrationResponse
+// @Failure      400       {object}  dto.ErrorResponse
+// @Failure      403       {object}  dto.ErrorResponse
+// @Failure      404       {object}  dto.ErrorResponse
+// @Failure      422       {object}  dto.ErrorResponse  "code: invalid_subscriber_type"
+// @Failure      500       {object}  dto.ErrorResponse
+// @Router       /admin/integrations/{provider}/lists [put]
+func PutIntegrationLists(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		var req dto.UpdateIntegrationListsRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Unable to parse request body"})
+		}
+		integration, err := findIntegration(c, db)
+		if integration == nil {
+			return err
+		}
+
+		names, err := repository.NewSubscriberRepository(db).TypeNames(c.UserContext())
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not check subscriber_types"})
+		}
+		seen := map[dto.IntegrationListRequest]bool{}
+		for i, l := range req.Lists {
+			l.RemoteID = strings.TrimSpace(l.RemoteID)
+			req.Lists[i] = l
+			if l.RemoteID == "" {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing remote_id"})
+			}
+			if seen[l] {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "List " + l.RemoteID + " is mapped twice"})
+			}
+			seen[l] = true
+			if l.SubscriberType != "" && !slices.Contains(names, l.SubscriberType) {
+				return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+					"error": "Unknown subscriber_type " + l.SubscriberType,
+					"code":  "invalid_subscriber_type",
+				})
+			}
+		}
+
+		// mappings kept as they were keep their sync cursors
+		err = database.Tx(c.UserContext(), db, func(tx *gorm.DB) error {
+			for _, existing := range integration.Lists {
+				key := dto.IntegrationListRequest{RemoteID: existing.RemoteID, SubscriberType: existing.SubscriberType}
+				if seen[key] {
+					delete(seen, key)
+					continue
+				}
+				if err := tx.Delete(&existing).Error; err != nil {
+					return err
+				}
+			}
+			for _, l := range req.Lists {
+				if !seen[l] {
+					continue
+				}
+				list := models.IntegrationList{IntegrationID: integration.ID, RemoteID: l.RemoteID, SubscriberType: l.SubscriberType}
+				if err := tx.Create(&list).Error; err != nil {
+					return err
+				}
+			}
+			return tx.Where("integration_id = ?", integration.ID).Order("id").Find(&integration.Lists).Error
+		})
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not save lists"})
+		}
+		return c.JSON(integrationResponse(*integration))
+	}
+}
+
+// DeleteIntegration godoc
+// @Summary      Disconnect a provider
+// @Description  Deletes the integration, its API key and its lists. Nothing is deleted at the provider.
+// @Tags         integrations
+// @Param        provider  path  string  true  "Provider"  Enums(mailchimp)
+// @Success      204  {string}  string
+// @Failure      403  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/integrations/{provider} [delete]
+func DeleteIntegration(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		integration, err := findIntegration(c, db)
+		if integration == nil {
+			return err
+		}
+		err = database.Tx(c.UserContext(), db, func(tx *gorm.DB) error {
+			if err := tx.Where("integration_id = ?", integration.ID).Delete(&models.IntegrationList{}).Error; err != nil {
+				return err
+			}
+			return tx.Delete(integration).Error
+		})
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not delete integration"})
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	}
+}
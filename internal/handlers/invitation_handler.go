@@ -0,0 +1,298 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	database "fiber-gorm-api/internal/db"
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
+	"fiber-gorm-api/internal/notify"
+	"fiber-gorm-api/internal/service"
+	sendgridservice "fiber-gorm-api/internal/services"
+	"fiber-gorm-api/internal/telemetry"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	defaultInvitationTTL     = 72 * time.Hour
+	defaultInvitationBaseURL = "https://admin.mylocal.ing/invitations/"
+)
+
+var (
+	errInvitationNotPending = errors.New("invitation is no longer valid")
+	errAlreadyAdmin         = errors.New("email already belongs to an admin")
+	errInvitationNotSent    = errors.New("invitation email could not be sent")
+)
+
+// invitationTTL is how long an invitation link stays valid, from ADMIN_INVITATION_TTL_HOURS
+func invitationTTL() time.Duration {
+	if n, err := strconv.Atoi(os.Getenv("ADMIN_INVITATION_TTL_HOURS")); err == nil && n > 0 {
+		return time.Duration(n) * time.Hour
+	}
+	return defaultInvitationTTL
+}
+
+// invitationLink is the URL emailed to the invitee, ADMIN_INVITATION_URL followed by the token
+func invitationLink(token string) string {
+	base := os.Getenv("ADMIN_INVITATION_URL")
+	if base == "" {
+		base = defaultInvitationBaseURL
+	}
+	return base + token
+}
+
+// hashToken returns the hex SHA-256 of an emailed single-use token, as stored in the database
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// InviteAdmin godoc
+// @Summary      Invite an admin
+// @Description  Emails a single-use invitation link to join the caller's organization with the given role.
+// @Tags         invitations
+// @Accept       json
+// @Produce      json
+// @Param        body  body      dto.InviteAdminRequest  true  "Invitee email and role (admin, editor, viewer)"
+// @Success      201   {object}  dto.InvitationResponse
+// @Failure      400   {object}  dto.ErrorResponse
+// @Failure      403   {object}  dto.ErrorResponse
+// @Failure      409   {object}  dto.ErrorResponse
+// @Failure      500   {object}  dto.ErrorResponse
+// @Router       /admin/users/invite [post]
+func InviteAdmin(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		var req dto.InviteAdminRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Unable to parse request body"})
+		}
+		req.Email = strings.TrimSpace(req.Email)
+		if !service.IsValidEmail(req.Email) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid or missing email"})
+		}
+		if _, ok := models.RoleScopes[req.Role]; !ok {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Unknown role: " + req.Role})
+		}
+
+		orgID := middleware.CurrentOrgID(c)
+		var org models.Organization
+		if err := db.First(&org, orgID).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not load organization"})
+		}
+
+		var count int64
+		db.Model(&models.AdminUser{}).Where("email = ?", req.Email).Count(&count)
+		if count > 0 {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "This email already belongs to an admin"})
+		}
+		db.Model(&models.AdminInvitation{}).
+			Where("org_id = ? AND email = ? AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > ?", orgID, req.Email, time.Now()).
+			Count(&count)
+		if count > 0 {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "An invitation is already pending for this email"})
+		}
+
+		token := randomToken(32)
+		invitation := models.AdminInvitation{
+			OrgID:     orgID,
+			Email:     req.Email,
+			Role:      req.Role,
+			TokenHash: hashToken(token),
+			InvitedBy: callerIdentity(c),
+			ExpiresAt: time.Now().Add(invitationTTL()),
+		}
+		// an invitation nobody received shouldn't block a retry, so a failed send rolls it back
+		err := database.Tx(c.UserContext(), db, func(tx *gorm.DB) error {
+			if err := tx.Create(&invitation).Error; err != nil {
+				return err
+			}
+			err := notify.Record(tx, &models.AdminActivity{
+				OrgID:   orgID,
+				Kind:    notify.KindAdminInvited,
+				Actor:   invitation.InvitedBy,
+				Summary: fmt.Sprintf("%s invited as %s of %s", req.Email, req.Role, org.Name),
+			})
+			if err != nil {
+				return err
+			}
+			err = telemetry.Trace(c.UserContext(), "sendgrid.send_invitation", func() error {
+				return sendgridservice.SendInvitationEmailFunc(req.Email, org.Name, invitationLink(token))
+			})
+			if err != nil {
+				return errInvitationNotSent
+			}
+			return nil
+		})
+		if errors.Is(err, errInvitationNotSent) {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to send email"})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not create invitation"})
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(dto.NewInvitationResponse(invitation))
+	}
+}
+
+// GetAllInvitations godoc
+// @Summary      List invitations
+// @Description  Lists the organization's invitations, newest first, with their status.
+// @Tags         invitations
+// @Produce      json
+// @Success      200  {array}   dto.InvitationResponse
+// @Failure      403  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/invitations [get]
+func GetAllInvitations(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		var invitations []models.AdminInvitation
+		if err := db.Scopes(orgScope(c)).Order("id DESC").Find(&invitations).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve invitations"})
+		}
+		resp := make([]dto.InvitationResponse, len(invitations))
+		for i, inv := range invitations {
+			resp[i] = dto.NewInvitationResponse(inv)
+		}
+		return c.JSON(resp)
+	}
+}
+
+// RevokeInvitation godoc
+// @Summary      Revoke an invitation
+// @Description  The link stops working immediately. Accepted invitations can't be revoked; remove the admin instead.
+// @Tags         invitations
+// @Produce      json
+// @Param        id   path      int true "Invitation ID"
+// @Success      200  {object}  dto.InvitationResponse
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      409  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/invitations/{id} [delete]
+func RevokeInvitation(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		id, err := strconv.Atoi(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid invitation ID"})
+		}
+
+		var invitation models.AdminInvitation
+		if err := db.Scopes(orgScope(c)).First(&invitation, id).Error; err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Invitation not found"})
+		}
+		if invitation.AcceptedAt != nil {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Invitation was already accepted"})
+		}
+		if invitation.RevokedAt == nil {
+			now := time.Now()
+			if err := db.Model(&invitation).Update("revoked_at", now).Error; err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not revoke invitation"})
+			}
+			invitation.RevokedAt = &now
+		}
+		return c.JSON(dto.NewInvitationResponse(invitation))
+	}
+}
+
+// GetInvitation godoc
+// @Summary      Preview an invitation
+// @Description  Public: shows the invitee which organization and role the emailed link grants.
+// @Tags         invitations
+// @Produce      json
+// @Param        token  path      string true "Token from the invitation link"
+// @Success      200    {object}  dto.InvitationPreviewResponse
+// @Failure      404    {object}  dto.ErrorResponse
+// @Failure      410    {object}  dto.ErrorResponse  "Accepted, revoked or expired"
+// @Router       /admin/invitations/{token} [get]
+func GetInvitation(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		var invitation models.AdminInvitation
+		if err := db.Where("token_hash = ?", hashToken(c.Params("token"))).First(&invitation).Error; err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Invitation not found"})
+		}
+		if !invitation.Pending(time.Now()) {
+			return c.Status(fiber.StatusGone).JSON(fiber.Map{"error": "Invitation is no longer valid"})
+		}
+
+		var org models.Organization
+		if err := db.First(&org, invitation.OrgID).Error; err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Invitation not found"})
+		}
+		return c.JSON(dto.InvitationPreviewResponse{
+			Email:            invitation.Email,
+			Role:             invitation.Role,
+			OrganizationName: org.Name,
+			ExpiresAt:        invitation.ExpiresAt,
+		})
+	}
+}
+
+// AcceptInvitation godoc
+// @Summary      Accept an invitation
+// @Description  Public: creates the admin with the invited role and signs them in. The link is single-use;
+// @Description  holding it proves control of the invited mailbox.
+// @Tags         invitations
+// @Produce      json
+// @Param        token  path      string true "Token from the invitation link"
+// @Success      200    {object}  dto.TokenResponse
+// @Failure      404    {object}  dto.ErrorResponse
+// @Failure      409    {object}  dto.ErrorResponse
+// @Failure      410    {object}  dto.ErrorResponse  "Accepted, revoked or expired"
+// @Failure      500    {object}  dto.ErrorResponse
+// @Router       /admin/invitations/{token} [post]
+func AcceptInvitation(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		var invitation models.AdminInvitation
+		err := database.Tx(c.UserContext(), db, func(tx *gorm.DB) error {
+			// lock the row so two clicks can't both create the admin
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("token_hash = ?", hashToken(c.Params("token"))).
+				First(&invitation).Error; err != nil {
+				return err
+			}
+			now := time.Now()
+			if !invitation.Pending(now) {
+				return errInvitationNotPending
+			}
+
+			var count int64
+			tx.Model(&models.AdminUser{}).Where("email = ?", invitation.Email).Count(&count)
+			if count > 0 {
+				return errAlreadyAdmin
+			}
+			admin := models.AdminUser{OrgID: invitation.OrgID, Email: invitation.Email, Role: invitation.Role}
+			if err := tx.Create(&admin).Error; err != nil {
+				return err
+			}
+			return tx.Model(&invitation).Update("accepted_at", now).Error
+		})
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Invitation not found"})
+		case errors.Is(err, errInvitationNotPending):
+			return c.Status(fiber.StatusGone).JSON(fiber.Map{"error": "Invitation is no longer valid"})
+		case errors.Is(err, errAlreadyAdmin):
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "This email already belongs to an admin"})
+		case err != nil:
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not accept invitation"})
+		}
+
+		return issueSessionToken(c, db, invitation.Email)
+	}
+}
@@ -0,0 +1,19 @@
+package handlers
+
+import (
+	"fiber-gorm-api/internal/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// jwks godoc
+// @Summary      JSON Web Key Set
+// @Description  Public keys verifying admin JWTs (RS256/EdDSA), by kid, including retired keys whose tokens are still valid. Empty with HS256.
+// @Tags         signin
+// @Produce      json
+// @Success      200  {object}  dto.JWKSResponse
+// @Router       /.well-known/jwks.json [get]
+func JWKS(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.JSON(middleware.PublicJWKS())
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"fiber-gorm-api/internal/i18n"
+	"fiber-gorm-api/internal/mailqueue"
+	"fiber-gorm-api/internal/telemetry"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Magic links sign in with one click instead of a typed code. The token is random; Redis maps
+// its hash to the email until the link is opened or expires.
+
+const (
+	defaultMagicLinkTTL         = 15 * time.Minute
+	defaultMagicLinkBaseURL     = "https://api.mylocal.ing/signin/magic/"
+	defaultMagicLinkRedirectURL = "https://signin.mylocal.ing/"
+)
+
+// Helper to form the Redis key holding the email a magic link signs in. Only the hash of the
+// token is stored.
+func magicLinkKey(token string) string {
+	return "signin_magic:" + hashToken(token)
+}
+
+// magicLinkTTL is how long a magic link stays valid, from SIGNIN_MAGIC_LINK_TTL_MINUTES
+func magicLinkTTL() time.Duration {
+	if n, err := strconv.Atoi(os.Getenv("SIGNIN_MAGIC_LINK_TTL_MINUTES")); err == nil && n > 0 {
+		return time.Duration(n) * time.Minute
+	}
+	return defaultMagicLinkTTL
+}
+
+// magicLink is the URL emailed to sign in, SIGNIN_MAGIC_LINK_URL followed by the token
+func magicLink(token string) string {
+	base := os.Getenv("SIGNIN_MAGIC_LINK_URL")
+	if base == "" {
+		base = defaultMagicLinkBaseURL
+	}
+	return base + token
+}
+
+// magicLinkRedirectURL is where an opened magic link lands, from SIGNIN_MAGIC_LINK_REDIRECT_URL
+func magicLinkRedirectURL() string {
+	if u := os.Getenv("SIGNIN_MAGIC_LINK_REDIRECT_URL"); u != "" {
+		return u
+	}
+	return defaultMagicLinkRedirectURL
+}
+
+// sendMagicLink stores a fresh magic link token for email in kv and sends the email of the link
+// with the EmailSender. Errors are safe to return to the client.
+func (h *SigninHandler) sendMagicLink(ctx context.Context, email string) (err error) {
+	ctx, span := telemetry.Start(ctx, "signin.send_magic_link")
+	defer telemetry.End(span, &err)
+
+	token := randomToken(32)
+	if err := h.kv.SetValue(ctx, magicLinkKey(token), email, magicLinkTTL()); err != nil {
+		return clientError(err, "Unable to store link in redis")
+	}
+
+	err = h.emails.Send(ctx, mailqueue.Email{
+		Kind:      mailqueue.KindMagicLink,
+		To:        email,
+		Locale:    i18n.FromContext(ctx),
+		Secret:    magicLink(token),
+		ExpiresAt: h.clock.Now().Add(magicLinkTTL()),
+	})
+	if err != nil {
+		return errors.New("Failed to queue email")
+	}
+	return nil
+}
+
+// MagicLinkSignIn godoc
+// @Summary      Sign in with a magic link
+// @Description  Opened from the email sent by /signin/request with method link. Consumes the single-use token, creates the session and redirects to SIGNIN_MAGIC_LINK_REDIRECT_URL#token=<JWT>.
+// @Description  An unknown, expired or already used link redirects to SIGNIN_MAGIC_LINK_REDIRECT_URL#error=invalid_link.
+// @Tags         signin
+// @Param        token  path  string  true  "Token from the emailed link"
+// @Success      302  {string}  string  "Redirect with the token, or the error, in the fragment"
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /signin/magic/{token} [get]
+func (h *SigninHandler) MagicLinkSignIn(c *fiber.Ctx) error {
+	redirect := magicLinkRedirectURL()
+
+	// single-use: whoever takes the key first signs in
+	email, err := h.kv.Take(c.UserContext(), magicLinkKey(c.Params("token")))
+	if err != nil || email == "" {
+		return c.Redirect(redirect+"#error=invalid_link", fiber.StatusFound)
+	}
+
+	token, err := h.sessionToken(c, email)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	// the fragment never reaches server logs
+	return c.Redirect(redirect+"#token="+url.QueryEscape(token), fiber.StatusFound)
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"os"
+	"time"
+
+	"fiber-gorm-api/internal/oauth"
+	redisclient "fiber-gorm-api/internal/redis"
+
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/oauth2"
+	"gorm.io/gorm"
+)
+
+// oauthStateTTL is how long the user has to complete the provider's consent screen
+const oauthStateTTL = 10 * time.Minute
+
+// oauthState is stored in Redis between /start and /callback
+type oauthState struct {
+	Provider     string `json:"provider"`
+	Nonce        string `json:"nonce"`
+	PKCEVerifier string `json:"pkce_verifier"`
+}
+
+// Helper to form the Redis key for a pending OAuth sign in
+func oauthStateKey(state string) string {
+	return "oauth_state:" + state
+}
+
+// OAuthStart godoc
+// @Summary      Start OAuth sign in
+// @Description  Redirects to the identity provider (google or github). State, nonce and PKCE verifier are kept in Redis.
+// @Tags         signin
+// @Param        provider  path  string  true  "Provider name (google, github)"
+// @Success      302  {string}  string  "Redirect to provider"
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /signin/oauth/{provider}/start [get]
+func OAuthStart(c *fiber.Ctx) error {
+	provider, ok := oauth.Get(c.Params("provider"))
+	if !ok {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Unknown or disabled sign in provider"})
+	}
+
+	state := randomToken(24)
+	pending := oauthState{
+		Provider:     provider.Name,
+		Nonce:        randomToken(24),
+		PKCEVerifier: oauth2.GenerateVerifier(),
+	}
+	raw, err := json.Marshal(pending)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not start sign in"})
+	}
+	if err := redisclient.SetValue(c.UserContext(), oauthStateKey(state), string(raw), oauthStateTTL); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Unable to store state in redis"})
+	}
+
+	return c.Redirect(provider.AuthCodeURL(state, pending.Nonce, pending.PKCEVerifier), fiber.StatusFound)
+}
+
+// OAuthCallback godoc
+// @Summary      OAuth sign in callback
+// @Description  Completes the code flow, maps the provider's verified email to a session and mints the session JWT.
+// @Description  Redirects to OAUTH_SUCCESS_REDIRECT_URL#token=... when configured, otherwise responds with the token.
+// @Tags         signin
+// @Produce      json
+// @Param        provider  path   string  true  "Provider name (google, github)"
+// @Param        code      query  string  true  "Authorization code"
+// @Param        state     query  string  true  "State returned by /start"
+// @Success      200  {object}  dto.TokenResponse  "JWT returned"
+// @Success      302  {string}  string  "Redirect with token in fragment"
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      401  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /signin/oauth/{provider}/callback [get]
+func OAuthCallback(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		provider, ok := oauth.Get(c.Params("provider"))
+		if !ok {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Unknown or disabled sign in provider"})
+		}
+		if errParam := c.Query("error"); errParam != "" {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Sign in was cancelled or denied"})
+		}
+
+		state, code := c.Query("state"), c.Query("code")
+		if state == "" || code == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing code or state"})
+		}
+
+		// state is single-use
+		raw, err := redisclient.GetValue(c.UserContext(), oauthStateKey(state))
+		if err != nil || raw == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Unknown or expired sign in state"})
+		}
+		_ = redisclient.DeleteKey(c.UserContext(), oauthStateKey(state))
+
+		var pending oauthState
+		if err := json.Unmarshal([]byte(raw), &pending); err != nil || pending.Provider != provider.Name {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Unknown or expired sign in state"})
+		}
+
+		email, err := provider.Exchange(c.UserContext(), code, pending.Nonce, pending.PKCEVerifier)
+		if errors.Is(err, oauth.ErrEmailNotVerified) {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Your email address is not verified with this provider"})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Sign in with provider failed"})
+		}
+
+		successURL := os.Getenv("OAUTH_SUCCESS_REDIRECT_URL")
+		if successURL == "" {
+			return issueSessionToken(c, db, email)
+		}
+
+		token, err := createSessionToken(c, db, email)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		}
+		// the fragment never reaches server logs
+		return c.Redirect(successURL+"#token="+url.QueryEscape(token), fiber.StatusFound)
+	}
+}
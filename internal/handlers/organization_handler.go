@@ -0,0 +1,341 @@
+package handlers
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+
+	database "fiber-gorm-api/internal/db"
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
+	"fiber-gorm-api/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// slugRegex keeps slugs URL and header friendly
+var slugRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,62}$`)
+
+var errOrganizationNotEmpty = errors.New("organization still has subscribers, API keys or admins")
+
+// canAccessOrg reports whether the caller may see organization id: their own, or any for platform admins
+func canAccessOrg(c *fiber.Ctx, id uint) bool {
+	orgID := middleware.CurrentOrgID(c)
+	return orgID == models.DefaultOrgID || orgID == id
+}
+
+// parseOrganizationRequest reads and validates a create / update body
+func parseOrganizationRequest(c *fiber.Ctx) (dto.OrganizationRequest, string) {
+	var req dto.OrganizationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return req, "Unable to parse request body"
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	req.Slug = strings.ToLower(strings.TrimSpace(req.Slug))
+	if req.Name == "" {
+		return req, "missing name"
+	}
+	if !slugRegex.MatchString(req.Slug) {
+		return req, "invalid or missing slug"
+	}
+	return req, ""
+}
+
+// CreateOrganization godoc
+// @Summary      Create an organization
+// @Description  Creates a new organization (local community). Platform admins only.
+// @Tags         organizations
+// @Accept       json
+// @Produce      json
+// @Param        organization  body      dto.OrganizationRequest  true  "Name and unique slug"
+// @Success      201  {object}  dto.OrganizationResponse
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      403  {object}  dto.ErrorResponse
+// @Failure      409  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/organizations [post]
+func CreateOrganization(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		req, problem := parseOrganizationRequest(c)
+		if problem != "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": problem})
+		}
+
+		var count int64
+		db.Model(&models.Organization{}).Where("slug = ?", req.Slug).Count(&count)
+		if count > 0 {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Slug already taken"})
+		}
+
+		org := models.Organization{Name: req.Name, Slug: req.Slug}
+		if err := db.Create(&org).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not create organization"})
+		}
+		return c.Status(fiber.StatusCreated).JSON(dto.NewOrganizationResponse(org))
+	}
+}
+
+// GetAllOrganizations godoc
+// @Summary      List organizations
+// @Description  Platform admins see every organization, everyone else only their own.
+// @Tags         organizations
+// @Produce      json
+// @Success      200  {array}   dto.OrganizationResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/organizations [get]
+func GetAllOrganizations(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		query := db.Order("id ASC")
+		if orgID := middleware.CurrentOrgID(c); orgID != models.DefaultOrgID {
+			query = query.Where("id = ?", orgID)
+		}
+
+		var orgs []models.Organization
+		if err := query.Find(&orgs).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve organizations"})
+		}
+
+		resp := make([]dto.OrganizationResponse, len(orgs))
+		for i, o := range orgs {
+			resp[i] = dto.NewOrganizationResponse(o)
+		}
+		return c.JSON(resp)
+	}
+}
+
+// GetOrganization godoc
+// @Summary      Get an organization
+// @Tags         organizations
+// @Produce      json
+// @Param        id   path      int true "Organization ID"
+// @Success      200  {object}  dto.OrganizationResponse
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Router       /admin/organizations/{id} [get]
+func GetOrganization(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		org, status, msg := loadOrganization(c, db)
+		if status != 0 {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+		return c.JSON(dto.NewOrganizationResponse(org))
+	}
+}
+
+// UpdateOrganization godoc
+// @Summary      Update an organization
+// @Description  Renames an organization or changes its slug. Platform admins only.
+// @Tags         organizations
+// @Accept       json
+// @Produce      json
+// @Param        id            path      int                      true  "Organization ID"
+// @Param        organization  body      dto.OrganizationRequest  true  "Name and unique slug"
+// @Success      200  {object}  dto.OrganizationResponse
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      403  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      409  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/organizations/{id} [put]
+func UpdateOrganization(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		org, status, msg := loadOrganization(c, db)
+		if status != 0 {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+
+		req, problem := parseOrganizationRequest(c)
+		if problem != "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": problem})
+		}
+
+		var count int64
+		db.Model(&models.Organization{}).Where("slug = ? AND id <> ?", req.Slug, org.ID).Count(&count)
+		if count > 0 {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Slug already taken"})
+		}
+
+		org.Name = req.Name
+		org.Slug = req.Slug
+		if err := db.Save(&org).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not update organization"})
+		}
+		return c.JSON(dto.NewOrganizationResponse(org))
+	}
+}
+
+// DeleteOrganization godoc
+// @Summary      Delete an organization
+// @Description  Deletes an empty organization (no subscribers, API keys or admins). The default organization can't be deleted. Platform admins only.
+// @Tags         organizations
+// @Param        id   path      int true "Organization ID"
+// @Success      204  {string}  string
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      403  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      409  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/organizations/{id} [delete]
+func DeleteOrganization(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		org, status, msg := loadOrganization(c, db)
+		if status != 0 {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+		if org.ID == models.DefaultOrgID {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "The default organization can't be deleted"})
+		}
+
+		err := database.Tx(c.UserContext(), db, func(tx *gorm.DB) error {
+			// refuse rather than cascade: tenant data should never vanish as a side effect
+			for _, model := range []interface{}{&models.Subscriber{}, &models.ApiKey{}, &models.AdminUser{}} {
+				var count int64
+				if err := tx.Unscoped().Model(model).Where("org_id = ?", org.ID).Count(&count).Error; err != nil {
+					return err
+				}
+				if count > 0 {
+					return errOrganizationNotEmpty
+				}
+			}
+			return tx.Delete(&org).Error
+		})
+		if errors.Is(err, errOrganizationNotEmpty) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Organization still has subscribers, API keys or admins"})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not delete organization"})
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	}
+}
+
+// GetOrganizationMembers godoc
+// @Summary      List an organization's admins
+// @Tags         organizations
+// @Produce      json
+// @Param        id   path      int true "Organization ID"
+// @Success      200  {array}   dto.AdminUserResponse
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/organizations/{id}/members [get]
+func GetOrganizationMembers(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		org, status, msg := loadOrganization(c, db)
+		if status != 0 {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+
+		var admins []models.AdminUser
+		if err := db.Where("org_id = ?", org.ID).Order("email ASC").Find(&admins).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve admins"})
+		}
+		resp := make([]dto.AdminUserResponse, len(admins))
+		for i, a := range admins {
+			resp[i] = dto.NewAdminUserResponse(a)
+		}
+		return c.JSON(resp)
+	}
+}
+
+// AddOrganizationMember godoc
+// @Summary      Add an admin to an organization
+// @Description  Assigns a sign-in email to the organization with a role. The admin's next sign-in is scoped to both.
+// @Tags         organizations
+// @Accept       json
+// @Produce      json
+// @Param        id      path      int                      true  "Organization ID"
+// @Param        member  body      dto.AddAdminUserRequest  true  "Admin email and role"
+// @Success      201  {object}  dto.AdminUserResponse
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      409  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/organizations/{id}/members [post]
+func AddOrganizationMember(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		org, status, msg := loadOrganization(c, db)
+		if status != 0 {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+
+		var req dto.AddAdminUserRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Unable to parse request body"})
+		}
+		admin, err := service.AddAdmin(c.UserContext(), db, org.ID, req.Email, req.Role)
+		var invalid *service.ValidationError
+		if errors.As(err, &invalid) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": invalid.Message})
+		}
+		if errors.Is(err, service.ErrAdminExists) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "This admin already belongs to an organization"})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not add admin"})
+		}
+		return c.Status(fiber.StatusCreated).JSON(dto.NewAdminUserResponse(*admin))
+	}
+}
+
+// RemoveOrganizationMember godoc
+// @Summary      Remove an admin from an organization
+// @Description  Existing sessions keep their organization until they expire or are revoked.
+// @Tags         organizations
+// @Param        id         path      int true "Organization ID"
+// @Param        memberId   path      int true "Admin user ID"
+// @Success      204  {string}  string
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/organizations/{id}/members/{memberId} [delete]
+func RemoveOrganizationMember(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		org, status, msg := loadOrganization(c, db)
+		if status != 0 {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+
+		memberID, err := strconv.Atoi(c.Params("memberId"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid admin ID"})
+		}
+
+		var admin models.AdminUser
+		if err := db.Where("org_id = ?", org.ID).First(&admin, memberID).Error; err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Admin not found"})
+		}
+		if err := db.Delete(&admin).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not remove admin"})
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	}
+}
+
+// loadOrganization fetches the organization named by :id if the caller may access it.
+// A non-zero status (with message) is returned otherwise; foreign organizations look missing.
+func loadOrganization(c *fiber.Ctx, db *gorm.DB) (models.Organization, int, string) {
+	var org models.Organization
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil || id < 1 {
+		return org, fiber.StatusBadRequest, "Invalid organization ID"
+	}
+	if !canAccessOrg(c, uint(id)) {
+		return org, fiber.StatusNotFound, "Organization not found"
+	}
+	if err := db.First(&org, id).Error; err != nil {
+		return org, fiber.StatusNotFound, "Organization not found"
+	}
+	return org, 0, ""
+}
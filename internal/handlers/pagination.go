@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 500
+
+	// NextCursorHeader carries the cursor for the following page when more rows exist
+	NextCursorHeader = "X-Next-Cursor"
+	// TotalCountHeader carries the number of rows matching a list's filters, across pages
+	TotalCountHeader = "X-Total-Count"
+)
+
+// sortable columns for list endpoints, keyed by the ?sort= value
+var pageSortColumns = map[string]string{
+	"id":         "id",
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+}
+
+// pageCursor is the decoded form of the opaque ?cursor= token.
+// It records the sort key and the position of the last row returned.
+type pageCursor struct {
+	Sort  string     `json:"s"`
+	ID    uint       `json:"id"`
+	Value *time.Time `json:"v,omitempty"`
+}
+
+// pageParams is the parsed pagination request for a list endpoint
+type pageParams struct {
+	Limit  int
+	Offset int
+	Sort   string
+	Cursor *pageCursor
+}
+
+// Paginated reports whether the caller asked for a page rather than the full list
+func (p pageParams) Paginated() bool {
+	return p.Limit > 0
+}
+
+func encodeCursor(cur pageCursor) string {
+	raw, _ := json.Marshal(cur)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(token string) (*pageCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, err
+	}
+	var cur pageCursor
+	if err := json.Unmarshal(raw, &cur); err != nil {
+		return nil, err
+	}
+	if _, ok := pageSortColumns[cur.Sort]; !ok {
+		return nil, errors.New("unknown sort key")
+	}
+	if cur.Sort != "id" && cur.Value == nil {
+		return nil, errors.New("missing sort value")
+	}
+	return &cur, nil
+}
+
+// parsePageParams reads limit, offset, sort and cursor from the query string.
+// Without limit, offset or cursor the list is returned unpaginated as before.
+func parsePageParams(c *fiber.Ctx) (pageParams, error) {
+	p := pageParams{Sort: "id"}
+
+	if sort := c.Query("sort"); sort != "" {
+		if _, ok := pageSortColumns[sort]; !ok {
+			return p, errors.New("Invalid sort")
+		}
+		p.Sort = sort
+	}
+
+	if limitParam := c.Query("limit"); limitParam != "" {
+		n, err := strconv.Atoi(limitParam)
+		if err != nil || n < 1 {
+			return p, errors.New("Invalid limit")
+		}
+		if n > maxPageLimit {
+			n = maxPageLimit
+		}
+		p.Limit = n
+	}
+
+	if offsetParam := c.Query("offset"); offsetParam != "" {
+		n, err := strconv.Atoi(offsetParam)
+		if err != nil || n < 0 {
+			return p, errors.New("Invalid offset")
+		}
+		p.Offset = n
+	}
+
+	if token := c.Query("cursor"); token != "" {
+		if p.Offset > 0 {
+			return p, errors.New("Cursor and offset cannot be combined")
+		}
+		cur, err := decodeCursor(token)
+		if err != nil {
+			return p, errors.New("Invalid cursor")
+		}
+		// the cursor is bound to the ordering it was issued for
+		p.Sort = cur.Sort
+		p.Cursor = cur
+	}
+
+	if p.Limit == 0 && (p.Offset > 0 || p.Cursor != nil) {
+		p.Limit = defaultPageLimit
+	}
+	return p, nil
+}
+
+// pageParamsFor builds cursor pagination from a page size and a cursor, as taken by the gRPC and
+// GraphQL list calls. A limit below one means the default page size.
+func pageParamsFor(limit int, cursor string) (pageParams, error) {
+	p := pageParams{Sort: "id", Limit: defaultPageLimit}
+	if limit > 0 {
+		p.Limit = min(limit, maxPageLimit)
+	}
+	if cursor != "" {
+		cur, err := decodeCursor(cursor)
+		if err != nil {
+			return p, errors.New("Invalid cursor")
+		}
+		p.Sort = cur.Sort
+		p.Cursor = cur
+	}
+	return p, nil
+}
+
+// apply adds ordering, keyset / offset filtering and the limit to query.
+// One extra row is fetched so callers can tell whether a next page exists.
+func (p pageParams) apply(query *gorm.DB) *gorm.DB {
+	col := pageSortColumns[p.Sort]
+	if col == "id" {
+		query = query.Order("id ASC")
+	} else {
+		query = query.Order(col + " ASC").Order("id ASC")
+	}
+
+	if !p.Paginated() {
+		return query
+	}
+
+	if p.Cursor != nil {
+		if col == "id" {
+			query = query.Where("id > ?", p.Cursor.ID)
+		} else {
+			query = query.Where("("+col+", id) > (?, ?)", *p.Cursor.Value, p.Cursor.ID)
+		}
+	} else if p.Offset > 0 {
+		query = query.Offset(p.Offset)
+	}
+	return query.Limit(p.Limit + 1)
+}
+
+// nextCursor builds the cursor pointing just past the given row
+func (p pageParams) nextCursor(id uint, createdAt, updatedAt time.Time) string {
+	cur := pageCursor{Sort: p.Sort, ID: id}
+	switch p.Sort {
+	case "created_at":
+		cur.Value = &createdAt
+	case "updated_at":
+		cur.Value = &updatedAt
+	}
+	return encodeCursor(cur)
+}
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/repository"
+	"fiber-gorm-api/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// preferencesAuthor is the author of the revisions of changes made on the preferences page
+const preferencesAuthor = "subscriber"
+
+// RequestPreferencesLink godoc
+// @Summary      Email a preferences link
+// @Description  Emails the subscriber with the address, in the organization named by X-Org / ?org=, a link to their preferences page (GET/PUT /preferences/{token}), valid for PREFERENCES_LINK_TTL_DAYS (30 by default).
+// @Description  The answer is the same whether or not the address is subscribed, and at most one email is sent every 5 minutes.
+// @Tags         preferences
+// @Accept       json
+// @Produce      json
+// @Param        body  body      dto.RequestPreferencesLinkRequest  true  "Subscriber address"
+// @Success      202   {object}  dto.MessageResponse
+// @Failure      400   {object}  dto.ErrorResponse
+// @Failure      500   {object}  dto.ErrorResponse
+// @Router       /preferences [post]
+func RequestPreferencesLink(db *gorm.DB) fiber.Handler {
+	svc := subscriberService(db)
+	return func(c *fiber.Ctx) error {
+		var req dto.RequestPreferencesLinkRequest
+		if err := c.BodyParser(&req); err != nil || !service.IsValidEmail(req.Email) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid or missing email"})
+		}
+
+		if err := svc.SendPreferencesLink(c.UserContext(), middleware.CurrentOrgID(c), req.Email); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not send preferences link"})
+		}
+		return c.Status(fiber.StatusAccepted).JSON(dto.MessageResponse{
+			Message: "If this address is subscribed, a link to manage it is on its way.",
+		})
+	}
+}
+
+// invalidPreferencesLink writes the 404 of an unknown or expired preferences token
+func invalidPreferencesLink(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+		"error": "Preferences link is invalid or has expired, please ask for a new one",
+		"code":  "invalid_token",
+	})
+}
+
+// GetPreferences godoc
+// @Summary      View subscription preferences
+// @Description  Returns the masked address, name, subscription status and subscriber_types, with their frequency and channel, of the subscriber a preferences link was issued to. No sign in: the emailed token is the credential.
+// @Tags         preferences
+// @Produce      json
+// @Param        token  path      string  true  "Token from the preferences link"
+// @Success      200    {object}  dto.PreferencesResponse
+// @Failure      404    {object}  dto.ErrorResponse  "code: invalid_token, also once the link expired"
+// @Failure      500    {object}  dto.ErrorResponse
+// @Router       /preferences/{token} [get]
+func GetPreferences(db *gorm.DB) fiber.Handler {
+	svc := subscriberService(db)
+	return func(c *fiber.Ctx) error {
+		subscriber, err := svc.Preferences(c.UserContext(), c.Params("token"))
+		if errors.Is(err, service.ErrInvalidPreferencesLink) {
+			return invalidPreferencesLink(c)
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve preferences"})
+		}
+		return c.JSON(dto.NewPreferencesResponse(*subscriber))
+	}
+}
+
+// UpdatePreferences godoc
+// @Summary      Update subscription preferences
+// @Description  Lets the subscriber behind a preferences link change their name, replace their subscriber_types (with each one's frequency and channel) and unsubscribe or subscribe again. Omitted fields are left untouched.
+// @Description  Subscribing again only reactivates an unsubscribed address: a bounced one stays bounced and a pending one still has to confirm.
+// @Tags         preferences
+// @Accept       json
+// @Produce      json
+// @Param        token        path      string                        true  "Token from the preferences link"
+// @Param        preferences  body      dto.UpdatePreferencesRequest  true  "Changes"
+// @Success      200          {object}  dto.PreferencesResponse
+// @Failure      400          {object}  dto.ErrorResponse  "code: invalid_frequency or invalid_channel"
+// @Failure      404          {object}  dto.ErrorResponse  "code: invalid_token, also once the link expired"
+// @Failure      409          {object}  dto.ErrorResponse  "code: version_conflict"
+// @Failure      422          {object}  dto.InvalidSubscriberTypesResponse  "code: invalid_subscriber_type"
+// @Failure      500          {object}  dto.ErrorResponse
+// @Router       /preferences/{token} [put]
+func UpdatePreferences(db *gorm.DB) fiber.Handler {
+	svc := subscriberService(db)
+	return func(c *fiber.Ctx) error {
+		var req dto.UpdatePreferencesRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Unable to parse request body"})
+		}
+
+		ctx := repository.WithAuthor(c.UserContext(), preferencesAuthor)
+		subscriber, err := svc.UpdatePreferences(ctx, c.Params("token"), service.UpdatePreferencesInput{
+			Name:            req.Name,
+			SubscriberTypes: req.TypeModels(),
+			Subscribed:      req.Subscribed,
+		})
+		var invalid *service.ValidationError
+		switch {
+		case errors.Is(err, service.ErrInvalidPreferencesLink):
+			return invalidPreferencesLink(c)
+		case errors.As(err, &invalid):
+			return subscriberValidationFailed(c, err)
+		case errors.Is(err, service.ErrVersionConflict):
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "Your subscription was changed in the meantime, please reload",
+				"code":  "version_conflict",
+			})
+		case err != nil:
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not update preferences"})
+		}
+		return c.JSON(dto.NewPreferencesResponse(*subscriber))
+	}
+}
+
+// CreatePreferencesLink godoc
+// @Summary      Create a preferences link
+// @Description  Returns a link, valid for PREFERENCES_LINK_TTL_DAYS (30 by default), letting the subscriber change their name, subscriber_types and subscription themselves, e.g. to include in a campaign footer.
+// @Tags         subscribers
+// @Produce      json
+// @Param        id   path      int  true  "Subscriber ID"
+// @Success      200  {object}  dto.PreferencesLinkResponse
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      409  {object}  dto.ErrorResponse  "code: anonymized"
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/subscribers/{id}/preferences-link [post]
+func CreatePreferencesLink(db *gorm.DB) fiber.Handler {
+	svc := subscriberService(db)
+	return func(c *fiber.Ctx) error {
+		id, err := strconv.Atoi(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid subscriber ID"})
+		}
+
+		url, expires, err := svc.PreferencesLink(c.UserContext(), middleware.CurrentOrgID(c), uint(id))
+		switch {
+		case errors.Is(err, service.ErrNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Subscriber not found"})
+		case errors.Is(err, service.ErrAnonymized):
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "Anonymized subscribers have no preferences to manage",
+				"code":  "anonymized",
+			})
+		case err != nil:
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not create preferences link"})
+		}
+		return c.JSON(dto.PreferencesLinkResponse{URL: url, ExpiresAt: expires})
+	}
+}
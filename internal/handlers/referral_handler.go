@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"strconv"
+	"time"
+
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// Sizes of the referral leaderboard
+const (
+	defaultLeaderboardLimit = 10
+	maxLeaderboardLimit     = 100
+)
+
+// activeCount counts the rows of subscribers confirmed and still subscribed
+var activeCount = "SUM(CASE WHEN status = '" + models.SubscriberStatusActive + "' THEN 1 ELSE 0 END)"
+
+// referralCounts are the totals of a set of referees
+type referralCounts struct {
+	Referrals int64
+	Active    int64
+	Referrers int64
+}
+
+// GetReferralStats godoc
+// @Summary      Referral leaderboard
+// @Description  Counts the subscribers of the organization who signed up with the referral code (ref) of another, how many of them are active, and how many subscribers referred them.
+// @Description  The leaderboard ranks the limit (10 by default) subscribers who referred the most. since and until filter the referees by their signup. Callers without the pii scope see masked emails.
+// @Tags         stats
+// @Produce      json
+// @Param        limit  query     int     false  "Leaderboard size, at most 100"
+// @Param        since  query     string  false  "Only referees created at or after this RFC 3339 time"
+// @Param        until  query     string  false  "Only referees created before this RFC 3339 time"
+// @Success      200  {object}  dto.ReferralStatsResponse
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/stats/referrals [get]
+func GetReferralStats(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		limit := c.QueryInt("limit", defaultLeaderboardLimit)
+		if limit < 1 || limit > maxLeaderboardLimit {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid limit"})
+		}
+		scope := orgIDScope(middleware.CurrentOrgID(c))
+		var conds []func(*gorm.DB) *gorm.DB
+		for param, cond := range map[string]string{"since": "created_at >= ?", "until": "created_at < ?"} {
+			value := c.Query(param)
+			if value == "" {
+				continue
+			}
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid " + param + ", expected an RFC 3339 time"})
+			}
+			conds = append(conds, func(db *gorm.DB) *gorm.DB { return db.Where(cond, t) })
+		}
+		db := db.WithContext(c.UserContext())
+		referees := func() *gorm.DB {
+			return db.Model(&models.Subscriber{}).Scopes(scope).Scopes(conds...).Where("referred_by_id IS NOT NULL")
+		}
+
+		stats, err := computeReferralStats(referees, limit)
+		if err == nil {
+			err = fillReferrers(db.Scopes(scope), stats.Leaderboard)
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not compute stats"})
+		}
+		if !middleware.CanSeePII(c) {
+			stats = stats.Redacted()
+		}
+		return c.JSON(stats)
+	}
+}
+
+// computeReferralStats runs the aggregate queries behind GetReferralStats on the referees
+// queries return, leaving the leaderboard's referrers to fillReferrers
+func computeReferralStats(referees func() *gorm.DB, limit int) (dto.ReferralStatsResponse, error) {
+	stats := dto.ReferralStatsResponse{GeneratedAt: time.Now(), Leaderboard: []dto.ReferrerCount{}}
+	var totals referralCounts
+	err := referees().
+		Select("COUNT(*) AS referrals, COALESCE(" + activeCount + ", 0) AS active, COUNT(DISTINCT referred_by_id) AS referrers").
+		Scan(&totals).Error
+	if err != nil {
+		return stats, err
+	}
+	stats.TotalReferred, stats.ActiveReferred, stats.Referrers = totals.Referrals, totals.Active, totals.Referrers
+	err = referees().
+		Select("referred_by_id AS subscriber_id, COUNT(*) AS referrals, " + activeCount + " AS active").
+		Group("referred_by_id").
+		Order("referrals DESC, referred_by_id").
+		Limit(limit).
+		Scan(&stats.Leaderboard).Error
+	return stats, err
+}
+
+// fillReferrers completes the leaderboard with the name, email and code of each referrer;
+// those deleted since are left blank
+func fillReferrers(db *gorm.DB, leaderboard []dto.ReferrerCount) error {
+	if len(leaderboard) == 0 {
+		return nil
+	}
+	ids := make([]uint, len(leaderboard))
+	for i, entry := range leaderboard {
+		ids[i] = entry.SubscriberID
+	}
+	var referrers []models.Subscriber
+	if err := db.Select("id, name, email, referral_code").Where("id IN ?", ids).Find(&referrers).Error; err != nil {
+		return err
+	}
+	byID := make(map[uint]models.Subscriber, len(referrers))
+	for _, s := range referrers {
+		byID[s.ID] = s
+	}
+	for i, entry := range leaderboard {
+		s, ok := byID[entry.SubscriberID]
+		if !ok {
+			continue
+		}
+		leaderboard[i].Name = s.Name
+		leaderboard[i].Email = s.Email
+		if s.ReferralCode != nil {
+			leaderboard[i].ReferralCode = *s.ReferralCode
+		}
+	}
+	return nil
+}
+
+// GetSubscriberReferrals godoc
+// @Summary      Subscriber referral history
+// @Description  Returns the referral code a subscriber shares, the subscriber who referred them, and the signups made with their code, newest first, with how many of those are active.
+// @Description  Pages hold limit referees (50 by default); pass the id of the last referee received as before to get the next page. Callers without the pii scope see masked emails.
+// @Tags         subscribers
+// @Produce      json
+// @Param        id      path      int  true   "Subscriber ID"
+// @Param        limit   query     int  false  "Page size, at most 500"
+// @Param        before  query     int  false  "Only referees older than this subscriber id"
+// @Success      200     {object}  dto.SubscriberReferralsResponse
+// @Failure      400     {object}  dto.ErrorResponse
+// @Failure      404     {object}  dto.ErrorResponse
+// @Failure      500     {object}  dto.ErrorResponse
+// @Router       /admin/subscribers/{id}/referrals [get]
+func GetSubscriberReferrals(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		subscriber, err := findSubscriberOf(c, db)
+		if subscriber == nil {
+			return err
+		}
+
+		limit := c.QueryInt("limit", defaultPageLimit)
+		if limit < 1 || limit > maxPageLimit {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid limit"})
+		}
+		page := db.Scopes(orgScope(c)).Where("referred_by_id = ?", subscriber.ID)
+		if before := c.Query("before"); before != "" {
+			id, err := strconv.Atoi(before)
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid before"})
+			}
+			page = page.Where("id < ?", id)
+		}
+
+		resp := dto.SubscriberReferralsResponse{Referees: []dto.ReferralSubscriber{}}
+		if subscriber.ReferralCode != nil {
+			resp.ReferralCode = *subscriber.ReferralCode
+		}
+		if subscriber.ReferredByID != nil {
+			var referrer models.Subscriber
+			if err := db.Scopes(orgScope(c)).First(&referrer, *subscriber.ReferredByID).Error; err == nil {
+				referredBy := dto.NewReferralSubscriber(referrer)
+				resp.ReferredBy = &referredBy
+			}
+		}
+		var counts referralCounts
+		err = db.Model(&models.Subscriber{}).Scopes(orgScope(c)).Where("referred_by_id = ?", subscriber.ID).
+			Select("COUNT(*) AS referrals, COALESCE(" + activeCount + ", 0) AS active").
+			Scan(&counts).Error
+		resp.Referrals, resp.Active = counts.Referrals, counts.Active
+		var referees []models.Subscriber
+		if err == nil {
+			err = page.Order("id DESC").Limit(limit).Find(&referees).Error
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve referrals"})
+		}
+		for _, s := range referees {
+			resp.Referees = append(resp.Referees, dto.NewReferralSubscriber(s))
+		}
+		if !middleware.CanSeePII(c) {
+			resp = resp.Redacted()
+		}
+		return c.JSON(resp)
+	}
+}
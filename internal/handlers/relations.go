@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"fiber-gorm-api/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// relationBatchSize is the most subscribers one relation query loads for: an unpaginated list
+// would otherwise bind every id at once, past Postgres' 65535 parameters on large datasets
+const relationBatchSize = 1000
+
+// loadSubscriberTypes sets the SubscriberTypes of subs, one query per relationBatchSize subscribers
+func loadSubscriberTypes(db *gorm.DB, subs []models.Subscriber) error {
+	return loadRelation(db, subs, "id ASC",
+		func(t models.SubscriberType) uint { return t.SubscriberID },
+		func(s *models.Subscriber, t models.SubscriberType) { s.SubscriberTypes = append(s.SubscriberTypes, t) },
+	)
+}
+
+// loadSubscriberNotes sets the Notes of subs, newest first, one query per relationBatchSize subscribers
+func loadSubscriberNotes(db *gorm.DB, subs []models.Subscriber) error {
+	return loadRelation(db, subs, "created_at DESC, id DESC",
+		func(n models.SubscriberNote) uint { return n.SubscriberID },
+		func(s *models.Subscriber, n models.SubscriberNote) { s.Notes = append(s.Notes, n) },
+	)
+}
+
+// loadRelation reads the rows of T belonging to subs (by subscriber_id) in batches, in order,
+// and hands each to add along with the subscriber it belongs to
+func loadRelation[T any](db *gorm.DB, subs []models.Subscriber, order string, owner func(T) uint, add func(*models.Subscriber, T)) error {
+	index := make(map[uint]int, len(subs))
+	ids := make([]uint, len(subs))
+	for i, s := range subs {
+		index[s.ID] = i
+		ids[i] = s.ID
+	}
+	for len(ids) > 0 {
+		batch := ids[:min(len(ids), relationBatchSize)]
+		ids = ids[len(batch):]
+
+		var rows []T
+		if err := db.Where("subscriber_id IN ?", batch).Order(order).Find(&rows).Error; err != nil {
+			return err
+		}
+		for _, row := range rows {
+			add(&subs[index[owner(row)]], row)
+		}
+	}
+	return nil
+}
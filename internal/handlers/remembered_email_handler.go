@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// The sign-in form prefills the email last used on the browser, kept in a cookie signed so it
+// can't be set by anyone but the API: the email, base64url encoded, a dot and its HMAC-SHA256.
+// It only ever holds the address, never a code or a session.
+
+const (
+	rememberedEmailCookie     = "mylo_remembered_email"
+	defaultRememberedEmailTTL = 365 * 24 * time.Hour
+)
+
+// rememberedEmailKeys returns the key signing remembered emails, SIGNIN_REMEMBER_EMAIL_KEY (the
+// JWT secret when unset), followed by the previous ones still accepted,
+// SIGNIN_REMEMBER_EMAIL_PREVIOUS_KEYS, comma separated
+func rememberedEmailKeys() [][]byte {
+	key := os.Getenv("SIGNIN_REMEMBER_EMAIL_KEY")
+	if key == "" {
+		key = os.Getenv("JWT_USER_SECRET_KEY")
+	}
+	if key == "" {
+		key = "devsecret"
+	}
+	keys := [][]byte{[]byte(key)}
+	for _, previous := range strings.Split(os.Getenv("SIGNIN_REMEMBER_EMAIL_PREVIOUS_KEYS"), ",") {
+		if previous = strings.TrimSpace(previous); previous != "" {
+			keys = append(keys, []byte(previous))
+		}
+	}
+	return keys
+}
+
+// rememberedEmailTTL is how long the browser keeps the email, from SIGNIN_REMEMBER_EMAIL_DAYS
+func rememberedEmailTTL() time.Duration {
+	if n, err := strconv.Atoi(os.Getenv("SIGNIN_REMEMBER_EMAIL_DAYS")); err == nil && n > 0 {
+		return time.Duration(n) * 24 * time.Hour
+	}
+	return defaultRememberedEmailTTL
+}
+
+func signRememberedEmail(key []byte, encoded string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("remembered_email:" + encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// rememberedEmailValue is the cookie value of email, signed with the current key
+func rememberedEmailValue(email string) string {
+	encoded := base64.RawURLEncoding.EncodeToString([]byte(email))
+	return encoded + "." + signRememberedEmail(rememberedEmailKeys()[0], encoded)
+}
+
+// parseRememberedEmail returns the email of a cookie value and whether it was signed with a
+// previous key, or "" when it isn't signed with any key
+func parseRememberedEmail(value string) (email string, stale bool) {
+	encoded, signature, ok := strings.Cut(value, ".")
+	if !ok {
+		return "", false
+	}
+	for i, key := range rememberedEmailKeys() {
+		if !hmac.Equal([]byte(signature), []byte(signRememberedEmail(key, encoded))) {
+			continue
+		}
+		raw, err := base64.RawURLEncoding.DecodeString(encoded)
+		if err != nil {
+			return "", false
+		}
+		return string(raw), i > 0
+	}
+	return "", false
+}
+
+// setRememberedEmailCookie sets the cookie remembering email, or expires it when email is ""
+func setRememberedEmailCookie(c *fiber.Ctx, email string) {
+	cookie := &fiber.Cookie{
+		Name:     rememberedEmailCookie,
+		Value:    rememberedEmailValue(email),
+		Path:     "/signin",
+		Expires:  time.Now().Add(rememberedEmailTTL()),
+		Secure:   true,
+		HTTPOnly: true,
+		SameSite: fiber.CookieSameSiteStrictMode,
+	}
+	if email == "" {
+		cookie.Value = ""
+		cookie.Expires = time.Unix(0, 0)
+	}
+	c.Cookie(cookie)
+}
+
+// RememberedEmail godoc
+// @Summary      Email remembered by this browser
+// @Description  Returns the email last saved with PUT /signin/remembered-email on this browser, for the sign-in form to prefill.
+// @Description  A cookie signed with a previous key of SIGNIN_REMEMBER_EMAIL_PREVIOUS_KEYS is accepted and signed again with SIGNIN_REMEMBER_EMAIL_KEY.
+// @Description  Cross-origin frontends send the cookie with credentials (CORS_SIGNIN_ALLOW_CREDENTIALS=true).
+// @Tags         signin
+// @Produce      json
+// @Success      200  {object}  dto.RememberedEmailResponse
+// @Failure      404  {object}  dto.ErrorResponse  "code: not_remembered, no email or a cookie that isn't ours"
+// @Router       /signin/remembered-email [get]
+func RememberedEmail(c *fiber.Ctx) error {
+	email, stale := parseRememberedEmail(c.Cookies(rememberedEmailCookie))
+	if email == "" {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "No remembered email", "code": "not_remembered"})
+	}
+	// keys rotate out: move the cookie to the current one while it's still accepted
+	if stale {
+		setRememberedEmailCookie(c, email)
+	}
+	return c.JSON(dto.RememberedEmailResponse{Email: email})
+}
+
+// RememberEmail godoc
+// @Summary      Remember an email on this browser
+// @Description  Saves the email in a signed, HTTP-only cookie for SIGNIN_REMEMBER_EMAIL_DAYS (365 by default), read back by GET /signin/remembered-email.
+// @Description  The cookie only holds the address: it doesn't sign in.
+// @Tags         signin
+// @Accept       json
+// @Produce      json
+// @Param        body  body      dto.RememberEmailRequest  true  "Email to remember"
+// @Success      200   {object}  dto.RememberedEmailResponse
+// @Failure      400   {object}  dto.ErrorResponse
+// @Router       /signin/remembered-email [put]
+func RememberEmail(c *fiber.Ctx) error {
+	var req dto.RememberEmailRequest
+	if err := c.BodyParser(&req); err != nil || !service.IsValidEmail(req.Email) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid email"})
+	}
+	setRememberedEmailCookie(c, req.Email)
+	return c.JSON(dto.RememberedEmailResponse{Email: req.Email})
+}
+
+// ForgetEmail godoc
+// @Summary      Forget the email remembered by this browser
+// @Description  Expires the cookie set by PUT /signin/remembered-email, e.g. to sign in with another address.
+// @Tags         signin
+// @Success      204  {string}  string
+// @Router       /signin/remembered-email [delete]
+func ForgetEmail(c *fiber.Ctx) error {
+	setRememberedEmailCookie(c, "")
+	return c.SendStatus(fiber.StatusNoContent)
+}
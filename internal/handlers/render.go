@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// MIMEApplicationMsgPack is the media type of MessagePack responses
+const MIMEApplicationMsgPack = "application/msgpack"
+
+// xmlItemName names the elements of the entries of a JSON array in XML responses
+const xmlItemName = "item"
+
+// render writes v, with the status already set on c, in the format the Accept header
+// prefers: JSON (also when nothing acceptable is offered), XML or MessagePack. Both keep the
+// JSON field names: XML under a root element named root, array entries as <item> elements;
+// MessagePack as a map keyed like the JSON object.
+func render(c *fiber.Ctx, root string, v interface{}) error {
+	c.Vary(fiber.HeaderAccept)
+	switch c.Accepts(fiber.MIMEApplicationJSON, fiber.MIMEApplicationXML, MIMEApplicationMsgPack) {
+	case fiber.MIMEApplicationXML:
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		body, err := jsonToXML(root, raw)
+		if err != nil {
+			return err
+		}
+		c.Set(fiber.HeaderContentType, fiber.MIMEApplicationXMLCharsetUTF8)
+		return c.Send(body)
+	case MIMEApplicationMsgPack:
+		var buf bytes.Buffer
+		enc := msgpack.NewEncoder(&buf)
+		enc.SetCustomStructTag("json")
+		enc.UseCompactInts(true)
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+		c.Set(fiber.HeaderContentType, MIMEApplicationMsgPack)
+		return c.Send(buf.Bytes())
+	default:
+		return c.JSON(v)
+	}
+}
+
+// jsonToXML converts a JSON document to XML, keeping the order of the object keys
+func jsonToXML(root string, raw []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	if err := writeXMLValue(enc, dec, root); err != nil {
+		return nil, err
+	}
+	if err := enc.Flush(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeXMLValue writes the next JSON value of dec as an element called name; null is an
+// empty element
+func writeXMLValue(enc *xml.Encoder, dec *json.Decoder, name string) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	start := xml.StartElement{Name: xml.Name{Local: xmlName(name)}}
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		for dec.More() {
+			child := xmlItemName
+			if t == '{' {
+				key, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				child = key.(string)
+			}
+			if err := writeXMLValue(enc, dec, child); err != nil {
+				return err
+			}
+		}
+		// the closing } or ]
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+	case nil:
+	default:
+		if err := enc.EncodeToken(xml.CharData(fmt.Sprint(t))); err != nil {
+			return err
+		}
+	}
+	return enc.EncodeToken(start.End())
+}
+
+// xmlName turns a JSON key, e.g. a free-form metadata key, into a valid element name:
+// characters XML doesn't allow become _, as does a leading one a name can't start with
+func xmlName(key string) string {
+	name := []rune(key)
+	for i, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("_-.", r) {
+			name[i] = '_'
+		}
+	}
+	if len(name) == 0 || (!unicode.IsLetter(name[0]) && name[0] != '_') {
+		name = append([]rune{'_'}, name...)
+	}
+	return string(name)
+}
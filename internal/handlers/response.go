@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// subscriberResponse serializes a subscriber for the caller, masking PII unless it may see it
+func subscriberResponse(c *fiber.Ctx, s models.Subscriber) dto.SubscriberResponse {
+	return redactSubscriberFor(c, dto.NewSubscriberResponse(s))
+}
+
+// subscriberResponses serializes a list of subscribers for the caller, masking PII unless it may see it
+func subscriberResponses(c *fiber.Ctx, subs []models.Subscriber) []dto.SubscriberResponse {
+	return redactSubscribersFor(c, dto.NewSubscriberResponses(subs))
+}
+
+// orgScope restricts a query to the caller's organization (see middleware.CurrentOrgID)
+func orgScope(c *fiber.Ctx) func(*gorm.DB) *gorm.DB {
+	return orgIDScope(middleware.CurrentOrgID(c))
+}
+
+// orgIDScope restricts a query to the organization orgID
+func orgIDScope(orgID uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(clause.Eq{
+			Column: clause.Column{Table: clause.CurrentTable, Name: "org_id"},
+			Value:  orgID,
+		})
+	}
+}
+
+// redactSubscriberFor masks PII in an already built response unless the caller may see it
+func redactSubscriberFor(c *fiber.Ctx, resp dto.SubscriberResponse) dto.SubscriberResponse {
+	if !middleware.CanSeePII(c) {
+		return resp.Redacted()
+	}
+	return resp
+}
+
+// redactSubscribersFor masks PII in already built responses unless the caller may see it
+func redactSubscribersFor(c *fiber.Ctx, resp []dto.SubscriberResponse) []dto.SubscriberResponse {
+	if !middleware.CanSeePII(c) {
+		return dto.RedactSubscribers(resp)
+	}
+	return resp
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
+	"fiber-gorm-api/internal/resthooks"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// invalidEventError lists the events hooks may subscribe to
+func invalidEventError(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		"error": "Invalid event, expected one of " + strings.Join(resthooks.Events, ", "),
+	})
+}
+
+// SubscribeRestHook godoc
+// @Summary      Subscribe a REST hook
+// @Description  Subscribes target_url to a subscriber event, following the REST Hooks convention of Zapier. Every event of the organization is POSTed to it as a dto.RestHookPayload, with the subscriber as it is when delivered; failed deliveries are retried with backoff, and a target answering 410 Gone is unsubscribed.
+// @Description  Payloads hold raw emails, so this needs the pii scope.
+// @Tags         rest-hooks
+// @Accept       json
+// @Produce      json
+// @Param        body  body      dto.CreateRestHookRequest  true  "Target URL and event"
+// @Success      201   {object}  dto.RestHookResponse
+// @Failure      400   {object}  dto.ErrorResponse
+// @Failure      403   {object}  dto.ErrorResponse
+// @Failure      500   {object}  dto.ErrorResponse
+// @Router       /admin/rest-hooks [post]
+func SubscribeRestHook(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req dto.CreateRestHookRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Unable to parse request body"})
+		}
+		if !resthooks.IsEvent(req.Event) {
+			return invalidEventError(c)
+		}
+		req.TargetURL = strings.TrimSpace(req.TargetURL)
+		if err := resthooks.ValidateTargetURL(req.TargetURL); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+
+		hook := models.RestHook{
+			OrgID:     middleware.CurrentOrgID(c),
+			Event:     req.Event,
+			TargetURL: req.TargetURL,
+			CreatedBy: callerIdentity(c),
+		}
+		if err := db.WithContext(c.UserContext()).Create(&hook).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not subscribe hook"})
+		}
+		c.Location("/admin/rest-hooks/" + strconv.FormatUint(uint64(hook.ID), 10))
+		return c.Status(fiber.StatusCreated).JSON(dto.NewRestHookResponse(hook))
+	}
+}
+
+// GetRestHooks godoc
+// @Summary      List REST hooks
+// @Description  Lists the REST hooks subscribed in the organization.
+// @Tags         rest-hooks
+// @Produce      json
+// @Success      200  {array}   dto.RestHookResponse
+// @Failure      403  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/rest-hooks [get]
+func GetRestHooks(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var hooks []models.RestHook
+		if err := db.WithContext(c.UserContext()).Scopes(orgScope(c)).Order("id").Find(&hooks).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve hooks"})
+		}
+		resp := make([]dto.RestHookResponse, len(hooks))
+		for i, h := range hooks {
+			resp[i] = dto.NewRestHookResponse(h)
+		}
+		return c.JSON(resp)
+	}
+}
+
+// UnsubscribeRestHook godoc
+// @Summary      Unsubscribe a REST hook
+// @Description  Deletes a REST hook; events still queued for it are dropped.
+// @Tags         rest-hooks
+// @Param        id   path  int  true  "Hook ID"
+// @Success      204  {string}  string
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      403  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/rest-hooks/{id} [delete]
+func UnsubscribeRestHook(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := strconv.Atoi(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid hook ID"})
+		}
+		res := db.WithContext(c.UserContext()).Scopes(orgScope(c)).Delete(&models.RestHook{}, id)
+		if res.Error != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not unsubscribe hook"})
+		}
+		if res.RowsAffected == 0 {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Hook not found"})
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	}
+}
+
+// GetRestHookSample godoc
+// @Summary      Sample REST hook payloads
+// @Description  Returns payloads of an event for the most recent subscribers of the organization (a made-up one when there are none yet), the way deliveries look. No-code tools such as Zapier poll it to show the fields while a zap is set up.
+// @Tags         rest-hooks
+// @Produce      json
+// @Param        event  query     string  true  "Event"  Enums(subscriber.created, subscriber.updated, subscriber.deleted)
+// @Success      200    {array}   dto.RestHookPayload
+// @Failure      400    {object}  dto.ErrorResponse
+// @Failure      403    {object}  dto.ErrorResponse
+// @Failure      500    {object}  dto.ErrorResponse
+// @Router       /admin/rest-hooks/sample [get]
+func GetRestHookSample(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		event := c.Query("event")
+		if !resthooks.IsEvent(event) {
+			return invalidEventError(c)
+		}
+		samples, err := resthooks.Sample(c.UserContext(), db, middleware.CurrentOrgID(c), event)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not build samples"})
+		}
+		return c.JSON(samples)
+	}
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"errors"
+	"os"
+	"strconv"
+
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
+	"fiber-gorm-api/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// GetReviewQueue godoc
+// @Summary      List quarantined signups
+// @Description  Lists the organization's public signups the spam checks quarantined, oldest first, with their spam score and the points of each check that fired.
+// @Description  limit (max 500), offset and cursor page through the queue like the subscriber list.
+// @Tags         review-queue
+// @Produce      json
+// @Param        limit   query     int     false  "Page size (max 500)"
+// @Param        offset  query     int     false  "Rows to skip"
+// @Param        cursor  query     string  false  "Opaque cursor from X-Next-Cursor"
+// @Success      200  {array}   dto.ReviewQueueItem
+// @Header       200  {string}  X-Next-Cursor  "Cursor for the next page, absent on the last page"
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/review-queue [get]
+func GetReviewQueue(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		page, err := parsePageParams(c)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+
+		var subscribers []models.Subscriber
+		query := db.Scopes(orgScope(c)).Where("status = ?", models.SubscriberStatusQuarantined)
+		if err := page.apply(query).Find(&subscribers).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve the review queue"})
+		}
+		if page.Paginated() && len(subscribers) > page.Limit {
+			subscribers = subscribers[:page.Limit]
+			last := subscribers[len(subscribers)-1]
+			c.Set(NextCursorHeader, page.nextCursor(last.ID, last.CreatedAt, last.UpdatedAt))
+		}
+		if err := loadSubscriberTypes(db, subscribers); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve the review queue"})
+		}
+
+		responses := subscriberResponses(c, subscribers)
+		items := make([]dto.ReviewQueueItem, len(subscribers))
+		for i, s := range subscribers {
+			items[i] = dto.ReviewQueueItem{
+				SubscriberResponse: responses[i],
+				SpamScore:          s.SpamScore,
+				SpamSignals:        s.SpamSignals,
+			}
+			if items[i].SpamSignals == nil {
+				items[i].SpamSignals = map[string]interface{}{}
+			}
+		}
+		return c.JSON(items)
+	}
+}
+
+// ApproveQuarantinedSubscriber godoc
+// @Summary      Approve a quarantined signup
+// @Description  Lets a quarantined signup through as an approved revision: active, or with SIGNUP_DOUBLE_OPT_IN=true pending and emailed the confirmation link it was held back from.
+// @Tags         review-queue
+// @Produce      json
+// @Param        id   path      int true "Subscriber ID"
+// @Success      200  {object}  dto.SubscriberResponse
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      409  {object}  dto.ErrorResponse  "code: not_quarantined"
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/review-queue/{id}/approve [post]
+func ApproveQuarantinedSubscriber(db *gorm.DB) fiber.Handler {
+	svc := subscriberService(db)
+	return func(c *fiber.Ctx) error {
+		id, err := strconv.Atoi(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid subscriber ID"})
+		}
+
+		doubleOptIn := os.Getenv("SIGNUP_DOUBLE_OPT_IN") == "true"
+		subscriber, err := svc.Approve(c.UserContext(), middleware.CurrentOrgID(c), uint(id), doubleOptIn)
+		if err != nil {
+			return reviewFailed(c, err, "Could not approve subscriber")
+		}
+		c.Set(fiber.HeaderETag, subscriberETag(subscriber.ID, subscriber.UpdatedAt))
+		return c.JSON(subscriberResponse(c, *subscriber))
+	}
+}
+
+// RejectQuarantinedSubscriber godoc
+// @Summary      Reject a quarantined signup
+// @Description  Deletes a quarantined signup. Like any deletion it can be undone with POST /admin/subscribers/{id}/undelete until it's purged.
+// @Tags         review-queue
+// @Param        id   path      int true "Subscriber ID"
+// @Success      204  {string}  string
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      409  {object}  dto.ErrorResponse  "code: not_quarantined"
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/review-queue/{id}/reject [post]
+func RejectQuarantinedSubscriber(db *gorm.DB) fiber.Handler {
+	svc := subscriberService(db)
+	return func(c *fiber.Ctx) error {
+		id, err := strconv.Atoi(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid subscriber ID"})
+		}
+
+		if err := svc.Reject(c.UserContext(), middleware.CurrentOrgID(c), uint(id)); err != nil {
+			return reviewFailed(c, err, "Could not reject subscriber")
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	}
+}
+
+// reviewFailed writes the error of an approval or rejection
+func reviewFailed(c *fiber.Ctx, err error, message string) error {
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Subscriber not found"})
+	case errors.Is(err, service.ErrNotQuarantined):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Subscriber is not quarantined",
+			"code":  "not_quarantined",
+		})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": message})
+	}
+}
@@ -0,0 +1,416 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	database "fiber-gorm-api/internal/db"
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/filterexpr"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
+	"fiber-gorm-api/internal/repository"
+	"fiber-gorm-api/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+var (
+	errUnparsableSegment = errors.New("unable to parse request body")
+	errUnknownSegment    = errors.New("unknown segment")
+)
+
+// subscriberFilterOf maps the filter of a segment request onto a repository filter
+func subscriberFilterOf(f dto.SegmentFilter) repository.SubscriberFilter {
+	return repository.SubscriberFilter{
+		Statuses:        f.Status,
+		Verified:        f.Verified,
+		SubscriberType:  f.SubscriberType,
+		Frequency:       f.Frequency,
+		Channel:         f.Channel,
+		SubscriberTypes: f.SubscriberTypes,
+		Tags:            f.Tags,
+		CreatedAfter:    f.CreatedAfter,
+		CreatedBefore:   f.CreatedBefore,
+		Query:           f.Query,
+	}
+}
+
+// filterRejected writes the 400 of a subscriber filter that Scope rejected, pointing at the part
+// of its expression at fault if that's the cause
+func filterRejected(c *fiber.Ctx, err error) error {
+	var exprErr *filterexpr.Error
+	if errors.As(err, &exprErr) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":    exprErr.Message,
+			"code":     "invalid_filter",
+			"position": exprErr.Pos,
+			"length":   exprErr.Len,
+		})
+	}
+	return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+}
+
+// parseSegmentFilter checks the filter of a segment request
+func parseSegmentFilter(c *fiber.Ctx, db *gorm.DB, f dto.SegmentFilter) (repository.SubscriberFilter, error) {
+	filter := subscriberFilterOf(f)
+	accepted, err := repository.NewSubscriberRepository(db).TypeNames(c.UserContext())
+	if err != nil {
+		return filter, err
+	}
+	return filter, service.ValidateSegmentFilter(&filter, accepted)
+}
+
+// parseSegment reads and validates a create / update body
+func parseSegment(c *fiber.Ctx, db *gorm.DB) (models.Segment, error) {
+	var req dto.SegmentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return models.Segment{}, errUnparsableSegment
+	}
+	segment := req.ToModel()
+	if segment.Name == "" {
+		return segment, service.ErrMissingName
+	}
+	filter, err := parseSegmentFilter(c, db, req.Filter)
+	segment.Filter = filter.Map()
+	return segment, err
+}
+
+// segmentInvalid writes the error response of a body parseSegment or parseSegmentFilter rejected
+func segmentInvalid(c *fiber.Ctx, err error) error {
+	var invalid *service.ValidationError
+	var types *service.InvalidTypesError
+	var exprErr *filterexpr.Error
+	switch {
+	case errors.Is(err, errUnparsableSegment):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Unable to parse request body"})
+	case errors.As(err, &exprErr):
+		return filterRejected(c, err)
+	case errors.As(err, &invalid), errors.As(err, &types):
+		return subscriberValidationFailed(c, err)
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve subscriber types"})
+	}
+}
+
+// segmentNameTaken reports whether another segment of the caller's organization than id is
+// named name
+func segmentNameTaken(c *fiber.Ctx, db *gorm.DB, name string, id uint) bool {
+	var count int64
+	db.Model(&models.Segment{}).Scopes(orgScope(c)).Where("name = ? AND id <> ?", name, id).Count(&count)
+	return count > 0
+}
+
+// loadAdminSegment reads the segment of the caller's organization named by the id param,
+// returning the status and message of the error response when it can't
+func loadAdminSegment(c *fiber.Ctx, db *gorm.DB) (models.Segment, int, string) {
+	var segment models.Segment
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return segment, fiber.StatusBadRequest, "Invalid segment ID"
+	}
+	if err := db.Scopes(orgScope(c)).First(&segment, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return segment, fiber.StatusNotFound, "Segment not found"
+		}
+		return segment, fiber.StatusInternalServerError, "Could not retrieve segment"
+	}
+	return segment, 0, ""
+}
+
+// segmentFilter reads the filter of the caller's organization's segment id, for the lists and
+// exports targeted at it. Unknown segments are errUnknownSegment.
+func segmentFilter(c *fiber.Ctx, db *gorm.DB, id uint) (models.Segment, repository.SubscriberFilter, error) {
+	var segment models.Segment
+	err := db.Scopes(orgScope(c)).First(&segment, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return segment, repository.SubscriberFilter{}, errUnknownSegment
+	}
+	if err != nil {
+		return segment, repository.SubscriberFilter{}, err
+	}
+	filter, err := repository.FilterFromMap(segment.Filter)
+	return segment, filter, err
+}
+
+// previewSegment counts the subscribers of the caller's organization matching filter, by status
+func previewSegment(c *fiber.Ctx, db *gorm.DB, filter repository.SubscriberFilter) (dto.SegmentPreviewResponse, error) {
+	preview := dto.SegmentPreviewResponse{ByStatus: []dto.StatusCount{}, GeneratedAt: time.Now()}
+	scope, err := filter.Scope()
+	if err != nil {
+		return preview, err
+	}
+	err = database.Replica(db).Model(&models.Subscriber{}).Scopes(orgScope(c), scope).
+		Select("subscribers.status AS status, COUNT(*) AS count").
+		Group("subscribers.status").
+		Order("count DESC, status").
+		Scan(&preview.ByStatus).Error
+	for _, count := range preview.ByStatus {
+		preview.Members += count.Count
+	}
+	return preview, err
+}
+
+// CreateSegment godoc
+// @Summary      Create a segment
+// @Description  Saves an audience of the organization: a filter by status, verification, subscriber_type preferences, subscriber_types held, metadata tags, signup dates and a filter expression (query, see POST /admin/segments/validate). Names are unique within an organization.
+// @Description  Target it with GET /admin/subscribers?segment={id} and POST /admin/exports (segment_id); its members are computed each time.
+// @Description  subscriber_types must be listed by GET /admin/subscriber-types (422); other invalid filters are rejected with code invalid_filter, with the position of the part of the query at fault if that's the cause.
+// @Tags         segments
+// @Accept       json
+// @Produce      json
+// @Param        segment  body      dto.SegmentRequest  true  "Segment definition"
+// @Success      201      {object}  dto.SegmentResponse
+// @Failure      400      {object}  dto.FilterErrorResponse
+// @Failure      409      {object}  dto.ErrorResponse
+// @Failure      422      {object}  dto.InvalidSubscriberTypesResponse
+// @Failure      500      {object}  dto.ErrorResponse
+// @Router       /admin/segments [post]
+func CreateSegment(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		segment, err := parseSegment(c, db)
+		if err != nil {
+			return segmentInvalid(c, err)
+		}
+		if segmentNameTaken(c, db, segment.Name, 0) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Name already taken"})
+		}
+
+		segment.OrgID = middleware.CurrentOrgID(c)
+		segment.CreatedBy = callerIdentity(c)
+		if err := db.Create(&segment).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not create segment"})
+		}
+		c.Location("/admin/segments/" + strconv.FormatUint(uint64(segment.ID), 10))
+		return c.Status(fiber.StatusCreated).JSON(dto.NewSegmentResponse(segment))
+	}
+}
+
+// GetSegments godoc
+// @Summary      List segments
+// @Description  Lists the segments of the organization.
+// @Tags         segments
+// @Produce      json
+// @Success      200  {array}   dto.SegmentResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/segments [get]
+func GetSegments(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var segments []models.Segment
+		if err := db.WithContext(c.UserContext()).Scopes(orgScope(c)).Order("id").Find(&segments).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve segments"})
+		}
+		resp := make([]dto.SegmentResponse, len(segments))
+		for i, s := range segments {
+			resp[i] = dto.NewSegmentResponse(s)
+		}
+		return c.JSON(resp)
+	}
+}
+
+// GetSegment godoc
+// @Summary      Get a segment
+// @Tags         segments
+// @Produce      json
+// @Param        id   path      int  true  "Segment ID"
+// @Success      200  {object}  dto.SegmentResponse
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/segments/{id} [get]
+func GetSegment(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		segment, status, msg := loadAdminSegment(c, db.WithContext(c.UserContext()))
+		if status != 0 {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+		return c.JSON(dto.NewSegmentResponse(segment))
+	}
+}
+
+// UpdateSegment godoc
+// @Summary      Update a segment
+// @Description  Replaces the name, description and filter of a segment, validated as on create. Exports already queued keep the filter they were queued with.
+// @Tags         segments
+// @Accept       json
+// @Produce      json
+// @Param        id       path      int                 true  "Segment ID"
+// @Param        segment  body      dto.SegmentRequest  true  "Segment definition"
+// @Success      200      {object}  dto.SegmentResponse
+// @Failure      400      {object}  dto.FilterErrorResponse
+// @Failure      404      {object}  dto.ErrorResponse
+// @Failure      409      {object}  dto.ErrorResponse
+// @Failure      422      {object}  dto.InvalidSubscriberTypesResponse
+// @Failure      500      {object}  dto.ErrorResponse
+// @Router       /admin/segments/{id} [put]
+func UpdateSegment(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		current, status, msg := loadAdminSegment(c, db)
+		if status != 0 {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+		segment, err := parseSegment(c, db)
+		if err != nil {
+			return segmentInvalid(c, err)
+		}
+		if segmentNameTaken(c, db, segment.Name, current.ID) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Name already taken"})
+		}
+
+		segment.ID, segment.OrgID = current.ID, current.OrgID
+		segment.CreatedBy, segment.CreatedAt = current.CreatedBy, current.CreatedAt
+		if err := db.Save(&segment).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not update segment"})
+		}
+		return c.JSON(dto.NewSegmentResponse(segment))
+	}
+}
+
+// DeleteSegment godoc
+// @Summary      Delete a segment
+// @Description  Deletes a segment. Its subscribers are kept, and exports already queued for it still run. Segments of campaigns not sent or cancelled yet can't be deleted (409 with code segment_in_use).
+// @Tags         segments
+// @Param        id   path  int  true  "Segment ID"
+// @Success      204  {string}  string
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      409  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/segments/{id} [delete]
+func DeleteSegment(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := strconv.Atoi(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid segment ID"})
+		}
+		db := db.WithContext(c.UserContext())
+		// a campaign left without its segment would be sent to every subscriber
+		var inUse int64
+		err = db.Model(&models.Campaign{}).Scopes(orgScope(c)).
+			Where("segment_id = ? AND status NOT IN ?", id, []string{models.CampaignSent, models.CampaignCancelled}).
+			Count(&inUse).Error
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not delete segment"})
+		}
+		if inUse > 0 {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "The segment is used by campaigns not sent or cancelled yet",
+				"code":  "segment_in_use",
+			})
+		}
+		res := db.Scopes(orgScope(c)).Delete(&models.Segment{}, id)
+		if res.Error != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not delete segment"})
+		}
+		if res.RowsAffected == 0 {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Segment not found"})
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	}
+}
+
+// PreviewSegment godoc
+// @Summary      Preview a segment
+// @Description  Counts the subscribers a segment holds now, in total and by status.
+// @Tags         segments
+// @Produce      json
+// @Param        id   path      int  true  "Segment ID"
+// @Success      200  {object}  dto.SegmentPreviewResponse
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/segments/{id}/preview [get]
+func PreviewSegment(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		segment, status, msg := loadAdminSegment(c, db)
+		if status != 0 {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+		filter, err := repository.FilterFromMap(segment.Filter)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not read segment filter"})
+		}
+		preview, err := previewSegment(c, db, filter)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not count members"})
+		}
+		return c.JSON(preview)
+	}
+}
+
+// PreviewSegmentFilter godoc
+// @Summary      Preview a segment filter
+// @Description  Counts the subscribers a filter would hold, in total and by status, to tune a segment before saving it. The filter is validated as on create.
+// @Tags         segments
+// @Accept       json
+// @Produce      json
+// @Param        filter  body      dto.SegmentFilter  true  "Segment filter"
+// @Success      200     {object}  dto.SegmentPreviewResponse
+// @Failure      400     {object}  dto.FilterErrorResponse
+// @Failure      422     {object}  dto.InvalidSubscriberTypesResponse
+// @Failure      500     {object}  dto.ErrorResponse
+// @Router       /admin/segments/preview [post]
+func PreviewSegmentFilter(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		var req dto.SegmentFilter
+		if err := c.BodyParser(&req); err != nil {
+			return segmentInvalid(c, errUnparsableSegment)
+		}
+		filter, err := parseSegmentFilter(c, db, req)
+		if err != nil {
+			return segmentInvalid(c, err)
+		}
+		preview, err := previewSegment(c, db, filter)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not count members"})
+		}
+		return c.JSON(preview)
+	}
+}
+
+// ValidateFilterExpression godoc
+// @Summary      Validate a filter expression
+// @Description  Checks a filter expression, as taken by segments (filter.query), exports (filters.query) and the filter parameter of GET /admin/subscribers and /admin/subscribers/search, e.g. "type:shopper AND created_at>2024-01-01 AND NOT tag:vip".
+// @Description  Conditions are field, operator (: or = for equality, !=, >, >=, <, <=) and value, double-quoted if it holds spaces or parentheses. They're combined with AND (also implied between conditions), OR, NOT and parentheses.
+// @Description  Fields: status, verified, type (alias subscriber_type, must be listed by GET /admin/subscriber-types), frequency, channel, tag, email and name (* matching any text), country, locale, utm_source, utm_medium, utm_campaign, referrer, spam_score, created_at and updated_at (a date compares whole days, or an RFC 3339 time).
+// @Description  An invalid expression isn't an error: the response points at the part at fault, its position and length counting characters from 0.
+// @Tags         segments
+// @Accept       json
+// @Produce      json
+// @Param        query  body      dto.FilterValidationRequest  true  "Filter expression"
+// @Success      200    {object}  dto.FilterValidationResponse
+// @Failure      400    {object}  dto.ErrorResponse
+// @Failure      500    {object}  dto.ErrorResponse
+// @Router       /admin/segments/validate [post]
+func ValidateFilterExpression(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req dto.FilterValidationRequest
+		if err := c.BodyParser(&req); err != nil {
+			return segmentInvalid(c, errUnparsableSegment)
+		}
+		if strings.TrimSpace(req.Query) == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing query"})
+		}
+		accepted, err := repository.NewSubscriberRepository(db.WithContext(c.UserContext())).TypeNames(c.UserContext())
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve subscriber types"})
+		}
+
+		var exprErr *filterexpr.Error
+		if err := service.ValidateFilterExpr(req.Query, accepted); errors.As(err, &exprErr) {
+			return c.JSON(dto.FilterValidationResponse{Error: &dto.FilterError{
+				Message:  exprErr.Message,
+				Position: exprErr.Pos,
+				Length:   exprErr.Len,
+			}})
+		}
+		return c.JSON(dto.FilterValidationResponse{Valid: true})
+	}
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"errors"
+	"sort"
+
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/session"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ListSessions godoc
+// @Summary      List active sessions
+// @Description  Lists every active session (device) of the authenticated user, newest first
+// @Tags         sessions
+// @Produce      json
+// @Success      200  {array}   dto.SessionResponse
+// @Failure      401  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/sessions [get]
+func ListSessions(c *fiber.Ctx) error {
+	current := middleware.CurrentSession(c)
+	if current == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Not signed in"})
+	}
+
+	sessions, err := session.ListForEmail(c.UserContext(), current.Email)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not load sessions"})
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].CreatedAt.After(sessions[j].CreatedAt)
+	})
+
+	return c.JSON(dto.NewSessionResponses(sessions, current.ID))
+}
+
+// RevokeSession godoc
+// @Summary      Revoke a session
+// @Description  Signs out one device of the authenticated user. Revoking the current session signs you out.
+// @Tags         sessions
+// @Param        id   path      string true "Session ID"
+// @Success      204  {string}  string
+// @Failure      401  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/sessions/{id} [delete]
+func RevokeSession(c *fiber.Ctx) error {
+	current := middleware.CurrentSession(c)
+	if current == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Not signed in"})
+	}
+
+	err := session.Revoke(c.UserContext(), current.Email, c.Params("id"))
+	if errors.Is(err, session.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Session not found"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not revoke session"})
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
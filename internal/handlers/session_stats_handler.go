@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"sort"
+	"time"
+
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/geoip"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
+	"fiber-gorm-api/internal/session"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetSessionStats godoc
+// @Summary      Active session stats
+// @Description  Counts the active admin sessions of the organization by device type (desktop, mobile, tablet, bot, other), OS, browser and country.
+// @Description  Clients are parsed from the User-Agent at sign-in. Countries need the MaxMind DB at GEOIP_DB_PATH: without it they're all unknown.
+// @Tags         stats
+// @Produce      json
+// @Success      200  {object}  dto.SessionStatsResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/stats/sessions [get]
+func GetSessionStats(c *fiber.Ctx) error {
+	sessions, err := session.ListAll(c.UserContext())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not load sessions"})
+	}
+	return c.JSON(sessionStats(sessions, middleware.CurrentOrgID(c)))
+}
+
+// sessionStats aggregates the sessions of orgID. Sessions without an organization belong to
+// the default one.
+func sessionStats(sessions []session.Session, orgID uint) dto.SessionStatsResponse {
+	devices, systems, browsers, countries := map[string]int64{}, map[string]int64{}, map[string]int64{}, map[string]int64{}
+	stats := dto.SessionStatsResponse{GeoIPEnabled: geoip.Enabled(), GeneratedAt: time.Now()}
+	for _, sess := range sessions {
+		owner := sess.OrgID
+		if owner == 0 {
+			owner = models.DefaultOrgID
+		}
+		if owner != orgID {
+			continue
+		}
+		stats.ActiveSessions++
+		client := sess.Client()
+		devices[orUnknown(client.Device)]++
+		systems[orUnknown(client.OS)]++
+		browsers[orUnknown(client.Browser)]++
+		countries[orUnknown(sess.Country)]++
+	}
+	stats.ByDevice = sortedCounts(devices)
+	stats.ByOS = sortedCounts(systems)
+	stats.ByBrowser = sortedCounts(browsers)
+	stats.ByCountry = sortedCounts(countries)
+	return stats
+}
+
+func orUnknown(value string) string {
+	if value == "" {
+		return "unknown"
+	}
+	return value
+}
+
+// sortedCounts lists counts, most common first, ties by name
+func sortedCounts(counts map[string]int64) []dto.SessionCount {
+	out := make([]dto.SessionCount, 0, len(counts))
+	for name, n := range counts {
+		out = append(out, dto.SessionCount{Name: name, Count: n})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
@@ -0,0 +1,275 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
+	"fiber-gorm-api/internal/service"
+	"fiber-gorm-api/internal/shortlinks"
+	"fiber-gorm-api/internal/tracking"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+var (
+	errUnparsableShortLink = errors.New("unable to parse request body")
+	errUnknownCampaign     = errors.New("unknown campaign")
+)
+
+// codeAttempts is how many generated codes are tried before giving up on a new short link
+const codeAttempts = 5
+
+// parseShortLink reads and validates a create / update body
+func parseShortLink(c *fiber.Ctx, db *gorm.DB) (models.ShortLink, error) {
+	var req dto.ShortLinkRequest
+	if err := c.BodyParser(&req); err != nil {
+		return models.ShortLink{}, errUnparsableShortLink
+	}
+	link := req.ToModel()
+	if err := service.ValidateShortLink(&link); err != nil {
+		return link, err
+	}
+	if link.CampaignID != nil {
+		var count int64
+		if err := db.Model(&models.Campaign{}).Scopes(orgScope(c)).Where("id = ?", *link.CampaignID).Count(&count).Error; err != nil {
+			return link, err
+		}
+		if count == 0 {
+			return link, errUnknownCampaign
+		}
+	}
+	return link, nil
+}
+
+// shortLinkInvalid writes the error response of a body parseShortLink rejected
+func shortLinkInvalid(c *fiber.Ctx, err error) error {
+	var invalid *service.ValidationError
+	switch {
+	case errors.Is(err, errUnparsableShortLink):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Unable to parse request body"})
+	case errors.Is(err, errUnknownCampaign):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Unknown campaign", "code": "unknown_campaign"})
+	case errors.As(err, &invalid):
+		return subscriberValidationFailed(c, err)
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve campaign"})
+	}
+}
+
+// codeTaken reports whether a short link other than id uses code, in any organization
+func codeTaken(db *gorm.DB, code string, id uint) bool {
+	var count int64
+	db.Model(&models.ShortLink{}).Where("code = ? AND id <> ?", code, id).Count(&count)
+	return count > 0
+}
+
+// loadAdminShortLink reads the short link of the caller's organization named by the id param,
+// returning the status and message of the error response when it can't
+func loadAdminShortLink(c *fiber.Ctx, db *gorm.DB) (models.ShortLink, int, string) {
+	var link models.ShortLink
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return link, fiber.StatusBadRequest, "Invalid short link ID"
+	}
+	if err := db.Scopes(orgScope(c)).First(&link, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return link, fiber.StatusNotFound, "Short link not found"
+		}
+		return link, fiber.StatusInternalServerError, "Could not retrieve short link"
+	}
+	return link, 0, ""
+}
+
+// CreateShortLink godoc
+// @Summary      Create a short link
+// @Description  Creates a short link of the organization, /l/{code} redirecting to url (an http(s) URL, code invalid_url). Codes are 3 to 32 lowercase letters, digits and dashes (code invalid_code), unique across organizations, and generated when omitted.
+// @Description  campaign_id attributes its clicks to a campaign of the organization (code unknown_campaign), see GET /admin/campaigns/{id}/stats. Short links in the HTML of a campaign aren't rewritten by its click tracking.
+// @Tags         short-links
+// @Accept       json
+// @Produce      json
+// @Param        link  body      dto.ShortLinkRequest  true  "Short link"
+// @Success      201   {object}  dto.ShortLinkResponse
+// @Failure      400   {object}  dto.ErrorResponse
+// @Failure      409   {object}  dto.ErrorResponse
+// @Failure      500   {object}  dto.ErrorResponse
+// @Router       /admin/short-links [post]
+func CreateShortLink(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		link, err := parseShortLink(c, db)
+		if err != nil {
+			return shortLinkInvalid(c, err)
+		}
+		if link.Code == "" {
+			for i := 0; i < codeAttempts && (link.Code == "" || codeTaken(db, link.Code, 0)); i++ {
+				link.Code = shortlinks.NewCode()
+			}
+		}
+		if codeTaken(db, link.Code, 0) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Code already taken"})
+		}
+
+		link.OrgID = middleware.CurrentOrgID(c)
+		link.CreatedBy = callerIdentity(c)
+		if err := db.Create(&link).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not create short link"})
+		}
+		c.Location("/admin/short-links/" + strconv.FormatUint(uint64(link.ID), 10))
+		return c.Status(fiber.StatusCreated).JSON(dto.NewShortLinkResponse(link))
+	}
+}
+
+// GetShortLinks godoc
+// @Summary      List short links
+// @Description  Lists the short links of the organization with their clicks, those of a campaign with campaign_id.
+// @Tags         short-links
+// @Produce      json
+// @Param        campaign_id  query     int  false  "Campaign the clicks are attributed to"
+// @Success      200          {array}   dto.ShortLinkResponse
+// @Failure      400          {object}  dto.ErrorResponse
+// @Failure      500          {object}  dto.ErrorResponse
+// @Router       /admin/short-links [get]
+func GetShortLinks(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		query := db.WithContext(c.UserContext()).Scopes(orgScope(c)).Order("id")
+		if raw := c.Query("campaign_id"); raw != "" {
+			campaignID, err := strconv.ParseUint(raw, 10, 64)
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid campaign_id"})
+			}
+			query = query.Where("campaign_id = ?", campaignID)
+		}
+		var links []models.ShortLink
+		if err := query.Find(&links).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve short links"})
+		}
+		resp := make([]dto.ShortLinkResponse, len(links))
+		for i, l := range links {
+			resp[i] = dto.NewShortLinkResponse(l)
+		}
+		return c.JSON(resp)
+	}
+}
+
+// GetShortLink godoc
+// @Summary      Get a short link
+// @Tags         short-links
+// @Produce      json
+// @Param        id   path      int  true  "Short link ID"
+// @Success      200  {object}  dto.ShortLinkResponse
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/short-links/{id} [get]
+func GetShortLink(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		link, status, msg := loadAdminShortLink(c, db.WithContext(c.UserContext()))
+		if status != 0 {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+		return c.JSON(dto.NewShortLinkResponse(link))
+	}
+}
+
+// UpdateShortLink godoc
+// @Summary      Update a short link
+// @Description  Changes the code, URL and campaign of a short link, validated as on create; the code is kept when omitted. Its clicks are kept. Links handed out with the old code stop working.
+// @Tags         short-links
+// @Accept       json
+// @Produce      json
+// @Param        id    path      int                   true  "Short link ID"
+// @Param        link  body      dto.ShortLinkRequest  true  "Short link"
+// @Success      200   {object}  dto.ShortLinkResponse
+// @Failure      400   {object}  dto.ErrorResponse
+// @Failure      404   {object}  dto.ErrorResponse
+// @Failure      409   {object}  dto.ErrorResponse
+// @Failure      500   {object}  dto.ErrorResponse
+// @Router       /admin/short-links/{id} [put]
+func UpdateShortLink(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		current, status, msg := loadAdminShortLink(c, db)
+		if status != 0 {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+		link, err := parseShortLink(c, db)
+		if err != nil {
+			return shortLinkInvalid(c, err)
+		}
+		if link.Code == "" {
+			link.Code = current.Code
+		}
+		if codeTaken(db, link.Code, current.ID) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Code already taken"})
+		}
+
+		current.Code, current.URL, current.CampaignID = link.Code, link.URL, link.CampaignID
+		if err := db.Select("code", "url", "campaign_id", "updated_at").Save(&current).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not update short link"})
+		}
+		return c.JSON(dto.NewShortLinkResponse(current))
+	}
+}
+
+// DeleteShortLink godoc
+// @Summary      Delete a short link
+// @Description  Deletes a short link; it's then answered 404.
+// @Tags         short-links
+// @Param        id   path  int  true  "Short link ID"
+// @Success      204  {string}  string
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/short-links/{id} [delete]
+func DeleteShortLink(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := strconv.Atoi(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid short link ID"})
+		}
+		res := db.WithContext(c.UserContext()).Scopes(orgScope(c)).Delete(&models.ShortLink{}, id)
+		if res.Error != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not delete short link"})
+		}
+		if res.RowsAffected == 0 {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Short link not found"})
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	}
+}
+
+// FollowShortLink godoc
+// @Summary      Short link
+// @Description  Redirects to the URL of the short link of code, in any case (see /admin/short-links), counting the click unless it comes from a bot or a mail security scanner.
+// @Tags         short-links
+// @Param        code  path  string  true  "Code of the short link"
+// @Success      302
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /l/{code} [get]
+func FollowShortLink(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		var link models.ShortLink
+		if err := db.Where("code = ?", strings.ToLower(c.Params("code"))).First(&link).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Link not found"})
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve link"})
+		}
+		if !tracking.IsBot(c.Get(fiber.HeaderUserAgent)) {
+			if err := shortlinks.Click(db, link.ID, time.Now()); err != nil {
+				log.Printf("[WARN] Short links: counting a click on %s failed: %v", link.Code, err)
+			}
+		}
+		c.Set(fiber.HeaderCacheControl, "no-store, max-age=0")
+		return c.Redirect(link.URL, fiber.StatusFound)
+	}
+}
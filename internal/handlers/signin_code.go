@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Sign-in codes are SIGNIN_CODE_LENGTH characters of SIGNIN_CODE_ALPHABET, valid for
+// SIGNIN_CODE_TTL_MINUTES.
+const (
+	defaultSignInCodeLength = 6
+	minSignInCodeLength     = 6
+	maxSignInCodeLength     = 10
+	defaultSignInCodeTTL    = 5 * time.Minute
+
+	signInCodeNumeric      = "numeric"
+	signInCodeAlphanumeric = "alphanumeric"
+
+	numericAlphabet = "0123456789"
+	// no I or O, easily taken for 1 and 0
+	alphanumericAlphabet = "0123456789ABCDEFGHJKLMNPQRSTUVWXYZ"
+)
+
+// signInCodeLength is SIGNIN_CODE_LENGTH, from 6 to 10 characters, 6 by default
+func signInCodeLength() int {
+	value := os.Getenv("SIGNIN_CODE_LENGTH")
+	if value == "" {
+		return defaultSignInCodeLength
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < minSignInCodeLength || n > maxSignInCodeLength {
+		log.Printf("[WARN] SIGNIN_CODE_LENGTH must be from %d to %d, using %d", minSignInCodeLength, maxSignInCodeLength, defaultSignInCodeLength)
+		return defaultSignInCodeLength
+	}
+	return n
+}
+
+// signInCodeAlphabet is what codes are made of, from SIGNIN_CODE_ALPHABET: digits (numeric, the
+// default), or digits and uppercase letters (alphanumeric)
+func signInCodeAlphabet() string {
+	if os.Getenv("SIGNIN_CODE_ALPHABET") == signInCodeAlphanumeric {
+		return alphanumericAlphabet
+	}
+	return numericAlphabet
+}
+
+// signInCodeTTL is how long a sign-in code stays valid, from SIGNIN_CODE_TTL_MINUTES
+func signInCodeTTL() time.Duration {
+	if n, err := strconv.Atoi(os.Getenv("SIGNIN_CODE_TTL_MINUTES")); err == nil && n > 0 {
+		return time.Duration(n) * time.Minute
+	}
+	return defaultSignInCodeTTL
+}
+
+// generateSignInCode returns a random code of the configured length and alphabet. Each character
+// is uniform over the alphabet: random bytes at or above the largest multiple of its size are
+// rejected rather than folded back by the modulo, which would favour the first characters.
+func generateSignInCode() (string, error) {
+	alphabet := signInCodeAlphabet()
+	length := signInCodeLength()
+	limit := 256 - 256%len(alphabet)
+
+	code := make([]byte, 0, length)
+	buf := make([]byte, length*2)
+	for len(code) < length {
+		if _, err := rand.Read(buf); err != nil {
+			return "", err
+		}
+		for _, b := range buf {
+			if int(b) >= limit {
+				continue
+			}
+			code = append(code, alphabet[int(b)%len(alphabet)])
+			if len(code) == length {
+				break
+			}
+		}
+	}
+	return string(code), nil
+}
+
+// normalizeSignInCode is code as typed, compared to the one sent: spaces around it dropped and,
+// codes having no lowercase letters, uppercased
+func normalizeSignInCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
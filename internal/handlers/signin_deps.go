@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"fiber-gorm-api/internal/mailqueue"
+
+	"gorm.io/gorm"
+)
+
+// EmailSender sends the emails of sign-in: codes and magic links, with the files of
+// email.Attachments. Attachments that can't be sent are a *sendgridservice.AttachmentError.
+type EmailSender interface {
+	Send(ctx context.Context, email mailqueue.Email) error
+}
+
+// TokenIssuer creates a session for email on the device of ip and userAgent and returns its JWT.
+// Errors are safe to return to the client.
+type TokenIssuer interface {
+	Issue(ctx context.Context, email, ip, userAgent string) (string, error)
+}
+
+// Clock tells the time codes expire and requests are counted from
+type Clock interface {
+	Now() time.Time
+}
+
+// NewQueuedEmailSender returns the EmailSender of the API: emails are queued in db, for the
+// outbox worker to send via SendGrid (see mailqueue)
+func NewQueuedEmailSender(db *gorm.DB) EmailSender {
+	return queuedEmailSender{db: db}
+}
+
+type queuedEmailSender struct {
+	db *gorm.DB
+}
+
+func (s queuedEmailSender) Send(ctx context.Context, email mailqueue.Email) error {
+	return mailqueue.Enqueue(s.db.WithContext(ctx), email)
+}
+
+// NewSessionTokenIssuer returns the TokenIssuer of the API: a session in Redis, with the
+// organization and role of the admin owning the email in db, referenced by a JWT
+func NewSessionTokenIssuer(db *gorm.DB) TokenIssuer {
+	return sessionTokenIssuer{db: db}
+}
+
+type sessionTokenIssuer struct {
+	db *gorm.DB
+}
+
+func (i sessionTokenIssuer) Issue(ctx context.Context, email, ip, userAgent string) (string, error) {
+	return newSessionToken(ctx, i.db, email, ip, userAgent)
+}
+
+// SystemClock is the Clock of the API, the time of the machine
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
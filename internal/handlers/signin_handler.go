@@ -1,126 +1,447 @@
 package handlers
 
 import (
+	"context"
 	"crypto/rand"
+	"crypto/subtle"
 	"encoding/base64"
+	"errors"
 	"fmt"
-	"log"
-	"time"
+	"strconv"
 
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/i18n"
+	"fiber-gorm-api/internal/mailqueue"
 	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
 	redisclient "fiber-gorm-api/internal/redis"
-	sendgridservice "fiber-gorm-api/internal/services"
+	"fiber-gorm-api/internal/resilience"
+	"fiber-gorm-api/internal/session"
+	"fiber-gorm-api/internal/sms"
+	"fiber-gorm-api/internal/telemetry"
 
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
-// Helper to form the Redis key for storing a sign-in code for the given email
+// Channels a sign-in code is sent over
+const (
+	signInChannelEmail = "email"
+	signInChannelSMS   = "sms"
+)
+
+// Methods of signing in by email: a code to type, or a link to open (see MagicLinkSignIn)
+const (
+	signInMethodCode = "code"
+	signInMethodLink = "link"
+)
+
+// signInCodeText is the text message of a sign-in code, translated with i18n.T
+const signInCodeText = "Your sign-in code is: %s"
+
+// Helper to form the Redis key for storing a sign-in code for the given email or phone
 func signInCodeKey(email string) string {
 	return "signin_code:" + email
 }
 
-// requestSignIn godoc
+// SigninHandler serves sign-in by code or magic link: codes and counters are kept in kv, emails
+// sent by emails, sessions created by tokens and time told by clock. Trusted devices, admins and
+// known addresses are looked up in db.
+type SigninHandler struct {
+	db     *gorm.DB
+	kv     redisclient.KVStore
+	emails EmailSender
+	tokens TokenIssuer
+	clock  Clock
+}
+
+// NewSigninHandler returns the sign-in handler backed by db, kv, emails, tokens and clock
+func NewSigninHandler(db *gorm.DB, kv redisclient.KVStore, emails EmailSender, tokens TokenIssuer, clock Clock) *SigninHandler {
+	return &SigninHandler{db: db, kv: kv, emails: emails, tokens: tokens, clock: clock}
+}
+
+// RequestSignIn godoc
 // @Summary      Request Sign In
-// @Description  Takes an email, generates a 6-digit code, stores in Redis, sends via SendGrid
+// @Description  Takes an email or a phone, generates a code, stores in Redis, sends via SendGrid or, for channel sms, a Twilio text message.
+// @Description  Codes are SIGNIN_CODE_LENGTH (6 to 10, 6 by default) digits, or digits and uppercase letters with SIGNIN_CODE_ALPHABET=alphanumeric, valid for SIGNIN_CODE_TTL_MINUTES (5 by default).
+// @Description  Emails are queued and sent in the background, retried while SendGrid is down: the response doesn't wait for them.
+// @Description  Phones are in E.164 form (+15551234567). A phone signs in as itself: admin roles stay with email addresses.
+// @Description  With method link, a single-use link to /signin/magic/{token} is emailed instead, valid for SIGNIN_MAGIC_LINK_TTL_MINUTES (15 by default).
+// @Description  A device trusted by this email or phone (trust_device at /signin/verify, cookie or X-Device-Token) gets a dto.TokenResponse right away instead.
+// @Description  Codes per address and hour are limited, separately for email (SIGNIN_EMAIL_REQUESTS_PER_HOUR, 10 by default, links included) and sms (SIGNIN_SMS_REQUESTS_PER_HOUR, 3 by default).
+// @Description  With SIGNIN_CODE_RECIPIENTS=known, only the addresses of admins and subscribers are emailed; others get the same 200, and every email request is answered after SIGNIN_RESPONSE_FLOOR_MS (500 by default), so neither tells them apart.
 // @Tags         signin
 // @Accept       json
 // @Produce      json
-// @Param        body  body      map[string]string  true  "e.g. { \"email\": \"user@example.com\" }"
-// @Success      200   {object}  map[string]string  "Code sent"
-// @Failure      400   {string}  string
+// @Param        body  body      dto.SignInRequest    true  "Email or phone to send the code to"
+// @Success      200   {object}  dto.MessageResponse  "Code sent (dto.TokenResponse for a trusted device)"
+// @Failure      400   {object}  dto.ErrorResponse  "code: invalid_phone, invalid_channel or invalid_method"
+// @Failure      429   {object}  dto.ErrorResponse  "code: too_many_requests"
+// @Failure      500   {object}  dto.ErrorResponse
+// @Failure      503   {object}  dto.ErrorResponse  "code: service_unavailable, with Retry-After, while Redis is down"
 // @Router       /signin/request [post]
-func RequestSignIn(c *fiber.Ctx) error {
-	var req struct {
-		Email string `json:"email"`
-	}
+func (h *SigninHandler) RequestSignIn(c *fiber.Ctx) error {
+	start := h.clock.Now()
+	db := h.db.WithContext(c.UserContext())
+	var req dto.SignInRequest
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
 	}
-	if req.Email == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing email"})
+	channel, to, err := signInDestination(req)
+	if err != nil {
+		return signInBadRequest(c, err)
+	}
+	switch {
+	case req.Method != "" && req.Method != signInMethodCode && req.Method != signInMethodLink:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Method must be code or link", "code": "invalid_method"})
+	case req.Method == signInMethodLink && channel != signInChannelEmail:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Sign-in links are only sent by email", "code": "invalid_method"})
 	}
 
-	code := generateSixDigitCode()
+	// a device remembered by this address skips the code
+	if trustedDevice(c, db, to) {
+		token, err := h.sessionToken(c, to)
+		return tokenResponse(c, token, err)
+	}
 
-	// store code in redis with 5 minute expiration
-	if err := redisclient.SetValue(signInCodeKey(req.Email), code, 5*time.Minute); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Unable to store code in redis"})
+	if wait, err := h.throttleSignInRequest(c.UserContext(), channel, to); errors.Is(err, resilience.ErrUnavailable) {
+		return middleware.ServiceUnavailable(c, err)
+	} else if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Unable to record sign-in request"})
+	} else if wait > 0 {
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(wait.Seconds())+1))
+		return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+			"error": "Too many sign-in codes requested, please try again later",
+			"code":  "too_many_requests",
+		})
 	}
 
-	// send code via sendgrid (stub function in 'sendgridservice')
-	if err := sendgridservice.SendCodeEmailFunc(req.Email, code); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to send email"})
+	// unknown addresses get the same answer, as late as known ones, without the email
+	if channel == signInChannelEmail && signInKnownOnly() {
+		defer h.waitResponseFloor(c.UserContext(), start)
+		known, err := signInAddressKnown(db, to)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Unable to check address"})
+		}
+		if !known {
+			return c.JSON(dto.MessageResponse{Message: signInSentMessage(channel, req.Method)})
+		}
 	}
 
-	return c.JSON(fiber.Map{
-		"message": "A sign-in code has been emailed to you.",
-	})
+	if req.Method == signInMethodLink {
+		err = h.sendMagicLink(c.UserContext(), to)
+	} else {
+		err = h.sendSignInCode(c.UserContext(), channel, to)
+	}
+	if errors.Is(err, resilience.ErrUnavailable) {
+		return middleware.ServiceUnavailable(c, err)
+	} else if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(dto.MessageResponse{Message: signInSentMessage(channel, req.Method)})
+}
+
+// signInSentMessage is the answer to a sign-in request sending a code over channel, or a link
+func signInSentMessage(channel, method string) string {
+	switch {
+	case method == signInMethodLink:
+		return "A sign-in link has been emailed to you."
+	case channel == signInChannelSMS:
+		return "A sign-in code has been texted to you."
+	default:
+		return "A sign-in code has been emailed to you."
+	}
+}
+
+var (
+	errSignInMissingEmail   = errors.New("missing email")
+	errSignInMissingPhone   = errors.New("missing phone")
+	errSignInInvalidPhone   = errors.New("invalid phone number")
+	errSignInInvalidChannel = errors.New("channel must be email or sms")
+)
+
+// signInDestination returns the channel of a sign-in request and where its code goes, the
+// email or the phone in E.164 form
+func signInDestination(req dto.SignInRequest) (channel, to string, err error) {
+	channel = req.Channel
+	if channel == "" {
+		channel = signInChannelEmail
+		if req.Email == "" && req.Phone != "" {
+			channel = signInChannelSMS
+		}
+	}
+
+	switch channel {
+	case signInChannelEmail:
+		if req.Email == "" {
+			return "", "", errSignInMissingEmail
+		}
+		return channel, req.Email, nil
+	case signInChannelSMS:
+		if req.Phone == "" {
+			return "", "", errSignInMissingPhone
+		}
+		phone := sms.NormalizePhone(req.Phone)
+		if phone == "" {
+			return "", "", errSignInInvalidPhone
+		}
+		return channel, phone, nil
+	default:
+		return "", "", errSignInInvalidChannel
+	}
+}
+
+// signInIdentity returns who a verification is for: the email, or else the phone in E.164 form
+func signInIdentity(email, phone string) (string, error) {
+	if email != "" || phone == "" {
+		return email, nil
+	}
+	if normalized := sms.NormalizePhone(phone); normalized != "" {
+		return normalized, nil
+	}
+	return "", errSignInInvalidPhone
+}
+
+// signInBadRequest writes the 400 of a sign-in request without a usable email or phone
+func signInBadRequest(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, errSignInInvalidPhone):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid phone number, use the E.164 format such as +15551234567",
+			"code":  "invalid_phone",
+		})
+	case errors.Is(err, errSignInInvalidChannel):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Channel must be email or sms", "code": "invalid_channel"})
+	case errors.Is(err, errSignInMissingPhone):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing phone"})
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing email"})
+	}
 }
 
-// verifySignIn godoc
+// VerifySignIn godoc
 // @Summary      Verify Sign In Code
-// @Description  Takes an email and 6-digit code. If valid, generate JWT & store session in redis
+// @Description  Takes the email or phone a code was sent to and the code, letters in either case. If valid, generate JWT & store session in redis.
+// @Description  After too many wrong codes the code is invalidated and verification is locked for a while.
+// @Description  With trust_device the device is remembered for TRUSTED_DEVICE_TTL_DAYS (30 by default): a cookie is set and device_token returned, for apps to send back in X-Device-Token.
 // @Tags         signin
 // @Accept       json
 // @Produce      json
-// @Param        body  body  map[string]string  true  "e.g. { \"email\": \"user@example.com\", \"code\": \"123456\" }"
-// @Success      200   {object}  map[string]string  "JWT returned"
-// @Failure      400   {string}  string
+// @Param        body  body      dto.VerifySignInRequest  true  "Email or phone and the code that was sent to it"
+// @Success      200   {object}  dto.TokenResponse  "JWT returned"
+// @Failure      400   {object}  dto.ErrorResponse  "code: invalid_phone"
+// @Failure      401   {object}  dto.ErrorResponse  "code: invalid_code"
+// @Failure      423   {object}  dto.ErrorResponse  "code: verification_locked"
+// @Failure      429   {object}  dto.ErrorResponse  "code: too_many_attempts"
+// @Failure      500   {object}  dto.ErrorResponse
+// @Failure      503   {object}  dto.ErrorResponse  "code: service_unavailable, with Retry-After, while Redis is down"
 // @Router       /signin/verify [post]
-func VerifySignIn(c *fiber.Ctx) error {
-	var req struct {
-		Email string `json:"email"`
-		Code  string `json:"code"`
-	}
+func (h *SigninHandler) VerifySignIn(c *fiber.Ctx) error {
+	db := h.db.WithContext(c.UserContext())
+	var req dto.VerifySignInRequest
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
 	}
-	if req.Email == "" || req.Code == "" {
+	identity, err := signInIdentity(req.Email, req.Phone)
+	if err != nil {
+		return signInBadRequest(c, err)
+	}
+	if identity == "" || req.Code == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing email or code"})
 	}
 
+	err = h.checkSignInCode(c.UserContext(), identity, req.Code)
+	h.countFailedSignIn(c.UserContext(), err)
+	switch {
+	case errors.Is(err, errSignInLocked):
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(h.verifyLockRemaining(c.UserContext(), identity).Seconds())+1))
+		return c.Status(fiber.StatusLocked).JSON(fiber.Map{
+			"error": "Too many failed attempts, verification is temporarily locked",
+			"code":  "verification_locked",
+		})
+	case errors.Is(err, errSignInCodeMissing):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "No sign-in code found or code expired"})
+	case errors.Is(err, errSignInTooManyAttempts):
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(lockoutDuration().Seconds())))
+		return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+			"error": "Too many failed attempts, the code has been invalidated",
+			"code":  "too_many_attempts",
+		})
+	case errors.Is(err, errSignInCodeInvalid):
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid code", "code": "invalid_code"})
+	case errors.Is(err, resilience.ErrUnavailable):
+		return middleware.ServiceUnavailable(c, err)
+	case err != nil:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Unable to record failed attempt"})
+	}
+
+	token, err := h.sessionToken(c, identity)
+	if !req.TrustDevice || err != nil {
+		return tokenResponse(c, token, err)
+	}
+	deviceToken, err := trustDevice(c, db, identity)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not remember device"})
+	}
+	return c.JSON(dto.TokenResponse{
+		Token:       token,
+		DeviceToken: deviceToken,
+	})
+}
+
+var (
+	errSignInLocked          = errors.New("verification is temporarily locked")
+	errSignInCodeMissing     = errors.New("no sign-in code found or code expired")
+	errSignInCodeInvalid     = errors.New("invalid code")
+	errSignInTooManyAttempts = errors.New("too many failed attempts, the code has been invalidated")
+)
+
+// sendSignInCode stores a fresh code for to, an email or a phone, in kv and sends it over
+// channel: texts right away, emails with the EmailSender. Errors are safe to return to the client.
+func (h *SigninHandler) sendSignInCode(ctx context.Context, channel, to string) (err error) {
+	ctx, span := telemetry.Start(ctx, "signin.send_code")
+	defer telemetry.End(span, &err)
+
+	code, err := generateSignInCode()
+	if err != nil {
+		return errors.New("Unable to generate code")
+	}
+
+	// store code in redis until it expires
+	if err := h.kv.SetValue(ctx, signInCodeKey(to), code, signInCodeTTL()); err != nil {
+		return clientError(err, "Unable to store code in redis")
+	}
+
+	// text code via twilio (sms.Sender, stubbed in tests), in the language of the request
+	if channel == signInChannelSMS {
+		err = telemetry.Trace(ctx, "twilio.send_code", func() error {
+			return sms.Sender.Send(ctx, to, fmt.Sprintf(i18n.T(i18n.FromContext(ctx), signInCodeText), code))
+		})
+		if err != nil {
+			return errors.New("Failed to send text message")
+		}
+		return nil
+	}
+
+	// queue the email for the outbox worker to send via sendgrid, in the language of the request
+	err = h.emails.Send(ctx, mailqueue.Email{
+		Kind:      mailqueue.KindSignInCode,
+		To:        to,
+		Locale:    i18n.FromContext(ctx),
+		Secret:    code,
+		ExpiresAt: h.clock.Now().Add(signInCodeTTL()),
+	})
+	if err != nil {
+		return errors.New("Failed to queue email")
+	}
+	return nil
+}
+
+// clientError hides err behind msg, safe to return to the client, unless err says a dependency
+// is down: that one is kept as is, for a 503 (see resilience.ErrUnavailable)
+func clientError(err error, msg string) error {
+	if errors.Is(err, resilience.ErrUnavailable) {
+		return err
+	}
+	return errors.New(msg)
+}
+
+// checkSignInCode verifies and consumes the code emailed to email, counting failed attempts
+// towards the lockout. Any other error means the attempt couldn't be recorded.
+func (h *SigninHandler) checkSignInCode(ctx context.Context, email, code string) (err error) {
+	ctx, span := telemetry.Start(ctx, "signin.check_code")
+	defer telemetry.End(span, &err)
+
+	// refuse while locked out after too many wrong guesses
+	if h.verifyLockRemaining(ctx, email) > 0 {
+		return errSignInLocked
+	}
+
 	// retrieve code from redis
-	storedCode, err := redisclient.GetValue(signInCodeKey(req.Email))
+	storedCode, err := h.kv.GetValue(ctx, signInCodeKey(email))
+	if errors.Is(err, resilience.ErrUnavailable) {
+		return err
+	}
 	if err != nil || storedCode == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "No sign-in code found or code expired"})
+		return errSignInCodeMissing
 	}
 
-	if storedCode != req.Code {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid code"})
+	if subtle.ConstantTimeCompare([]byte(storedCode), []byte(normalizeSignInCode(code))) != 1 {
+		locked, err := h.recordFailedVerify(ctx, email)
+		if err != nil {
+			return err
+		}
+		if locked {
+			return errSignInTooManyAttempts
+		}
+		return errSignInCodeInvalid
 	}
 
 	// Remove the code from redis (single-use)
-	_ = redisclient.DeleteKey(signInCodeKey(req.Email))
+	_ = h.kv.DeleteKey(ctx, signInCodeKey(email))
+	h.clearFailedVerifies(ctx, email)
+	return nil
+}
 
-	// Create user session (store minimal user profile in Redis)
-	sessionID := randomToken(16)
-	userProfile := fmt.Sprintf(`{"email":"%s"}`, req.Email)
-	if err := redisclient.SetValue("session:"+sessionID, userProfile, 24*time.Hour); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not store session"})
-	}
+// sessionToken creates a session for email on the calling device with the TokenIssuer and
+// returns its JWT
+func (h *SigninHandler) sessionToken(c *fiber.Ctx, email string) (string, error) {
+	return h.tokens.Issue(c.UserContext(), email, c.IP(), c.Get(fiber.HeaderUserAgent))
+}
 
-	// Generate JWT referencing this session
-	token, err := middleware.GenerateJWT(sessionID)
+// issueSessionToken creates a session for email on the calling device and responds with its JWT
+func issueSessionToken(c *fiber.Ctx, db *gorm.DB, email string) error {
+	token, err := createSessionToken(c, db, email)
+	return tokenResponse(c, token, err)
+}
+
+// tokenResponse responds with token, the JWT of a new session, or err, the error creating it
+func tokenResponse(c *fiber.Ctx, token string, err error) error {
+	if errors.Is(err, resilience.ErrUnavailable) {
+		return middleware.ServiceUnavailable(c, err)
+	}
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not create token"})
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	return c.JSON(fiber.Map{
-		"token": token,
+	return c.JSON(dto.TokenResponse{
+		Token: token,
 	})
 }
 
-// Generate a random 6-digit numeric code
-func generateSixDigitCode() string {
-	var b [3]byte
-	_, err := rand.Read(b[:])
+// createSessionToken creates a session for email on the calling device and returns its JWT
+func createSessionToken(c *fiber.Ctx, db *gorm.DB, email string) (string, error) {
+	return newSessionToken(c.UserContext(), db, email, c.IP(), c.Get(fiber.HeaderUserAgent))
+}
+
+// newSessionToken creates a session for email on the given device and returns its JWT
+func newSessionToken(ctx context.Context, db *gorm.DB, email, ip, userAgent string) (_ string, err error) {
+	ctx, span := telemetry.Start(ctx, "signin.new_session")
+	defer telemetry.End(span, &err)
+
+	// Create user session (profile, organization + device metadata in Redis)
+	orgID, role := adminForEmail(ctx, db, email)
+	sess, err := session.CreateWithRole(ctx, email, orgID, role, ip, userAgent)
+	if err != nil {
+		return "", clientError(err, "Could not store session")
+	}
+
+	// Generate JWT referencing this session
+	token, err := middleware.GenerateJWT(sess.ID)
 	if err != nil {
-		log.Println("Failed to generate random bytes, fallback to time-based code.")
-		now := time.Now().UnixNano()
-		return fmt.Sprintf("%06d", now%1000000)
+		return "", fmt.Errorf("Could not create token")
+	}
+	return token, nil
+}
+
+// adminForEmail returns the organization and role an admin signs in with. Emails without
+// an AdminUser row belong to the default organization, without a role.
+func adminForEmail(ctx context.Context, db *gorm.DB, email string) (uint, string) {
+	var admin models.AdminUser
+	if err := db.WithContext(ctx).Where("email = ?", email).First(&admin).Error; err != nil {
+		return models.DefaultOrgID, ""
 	}
-	num := (int(b[0])<<16 | int(b[1])<<8 | int(b[2])) % 1000000
-	return fmt.Sprintf("%06d", num)
+	return admin.OrgID, admin.Role
 }
 
 // randomToken returns a URL-safe random string
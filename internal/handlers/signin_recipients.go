@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"time"
+
+	"fiber-gorm-api/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// Who sign-in emails (codes and links) are sent to, from SIGNIN_CODE_RECIPIENTS
+const (
+	signInRecipientsAny   = "any"
+	signInRecipientsKnown = "known"
+)
+
+// defaultSignInResponseFloor is how long, at least, answering a sign-in request takes when only
+// known addresses are emailed
+const defaultSignInResponseFloor = 500 * time.Millisecond
+
+// signInKnownOnly reports whether sign-in emails only go to known addresses, those of admins and
+// subscribers: SIGNIN_CODE_RECIPIENTS=known. The default, any, emails whoever asks. Texts are
+// sent either way, as no account has a phone.
+func signInKnownOnly() bool {
+	return os.Getenv("SIGNIN_CODE_RECIPIENTS") == signInRecipientsKnown
+}
+
+// signInResponseFloor is SIGNIN_RESPONSE_FLOOR_MS: with known addresses only, every sign-in
+// request is answered after this long, so its timing doesn't tell a known address from another
+func signInResponseFloor() time.Duration {
+	if n, err := strconv.Atoi(os.Getenv("SIGNIN_RESPONSE_FLOOR_MS")); err == nil && n >= 0 {
+		return time.Duration(n) * time.Millisecond
+	}
+	return defaultSignInResponseFloor
+}
+
+// signInAddressKnown reports whether email belongs to an admin or a subscriber of any
+// organization (anonymized and deleted ones aside)
+func signInAddressKnown(db *gorm.DB, email string) (bool, error) {
+	var admins int64
+	if err := db.Model(&models.AdminUser{}).Where("LOWER(email) = LOWER(?)", email).Count(&admins).Error; err != nil {
+		return false, err
+	}
+	if admins > 0 {
+		return true, nil
+	}
+	var subscribers int64
+	err := db.Model(&models.Subscriber{}).
+		Where("LOWER(email) = LOWER(?) AND anonymized_at IS NULL", email).
+		Count(&subscribers).Error
+	return subscribers > 0, err
+}
+
+// waitResponseFloor returns once the response floor has passed since start, or ctx is done
+func (h *SigninHandler) waitResponseFloor(ctx context.Context, start time.Time) {
+	wait := time.NewTimer(start.Add(signInResponseFloor()).Sub(h.clock.Now()))
+	defer wait.Stop()
+	select {
+	case <-wait.C:
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"fiber-gorm-api/internal/models"
+	"fiber-gorm-api/internal/notify"
+)
+
+const (
+	defaultMaxVerifyAttempts = 5
+	defaultLockoutMinutes    = 15
+	// codes per address and hour, lower for texts as each one is paid for
+	defaultEmailRequestsPerHour = 10
+	defaultSMSRequestsPerHour   = 3
+	// wrong codes across all addresses in an hour before the platform admins are told
+	defaultFailureAlertPerHour = 50
+)
+
+// Redis key counting the sign-in codes sent to the given email or phone over channel this hour
+func signInRequestsKey(channel, to string) string {
+	return "signin_requests:" + channel + ":" + to
+}
+
+// signInRequestsPerHour is how many codes an address may be sent each hour over channel
+// (SIGNIN_EMAIL_REQUESTS_PER_HOUR, SIGNIN_SMS_REQUESTS_PER_HOUR)
+func signInRequestsPerHour(channel string) int64 {
+	name, def := "SIGNIN_EMAIL_REQUESTS_PER_HOUR", defaultEmailRequestsPerHour
+	if channel == signInChannelSMS {
+		name, def = "SIGNIN_SMS_REQUESTS_PER_HOUR", defaultSMSRequestsPerHour
+	}
+	if n, err := strconv.Atoi(os.Getenv(name)); err == nil && n > 0 {
+		return int64(n)
+	}
+	return int64(def)
+}
+
+// throttleSignInRequest counts a code requested for to over channel and, once the hourly limit
+// is exceeded, returns how long until another one may be sent
+func (h *SigninHandler) throttleSignInRequest(ctx context.Context, channel, to string) (time.Duration, error) {
+	key := signInRequestsKey(channel, to)
+	requests, err := h.kv.Increment(ctx, key, time.Hour)
+	if err != nil {
+		return 0, err
+	}
+	if requests <= signInRequestsPerHour(channel) {
+		return 0, nil
+	}
+	ttl, err := h.kv.TTL(ctx, key)
+	if err != nil || ttl <= 0 {
+		return time.Hour, nil
+	}
+	return ttl, nil
+}
+
+// Redis key counting failed /signin/verify attempts for the given email
+func signInAttemptsKey(email string) string {
+	return "signin_attempts:" + email
+}
+
+// Redis key present while verification is locked for the given email
+func signInLockKey(email string) string {
+	return "signin_lock:" + email
+}
+
+// maxVerifyAttempts is how many wrong codes are tolerated before locking (SIGNIN_MAX_VERIFY_ATTEMPTS)
+func maxVerifyAttempts() int64 {
+	if n, err := strconv.Atoi(os.Getenv("SIGNIN_MAX_VERIFY_ATTEMPTS")); err == nil && n > 0 {
+		return int64(n)
+	}
+	return defaultMaxVerifyAttempts
+}
+
+// lockoutDuration is how long verification stays locked (SIGNIN_LOCKOUT_MINUTES)
+func lockoutDuration() time.Duration {
+	if n, err := strconv.Atoi(os.Getenv("SIGNIN_LOCKOUT_MINUTES")); err == nil && n > 0 {
+		return time.Duration(n) * time.Minute
+	}
+	return defaultLockoutMinutes * time.Minute
+}
+
+// verifyLockRemaining returns how long verification is still locked for email, or 0 if it isn't
+func (h *SigninHandler) verifyLockRemaining(ctx context.Context, email string) time.Duration {
+	ttl, err := h.kv.TTL(ctx, signInLockKey(email))
+	if err != nil || ttl <= 0 {
+		return 0
+	}
+	return ttl
+}
+
+// recordFailedVerify counts a wrong code. Once the limit is reached the pending code is
+// invalidated, verification is locked, and true is returned.
+func (h *SigninHandler) recordFailedVerify(ctx context.Context, email string) (bool, error) {
+	attempts, err := h.kv.Increment(ctx, signInAttemptsKey(email), signInCodeTTL())
+	if err != nil {
+		return false, err
+	}
+	if attempts < maxVerifyAttempts() {
+		return false, nil
+	}
+
+	_ = h.kv.DeleteKey(ctx, signInCodeKey(email))
+	_ = h.kv.DeleteKey(ctx, signInAttemptsKey(email))
+	if err := h.kv.SetValue(ctx, signInLockKey(email), "1", lockoutDuration()); err != nil {
+		return true, err
+	}
+	return true, nil
+}
+
+// clearFailedVerifies resets the failure counter after a successful verification
+func (h *SigninHandler) clearFailedVerifies(ctx context.Context, email string) {
+	_ = h.kv.DeleteKey(ctx, signInAttemptsKey(email))
+}
+
+// Redis key counting wrong sign-in codes, across all addresses, in the hour of t
+func signInFailuresKey(t time.Time) string {
+	return "signin_failures:" + t.UTC().Format("2006010215")
+}
+
+// failureAlertPerHour is how many wrong codes an hour are unusual enough to tell the platform
+// admins about (SIGNIN_FAILURE_ALERT_PER_HOUR)
+func failureAlertPerHour() int64 {
+	if n, err := strconv.Atoi(os.Getenv("SIGNIN_FAILURE_ALERT_PER_HOUR")); err == nil && n > 0 {
+		return int64(n)
+	}
+	return defaultFailureAlertPerHour
+}
+
+// countFailedSignIn counts err, the result of checkSignInCode, if it is a wrong code. The
+// platform admins are told once per hour, when the count reaches the alert threshold.
+func (h *SigninHandler) countFailedSignIn(ctx context.Context, err error) {
+	if !errors.Is(err, errSignInCodeInvalid) && !errors.Is(err, errSignInTooManyAttempts) {
+		return
+	}
+	now := h.clock.Now()
+	failures, err := h.kv.Increment(ctx, signInFailuresKey(now), time.Hour)
+	if err != nil || failures != failureAlertPerHour() {
+		return
+	}
+	err = notify.Record(h.db.WithContext(ctx), &models.AdminActivity{
+		OrgID:   models.DefaultOrgID,
+		Kind:    notify.KindFailedSignIns,
+		Summary: fmt.Sprintf("%d failed sign-ins since %s", failures, now.UTC().Truncate(time.Hour).Format(time.RFC3339)),
+	})
+	if err != nil {
+		log.Printf("[WARN] Could not record failed sign-ins: %v", err)
+	}
+}
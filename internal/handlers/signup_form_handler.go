@@ -0,0 +1,361 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"fiber-gorm-api/internal/anomaly"
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
+	"fiber-gorm-api/internal/repository"
+	"fiber-gorm-api/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// signupFormLocalKey is the fiber.Ctx Locals key under which LoadSignupForm stores the form
+const signupFormLocalKey = "signup_form"
+
+var errUnparsableForm = errors.New("unable to parse request body")
+
+// parseSignupForm reads and validates a create / update body
+func parseSignupForm(c *fiber.Ctx, db *gorm.DB) (models.SignupForm, error) {
+	var req dto.SignupFormRequest
+	if err := c.BodyParser(&req); err != nil {
+		return models.SignupForm{}, errUnparsableForm
+	}
+	form := req.ToModel()
+	accepted, err := repository.NewSubscriberRepository(db).TypeNames(c.UserContext())
+	if err != nil {
+		return form, err
+	}
+	return form, service.ValidateSignupForm(&form, accepted)
+}
+
+// signupFormInvalid writes the error response of a body parseSignupForm rejected
+func signupFormInvalid(c *fiber.Ctx, err error) error {
+	var invalid *service.ValidationError
+	var types *service.InvalidTypesError
+	switch {
+	case errors.Is(err, errUnparsableForm):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Unable to parse request body"})
+	case errors.As(err, &invalid), errors.As(err, &types):
+		return subscriberValidationFailed(c, err)
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve subscriber types"})
+	}
+}
+
+// slugTaken reports whether another form of the caller's organization than id uses slug
+func slugTaken(c *fiber.Ctx, db *gorm.DB, slug string, id uint) bool {
+	var count int64
+	db.Model(&models.SignupForm{}).Scopes(orgScope(c)).Where("slug = ? AND id <> ?", slug, id).Count(&count)
+	return count > 0
+}
+
+// loadAdminSignupForm reads the form of the caller's organization named by the id param,
+// returning the status and message of the error response when it can't
+func loadAdminSignupForm(c *fiber.Ctx, db *gorm.DB) (models.SignupForm, int, string) {
+	var form models.SignupForm
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return form, fiber.StatusBadRequest, "Invalid form ID"
+	}
+	if err := db.Scopes(orgScope(c)).First(&form, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return form, fiber.StatusNotFound, "Form not found"
+		}
+		return form, fiber.StatusInternalServerError, "Could not retrieve form"
+	}
+	return form, 0, ""
+}
+
+// CreateSignupForm godoc
+// @Summary      Create a signup form
+// @Description  Creates a public signup form of the organization, submitted to POST /signup/forms/{slug}/submit. Slugs are unique within an organization.
+// @Description  subscriber_types must be listed by GET /admin/subscriber-types. Custom fields are text, number, checkbox or select (with options); their names can't be those of the submission's own parameters (code invalid_field).
+// @Description  success_redirect must be an http(s) URL (code invalid_redirect). captcha defaults to true.
+// @Tags         signup-forms
+// @Accept       json
+// @Produce      json
+// @Param        form  body      dto.SignupFormRequest  true  "Form definition"
+// @Success      201   {object}  dto.SignupFormResponse
+// @Failure      400   {object}  dto.ErrorResponse
+// @Failure      409   {object}  dto.ErrorResponse
+// @Failure      422   {object}  dto.InvalidSubscriberTypesResponse
+// @Failure      500   {object}  dto.ErrorResponse
+// @Router       /admin/signup-forms [post]
+func CreateSignupForm(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		form, err := parseSignupForm(c, db)
+		if err != nil {
+			return signupFormInvalid(c, err)
+		}
+		if slugTaken(c, db, form.Slug, 0) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Slug already taken"})
+		}
+
+		form.OrgID = middleware.CurrentOrgID(c)
+		form.CreatedBy = callerIdentity(c)
+		if err := db.Create(&form).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not create form"})
+		}
+		c.Location("/admin/signup-forms/" + strconv.FormatUint(uint64(form.ID), 10))
+		return c.Status(fiber.StatusCreated).JSON(dto.NewSignupFormResponse(form))
+	}
+}
+
+// GetSignupForms godoc
+// @Summary      List signup forms
+// @Description  Lists the signup forms of the organization.
+// @Tags         signup-forms
+// @Produce      json
+// @Success      200  {array}   dto.SignupFormResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/signup-forms [get]
+func GetSignupForms(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var forms []models.SignupForm
+		if err := db.WithContext(c.UserContext()).Scopes(orgScope(c)).Order("id").Find(&forms).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve forms"})
+		}
+		resp := make([]dto.SignupFormResponse, len(forms))
+		for i, f := range forms {
+			resp[i] = dto.NewSignupFormResponse(f)
+		}
+		return c.JSON(resp)
+	}
+}
+
+// GetSignupForm godoc
+// @Summary      Get a signup form
+// @Tags         signup-forms
+// @Produce      json
+// @Param        id   path      int  true  "Form ID"
+// @Success      200  {object}  dto.SignupFormResponse
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/signup-forms/{id} [get]
+func GetSignupForm(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		form, status, msg := loadAdminSignupForm(c, db.WithContext(c.UserContext()))
+		if status != 0 {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+		return c.JSON(dto.NewSignupFormResponse(form))
+	}
+}
+
+// UpdateSignupForm godoc
+// @Summary      Update a signup form
+// @Description  Replaces the definition of a signup form, validated as on create. Subscribers who already submitted it keep their metadata.
+// @Tags         signup-forms
+// @Accept       json
+// @Produce      json
+// @Param        id    path      int                    true  "Form ID"
+// @Param        form  body      dto.SignupFormRequest  true  "Form definition"
+// @Success      200   {object}  dto.SignupFormResponse
+// @Failure      400   {object}  dto.ErrorResponse
+// @Failure      404   {object}  dto.ErrorResponse
+// @Failure      409   {object}  dto.ErrorResponse
+// @Failure      422   {object}  dto.InvalidSubscriberTypesResponse
+// @Failure      500   {object}  dto.ErrorResponse
+// @Router       /admin/signup-forms/{id} [put]
+func UpdateSignupForm(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		current, status, msg := loadAdminSignupForm(c, db)
+		if status != 0 {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+		form, err := parseSignupForm(c, db)
+		if err != nil {
+			return signupFormInvalid(c, err)
+		}
+		if slugTaken(c, db, form.Slug, current.ID) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Slug already taken"})
+		}
+
+		form.ID, form.OrgID = current.ID, current.OrgID
+		form.CreatedBy, form.CreatedAt = current.CreatedBy, current.CreatedAt
+		if err := db.Save(&form).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not update form"})
+		}
+		return c.JSON(dto.NewSignupFormResponse(form))
+	}
+}
+
+// DeleteSignupForm godoc
+// @Summary      Delete a signup form
+// @Description  Deletes a signup form; its submissions are then answered 404. Subscribers who submitted it are kept.
+// @Tags         signup-forms
+// @Param        id   path  int  true  "Form ID"
+// @Success      204  {string}  string
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/signup-forms/{id} [delete]
+func DeleteSignupForm(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := strconv.Atoi(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid form ID"})
+		}
+		res := db.WithContext(c.UserContext()).Scopes(orgScope(c)).Delete(&models.SignupForm{}, id)
+		if res.Error != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not delete form"})
+		}
+		if res.RowsAffected == 0 {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Form not found"})
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	}
+}
+
+// LoadSignupForm reads the form of the slug param in the organization ResolveOrg picked, for
+// SignupFormCaptcha and SubmitSignupForm
+func LoadSignupForm(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var form models.SignupForm
+		err := db.WithContext(c.UserContext()).Scopes(orgScope(c)).Where("slug = ?", c.Params("slug")).First(&form).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Unknown signup form"})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve form"})
+		}
+		c.Locals(signupFormLocalKey, form)
+		return c.Next()
+	}
+}
+
+// SignupFormCaptcha reports whether the form LoadSignupForm read requires a captcha token
+func SignupFormCaptcha(c *fiber.Ctx) bool {
+	form, ok := c.Locals(signupFormLocalKey).(models.SignupForm)
+	return !ok || form.Captcha
+}
+
+// isFormPost reports whether the request is an HTML form post rather than JSON
+func isFormPost(c *fiber.Ctx) bool {
+	return strings.HasPrefix(c.Get(fiber.HeaderContentType), fiber.MIMEApplicationForm)
+}
+
+// parseSubmission reads a JSON submission, or an HTML form post whose values other than the
+// submission's own parameters (and the honeypot) are the custom fields
+func parseSubmission(c *fiber.Ctx) (dto.SignupFormSubmission, error) {
+	var req dto.SignupFormSubmission
+	if !isFormPost(c) {
+		err := c.BodyParser(&req)
+		return req, err
+	}
+
+	args := c.Request().PostArgs()
+	value := func(key string) string { return string(args.Peek(key)) }
+	req = dto.SignupFormSubmission{
+		Email:       value("email"),
+		Name:        value("name"),
+		Locale:      value("locale"),
+		UTMSource:   value("utm_source"),
+		UTMMedium:   value("utm_medium"),
+		UTMCampaign: value("utm_campaign"),
+		Referrer:    value("referrer"),
+		Ref:         value("ref"),
+		Fields:      map[string]interface{}{},
+	}
+	for _, name := range args.PeekMulti("subscriber_types") {
+		req.SubscriberTypes = append(req.SubscriberTypes, string(name))
+	}
+	honeypot := os.Getenv("SIGNUP_HONEYPOT_FIELD")
+	args.VisitAll(func(key, value []byte) {
+		if name := string(key); name != honeypot && !slices.Contains(service.ReservedFieldNames, name) {
+			req.Fields[name] = string(value)
+		}
+	})
+	return req, nil
+}
+
+// SubmitSignupForm godoc
+// @Summary      Submit a signup form
+// @Description  Signs up to the signup form of slug (see /admin/signup-forms), in the organization named by X-Org / ?org=. Accepts JSON, or an HTML form post where each custom field is a top-level value and subscriber_types may repeat.
+// @Description  subscriber_types must be among those of the form (422 otherwise), all of them when omitted. Custom fields are checked against the form: unknown_field, missing_field (also for an unchecked required checkbox) or invalid_field, and stored in the subscriber's metadata.
+// @Description  Otherwise the same as POST /signup/subscribers: double opt-in, spam quarantine, attribution and referral code (ref), honeypot, and a captcha token unless the form has its captcha off.
+// @Description  HTML form posts are redirected (303) to the form's success_redirect once subscribed, when it has one.
+// @Tags         subscribers
+// @Accept       json,x-www-form-urlencoded
+// @Produce      json
+// @Param        slug        path      string                    true  "Form slug"
+// @Param        submission  body      dto.SignupFormSubmission  true  "Subscriber info and custom field values"
+// @Success      201         {object}  dto.SignupFormSubmitResponse
+// @Success      303         {string}  string
+// @Failure      400         {object}  dto.ErrorResponse
+// @Failure      404         {object}  dto.ErrorResponse
+// @Failure      422         {object}  dto.InvalidSubscriberTypesResponse
+// @Failure      500         {object}  dto.ErrorResponse
+// @Router       /signup/forms/{slug}/submit [post]
+func SubmitSignupForm(db *gorm.DB) fiber.Handler {
+	svc := subscriberService(db)
+	return func(c *fiber.Ctx) error {
+		form := c.Locals(signupFormLocalKey).(models.SignupForm)
+		req, err := parseSubmission(c)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Unable to parse request body"})
+		}
+
+		types, err := service.SignupFormTypes(form, req.SubscriberTypes)
+		if err != nil {
+			return subscriberValidationFailed(c, err)
+		}
+		metadata, err := service.SignupFormValues(form, req.Fields)
+		if err != nil {
+			return subscriberValidationFailed(c, err)
+		}
+
+		in := service.CreateSubscriberInput{
+			OrgID:           form.OrgID,
+			Email:           strings.TrimSpace(req.Email),
+			Name:            strings.TrimSpace(req.Name),
+			SubscriberTypes: types,
+			Metadata:        metadata,
+			Locale:          req.Locale,
+			DoubleOptIn:     os.Getenv("SIGNUP_DOUBLE_OPT_IN") == "true",
+			Ref:             req.Ref,
+		}
+		if in.Locale == "" {
+			in.Locale = middleware.CurrentLocale(c)
+		}
+		publicSignup(c, &in, service.Attribution{
+			Source:   req.UTMSource,
+			Medium:   req.UTMMedium,
+			Campaign: req.UTMCampaign,
+			Referrer: req.Referrer,
+		})
+
+		subscriber, err := svc.Create(c.UserContext(), in)
+		var invalid *service.ValidationError
+		if errors.As(err, &invalid) {
+			return subscriberValidationFailed(c, err)
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": fmt.Sprintf("Could not create subscriber: %v", err),
+			})
+		}
+		anomaly.CountSignup(c.UserContext())
+
+		if isFormPost(c) && form.SuccessRedirect != "" {
+			return c.Redirect(form.SuccessRedirect, fiber.StatusSeeOther)
+		}
+		return c.Status(fiber.StatusCreated).JSON(dto.SignupFormSubmitResponse{
+			Status:      subscriber.Status,
+			RedirectURL: form.SuccessRedirect,
+		})
+	}
+}
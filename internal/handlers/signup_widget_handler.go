@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+
+	"fiber-gorm-api/internal/captcha"
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/i18n"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
+	"fiber-gorm-api/internal/widget"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// jsonpCallback restricts JSONP callbacks to dotted JavaScript identifiers, so they can't inject
+// script
+var jsonpCallback = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]{0,63}(\.[A-Za-z_$][A-Za-z0-9_$]{0,63}){0,3}$`)
+
+// GetSignupWidget godoc
+// @Summary      Signup widget
+// @Description  Serves the signup form of slug (see /admin/signup-forms) for partner sites to embed, in the organization named by X-Org / ?org=.
+// @Description  By default an HTML page to show in an iframe: it may be framed by the signup origins and CORS_SIGNUP_PARTNER_ORIGINS, and forwards the utm_* and ref params of its own URL.
+// @Description  format=json returns the form's config instead, for a script of the partner site to render it, and callback wraps it in JSONP.
+// @Description  The captcha is rendered with CAPTCHA_SITE_KEY when the form requires one.
+// @Tags         subscribers
+// @Produce      html,json,application/javascript
+// @Param        slug      path      string  true   "Form slug"
+// @Param        format    query     string  false  "html (default) or json"  Enums(html, json)
+// @Param        callback  query     string  false  "JSONP callback, a JavaScript identifier"
+// @Success      200       {object}  dto.SignupWidgetConfig
+// @Failure      400       {object}  dto.ErrorResponse
+// @Failure      404       {object}  dto.ErrorResponse
+// @Failure      500       {object}  dto.ErrorResponse
+// @Router       /signup/widget/{slug} [get]
+func GetSignupWidget(frameAncestors string) fiber.Handler {
+	ancestors := strings.ReplaceAll(frameAncestors, ",", " ")
+	return func(c *fiber.Ctx) error {
+		form := c.Locals(signupFormLocalKey).(models.SignupForm)
+		submitURL := c.BaseURL() + "/signup/forms/" + url.PathEscape(form.Slug) + "/submit"
+		if org := middleware.RequestedOrg(c); org != "" {
+			submitURL += "?org=" + url.QueryEscape(org)
+		}
+		var formCaptcha *captcha.Widget
+		if w, ok := captcha.ClientWidget(); ok && form.Captcha {
+			formCaptcha = &w
+		}
+		honeypot := os.Getenv("SIGNUP_HONEYPOT_FIELD")
+
+		callback := c.Query("callback")
+		if callback != "" || c.Query("format") == "json" {
+			if callback != "" && !jsonpCallback.MatchString(callback) {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid callback"})
+			}
+			config := dto.SignupWidgetConfig{
+				Slug:            form.Slug,
+				Name:            form.Name,
+				SubmitURL:       submitURL,
+				SubscriberTypes: form.TypeNames(),
+				Fields:          dto.NewSignupFormResponse(form).Fields,
+				HoneypotField:   honeypot,
+			}
+			if formCaptcha != nil {
+				config.Captcha = &dto.SignupWidgetCaptcha{
+					Provider: formCaptcha.Provider,
+					SiteKey:  formCaptcha.SiteKey,
+					Script:   formCaptcha.Script,
+				}
+			}
+			if callback != "" {
+				return c.JSONP(config, callback)
+			}
+			return c.JSON(config)
+		}
+
+		raw := make([]byte, 16)
+		if _, err := rand.Read(raw); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not render widget"})
+		}
+		nonce := base64.StdEncoding.EncodeToString(raw)
+
+		// the page runs its own inline script, and the captcha's, and is framed by partner sites
+		// only: frame-ancestors supersedes X-Frame-Options, which can't list them. The captcha
+		// scripts and frames don't opt into cross-origin embedding, which require-corp demands.
+		captchaOrigins, frames := "", "'none'"
+		if formCaptcha != nil {
+			captchaOrigins, frames = " "+formCaptcha.Origins, formCaptcha.Origins
+		}
+		c.Set(fiber.HeaderContentSecurityPolicy, fmt.Sprintf(
+			"default-src 'none'; script-src 'nonce-%[1]s'%[2]s; style-src 'nonce-%[1]s'%[2]s; connect-src 'self'%[2]s; frame-src %[3]s; form-action 'self'; frame-ancestors %[4]s",
+			nonce, captchaOrigins, frames, ancestors,
+		))
+		c.Response().Header.Del(fiber.HeaderXFrameOptions)
+		c.Response().Header.Del("Cross-Origin-Embedder-Policy")
+
+		locale := middleware.CurrentLocale(c)
+		c.Type("html", "utf-8")
+		return widget.Render(c, widget.Page{
+			Locale:    locale,
+			Form:      form,
+			SubmitURL: submitURL,
+			Captcha:   formCaptcha,
+			Honeypot:  honeypot,
+			Nonce:     nonce,
+			T:         func(message string) string { return i18n.T(locale, message) },
+		})
+	}
+}
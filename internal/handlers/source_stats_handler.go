@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"time"
+
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// GetSourceStats godoc
+// @Summary      Signups by source
+// @Description  Counts the subscribers of the organization by the channel they signed up from: utm_source (else the referrer host, else none), utm_medium, utm_campaign and referrer, as sent to POST /signup/subscribers.
+// @Tags         stats
+// @Produce      json
+// @Param        since  query     string  false  "Only subscribers created at or after this RFC 3339 time"
+// @Param        until  query     string  false  "Only subscribers created before this RFC 3339 time"
+// @Success      200  {object}  dto.SourceStatsResponse
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/stats/sources [get]
+func GetSourceStats(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		scope := orgIDScope(middleware.CurrentOrgID(c))
+		var conds []func(*gorm.DB) *gorm.DB
+		for param, cond := range map[string]string{"since": "created_at >= ?", "until": "created_at < ?"} {
+			value := c.Query(param)
+			if value == "" {
+				continue
+			}
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid " + param + ", expected an RFC 3339 time"})
+			}
+			conds = append(conds, func(db *gorm.DB) *gorm.DB { return db.Where(cond, t) })
+		}
+		subscribers := func() *gorm.DB {
+			return db.WithContext(c.UserContext()).Model(&models.Subscriber{}).Scopes(scope).Scopes(conds...)
+		}
+
+		stats, err := computeSourceStats(subscribers)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not compute stats"})
+		}
+		return c.JSON(stats)
+	}
+}
+
+// computeSourceStats runs the aggregate queries behind GetSourceStats on the subscribers
+// queries return
+func computeSourceStats(subscribers func() *gorm.DB) (dto.SourceStatsResponse, error) {
+	stats := dto.SourceStatsResponse{GeneratedAt: time.Now()}
+	if err := subscribers().Count(&stats.TotalSignups).Error; err != nil {
+		return stats, err
+	}
+
+	// grouped by the expression: "name" alone would be the subscriber's name column
+	countBy := func(query *gorm.DB, expr string) ([]dto.SourceCount, error) {
+		counts := []dto.SourceCount{}
+		err := query.Select(expr + " AS name, COUNT(*) AS count").
+			Group(expr).
+			Order("count DESC, " + expr).
+			Scan(&counts).Error
+		return counts, err
+	}
+	var err error
+	if stats.BySource, err = countBy(subscribers(), "COALESCE(NULLIF(utm_source, ''), NULLIF(referrer, ''), 'none')"); err != nil {
+		return stats, err
+	}
+	if stats.ByMedium, err = countBy(subscribers(), "COALESCE(NULLIF(utm_medium, ''), 'none')"); err != nil {
+		return stats, err
+	}
+	if stats.ByReferrer, err = countBy(subscribers().Where("referrer <> ''"), "referrer"); err != nil {
+		return stats, err
+	}
+
+	const source = "COALESCE(NULLIF(utm_source, ''), 'none')"
+	stats.ByCampaign = []dto.CampaignCount{}
+	err = subscribers().Where("utm_campaign <> ''").
+		Select(source + " AS source, utm_campaign AS campaign, COUNT(*) AS count").
+		Group(source + ", utm_campaign").
+		Order("count DESC, " + source + ", utm_campaign").
+		Scan(&stats.ByCampaign).Error
+	return stats, err
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"fiber-gorm-api/internal/cache"
+	database "fiber-gorm-api/internal/db"
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// recentSignupsLimit is how many of the newest subscribers the dashboard lists
+const recentSignupsLimit = 10
+
+// GetStats godoc
+// @Summary      Admin dashboard stats
+// @Description  Subscriber counts by type, signup growth per day / week / month, unsubscribe rate and the most recent signups.
+// @Description  Unsubscribed counts subscribers with status unsubscribed, which includes anonymized ones. Cached in the Redis entity DB when caching is enabled.
+// @Tags         stats
+// @Produce      json
+// @Success      200  {object}  dto.StatsResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/stats [get]
+func GetStats(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		stats, err := loadStats(c.UserContext(), db, middleware.CurrentOrgID(c))
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not compute stats"})
+		}
+		stats.RecentSignups = redactSubscribersFor(c, stats.RecentSignups)
+		return c.JSON(stats)
+	}
+}
+
+// loadStats returns the stats of an organization from the read cache, computing them on a miss.
+// RecentSignups still has to be redacted for the caller.
+func loadStats(ctx context.Context, db *gorm.DB, orgID uint) (dto.StatsResponse, error) {
+	db = db.WithContext(ctx)
+	cacheKey := ""
+	if cache.Enabled() {
+		cacheKey = cache.StatsKey(ctx, orgID)
+		var cached dto.StatsResponse
+		if cache.Get(ctx, cacheKey, &cached) {
+			return cached, nil
+		}
+	}
+
+	stats, err := computeStats(db, orgIDScope(orgID), orgID)
+	if err != nil {
+		return stats, err
+	}
+
+	if cacheKey != "" {
+		cache.Set(ctx, cacheKey, stats)
+	}
+	return stats, nil
+}
+
+// computeStats runs the aggregate queries behind GetStats. scope restricts subscriber
+// queries to the organization; subscriber_types are joined back to their subscriber.
+func computeStats(db *gorm.DB, scope func(*gorm.DB) *gorm.DB, orgID uint) (dto.StatsResponse, error) {
+	stats := dto.StatsResponse{GeneratedAt: time.Now()}
+
+	var totals struct {
+		Total        int64
+		Unsubscribed int64
+	}
+	if err := db.Scopes(scope).Model(&models.Subscriber{}).
+		Select("COUNT(*) AS total, COUNT(*) FILTER (WHERE status = ?) AS unsubscribed", models.SubscriberStatusUnsubscribed).
+		Scan(&totals).Error; err != nil {
+		return stats, err
+	}
+	stats.TotalSubscribers = totals.Total
+	stats.UnsubscribedSubscribers = totals.Unsubscribed
+	stats.ActiveSubscribers = totals.Total - totals.Unsubscribed
+	if totals.Total > 0 {
+		stats.UnsubscribeRate = float64(totals.Unsubscribed) / float64(totals.Total)
+	}
+
+	var err error
+	if stats.ByType, err = countByType(db, orgID); err != nil {
+		return stats, err
+	}
+
+	now := time.Now()
+	if stats.Growth.Daily, err = signupGrowth(db.Scopes(scope), "day", now.AddDate(0, 0, -30)); err != nil {
+		return stats, err
+	}
+	if stats.Growth.Weekly, err = signupGrowth(db.Scopes(scope), "week", now.AddDate(0, 0, -7*12)); err != nil {
+		return stats, err
+	}
+	if stats.Growth.Monthly, err = signupGrowth(db.Scopes(scope), "month", now.AddDate(0, -12, 0)); err != nil {
+		return stats, err
+	}
+
+	var recent []models.Subscriber
+	if err := db.Scopes(scope).Preload("SubscriberTypes").
+		Order("created_at DESC").
+		Limit(recentSignupsLimit).
+		Find(&recent).Error; err != nil {
+		return stats, err
+	}
+	stats.RecentSignups = dto.NewSubscriberResponses(recent)
+
+	return stats, nil
+}
+
+// countByType counts the subscribers of an organization per subscriber_type, most used first
+func countByType(db *gorm.DB, orgID uint) ([]dto.TypeCount, error) {
+	counts := []dto.TypeCount{}
+	err := db.Model(&models.SubscriberType{}).
+		Select("subscriber_types.name, COUNT(DISTINCT subscriber_types.subscriber_id) AS count").
+		Joins("JOIN subscribers ON subscribers.id = subscriber_types.subscriber_id").
+		Where("subscribers.org_id = ? AND subscribers.deleted_at IS NULL", orgID).
+		Group("subscriber_types.name").
+		Order("count DESC").
+		Scan(&counts).Error
+	return counts, err
+}
+
+// signupGrowth counts signups per date_trunc unit (day, week, month) since the given time.
+// Buckets without signups are omitted.
+func signupGrowth(db *gorm.DB, unit string, since time.Time) ([]dto.GrowthPoint, error) {
+	query := db.Model(&models.Subscriber{}).
+		Select(database.TruncTime(db, unit, "created_at")+" AS period, COUNT(*) AS count").
+		Where("created_at >= ?", since).
+		Group("period").
+		Order("period")
+	if !database.IsSQLite(db) {
+		points := []dto.GrowthPoint{}
+		err := query.Scan(&points).Error
+		return points, err
+	}
+
+	// SQLite has no date type, its periods come back as "YYYY-MM-DD"
+	var rows []struct {
+		Period string
+		Count  int64
+	}
+	if err := query.Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	points := make([]dto.GrowthPoint, 0, len(rows))
+	for _, row := range rows {
+		period, err := time.Parse(time.DateOnly, row.Period)
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, dto.GrowthPoint{Period: period, Count: row.Count})
+	}
+	return points, nil
+}
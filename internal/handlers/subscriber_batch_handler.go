@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+
+	"fiber-gorm-api/internal/cache"
+	database "fiber-gorm-api/internal/db"
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
+	"fiber-gorm-api/internal/notify"
+	"fiber-gorm-api/internal/repository"
+	"fiber-gorm-api/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// maxBatchOperations caps a single batch so one request can't hold a transaction forever
+const maxBatchOperations = 500
+
+// errBatchFailed aborts the batch transaction once every operation has been attempted
+var errBatchFailed = errors.New("batch has failed operations")
+
+// BatchSubscribers godoc
+// @Summary      Batch create/update/delete subscribers
+// @Description  Applies up to 500 operations in a single transaction. Either all succeed (200, committed=true)
+// @Description  or everything is rolled back (422, committed=false) and the failing items carry an error.
+// @Tags         subscribers
+// @Accept       json
+// @Produce      json
+// @Param        body  body      dto.BatchRequest  true  "Operations, applied in order"
+// @Success      200   {object}  dto.BatchResponse
+// @Failure      400   {object}  dto.ErrorResponse
+// @Failure      422   {object}  dto.BatchResponse
+// @Failure      500   {object}  dto.ErrorResponse
+// @Router       /admin/subscribers/batch [post]
+func BatchSubscribers(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		var req dto.BatchRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Unable to parse request body"})
+		}
+		if len(req.Operations) == 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "No operations given"})
+		}
+		if len(req.Operations) > maxBatchOperations {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": fmt.Sprintf("At most %d operations per batch", maxBatchOperations),
+			})
+		}
+
+		results := make([]dto.BatchResult, len(req.Operations))
+		err := database.Tx(c.UserContext(), db, func(tx *gorm.DB) error {
+			failed := false
+			for i, op := range req.Operations {
+				results[i] = applyBatchOperation(c, tx, i, op)
+				if results[i].Error != "" {
+					failed = true
+				}
+			}
+			if failed {
+				return errBatchFailed
+			}
+			return recordBulkDelete(c, tx, req.Operations)
+		})
+
+		if errors.Is(err, errBatchFailed) {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(dto.BatchResponse{Committed: false, Results: results})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not commit batch"})
+		}
+
+		var touched []uint
+		for i, op := range req.Operations {
+			if op.ID != 0 {
+				touched = append(touched, op.ID)
+			} else if results[i].Subscriber != nil {
+				touched = append(touched, results[i].Subscriber.ID)
+			}
+		}
+		cache.InvalidateSubscriber(c.UserContext(), touched...)
+
+		return c.JSON(dto.BatchResponse{Committed: true, Results: results})
+	}
+}
+
+// recordBulkDelete reports a batch deleting subscribers to the platform admins
+func recordBulkDelete(c *fiber.Ctx, tx *gorm.DB, ops []dto.BatchOperation) error {
+	deleted := 0
+	for _, op := range ops {
+		if op.Op == dto.BatchDelete {
+			deleted++
+		}
+	}
+	if deleted == 0 {
+		return nil
+	}
+	orgID := middleware.CurrentOrgID(c)
+	return notify.Record(tx, &models.AdminActivity{
+		OrgID:   orgID,
+		Kind:    notify.KindBulkDelete,
+		Actor:   callerIdentity(c),
+		Summary: fmt.Sprintf("%d subscribers of organization %d deleted in one batch", deleted, orgID),
+	})
+}
+
+// applyBatchOperation runs one operation inside the batch transaction
+func applyBatchOperation(c *fiber.Ctx, tx *gorm.DB, index int, op dto.BatchOperation) dto.BatchResult {
+	result := dto.BatchResult{Index: index, Op: op.Op}
+	fail := func(status int, msg string) dto.BatchResult {
+		result.Status = status
+		result.Error = msg
+		return result
+	}
+
+	// the repository writes nest as savepoints, so a failing item is rolled back on its own
+	// without aborting the whole Postgres transaction and later items still report
+	repo := repository.NewSubscriberRepository(tx)
+	ctx := c.UserContext()
+
+	switch op.Op {
+	case dto.BatchCreate:
+		if op.Subscriber == nil {
+			return fail(fiber.StatusBadRequest, "Missing subscriber")
+		}
+		subscriber := dto.CreateSubscriberRequest{
+			Email:           op.Subscriber.Email,
+			Name:            op.Subscriber.Name,
+			SubscriberTypes: op.Subscriber.SubscriberTypes,
+			Metadata:        op.Subscriber.Metadata,
+		}.ToModel()
+		subscriber.OrgID = middleware.CurrentOrgID(c)
+		subscriber.Status = models.SubscriberStatusActive
+		if err := service.ValidateSubscriber(&subscriber); err != nil {
+			return fail(fiber.StatusBadRequest, err.Error())
+		}
+		if err := service.CheckSubscriberTypes(ctx, repo, subscriber.SubscriberTypes); errors.Is(err, service.ErrInvalidType) {
+			return fail(fiber.StatusUnprocessableEntity, err.Error())
+		} else if err != nil {
+			return fail(fiber.StatusInternalServerError, "Could not check subscriber_types")
+		}
+		if err := repo.Create(ctx, &subscriber); err != nil {
+			return fail(fiber.StatusInternalServerError, "Could not create subscriber")
+		}
+		resp := subscriberResponse(c, subscriber)
+		result.Status = fiber.StatusCreated
+		result.Subscriber = &resp
+		return result
+
+	case dto.BatchUpdate:
+		if op.Subscriber == nil {
+			return fail(fiber.StatusBadRequest, "Missing subscriber")
+		}
+		existing, err := repo.Find(ctx, middleware.CurrentOrgID(c), op.ID)
+		if err != nil {
+			return fail(fiber.StatusNotFound, "Subscriber not found")
+		}
+		updates := op.Subscriber.ToModel()
+		if err := service.ValidateSubscriber(&updates); err != nil {
+			return fail(fiber.StatusBadRequest, err.Error())
+		}
+		if err := service.CheckSubscriberTypes(ctx, repo, updates.SubscriberTypes); errors.Is(err, service.ErrInvalidType) {
+			return fail(fiber.StatusUnprocessableEntity, err.Error())
+		} else if err != nil {
+			return fail(fiber.StatusInternalServerError, "Could not check subscriber_types")
+		}
+		if op.Subscriber.Version != nil && *op.Subscriber.Version != existing.Version {
+			return fail(fiber.StatusConflict, "Subscriber version is stale")
+		}
+		existing.Email = updates.Email
+		existing.Name = updates.Name
+		if updates.Metadata != nil {
+			existing.Metadata = updates.Metadata
+		}
+		if err := repo.Update(ctx, existing, updates.SubscriberTypes); err != nil {
+			if errors.Is(err, repository.ErrVersionConflict) {
+				return fail(fiber.StatusConflict, "Subscriber version is stale")
+			}
+			return fail(fiber.StatusInternalServerError, "Could not update subscriber")
+		}
+		if existing, err = repo.Find(ctx, existing.OrgID, existing.ID); err != nil {
+			return fail(fiber.StatusInternalServerError, "Failed to fetch updated subscriber")
+		}
+		resp := subscriberResponse(c, *existing)
+		result.Status = fiber.StatusOK
+		result.Subscriber = &resp
+		return result
+
+	case dto.BatchDelete:
+		existing, err := repo.Find(ctx, middleware.CurrentOrgID(c), op.ID)
+		if err != nil {
+			return fail(fiber.StatusNotFound, "Subscriber not found")
+		}
+		if err := repo.Delete(ctx, existing); err != nil {
+			return fail(fiber.StatusInternalServerError, "Could not delete subscriber")
+		}
+		result.Status = fiber.StatusNoContent
+		return result
+
+	default:
+		return fail(fiber.StatusBadRequest, "Unknown op, expected create, update or delete")
+	}
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/middleware"
+	redisclient "fiber-gorm-api/internal/redis"
+	"fiber-gorm-api/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// ConfirmSubscriber godoc
+// @Summary      Confirm a signup (double opt-in)
+// @Description  Marks the subscriber behind an emailed confirmation token as active with a verified email. Tokens are single-use.
+// @Tags         subscribers
+// @Accept       json
+// @Produce      json
+// @Param        body  body      dto.ConfirmSubscriberRequest  true  "Token from the confirmation link"
+// @Success      200   {object}  dto.ConfirmSubscriberResponse
+// @Failure      400   {object}  dto.ErrorResponse
+// @Failure      404   {object}  dto.ErrorResponse  "code: invalid_token"
+// @Failure      410   {object}  dto.ErrorResponse  "code: confirmation_expired"
+// @Failure      500   {object}  dto.ErrorResponse
+// @Router       /signup/subscribers/confirm [post]
+func ConfirmSubscriber(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		var req dto.ConfirmSubscriberRequest
+		if err := c.BodyParser(&req); err != nil || req.Token == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing token"})
+		}
+
+		subscriber, err := subscriberService(db).Confirm(c.UserContext(), req.Token)
+		if errors.Is(err, service.ErrInvalidToken) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Confirmation link is invalid or was already used",
+				"code":  "invalid_token",
+			})
+		}
+		if errors.Is(err, service.ErrTokenExpired) {
+			return c.Status(fiber.StatusGone).JSON(fiber.Map{
+				"error": "Confirmation link has expired, please sign up again",
+				"code":  "confirmation_expired",
+			})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not confirm subscriber"})
+		}
+
+		return c.JSON(dto.ConfirmSubscriberResponse{
+			Status:          subscriber.Status,
+			EmailVerifiedAt: *subscriber.EmailVerifiedAt,
+		})
+	}
+}
+
+const defaultMaxConfirmationResends = 5
+
+// confirmationResendsKey is the Redis key counting today's confirmation resends of a subscriber
+func confirmationResendsKey(id uint) string {
+	return "confirmation_resends:" + strconv.FormatUint(uint64(id), 10)
+}
+
+// maxConfirmationResends is how many confirmation emails may be resent to a subscriber a day
+// (SUBSCRIBER_MAX_RESENDS_PER_DAY), on top of the cooldown between two sends
+func maxConfirmationResends() int {
+	if n, err := strconv.Atoi(os.Getenv("SUBSCRIBER_MAX_RESENDS_PER_DAY")); err == nil && n > 0 {
+		return n
+	}
+	return defaultMaxConfirmationResends
+}
+
+// ResendSubscriberConfirmation godoc
+// @Summary      Resend a confirmation email
+// @Description  Emails a pending subscriber a new double opt-in link, invalidating the previous one, e.g. when it never arrived.
+// @Description  A subscriber can get one every SUBSCRIBER_RESEND_COOLDOWN_MINUTES and SUBSCRIBER_MAX_RESENDS_PER_DAY a day.
+// @Tags         subscribers
+// @Produce      json
+// @Param        id   path      int  true  "Subscriber ID"
+// @Success      200  {object}  dto.MessageResponse
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      409  {object}  dto.ErrorResponse  "code: not_pending"
+// @Failure      429  {object}  dto.ErrorResponse  "code: resend_too_soon or resend_limit_reached, with Retry-After"
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/subscribers/{id}/resend-confirmation [post]
+func ResendSubscriberConfirmation(db *gorm.DB) fiber.Handler {
+	svc := subscriberService(db)
+	return func(c *fiber.Ctx) error {
+		id, err := strconv.Atoi(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid subscriber ID"})
+		}
+
+		key := confirmationResendsKey(uint(id))
+		if sent, _ := redisclient.GetValue(c.UserContext(), key); sent != "" {
+			if n, _ := strconv.Atoi(sent); n >= maxConfirmationResends() {
+				ttl, _ := redisclient.TTL(c.UserContext(), key)
+				c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(ttl.Seconds())+1))
+				return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+					"error": "Too many confirmation emails were sent to this subscriber today",
+					"code":  "resend_limit_reached",
+				})
+			}
+		}
+
+		_, err = svc.ResendConfirmation(c.UserContext(), middleware.CurrentOrgID(c), uint(id))
+		var tooSoon *service.ResendTooSoonError
+		switch {
+		case errors.Is(err, service.ErrNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Subscriber not found"})
+		case errors.Is(err, service.ErrNotPending):
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "Subscriber is not waiting for a confirmation",
+				"code":  "not_pending",
+			})
+		case errors.As(err, &tooSoon):
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(tooSoon.RetryAfter.Seconds())+1))
+			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+				"error": "A confirmation email was sent recently, please wait before resending",
+				"code":  "resend_too_soon",
+			})
+		case err != nil:
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not resend confirmation email"})
+		}
+
+		if _, err := redisclient.Increment(c.UserContext(), key, 24*time.Hour); err != nil {
+			log.Printf("[WARN] Could not count confirmation resend of subscriber %d: %v", id, err)
+		}
+		return c.JSON(dto.MessageResponse{Message: "A new confirmation email has been sent."})
+	}
+}
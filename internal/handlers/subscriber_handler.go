@@ -1,135 +1,470 @@
 package handlers
 
 import (
+	"errors"
+	"fiber-gorm-api/internal/anomaly"
+	"fiber-gorm-api/internal/cache"
+	database "fiber-gorm-api/internal/db"
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/geoip"
+	"fiber-gorm-api/internal/middleware"
 	"fiber-gorm-api/internal/models"
+	"fiber-gorm-api/internal/repository"
+	"fiber-gorm-api/internal/service"
+	"fiber-gorm-api/internal/spam"
 	"fmt"
-	"regexp"
+	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
 )
 
-// A more robust email regex to ensure an address-like format.
-// (Though there's no perfect regex for all valid emails, this is a decent approach.)
-var emailRegex = regexp.MustCompile(`^[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}$`)
+// subscriberService returns the subscriber business logic backed by db
+func subscriberService(db *gorm.DB) service.SubscriberService {
+	return service.NewSubscriberService(repository.NewSubscriberRepository(db))
+}
 
-// validateSubscriberFields performs stricter checks on email and name
-func validateSubscriberFields(sub *models.Subscriber) error {
-	// Email must not be empty, must contain '@', must match our robust pattern
-	if sub.Email == "" || !emailRegex.MatchString(sub.Email) {
-		return fmt.Errorf("invalid or missing email")
+// subscriberValidationFailed writes the 400 for a service.ValidationError, with a code for
+// email domain problems, or the 422 listing the accepted names for unknown subscriber_types
+func subscriberValidationFailed(c *fiber.Ctx, err error) error {
+	var types *service.InvalidTypesError
+	if errors.As(err, &types) {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(dto.InvalidSubscriberTypesResponse{
+			Error:    types.Error(),
+			Code:     service.ErrInvalidType.Code,
+			Invalid:  types.Invalid,
+			Accepted: types.Accepted,
+		})
 	}
-
-	// Name must be non-empty
-	if strings.TrimSpace(sub.Name) == "" {
-		return fmt.Errorf("missing name")
+	var invalid *service.ValidationError
+	errors.As(err, &invalid)
+	body := fiber.Map{"error": invalid.Error()}
+	if invalid.Code != "" {
+		body["code"] = invalid.Code
 	}
-	return nil
+	return c.Status(fiber.StatusBadRequest).JSON(body)
 }
 
 // CreateSubscriber godoc
 // @Summary      Create a new subscriber
 // @Description  Creates a new subscriber record, optionally with multiple subscriber_types. Validates email & name.
+// @Description  Disposable email domains are rejected with code disposable_email; with EMAIL_MX_CHECK on, domains without mail servers with code undeliverable_email_domain.
+// @Description  subscriber_types must be listed by GET /admin/subscriber-types; unknown names are rejected with 422 listing them and the accepted ones.
+// @Description  Subscribers created by admins start out active. metadata is a free-form JSON object of at most 16KB (code metadata_too_large).
 // @Tags         subscribers
 // @Accept       json
-// @Produce      json
-// @Param        subscriber  body      models.Subscriber  true  "Subscriber info (with subscriber_types optional)"
-// @Success      201         {object}  models.Subscriber
-// @Failure      400         {string}  string
-// @Failure      500         {string}  string
+// @Produce      json,application/xml,application/msgpack
+// @Param        subscriber  body      dto.CreateSubscriberRequest  true  "Subscriber info (with subscriber_types optional)"
+// @Success      201         {object}  dto.SubscriberResponse
+// @Failure      400         {object}  dto.ErrorResponse
+// @Failure      422         {object}  dto.InvalidSubscriberTypesResponse
+// @Failure      500         {object}  dto.ErrorResponse
 // @Router       /admin/subscribers [post]
-// @Router       /signup/subscribers [post]
 func CreateSubscriber(db *gorm.DB) fiber.Handler {
+	return createSubscriber(db, false, true)
+}
+
+// SignupSubscriber godoc
+// @Summary      Sign up as a subscriber
+// @Description  Public signup, same body and validation as the admin create; metadata is ignored.
+// @Description  With SIGNUP_DOUBLE_OPT_IN=true the subscriber starts out pending and is emailed a confirmation link (see /signup/subscribers/confirm).
+// @Description  A captcha token (captcha_token body field or X-Captcha-Token header) is required when captcha is enabled, and a filled honeypot field is rejected.
+// @Description  utm_source, utm_medium, utm_campaign and referrer record the channel the signup came from, see /admin/stats/sources; an invalid referrer is rejected with code invalid_attribution.
+// @Description  ref (or ?ref=) is the referral_code of the subscriber who referred the signup, see /admin/stats/referrals; unknown codes are ignored.
+// @Description  Signups scoring SPAM_QUARANTINE_SCORE (70 by default) or more on the spam checks (IP velocity, gibberish name, domain reputation) are created quarantined, and emailed nothing until an admin reviews them.
+// @Tags         subscribers
+// @Accept       json
+// @Produce      json,application/xml,application/msgpack
+// @Param        subscriber  body      dto.CreateSubscriberRequest  true  "Subscriber info (with subscriber_types optional)"
+// @Success      201         {object}  dto.SubscriberResponse
+// @Failure      400         {object}  dto.ErrorResponse
+// @Failure      404         {object}  dto.ErrorResponse
+// @Failure      422         {object}  dto.InvalidSubscriberTypesResponse
+// @Failure      500         {object}  dto.ErrorResponse
+// @Router       /signup/subscribers [post]
+func SignupSubscriber(db *gorm.DB) fiber.Handler {
+	return createSubscriber(db, os.Getenv("SIGNUP_DOUBLE_OPT_IN") == "true", false)
+}
+
+// createSubscriber inserts a subscriber in the caller's organization; only admins set metadata
+func createSubscriber(db *gorm.DB, doubleOptIn, admin bool) fiber.Handler {
+	svc := subscriberService(db)
 	return func(c *fiber.Ctx) error {
-		var subscriber models.Subscriber
-		if err := c.BodyParser(&subscriber); err != nil {
+		var req dto.CreateSubscriberRequest
+		if err := c.BodyParser(&req); err != nil {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Unable to parse request body"})
 		}
-
-		// Validate email & name
-		if err := validateSubscriberFields(&subscriber); err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		fields := req.ToModel()
+		if !admin {
+			fields.Metadata = nil
+			// someone signing up themselves is written to in the language they browse in
+			if fields.Locale == "" {
+				fields.Locale = middleware.CurrentLocale(c)
+			}
 		}
 
-		err := db.Create(&subscriber).Error
+		in := service.CreateSubscriberInput{
+			OrgID:           middleware.CurrentOrgID(c),
+			Email:           fields.Email,
+			Name:            fields.Name,
+			SubscriberTypes: fields.SubscriberTypes,
+			Metadata:        fields.Metadata,
+			Locale:          fields.Locale,
+			DoubleOptIn:     doubleOptIn,
+		}
+		if !admin {
+			in.Ref = req.Ref
+			publicSignup(c, &in, service.Attribution{
+				Source:   req.UTMSource,
+				Medium:   req.UTMMedium,
+				Campaign: req.UTMCampaign,
+				Referrer: req.Referrer,
+			})
+		}
+		subscriber, err := svc.Create(c.UserContext(), in)
+		var invalid *service.ValidationError
+		if errors.As(err, &invalid) {
+			return subscriberValidationFailed(c, err)
+		}
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"error": fmt.Sprintf("Could not create subscriber: %v", err),
 			})
 		}
+		if !admin {
+			anomaly.CountSignup(c.UserContext())
+		}
+		return render(c.Status(fiber.StatusCreated), "subscriber", subscriberResponse(c, *subscriber))
+	}
+}
 
-		// Return with joined subscriber_types
-		if err := db.Preload("SubscriberTypes").First(&subscriber, subscriber.ID).Error; err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Failed to load created subscriber with subscriber_types",
-			})
+// publicSignup completes the input of a signup by the subscriber themselves with its spam
+// scoring, location and attribution, and the referral code of the ?ref= of the link followed
+// when the body has none
+func publicSignup(c *fiber.Ctx, in *service.CreateSubscriberInput, attribution service.Attribution) {
+	if in.Ref == "" {
+		in.Ref = c.Query("ref")
+	}
+	// likely spam is created quarantined rather than rejected, for an admin to review
+	verdict := spam.Score(c.UserContext(), spam.Signup{OrgID: in.OrgID, Email: in.Email, Name: in.Name, IP: c.IP()})
+	in.SpamScore, in.Quarantine = verdict.Score, verdict.Quarantined
+	in.SpamSignals = models.JSONMap{}
+	for name, points := range verdict.Signals {
+		in.SpamSignals[name] = points
+	}
+	location := geoip.Lookup(c.IP())
+	in.Country, in.Region = location.Country, location.Region
+	in.Attribution = attribution
+}
+
+// subscriberPage is a list response as held in the read cache
+type subscriberPage struct {
+	Subscribers []dto.SubscriberResponse `json:"subscribers"`
+	NextCursor  string                   `json:"next_cursor,omitempty"`
+	Total       *int64                   `json:"total,omitempty"`
+}
+
+// subscriberRow is a subscriber read along with the number of rows matching its query,
+// counted by a window function so the page and the total take one query
+type subscriberRow struct {
+	models.Subscriber
+	TotalCount int64 `gorm:"column:total_count"`
+}
+
+// subscribersLastModified is when the subscribers of the caller's organization last changed:
+// the latest update or soft delete. It's cached in Redis until the next subscriber write.
+func subscribersLastModified(c *fiber.Ctx, db *gorm.DB) (time.Time, error) {
+	ctx := c.UserContext()
+	key := ""
+	if cache.Enabled() {
+		key = cache.LastModifiedKey(ctx, middleware.CurrentOrgID(c))
+		var cached time.Time
+		if cache.Get(ctx, key, &cached) {
+			return cached, nil
+		}
+	}
+
+	subscribers := func() *gorm.DB {
+		return db.WithContext(ctx).Unscoped().Model(&models.Subscriber{}).Scopes(orgScope(c)).Limit(1)
+	}
+	var updated, deleted []time.Time
+	if err := subscribers().Order("updated_at DESC").Pluck("updated_at", &updated).Error; err != nil {
+		return time.Time{}, err
+	}
+	if err := subscribers().Where("deleted_at IS NOT NULL").Order("deleted_at DESC").Pluck("deleted_at", &deleted).Error; err != nil {
+		return time.Time{}, err
+	}
+
+	var modified time.Time
+	for _, t := range append(updated, deleted...) {
+		if t.After(modified) {
+			modified = t
+		}
+	}
+	if key != "" {
+		cache.Set(ctx, key, modified)
+	}
+	return modified, nil
+}
+
+// deliveryFilter reads the ?status= (comma separated) and ?verified= filters of the admin list
+// endpoints, the ?subscriber_type=, ?frequency= and ?channel= ones campaigns are targeted with,
+// and the ?filter= expression
+func deliveryFilter(c *fiber.Ctx) (func(*gorm.DB) *gorm.DB, error) {
+	return deliveryFilterOf(c).Scope()
+}
+
+// deliveryFilterOf reads the filters of deliveryFilter
+func deliveryFilterOf(c *fiber.Ctx) repository.SubscriberFilter {
+	f := repository.SubscriberFilter{
+		Verified:       c.Query("verified"),
+		SubscriberType: c.Query("subscriber_type"),
+		Frequency:      c.Query("frequency"),
+		Channel:        c.Query("channel"),
+		Query:          c.Query("filter"),
+	}
+	if param := c.Query("status"); param != "" {
+		for _, st := range strings.Split(param, ",") {
+			f.Statuses = append(f.Statuses, strings.TrimSpace(st))
 		}
-		return c.Status(fiber.StatusCreated).JSON(subscriber)
+	}
+	return f
+}
+
+// setPageHeaders sets the X-Next-Cursor and X-Total-Count headers of a list page
+func setPageHeaders(c *fiber.Ctx, page subscriberPage) {
+	if page.NextCursor != "" {
+		c.Set(NextCursorHeader, page.NextCursor)
+	}
+	if page.Total != nil {
+		c.Set(TotalCountHeader, strconv.FormatInt(*page.Total, 10))
 	}
 }
 
 // GetAllSubscribers godoc
 // @Summary      Get all subscribers
-// @Description  Returns a list of all subscribers, including their subscriber_types
+// @Description  Returns a list of all subscribers. Relations are only embedded when asked for: include=subscriber_types,notes (unknown ones are rejected with code invalid_include).
+// @Description  Pass limit (with offset, or with the opaque cursor from the X-Next-Cursor header) to page through the list.
+// @Description  Cursor pagination is stable while rows are added or removed mid-iteration. Other reads return the number of matching subscribers in X-Total-Count.
+// @Description  Filter by status and verified to only target deliverable addresses, and by subscriber_type, frequency and channel to target a campaign (all three apply to the same subscriber_type).
+// @Description  segment targets a saved segment (see /admin/segments) instead, or on top of the other filters; unknown ids are rejected with code unknown_segment.
+// @Description  filter narrows the list with an expression, e.g. filter=type:shopper AND NOT tag:vip (see POST /admin/segments/validate); invalid ones are rejected with code invalid_filter and the position of the part at fault.
+// @Description  fields picks the fields returned, e.g. fields=id,email for a sync that only needs addresses; a relation listed there is embedded too. Unknown fields are rejected with code invalid_fields.
+// @Description  Last-Modified is when any of the organization's subscribers last changed, rounded up to the second and only sent once that second is over; send it back in If-Modified-Since to get a 304 when nothing did.
+// @Description  Accept: application/xml or application/msgpack returns the list as XML (<subscribers><item>…</item></subscribers>) or MessagePack instead of JSON.
 // @Tags         subscribers
-// @Produce      json
-// @Success      200  {array}   models.Subscriber
-// @Failure      500  {string}  string
+// @Produce      json,application/xml,application/msgpack
+// @Param        status             query     string  false  "Comma separated statuses: pending, active, bounced, unsubscribed, quarantined"
+// @Param        verified           query     bool    false  "Only subscribers with (true) or without (false) a verified email"
+// @Param        subscriber_type    query     string  false  "Only subscribers having this subscriber_type"
+// @Param        frequency          query     string  false  "Only subscribers with a subscriber_type at this frequency: daily, weekly or monthly"
+// @Param        channel            query     string  false  "Only subscribers with a subscriber_type on this channel: email or sms"
+// @Param        segment            query     int     false  "Only the members of this segment"
+// @Param        filter             query     string  false  "Filter expression, e.g. type:shopper AND NOT tag:vip, see POST /admin/segments/validate"
+// @Param        limit              query     int     false  "Page size (default 50 when paginating, max 500)"
+// @Param        offset             query     int     false  "Rows to skip (offset pagination)"
+// @Param        cursor             query     string  false  "Opaque cursor from a previous X-Next-Cursor header"
+// @Param        sort               query     string  false  "Sort key: id (default), created_at or updated_at"
+// @Param        fields             query     string  false  "Comma separated fields to return, e.g. id,email,created_at (default all)"
+// @Param        include            query     string  false  "Comma separated relations to embed: subscriber_types, notes (default none)"
+// @Param        If-Modified-Since  header    string  false  "Last-Modified from a previous read"
+// @Success      200  {array}   dto.SubscriberResponse
+// @Header       200  {string}  X-Next-Cursor  "Cursor for the next page, absent on the last page"
+// @Header       200  {int}     X-Total-Count  "Subscribers matching the filters, absent on cursor pages"
+// @Header       200  {string}  Last-Modified  "When the organization's subscribers last changed, whichever page or filter is read"
+// @Success      304  {string}  string  "Not modified"
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
 // @Router       /admin/subscribers [get]
 func GetAllSubscribers(db *gorm.DB) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		var subscribers []models.Subscriber
-		if err := db.Preload("SubscriberTypes").Find(&subscribers).Error; err != nil {
+		db := database.Replica(db.WithContext(c.UserContext()))
+		page, err := parsePageParams(c)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		filter, err := deliveryFilter(c)
+		if err != nil {
+			return filterRejected(c, err)
+		}
+		segmentVersion := ""
+		if param := c.Query("segment"); param != "" {
+			id, err := strconv.ParseUint(param, 10, 0)
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid segment"})
+			}
+			segment, segmented, err := segmentFilter(c, db, uint(id))
+			if errors.Is(err, errUnknownSegment) {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Unknown segment", "code": "unknown_segment"})
+			}
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve segment"})
+			}
+			bySegment, err := segmented.Scope()
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not read segment filter"})
+			}
+			byQuery := filter
+			filter = func(db *gorm.DB) *gorm.DB { return bySegment(byQuery(db)) }
+			// an edited segment lists other subscribers, though none of them changed
+			segmentVersion = fmt.Sprintf("&segment_version=%d", segment.UpdatedAt.UnixNano())
+		}
+		projection, err := subscriberProjectionFor(c)
+		if err != nil {
+			return invalidProjection(c, err)
+		}
+
+		// Nothing changed since the caller's copy, whatever page or filter it is
+		modified, err := subscribersLastModified(c, db)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Could not retrieve subscribers",
+			})
+		}
+		if notModifiedSince(c, modified) {
+			return c.SendStatus(fiber.StatusNotModified)
+		}
+
+		cacheKey := ""
+		if cache.Enabled() {
+			cacheKey = cache.SubscriberListKey(c.UserContext(), fmt.Sprintf("org=%d&%s%s", middleware.CurrentOrgID(c), c.Request().URI().QueryString(), segmentVersion))
+			var cached subscriberPage
+			if cache.Get(c.UserContext(), cacheKey, &cached) {
+				setPageHeaders(c, cached)
+				return render(c, "subscribers", projection.many(redactSubscribersFor(c, cached.Subscribers)))
+			}
+		}
+
+		// The page and its total in one query, then one query per included relation
+		var rows []subscriberRow
+		query := db.Model(&models.Subscriber{}).Scopes(orgScope(c), filter).
+			Select(append(projection.columns(), "COUNT(*) OVER() AS total_count"))
+		if err := page.apply(query).Find(&rows).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Could not retrieve subscribers",
+			})
+		}
+		subscribers := make([]models.Subscriber, len(rows))
+		for i, row := range rows {
+			subscribers[i] = row.Subscriber
+		}
+
+		result := subscriberPage{}
+		// keyset pages only see the rows after their cursor, so they get no total
+		if page.Cursor == nil {
+			var total int64
+			if len(rows) > 0 {
+				total = rows[0].TotalCount
+			} else if page.Offset > 0 {
+				// an offset past the end still has a total
+				if err := db.Model(&models.Subscriber{}).Scopes(orgScope(c), filter).Count(&total).Error; err != nil {
+					return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+						"error": "Could not retrieve subscribers",
+					})
+				}
+			}
+			result.Total = &total
+		}
+		if page.Paginated() && len(subscribers) > page.Limit {
+			subscribers = subscribers[:page.Limit]
+			last := subscribers[len(subscribers)-1]
+			result.NextCursor = page.nextCursor(last.ID, last.CreatedAt, last.UpdatedAt)
+		}
+		if err := projection.load(db, subscribers); err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"error": "Could not retrieve subscribers",
 			})
 		}
-		return c.JSON(subscribers)
+		result.Subscribers = dto.NewSubscriberResponses(subscribers)
+
+		if cacheKey != "" {
+			cache.Set(c.UserContext(), cacheKey, result)
+		}
+		setPageHeaders(c, result)
+		return render(c, "subscribers", projection.many(redactSubscribersFor(c, result.Subscribers)))
 	}
 }
 
 // GetSubscriber godoc
 // @Summary      Get a single subscriber
-// @Description  Gets subscriber by id, including all subscriber_types
+// @Description  Gets subscriber by id, including all subscriber_types, or only the fields listed in fields (unknown ones are rejected with code invalid_fields).
+// @Description  include picks the relations embedded instead: e.g. include=subscriber_types,notes, or an empty include for none (unknown ones are rejected with code invalid_include).
+// @Description  The ETag header identifies this revision; send it back in If-None-Match to get a 304 when unchanged.
+// @Description  Accept: application/xml or application/msgpack returns the subscriber as XML (<subscriber>…</subscriber>) or MessagePack instead of JSON.
 // @Tags         subscribers
-// @Produce      json
-// @Param        id   path      int true "Subscriber ID"
-// @Success      200  {object}  models.Subscriber
-// @Failure      400  {string}  string
-// @Failure      404  {string}  string
+// @Produce      json,application/xml,application/msgpack
+// @Param        id             path      int     true   "Subscriber ID"
+// @Param        fields         query     string  false  "Comma separated fields to return, e.g. id,email,created_at (default all)"
+// @Param        include        query     string  false  "Comma separated relations to embed: subscriber_types, notes (default subscriber_types, none with fields)"
+// @Param        If-None-Match  header    string  false  "ETag from a previous read"
+// @Success      200  {object}  dto.SubscriberResponse
+// @Header       200  {string}  ETag  "Current revision of the subscriber"
+// @Success      304  {string}  string  "Not modified"
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
 // @Router       /admin/subscribers/{id} [get]
 func GetSubscriber(db *gorm.DB) fiber.Handler {
 	return func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
 		idParam := c.Params("id")
 		id, err := strconv.Atoi(idParam)
 		if err != nil {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid subscriber ID"})
 		}
+		projection, err := subscriberProjectionFor(c, "subscriber_types")
+		if err != nil {
+			return invalidProjection(c, err)
+		}
 
-		var subscriber models.Subscriber
-		if err := db.Preload("SubscriberTypes").First(&subscriber, id).Error; err != nil {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Subscriber not found"})
+		var resp dto.SubscriberResponse
+		if !projection.cached() || !cache.Get(c.UserContext(), cache.SubscriberKey(uint(id)), &resp) || resp.OrgID != middleware.CurrentOrgID(c) {
+			var subscriber models.Subscriber
+			if err := db.Scopes(orgScope(c), projection.scope).First(&subscriber, id).Error; err != nil {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Subscriber not found"})
+			}
+			found := []models.Subscriber{subscriber}
+			if err := projection.load(db, found); err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve subscriber"})
+			}
+			resp = dto.NewSubscriberResponse(found[0])
+			// only whole records are cached
+			if projection.whole() {
+				cache.Set(c.UserContext(), cache.SubscriberKey(subscriber.ID), resp)
+			}
+		}
+
+		if notModified(c, subscriberETag(resp.ID, resp.UpdatedAt)) {
+			return c.SendStatus(fiber.StatusNotModified)
 		}
-		return c.JSON(subscriber)
+		return render(c, "subscriber", projection.one(redactSubscriberFor(c, resp)))
 	}
 }
 
 // UpdateSubscriber godoc
 // @Summary      Update a subscriber
-// @Description  Updates subscriber by id. If subscriber_types are provided, it overwrites them. Validates email & name.
+// @Description  Updates subscriber by id. If subscriber_types or metadata are provided, they overwrite the stored ones. Validates email & name.
+// @Description  If-Match must carry the ETag of the revision being edited, so concurrent edits are not silently lost.
 // @Tags         subscribers
 // @Accept       json
-// @Produce      json
-// @Param        id   path      int true "Subscriber ID"
-// @Param        subscriber  body      models.Subscriber  true  "Subscriber info (subscriber_types optional)"
-// @Success      200  {object}  models.Subscriber
-// @Failure      400  {string}  string
-// @Failure      404  {string}  string
-// @Failure      500  {string}  string
+// @Produce      json,application/xml,application/msgpack
+// @Param        id          path      int                          true  "Subscriber ID"
+// @Param        If-Match    header    string                       true  "ETag from GET /admin/subscribers/{id}"
+// @Param        subscriber  body      dto.UpdateSubscriberRequest  true  "Subscriber info (subscriber_types optional)"
+// @Success      200  {object}  dto.SubscriberResponse
+// @Header       200  {string}  ETag  "New revision of the subscriber"
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      409  {object}  dto.ErrorResponse  "code: version_conflict"
+// @Failure      412  {object}  dto.ErrorResponse  "code: precondition_failed"
+// @Failure      422  {object}  dto.InvalidSubscriberTypesResponse
+// @Failure      428  {object}  dto.ErrorResponse  "code: if_match_required"
+// @Failure      500  {object}  dto.ErrorResponse
 // @Router       /admin/subscribers/{id} [put]
 func UpdateSubscriber(db *gorm.DB) fiber.Handler {
+	svc := subscriberService(db)
 	return func(c *fiber.Ctx) error {
 		idParam := c.Params("id")
 		id, convErr := strconv.Atoi(idParam)
@@ -138,81 +473,73 @@ func UpdateSubscriber(db *gorm.DB) fiber.Handler {
 		}
 
 		// Get existing subscriber
-		var existing models.Subscriber
-		if err := db.Preload("SubscriberTypes").First(&existing, id).Error; err != nil {
+		existing, err := svc.Get(c.UserContext(), middleware.CurrentOrgID(c), uint(id))
+		if err != nil {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Subscriber not found"})
 		}
 
-		// Parse the incoming updates
-		var updates models.Subscriber
-		if err := c.BodyParser(&updates); err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Unable to parse request body"})
+		// Refuse to overwrite a revision the caller has not seen
+		if ok, err := checkIfMatch(c, subscriberETag(existing.ID, existing.UpdatedAt)); !ok {
+			return err
 		}
 
-		// Validate email & name
-		if err := validateSubscriberFields(&updates); err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		// Parse the incoming updates
+		var req dto.UpdateSubscriberRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Unable to parse request body"})
 		}
+		updates := req.ToModel()
 
-		// Update basic fields
-		existing.Email = updates.Email
-		existing.Name = updates.Name
-
-		// If subscriber_types are present, overwrite
-		if updates.SubscriberTypes != nil && len(updates.SubscriberTypes) > 0 {
-			// remove all existing subscriber_types
-			if err := db.Where("subscriber_id = ?", existing.ID).Delete(&models.SubscriberType{}).Error; err != nil {
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-					"error": "Could not clear old subscriber_types",
-				})
-			}
-			// create new subscriber_types referencing existing.ID
-			for i := range updates.SubscriberTypes {
-				updates.SubscriberTypes[i].SubscriberID = existing.ID
-			}
-			if err := db.Create(&updates.SubscriberTypes).Error; err != nil {
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-					"error": "Could not create updated subscriber_types",
-				})
-			}
-		} else if updates.SubscriberTypes != nil && len(updates.SubscriberTypes) == 0 {
-			// If an empty slice was explicitly passed
-			if err := db.Where("subscriber_id = ?", existing.ID).Delete(&models.SubscriberType{}).Error; err != nil {
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-					"error": "Could not remove subscriber_types",
-				})
-			}
+		// Without an explicit version, the revision matched by If-Match is the one being edited
+		version := req.Version
+		if version == nil {
+			version = &existing.Version
 		}
-
-		// Save subscriber base fields
-		if err := db.Save(&existing).Error; err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Could not update subscriber",
+		subscriber, err := svc.Update(c.UserContext(), service.UpdateSubscriberInput{
+			OrgID:           existing.OrgID,
+			ID:              existing.ID,
+			Email:           updates.Email,
+			Name:            updates.Name,
+			SubscriberTypes: updates.SubscriberTypes,
+			Metadata:        updates.Metadata,
+			Locale:          updates.Locale,
+			Version:         version,
+		})
+		var invalid *service.ValidationError
+		switch {
+		case errors.As(err, &invalid):
+			return subscriberValidationFailed(c, err)
+		case errors.Is(err, service.ErrNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Subscriber not found"})
+		case errors.Is(err, service.ErrVersionConflict):
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "Subscriber was modified by someone else",
+				"code":  "version_conflict",
 			})
-		}
-
-		// Return with joined subscriber_types
-		if err := db.Preload("SubscriberTypes").First(&existing, existing.ID).Error; err != nil {
+		case err != nil:
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Failed to fetch updated subscriber",
+				"error": "Could not update subscriber",
 			})
 		}
 
-		return c.JSON(existing)
+		c.Set(fiber.HeaderETag, subscriberETag(subscriber.ID, subscriber.UpdatedAt))
+		return render(c, "subscriber", subscriberResponse(c, *subscriber))
 	}
 }
 
 // DeleteSubscriber godoc
 // @Summary      Delete a subscriber
 // @Description  Deletes subscriber by id (and associated subscriber_types).
+// @Description  The subscriber is soft-deleted: POST /admin/subscribers/{id}/undelete brings it back with its subscriber_types, notes and history until it's purged for good after SUBSCRIBER_RETENTION_DAYS.
 // @Tags         subscribers
 // @Param        id   path      int true "Subscriber ID"
 // @Success      204  {string}  string
-// @Failure      400  {string}  string
-// @Failure      404  {string}  string
-// @Failure      500  {string}  string
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
 // @Router       /admin/subscribers/{id} [delete]
 func DeleteSubscriber(db *gorm.DB) fiber.Handler {
+	svc := subscriberService(db)
 	return func(c *fiber.Ctx) error {
 		idParam := c.Params("id")
 		id, convErr := strconv.Atoi(idParam)
@@ -220,23 +547,57 @@ func DeleteSubscriber(db *gorm.DB) fiber.Handler {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid subscriber ID"})
 		}
 
-		var subscriber models.Subscriber
-		if err := db.First(&subscriber, id).Error; err != nil {
+		err := svc.Delete(c.UserContext(), middleware.CurrentOrgID(c), uint(id))
+		if errors.Is(err, service.ErrNotFound) {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Subscriber not found"})
 		}
-
-		// Remove subscriber_types first (if not using a cascade constraint).
-		if err := db.Where("subscriber_id = ?", subscriber.ID).Delete(&models.SubscriberType{}).Error; err != nil {
+		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Could not delete subscriber_types",
+				"error": "Could not delete subscriber",
 			})
 		}
+		return c.SendStatus(fiber.StatusNoContent)
+	}
+}
+
+// UndeleteSubscriber godoc
+// @Summary      Undelete a subscriber
+// @Description  Brings back a subscriber deleted less than SUBSCRIBER_RETENTION_DAYS ago, with the subscriber_types, notes and history it had, as an undeleted revision.
+// @Description  Subscribers deleted longer ago are due to be purged and get a 410 with code undo_window_expired.
+// @Tags         subscribers
+// @Produce      json,application/xml,application/msgpack
+// @Param        id   path      int true "Subscriber ID"
+// @Success      200  {object}  dto.SubscriberResponse
+// @Header       200  {string}  ETag  "Current revision of the subscriber"
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse  "Unknown or not deleted"
+// @Failure      410  {object}  dto.ErrorResponse  "code: undo_window_expired"
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/subscribers/{id}/undelete [post]
+func UndeleteSubscriber(db *gorm.DB) fiber.Handler {
+	svc := subscriberService(db)
+	return func(c *fiber.Ctx) error {
+		id, err := strconv.Atoi(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid subscriber ID"})
+		}
 
-		if err := db.Delete(&subscriber).Error; err != nil {
+		subscriber, err := svc.Undelete(c.UserContext(), middleware.CurrentOrgID(c), uint(id))
+		switch {
+		case errors.Is(err, service.ErrNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Deleted subscriber not found"})
+		case errors.Is(err, service.ErrUndoWindowExpired):
+			return c.Status(fiber.StatusGone).JSON(fiber.Map{
+				"error": "Subscriber was deleted too long ago to be undeleted",
+				"code":  "undo_window_expired",
+			})
+		case err != nil:
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Could not delete subscriber",
+				"error": "Could not undelete subscriber",
 			})
 		}
-		return c.SendStatus(fiber.StatusNoContent)
+
+		c.Set(fiber.HeaderETag, subscriberETag(subscriber.ID, subscriber.UpdatedAt))
+		return render(c, "subscriber", subscriberResponse(c, *subscriber))
 	}
 }
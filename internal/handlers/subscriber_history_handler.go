@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
+	"fiber-gorm-api/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// GetSubscriberHistory godoc
+// @Summary      Subscriber revision history
+// @Description  Lists the revisions of a subscriber, newest first: who made each write, the subscriber before and after it, and the changed fields for rendering a diff (metadata keys as metadata.<key>).
+// @Description  Pages hold limit revisions (50 by default); pass the id of the last revision received as before to get the next page. Callers without the pii scope see masked emails.
+// @Tags         subscribers
+// @Produce      json
+// @Param        id      path      int  true   "Subscriber ID"
+// @Param        limit   query     int  false  "Page size, at most 500"
+// @Param        before  query     int  false  "Only revisions older than this revision id"
+// @Success      200     {array}   dto.SubscriberRevisionResponse
+// @Failure      400     {object}  dto.ErrorResponse
+// @Failure      404     {object}  dto.ErrorResponse
+// @Failure      500     {object}  dto.ErrorResponse
+// @Router       /admin/subscribers/{id}/history [get]
+func GetSubscriberHistory(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		subscriber, err := findSubscriberOf(c, db)
+		if subscriber == nil {
+			return err
+		}
+
+		limit := c.QueryInt("limit", defaultPageLimit)
+		if limit < 1 || limit > maxPageLimit {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid limit"})
+		}
+		query := db.Where("subscriber_id = ?", subscriber.ID)
+		if before := c.Query("before"); before != "" {
+			id, err := strconv.Atoi(before)
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid before"})
+			}
+			query = query.Where("id < ?", id)
+		}
+
+		var revisions []models.SubscriberRevision
+		if err := query.Order("id DESC").Limit(limit).Find(&revisions).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve history"})
+		}
+		resp, err := dto.NewSubscriberRevisionResponses(revisions)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not decode history"})
+		}
+		if !middleware.CanSeePII(c) {
+			for i := range resp {
+				resp[i] = resp[i].Redacted()
+			}
+		}
+		return c.JSON(resp)
+	}
+}
+
+// RestoreSubscriberRevision godoc
+// @Summary      Roll a subscriber back to a revision
+// @Description  Applies the state the subscriber had after the given revision (email, name, status, verification, metadata and subscriber_types) and records the rollback as a new restored revision.
+// @Description  A bounce or opt-out since the revision is kept: unsubscribed and bounced subscribers keep their status.
+// @Description  Revisions holding subscriber_types that were removed since are rejected with 422.
+// @Tags         subscribers
+// @Produce      json,application/xml,application/msgpack
+// @Param        id        path      int  true  "Subscriber ID"
+// @Param        revision  path      int  true  "Revision ID"
+// @Success      200       {object}  dto.SubscriberResponse
+// @Failure      400       {object}  dto.ErrorResponse
+// @Failure      404       {object}  dto.ErrorResponse
+// @Failure      409       {object}  dto.ErrorResponse  "code: version_conflict or anonymized"
+// @Failure      422       {object}  dto.InvalidSubscriberTypesResponse
+// @Failure      500       {object}  dto.ErrorResponse
+// @Router       /admin/subscribers/{id}/history/{revision}/restore [post]
+func RestoreSubscriberRevision(db *gorm.DB) fiber.Handler {
+	svc := subscriberService(db)
+	return func(c *fiber.Ctx) error {
+		id, err := strconv.Atoi(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid subscriber ID"})
+		}
+		revisionID, err := strconv.Atoi(c.Params("revision"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid revision ID"})
+		}
+
+		subscriber, err := svc.Restore(c.UserContext(), middleware.CurrentOrgID(c), uint(id), uint(revisionID))
+		var invalid *service.ValidationError
+		switch {
+		case errors.As(err, &invalid):
+			return subscriberValidationFailed(c, err)
+		case errors.Is(err, service.ErrNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Subscriber not found"})
+		case errors.Is(err, service.ErrRevisionNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Revision not found"})
+		case errors.Is(err, service.ErrVersionConflict):
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "Subscriber was modified by someone else",
+				"code":  "version_conflict",
+			})
+		case errors.Is(err, service.ErrAnonymized):
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "Anonymized subscribers can't be restored",
+				"code":  "anonymized",
+			})
+		case err != nil:
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not restore subscriber"})
+		}
+
+		c.Set(fiber.HeaderETag, subscriberETag(subscriber.ID, subscriber.UpdatedAt))
+		return render(c, "subscriber", subscriberResponse(c, *subscriber))
+	}
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// MergeSubscribers godoc
+// @Summary      Merge a duplicate subscriber
+// @Description  Folds the subscriber source_id into the subscriber of the path: its subscriber_types, notes and email delivery history move over, metadata is combined (the target's values win) and the source is soft-deleted.
+// @Description  email_rule picks the address kept, with its verification and status: target (default), source, or verified, the verified address (the target's when both or neither are).
+// @Tags         subscribers
+// @Accept       json
+// @Produce      json,application/xml,application/msgpack
+// @Param        id     path      int                          true  "Target subscriber ID"
+// @Param        merge  body      dto.MergeSubscribersRequest  true  "Source subscriber and email rule"
+// @Success      200    {object}  dto.SubscriberResponse
+// @Failure      400    {object}  dto.ErrorResponse  "code: merge_into_self or invalid_email_rule"
+// @Failure      404    {object}  dto.ErrorResponse
+// @Failure      409    {object}  dto.ErrorResponse  "code: version_conflict or anonymized"
+// @Failure      500    {object}  dto.ErrorResponse
+// @Router       /admin/subscribers/{id}/merge [post]
+func MergeSubscribers(db *gorm.DB) fiber.Handler {
+	svc := subscriberService(db)
+	return func(c *fiber.Ctx) error {
+		id, err := strconv.Atoi(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid subscriber ID"})
+		}
+		var req dto.MergeSubscribersRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Unable to parse request body"})
+		}
+		if req.SourceID == 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing source_id"})
+		}
+
+		subscriber, err := svc.Merge(c.UserContext(), service.MergeSubscribersInput{
+			OrgID:     middleware.CurrentOrgID(c),
+			TargetID:  uint(id),
+			SourceID:  req.SourceID,
+			EmailRule: req.EmailRule,
+			Version:   req.Version,
+		})
+		var invalid *service.ValidationError
+		switch {
+		case errors.As(err, &invalid):
+			return subscriberValidationFailed(c, err)
+		case errors.Is(err, service.ErrNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Subscriber not found"})
+		case errors.Is(err, service.ErrVersionConflict):
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "Subscriber was modified by someone else",
+				"code":  "version_conflict",
+			})
+		case errors.Is(err, service.ErrAnonymized):
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "Anonymized subscribers can't be merged",
+				"code":  "anonymized",
+			})
+		case err != nil:
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not merge subscribers"})
+		}
+
+		c.Set(fiber.HeaderETag, subscriberETag(subscriber.ID, subscriber.UpdatedAt))
+		return render(c, "subscriber", subscriberResponse(c, *subscriber))
+	}
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// maxNoteLength bounds a note's text, in characters
+const maxNoteLength = 10000
+
+// findSubscriberOf loads the subscriber of the :id param in the caller's organization, or
+// writes the 400/404 and returns nil
+func findSubscriberOf(c *fiber.Ctx, db *gorm.DB) (*models.Subscriber, error) {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return nil, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid subscriber ID"})
+	}
+	var subscriber models.Subscriber
+	if err := db.Scopes(orgScope(c)).First(&subscriber, id).Error; err != nil {
+		return nil, c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Subscriber not found"})
+	}
+	return &subscriber, nil
+}
+
+// CreateSubscriberNote godoc
+// @Summary      Add a note to a subscriber
+// @Description  Records free text about a subscriber (support context, call summaries, ...), authored by the caller. Text is required and at most 10000 characters.
+// @Tags         subscribers
+// @Accept       json
+// @Produce      json
+// @Param        id    path      int                              true  "Subscriber ID"
+// @Param        note  body      dto.CreateSubscriberNoteRequest  true  "Note"
+// @Success      201   {object}  dto.SubscriberNoteResponse
+// @Failure      400   {object}  dto.ErrorResponse
+// @Failure      404   {object}  dto.ErrorResponse
+// @Failure      500   {object}  dto.ErrorResponse
+// @Router       /admin/subscribers/{id}/notes [post]
+func CreateSubscriberNote(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		subscriber, err := findSubscriberOf(c, db)
+		if subscriber == nil {
+			return err
+		}
+
+		var req dto.CreateSubscriberNoteRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Unable to parse request body"})
+		}
+		text := strings.TrimSpace(req.Text)
+		if text == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing text"})
+		}
+		if utf8.RuneCountInString(text) > maxNoteLength {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Note is too long"})
+		}
+
+		note := models.SubscriberNote{
+			SubscriberID: subscriber.ID,
+			Author:       callerIdentity(c),
+			Text:         text,
+		}
+		if err := db.Create(&note).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not create note"})
+		}
+		return c.Status(fiber.StatusCreated).JSON(dto.NewSubscriberNoteResponse(note))
+	}
+}
+
+// GetSubscriberNotes godoc
+// @Summary      List a subscriber's notes
+// @Description  Lists the notes recorded about a subscriber, newest first.
+// @Tags         subscribers
+// @Produce      json
+// @Param        id   path      int true "Subscriber ID"
+// @Success      200  {array}   dto.SubscriberNoteResponse
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/subscribers/{id}/notes [get]
+func GetSubscriberNotes(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		subscriber, err := findSubscriberOf(c, db)
+		if subscriber == nil {
+			return err
+		}
+
+		var notes []models.SubscriberNote
+		if err := db.Where("subscriber_id = ?", subscriber.ID).
+			Order("created_at DESC").Order("id DESC").
+			Find(&notes).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve notes"})
+		}
+		return c.JSON(dto.NewSubscriberNoteResponses(notes))
+	}
+}
+
+// DeleteSubscriberNote godoc
+// @Summary      Delete a subscriber's note
+// @Description  Deletes one note of a subscriber.
+// @Tags         subscribers
+// @Param        id      path  int  true  "Subscriber ID"
+// @Param        noteId  path  int  true  "Note ID"
+// @Success      204  {string}  string
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/subscribers/{id}/notes/{noteId} [delete]
+func DeleteSubscriberNote(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		subscriber, err := findSubscriberOf(c, db)
+		if subscriber == nil {
+			return err
+		}
+		noteID, err := strconv.Atoi(c.Params("noteId"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid note ID"})
+		}
+
+		res := db.Where("id = ? AND subscriber_id = ?", noteID, subscriber.ID).Delete(&models.SubscriberNote{})
+		if res.Error != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not delete note"})
+		}
+		if res.RowsAffected == 0 {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Note not found"})
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	}
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+
+	database "fiber-gorm-api/internal/db"
+	"fiber-gorm-api/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	defaultSearchLimit = 50
+	maxSearchLimit     = 200
+)
+
+// likeEscaper escapes the LIKE wildcards so user input is matched literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// SearchSubscribers godoc
+// @Summary      Search subscribers
+// @Description  Partial / fuzzy matching on email and name (pg_trgm), ordered by relevance. On SQLite substring matches only, newest first. Optionally filtered by subscriber_type and its frequency and channel, and by a filter expression.
+// @Description  Answers 304 when If-Modified-Since is at or after the Last-Modified of the organization's subscribers.
+// @Tags         subscribers
+// @Produce      json,application/xml,application/msgpack
+// @Param        q                  query     string  true   "Search term (matched against email and name)"
+// @Param        subscriber_type    query     string  false  "Only return subscribers having this subscriber_type"
+// @Param        frequency          query     string  false  "Only subscribers with a subscriber_type at this frequency: daily, weekly or monthly"
+// @Param        channel            query     string  false  "Only subscribers with a subscriber_type on this channel: email or sms"
+// @Param        status             query     string  false  "Comma separated statuses: pending, active, bounced, unsubscribed, quarantined"
+// @Param        verified           query     bool    false  "Only subscribers with (true) or without (false) a verified email"
+// @Param        filter             query     string  false  "Filter expression, e.g. type:shopper AND NOT tag:vip, see POST /admin/segments/validate"
+// @Param        limit              query     int     false  "Max results (default 50, max 200)"
+// @Param        fields             query     string  false  "Comma separated fields to return, e.g. id,email,created_at (default all)"
+// @Param        include            query     string  false  "Comma separated relations to embed: subscriber_types, notes (default none)"
+// @Param        If-Modified-Since  header    string  false  "Last-Modified from a previous search"
+// @Success      200  {array}   dto.SubscriberResponse
+// @Header       200  {string}  Last-Modified  "When the organization's subscribers last changed"
+// @Success      304  {string}  string  "Not modified"
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/subscribers/search [get]
+func SearchSubscribers(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		db := database.Replica(db.WithContext(c.UserContext()))
+		q := strings.TrimSpace(c.Query("q"))
+		if q == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing search term"})
+		}
+
+		limit := defaultSearchLimit
+		if limitParam := c.Query("limit"); limitParam != "" {
+			n, err := strconv.Atoi(limitParam)
+			if err != nil || n < 1 {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid limit"})
+			}
+			if n > maxSearchLimit {
+				n = maxSearchLimit
+			}
+			limit = n
+		}
+
+		filter, err := deliveryFilter(c)
+		if err != nil {
+			return filterRejected(c, err)
+		}
+		projection, err := subscriberProjectionFor(c)
+		if err != nil {
+			return invalidProjection(c, err)
+		}
+
+		modified, err := subscribersLastModified(c, db)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Could not search subscribers",
+			})
+		}
+		if notModifiedSince(c, modified) {
+			return c.SendStatus(fiber.StatusNotModified)
+		}
+
+		pattern := "%" + likeEscaper.Replace(q) + "%"
+
+		query := db.Model(&models.Subscriber{}).Scopes(orgScope(c), filter)
+		if database.IsSQLite(db) {
+			// no pg_trgm: substring matches only, newest first
+			query = query.
+				Where(database.ILike(db, "subscribers.email")+" OR "+database.ILike(db, "subscribers.name"), pattern, pattern).
+				Order("subscribers.id DESC")
+		} else {
+			// Most relevant first: trigram similarity on either column, then newest
+			query = query.
+				Where("subscribers.email ILIKE ? OR subscribers.name ILIKE ? OR subscribers.email % ? OR subscribers.name % ?",
+					pattern, pattern, q, q).
+				Order(clause.OrderBy{Expression: clause.Expr{
+					SQL:                "GREATEST(similarity(subscribers.email, ?), similarity(COALESCE(subscribers.name, ''), ?)) DESC, subscribers.id DESC",
+					Vars:               []interface{}{q, q},
+					WithoutParentheses: true,
+				}})
+		}
+
+		query = query.Limit(limit)
+
+		var subscribers []models.Subscriber
+		if err := query.Scopes(projection.scope).Find(&subscribers).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Could not search subscribers",
+			})
+		}
+		if err := projection.load(db, subscribers); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Could not search subscribers",
+			})
+		}
+		return render(c, "subscribers", projection.many(subscriberResponses(c, subscribers)))
+	}
+}
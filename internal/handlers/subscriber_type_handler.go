@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"regexp"
+	"strings"
+
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// subscriberTypeNameRegex keeps type names short lowercase identifiers
+var subscriberTypeNameRegex = regexp.MustCompile(`^[a-z][a-z0-9_]{1,31}$`)
+
+func subscriberTypeDefinitionResponse(t models.SubscriberTypeDefinition) dto.SubscriberTypeDefinitionResponse {
+	return dto.SubscriberTypeDefinitionResponse{Name: t.Name, Description: t.Description, CreatedAt: t.CreatedAt}
+}
+
+// GetSubscriberTypes godoc
+// @Summary      List subscriber types
+// @Description  Lists the names subscriber_types may take, in alphabetical order. Subscriber writes with other names are rejected with code invalid_subscriber_type.
+// @Tags         subscriber-types
+// @Produce      json
+// @Success      200  {array}   dto.SubscriberTypeDefinitionResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/subscriber-types [get]
+func GetSubscriberTypes(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var types []models.SubscriberTypeDefinition
+		if err := db.WithContext(c.UserContext()).Order("name").Find(&types).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve subscriber types"})
+		}
+		resp := make([]dto.SubscriberTypeDefinitionResponse, len(types))
+		for i, t := range types {
+			resp[i] = subscriberTypeDefinitionResponse(t)
+		}
+		return c.JSON(resp)
+	}
+}
+
+// CreateSubscriberType godoc
+// @Summary      Add a subscriber type
+// @Description  Allows a new subscriber_types name, for every organization. Names are 2 to 32 lowercase letters, digits or underscores, starting with a letter. Platform admins only.
+// @Tags         subscriber-types
+// @Accept       json
+// @Produce      json
+// @Param        type  body      dto.CreateSubscriberTypeRequest  true  "Name and description"
+// @Success      201   {object}  dto.SubscriberTypeDefinitionResponse
+// @Failure      400   {object}  dto.ErrorResponse
+// @Failure      403   {object}  dto.ErrorResponse
+// @Failure      409   {object}  dto.ErrorResponse
+// @Failure      500   {object}  dto.ErrorResponse
+// @Router       /admin/subscriber-types [post]
+func CreateSubscriberType(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		var req dto.CreateSubscriberTypeRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Unable to parse request body"})
+		}
+		if !subscriberTypeNameRegex.MatchString(req.Name) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid name"})
+		}
+
+		var existing int64
+		if err := db.Model(&models.SubscriberTypeDefinition{}).Where("name = ?", req.Name).Count(&existing).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not create subscriber type"})
+		}
+		if existing > 0 {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Subscriber type already exists"})
+		}
+
+		t := models.SubscriberTypeDefinition{Name: req.Name, Description: strings.TrimSpace(req.Description)}
+		if err := db.Create(&t).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not create subscriber type"})
+		}
+		return c.Status(fiber.StatusCreated).JSON(subscriberTypeDefinitionResponse(t))
+	}
+}
+
+// DeleteSubscriberType godoc
+// @Summary      Remove a subscriber type
+// @Description  Disallows a subscriber_types name. Types still held by subscribers (deleted ones included) can't be removed. Platform admins only.
+// @Tags         subscriber-types
+// @Param        name  path  string  true  "Type name"
+// @Success      204  {string}  string
+// @Failure      403  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      409  {object}  dto.ErrorResponse  "code: type_in_use"
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/subscriber-types/{name} [delete]
+func DeleteSubscriberType(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		name := c.Params("name")
+
+		var inUse int64
+		if err := db.Model(&models.SubscriberType{}).Where("name = ?", name).Count(&inUse).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not delete subscriber type"})
+		}
+		if inUse > 0 {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "Subscriber type is still in use",
+				"code":  "type_in_use",
+			})
+		}
+
+		res := db.Where("name = ?", name).Delete(&models.SubscriberTypeDefinition{})
+		if res.Error != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not delete subscriber type"})
+		}
+		if res.RowsAffected == 0 {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Subscriber type not found"})
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	}
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"fiber-gorm-api/internal/campaigns"
+	"fiber-gorm-api/internal/models"
+	"fiber-gorm-api/internal/tracking"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// trackedEvent reports whether an open or click with claims should be recorded at now: its
+// token is still within TRACKING_TOKEN_TTL_DAYS and it doesn't come from a bot
+func trackedEvent(c *fiber.Ctx, claims tracking.Claims, now time.Time) bool {
+	return claims.Fresh(now) && !tracking.IsBot(c.Get(fiber.HeaderUserAgent))
+}
+
+// TrackOpen godoc
+// @Summary      Campaign open pixel
+// @Description  The 1x1 GIF at the end of campaign emails, recording an open of the recipient its token names (see /admin/campaigns/{id}/stats). Bots, mail security scanners and tokens older than TRACKING_TOKEN_TTL_DAYS aren't counted. The pixel is served whatever the token, never cached.
+// @Tags         tracking
+// @Produce      image/gif
+// @Param        token  path  string  true  "Token of the recipient"
+// @Success      200  {file}  file
+// @Router       /t/o/{token} [get]
+func TrackOpen(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		now := time.Now()
+		if claims, ok := tracking.Parse(tracking.KindOpen, c.Params("token")); ok && trackedEvent(c, claims, now) {
+			err := campaigns.RecordOpen(db.WithContext(c.UserContext()), claims.SendID, c.Get(fiber.HeaderUserAgent), now)
+			if err != nil {
+				log.Printf("[WARN] Tracking: recording the open of %d failed: %v", claims.SendID, err)
+			}
+		}
+		c.Set(fiber.HeaderCacheControl, "no-store, max-age=0")
+		c.Set(fiber.HeaderContentType, "image/gif")
+		return c.Send(tracking.Pixel)
+	}
+}
+
+// TrackClick godoc
+// @Summary      Campaign link
+// @Description  The links of campaign emails point here, recording a click (and an open) of the recipient its token names, then redirecting to the link. Bots, mail security scanners and tokens older than TRACKING_TOKEN_TTL_DAYS aren't counted, but still redirected.
+// @Tags         tracking
+// @Param        token  path  string  true  "Token of the recipient and the link"
+// @Success      302
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /t/c/{token} [get]
+func TrackClick(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, ok := tracking.Parse(tracking.KindClick, c.Params("token"))
+		if !ok || claims.LinkID == 0 {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Link not found"})
+		}
+		conn := db.WithContext(c.UserContext())
+		var link models.CampaignLink
+		if err := conn.First(&link, claims.LinkID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Link not found"})
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve link"})
+		}
+
+		now := time.Now()
+		if trackedEvent(c, claims, now) {
+			if err := campaigns.RecordClick(conn, claims.SendID, link.ID, c.Get(fiber.HeaderUserAgent), now); err != nil {
+				log.Printf("[WARN] Tracking: recording the click of %d on link %d failed: %v", claims.SendID, link.ID, err)
+			}
+		}
+		c.Set(fiber.HeaderCacheControl, "no-store, max-age=0")
+		return c.Redirect(link.URL, fiber.StatusFound)
+	}
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// Trusted devices skip the code: /signin/verify with trust_device sets a long-lived device
+// token, as a cookie for browsers and in the response for apps (sent back in X-Device-Token),
+// and /signin/request from that device for the same email or phone answers with a session.
+
+const (
+	trustedDeviceCookie     = "mylo_trusted_device"
+	trustedDeviceHeader     = "X-Device-Token"
+	defaultTrustedDeviceTTL = 30 * 24 * time.Hour
+)
+
+// trustedDeviceTTL is how long a device stays trusted, from TRUSTED_DEVICE_TTL_DAYS
+func trustedDeviceTTL() time.Duration {
+	if n, err := strconv.Atoi(os.Getenv("TRUSTED_DEVICE_TTL_DAYS")); err == nil && n > 0 {
+		return time.Duration(n) * 24 * time.Hour
+	}
+	return defaultTrustedDeviceTTL
+}
+
+// trustDevice remembers the calling device for email and returns its device token, also set
+// as a cookie
+func trustDevice(c *fiber.Ctx, db *gorm.DB, email string) (string, error) {
+	token := randomToken(32)
+	now := time.Now()
+	device := models.TrustedDevice{
+		Email:      email,
+		TokenHash:  hashToken(token),
+		IP:         c.IP(),
+		UserAgent:  c.Get(fiber.HeaderUserAgent),
+		LastUsedAt: now,
+		ExpiresAt:  now.Add(trustedDeviceTTL()),
+	}
+	if err := db.Create(&device).Error; err != nil {
+		return "", err
+	}
+
+	c.Cookie(&fiber.Cookie{
+		Name:     trustedDeviceCookie,
+		Value:    token,
+		Path:     "/signin",
+		Expires:  device.ExpiresAt,
+		Secure:   true,
+		HTTPOnly: true,
+		SameSite: fiber.CookieSameSiteStrictMode,
+	})
+	return token, nil
+}
+
+// trustedDevice reports whether the calling device was trusted by email and hasn't expired,
+// recording its use
+func trustedDevice(c *fiber.Ctx, db *gorm.DB, email string) bool {
+	token := c.Cookies(trustedDeviceCookie)
+	if token == "" {
+		token = c.Get(trustedDeviceHeader)
+	}
+	if token == "" {
+		return false
+	}
+
+	var device models.TrustedDevice
+	err := db.Where("token_hash = ? AND email = ? AND expires_at > ?", hashToken(token), email, time.Now()).
+		First(&device).Error
+	if err != nil {
+		return false
+	}
+	db.Model(&device).Updates(map[string]interface{}{
+		"last_used_at": time.Now(),
+		"ip":           c.IP(),
+		"user_agent":   c.Get(fiber.HeaderUserAgent),
+	})
+	return true
+}
+
+// ListTrustedDevices godoc
+// @Summary      List trusted devices
+// @Description  Lists the devices the authenticated user chose to remember at sign-in, which skip the code until they expire, most recently used first
+// @Tags         sessions
+// @Produce      json
+// @Success      200  {array}   dto.TrustedDeviceResponse
+// @Failure      401  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/trusted-devices [get]
+func ListTrustedDevices(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		current := middleware.CurrentSession(c)
+		if current == nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Not signed in"})
+		}
+
+		var devices []models.TrustedDevice
+		err := db.WithContext(c.UserContext()).
+			Where("email = ? AND expires_at > ?", current.Email, time.Now()).
+			Order("last_used_at DESC").
+			Find(&devices).Error
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not load trusted devices"})
+		}
+		return c.JSON(dto.NewTrustedDeviceResponses(devices))
+	}
+}
+
+// RevokeTrustedDevice godoc
+// @Summary      Revoke a trusted device
+// @Description  Forgets one trusted device of the authenticated user: it needs a code again. Sessions it already has are revoked separately (DELETE /admin/sessions/{id}).
+// @Tags         sessions
+// @Param        id   path      int  true  "Trusted device ID"
+// @Success      204  {string}  string
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      401  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/trusted-devices/{id} [delete]
+func RevokeTrustedDevice(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		current := middleware.CurrentSession(c)
+		if current == nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Not signed in"})
+		}
+		id, err := strconv.Atoi(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid trusted device ID"})
+		}
+
+		res := db.WithContext(c.UserContext()).
+			Where("id = ? AND email = ?", id, current.Email).
+			Delete(&models.TrustedDevice{})
+		if res.Error != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not revoke trusted device"})
+		}
+		if res.RowsAffected == 0 {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Trusted device not found"})
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	}
+}
+
+// RevokeTrustedDevices godoc
+// @Summary      Revoke every trusted device
+// @Description  Forgets every trusted device of the authenticated user, e.g. after losing one
+// @Tags         sessions
+// @Success      204  {string}  string
+// @Failure      401  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/trusted-devices [delete]
+func RevokeTrustedDevices(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		current := middleware.CurrentSession(c)
+		if current == nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Not signed in"})
+		}
+		err := db.WithContext(c.UserContext()).Where("email = ?", current.Email).Delete(&models.TrustedDevice{}).Error
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not revoke trusted devices"})
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	}
+}
@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"bytes"
+
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/passkey"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// WebAuthnRegisterBegin godoc
+// @Summary      Begin passkey registration
+// @Description  Starts registering a passkey for the signed-in user. Returns the PublicKeyCredentialCreationOptions to pass to navigator.credentials.create().
+// @Tags         signin
+// @Produce      json
+// @Success      200  {object}  object  "protocol.CredentialCreation"
+// @Failure      401  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /signin/webauthn/register/begin [post]
+func WebAuthnRegisterBegin(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		current := middleware.CurrentSession(c)
+		if current == nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Not signed in"})
+		}
+
+		user, err := passkey.LoadUser(db, current.Email)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not load passkeys"})
+		}
+
+		// don't let the same authenticator register twice
+		exclusions := make([]protocol.CredentialDescriptor, len(user.Credentials))
+		for i, cred := range user.Credentials {
+			exclusions[i] = cred.Descriptor()
+		}
+
+		creation, data, err := passkey.WebAuthn.BeginRegistration(user, webauthn.WithExclusions(exclusions))
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not start passkey registration"})
+		}
+		if err := passkey.SaveCeremony(c.UserContext(), "register", current.Email, data); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Unable to store registration in redis"})
+		}
+		return c.JSON(creation)
+	}
+}
+
+// WebAuthnRegisterFinish godoc
+// @Summary      Finish passkey registration
+// @Description  Verifies the attestation returned by navigator.credentials.create() and stores the new passkey.
+// @Tags         signin
+// @Accept       json
+// @Produce      json
+// @Param        body  body      object  true  "PublicKeyCredential from the browser"
+// @Success      201   {object}  dto.MessageResponse
+// @Failure      400   {object}  dto.ErrorResponse
+// @Failure      401   {object}  dto.ErrorResponse
+// @Failure      500   {object}  dto.ErrorResponse
+// @Router       /signin/webauthn/register/finish [post]
+func WebAuthnRegisterFinish(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		current := middleware.CurrentSession(c)
+		if current == nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Not signed in"})
+		}
+
+		data, err := passkey.TakeCeremony(c.UserContext(), "register", current.Email)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "No registration in progress or it expired"})
+		}
+
+		parsed, err := protocol.ParseCredentialCreationResponseBody(bytes.NewReader(c.Body()))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid credential"})
+		}
+
+		user, err := passkey.LoadUser(db, current.Email)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not load passkeys"})
+		}
+
+		cred, err := passkey.WebAuthn.CreateCredential(user, *data, parsed)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Passkey verification failed"})
+		}
+		if err := passkey.SaveCredential(db, current.Email, cred); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not store passkey"})
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(dto.MessageResponse{Message: "Passkey registered."})
+	}
+}
+
+// WebAuthnLoginBegin godoc
+// @Summary      Begin passkey sign in
+// @Description  Takes an email with registered passkeys and returns the PublicKeyCredentialRequestOptions to pass to navigator.credentials.get().
+// @Tags         signin
+// @Accept       json
+// @Produce      json
+// @Param        body  body      dto.SignInRequest  true  "Email to sign in as"
+// @Success      200   {object}  object  "protocol.CredentialAssertion"
+// @Failure      400   {object}  dto.ErrorResponse
+// @Failure      500   {object}  dto.ErrorResponse
+// @Router       /signin/webauthn/login/begin [post]
+func WebAuthnLoginBegin(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		var req dto.SignInRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+		}
+		if req.Email == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing email"})
+		}
+
+		user, err := passkey.LoadUser(db, req.Email)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not load passkeys"})
+		}
+		if len(user.Credentials) == 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "No passkey registered for this email"})
+		}
+
+		assertion, data, err := passkey.WebAuthn.BeginLogin(user)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not start passkey sign in"})
+		}
+		if err := passkey.SaveCeremony(c.UserContext(), "login", req.Email, data); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Unable to store sign in in redis"})
+		}
+		return c.JSON(assertion)
+	}
+}
+
+// WebAuthnLoginFinish godoc
+// @Summary      Finish passkey sign in
+// @Description  Verifies the assertion returned by navigator.credentials.get(). If valid, generate JWT & store session in redis
+// @Tags         signin
+// @Accept       json
+// @Produce      json
+// @Param        email  query     string  true  "Email passed to /signin/webauthn/login/begin"
+// @Param        body   body      object  true  "PublicKeyCredential from the browser"
+// @Success      200    {object}  dto.TokenResponse  "JWT returned"
+// @Failure      400    {object}  dto.ErrorResponse
+// @Failure      401    {object}  dto.ErrorResponse
+// @Failure      500    {object}  dto.ErrorResponse
+// @Router       /signin/webauthn/login/finish [post]
+func WebAuthnLoginFinish(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		db := db.WithContext(c.UserContext())
+		email := c.Query("email")
+		if email == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing email"})
+		}
+
+		data, err := passkey.TakeCeremony(c.UserContext(), "login", email)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "No sign in in progress or it expired"})
+		}
+
+		parsed, err := protocol.ParseCredentialRequestResponseBody(bytes.NewReader(c.Body()))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid credential"})
+		}
+
+		user, err := passkey.LoadUser(db, email)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not load passkeys"})
+		}
+
+		cred, err := passkey.WebAuthn.ValidateLogin(user, *data, parsed)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Passkey verification failed"})
+		}
+		if cred.Authenticator.CloneWarning {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Passkey may have been cloned"})
+		}
+
+		// persist the new sign counter
+		if err := passkey.SaveCredential(db, email, cred); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not update passkey"})
+		}
+
+		return issueSessionToken(c, db, email)
+	}
+}
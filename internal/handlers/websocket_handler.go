@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/realtime"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// wsPingInterval is how often idle connections are pinged; clients that miss two pongs are dropped
+	wsPingInterval = 30 * time.Second
+	wsWriteTimeout = 10 * time.Second
+	// wsOrgLocalKey carries the caller's organization from the upgrade request into the connection
+	wsOrgLocalKey = "ws_org_id"
+)
+
+// AdminWebSocket godoc
+// @Summary      Real-time admin notifications (WebSocket)
+// @Description  Upgrades to a WebSocket pushing the events of the caller's organization as JSON text messages with id, topic, subscriber_id, import_id or campaign_id and run, status, the total, processed and failed rows of imports or the emails a run queued (processed) and occurred_at (e.g. subscriber.created for new signups, import.progress and import.finished for imports, campaign.progress for campaign sends).
+// @Description  Browsers authenticate with new WebSocket(url, ["bearer", jwt]); other clients send the usual Authorization or X-API-Key header. Messages sent by the client are ignored.
+// @Tags         events
+// @Success      101  {string}  string  "Switching protocols"
+// @Failure      401  {object}  dto.ErrorResponse
+// @Failure      403  {object}  dto.ErrorResponse
+// @Failure      426  {object}  dto.ErrorResponse  "code: upgrade_required"
+// @Router       /admin/ws [get]
+func AdminWebSocket() fiber.Handler {
+	upgrade := websocket.New(serveAdminWebSocket, websocket.Config{
+		Subprotocols: []string{middleware.WebSocketBearerProtocol},
+	})
+
+	return func(c *fiber.Ctx) error {
+		if !websocket.IsWebSocketUpgrade(c) {
+			return c.Status(fiber.StatusUpgradeRequired).JSON(fiber.Map{
+				"error": "Expected a WebSocket upgrade",
+				"code":  "upgrade_required",
+			})
+		}
+		c.Locals(wsOrgLocalKey, middleware.CurrentOrgID(c))
+		return upgrade(c)
+	}
+}
+
+// serveAdminWebSocket forwards the organization's events until the client goes away
+func serveAdminWebSocket(conn *websocket.Conn) {
+	orgID, _ := conn.Locals(wsOrgLocalKey).(uint)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pubsub := realtime.Subscribe(ctx, orgID)
+	defer pubsub.Close()
+	messages := pubsub.Channel()
+
+	// The read loop only handles pongs and notices the connection closing
+	conn.SetReadDeadline(time.Now().Add(2 * wsPingInterval))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(2 * wsPingInterval))
+	})
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ping := time.NewTicker(wsPingInterval)
+	defer ping.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+			conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+			if err := conn.WriteMessage(websocket.TextMessage, []byte(msg.Payload)); err != nil {
+				return
+			}
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteTimeout)); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package i18n
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"strings"
+	texttemplate "text/template"
+)
+
+// Email templates live in templates/<locale>/<name>.txt, defining "subject" and "text", and
+// templates/<locale>/<name>.html with the HTML body. A locale without a template uses
+// DefaultLocale's.
+
+//go:embed templates
+var templateFS embed.FS
+
+// Email is a transactional email rendered in one locale
+type Email struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+type emailTemplate struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+// emailTemplates maps "<locale>/<name>" to its parsed template
+var emailTemplates = loadEmailTemplates()
+
+func loadEmailTemplates() map[string]emailTemplate {
+	out := map[string]emailTemplate{}
+	paths, err := fs.Glob(templateFS, "templates/*/*.txt")
+	if err != nil {
+		panic("i18n: " + err.Error())
+	}
+	for _, path := range paths {
+		key := strings.TrimSuffix(strings.TrimPrefix(path, "templates/"), ".txt")
+		out[key] = emailTemplate{
+			text: texttemplate.Must(texttemplate.ParseFS(templateFS, path)),
+			html: htmltemplate.Must(htmltemplate.ParseFS(templateFS, "templates/"+key+".html")),
+		}
+	}
+	return out
+}
+
+// RenderEmail renders the email name (e.g. "signin_code") in locale with data
+func RenderEmail(locale, name string, data interface{}) (Email, error) {
+	tmpl, ok := emailTemplates[locale+"/"+name]
+	if !ok {
+		tmpl, ok = emailTemplates[DefaultLocale+"/"+name]
+	}
+	if !ok {
+		return Email{}, fmt.Errorf("i18n: no email template %q", name)
+	}
+
+	var subject, text, html bytes.Buffer
+	if err := tmpl.text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return Email{}, err
+	}
+	if err := tmpl.text.ExecuteTemplate(&text, "text", data); err != nil {
+		return Email{}, err
+	}
+	if err := tmpl.html.Execute(&html, data); err != nil {
+		return Email{}, err
+	}
+	return Email{
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    strings.TrimSpace(text.String()),
+		HTML:    strings.TrimSpace(html.String()),
+	}, nil
+}
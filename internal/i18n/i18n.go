@@ -0,0 +1,112 @@
+// Package i18n translates what the API tells people: error messages, from a bundle per
+// locale keyed by the English message, and transactional emails, from templates per locale
+// (see RenderEmail). Anything missing in a locale falls back to English.
+package i18n
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultLocale is the language of the messages in the code, and the fallback of every other
+const DefaultLocale = "en"
+
+// Locales lists every supported locale, DefaultLocale first
+var Locales = []string{DefaultLocale, "fr", "es"}
+
+//go:embed locales/*.json
+var bundleFS embed.FS
+
+// bundles maps a locale to its translations of English messages
+var bundles = loadBundles()
+
+func loadBundles() map[string]map[string]string {
+	out := map[string]map[string]string{}
+	for _, locale := range Locales[1:] {
+		raw, err := bundleFS.ReadFile("locales/" + locale + ".json")
+		if err != nil {
+			panic("i18n: missing bundle " + locale + ".json")
+		}
+		bundle := map[string]string{}
+		if err := json.Unmarshal(raw, &bundle); err != nil {
+			panic("i18n: invalid bundle " + locale + ".json: " + err.Error())
+		}
+		out[locale] = bundle
+	}
+	return out
+}
+
+// Supported reports whether locale is one of Locales
+func Supported(locale string) bool {
+	return slices.Contains(Locales, locale)
+}
+
+// Normalize maps a language tag such as "fr-CA" onto the supported locale of its language,
+// or "" when there is none
+func Normalize(tag string) string {
+	lang, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+	lang, _, _ = strings.Cut(lang, "_")
+	if Supported(lang) {
+		return lang
+	}
+	return ""
+}
+
+// Match picks the supported locale an Accept-Language header prefers most, DefaultLocale when
+// it names none
+func Match(acceptLanguage string) string {
+	type weighted struct {
+		locale string
+		q      float64
+	}
+	var candidates []weighted
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if locale := Normalize(tag); locale != "" && q > 0 {
+			candidates = append(candidates, weighted{locale, q})
+		}
+	}
+	// equal weights keep the order of the header
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+	if len(candidates) == 0 {
+		return DefaultLocale
+	}
+	return candidates[0].locale
+}
+
+// T translates an English message into locale, returning it unchanged when the locale's
+// bundle doesn't have it
+func T(locale, message string) string {
+	if translated, ok := bundles[locale][message]; ok {
+		return translated
+	}
+	return message
+}
+
+type localeContextKey struct{}
+
+// WithLocale attaches the locale of the caller to ctx, for the emails sent on its behalf
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeContextKey{}, locale)
+}
+
+// FromContext returns the locale WithLocale attached to ctx, DefaultLocale if none
+func FromContext(ctx context.Context) string {
+	if locale, ok := ctx.Value(localeContextKey{}).(string); ok && locale != "" {
+		return locale
+	}
+	return DefaultLocale
+}
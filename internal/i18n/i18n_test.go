@@ -0,0 +1,71 @@
+package i18n
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	cases := map[string]string{
+		"":                        DefaultLocale,
+		"fr":                      "fr",
+		"es-MX,es;q=0.9":          "es",
+		"de-DE,de;q=0.9,fr;q=0.8": "fr",
+		"en;q=0.5,fr;q=0.7":       "fr",
+		"fr;q=0,es":               "es",
+		"ja":                      DefaultLocale,
+		"fr;q=bogus,es;q=0.2":     "es",
+		"EN-gb":                   "en",
+	}
+	for header, want := range cases {
+		if got := Match(header); got != want {
+			t.Errorf("Match(%q): expected %s, got %s", header, want, got)
+		}
+	}
+}
+
+func TestT(t *testing.T) {
+	if got := T("fr", "Missing email"); got != "Adresse e-mail manquante" {
+		t.Errorf("Expected the French message, got %q", got)
+	}
+	if got := T("es", "Not translated anywhere"); got != "Not translated anywhere" {
+		t.Errorf("Expected an unknown message unchanged, got %q", got)
+	}
+	if got := T("xx", "Missing email"); got != "Missing email" {
+		t.Errorf("Expected an unknown locale to keep English, got %q", got)
+	}
+	if got := FromContext(WithLocale(context.Background(), "es")); got != "es" {
+		t.Errorf("Expected the context locale, got %q", got)
+	}
+	if got := FromContext(context.Background()); got != DefaultLocale {
+		t.Errorf("Expected the default locale, got %q", got)
+	}
+}
+
+func TestRenderEmail(t *testing.T) {
+	for _, locale := range Locales {
+		email, err := RenderEmail(locale, "confirmation", map[string]string{"Link": "https://example.com/confirm/abc?x=1&y=2"})
+		if err != nil {
+			t.Fatalf("%s: render failed: %v", locale, err)
+		}
+		if email.Subject == "" || !strings.Contains(email.Text, "https://example.com/confirm/abc?x=1&y=2") {
+			t.Errorf("%s: expected a subject and the link in the text, got %+v", locale, email)
+		}
+		if !strings.Contains(email.HTML, "x=1&amp;y=2") {
+			t.Errorf("%s: expected the link escaped in the HTML, got %s", locale, email.HTML)
+		}
+	}
+
+	fr, _ := RenderEmail("fr", "signin_code", map[string]string{"Code": "123456"})
+	if fr.Subject != "Votre code de connexion" || !strings.Contains(fr.Text, "123456") {
+		t.Errorf("Expected the French sign-in code email, got %+v", fr)
+	}
+	fallback, err := RenderEmail("xx", "signin_code", map[string]string{"Code": "123456"})
+	if err != nil || fallback.Subject != "Your Sign-In Code" {
+		t.Errorf("Expected an unknown locale to fall back to English, got %+v, %v", fallback, err)
+	}
+	if _, err := RenderEmail("en", "no_such_email", nil); err == nil {
+		t.Errorf("Expected an error for an unknown template")
+	}
+}
@@ -0,0 +1,463 @@
+// Package imports creates and updates subscribers from uploaded files in the background:
+// POST /admin/imports stores the file and queues an ImportJob, the scheduler's import worker
+// claims it and applies the rows in batches, saving and publishing its progress after each, and
+// the rows that failed end up in an error report the admin downloads through a signed link.
+package imports
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"fiber-gorm-api/internal/db"
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/models"
+	"fiber-gorm-api/internal/realtime"
+	"fiber-gorm-api/internal/repository"
+	"fiber-gorm-api/internal/service"
+	"fiber-gorm-api/internal/storage"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultTTL            = 72 * time.Hour
+	defaultBatchSize      = 500
+	defaultMaxUploadBytes = 20 << 20
+)
+
+// Formats are the file formats an import may be uploaded in
+var Formats = []string{models.ImportFormatCSV, models.ImportFormatJSON}
+
+// contentTypes are the MIME types of the formats
+var contentTypes = map[string]string{
+	models.ImportFormatCSV:  "text/csv; charset=utf-8",
+	models.ImportFormatJSON: "application/json",
+}
+
+// reportHeader are the columns of error reports: the row of the file (1 for the first
+// subscriber), its email and why it failed
+var reportHeader = []string{"row", "email", "error"}
+
+// TTL is how long the error report of a finished import can be downloaded before the job is
+// purged (IMPORT_TTL_HOURS)
+func TTL() time.Duration {
+	if n, err := strconv.Atoi(os.Getenv("IMPORT_TTL_HOURS")); err == nil && n > 0 {
+		return time.Duration(n) * time.Hour
+	}
+	return defaultTTL
+}
+
+// BatchSize is how many rows are applied between progress updates (IMPORT_BATCH_SIZE)
+func BatchSize() int {
+	if n, err := strconv.Atoi(os.Getenv("IMPORT_BATCH_SIZE")); err == nil && n > 0 {
+		return n
+	}
+	return defaultBatchSize
+}
+
+// MaxUploadBytes is the largest file that can be uploaded (IMPORT_MAX_UPLOAD_MB)
+func MaxUploadBytes() int {
+	if n, err := strconv.Atoi(os.Getenv("IMPORT_MAX_UPLOAD_MB")); err == nil && n > 0 {
+		return n << 20
+	}
+	return defaultMaxUploadBytes
+}
+
+// FormatOf guesses the format of an uploaded file from its extension, empty if unknown
+func FormatOf(fileName string) string {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv":
+		return models.ImportFormatCSV
+	case ".json":
+		return models.ImportFormatJSON
+	}
+	return ""
+}
+
+// ReportFileName is the name the error report of job is downloaded as
+func ReportFileName(job *models.ImportJob) string {
+	return "import-" + strconv.FormatUint(uint64(job.ID), 10) + "-errors.csv"
+}
+
+// Enqueue stores the upload r of size bytes and saves job pending with it, so the worker
+// never sees a job without its file
+func Enqueue(ctx context.Context, conn *gorm.DB, job *models.ImportJob, r io.Reader, size int64) error {
+	job.Status = models.ImportPending
+	return db.Tx(ctx, conn, func(tx *gorm.DB) error {
+		if err := tx.Create(job).Error; err != nil {
+			return err
+		}
+		job.File = fmt.Sprintf("%s%d.%s", storage.PrefixImports, job.ID, job.Format)
+		if err := storage.Default.Put(ctx, job.File, r, size, contentTypes[job.Format]); err != nil {
+			return err
+		}
+		if err := tx.Model(job).Update("file", job.File).Error; err != nil {
+			storage.Default.Delete(ctx, job.File)
+			return err
+		}
+		return nil
+	})
+}
+
+// ProcessPending runs the pending jobs, oldest first, until none is left, and returns how many
+// it ran. Several workers may call it at once: each job is claimed by a single one.
+func ProcessPending(conn *gorm.DB) (int, error) {
+	ran := 0
+	for {
+		job, err := claim(conn)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ran, nil
+		}
+		if err != nil {
+			return ran, err
+		}
+		ran++
+		if err := Run(conn, job); err != nil {
+			log.Printf("[WARN] Imports: job %d failed: %v", job.ID, err)
+		}
+	}
+}
+
+// claim marks the oldest pending job running and returns it
+func claim(conn *gorm.DB) (*models.ImportJob, error) {
+	for {
+		var job models.ImportJob
+		if err := conn.Where("status = ?", models.ImportPending).Order("id").First(&job).Error; err != nil {
+			return nil, err
+		}
+		ok, err := claimJob(conn, &job)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return &job, nil
+		}
+	}
+}
+
+// claimJob marks job running unless it isn't pending anymore. The status check in the UPDATE
+// makes a job claimed by another worker in the meantime look taken rather than run twice.
+func claimJob(conn *gorm.DB, job *models.ImportJob) (bool, error) {
+	now := time.Now()
+	res := conn.Model(&models.ImportJob{}).
+		Where("id = ? AND status = ?", job.ID, models.ImportPending).
+		Updates(map[string]interface{}{"status": models.ImportRunning, "started_at": now})
+	if res.Error != nil || res.RowsAffected == 0 {
+		return false, res.Error
+	}
+	job.Status, job.StartedAt = models.ImportRunning, &now
+	return true, nil
+}
+
+// RunJob runs the pending job id right away rather than waiting for the worker, for myloctl.
+// It returns gorm.ErrRecordNotFound when no pending job has the id.
+func RunJob(conn *gorm.DB, id uint) (*models.ImportJob, error) {
+	var job models.ImportJob
+	if err := conn.First(&job, id).Error; err != nil {
+		return nil, err
+	}
+	ok, err := claimJob(conn, &job)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	err = Run(conn, &job)
+	if reloadErr := conn.First(&job, id).Error; reloadErr != nil && err == nil {
+		err = reloadErr
+	}
+	return &job, err
+}
+
+// Run applies the rows of a claimed job and saves the job done, or failed with the error. The
+// upload is deleted either way: only the counts and the error report are kept.
+func Run(conn *gorm.DB, job *models.ImportJob) error {
+	err := process(conn, job)
+	ctx := context.Background()
+	if job.File != "" {
+		if delErr := storage.Default.Delete(ctx, job.File); delErr != nil {
+			log.Printf("[WARN] Imports: deleting the upload of job %d failed: %v", job.ID, delErr)
+		}
+	}
+
+	now := time.Now()
+	updates := progress(job)
+	updates["file"] = ""
+	updates["error_report"] = job.ErrorReport
+	updates["finished_at"] = now
+	updates["expires_at"] = now.Add(TTL())
+	status := models.ImportDone
+	if err != nil {
+		status = models.ImportFailed
+		updates["error"] = err.Error()
+	}
+	updates["status"] = status
+	if saveErr := conn.Model(job).Updates(updates).Error; saveErr != nil {
+		if job.ErrorReport != "" {
+			storage.Default.Delete(ctx, job.ErrorReport)
+		}
+		return saveErr
+	}
+	publish(job, realtime.TopicImportFinished, status)
+	return err
+}
+
+// publish tells the live clients of the organization of job how far it got. Clients that miss
+// it still have GET /admin/imports/:id, so a failure is only logged.
+func publish(job *models.ImportJob, topic, status string) {
+	err := realtime.Publish(context.Background(), job.OrgID, realtime.Event{
+		Topic:      topic,
+		ImportID:   job.ID,
+		Status:     status,
+		Total:      job.TotalRows,
+		Processed:  job.ProcessedRows,
+		Failed:     job.FailedRows,
+		OccurredAt: time.Now(),
+	})
+	if err != nil {
+		log.Printf("[WARN] Imports: publishing the progress of job %d failed: %v", job.ID, err)
+	}
+}
+
+// progress are the counters of job, saved after each batch
+func progress(job *models.ImportJob) map[string]interface{} {
+	return map[string]interface{}{
+		"total_rows":     job.TotalRows,
+		"processed_rows": job.ProcessedRows,
+		"created_rows":   job.CreatedRows,
+		"updated_rows":   job.UpdatedRows,
+		"failed_rows":    job.FailedRows,
+	}
+}
+
+// process applies the rows of the upload of job, counting them in job, and stores the error
+// report when some failed. The upload is copied to a temporary file first: it's read once to
+// count the rows, so progress has a total, and once to apply them.
+func process(conn *gorm.DB, job *models.ImportJob) error {
+	ctx := context.Background()
+	upload, err := storage.Default.Open(ctx, job.File)
+	if err != nil {
+		return fmt.Errorf("opening the upload: %w", err)
+	}
+	f, err := os.CreateTemp("", fmt.Sprintf("import-%d-*", job.ID))
+	if err != nil {
+		upload.Close()
+		return err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	_, err = io.Copy(f, upload)
+	upload.Close()
+	if err != nil {
+		return fmt.Errorf("reading the upload: %w", err)
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	rows, err := newReader(job.Format, bufio.NewReader(f))
+	if err != nil {
+		return err
+	}
+	for {
+		if _, err := rows.next(); err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		job.TotalRows++
+	}
+	if err := conn.Model(job).Update("total_rows", job.TotalRows).Error; err != nil {
+		return err
+	}
+	publish(job, realtime.TopicImportProgress, models.ImportRunning)
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if rows, err = newReader(job.Format, bufio.NewReader(f)); err != nil {
+		return err
+	}
+	report, err := newReport(job)
+	if err != nil {
+		return err
+	}
+	defer report.discard()
+
+	repo := repository.NewSubscriberRepository(conn)
+	svc := service.NewSubscriberService(repo)
+	// the revisions of the subscribers are authored by whoever uploaded the file
+	ctx = repository.WithAuthor(ctx, job.RequestedBy)
+	batch := BatchSize()
+	for {
+		row, err := rows.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		created, err := apply(ctx, repo, svc, job.OrgID, row)
+		job.ProcessedRows++
+		switch {
+		case err != nil:
+			job.FailedRows++
+			if err := report.add(job.ProcessedRows, row.Email, err); err != nil {
+				return err
+			}
+		case created:
+			job.CreatedRows++
+		default:
+			job.UpdatedRows++
+		}
+		if job.ProcessedRows%batch == 0 {
+			if err := conn.Model(job).Updates(progress(job)).Error; err != nil {
+				return err
+			}
+			publish(job, realtime.TopicImportProgress, models.ImportRunning)
+		}
+	}
+
+	if job.FailedRows > 0 {
+		key, err := report.store(ctx)
+		if err != nil {
+			return fmt.Errorf("storing the error report: %w", err)
+		}
+		job.ErrorReport = key
+	}
+	return nil
+}
+
+// apply creates the subscriber of row, or updates the subscriber of the organization with its
+// email, and reports whether it created one. An update keeps the name when the row has none,
+// and the subscriber_types, metadata and locale the row doesn't set.
+func apply(ctx context.Context, repo repository.SubscriberRepository, svc service.SubscriberService, orgID uint, row dto.CreateSubscriberRequest) (bool, error) {
+	sub := row.ToModel()
+	existing, err := repo.FindByEmail(ctx, orgID, sub.Email)
+	if errors.Is(err, repository.ErrNotFound) {
+		_, err := svc.Create(ctx, service.CreateSubscriberInput{
+			OrgID:           orgID,
+			Email:           sub.Email,
+			Name:            sub.Name,
+			SubscriberTypes: sub.SubscriberTypes,
+			Metadata:        sub.Metadata,
+			Locale:          sub.Locale,
+		})
+		return true, err
+	}
+	if err != nil {
+		return false, err
+	}
+	if strings.TrimSpace(sub.Name) == "" {
+		sub.Name = existing.Name
+	}
+	_, err = svc.Update(ctx, service.UpdateSubscriberInput{
+		OrgID:           orgID,
+		ID:              existing.ID,
+		Email:           existing.Email,
+		Name:            sub.Name,
+		SubscriberTypes: sub.SubscriberTypes,
+		Metadata:        sub.Metadata,
+		Locale:          sub.Locale,
+	})
+	return false, err
+}
+
+// report spools the rows that failed to a temporary CSV until it's stored
+type report struct {
+	job  *models.ImportJob
+	file *os.File
+	buf  *bufio.Writer
+	w    *csv.Writer
+}
+
+func newReport(job *models.ImportJob) (*report, error) {
+	f, err := os.CreateTemp("", fmt.Sprintf("import-%d-errors-*", job.ID))
+	if err != nil {
+		return nil, err
+	}
+	buf := bufio.NewWriter(f)
+	w := csv.NewWriter(buf)
+	if err := w.Write(reportHeader); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, err
+	}
+	return &report{job: job, file: f, buf: buf, w: w}, nil
+}
+
+// add records that the row-th row, of email, failed with err. Only validation errors are worth
+// showing the admin as is; the others are logged.
+func (r *report) add(row int, email string, err error) error {
+	message := err.Error()
+	var invalid *service.ValidationError
+	var types *service.InvalidTypesError
+	switch {
+	case errors.As(err, &types), errors.As(err, &invalid):
+	case errors.Is(err, repository.ErrVersionConflict):
+		message = "the subscriber changed during the import"
+	default:
+		log.Printf("[WARN] Imports: job %d row %d failed: %v", r.job.ID, row, err)
+		message = "could not save the subscriber"
+	}
+	return r.w.Write([]string{strconv.Itoa(row), email, message})
+}
+
+// store writes the report to storage and returns its key
+func (r *report) store(ctx context.Context) (string, error) {
+	r.w.Flush()
+	if err := r.w.Error(); err != nil {
+		return "", err
+	}
+	if err := r.buf.Flush(); err != nil {
+		return "", err
+	}
+	size, err := r.file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return "", err
+	}
+	if _, err := r.file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	key := fmt.Sprintf("%s%d-errors.csv", storage.PrefixImports, r.job.ID)
+	if err := storage.Default.Put(ctx, key, r.file, size, contentTypes[models.ImportFormatCSV]); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+func (r *report) discard() {
+	r.file.Close()
+	os.Remove(r.file.Name())
+}
+
+// Purge deletes the error reports and rows of imports that expired before now, returning how
+// many jobs it deleted. Uploads are deleted as soon as they're processed.
+func Purge(conn *gorm.DB, now time.Time) (int64, error) {
+	var expired []models.ImportJob
+	if err := conn.Where("expires_at < ?", now).Find(&expired).Error; err != nil || len(expired) == 0 {
+		return 0, err
+	}
+	ids := make([]uint, len(expired))
+	for i, job := range expired {
+		ids[i] = job.ID
+		if job.ErrorReport != "" {
+			if err := storage.Default.Delete(context.Background(), job.ErrorReport); err != nil {
+				return 0, err
+			}
+		}
+	}
+	res := conn.Where("id IN ?", ids).Delete(&models.ImportJob{})
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,226 @@
+package imports
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"fiber-gorm-api/internal/models"
+	"fiber-gorm-api/internal/realtime"
+	redisclient "fiber-gorm-api/internal/redis"
+	"fiber-gorm-api/internal/storage"
+	"fiber-gorm-api/internal/testutil/testenv"
+
+	"gorm.io/gorm"
+)
+
+// enqueue uploads body as a pending job of the default organization
+func enqueue(t *testing.T, conn *gorm.DB, format, body string) *models.ImportJob {
+	t.Helper()
+	job := &models.ImportJob{OrgID: models.DefaultOrgID, Format: format, RequestedBy: "importer@example.com"}
+	if err := Enqueue(context.Background(), conn, job, strings.NewReader(body), int64(len(body))); err != nil {
+		t.Fatal(err)
+	}
+	return job
+}
+
+func TestProcessPending(t *testing.T) {
+	t.Setenv("IMPORT_BATCH_SIZE", "2")
+	testenv.LocalStorage(t)
+	conn := testenv.SQLite(t)
+	existing := models.Subscriber{OrgID: models.DefaultOrgID, Email: "ada@example.com", Name: "Ada", Status: models.SubscriberStatusUnsubscribed,
+		SubscriberTypes: []models.SubscriberType{{Name: "donor", Frequency: "monthly", Channel: "email"}}}
+	if err := conn.Create(&existing).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// the columns of an export, in another order, plus one the import doesn't know
+	csvJob := enqueue(t, conn, models.ImportFormatCSV, "\ufeffName,email,id,subscriber_types,notes\n"+
+		"Ada Lovelace,ada@example.com,1,,vip\n"+
+		"Grace,grace@example.com,,shopper:daily:sms;donor,\n"+
+		",nameless@example.com,,,\n"+
+		"Bad,not-an-email,,,\n"+
+		"Typo,typo@example.com,,astronaut,\n")
+	jsonJob := enqueue(t, conn, models.ImportFormatJSON,
+		`[{"email": "linus@example.com", "name": "Linus", "locale": "fr"}, {"email": "ada@example.com", "subscriber_types": []}]`)
+	brokenJob := enqueue(t, conn, models.ImportFormatJSON, `{"email": "not@an-array.com"}`)
+
+	ran, err := ProcessPending(conn)
+	if err != nil || ran != 3 {
+		t.Fatalf("Expected 3 jobs run, got %d (%v)", ran, err)
+	}
+	if ran, _ := ProcessPending(conn); ran != 0 {
+		t.Errorf("Expected finished jobs to be left alone, ran %d", ran)
+	}
+
+	t.Run("CSV with failed rows", func(t *testing.T) {
+		conn.First(csvJob, csvJob.ID)
+		if csvJob.Status != models.ImportDone || csvJob.ExpiresAt == nil {
+			t.Fatalf("Expected a done job, got %+v", csvJob)
+		}
+		if csvJob.TotalRows != 5 || csvJob.ProcessedRows != 5 || csvJob.CreatedRows != 1 || csvJob.UpdatedRows != 1 || csvJob.FailedRows != 3 {
+			t.Errorf("Expected 5 rows: 1 created, 1 updated, 3 failed, got %+v", csvJob)
+		}
+		upload := fmt.Sprintf("%s%d.csv", storage.PrefixImports, csvJob.ID)
+		if _, err := storage.Default.Open(context.Background(), upload); csvJob.File != "" || !errors.Is(err, storage.ErrNotFound) {
+			t.Errorf("Expected the upload deleted, got %q (%v)", csvJob.File, err)
+		}
+
+		var ada models.Subscriber
+		conn.Preload("SubscriberTypes").Where("email = ?", "ada@example.com").First(&ada)
+		if ada.Name != "Ada Lovelace" || ada.Status != models.SubscriberStatusUnsubscribed {
+			t.Errorf("Expected Ada renamed and still unsubscribed, got %+v", ada)
+		}
+		var grace models.Subscriber
+		conn.Preload("SubscriberTypes", func(tx *gorm.DB) *gorm.DB { return tx.Order("name DESC") }).
+			Where("email = ?", "grace@example.com").First(&grace)
+		if grace.Status != models.SubscriberStatusActive || len(grace.SubscriberTypes) != 2 || grace.SubscriberTypes[0].Channel != "sms" {
+			t.Errorf("Expected Grace active with her 2 subscriber_types, got %+v", grace)
+		}
+
+		f, err := storage.Default.Open(context.Background(), csvJob.ErrorReport)
+		if err != nil {
+			t.Fatalf("Expected an error report, got %v", err)
+		}
+		defer f.Close()
+		records, err := csv.NewReader(f).ReadAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(records) != 4 || records[0][0] != "row" {
+			t.Fatalf("Expected a header and 3 failed rows, got %v", records)
+		}
+		if records[1][0] != "3" || records[1][1] != "nameless@example.com" || records[1][2] != "missing name" {
+			t.Errorf("Expected the row without a name, got %v", records[1])
+		}
+		if records[3][1] != "typo@example.com" || !strings.Contains(records[3][2], "astronaut") {
+			t.Errorf("Expected the unknown subscriber_type named, got %v", records[3])
+		}
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		conn.First(jsonJob, jsonJob.ID)
+		if jsonJob.Status != models.ImportDone || jsonJob.CreatedRows != 1 || jsonJob.UpdatedRows != 1 || jsonJob.ErrorReport != "" {
+			t.Fatalf("Expected a done job without errors, got %+v", jsonJob)
+		}
+		var linus models.Subscriber
+		conn.Where("email = ?", "linus@example.com").First(&linus)
+		if linus.Locale != "fr" {
+			t.Errorf("Expected Linus in French, got %+v", linus)
+		}
+		var types int64
+		conn.Model(&models.SubscriberType{}).Where("subscriber_id = ?", existing.ID).Count(&types)
+		if types != 0 {
+			t.Errorf("Expected an empty subscriber_types to clear Ada's, got %d", types)
+		}
+	})
+
+	t.Run("Malformed file fails", func(t *testing.T) {
+		conn.First(brokenJob, brokenJob.ID)
+		if brokenJob.Status != models.ImportFailed || !strings.Contains(brokenJob.Error, "array") {
+			t.Errorf("Expected a failed job with its error, got %+v", brokenJob)
+		}
+	})
+
+	t.Run("Error report link", func(t *testing.T) {
+		now := time.Now()
+		link, _, err := ErrorReportURL(context.Background(), csvJob, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		u, _ := url.Parse(link)
+		q := u.Query()
+		if !strings.HasSuffix(u.Path, "/errors") || !storage.VerifyLink(csvJob.ErrorReport, q.Get("expires"), q.Get("signature"), now) {
+			t.Errorf("Expected a signed link to the report, got %q", link)
+		}
+	})
+
+	t.Run("Purge", func(t *testing.T) {
+		if n, err := Purge(conn, time.Now()); err != nil || n != 0 {
+			t.Fatalf("Expected nothing expired yet, purged %d (%v)", n, err)
+		}
+		n, err := Purge(conn, time.Now().Add(TTL()+time.Minute))
+		if err != nil || n != 3 {
+			t.Fatalf("Expected the 3 jobs purged, got %d (%v)", n, err)
+		}
+		if _, err := storage.Default.Open(context.Background(), csvJob.ErrorReport); !errors.Is(err, storage.ErrNotFound) {
+			t.Errorf("Expected the error report deleted, got %v", err)
+		}
+	})
+}
+
+func TestProgressEvents(t *testing.T) {
+	t.Setenv("IMPORT_BATCH_SIZE", "2")
+	testenv.LocalStorage(t)
+	conn := testenv.SQLite(t)
+	redisclient.InitRedis("session") // in-process unless REDIS_HOST is set
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	pubsub := realtime.Subscribe(ctx, models.DefaultOrgID)
+	defer pubsub.Close()
+	// the subscription is only live once confirmed
+	if _, err := pubsub.Receive(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	job := enqueue(t, conn, models.ImportFormatCSV, "email,name\n"+
+		"ada@example.com,Ada\n"+
+		"not-an-email,Bad\n"+
+		"grace@example.com,Grace\n")
+	if ran, err := ProcessPending(conn); err != nil || ran != 1 {
+		t.Fatalf("Expected 1 job run, got %d (%v)", ran, err)
+	}
+
+	want := []realtime.Event{
+		{Topic: realtime.TopicImportProgress, ImportID: job.ID, Status: models.ImportRunning, Total: 3},
+		{Topic: realtime.TopicImportProgress, ImportID: job.ID, Status: models.ImportRunning, Total: 3, Processed: 2, Failed: 1},
+		{Topic: realtime.TopicImportFinished, ImportID: job.ID, Status: models.ImportDone, Total: 3, Processed: 3, Failed: 1},
+	}
+	messages := pubsub.Channel()
+	for i, w := range want {
+		select {
+		case msg := <-messages:
+			got, err := realtime.Decode(msg.Payload)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.OccurredAt.IsZero() {
+				t.Errorf("Expected event %d to say when it occurred, got %+v", i, got)
+			}
+			got.OccurredAt = time.Time{}
+			if got != w {
+				t.Errorf("Expected event %d to be %+v, got %+v", i, w, got)
+			}
+		case <-ctx.Done():
+			t.Fatalf("Expected event %d, got none", i)
+		}
+	}
+}
+
+func TestReaders(t *testing.T) {
+	for name, tc := range map[string]struct {
+		format, body, err string
+	}{
+		"CSV without an email column": {models.ImportFormatCSV, "name\nAda\n", "no email column"},
+		"Empty CSV":                   {models.ImportFormatCSV, "", "empty"},
+		"Unterminated quote":          {models.ImportFormatCSV, "email\n\"ada@example.com\n", "row 1"},
+		"JSON object":                 {models.ImportFormatJSON, `{}`, "array"},
+		"Unknown format":              {"xlsx", "", "unknown format"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			r, err := newReader(tc.format, strings.NewReader(tc.body))
+			for err == nil {
+				_, err = r.next()
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("Expected an error about %q, got %v", tc.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package imports
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"fiber-gorm-api/internal/models"
+	"fiber-gorm-api/internal/storage"
+)
+
+const defaultErrorsBaseURL = "http://localhost:3517/admin/imports/"
+
+// ErrorReportURL returns a signed link to the error report of the finished job (see
+// storage.DownloadURL), valid until the returned time. IMPORT_ERRORS_URL is the public base URL
+// of /admin/imports/ for links served by the API.
+func ErrorReportURL(ctx context.Context, job *models.ImportJob, now time.Time) (string, time.Time, error) {
+	base := os.Getenv("IMPORT_ERRORS_URL")
+	if base == "" {
+		base = defaultErrorsBaseURL
+	}
+	apiURL := fmt.Sprintf("%s%d/errors", base, job.ID)
+	return storage.DownloadURL(ctx, storage.Default, job.ErrorReport, ReportFileName(job), apiURL, job.ExpiresAt, now)
+}
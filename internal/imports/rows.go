@@ -0,0 +1,142 @@
+package imports
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/models"
+)
+
+// rowReader reads the subscribers of an upload one at a time, io.EOF after the last. Any
+// other error means the file itself is malformed and the import stops.
+type rowReader interface {
+	next() (dto.CreateSubscriberRequest, error)
+}
+
+func newReader(format string, r io.Reader) (rowReader, error) {
+	switch format {
+	case models.ImportFormatCSV:
+		return newCSVReader(r)
+	case models.ImportFormatJSON:
+		return newJSONReader(r)
+	}
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+// csvReader reads a CSV with a header row. email is required; name, locale and
+// subscriber_types (name:frequency:channel triplets separated by semicolons, as exported) are
+// optional and other columns, such as the rest of an export's, are ignored.
+type csvReader struct {
+	r       *csv.Reader
+	columns map[string]int
+	row     int
+}
+
+func newCSVReader(r io.Reader) (*csvReader, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, errors.New("the file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading the header: %w", err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		if i == 0 {
+			// spreadsheet apps save UTF-8 with a byte order mark
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["email"]; !ok {
+		return nil, errors.New("the header has no email column")
+	}
+	return &csvReader{r: cr, columns: columns}, nil
+}
+
+func (c *csvReader) next() (dto.CreateSubscriberRequest, error) {
+	record, err := c.r.Read()
+	if err == io.EOF {
+		return dto.CreateSubscriberRequest{}, err
+	}
+	c.row++
+	if err != nil {
+		return dto.CreateSubscriberRequest{}, fmt.Errorf("row %d: %w", c.row, err)
+	}
+	field := func(name string) (string, bool) {
+		i, ok := c.columns[name]
+		if !ok || i >= len(record) {
+			return "", false
+		}
+		return strings.TrimSpace(record[i]), true
+	}
+
+	var row dto.CreateSubscriberRequest
+	row.Email, _ = field("email")
+	row.Name, _ = field("name")
+	row.Locale, _ = field("locale")
+	// an empty cell keeps the subscriber_types of an existing subscriber
+	if types, ok := field("subscriber_types"); ok && types != "" {
+		row.SubscriberTypes = []dto.SubscriberTypeRequest{}
+		for _, triplet := range strings.Split(types, ";") {
+			parts := strings.Split(strings.TrimSpace(triplet), ":")
+			t := dto.SubscriberTypeRequest{Name: parts[0]}
+			if len(parts) > 1 {
+				t.Frequency = parts[1]
+			}
+			if len(parts) > 2 {
+				t.Channel = parts[2]
+			}
+			row.SubscriberTypes = append(row.SubscriberTypes, t)
+		}
+	}
+	return row, nil
+}
+
+// jsonReader reads an array of objects shaped like the body of POST /admin/subscribers, or
+// like the subscribers of a JSON export. A subscriber without subscriber_types or metadata keeps
+// those it has.
+type jsonReader struct {
+	dec *json.Decoder
+	row int
+}
+
+func newJSONReader(r io.Reader) (*jsonReader, error) {
+	dec := json.NewDecoder(r)
+	tok, err := dec.Token()
+	if err == io.EOF {
+		return nil, errors.New("the file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading the file: %w", err)
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		return nil, errors.New("expected an array of subscribers")
+	}
+	return &jsonReader{dec: dec}, nil
+}
+
+func (j *jsonReader) next() (dto.CreateSubscriberRequest, error) {
+	var row dto.CreateSubscriberRequest
+	if !j.dec.More() {
+		if _, err := j.dec.Token(); err != nil {
+			return row, fmt.Errorf("reading the end of the array: %w", err)
+		}
+		return row, io.EOF
+	}
+	j.row++
+	if err := j.dec.Decode(&row); err != nil {
+		return row, fmt.Errorf("row %d: %w", j.row, err)
+	}
+	row.Email = strings.TrimSpace(row.Email)
+	row.Name = strings.TrimSpace(row.Name)
+	return row, nil
+}
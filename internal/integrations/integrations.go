@@ -0,0 +1,79 @@
+// Package integrations keeps the subscribers of an organization in sync with its account at a
+// mailing list provider. An admin configures the API key of the account and which of its lists
+// get which subscribers (see PUT /admin/integrations/{provider}); the scheduler's sync worker
+// then pushes the new and changed active subscribers to each list and pulls the addresses that
+// unsubscribed there, unsubscribing them here too.
+package integrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"fiber-gorm-api/internal/models"
+)
+
+// Providers are the mailing list providers an organization can connect
+var Providers = []string{models.ProviderMailchimp}
+
+var (
+	// ErrInvalidCredentials is returned when the provider rejects the API key
+	ErrInvalidCredentials = errors.New("the provider rejected the API key")
+	// ErrUnknownProvider is returned for a provider not in Providers
+	ErrUnknownProvider = errors.New("unknown provider")
+)
+
+// Member is a subscriber pushed to a list
+type Member struct {
+	Email string
+	Name  string
+}
+
+// MemberError is a member the provider refused, and why
+type MemberError struct {
+	Email string
+	Error string
+}
+
+// Unsubscribe is an address that unsubscribed from a list at the provider, and when
+type Unsubscribe struct {
+	Email string
+	At    time.Time
+}
+
+// Connector talks to the API of a provider with the credentials of one account
+type Connector interface {
+	// Ping checks the credentials, returning ErrInvalidCredentials if they're rejected
+	Ping(ctx context.Context) error
+	// Upsert adds members to the list, subscribed, or updates the name of those already in it
+	// without changing whether they're subscribed. It returns the members the provider refused.
+	// At most 500 members are passed at once.
+	Upsert(ctx context.Context, listID string, members []Member) ([]MemberError, error)
+	// Unsubscribed lists the addresses unsubscribed from the list, since the given time when
+	// it's not zero, oldest change first
+	Unsubscribed(ctx context.Context, listID string, since time.Time) ([]Unsubscribe, error)
+}
+
+// NewConnector returns the Connector of provider for apiKey. Tests replace it to fake providers.
+var NewConnector = func(provider, apiKey string) (Connector, error) {
+	switch provider {
+	case models.ProviderMailchimp:
+		return NewMailchimp(apiKey)
+	}
+	return nil, fmt.Errorf("%w %q", ErrUnknownProvider, provider)
+}
+
+// IsProvider reports whether provider can be connected
+func IsProvider(provider string) bool {
+	return slices.Contains(Providers, provider)
+}
+
+// KeyHint is the end of an API key, enough for an admin to tell which one is configured
+func KeyHint(apiKey string) string {
+	if len(apiKey) <= 8 {
+		return "…"
+	}
+	return "…" + apiKey[len(apiKey)-4:]
+}
@@ -0,0 +1,161 @@
+package integrations
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// mailchimpPageSize is how many members are listed per request, the most Mailchimp allows
+const mailchimpPageSize = 1000
+
+// Mailchimp is a Connector for the Mailchimp Marketing API, whose lists are called audiences
+type Mailchimp struct {
+	APIKey string
+	// BaseURL is https://<dc>.api.mailchimp.com/3.0, dc being the data center the API key ends with
+	BaseURL string
+	Client  *http.Client
+}
+
+// NewMailchimp configures a Mailchimp connector for apiKey, which ends with the data center of
+// the account (e.g. 0123abcd-us21)
+func NewMailchimp(apiKey string) (*Mailchimp, error) {
+	i := strings.LastIndexByte(apiKey, '-')
+	if i < 0 || i == len(apiKey)-1 {
+		return nil, fmt.Errorf("%w: a Mailchimp API key ends with its data center, such as -us21", ErrInvalidCredentials)
+	}
+	return &Mailchimp{
+		APIKey:  apiKey,
+		BaseURL: "https://" + apiKey[i+1:] + ".api.mailchimp.com/3.0",
+		Client:  &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// do sends a request to the API and decodes the JSON response into out, when not nil
+func (m *Mailchimp) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(raw)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, m.BaseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	// any user name goes, the key is the password
+	req.SetBasicAuth("mylo", m.APIKey)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := m.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("mailchimp request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return ErrInvalidCredentials
+	}
+	if resp.StatusCode >= 300 {
+		var problem struct {
+			Title  string `json:"title"`
+			Detail string `json:"detail"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&problem)
+		return fmt.Errorf("mailchimp returned status %d (%s): %s", resp.StatusCode, problem.Title, problem.Detail)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func (m *Mailchimp) Ping(ctx context.Context) error {
+	return m.do(ctx, http.MethodGet, "/ping", nil, nil)
+}
+
+func (m *Mailchimp) Upsert(ctx context.Context, listID string, members []Member) ([]MemberError, error) {
+	type mergeFields struct {
+		FirstName string `json:"FNAME,omitempty"`
+		LastName  string `json:"LNAME,omitempty"`
+	}
+	type member struct {
+		EmailAddress string      `json:"email_address"`
+		StatusIfNew  string      `json:"status_if_new"`
+		MergeFields  mergeFields `json:"merge_fields"`
+	}
+	body := struct {
+		Members        []member `json:"members"`
+		UpdateExisting bool     `json:"update_existing"`
+	}{UpdateExisting: true}
+	for _, mb := range members {
+		first, last, _ := strings.Cut(strings.TrimSpace(mb.Name), " ")
+		body.Members = append(body.Members, member{
+			EmailAddress: mb.Email,
+			StatusIfNew:  "subscribed",
+			MergeFields:  mergeFields{FirstName: first, LastName: strings.TrimSpace(last)},
+		})
+	}
+
+	var out struct {
+		Errors []struct {
+			EmailAddress string `json:"email_address"`
+			Error        string `json:"error"`
+		} `json:"errors"`
+	}
+	if err := m.do(ctx, http.MethodPost, "/lists/"+url.PathEscape(listID), body, &out); err != nil {
+		return nil, err
+	}
+	refused := make([]MemberError, len(out.Errors))
+	for i, e := range out.Errors {
+		refused[i] = MemberError{Email: e.EmailAddress, Error: e.Error}
+	}
+	return refused, nil
+}
+
+func (m *Mailchimp) Unsubscribed(ctx context.Context, listID string, since time.Time) ([]Unsubscribe, error) {
+	var all []Unsubscribe
+	for offset := 0; ; offset += mailchimpPageSize {
+		query := url.Values{}
+		query.Set("status", "unsubscribed")
+		query.Set("fields", "members.email_address,members.last_changed,total_items")
+		query.Set("count", strconv.Itoa(mailchimpPageSize))
+		query.Set("offset", strconv.Itoa(offset))
+		if !since.IsZero() {
+			query.Set("since_last_changed", since.UTC().Format(time.RFC3339))
+		}
+		var page struct {
+			Members []struct {
+				EmailAddress string    `json:"email_address"`
+				LastChanged  time.Time `json:"last_changed"`
+			} `json:"members"`
+			TotalItems int `json:"total_items"`
+		}
+		path := "/lists/" + url.PathEscape(listID) + "/members?" + query.Encode()
+		if err := m.do(ctx, http.MethodGet, path, nil, &page); err != nil {
+			return nil, err
+		}
+		for _, mb := range page.Members {
+			all = append(all, Unsubscribe{Email: mb.EmailAddress, At: mb.LastChanged})
+		}
+		if len(page.Members) < mailchimpPageSize || offset+len(page.Members) >= page.TotalItems {
+			break
+		}
+	}
+	// Mailchimp lists by id; the oldest first lets the sync resume from the last one it applied
+	slices.SortFunc(all, func(a, b Unsubscribe) int { return a.At.Compare(b.At) })
+	return all, nil
+}
@@ -0,0 +1,107 @@
+package integrations
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNewMailchimp(t *testing.T) {
+	m, err := NewMailchimp("0123abcd-us21")
+	if err != nil || m.BaseURL != "https://us21.api.mailchimp.com/3.0" {
+		t.Fatalf("Expected the data center in the base URL, got %+v (%v)", m, err)
+	}
+	for _, key := range []string{"0123abcd", "0123abcd-"} {
+		if _, err := NewMailchimp(key); !errors.Is(err, ErrInvalidCredentials) {
+			t.Errorf("%q: expected ErrInvalidCredentials, got %v", key, err)
+		}
+	}
+}
+
+func TestMailchimp(t *testing.T) {
+	var got *http.Request
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		body = nil
+		json.NewDecoder(r.Body).Decode(&body)
+		if _, key, _ := r.BasicAuth(); key != "secret-us1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"title": "API Key Invalid"}`))
+			return
+		}
+		switch r.URL.Path {
+		case "/3.0/ping":
+			w.Write([]byte(`{"health_status": "Everything's Chimpy!"}`))
+		case "/3.0/lists/list1":
+			w.Write([]byte(`{"errors": [{"email_address": "fake@example.com", "error": "looks fake"}]}`))
+		case "/3.0/lists/list1/members":
+			w.Write([]byte(`{"members": [
+				{"email_address": "late@example.com", "last_changed": "2026-10-02T10:00:00+00:00"},
+				{"email_address": "early@example.com", "last_changed": "2026-10-01T10:00:00+00:00"}
+			], "total_items": 2}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"title": "Resource Not Found", "detail": "The requested resource could not be found."}`))
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	m := &Mailchimp{APIKey: "secret-us1", BaseURL: server.URL + "/3.0", Client: server.Client()}
+
+	t.Run("Ping", func(t *testing.T) {
+		if err := m.Ping(ctx); err != nil {
+			t.Fatalf("Expected the key accepted, got %v", err)
+		}
+		wrong := &Mailchimp{APIKey: "wrong-us1", BaseURL: m.BaseURL, Client: m.Client}
+		if err := wrong.Ping(ctx); !errors.Is(err, ErrInvalidCredentials) {
+			t.Errorf("Expected ErrInvalidCredentials, got %v", err)
+		}
+	})
+
+	t.Run("Upsert", func(t *testing.T) {
+		refused, err := m.Upsert(ctx, "list1", []Member{{Email: "ada@example.com", Name: "Ada King Lovelace"}, {Email: "fake@example.com"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(refused) != 1 || refused[0].Email != "fake@example.com" || refused[0].Error != "looks fake" {
+			t.Errorf("Expected the fake address refused, got %+v", refused)
+		}
+		members := body["members"].([]interface{})
+		ada := members[0].(map[string]interface{})
+		merge := ada["merge_fields"].(map[string]interface{})
+		if got.Method != http.MethodPost || body["update_existing"] != true || ada["status_if_new"] != "subscribed" {
+			t.Errorf("Expected a batch subscribe updating existing members, got %s %v", got.Method, body)
+		}
+		if merge["FNAME"] != "Ada" || merge["LNAME"] != "King Lovelace" {
+			t.Errorf("Expected the name split in FNAME and LNAME, got %v", merge)
+		}
+	})
+
+	t.Run("Unsubscribed", func(t *testing.T) {
+		since := time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC)
+		unsubscribes, err := m.Unsubscribed(ctx, "list1", since)
+		if err != nil {
+			t.Fatal(err)
+		}
+		q := got.URL.Query()
+		if q.Get("status") != "unsubscribed" || q.Get("since_last_changed") != "2026-09-30T00:00:00Z" {
+			t.Errorf("Unexpected query %v", q)
+		}
+		if len(unsubscribes) != 2 || unsubscribes[0].Email != "early@example.com" {
+			t.Errorf("Expected the oldest unsubscribe first, got %+v", unsubscribes)
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		_, err := m.Upsert(ctx, "missing/list", nil)
+		if err == nil || errors.Is(err, ErrInvalidCredentials) {
+			t.Errorf("Expected the API error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,244 @@
+package integrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"fiber-gorm-api/internal/cache"
+	"fiber-gorm-api/internal/db"
+	"fiber-gorm-api/internal/models"
+	"fiber-gorm-api/internal/outbox"
+	"fiber-gorm-api/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// pushBatchSize is how many subscribers are pushed per request, the most Mailchimp takes
+	pushBatchSize = 500
+	// staleSync is how long a sync may run before another worker assumes it crashed and takes over
+	staleSync = time.Hour
+)
+
+// unsubscribeFrom are the statuses an unsubscribe at the provider moves a subscriber from
+var unsubscribeFrom = []string{models.SubscriberStatusPending, models.SubscriberStatusActive, models.SubscriberStatusBounced}
+
+// Result counts what a sync did
+type Result struct {
+	Pushed       int
+	Refused      int
+	Unsubscribed int
+}
+
+// SyncAll syncs every enabled integration no other worker is syncing, and returns how many it
+// synced. An integration whose sync fails keeps the error in LastError; the others go on.
+func SyncAll(conn *gorm.DB) (int, error) {
+	var ids []uint
+	if err := conn.Model(&models.Integration{}).Where("enabled").Order("id").Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+	synced := 0
+	for _, id := range ids {
+		integration, err := claim(conn, id)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return synced, err
+		}
+		synced++
+		result, err := Sync(context.Background(), conn, integration)
+		if err != nil {
+			log.Printf("[WARN] Integrations: syncing %s of organization %d failed: %v", integration.Provider, integration.OrgID, err)
+		} else {
+			log.Printf("Integrations: synced %s of organization %d: %d pushed, %d refused, %d unsubscribed",
+				integration.Provider, integration.OrgID, result.Pushed, result.Refused, result.Unsubscribed)
+		}
+	}
+	return synced, nil
+}
+
+// claim marks the integration syncing and loads it with its lists, unless it's disabled or
+// another worker is syncing it (gorm.ErrRecordNotFound)
+func claim(conn *gorm.DB, id uint) (*models.Integration, error) {
+	now := time.Now()
+	res := conn.Model(&models.Integration{}).
+		Where("id = ? AND enabled AND (syncing_since IS NULL OR syncing_since < ?)", id, now.Add(-staleSync)).
+		Update("syncing_since", now)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	var integration models.Integration
+	if err := conn.Preload("Lists", func(tx *gorm.DB) *gorm.DB { return tx.Order("id") }).First(&integration, id).Error; err != nil {
+		return nil, err
+	}
+	return &integration, nil
+}
+
+// Sync pushes to and pulls from every list of a claimed integration, then releases it with the
+// outcome in LastSyncAt and LastError
+func Sync(ctx context.Context, conn *gorm.DB, integration *models.Integration) (Result, error) {
+	var result Result
+	err := sync(ctx, conn, integration, &result)
+	lastError := ""
+	if err != nil {
+		lastError = err.Error()
+	}
+	saveErr := conn.Model(integration).Updates(map[string]interface{}{
+		"syncing_since": nil,
+		"last_sync_at":  time.Now(),
+		"last_error":    lastError,
+	}).Error
+	if err == nil {
+		err = saveErr
+	}
+	return result, err
+}
+
+func sync(ctx context.Context, conn *gorm.DB, integration *models.Integration, result *Result) error {
+	connector, err := NewConnector(integration.Provider, integration.APIKey)
+	if err != nil {
+		return err
+	}
+	for i := range integration.Lists {
+		list := &integration.Lists[i]
+		if err := push(ctx, conn, connector, integration.OrgID, list, result); err != nil {
+			return fmt.Errorf("pushing to list %s: %w", list.RemoteID, err)
+		}
+		if err := pull(ctx, conn, connector, integration, list, result); err != nil {
+			return fmt.Errorf("pulling from list %s: %w", list.RemoteID, err)
+		}
+	}
+	return nil
+}
+
+// push sends the active subscribers of the list changed since the last push, in the order
+// they changed, and saves how far it got after each batch
+func push(ctx context.Context, conn *gorm.DB, connector Connector, orgID uint, list *models.IntegrationList, result *Result) error {
+	for {
+		query := conn.WithContext(ctx).Model(&models.Subscriber{}).
+			Where("subscribers.org_id = ? AND subscribers.status = ?", orgID, models.SubscriberStatusActive)
+		if list.SubscriberType != "" {
+			query = query.Where("EXISTS (SELECT 1 FROM subscriber_types st WHERE st.subscriber_id = subscribers.id AND st.name = ?)", list.SubscriberType)
+		}
+		if list.PushedAt != nil {
+			query = query.Where("(subscribers.updated_at > ? OR (subscribers.updated_at = ? AND subscribers.id > ?))",
+				*list.PushedAt, *list.PushedAt, list.PushedID)
+		}
+		var batch []models.Subscriber
+		if err := query.Order("subscribers.updated_at").Order("subscribers.id").Limit(pushBatchSize).Find(&batch).Error; err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+
+		members := make([]Member, len(batch))
+		for i, s := range batch {
+			members[i] = Member{Email: s.Email, Name: s.Name}
+		}
+		refused, err := connector.Upsert(ctx, list.RemoteID, members)
+		if err != nil {
+			return err
+		}
+		for _, r := range refused {
+			log.Printf("[WARN] Integrations: list %s refused a subscriber: %s", list.RemoteID, r.Error)
+		}
+		result.Pushed += len(batch) - len(refused)
+		result.Refused += len(refused)
+
+		last := batch[len(batch)-1]
+		list.PushedAt, list.PushedID = &last.UpdatedAt, last.ID
+		if err := conn.Model(list).Updates(map[string]interface{}{"pushed_at": last.UpdatedAt, "pushed_id": last.ID}).Error; err != nil {
+			return err
+		}
+		if len(batch) < pushBatchSize {
+			return nil
+		}
+	}
+}
+
+// pull unsubscribes the subscribers of the organization who unsubscribed from the list at the
+// provider since the last pull
+func pull(ctx context.Context, conn *gorm.DB, connector Connector, integration *models.Integration, list *models.IntegrationList, result *Result) error {
+	var since time.Time
+	if list.PulledAt != nil {
+		since = *list.PulledAt
+	}
+	unsubscribes, err := connector.Unsubscribed(ctx, list.RemoteID, since)
+	if err != nil {
+		return err
+	}
+	// status changes are recorded in the subscribers' revisions as made by the provider
+	ctx = repository.WithAuthor(ctx, integration.Provider)
+	for _, u := range unsubscribes {
+		changed, err := unsubscribe(ctx, conn, integration.OrgID, u.Email)
+		if err != nil {
+			return err
+		}
+		result.Unsubscribed += len(changed)
+		cache.InvalidateSubscriber(ctx, changed...)
+		if u.At.After(since) {
+			since = u.At
+			list.PulledAt = &since
+			if err := conn.Model(list).Update("pulled_at", since).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// unsubscribe marks the subscribers of the organization with the address unsubscribed, and
+// returns the ids of those whose status changed
+func unsubscribe(ctx context.Context, conn *gorm.DB, orgID uint, email string) ([]uint, error) {
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return nil, nil
+	}
+	var subscribers []models.Subscriber
+	if err := conn.WithContext(ctx).
+		Where("org_id = ? AND LOWER(email) = LOWER(?) AND status IN ?", orgID, email, unsubscribeFrom).
+		Find(&subscribers).Error; err != nil {
+		return nil, err
+	}
+
+	var changed []uint
+	err := db.Tx(ctx, conn, func(tx *gorm.DB) error {
+		for _, s := range subscribers {
+			before, err := repository.LoadSnapshot(tx, s.ID)
+			if err != nil {
+				return err
+			}
+			res := tx.Model(&models.Subscriber{}).
+				Where("id = ? AND status IN ?", s.ID, unsubscribeFrom).
+				Updates(map[string]interface{}{
+					"status":  models.SubscriberStatusUnsubscribed,
+					"version": gorm.Expr("version + 1"),
+				})
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				continue
+			}
+			if err := repository.RecordRevision(ctx, tx, s.ID, models.RevisionSynced, before); err != nil {
+				return err
+			}
+			s.Status = models.SubscriberStatusUnsubscribed
+			if err := outbox.EnqueueSubscriber(tx, outbox.TopicSubscriberUpdated, &s); err != nil {
+				return err
+			}
+			changed = append(changed, s.ID)
+		}
+		return nil
+	})
+	return changed, err
+}
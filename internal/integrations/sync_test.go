@@ -0,0 +1,144 @@
+package integrations
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"fiber-gorm-api/internal/models"
+	"fiber-gorm-api/internal/testutil/testenv"
+)
+
+// fakeConnector records what is pushed and serves unsubscribes from memory
+type fakeConnector struct {
+	pushed       map[string][]Member
+	unsubscribed map[string][]Unsubscribe
+	err          error
+}
+
+func (f *fakeConnector) Ping(context.Context) error { return f.err }
+
+func (f *fakeConnector) Upsert(_ context.Context, listID string, members []Member) ([]MemberError, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.pushed[listID] = append(f.pushed[listID], members...)
+	return nil, nil
+}
+
+func (f *fakeConnector) Unsubscribed(_ context.Context, listID string, since time.Time) ([]Unsubscribe, error) {
+	var out []Unsubscribe
+	for _, u := range f.unsubscribed[listID] {
+		if since.IsZero() || !u.At.Before(since) {
+			out = append(out, u)
+		}
+	}
+	return out, nil
+}
+
+func useFake(t *testing.T) *fakeConnector {
+	t.Helper()
+	fake := &fakeConnector{pushed: map[string][]Member{}, unsubscribed: map[string][]Unsubscribe{}}
+	original := NewConnector
+	t.Cleanup(func() { NewConnector = original })
+	NewConnector = func(string, string) (Connector, error) { return fake, nil }
+	return fake
+}
+
+func TestSyncAll(t *testing.T) {
+	fake := useFake(t)
+	conn := testenv.SQLite(t)
+	for _, s := range []models.Subscriber{
+		{OrgID: models.DefaultOrgID, Email: "ada@example.com", Name: "Ada", Status: models.SubscriberStatusActive,
+			SubscriberTypes: []models.SubscriberType{{Name: "donor", Frequency: "monthly", Channel: "email"}}},
+		{OrgID: models.DefaultOrgID, Email: "grace@example.com", Name: "Grace", Status: models.SubscriberStatusActive},
+		{OrgID: models.DefaultOrgID, Email: "pending@example.com", Status: models.SubscriberStatusPending},
+		{OrgID: models.DefaultOrgID + 1, Email: "other-org@example.com", Status: models.SubscriberStatusActive},
+	} {
+		if err := conn.Create(&s).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	integration := models.Integration{OrgID: models.DefaultOrgID, Provider: models.ProviderMailchimp, APIKey: "key-us1", Enabled: true,
+		Lists: []models.IntegrationList{{RemoteID: "everyone"}, {RemoteID: "donors", SubscriberType: "donor"}}}
+	disabled := models.Integration{OrgID: models.DefaultOrgID + 1, Provider: models.ProviderMailchimp, APIKey: "key-us1",
+		Lists: []models.IntegrationList{{RemoteID: "other"}}}
+	for _, i := range []*models.Integration{&integration, &disabled} {
+		if err := conn.Create(i).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("Push", func(t *testing.T) {
+		if n, err := SyncAll(conn); err != nil || n != 1 {
+			t.Fatalf("Expected the enabled integration synced, got %d (%v)", n, err)
+		}
+		if len(fake.pushed["everyone"]) != 2 || len(fake.pushed["donors"]) != 1 || fake.pushed["donors"][0].Email != "ada@example.com" {
+			t.Errorf("Expected the 2 active subscribers and the donor pushed, got %+v", fake.pushed)
+		}
+		if len(fake.pushed["other"]) != 0 {
+			t.Error("Expected nothing pushed for the disabled integration")
+		}
+		conn.First(&integration, integration.ID)
+		if integration.LastSyncAt == nil || integration.LastError != "" || integration.SyncingSince != nil {
+			t.Errorf("Expected a successful sync recorded and released, got %+v", integration)
+		}
+
+		// only subscribers changed since are pushed again
+		SyncAll(conn)
+		if len(fake.pushed["everyone"]) != 2 {
+			t.Errorf("Expected nothing pushed twice, got %+v", fake.pushed["everyone"])
+		}
+		newcomer := models.Subscriber{OrgID: models.DefaultOrgID, Email: "linus@example.com", Name: "Linus", Status: models.SubscriberStatusActive}
+		conn.Create(&newcomer)
+		SyncAll(conn)
+		if len(fake.pushed["everyone"]) != 3 || fake.pushed["everyone"][2].Email != "linus@example.com" {
+			t.Errorf("Expected the new subscriber pushed, got %+v", fake.pushed["everyone"])
+		}
+	})
+
+	t.Run("Pull", func(t *testing.T) {
+		at := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+		fake.unsubscribed["everyone"] = []Unsubscribe{{Email: "GRACE@example.com", At: at}, {Email: "stranger@example.com", At: at}}
+		SyncAll(conn)
+
+		var grace models.Subscriber
+		conn.Where("email = ?", "grace@example.com").First(&grace)
+		if grace.Status != models.SubscriberStatusUnsubscribed {
+			t.Fatalf("Expected Grace unsubscribed, got %s", grace.Status)
+		}
+		var revision models.SubscriberRevision
+		conn.Where("subscriber_id = ?", grace.ID).Order("id DESC").First(&revision)
+		if revision.Action != models.RevisionSynced || revision.Author != models.ProviderMailchimp {
+			t.Errorf("Expected a revision authored by mailchimp, got %+v", revision)
+		}
+		var list models.IntegrationList
+		conn.Where("remote_id = ?", "everyone").First(&list)
+		if list.PulledAt == nil || !list.PulledAt.Equal(at) {
+			t.Errorf("Expected the pull cursor at the last unsubscribe, got %v", list.PulledAt)
+		}
+	})
+
+	t.Run("Failures are recorded", func(t *testing.T) {
+		fake.err = errors.New("mailchimp is down")
+		conn.Model(&models.Subscriber{}).Where("email = ?", "ada@example.com").Update("name", "Ada Lovelace")
+		SyncAll(conn)
+		conn.First(&integration, integration.ID)
+		if integration.LastError == "" || integration.SyncingSince != nil {
+			t.Errorf("Expected the error recorded and the integration released, got %+v", integration)
+		}
+	})
+
+	t.Run("A running sync isn't claimed twice", func(t *testing.T) {
+		fake.err = nil
+		conn.Model(&integration).Update("syncing_since", time.Now())
+		if n, _ := SyncAll(conn); n != 0 {
+			t.Errorf("Expected the integration skipped, synced %d", n)
+		}
+		conn.Model(&integration).Update("syncing_since", time.Now().Add(-2*staleSync))
+		if n, _ := SyncAll(conn); n != 1 {
+			t.Errorf("Expected a stale sync taken over, synced %d", n)
+		}
+	})
+}
@@ -0,0 +1,100 @@
+// Package mailqueue sends sign-in emails from the outbox worker rather than the request: the
+// request only records the email, so a slow or failing provider never holds up signing in.
+// Sends are retried with the outbox backoff; those given up on are kept as dead letters
+// (models.EmailDeadLetter) for platform admins to look into.
+package mailqueue
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"fiber-gorm-api/internal/models"
+	"fiber-gorm-api/internal/outbox"
+	sendgridservice "fiber-gorm-api/internal/services"
+
+	"gorm.io/gorm"
+)
+
+// TopicSend is the outbox topic of one email to send
+const TopicSend = "email.send"
+
+// Kinds of queued emails, named like in the email log
+const (
+	KindSignInCode = sendgridservice.EmailTypeSignInCode
+	KindMagicLink  = sendgridservice.EmailTypeMagicLink
+)
+
+// errExpired is the failure of an email that waited longer than what it carries stays valid
+var errExpired = errors.New("expired before it could be sent")
+
+// Email is the payload of TopicSend. Secret, the code or the link, is only kept until the email
+// is sent or given up on.
+type Email struct {
+	Kind        string                       `json:"kind"`
+	To          string                       `json:"to"`
+	Locale      string                       `json:"locale,omitempty"`
+	Secret      string                       `json:"secret,omitempty"`
+	ExpiresAt   time.Time                    `json:"expires_at"`
+	Attachments []sendgridservice.Attachment `json:"attachments,omitempty"`
+}
+
+// Enqueue records email to be sent by the outbox worker. Attachments are checked first, an
+// *sendgridservice.AttachmentError rejecting them right away rather than failing the send.
+func Enqueue(db *gorm.DB, email Email) error {
+	if err := sendgridservice.ValidateAttachments(email.Attachments); err != nil {
+		return err
+	}
+	return outbox.Enqueue(db, TopicSend, 0, email)
+}
+
+// Send sends the email of a TopicSend event. Emails the provider refused, of an unknown kind or
+// past their expiry fail permanently: retrying wouldn't deliver anything usable.
+func Send(event models.OutboxEvent) error {
+	var email Email
+	if err := json.Unmarshal([]byte(event.Payload), &email); err != nil {
+		return outbox.Permanent(err)
+	}
+	if time.Now().After(email.ExpiresAt) {
+		return outbox.Permanent(errExpired)
+	}
+
+	var err error
+	switch email.Kind {
+	case KindSignInCode:
+		err = sendgridservice.SendCodeEmailFunc(email.To, email.Locale, email.Secret, email.Attachments...)
+	case KindMagicLink:
+		err = sendgridservice.SendMagicLinkEmailFunc(email.To, email.Locale, email.Secret, email.Attachments...)
+	default:
+		return outbox.Permanent(fmt.Errorf("unknown email kind %q", email.Kind))
+	}
+	if err != nil && !sendgridservice.Retryable(err) {
+		return outbox.Permanent(err)
+	}
+	return err
+}
+
+// DeadLetter records the email of a TopicSend event given up on, and drops its secret from the
+// event, which is kept
+func DeadLetter(tx *gorm.DB, event models.OutboxEvent, cause error) error {
+	var email Email
+	_ = json.Unmarshal([]byte(event.Payload), &email)
+	letter := models.EmailDeadLetter{
+		OutboxEventID: event.ID,
+		Kind:          email.Kind,
+		ToEmail:       email.To,
+		Attempts:      event.Attempts,
+		LastError:     cause.Error(),
+	}
+	if err := tx.Create(&letter).Error; err != nil {
+		return err
+	}
+
+	email.Secret = ""
+	raw, err := json.Marshal(email)
+	if err != nil {
+		return err
+	}
+	return tx.Model(&event).Update("payload", string(raw)).Error
+}
@@ -0,0 +1,129 @@
+package mailqueue
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"fiber-gorm-api/internal/models"
+	"fiber-gorm-api/internal/outbox"
+	sendgridservice "fiber-gorm-api/internal/services"
+	"fiber-gorm-api/internal/testutil/testenv"
+)
+
+func TestQueue(t *testing.T) {
+	conn := testenv.SQLite(t)
+	t.Setenv("OUTBOX_MAX_ATTEMPTS", "2")
+	outbox.Handle(TopicSend, Send)
+	outbox.HandleFailed(TopicSend, DeadLetter)
+
+	var sent []string
+	var attached []sendgridservice.Attachment
+	providerDown := false
+	original := sendgridservice.SendCodeEmailFunc
+	t.Cleanup(func() { sendgridservice.SendCodeEmailFunc = original })
+	sendgridservice.SendCodeEmailFunc = func(toEmail, locale, code string, attachments ...sendgridservice.Attachment) error {
+		if providerDown {
+			return errors.New("connection refused")
+		}
+		sent = append(sent, toEmail+" "+locale+" "+code)
+		attached = append(attached, attachments...)
+		return nil
+	}
+
+	enqueue := func(email Email) models.OutboxEvent {
+		t.Helper()
+		if err := Enqueue(conn, email); err != nil {
+			t.Fatal(err)
+		}
+		var event models.OutboxEvent
+		conn.Order("id DESC").First(&event)
+		return event
+	}
+	// drain runs the worker once, with the backoff of earlier failures elapsed
+	drain := func() {
+		t.Helper()
+		conn.Model(&models.OutboxEvent{}).Where("processed_at IS NULL").Update("available_at", time.Now().Add(-time.Second))
+		if _, err := outbox.Drain(conn, 10); err != nil {
+			t.Fatal(err)
+		}
+	}
+	reload := func(event models.OutboxEvent) models.OutboxEvent {
+		t.Helper()
+		conn.First(&event, event.ID)
+		return event
+	}
+	expires := time.Now().Add(time.Minute)
+
+	t.Run("Sends queued emails", func(t *testing.T) {
+		event := enqueue(Email{Kind: KindSignInCode, To: "ada@example.com", Locale: "fr", Secret: "123456", ExpiresAt: expires})
+		drain()
+		if len(sent) != 1 || sent[0] != "ada@example.com fr 123456" {
+			t.Errorf("Expected the code sent, got %v", sent)
+		}
+		if reload(event).ProcessedAt == nil {
+			t.Error("Expected the event processed")
+		}
+	})
+
+	t.Run("Sends attachments", func(t *testing.T) {
+		invite := sendgridservice.Attachment{Filename: "market.ics", ContentType: "text/calendar", Content: []byte("BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n")}
+		enqueue(Email{Kind: KindSignInCode, To: "ada@example.com", Secret: "123456", ExpiresAt: expires, Attachments: []sendgridservice.Attachment{invite}})
+		drain()
+		if len(attached) != 1 || attached[0].Filename != "market.ics" || string(attached[0].Content) != string(invite.Content) {
+			t.Errorf("Expected the invite sent along, got %+v", attached)
+		}
+
+		bad := Email{Kind: KindSignInCode, To: "ada@example.com", ExpiresAt: expires, Attachments: []sendgridservice.Attachment{
+			{Filename: "run.exe", ContentType: "application/octet-stream", Content: []byte("MZ")},
+		}}
+		var attachmentErr *sendgridservice.AttachmentError
+		if err := Enqueue(conn, bad); !errors.As(err, &attachmentErr) {
+			t.Errorf("Expected the attachment rejected on enqueue, got %v", err)
+		}
+	})
+
+	t.Run("Retries, then keeps a dead letter", func(t *testing.T) {
+		providerDown = true
+		defer func() { providerDown = false }()
+		event := enqueue(Email{Kind: KindSignInCode, To: "grace@example.com", Secret: "654321", ExpiresAt: expires})
+
+		drain()
+		if event = reload(event); event.Attempts != 1 || event.FailedAt != nil {
+			t.Fatalf("Expected one failed attempt to retry, got %d attempts, failed at %v", event.Attempts, event.FailedAt)
+		}
+		drain()
+		if event = reload(event); event.FailedAt == nil {
+			t.Fatal("Expected the event given up on after OUTBOX_MAX_ATTEMPTS")
+		}
+		if strings.Contains(event.Payload, "654321") {
+			t.Errorf("Expected the code dropped from the event, got %s", event.Payload)
+		}
+		var letter models.EmailDeadLetter
+		if err := conn.Where("outbox_event_id = ?", event.ID).First(&letter).Error; err != nil {
+			t.Fatalf("Expected a dead letter: %v", err)
+		}
+		if letter.Kind != KindSignInCode || letter.ToEmail != "grace@example.com" || letter.Attempts != 2 || !strings.Contains(letter.LastError, "connection refused") {
+			t.Errorf("Unexpected dead letter %+v", letter)
+		}
+	})
+
+	t.Run("Expired emails are given up on at once", func(t *testing.T) {
+		event := enqueue(Email{Kind: KindSignInCode, To: "late@example.com", Secret: "111111", ExpiresAt: time.Now().Add(-time.Second)})
+		drain()
+		if event = reload(event); event.FailedAt == nil || event.Attempts != 1 {
+			t.Fatalf("Expected the event failed after one attempt, got %d attempts, failed at %v", event.Attempts, event.FailedAt)
+		}
+		var count int64
+		conn.Model(&models.EmailDeadLetter{}).Where("to_email = ?", "late@example.com").Count(&count)
+		if count != 1 {
+			t.Errorf("Expected a dead letter, found %d", count)
+		}
+		for _, s := range sent {
+			if strings.HasPrefix(s, "late@example.com") {
+				t.Error("Expected the expired code not to be sent")
+			}
+		}
+	})
+}
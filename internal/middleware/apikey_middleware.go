@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"fiber-gorm-api/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// APIKeyHeader is the request header carrying a machine-to-machine API key
+const APIKeyHeader = "X-API-Key"
+
+// APIKeyLocalKey is the fiber.Ctx Locals key under which the authenticated *models.ApiKey is stored
+const APIKeyLocalKey = "api_key"
+
+// HashAPIKey returns the hex SHA-256 of a plaintext API key, as stored in the database
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// CurrentAPIKey returns the API key the request authenticated with, or nil for JWT sessions
+func CurrentAPIKey(c *fiber.Ctx) *models.ApiKey {
+	key, _ := c.Locals(APIKeyLocalKey).(*models.ApiKey)
+	return key
+}
+
+// HasScope reports whether the caller may act with scope. Signed-in users (JWT sessions)
+// get the scopes of their admin role, or every scope without one; API keys only what they were granted.
+func HasScope(c *fiber.Ctx, scope string) bool {
+	return callerOf(c).HasScope(scope)
+}
+
+// CanSeePII reports whether responses may include raw personal data. API keys and admin
+// roles without the pii scope get redacted output; other sessions and public routes are unaffected.
+func CanSeePII(c *fiber.Ctx) bool {
+	return callerOf(c).CanSeePII()
+}
+
+// authenticateAPIKey looks up an active API key by its plaintext and records its use.
+// Errors are safe to return to the client.
+func authenticateAPIKey(ctx context.Context, db *gorm.DB, plaintext string) (*models.ApiKey, error) {
+	db = db.WithContext(ctx)
+	var key models.ApiKey
+	if err := db.Where("key_hash = ?", HashAPIKey(plaintext)).First(&key).Error; err != nil {
+		return nil, errors.New("Invalid API key")
+	}
+	now := time.Now()
+	if !key.Active(now) {
+		return nil, errors.New("API key revoked or expired")
+	}
+
+	// best effort, a failed bookkeeping write shouldn't fail the request
+	db.Model(&key).UpdateColumn("last_used_at", now)
+	key.LastUsedAt = &now
+	return &key, nil
+}
+
+// RequireJWTOrAPIKey accepts either an X-API-Key header or a Bearer JWT (see RequireJWT).
+// API keys need the read scope for GET/HEAD requests and the write scope for everything else.
+func RequireJWTOrAPIKey(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		plaintext := c.Get(APIKeyHeader)
+		if plaintext == "" {
+			return RequireJWT(c)
+		}
+
+		key, err := authenticateAPIKey(c.UserContext(), db, plaintext)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": err.Error()})
+		}
+
+		required := models.ScopeWrite
+		if c.Method() == fiber.MethodGet || c.Method() == fiber.MethodHead {
+			required = models.ScopeRead
+		}
+		if !key.HasScope(required) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "API key lacks the " + required + " scope"})
+		}
+
+		c.Locals(APIKeyLocalKey, key)
+		return c.Next()
+	}
+}
+
+// RequireScope rejects callers lacking scope with 403
+func RequireScope(scope string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !HasScope(c, scope) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Missing required scope: " + scope})
+		}
+		return c.Next()
+	}
+}
+
+// RequireMethodScope applies the read / write split to every caller, including admins whose
+// role limits their session: GET/HEAD need the read scope, everything else write.
+func RequireMethodScope(c *fiber.Ctx) error {
+	required := models.ScopeWrite
+	if c.Method() == fiber.MethodGet || c.Method() == fiber.MethodHead {
+		required = models.ScopeRead
+	}
+	if !HasScope(c, required) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Missing required scope: " + required})
+	}
+	return c.Next()
+}
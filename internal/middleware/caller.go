@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"fiber-gorm-api/internal/models"
+	"fiber-gorm-api/internal/repository"
+	"fiber-gorm-api/internal/session"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Caller is whoever an authenticated request acts as, independent of the transport (REST or gRPC):
+// an API key, a signed-in session, or neither on public routes.
+type Caller struct {
+	APIKey  *models.ApiKey
+	Session *session.Session
+}
+
+// callerOf returns the caller attached to a Fiber request by RequireJWT / RequireJWTOrAPIKey
+func callerOf(c *fiber.Ctx) Caller {
+	return Caller{APIKey: CurrentAPIKey(c), Session: CurrentSession(c)}
+}
+
+// Identity names the caller for created_by style columns: the session's email or
+// api-key:<prefix>, empty on public routes
+func (c Caller) Identity() string {
+	if c.APIKey != nil {
+		return "api-key:" + c.APIKey.Prefix
+	}
+	if c.Session != nil {
+		return c.Session.Email
+	}
+	return ""
+}
+
+// CurrentIdentity is the Identity of the caller of a Fiber request
+func CurrentIdentity(c *fiber.Ctx) string {
+	return callerOf(c).Identity()
+}
+
+// RecordAuthor attaches the caller's identity to the request context, so the subscriber
+// revisions written while handling it name who made the change
+func RecordAuthor(c *fiber.Ctx) error {
+	c.SetUserContext(repository.WithAuthor(c.UserContext(), CurrentIdentity(c)))
+	return c.Next()
+}
+
+// HasScope reports whether the caller may act with scope (see the Fiber HasScope)
+func (c Caller) HasScope(scope string) bool {
+	if c.APIKey != nil {
+		return c.APIKey.HasScope(scope)
+	}
+	if c.Session == nil {
+		return false
+	}
+	return c.Session.Role == "" || models.RoleHasScope(c.Session.Role, scope)
+}
+
+// CanSeePII reports whether responses may include raw personal data (see the Fiber CanSeePII)
+func (c Caller) CanSeePII() bool {
+	if c.APIKey != nil {
+		return c.APIKey.HasScope(models.ScopePII)
+	}
+	if c.Session != nil && c.Session.Role != "" {
+		return models.RoleHasScope(c.Session.Role, models.ScopePII)
+	}
+	return true
+}
+
+// orgID is the caller's own organization, 0 when it has none
+func (c Caller) orgID() uint {
+	if c.APIKey != nil && c.APIKey.OrgID != 0 {
+		return c.APIKey.OrgID
+	}
+	if c.Session != nil && c.Session.OrgID != 0 {
+		return c.Session.OrgID
+	}
+	return 0
+}
+
+// OrgID is the organization the caller's queries are scoped to, the default one when it has none
+func (c Caller) OrgID() uint {
+	if orgID := c.orgID(); orgID != 0 {
+		return orgID
+	}
+	return models.DefaultOrgID
+}
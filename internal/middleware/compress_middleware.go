@@ -0,0 +1,129 @@
+package middleware
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+)
+
+const defaultCompressionMinBytes = 1024
+
+// compressionLevels are the brotli and gzip levels of COMPRESSION_LEVEL
+var compressionLevels = map[string][2]int{
+	"speed":   {fasthttp.CompressBrotliBestSpeed, fasthttp.CompressBestSpeed},
+	"default": {fasthttp.CompressBrotliDefaultCompression, fasthttp.CompressDefaultCompression},
+	"best":    {fasthttp.CompressBrotliBestCompression, fasthttp.CompressBestCompression},
+}
+
+// Compression compresses text responses (JSON, CSV exports, swagger assets) with brotli or
+// gzip, whichever the client prefers in Accept-Encoding, q-values included.
+//
+//   - COMPRESSION_LEVEL: off, speed, default (the default) or best
+//   - COMPRESSION_MIN_BYTES: smaller bodies are sent as is (1024 by default)
+//
+// Streams (SSE) and WebSocket upgrades are left alone.
+func Compression() fiber.Handler {
+	level := os.Getenv("COMPRESSION_LEVEL")
+	if level == "" {
+		level = "default"
+	}
+	if level == "off" {
+		return func(c *fiber.Ctx) error { return c.Next() }
+	}
+	levels, ok := compressionLevels[level]
+	if !ok {
+		levels = compressionLevels["default"]
+	}
+	minBytes := defaultCompressionMinBytes
+	if n, err := strconv.Atoi(os.Getenv("COMPRESSION_MIN_BYTES")); err == nil && n >= 0 {
+		minBytes = n
+	}
+
+	return func(c *fiber.Ctx) error {
+		if err := c.Next(); err != nil {
+			return err
+		}
+
+		resp := c.Response()
+		if resp.IsBodyStream() || len(resp.Header.Peek(fiber.HeaderContentEncoding)) > 0 ||
+			!compressible(string(resp.Header.ContentType())) {
+			return nil
+		}
+		switch resp.StatusCode() {
+		case fiber.StatusSwitchingProtocols, fiber.StatusNoContent, fiber.StatusNotModified:
+			return nil
+		}
+		body := resp.Body()
+		if len(body) < minBytes {
+			return nil
+		}
+
+		// the encoding depends on the request, so must the caches
+		c.Vary(fiber.HeaderAcceptEncoding)
+
+		var out []byte
+		switch acceptedEncoding(c.Get(fiber.HeaderAcceptEncoding)) {
+		case "br":
+			out = fasthttp.AppendBrotliBytesLevel(nil, body, levels[0])
+			c.Set(fiber.HeaderContentEncoding, "br")
+		case "gzip":
+			out = fasthttp.AppendGzipBytesLevel(nil, body, levels[1])
+			c.Set(fiber.HeaderContentEncoding, "gzip")
+		default:
+			return nil
+		}
+		resp.SetBodyRaw(out)
+		return nil
+	}
+}
+
+// compressible reports whether a content type is text that's worth compressing
+func compressible(contentType string) bool {
+	contentType = strings.ToLower(contentType)
+	for _, t := range []string{"json", "text/", "xml", "javascript", "csv"} {
+		if strings.Contains(contentType, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// acceptedEncoding picks br or gzip from an Accept-Encoding header, by q-value and brotli on a
+// tie, or "" when the client accepts neither. Codings with q=0 are refused, and "*" stands for
+// every coding not listed.
+func acceptedEncoding(header string) string {
+	q := map[string]float64{}
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		weight := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				weight = f
+			}
+		}
+		q[name] = weight
+	}
+
+	weight := func(coding string) float64 {
+		if w, ok := q[coding]; ok {
+			return w
+		}
+		return q["*"] // 0 when absent
+	}
+	br, gzip := weight("br"), weight("gzip")
+	switch {
+	case br > 0 && br >= gzip:
+		return "br"
+	case gzip > 0:
+		return "gzip"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestAcceptedEncoding(t *testing.T) {
+	cases := map[string]string{
+		"":                     "",
+		"identity":             "",
+		"gzip":                 "gzip",
+		"gzip, deflate, br":    "br",
+		"br;q=0, gzip":         "gzip",
+		"br;q=0.5, gzip;q=0.8": "gzip",
+		"gzip;q=0, br;q=0":     "",
+		"*":                    "br",
+		"*;q=0.1, gzip;q=0.9":  "gzip",
+		"deflate, GZIP;q=1.0":  "gzip",
+	}
+	for header, want := range cases {
+		if got := acceptedEncoding(header); got != want {
+			t.Errorf("%q: expected %q, got %q", header, want, got)
+		}
+	}
+}
+
+func TestCompression(t *testing.T) {
+	t.Setenv("COMPRESSION_MIN_BYTES", "100")
+
+	large := strings.Repeat(`{"email":"ada@example.com"},`, 100)
+	app := fiber.New()
+	app.Use(Compression())
+	app.Get("/large", func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		return c.SendString(large)
+	})
+	app.Get("/small", func(c *fiber.Ctx) error { return c.JSON(fiber.Map{"ok": true}) })
+
+	get := func(path, acceptEncoding string) (http.Header, []byte) {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Request error: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		return resp.Header, body
+	}
+
+	header, body := get("/large", "gzip")
+	if header.Get("Content-Encoding") != "gzip" || header.Get("Vary") != "Accept-Encoding" {
+		t.Fatalf("Expected a gzipped response varying on Accept-Encoding, got %v", header)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("Invalid gzip body: %v", err)
+	}
+	if plain, _ := io.ReadAll(zr); string(plain) != large {
+		t.Errorf("Expected the original body after decompression")
+	}
+
+	if header, _ := get("/large", "gzip, br"); header.Get("Content-Encoding") != "br" {
+		t.Errorf("Expected brotli, got %q", header.Get("Content-Encoding"))
+	}
+	if header, body := get("/large", "br;q=0, gzip;q=0"); header.Get("Content-Encoding") != "" || string(body) != large {
+		t.Errorf("Expected an uncompressed body when both codings are refused")
+	}
+	if header, _ := get("/small", "gzip"); header.Get("Content-Encoding") != "" {
+		t.Errorf("Expected bodies under the threshold to be sent as is")
+	}
+}
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"log"
+	"os"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/cors"
+)
+
+// CORS builds the CORS middleware of one route group ("admin", "signin", "signup").
+// Allowed origins come from CORS_<GROUP>_ORIGINS, a comma separated list that may use
+// subdomain wildcards (https://*.mylocal.ing), falling back to defaultOrigins.
+// CORS_<GROUP>_ALLOW_CREDENTIALS=true lets browsers send cookies / credentials.
+// CORS_DEV_MODE=true accepts any origin, for local frontends only.
+func CORS(group, defaultOrigins string, cfg cors.Config) fiber.Handler {
+	return newCORS(group, corsOrigins(group, defaultOrigins), cfg)
+}
+
+// PartnerCORS builds the CORS middleware of the embeddable routes of a group: its origins as for
+// CORS, plus the partner sites of CORS_<GROUP>_PARTNER_ORIGINS embedding them (see EmbedOrigins)
+func PartnerCORS(group, defaultOrigins string, cfg cors.Config) fiber.Handler {
+	return newCORS(group, EmbedOrigins(group, defaultOrigins), cfg)
+}
+
+// EmbedOrigins returns the origins of group as for CORS with its partner sites, or * when
+// CORS_<GROUP>_PARTNER_ORIGINS is *
+func EmbedOrigins(group, defaultOrigins string) string {
+	partners := normalizeOrigins(os.Getenv(corsPrefix(group) + "PARTNER_ORIGINS"))
+	if partners == "*" {
+		return partners
+	}
+	return normalizeOrigins(corsOrigins(group, defaultOrigins) + "," + partners)
+}
+
+// corsPrefix is the prefix of the environment variables of group
+func corsPrefix(group string) string {
+	return "CORS_" + strings.ToUpper(group) + "_"
+}
+
+// corsOrigins returns CORS_<GROUP>_ORIGINS, or defaultOrigins when unset
+func corsOrigins(group, defaultOrigins string) string {
+	if origins := os.Getenv(corsPrefix(group) + "ORIGINS"); origins != "" {
+		return origins
+	}
+	return defaultOrigins
+}
+
+// newCORS builds the CORS middleware of group allowing origins
+func newCORS(group, origins string, cfg cors.Config) fiber.Handler {
+	prefix := corsPrefix(group)
+	cfg.AllowOrigins = normalizeOrigins(origins)
+	cfg.AllowCredentials = os.Getenv(prefix+"ALLOW_CREDENTIALS") == "true"
+
+	if os.Getenv("CORS_DEV_MODE") == "true" {
+		log.Printf("[WARN] CORS_DEV_MODE is on, %s routes accept requests from any origin", group)
+		cfg.AllowOrigins = ""
+		cfg.AllowOriginsFunc = func(string) bool { return true }
+	} else if cfg.AllowCredentials && cfg.AllowOrigins == "*" {
+		// fiber refuses this combination, fail loudly with the variable to fix
+		log.Fatalf("%sORIGINS can't be * when %sALLOW_CREDENTIALS=true", prefix, prefix)
+	}
+
+	return cors.New(cfg)
+}
+
+// normalizeOrigins trims a comma separated origin list and drops empty entries
+func normalizeOrigins(origins string) string {
+	var out []string
+	for _, o := range strings.Split(origins, ",") {
+		if o = strings.TrimSpace(o); o != "" {
+			out = append(out, o)
+		}
+	}
+	return strings.Join(out, ",")
+}
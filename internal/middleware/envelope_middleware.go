@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+
+	"fiber-gorm-api/internal/dto"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// EnvelopeMediaType is the Accept media type asking for enveloped responses
+const EnvelopeMediaType = "application/vnd.mylo.envelope+json"
+
+// Envelope wraps JSON responses in a dto.Envelope, {"data": ..., "meta": {...}, "errors": [...]},
+// for clients sending Accept: application/vnd.mylo.envelope+json, or every client with
+// RESPONSE_ENVELOPE=true. Meta carries the request ID (see requestid, also in X-Request-ID)
+// and the X-Next-Cursor of lists. Requests under the skipped path prefixes keep the shape
+// their format prescribes (GraphQL, JWKS, the swagger spec).
+func Envelope(skip ...string) fiber.Handler {
+	always := os.Getenv("RESPONSE_ENVELOPE") == "true"
+	return func(c *fiber.Ctx) error {
+		for _, prefix := range skip {
+			if strings.HasPrefix(c.Path(), prefix) {
+				return c.Next()
+			}
+		}
+		if !always && !strings.Contains(c.Get(fiber.HeaderAccept), EnvelopeMediaType) {
+			return c.Next()
+		}
+
+		err := c.Next()
+		wrapResponse(c)
+		return err
+	}
+}
+
+// wrapResponse replaces a JSON response body with its dto.Envelope
+func wrapResponse(c *fiber.Ctx) {
+	resp := c.Response()
+	if !strings.HasPrefix(string(resp.Header.ContentType()), fiber.MIMEApplicationJSON) || len(resp.Body()) == 0 {
+		return
+	}
+	body := json.RawMessage(append([]byte(nil), resp.Body()...))
+	if !json.Valid(body) {
+		return
+	}
+
+	status := resp.StatusCode()
+	envelope := dto.Envelope{
+		Data: body,
+		Meta: dto.EnvelopeMeta{
+			RequestID:  string(resp.Header.Peek(fiber.HeaderXRequestID)),
+			Status:     status,
+			NextCursor: string(resp.Header.Peek("X-Next-Cursor")),
+		},
+	}
+	if status >= fiber.StatusBadRequest {
+		envelope.Data = json.RawMessage("null")
+		envelope.Errors = []dto.EnvelopeError{envelopeError(body)}
+	}
+
+	raw, err := json.Marshal(envelope)
+	if err != nil {
+		return
+	}
+	resp.SetBodyRaw(raw)
+}
+
+// envelopeError turns an error body, usually a dto.ErrorResponse, into a dto.EnvelopeError
+func envelopeError(body json.RawMessage) dto.EnvelopeError {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return dto.EnvelopeError{Message: string(body)}
+	}
+	out := dto.EnvelopeError{}
+	out.Message, _ = fields["error"].(string)
+	out.Code, _ = fields["code"].(string)
+	delete(fields, "error")
+	delete(fields, "code")
+	if len(fields) > 0 {
+		out.Details = fields
+	}
+	return out
+}
@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"fiber-gorm-api/internal/dto"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+)
+
+func TestEnvelope(t *testing.T) {
+	app := fiber.New()
+	app.Use(requestid.New())
+	app.Use(Envelope("/raw"))
+	app.Get("/list", func(c *fiber.Ctx) error {
+		c.Set("X-Next-Cursor", "abc")
+		return c.JSON([]fiber.Map{{"id": 1}})
+	})
+	app.Get("/invalid", func(c *fiber.Ctx) error {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error": "unknown subscriber_type", "code": "invalid_subscriber_type", "invalid": []string{"astronaut"},
+		})
+	})
+	app.Get("/raw", func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{"keys": []string{}})
+	})
+
+	get := func(url string, enveloped bool) (int, map[string]json.RawMessage, string) {
+		req := httptest.NewRequest("GET", url, nil)
+		if enveloped {
+			req.Header.Set("Accept", EnvelopeMediaType)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Request error: %v", err)
+		}
+		var body map[string]json.RawMessage
+		json.NewDecoder(resp.Body).Decode(&body)
+		return resp.StatusCode, body, resp.Header.Get("X-Request-ID")
+	}
+
+	// without asking, responses keep their shape
+	if _, body, _ := get("/invalid", false); string(body["error"]) != `"unknown subscriber_type"` {
+		t.Errorf("Expected the plain error body, got %v", body)
+	}
+
+	_, body, requestID := get("/list", true)
+	var meta dto.EnvelopeMeta
+	json.Unmarshal(body["meta"], &meta)
+	if string(body["data"]) != `[{"id":1}]` || meta.Status != 200 || meta.NextCursor != "abc" || meta.RequestID != requestID || requestID == "" {
+		t.Errorf("Expected the list in data with its meta, got %s %+v", body["data"], meta)
+	}
+	if _, ok := body["errors"]; ok {
+		t.Errorf("Expected no errors on a success, got %s", body["errors"])
+	}
+
+	status, body, _ := get("/invalid", true)
+	var errs []dto.EnvelopeError
+	json.Unmarshal(body["errors"], &errs)
+	if status != 422 || string(body["data"]) != "null" || len(errs) != 1 {
+		t.Fatalf("Expected null data and one error, got %d %v", status, body)
+	}
+	if errs[0].Message != "unknown subscriber_type" || errs[0].Code != "invalid_subscriber_type" || errs[0].Details["invalid"] == nil {
+		t.Errorf("Expected the error message, code and details, got %+v", errs[0])
+	}
+
+	if _, body, _ := get("/raw", true); body["keys"] == nil {
+		t.Errorf("Expected a skipped path to keep its shape, got %v", body)
+	}
+}
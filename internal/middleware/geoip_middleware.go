@@ -0,0 +1,17 @@
+package middleware
+
+import (
+	"fiber-gorm-api/internal/geoip"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ClientIP attaches the IP address of the client to the request context, so the subscriber
+// revisions and admin activity written while handling it are located where it came from (see
+// geoip.FromContext). The lookup only happens when a record is written.
+func ClientIP() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.SetUserContext(geoip.WithIP(c.UserContext(), c.IP()))
+		return c.Next()
+	}
+}
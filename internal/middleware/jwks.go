@@ -0,0 +1,192 @@
+package middleware
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"fiber-gorm-api/internal/dto"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// jwksRefreshInterval limits how often an unknown kid makes us fetch the key set again
+const jwksRefreshInterval = time.Minute
+
+// jwkPublicKey decodes a JWK, or fails for key types we don't verify with
+func jwkPublicKey(k dto.JWK) (crypto.PublicKey, error) {
+	switch {
+	case k.Kty == "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case k.Kty == "OKP" && k.Crv == "Ed25519":
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key size")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %s", k.Kty)
+	}
+}
+
+// newJWK encodes an RSA or Ed25519 public key as a JWK identified by its thumbprint
+func newJWK(key crypto.PublicKey) dto.JWK {
+	var k dto.JWK
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		k = dto.JWK{
+			Kty: "RSA",
+			Alg: jwt.SigningMethodRS256.Alg(),
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}
+	case ed25519.PublicKey:
+		k = dto.JWK{
+			Kty: "OKP",
+			Alg: jwt.SigningMethodEdDSA.Alg(),
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(key),
+		}
+	}
+	k.Use = "sig"
+	k.Kid = jwkThumbprint(k)
+	return k
+}
+
+// jwkThumbprint is the RFC 7638 thumbprint of k: the SHA-256 of its required members in
+// lexicographic order, which makes a stable kid that needs no configuration
+func jwkThumbprint(k dto.JWK) string {
+	var canonical string
+	if k.Kty == "RSA" {
+		canonical = fmt.Sprintf(`{"e":%q,"kty":"RSA","n":%q}`, k.E, k.N)
+	} else {
+		canonical = fmt.Sprintf(`{"crv":%q,"kty":"OKP","x":%q}`, k.Crv, k.X)
+	}
+	sum := sha256.Sum256([]byte(canonical))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// localKeySet verifies tokens with our own public keys: the signing key's and those of
+// retired keys whose tokens haven't expired yet
+type localKeySet struct {
+	keys map[string]crypto.PublicKey
+	// fallback verifies tokens without a kid, issued before keys had one
+	fallback crypto.PublicKey
+}
+
+// keyfunc is the jwt.Keyfunc picking the key of token's kid
+func (s *localKeySet) keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, ok := token.Header["kid"].(string)
+	if !ok && s.fallback != nil {
+		return s.fallback, nil
+	}
+	if key, ok := s.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown key %q", kid)
+}
+
+// remoteKeySet verifies tokens with the keys published at a JWKS URL. Keys are cached and
+// fetched again when a token names a kid we don't know, at most once per jwksRefreshInterval.
+type remoteKeySet struct {
+	url    string
+	client *http.Client
+
+	mu      sync.Mutex
+	keys    map[string]crypto.PublicKey
+	fetched time.Time
+}
+
+func newRemoteKeySet(url string) *remoteKeySet {
+	return &remoteKeySet{url: url, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// keyfunc is the jwt.Keyfunc picking the key of token's kid
+func (s *remoteKeySet) keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if key, ok := s.keys[kid]; ok {
+		return key, nil
+	}
+	if time.Since(s.fetched) < jwksRefreshInterval {
+		return nil, fmt.Errorf("unknown key %q", kid)
+	}
+	if err := s.refresh(); err != nil {
+		log.Printf("[WARN] Could not fetch JWKS from %s: %v", s.url, err)
+		return nil, err
+	}
+	if key, ok := s.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown key %q", kid)
+}
+
+// refresh replaces the cached keys with the ones currently published; called with mu held
+func (s *remoteKeySet) refresh() error {
+	s.fetched = time.Now()
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.client.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	var set dto.JWKSResponse
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return err
+	}
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		key, err := jwkPublicKey(k)
+		if err != nil {
+			continue // e.g. encryption keys published alongside
+		}
+		keys[k.Kid] = key
+	}
+	s.keys = keys
+	return nil
+}
+
+// PublicJWKS returns the public keys of our signing and retired keys, for services verifying
+// our tokens; empty with HS256, whose secret can't be published
+func PublicJWKS() dto.JWKSResponse {
+	keys := jwtConfig().jwks
+	if keys == nil {
+		keys = []dto.JWK{}
+	}
+	return dto.JWKSResponse{Keys: keys}
+}
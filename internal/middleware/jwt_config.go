@@ -0,0 +1,222 @@
+package middleware
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/session"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// errNoSigningKey is returned by GenerateJWT when tokens are only verified here (JWT_JWKS_URL
+// without JWT_PRIVATE_KEY_FILE)
+var errNoSigningKey = errors.New("no JWT signing key configured")
+
+// jwtSettings is how admin JWTs are signed and verified
+type jwtSettings struct {
+	method    jwt.SigningMethod
+	signKey   interface{} // []byte, *rsa.PrivateKey or ed25519.PrivateKey; nil if we can't sign
+	kid       string      // set in the header of signed tokens, empty with HS256
+	verifyKey jwt.Keyfunc
+	jwks      []dto.JWK // our public keys, published at /.well-known/jwks.json
+	ttl       time.Duration
+	issuer    string
+	audience  string
+}
+
+var (
+	jwtCfg     *jwtSettings
+	jwtCfgOnce sync.Once
+)
+
+// jwtConfig loads the JWT settings from the environment on first use:
+//
+//   - JWT_ALGORITHM: HS256 (default), RS256 or EdDSA
+//   - JWT_USER_SECRET_KEY: the HS256 secret
+//   - JWT_PRIVATE_KEY_FILE: PEM private key signing RS256/EdDSA tokens
+//   - JWT_PUBLIC_KEY_FILE: PEM public key verifying them, by default the private key's
+//   - JWT_PREVIOUS_PUBLIC_KEY_FILES: comma-separated PEM public keys of retired signing keys,
+//     still accepted and published until their tokens expire
+//   - JWT_JWKS_URL: verify with the keys of this JWKS instead, picked by the token's kid
+//   - JWT_TTL_SECONDS: token lifetime, by default the session's max lifetime (7 days)
+//   - JWT_ISSUER, JWT_AUDIENCE: set as iss/aud and required by RequireJWT when not empty
+func jwtConfig() *jwtSettings {
+	jwtCfgOnce.Do(func() {
+		cfg, err := loadJWTSettings()
+		if err != nil {
+			log.Fatalf("Invalid JWT configuration: %v", err)
+		}
+		jwtCfg = cfg
+	})
+	return jwtCfg
+}
+
+func loadJWTSettings() (*jwtSettings, error) {
+	cfg := &jwtSettings{
+		ttl:      session.MaxLifetime(),
+		issuer:   os.Getenv("JWT_ISSUER"),
+		audience: os.Getenv("JWT_AUDIENCE"),
+	}
+	if s := os.Getenv("JWT_TTL_SECONDS"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("JWT_TTL_SECONDS must be a positive number of seconds, got %q", s)
+		}
+		cfg.ttl = time.Duration(n) * time.Second
+	}
+
+	switch alg := os.Getenv("JWT_ALGORITHM"); alg {
+	case "", "HS256":
+		secret := os.Getenv("JWT_USER_SECRET_KEY")
+		if secret == "" {
+			secret = "devsecret"
+		}
+		cfg.method = jwt.SigningMethodHS256
+		cfg.signKey = []byte(secret)
+		cfg.verifyKey = func(*jwt.Token) (interface{}, error) { return []byte(secret), nil }
+		return cfg, nil
+	case "RS256":
+		cfg.method = jwt.SigningMethodRS256
+	case "EdDSA":
+		cfg.method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unknown JWT_ALGORITHM %q, expected HS256, RS256 or EdDSA", alg)
+	}
+
+	keys := &localKeySet{keys: map[string]crypto.PublicKey{}}
+	addKey := func(key crypto.PublicKey) string {
+		k := newJWK(key)
+		if _, ok := keys.keys[k.Kid]; !ok {
+			keys.keys[k.Kid] = key
+			cfg.jwks = append(cfg.jwks, k)
+		}
+		return k.Kid
+	}
+
+	if path := os.Getenv("JWT_PRIVATE_KEY_FILE"); path != "" {
+		key, err := readPrivateKey(path, cfg.method)
+		if err != nil {
+			return nil, err
+		}
+		cfg.signKey = key
+		cfg.kid = addKey(key.Public())
+		keys.fallback = key.Public()
+	}
+	if path := os.Getenv("JWT_PUBLIC_KEY_FILE"); path != "" {
+		key, err := readPublicKey(path, cfg.method)
+		if err != nil {
+			return nil, err
+		}
+		addKey(key)
+		if keys.fallback == nil {
+			keys.fallback = key
+		}
+	}
+	for _, path := range strings.Split(os.Getenv("JWT_PREVIOUS_PUBLIC_KEY_FILES"), ",") {
+		if path = strings.TrimSpace(path); path == "" {
+			continue
+		}
+		key, err := readPublicKey(path, cfg.method)
+		if err != nil {
+			return nil, err
+		}
+		addKey(key)
+	}
+
+	if url := os.Getenv("JWT_JWKS_URL"); url != "" {
+		cfg.verifyKey = newRemoteKeySet(url).keyfunc
+	} else if len(keys.keys) > 0 {
+		cfg.verifyKey = keys.keyfunc
+	} else {
+		return nil, fmt.Errorf("%s needs JWT_PRIVATE_KEY_FILE, JWT_PUBLIC_KEY_FILE or JWT_JWKS_URL", cfg.method.Alg())
+	}
+	return cfg, nil
+}
+
+// parserOptions are the checks RequireJWT applies besides the signature and expiry
+func (cfg *jwtSettings) parserOptions() []jwt.ParserOption {
+	opts := []jwt.ParserOption{jwt.WithValidMethods([]string{cfg.method.Alg()})}
+	if cfg.issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.issuer))
+	}
+	if cfg.audience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.audience))
+	}
+	return opts
+}
+
+// readPEM returns the DER bytes of the first PEM block of the file at path
+func readPEM(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s is not a PEM file", path)
+	}
+	return block.Bytes, nil
+}
+
+// readPrivateKey reads a PKCS#8 (or, for RSA, PKCS#1) private key matching method
+func readPrivateKey(path string, method jwt.SigningMethod) (crypto.Signer, error) {
+	der, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		if rsaKey, rsaErr := x509.ParsePKCS1PrivateKey(der); rsaErr == nil {
+			key = rsaKey
+		} else {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		if method == jwt.SigningMethodRS256 {
+			return k, nil
+		}
+	case ed25519.PrivateKey:
+		if method == jwt.SigningMethodEdDSA {
+			return k, nil
+		}
+	}
+	return nil, fmt.Errorf("%s is not a %s private key", path, method.Alg())
+}
+
+// readPublicKey reads a PKIX public key matching method
+func readPublicKey(path string, method jwt.SigningMethod) (crypto.PublicKey, error) {
+	der, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	switch key.(type) {
+	case *rsa.PublicKey:
+		if method == jwt.SigningMethodRS256 {
+			return key, nil
+		}
+	case ed25519.PublicKey:
+		if method == jwt.SigningMethodEdDSA {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("%s is not a %s public key", path, method.Alg())
+}
@@ -0,0 +1,209 @@
+package middleware
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"fiber-gorm-api/internal/dto"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// useJWTEnv loads the JWT settings from env for the rest of the test
+func useJWTEnv(t *testing.T, env map[string]string) {
+	original := jwtConfig()
+	for k, v := range env {
+		t.Setenv(k, v)
+	}
+	cfg, err := loadJWTSettings()
+	if err != nil {
+		t.Fatalf("Invalid JWT settings: %v", err)
+	}
+	jwtCfg = cfg
+	t.Cleanup(func() { jwtCfg = original })
+}
+
+// writePrivateKey stores key as a PKCS#8 PEM file and returns its path
+func writePrivateKey(t *testing.T, key interface{}) string {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "jwt.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	return path
+}
+
+// tokenStatus is the status RequireJWT answers for token
+func tokenStatus(t *testing.T, token string) int {
+	req := httptest.NewRequest("GET", "/test-jwt", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := setupJWTTestApp().Test(req)
+	if err != nil {
+		t.Fatalf("Request error: %v", err)
+	}
+	return resp.StatusCode
+}
+
+func TestAsymmetricJWT(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	for alg, key := range map[string]interface{}{"RS256": rsaKey, "EdDSA": edKey} {
+		t.Run(alg, func(t *testing.T) {
+			useJWTEnv(t, map[string]string{
+				"JWT_ALGORITHM":        alg,
+				"JWT_PRIVATE_KEY_FILE": writePrivateKey(t, key),
+				"JWT_TTL_SECONDS":      "600",
+			})
+
+			token, err := GenerateJWT("someSessionKey")
+			if err != nil {
+				t.Fatalf("Failed to generate token: %v", err)
+			}
+			parsed, err := jwt.Parse(token, jwtConfig().verifyKey)
+			if err != nil || parsed.Method.Alg() != alg {
+				t.Fatalf("Expected a valid %s token, got %v", alg, err)
+			}
+			exp, _ := parsed.Claims.GetExpirationTime()
+			if d := time.Until(exp.Time); d > 10*time.Minute || d < 9*time.Minute {
+				t.Errorf("Expected the token to expire in 10 minutes, got %s", d)
+			}
+
+			// an HS256 token must not pass, whatever it is signed with
+			hs, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"session_key": "x"}).SignedString([]byte("devsecret"))
+			if status := tokenStatus(t, hs); status != http.StatusUnauthorized {
+				t.Errorf("Expected 401 for an HS256 token, got %d", status)
+			}
+		})
+	}
+}
+
+func TestIssuerAndAudience(t *testing.T) {
+	useJWTEnv(t, map[string]string{"JWT_ISSUER": "https://id.example.com", "JWT_AUDIENCE": "mylocal-api"})
+
+	token, err := GenerateJWT("someSessionKey")
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+	if _, err := jwt.Parse(token, jwtConfig().verifyKey, jwtConfig().parserOptions()...); err != nil {
+		t.Errorf("Expected the generated token to pass, got %v", err)
+	}
+
+	for name, claims := range map[string]jwt.MapClaims{
+		"no issuer":      {"aud": "mylocal-api"},
+		"wrong issuer":   {"iss": "https://evil.example.com", "aud": "mylocal-api"},
+		"wrong audience": {"iss": "https://id.example.com", "aud": "another-api"},
+	} {
+		claims["session_key"] = "someSessionKey"
+		claims["exp"] = jwt.NewNumericDate(time.Now().Add(time.Hour))
+		ss, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("devsecret"))
+		if status := tokenStatus(t, ss); status != http.StatusUnauthorized {
+			t.Errorf("%s: expected 401, got %d", name, status)
+		}
+	}
+}
+
+func TestJWKSVerification(t *testing.T) {
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	fetches := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		k := newJWK(&key.PublicKey)
+		k.Kid = "key-1"
+		json.NewEncoder(w).Encode(dto.JWKSResponse{Keys: []dto.JWK{k}})
+	}))
+	defer server.Close()
+
+	useJWTEnv(t, map[string]string{"JWT_ALGORITHM": "RS256", "JWT_JWKS_URL": server.URL})
+
+	if _, err := GenerateJWT("someSessionKey"); err != errNoSigningKey {
+		t.Errorf("Expected errNoSigningKey without a private key, got %v", err)
+	}
+
+	sign := func(kid string) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+			"session_key": "someSessionKey",
+			"exp":         jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		})
+		token.Header["kid"] = kid
+		ss, _ := token.SignedString(key)
+		return ss
+	}
+
+	if _, err := jwt.Parse(sign("key-1"), jwtConfig().verifyKey); err != nil {
+		t.Errorf("Expected the JWKS key to verify the token, got %v", err)
+	}
+	if _, err := jwt.Parse(sign("key-2"), jwtConfig().verifyKey); err == nil {
+		t.Error("Expected an unknown kid to fail")
+	}
+	if fetches != 1 {
+		t.Errorf("Expected the key set to be fetched once, got %d", fetches)
+	}
+}
+
+// writePublicKey stores key as a PKIX PEM file and returns its path
+func writePublicKey(t *testing.T, key interface{}) string {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "jwt.pub.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	return path
+}
+
+func TestKeyRotation(t *testing.T) {
+	oldPub, oldKey, _ := ed25519.GenerateKey(rand.Reader)
+	_, newKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	useJWTEnv(t, map[string]string{"JWT_ALGORITHM": "EdDSA", "JWT_PRIVATE_KEY_FILE": writePrivateKey(t, oldKey)})
+	oldToken, err := GenerateJWT("someSessionKey")
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+
+	// rotate: sign with the new key, keep accepting the old one
+	useJWTEnv(t, map[string]string{
+		"JWT_PRIVATE_KEY_FILE":          writePrivateKey(t, newKey),
+		"JWT_PREVIOUS_PUBLIC_KEY_FILES": writePublicKey(t, oldPub),
+	})
+	newToken, err := GenerateJWT("someSessionKey")
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+
+	jwks := PublicJWKS()
+	if len(jwks.Keys) != 2 {
+		t.Fatalf("Expected 2 published keys, got %d", len(jwks.Keys))
+	}
+	for i, token := range []string{newToken, oldToken} {
+		parsed, err := jwt.Parse(token, jwtConfig().verifyKey)
+		if err != nil {
+			t.Fatalf("Expected token %d to stay valid across the rotation, got %v", i, err)
+		}
+		if parsed.Header["kid"] != jwks.Keys[i].Kid {
+			t.Errorf("Expected token %d to carry kid %s, got %v", i, jwks.Keys[i].Kid, parsed.Header["kid"])
+		}
+	}
+
+	// once retired, the old key's tokens are rejected
+	useJWTEnv(t, map[string]string{"JWT_PREVIOUS_PUBLIC_KEY_FILES": ""})
+	if _, err := jwt.Parse(oldToken, jwtConfig().verifyKey); err == nil {
+		t.Error("Expected a token of a retired key to fail")
+	}
+}
@@ -1,77 +1,144 @@
 package middleware
 
 import (
-	"os"
+	"context"
+	"errors"
+	"log"
 	"strings"
 	"time"
 
-	redisclient "fiber-gorm-api/internal/redis"
+	"fiber-gorm-api/internal/resilience"
+	"fiber-gorm-api/internal/session"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// SessionLocalKey is the fiber.Ctx Locals key under which RequireJWT stores the *session.Session
+const SessionLocalKey = "session"
+
+// CurrentSession returns the session attached by RequireJWT, or nil if the route isn't protected
+func CurrentSession(c *fiber.Ctx) *session.Session {
+	sess, _ := c.Locals(SessionLocalKey).(*session.Session)
+	return sess
+}
+
+// errSessionMoved refuses a session used from a client unlike the one that signed in, with
+// SESSION_FINGERPRINT=enforce: the token may have been stolen
+var errSessionMoved = errors.New("Session used from another device, please sign in again")
+
 // RequireJWT is a Fiber middleware that checks for a valid JWT in Authorization header
 func RequireJWT(c *fiber.Ctx) error {
-	authHeader := c.Get("Authorization")
+	sess, err := sessionFromAuthorization(c.UserContext(), c.Get("Authorization"), c.IP(), c.Get(fiber.HeaderUserAgent))
+	if errors.Is(err, resilience.ErrUnavailable) {
+		return ServiceUnavailable(c, err)
+	}
+	if errors.Is(err, errSessionMoved) {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": err.Error(), "code": "reauthentication_required"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	// Expose the session to downstream handlers
+	c.Locals(SessionLocalKey, sess)
+
+	return c.Next()
+}
+
+// sessionFromAuthorization resolves a "Bearer <jwt>" header value, sent by the client at ip with
+// userAgent, to its Redis session. Errors are safe to return to the client; while Redis is down
+// they wrap resilience.ErrUnavailable, which isn't the client's fault.
+func sessionFromAuthorization(ctx context.Context, authHeader, ip, userAgent string) (*session.Session, error) {
 	if authHeader == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Missing Authorization header"})
+		return nil, errors.New("Missing Authorization header")
 	}
 
 	tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 	if tokenString == authHeader {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid token format"})
+		return nil, errors.New("Invalid token format")
 	}
 
+	cfg := jwtConfig()
 	claims := jwt.MapClaims{}
-	secret := os.Getenv("JWT_USER_SECRET_KEY")
-	if secret == "" {
-		secret = "devsecret"
-	}
-
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		return []byte(secret), nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, claims, cfg.verifyKey, cfg.parserOptions()...)
 	if err != nil || !token.Valid {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid or expired token"})
+		return nil, errors.New("Invalid or expired token")
 	}
 
 	sessionKey, ok := claims["session_key"].(string)
 	if !ok {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Session key missing in token"})
+		return nil, errors.New("Session key missing in token")
 	}
 
-	// Check Redis for session
-	sessionVal, err := redisclient.GetValue("session:" + sessionKey)
+	// Check Redis for session (or the in-process cache, with SESSION_CACHE_TTL_SECONDS)
+	sess, err := session.Lookup(ctx, sessionKey)
+	if errors.Is(err, session.ErrNotFound) {
+		return nil, errors.New("Session not found or expired")
+	}
+	if errors.Is(err, resilience.ErrUnavailable) {
+		return nil, err
+	}
 	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Session not found or expired"})
+		return nil, errors.New("Session invalid or not found")
 	}
-	if sessionVal == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Session invalid or not found"})
+	if err := checkFingerprint(sess, ip, userAgent); err != nil {
+		return nil, err
 	}
 
-	return c.Next()
+	// Sliding expiry: activity keeps the session alive up to its max lifetime
+	if err := session.Touch(ctx, sess); errors.Is(err, session.ErrNotFound) {
+		return nil, errors.New("Session not found or expired")
+	} else if err != nil {
+		log.Printf("[WARN] Could not refresh session: %v", err)
+	}
+	return sess, nil
 }
 
-// GenerateJWT creates a new JWT with the given session key, valid for 1 day
+// checkFingerprint compares the client at ip with userAgent to the one sess signed in from, as
+// SESSION_FINGERPRINT says: a drastic change (see session.FingerprintChanged) is logged, or
+// refused with errSessionMoved
+func checkFingerprint(sess *session.Session, ip, userAgent string) error {
+	mode := session.FingerprintMode()
+	if mode == session.FingerprintOff || !session.FingerprintChanged(sess.Fingerprint, session.Fingerprint(ip, userAgent)) {
+		return nil
+	}
+	log.Printf("[WARN] Session of org %d signed in from %s used from another browser and network, %s (SESSION_FINGERPRINT=%s)", sess.OrgID, sess.IP, ip, mode)
+	if mode == session.FingerprintEnforce {
+		return errSessionMoved
+	}
+	return nil
+}
+
+// GenerateJWT creates a new JWT with the given session key, valid for JWT_TTL_SECONDS (the
+// session's max lifetime by default) and signed as configured by JWT_ALGORITHM
 func GenerateJWT(sessionKey string) (string, error) {
-	secret := os.Getenv("JWT_USER_SECRET_KEY")
-	if secret == "" {
-		secret = "devsecret"
+	cfg := jwtConfig()
+	if cfg.signKey == nil {
+		return "", errNoSigningKey
 	}
 
 	// Use explicit time.Now() instead of jwt.TimeFunc
 	now := time.Now()
-	exp := now.Add(24 * time.Hour)
+	exp := now.Add(cfg.ttl)
 
 	claims := jwt.MapClaims{
 		"session_key": sessionKey,
 		"exp":         jwt.NewNumericDate(exp),
 		"iat":         jwt.NewNumericDate(now),
 	}
+	if cfg.issuer != "" {
+		claims["iss"] = cfg.issuer
+	}
+	if cfg.audience != "" {
+		claims["aud"] = cfg.audience
+	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	ss, err := token.SignedString([]byte(secret))
+	token := jwt.NewWithClaims(cfg.method, claims)
+	if cfg.kid != "" {
+		token.Header["kid"] = cfg.kid
+	}
+	ss, err := token.SignedString(cfg.signKey)
 	if err != nil {
 		return "", err
 	}
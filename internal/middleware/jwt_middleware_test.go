@@ -2,7 +2,9 @@ package middleware
 
 import (
 	"encoding/json"
+	"fiber-gorm-api/internal/models"
 	redisclient "fiber-gorm-api/internal/redis"
+	"fiber-gorm-api/internal/session"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -11,6 +13,7 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/valyala/fasthttp"
 )
 
 // We'll define a minimal next handler for our tests.
@@ -23,7 +26,7 @@ func nextHandler(c *fiber.Ctx) error {
 // Setup a fiber app that uses RequireJWT and nextHandler
 // so we can test different token scenarios.
 func setupJWTTestApp() *fiber.App {
-	redisclient.InitRedis("session") // rely on real Redis from Docker Compose
+	redisclient.InitRedis("session") // in-process unless REDIS_HOST is set
 
 	app := fiber.New()
 	app.Use(RequireJWT)
@@ -131,7 +134,7 @@ func TestValidTokenWithSession(t *testing.T) {
 	// 1) Create a session in Redis
 	sessionID := "validSessionTest"
 	userProfile := `{"email":"valid@example.com"}`
-	if err := redisclient.SetValue("session:"+sessionID, userProfile, 0); err != nil {
+	if err := redisclient.SetValue(redisclient.Ctx, "session:"+sessionID, userProfile, 0); err != nil {
 		t.Fatalf("failed to store session in redis: %v", err)
 	}
 
@@ -161,6 +164,48 @@ func TestValidTokenWithSession(t *testing.T) {
 	}
 }
 
+func TestSessionFingerprint(t *testing.T) {
+	app := setupJWTTestApp()
+	sess, err := session.Create(redisclient.Ctx, "fingerprint@example.com", models.DefaultOrgID, "203.0.113.7", "Firefox/128.0")
+	if err != nil {
+		t.Fatalf("Could not create session: %v", err)
+	}
+	token, err := generateTestJWT(sess.ID)
+	if err != nil {
+		t.Fatalf("Failed to generate JWT: %v", err)
+	}
+	// requests made by app.Test come from 0.0.0.0, another network than the sign-in's
+	request := func(userAgent string) (int, string) {
+		t.Helper()
+		req := httptest.NewRequest("GET", "/test-jwt", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("User-Agent", userAgent)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Request error: %v", err)
+		}
+		var body map[string]interface{}
+		_ = json.NewDecoder(resp.Body).Decode(&body)
+		code, _ := body["code"].(string)
+		return resp.StatusCode, code
+	}
+
+	for _, mode := range []string{"off", "log"} {
+		t.Setenv("SESSION_FINGERPRINT", mode)
+		if status, _ := request("curl/8.5.0"); status != http.StatusOK {
+			t.Errorf("Expected another client let through with %s, got %d", mode, status)
+		}
+	}
+
+	t.Setenv("SESSION_FINGERPRINT", "enforce")
+	if status, code := request("curl/8.5.0"); status != http.StatusUnauthorized || code != "reauthentication_required" {
+		t.Errorf("Expected 401 reauthentication_required for another browser on another network, got %d %q", status, code)
+	}
+	if status, _ := request("Firefox/128.0"); status != http.StatusOK {
+		t.Errorf("Expected the same browser let through from another network, got %d", status)
+	}
+}
+
 // TestGenerateJWT checks if the function sets session_key, exp, iat
 func TestGenerateJWT(t *testing.T) {
 	token, err := GenerateJWT("someSessionKey")
@@ -216,3 +261,38 @@ func generateTestJWT(sessionKey string) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString([]byte(secret))
 }
+
+// BenchmarkRequireJWT authenticates requests with a valid token: verifying it, then loading and
+// refreshing its session in Redis. Requests go straight to the app's handler, without a network
+// connection:
+//
+//	go test ./internal/middleware -run '^$' -bench RequireJWT -benchmem
+func BenchmarkRequireJWT(b *testing.B) {
+	app := setupJWTTestApp()
+	sess, err := session.Create(redisclient.Ctx, "bench@example.com", models.DefaultOrgID, "", "")
+	if err != nil {
+		b.Fatalf("failed to create session: %v", err)
+	}
+	token, err := GenerateJWT(sess.ID)
+	if err != nil {
+		b.Fatalf("failed to generate token: %v", err)
+	}
+	handler := app.Handler()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		var ctx fasthttp.RequestCtx
+		for pb.Next() {
+			ctx.Request.Reset()
+			ctx.Response.Reset()
+			ctx.Request.SetRequestURI("/test-jwt")
+			ctx.Request.Header.Set("Authorization", "Bearer "+token)
+			handler(&ctx)
+			if status := ctx.Response.StatusCode(); status != http.StatusOK {
+				b.Errorf("Expected 200, got %d", status)
+				return
+			}
+		}
+	})
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"encoding/json"
+	"strings"
+
+	"fiber-gorm-api/internal/i18n"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const localeLocalsKey = "locale"
+
+// Locale picks the language of a request, ?lang= or else the best match of Accept-Language
+// (see i18n.Match), for handlers (CurrentLocale) and the emails they send
+// (i18n.FromContext(c.UserContext())). The "error" of JSON error responses is translated into
+// it when its bundle has the message.
+func Locale() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		locale := i18n.Match(c.Get(fiber.HeaderAcceptLanguage))
+		if lang := i18n.Normalize(c.Query("lang")); lang != "" {
+			locale = lang
+		}
+		c.Locals(localeLocalsKey, locale)
+		c.SetUserContext(i18n.WithLocale(c.UserContext(), locale))
+
+		err := c.Next()
+		c.Vary(fiber.HeaderAcceptLanguage)
+		if locale != i18n.DefaultLocale && c.Response().StatusCode() >= fiber.StatusBadRequest {
+			translateError(c, locale)
+		}
+		return err
+	}
+}
+
+// CurrentLocale returns the locale Locale picked for the request, i18n.DefaultLocale outside it
+func CurrentLocale(c *fiber.Ctx) string {
+	if locale, ok := c.Locals(localeLocalsKey).(string); ok {
+		return locale
+	}
+	return i18n.DefaultLocale
+}
+
+// translateError rewrites the "error" message of a JSON response body into locale
+func translateError(c *fiber.Ctx, locale string) {
+	if !strings.HasPrefix(string(c.Response().Header.ContentType()), fiber.MIMEApplicationJSON) {
+		return
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal(c.Response().Body(), &body); err != nil {
+		return
+	}
+	message, ok := body["error"].(string)
+	if !ok {
+		return
+	}
+	translated := i18n.T(locale, message)
+	if translated == message {
+		return
+	}
+	body["error"] = translated
+	if raw, err := json.Marshal(body); err == nil {
+		c.Response().SetBodyRaw(raw)
+		c.Set(fiber.HeaderContentLanguage, locale)
+	}
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"fiber-gorm-api/internal/i18n"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestLocale(t *testing.T) {
+	app := fiber.New()
+	app.Use(Locale())
+	app.Get("/locale", func(c *fiber.Ctx) error {
+		return c.SendString(CurrentLocale(c) + "," + i18n.FromContext(c.UserContext()))
+	})
+	app.Get("/error", func(c *fiber.Ctx) error {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing email", "code": "missing_email"})
+	})
+
+	cases := []struct {
+		url, acceptLanguage, want string
+	}{
+		{"/locale", "", "en,en"},
+		{"/locale", "fr-CA,fr;q=0.9,en;q=0.8", "fr,fr"},
+		{"/locale", "de-DE, es;q=0.5, en;q=0.4", "es,es"},
+		{"/locale?lang=es", "fr", "es,es"},
+		{"/locale?lang=xx", "fr", "fr,fr"},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest("GET", tc.url, nil)
+		req.Header.Set("Accept-Language", tc.acceptLanguage)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Request error: %v", err)
+		}
+		buf := make([]byte, 16)
+		n, _ := resp.Body.Read(buf)
+		if got := string(buf[:n]); got != tc.want {
+			t.Errorf("%s with %q: expected %s, got %s", tc.url, tc.acceptLanguage, tc.want, got)
+		}
+	}
+
+	req := httptest.NewRequest("GET", "/error", nil)
+	req.Header.Set("Accept-Language", "fr")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Request error: %v", err)
+	}
+	var body map[string]string
+	json.NewDecoder(resp.Body).Decode(&body)
+	if body["error"] != "Adresse e-mail manquante" || body["code"] != "missing_email" {
+		t.Errorf("Expected the error translated and the code kept, got %v", body)
+	}
+	if resp.Header.Get("Content-Language") != "fr" {
+		t.Errorf("Expected Content-Language fr, got %q", resp.Header.Get("Content-Language"))
+	}
+}
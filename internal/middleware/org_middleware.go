@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"fiber-gorm-api/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// OrgHeader lets public (unauthenticated) routes name the organization by slug
+const OrgHeader = "X-Org"
+
+// OrgLocalKey is the fiber.Ctx Locals key under which ResolveOrg stores the organization id
+const OrgLocalKey = "org_id"
+
+// CurrentOrgID returns the organization every query of this request is scoped to:
+// the API key's organization, the signed-in session's, or the one resolved by ResolveOrg.
+// Anything else (including sessions issued before organizations existed) falls back to the default.
+func CurrentOrgID(c *fiber.Ctx) uint {
+	if orgID := callerOf(c).orgID(); orgID != 0 {
+		return orgID
+	}
+	if orgID, ok := c.Locals(OrgLocalKey).(uint); ok && orgID != 0 {
+		return orgID
+	}
+	return models.DefaultOrgID
+}
+
+// ResolveOrg picks the organization for public routes from the X-Org header or the
+// ?org= query param (a slug). Without either the default organization is used.
+func ResolveOrg(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		slug := RequestedOrg(c)
+		if slug == "" {
+			return c.Next()
+		}
+
+		var org models.Organization
+		if err := db.WithContext(c.UserContext()).Where("slug = ?", slug).First(&org).Error; err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Unknown organization"})
+		}
+		c.Locals(OrgLocalKey, org.ID)
+		return c.Next()
+	}
+}
+
+// RequestedOrg returns the slug of the organization a public request names in the X-Org header
+// or the ?org= query param, "" when it names none
+func RequestedOrg(c *fiber.Ctx) string {
+	if slug := c.Get(OrgHeader); slug != "" {
+		return slug
+	}
+	return c.Query("org")
+}
+
+// RequirePlatformOrg only lets members of the default organization through. It guards
+// operations spanning organizations, such as creating or deleting them.
+func RequirePlatformOrg(c *fiber.Ctx) error {
+	if CurrentOrgID(c) != models.DefaultOrgID {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Only platform admins may manage organizations"})
+	}
+	return c.Next()
+}
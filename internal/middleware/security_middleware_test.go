@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestSecurityHeaders(t *testing.T) {
+	t.Setenv("SECURITY_REFERRER_POLICY", "same-origin")
+
+	app := fiber.New()
+	app.Use(SecurityHeaders("api", APIContentSecurityPolicy, "/swagger"))
+	app.Get("/swagger/*", SecurityHeaders("swagger", SwaggerContentSecurityPolicy), func(c *fiber.Ctx) error {
+		return c.SendString("ui")
+	})
+	app.Get("/ping", func(c *fiber.Ctx) error { return c.SendString("pong") })
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/ping", nil))
+	if err != nil {
+		t.Fatalf("Request error: %v", err)
+	}
+	for header, want := range map[string]string{
+		"X-Content-Type-Options":  "nosniff",
+		"X-Frame-Options":         "DENY",
+		"Referrer-Policy":         "same-origin",
+		"Content-Security-Policy": APIContentSecurityPolicy,
+	} {
+		if got := resp.Header.Get(header); got != want {
+			t.Errorf("Expected %s %q, got %q", header, want, got)
+		}
+	}
+
+	resp, err = app.Test(httptest.NewRequest("GET", "/swagger/index.html", nil))
+	if err != nil {
+		t.Fatalf("Request error: %v", err)
+	}
+	if got := resp.Header.Get("Content-Security-Policy"); got != SwaggerContentSecurityPolicy {
+		t.Errorf("Expected the swagger CSP, got %q", got)
+	}
+}
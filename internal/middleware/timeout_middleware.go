@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strconv"
+	"time"
+
+	"fiber-gorm-api/internal/resilience"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const defaultRequestTimeout = 30 * time.Second
+
+// requestTimeout is the deadline of a request from REQUEST_TIMEOUT_SECONDS; 0 disables it
+func requestTimeout() time.Duration {
+	if n, err := strconv.Atoi(os.Getenv("REQUEST_TIMEOUT_SECONDS")); err == nil && n >= 0 {
+		return time.Duration(n) * time.Second
+	}
+	return defaultRequestTimeout
+}
+
+// RequestTimeout puts a deadline on c.UserContext(), which handlers pass to GORM and Redis,
+// so slow queries are cancelled instead of piling up. A request that ran out of time gets a
+// 503 with code request_timeout, whatever the handler answered. Streams (SSE, WebSocket) are
+// unaffected: they outlive the handler on their own context.
+func RequestTimeout() fiber.Handler {
+	timeout := requestTimeout()
+
+	return func(c *fiber.Ctx) error {
+		if timeout == 0 {
+			return c.Next()
+		}
+
+		ctx, cancel := context.WithTimeout(c.UserContext(), timeout)
+		defer cancel()
+		c.SetUserContext(ctx)
+
+		err := c.Next()
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"error": "Request timed out",
+				"code":  "request_timeout",
+			})
+		}
+		return err
+	}
+}
+
+// ServiceUnavailable writes the 503 of a request failed by a dependency that is down (Redis,
+// the email provider; see resilience.ErrUnavailable), with code service_unavailable and a
+// Retry-After telling clients when it's worth trying again
+func ServiceUnavailable(c *fiber.Ctx, err error) error {
+	retryAfter, _ := resilience.RetryAfter(err)
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(retryAfter.Seconds())+1))
+	return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+		"error": "Service temporarily unavailable, please try again shortly",
+		"code":  "service_unavailable",
+	})
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestRequestTimeout(t *testing.T) {
+	t.Setenv("REQUEST_TIMEOUT_SECONDS", "1")
+	app := fiber.New()
+	app.Use(RequestTimeout())
+	app.Get("/fast", func(c *fiber.Ctx) error {
+		if _, ok := c.UserContext().Deadline(); !ok {
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+	app.Get("/slow", func(c *fiber.Ctx) error {
+		<-c.UserContext().Done()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "query cancelled"})
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/fast", nil))
+	if err != nil {
+		t.Fatalf("Request error: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusNoContent {
+		t.Errorf("Expected 204 with a deadline on the context, got %d", resp.StatusCode)
+	}
+
+	resp, err = app.Test(httptest.NewRequest("GET", "/slow", nil), int((3 * time.Second).Milliseconds()))
+	if err != nil {
+		t.Fatalf("Request error: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusServiceUnavailable {
+		t.Errorf("Expected 503 once the deadline passed, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// WebSocketBearerProtocol is offered by browsers together with their JWT, as in
+// new WebSocket(url, ["bearer", token]), since they can't set headers on the upgrade request
+const WebSocketBearerProtocol = "bearer"
+
+// WebSocketBearer moves a JWT offered in Sec-WebSocket-Protocol to the Authorization header,
+// so the regular JWT middleware authenticates the upgrade. An existing Authorization header wins.
+func WebSocketBearer(c *fiber.Ctx) error {
+	if c.Get(fiber.HeaderAuthorization) == "" {
+		protocols := strings.Split(c.Get(fiber.HeaderSecWebSocketProtocol), ",")
+		if len(protocols) == 2 && strings.TrimSpace(protocols[0]) == WebSocketBearerProtocol {
+			c.Request().Header.Set(fiber.HeaderAuthorization, "Bearer "+strings.TrimSpace(protocols[1]))
+		}
+	}
+	return c.Next()
+}
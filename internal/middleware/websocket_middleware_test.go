@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestWebSocketBearer(t *testing.T) {
+	app := fiber.New()
+	app.Use(WebSocketBearer)
+	app.Get("/ws", func(c *fiber.Ctx) error {
+		return c.SendString(c.Get(fiber.HeaderAuthorization))
+	})
+
+	cases := []struct {
+		name, protocol, authorization, expected string
+	}{
+		{"token from subprotocol", "bearer, abc.def", "", "Bearer abc.def"},
+		{"authorization header wins", "bearer, abc.def", "Bearer header", "Bearer header"},
+		{"other subprotocols are ignored", "graphql-ws", "", ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/ws", nil)
+			req.Header.Set(fiber.HeaderSecWebSocketProtocol, tc.protocol)
+			if tc.authorization != "" {
+				req.Header.Set(fiber.HeaderAuthorization, tc.authorization)
+			}
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("Request error: %v", err)
+			}
+			body := make([]byte, 64)
+			n, _ := resp.Body.Read(body)
+			if got := string(body[:n]); got != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// AdminActivity is a sensitive action platform admins are emailed about: an admin invited, an
+// API key created, a bulk delete, a burst of failed sign-ins or signups. NotifiedAt is set once emailed,
+// on its own or in a digest.
+type AdminActivity struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	OrgID      uint       `gorm:"not null" json:"org_id"`
+	Kind       string     `gorm:"type:varchar(32);not null" json:"kind"`
+	Actor      string     `gorm:"type:varchar(255)" json:"actor"` // who did it, empty when nobody signed in
+	Summary    string     `gorm:"type:text;not null" json:"summary"`
+	Country    string     `gorm:"type:varchar(2)" json:"country,omitempty"` // where the request came from, with GeoIP enabled
+	Region     string     `gorm:"type:varchar(8)" json:"region,omitempty"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	NotifiedAt *time.Time `json:"notified_at,omitempty"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// AdminInvitation is a single-use, emailed invitation to become an admin of an organization.
+// Only the SHA-256 hash of the token is stored; the plaintext only exists in the emailed link.
+type AdminInvitation struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	OrgID      uint       `gorm:"not null;index" json:"org_id"`
+	Email      string     `gorm:"type:varchar(255);not null" json:"email"`
+	Role       string     `gorm:"type:varchar(32);not null" json:"role"`
+	TokenHash  string     `gorm:"type:char(64);not null;uniqueIndex" json:"-"`
+	InvitedBy  string     `gorm:"type:varchar(255)" json:"invited_by"`
+	ExpiresAt  time.Time  `gorm:"not null" json:"expires_at"`
+	AcceptedAt *time.Time `json:"accepted_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt  time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// Pending reports whether the invitation can still be accepted
+func (i *AdminInvitation) Pending(now time.Time) bool {
+	return i.AcceptedAt == nil && i.RevokedAt == nil && now.Before(i.ExpiresAt)
+}
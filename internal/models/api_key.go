@@ -0,0 +1,60 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// API key scopes. "admin" implies every other scope.
+const (
+	ScopeRead  = "read"  // GET on admin resources
+	ScopeWrite = "write" // create / update / delete on admin resources
+	ScopePII   = "pii"   // see raw personal data (emails) instead of masked values
+	ScopeAdmin = "admin" // manage API keys and everything else
+)
+
+// ValidScopes lists every scope an API key may be granted.
+var ValidScopes = []string{ScopeRead, ScopeWrite, ScopePII, ScopeAdmin}
+
+// ApiKey grants machine-to-machine access to /admin routes via the X-API-Key header.
+// Only the SHA-256 hash of the key is stored; the plaintext is shown once at creation.
+type ApiKey struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	OrgID      uint       `gorm:"not null;default:1;index" json:"org_id"`
+	Name       string     `gorm:"type:varchar(255);not null" json:"name"`
+	Prefix     string     `gorm:"type:varchar(16);not null" json:"prefix"`
+	KeyHash    string     `gorm:"type:char(64);not null;uniqueIndex" json:"-"`
+	Scopes     string     `gorm:"type:varchar(255);not null" json:"scopes"` // comma separated
+	CreatedBy  string     `gorm:"type:varchar(255)" json:"created_by"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt  time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// ScopeList splits the stored scopes
+func (k *ApiKey) ScopeList() []string {
+	if k.Scopes == "" {
+		return nil
+	}
+	return strings.Split(k.Scopes, ",")
+}
+
+// HasScope reports whether the key was granted scope (admin implies all)
+func (k *ApiKey) HasScope(scope string) bool {
+	for _, s := range k.ScopeList() {
+		if s == scope || s == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// Active reports whether the key can currently be used
+func (k *ApiKey) Active(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
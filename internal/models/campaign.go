@@ -0,0 +1,98 @@
+package models
+
+import "time"
+
+// Campaign statuses
+const (
+	CampaignDraft     = "draft"     // not scheduled yet
+	CampaignScheduled = "scheduled" // waiting for NextRunAt
+	CampaignSending   = "sending"   // a run is being queued
+	CampaignTesting   = "testing"   // the test group of a run was queued, waiting for TestEndsAt
+	CampaignPaused    = "paused"
+	CampaignSent      = "sent" // a one-off campaign whose run was queued
+	CampaignCancelled = "cancelled"
+)
+
+// Campaign is an email sent to the active members of a segment, or of Filter (a
+// repository.SubscriberFilter stored as JSON) without one: once at NextRunAt, or at every
+// occurrence of Recurrence, a cron expression read in Timezone, e.g. a weekly roundup. Run counts
+// the runs started, each recipient of a run being a CampaignSend. Error is why the last run
+// stopped, if it didn't finish.
+//
+// With a TestPercent, each run is an A/B test of Subject against SubjectB: that share of the
+// audience gets one or the other, and TestHours later the subject opened most (Winner) is sent
+// to the remainder.
+//
+// A campaign announcing an Event (EventID) carries its calendar invite.
+type Campaign struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	OrgID      uint       `gorm:"not null;index" json:"org_id"`
+	Name       string     `gorm:"type:varchar(255);not null" json:"name"`
+	Subject    string     `gorm:"type:varchar(255);not null" json:"subject"`
+	SubjectB   string     `gorm:"type:varchar(255)" json:"subject_b,omitempty"`
+	Text       string     `gorm:"type:text" json:"text"`
+	HTML       string     `gorm:"type:text" json:"html,omitempty"`
+	SegmentID  *uint      `gorm:"index" json:"segment_id,omitempty"`
+	Filter     JSONMap    `gorm:"type:jsonb;not null;default:'{}'" json:"filter"`
+	Status     string     `gorm:"type:varchar(16);not null;default:draft;index" json:"status"`
+	Timezone   string     `gorm:"type:varchar(64);not null;default:UTC" json:"timezone"`
+	Recurrence string     `gorm:"type:varchar(128)" json:"recurrence,omitempty"`
+	NextRunAt  *time.Time `gorm:"index" json:"next_run_at,omitempty"`
+	Run        int        `gorm:"not null;default:0" json:"run"`
+	LastRunAt  *time.Time `json:"last_run_at,omitempty"`
+	SentCount  int        `gorm:"not null;default:0" json:"sent_count"` // emails queued, over every run
+	Error      string     `gorm:"type:text" json:"error,omitempty"`
+	// TestPercent is the share of the audience in the test group, split between the subjects
+	TestPercent int        `gorm:"not null;default:0" json:"test_percent,omitempty"`
+	TestHours   int        `gorm:"not null;default:0" json:"test_hours,omitempty"`
+	TestEndsAt  *time.Time `json:"test_ends_at,omitempty"`
+	Winner      string     `gorm:"type:varchar(1)" json:"winner,omitempty"` // of the current run
+	EventID     *uint      `gorm:"index:campaigns_event_id_idx" json:"event_id,omitempty"`
+	CreatedBy   string     `gorm:"type:varchar(255)" json:"created_by"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// CampaignSend is the email of one subscriber in a run of a campaign. Its unique index keeps
+// anyone from being queued the same run twice, whatever the dispatchers do. Variant is the
+// subject it got, "a" or "b", Test whether it was part of the test group of an A/B test.
+type CampaignSend struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	CampaignID   uint       `gorm:"not null;uniqueIndex:campaign_sends_campaign_id_run_subscriber_id_idx,priority:1" json:"campaign_id"`
+	Run          int        `gorm:"not null;uniqueIndex:campaign_sends_campaign_id_run_subscriber_id_idx,priority:2" json:"run"`
+	SubscriberID uint       `gorm:"not null;index;uniqueIndex:campaign_sends_campaign_id_run_subscriber_id_idx,priority:3" json:"subscriber_id"`
+	Email        string     `gorm:"type:varchar(255);not null" json:"email"`
+	Variant      string     `gorm:"type:varchar(1);not null;default:a" json:"variant"`
+	Test         bool       `gorm:"not null;default:false" json:"test"`
+	SentAt       *time.Time `json:"sent_at,omitempty"`   // handed to the provider
+	OpenedAt     *time.Time `json:"opened_at,omitempty"` // first open, or click
+	ClickedAt    *time.Time `json:"clicked_at,omitempty"`
+	CreatedAt    time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// Campaign event types
+const (
+	CampaignEventOpen  = "open"
+	CampaignEventClick = "click"
+)
+
+// CampaignLink is a link of the HTML of a campaign, tracked by redirecting its clicks
+type CampaignLink struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	CampaignID uint      `gorm:"not null;uniqueIndex:campaign_links_campaign_id_url_idx,priority:1" json:"campaign_id"`
+	URL        string    `gorm:"type:varchar(2048);not null;uniqueIndex:campaign_links_campaign_id_url_idx,priority:2" json:"url"`
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// CampaignEvent is an open or a click of the email of a campaign recipient, bots left out.
+// Every one is kept, the first ones also setting OpenedAt and ClickedAt of the CampaignSend.
+type CampaignEvent struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	CampaignID uint      `gorm:"not null;index:campaign_events_campaign_id_run_idx,priority:1" json:"campaign_id"`
+	Run        int       `gorm:"not null;index:campaign_events_campaign_id_run_idx,priority:2" json:"run"`
+	SendID     uint      `gorm:"not null;index:campaign_events_send_id_idx" json:"send_id"`
+	Type       string    `gorm:"type:varchar(8);not null" json:"type"`
+	LinkID     *uint     `json:"link_id,omitempty"`
+	UserAgent  string    `gorm:"type:varchar(255)" json:"user_agent,omitempty"`
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
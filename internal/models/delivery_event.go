@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// Delivery event kinds reported by the SendGrid Event Webhook that change a subscriber's status.
+const (
+	DeliveryEventBounce           = "bounce"
+	DeliveryEventSpamReport       = "spamreport"
+	DeliveryEventUnsubscribe      = "unsubscribe"
+	DeliveryEventGroupUnsubscribe = "group_unsubscribe"
+)
+
+// DeliveryEvent is one email provider event (delivered, bounce, spam report, ...) about a subscriber.
+// SGEventID is SendGrid's unique event id, so retried webhook deliveries are stored once.
+type DeliveryEvent struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	SubscriberID uint      `gorm:"not null;uniqueIndex:delivery_events_subscriber_event_idx" json:"subscriber_id"`
+	Event        string    `gorm:"type:varchar(32);not null" json:"event"`
+	Reason       string    `gorm:"type:text" json:"reason,omitempty"`
+	SGEventID    string    `gorm:"column:sg_event_id;type:varchar(64);not null;uniqueIndex:delivery_events_subscriber_event_idx" json:"sg_event_id"`
+	SGMessageID  string    `gorm:"column:sg_message_id;type:varchar(255)" json:"sg_message_id,omitempty"`
+	OccurredAt   time.Time `gorm:"not null" json:"occurred_at"`
+	CreatedAt    time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// EmailDeadLetter is a queued email (a sign-in code or link) that could not be sent: the provider
+// kept failing through the retries, refused it, or it expired waiting. What it carried is not
+// kept, only who it was for and why it failed.
+type EmailDeadLetter struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	OutboxEventID uint      `gorm:"not null" json:"outbox_event_id"`
+	Kind          string    `gorm:"type:varchar(32);not null" json:"kind"`
+	ToEmail       string    `gorm:"type:varchar(255);not null" json:"to_email"`
+	Attempts      int       `gorm:"not null" json:"attempts"`
+	LastError     string    `gorm:"type:text" json:"last_error"`
+	CreatedAt     time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
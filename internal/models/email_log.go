@@ -0,0 +1,78 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Statuses of a logged email: sent or failed when handed to the provider, then moved along by
+// the SendGrid Event Webhook
+const (
+	EmailStatusSent      = "sent"
+	EmailStatusFailed    = "failed"
+	EmailStatusDeferred  = "deferred"
+	EmailStatusDelivered = "delivered"
+	EmailStatusBounced   = "bounce"
+	EmailStatusDropped   = "dropped"
+)
+
+// EmailLog is one email the API sent, or tried to: its type (signin_code, confirmation, ...),
+// recipient and the provider's message id, which the webhook events of the email carry.
+type EmailLog struct {
+	ID          uint             `gorm:"primaryKey" json:"id"`
+	Type        string           `gorm:"type:varchar(32);not null" json:"type"`
+	ToEmail     string           `gorm:"type:varchar(255);not null" json:"to_email"`
+	MessageID   string           `gorm:"type:varchar(255)" json:"message_id,omitempty"`
+	Status      string           `gorm:"type:varchar(16);not null" json:"status"`
+	Error       string           `gorm:"type:text" json:"error,omitempty"`
+	Attachments EmailAttachments `gorm:"type:jsonb;not null;default:'[]'" json:"attachments"`
+	CreatedAt   time.Time        `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time        `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// EmailAttachment references a file sent with a logged email; its content isn't kept
+type EmailAttachment struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Size        int    `json:"size"`
+	SHA256      string `json:"sha256"`
+}
+
+// EmailAttachments are the files of a logged email, stored in a jsonb column
+type EmailAttachments []EmailAttachment
+
+// Value encodes a for the database; nil is stored as an empty array
+func (a EmailAttachments) Value() (driver.Value, error) {
+	if a == nil {
+		return "[]", nil
+	}
+	raw, err := json.Marshal(a)
+	if err != nil {
+		return nil, err
+	}
+	return string(raw), nil
+}
+
+// Scan decodes a jsonb column read as text or bytes
+func (a *EmailAttachments) Scan(value interface{}) error {
+	var raw []byte
+	switch v := value.(type) {
+	case nil:
+		*a = EmailAttachments{}
+		return nil
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into EmailAttachments", value)
+	}
+	out := EmailAttachments{}
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return err
+	}
+	*a = out
+	return nil
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// EmailTemplate is an email of an organization written once and filled with variables, such as
+// the name of each recipient (see package templates). Its subject, text and HTML are Go
+// templates. The HTML of one authored in Markdown or MJML is compiled from its source on save.
+type EmailTemplate struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	OrgID     uint      `gorm:"not null;index:email_templates_org_id_idx" json:"org_id"`
+	Name      string    `gorm:"type:varchar(255);not null" json:"name"`
+	Subject   string    `gorm:"type:varchar(255);not null" json:"subject"`
+	Text      string    `gorm:"type:text;not null;default:''" json:"text"`
+	HTML      string    `gorm:"type:text;not null;default:''" json:"html"`
+	Format    string    `gorm:"type:varchar(16);not null;default:'html'" json:"format"`
+	Source    string    `gorm:"type:text;not null;default:''" json:"source"`
+	CreatedBy string    `gorm:"type:varchar(255)" json:"created_by"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Event is a local event of an organization, such as a market day or a meetup, announced by
+// campaigns with its calendar invite (see package calendar). StartsAt and EndsAt are stored in
+// UTC, Timezone being where the event takes place. Sequence counts its updates, for calendars
+// to replace the invite they already have.
+type Event struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	OrgID       uint      `gorm:"not null;index:events_org_id_idx" json:"org_id"`
+	Title       string    `gorm:"type:varchar(255);not null" json:"title"`
+	Description string    `gorm:"type:text;not null;default:''" json:"description"`
+	Location    string    `gorm:"type:varchar(255);not null;default:''" json:"location"`
+	URL         string    `gorm:"type:varchar(2048);not null;default:''" json:"url"`
+	StartsAt    time.Time `gorm:"not null;index:events_starts_at_idx" json:"starts_at"`
+	EndsAt      time.Time `gorm:"not null" json:"ends_at"`
+	Timezone    string    `gorm:"type:varchar(64);not null;default:UTC" json:"timezone"`
+	Sequence    int       `gorm:"not null;default:0" json:"sequence"`
+	CreatedBy   string    `gorm:"type:varchar(255)" json:"created_by"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
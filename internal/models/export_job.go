@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// Export job statuses
+const (
+	ExportPending = "pending" // waiting for the worker
+	ExportRunning = "running"
+	ExportDone    = "done" // the file can be downloaded until ExpiresAt
+	ExportFailed  = "failed"
+)
+
+// Export file formats
+const (
+	ExportFormatCSV  = "csv"
+	ExportFormatJSON = "json"
+)
+
+// ExportJob is a subscriber export generated in the background. Filters holds the
+// repository.SubscriberFilter applied, copied from the segment SegmentID when it was queued for
+// one, File where the worker wrote the result.
+type ExportJob struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	OrgID       uint       `gorm:"not null;index" json:"org_id"`
+	Format      string     `gorm:"type:varchar(8);not null" json:"format"`
+	Filters     JSONMap    `gorm:"type:jsonb;not null;default:'{}'" json:"filters"`
+	SegmentID   *uint      `gorm:"index" json:"segment_id,omitempty"`
+	Status      string     `gorm:"type:varchar(16);not null;default:pending;index" json:"status"`
+	RequestedBy string     `gorm:"type:varchar(255)" json:"requested_by"`
+	RowCount    int        `gorm:"not null;default:0" json:"row_count"`
+	File        string     `gorm:"type:varchar(512)" json:"-"`
+	Error       string     `gorm:"type:text" json:"error,omitempty"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+}
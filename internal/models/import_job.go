@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// Import job statuses
+const (
+	ImportPending = "pending" // uploaded, waiting for the worker
+	ImportRunning = "running"
+	ImportDone    = "done" // every row was attempted; FailedRows of them are in the error report
+	ImportFailed  = "failed"
+)
+
+// Import file formats, the formats of exports so an export can be imported back
+const (
+	ImportFormatCSV  = "csv"
+	ImportFormatJSON = "json"
+)
+
+// ImportJob is a file of subscribers uploaded for the worker to create or update in the
+// background. File is the upload, kept until the worker is done with it, ErrorReport the CSV
+// of the rows that failed.
+type ImportJob struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	OrgID         uint       `gorm:"not null;index" json:"org_id"`
+	Format        string     `gorm:"type:varchar(8);not null" json:"format"`
+	FileName      string     `gorm:"type:varchar(255)" json:"file_name"`
+	Status        string     `gorm:"type:varchar(16);not null;default:pending;index" json:"status"`
+	RequestedBy   string     `gorm:"type:varchar(255)" json:"requested_by"`
+	TotalRows     int        `gorm:"not null;default:0" json:"total_rows"`
+	ProcessedRows int        `gorm:"not null;default:0" json:"processed_rows"`
+	CreatedRows   int        `gorm:"not null;default:0" json:"created_rows"`
+	UpdatedRows   int        `gorm:"not null;default:0" json:"updated_rows"`
+	FailedRows    int        `gorm:"not null;default:0" json:"failed_rows"`
+	File          string     `gorm:"type:varchar(512)" json:"-"`
+	ErrorReport   string     `gorm:"type:varchar(512)" json:"-"`
+	Error         string     `gorm:"type:text" json:"error,omitempty"`
+	CreatedAt     time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	StartedAt     *time.Time `json:"started_at,omitempty"`
+	FinishedAt    *time.Time `json:"finished_at,omitempty"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
+}
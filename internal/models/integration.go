@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// Integration providers
+const (
+	ProviderMailchimp = "mailchimp"
+)
+
+// Integration connects an organization to its account at a mailing list provider. The worker
+// keeps the lists of IntegrationList in sync: new subscribers are pushed, unsubscribes pulled.
+type Integration struct {
+	ID       uint   `gorm:"primaryKey" json:"id"`
+	OrgID    uint   `gorm:"not null;uniqueIndex:integrations_org_id_provider_idx" json:"org_id"`
+	Provider string `gorm:"type:varchar(32);not null;uniqueIndex:integrations_org_id_provider_idx" json:"provider"`
+	// APIKey is never returned, only its last characters
+	APIKey    string `gorm:"type:varchar(255);not null" json:"-"`
+	Enabled   bool   `gorm:"not null" json:"enabled"`
+	CreatedBy string `gorm:"type:varchar(255)" json:"created_by"`
+	// SyncingSince is set while a worker syncs the integration, so no other one does at once
+	SyncingSince *time.Time        `json:"-"`
+	LastSyncAt   *time.Time        `json:"last_sync_at,omitempty"`
+	LastError    string            `gorm:"type:text" json:"last_error,omitempty"`
+	Lists        []IntegrationList `gorm:"constraint:OnDelete:CASCADE" json:"lists"`
+	CreatedAt    time.Time         `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt    time.Time         `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// IntegrationList maps a list at the provider (a Mailchimp audience) to the subscribers synced
+// with it: the active subscribers with SubscriberType, or all of them when empty. PushedAt and
+// PushedID are the last subscriber pushed (by updated_at, then id), PulledAt the latest
+// unsubscribe pulled.
+type IntegrationList struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	IntegrationID  uint       `gorm:"not null;index" json:"integration_id"`
+	RemoteID       string     `gorm:"type:varchar(64);not null" json:"remote_id"`
+	SubscriberType string     `gorm:"type:varchar(32)" json:"subscriber_type,omitempty"`
+	PushedAt       *time.Time `json:"pushed_at,omitempty"`
+	PushedID       uint       `gorm:"not null;default:0" json:"-"`
+	PulledAt       *time.Time `json:"pulled_at,omitempty"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// DefaultOrgID is the organization created by the migration. Existing data, admins
+// without an AdminUser row and public signups without an org slug belong to it.
+const DefaultOrgID uint = 1
+
+// Organization is one local community served by this deployment. Subscribers,
+// API keys and admin users are scoped to exactly one organization.
+type Organization struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Name      string    `gorm:"type:varchar(255);not null" json:"name"`
+	Slug      string    `gorm:"type:varchar(64);not null;uniqueIndex" json:"slug"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// Admin roles, each granting a fixed set of scopes to the admin's sessions.
+const (
+	RoleAdmin  = "admin"  // everything, including API keys, invitations and members
+	RoleEditor = "editor" // read and change subscribers, see personal data
+	RoleViewer = "viewer" // read only, personal data masked
+)
+
+// RoleScopes maps every valid role to the scopes it grants.
+var RoleScopes = map[string][]string{
+	RoleAdmin:  {ScopeAdmin},
+	RoleEditor: {ScopeRead, ScopeWrite, ScopePII},
+	RoleViewer: {ScopeRead},
+}
+
+// AdminUser assigns an admin (by sign-in email) to the organization they manage, with a role.
+type AdminUser struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	OrgID     uint      `gorm:"not null;index" json:"org_id"`
+	Email     string    `gorm:"type:varchar(255);not null;uniqueIndex" json:"email"`
+	Role      string    `gorm:"type:varchar(32);not null;default:admin" json:"role"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// RoleHasScope reports whether role grants scope (admin implies all)
+func RoleHasScope(role, scope string) bool {
+	for _, s := range RoleScopes[role] {
+		if s == scope || s == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// OutboxEvent is a side effect of a database write (cache invalidation, webhook, campaign trigger)
+// recorded in the same transaction as the write, or of a request (a sign-in email), dispatched
+// later by the background worker.
+// ProcessedAt is set once every handler succeeded; FailedAt once the retries are used up.
+type OutboxEvent struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	Topic       string     `gorm:"type:varchar(64);not null" json:"topic"`
+	OrgID       uint       `gorm:"not null" json:"org_id"`
+	Payload     string     `gorm:"type:jsonb;not null" json:"payload"`
+	Attempts    int        `gorm:"not null;default:0" json:"attempts"`
+	LastError   string     `gorm:"type:text" json:"last_error,omitempty"`
+	AvailableAt time.Time  `gorm:"not null" json:"available_at"`
+	ProcessedAt *time.Time `json:"processed_at,omitempty"`
+	FailedAt    *time.Time `json:"failed_at,omitempty"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// RestHook is a REST Hooks subscription (the convention Zapier uses): every Event of the
+// organization is POSTed to TargetURL until the hook is deleted, or the target answers 410 Gone.
+type RestHook struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	OrgID     uint      `gorm:"not null;index:rest_hooks_org_id_event_idx" json:"org_id"`
+	Event     string    `gorm:"type:varchar(64);not null;index:rest_hooks_org_id_event_idx" json:"event"`
+	TargetURL string    `gorm:"type:text;not null" json:"target_url"`
+	CreatedBy string    `gorm:"type:varchar(255)" json:"created_by"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
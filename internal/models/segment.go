@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// Segment is a saved audience of an organization: a subscriber filter (a
+// repository.SubscriberFilter stored as JSON) that campaigns and exports are targeted at by id,
+// its members computed whenever it's used.
+type Segment struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	OrgID       uint      `gorm:"not null;uniqueIndex:segments_org_id_name_idx" json:"org_id"`
+	Name        string    `gorm:"type:varchar(255);not null;uniqueIndex:segments_org_id_name_idx" json:"name"`
+	Description string    `gorm:"type:text" json:"description,omitempty"`
+	Filter      JSONMap   `gorm:"type:jsonb;not null;default:'{}'" json:"filter"`
+	CreatedBy   string    `gorm:"type:varchar(255)" json:"created_by"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
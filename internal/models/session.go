@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// Session is a signed-in device kept in the database rather than Redis, with SESSION_STORE=postgres.
+// Data is the session as JSON, the same as the value Redis would hold.
+type Session struct {
+	ID        string    `gorm:"type:varchar(64);primaryKey"`
+	Email     string    `gorm:"type:varchar(255);not null;index"`
+	Data      string    `gorm:"type:text;not null"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// ShortLink is a compact link of an organization, /l/{code} redirecting to URL. Codes are
+// unique across organizations, the redirect not knowing which one it's for. A link made for a
+// campaign counts its clicks towards it.
+type ShortLink struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	OrgID         uint       `gorm:"not null;index:short_links_org_id_idx" json:"org_id"`
+	Code          string     `gorm:"type:varchar(32);not null;uniqueIndex:short_links_code_idx" json:"code"`
+	URL           string     `gorm:"type:varchar(2048);not null" json:"url"`
+	CampaignID    *uint      `gorm:"index:short_links_campaign_id_idx" json:"campaign_id,omitempty"`
+	Clicks        int        `gorm:"not null;default:0" json:"clicks"` // bots left out
+	LastClickedAt *time.Time `json:"last_clicked_at,omitempty"`
+	CreatedBy     string     `gorm:"type:varchar(255)" json:"created_by"`
+	CreatedAt     time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt     time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Types of the custom fields of a signup form
+const (
+	FieldText     = "text"
+	FieldNumber   = "number"
+	FieldCheckbox = "checkbox"
+	FieldSelect   = "select"
+)
+
+// FieldTypes lists every valid SignupFormField.Type
+var FieldTypes = []string{FieldText, FieldNumber, FieldCheckbox, FieldSelect}
+
+// SignupForm is a public signup form of an organization, submitted to
+// /signup/forms/{slug}/submit. Each form offers its own subscriber_types and asks for its own
+// custom fields, stored in the subscriber's metadata.
+type SignupForm struct {
+	ID    uint   `gorm:"primaryKey" json:"id"`
+	OrgID uint   `gorm:"not null;uniqueIndex:signup_forms_org_id_slug_idx" json:"org_id"`
+	Slug  string `gorm:"type:varchar(64);not null;uniqueIndex:signup_forms_org_id_slug_idx" json:"slug"`
+	Name  string `gorm:"type:varchar(255);not null" json:"name"`
+	// SubscriberTypes a submission may pick among, comma separated; it gets all of them when it
+	// doesn't pick
+	SubscriberTypes string           `gorm:"type:text;not null;default:''" json:"subscriber_types"`
+	Fields          SignupFormFields `gorm:"type:jsonb;not null;default:'[]'" json:"fields"`
+	// SuccessRedirect is where browsers posting the form are sent once subscribed
+	SuccessRedirect string    `gorm:"type:text" json:"success_redirect,omitempty"`
+	Captcha         bool      `gorm:"not null;default:true" json:"captcha"` // require a captcha token when CAPTCHA_PROVIDER is set
+	CreatedBy       string    `gorm:"type:varchar(255)" json:"created_by"`
+	CreatedAt       time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt       time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TypeNames returns the subscriber_types the form offers
+func (f SignupForm) TypeNames() []string {
+	if f.SubscriberTypes == "" {
+		return []string{}
+	}
+	return strings.Split(f.SubscriberTypes, ",")
+}
+
+// SignupFormField is a custom field of a signup form, stored under Name in the metadata of the
+// subscribers submitting it
+type SignupFormField struct {
+	Name     string   `json:"name"`
+	Label    string   `json:"label,omitempty"`
+	Type     string   `json:"type"`
+	Required bool     `json:"required"`
+	Options  []string `json:"options,omitempty"` // the values a select accepts
+}
+
+// SignupFormFields are the custom fields of a form, stored in a jsonb column
+type SignupFormFields []SignupFormField
+
+// Value encodes f for the database; nil is stored as an empty array
+func (f SignupFormFields) Value() (driver.Value, error) {
+	if f == nil {
+		return "[]", nil
+	}
+	raw, err := json.Marshal(f)
+	if err != nil {
+		return nil, err
+	}
+	return string(raw), nil
+}
+
+// Scan decodes a jsonb column read as text or bytes
+func (f *SignupFormFields) Scan(value interface{}) error {
+	var raw []byte
+	switch v := value.(type) {
+	case nil:
+		*f = SignupFormFields{}
+		return nil
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into SignupFormFields", value)
+	}
+	out := SignupFormFields{}
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return err
+	}
+	*f = out
+	return nil
+}
@@ -1,14 +1,97 @@
 package models
 
-import "time"
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Subscriber delivery statuses. Only active subscribers should receive campaigns.
+const (
+	SubscriberStatusPending      = "pending"      // signed up, double opt-in not confirmed yet
+	SubscriberStatusActive       = "active"       // deliverable
+	SubscriberStatusBounced      = "bounced"      // the address hard-bounced or reported spam
+	SubscriberStatusUnsubscribed = "unsubscribed" // opted out or anonymized
+	SubscriberStatusQuarantined  = "quarantined"  // public signup scored as likely spam, awaiting review
+)
+
+// SubscriberStatuses lists every valid Subscriber.Status
+var SubscriberStatuses = []string{
+	SubscriberStatusPending,
+	SubscriberStatusActive,
+	SubscriberStatusBounced,
+	SubscriberStatusUnsubscribed,
+	SubscriberStatusQuarantined,
+}
 
 // Subscriber represents a single subscriber record.
 // A subscriber can have MANY subscriber_types records referencing it.
 type Subscriber struct {
 	ID               uint             `gorm:"primaryKey" json:"id"`
-	Email            string           `gorm:"type:varchar(255);not null" json:"email"`
+	OrgID            uint             `gorm:"not null;default:1;index;index:subscribers_org_id_email_idx,priority:1" json:"org_id"`
+	Email            string           `gorm:"type:varchar(255);not null;index:subscribers_org_id_email_idx,priority:2" json:"email"`
 	Name             string           `gorm:"type:varchar(255)" json:"name"`
 	SubscriberTypes  []SubscriberType `gorm:"foreignKey:SubscriberID" json:"subscriber_types,omitempty"`
+	Notes            []SubscriberNote `gorm:"foreignKey:SubscriberID" json:"-"` // only loaded on ?include=notes
+	Status           string           `gorm:"type:varchar(16);not null;default:active" json:"status"`
+	EmailVerifiedAt  *time.Time       `json:"email_verified_at,omitempty"`
+	ConfirmTokenHash *string          `gorm:"type:char(64);uniqueIndex" json:"-"` // double opt-in, sha256 of the emailed token
+	ConfirmSentAt    *time.Time       `json:"-"`
+	AnonymizedAt     *time.Time       `json:"anonymized_at,omitempty"`
+	Locale           string           `gorm:"type:varchar(8);not null;default:en" json:"locale"`    // language of the emails sent to them
+	Metadata         JSONMap          `gorm:"type:jsonb;not null;default:'{}'" json:"metadata"`     // free-form, set by admins
+	SpamScore        int              `gorm:"not null;default:0" json:"spam_score"`                 // of a public signup, see the spam package
+	SpamSignals      JSONMap          `gorm:"type:jsonb;not null;default:'{}'" json:"spam_signals"` // points of each spam check that fired
+	Country          string           `gorm:"type:varchar(2)" json:"country,omitempty"`             // of the IP a public signup came from, with GeoIP enabled
+	Region           string           `gorm:"type:varchar(8)" json:"region,omitempty"`              // subdivision of Country, see geoip.Location
+	UTMSource        string           `gorm:"type:varchar(100)" json:"utm_source,omitempty"`        // attribution of a public signup, see service.Attribution
+	UTMMedium        string           `gorm:"type:varchar(100)" json:"utm_medium,omitempty"`
+	UTMCampaign      string           `gorm:"type:varchar(100)" json:"utm_campaign,omitempty"`
+	Referrer         string           `gorm:"type:varchar(255)" json:"referrer,omitempty"`                 // host of the page linking to the signup form
+	ReferralCode     *string          `gorm:"type:varchar(16);uniqueIndex" json:"referral_code,omitempty"` // shared to refer others, as the ref of their signup
+	ReferredByID     *uint            `gorm:"index" json:"referred_by_id,omitempty"`                       // the subscriber whose code they signed up with
+	Version          int              `gorm:"not null;default:1" json:"version"`
 	CreatedAt        time.Time        `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt        time.Time        `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt        gorm.DeletedAt   `gorm:"index" json:"-"` // soft delete, purged by the cleanup scheduler
+}
+
+// JSONMap is a JSON object stored in a jsonb column
+type JSONMap map[string]interface{}
+
+// Value encodes m for the database; nil is stored as an empty object
+func (m JSONMap) Value() (driver.Value, error) {
+	if m == nil {
+		return "{}", nil
+	}
+	raw, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return string(raw), nil
+}
+
+// Scan decodes a jsonb column read as text or bytes
+func (m *JSONMap) Scan(value interface{}) error {
+	var raw []byte
+	switch v := value.(type) {
+	case nil:
+		*m = JSONMap{}
+		return nil
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into JSONMap", value)
+	}
+	out := JSONMap{}
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return err
+	}
+	*m = out
+	return nil
 }
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// SubscriberNote is free text an admin recorded about a subscriber (support context, call
+// summaries, ...). Author is the email of the admin who wrote it, or api-key:<prefix>.
+type SubscriberNote struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	SubscriberID uint      `gorm:"not null;index" json:"subscriber_id"`
+	Author       string    `gorm:"type:varchar(255);not null" json:"author"`
+	Text         string    `gorm:"type:text;not null" json:"text"`
+	CreatedAt    time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// Subscriber revision actions, the kind of write a revision records
+const (
+	RevisionCreated    = "created"
+	RevisionUpdated    = "updated"
+	RevisionConfirmed  = "confirmed"
+	RevisionMerged     = "merged"     // a duplicate was folded into the subscriber
+	RevisionDelivery   = "delivery"   // a bounce, spam report or unsubscribe changed the status
+	RevisionAnonymized = "anonymized" // earlier revisions are deleted with the personal data
+	RevisionRestored   = "restored"   // an earlier revision's state was applied again
+	RevisionUndeleted  = "undeleted"  // the subscriber was brought back within the undo window
+	RevisionApproved   = "approved"   // a quarantined signup was let through in review
+	RevisionSynced     = "synced"     // an unsubscribe at a mailing list provider was pulled by an integration
+)
+
+// SubscriberRevision is the state of a subscriber before and after one write, newest Version
+// last. Author is the admin's email, api-key:<prefix>, sendgrid (delivery events) or the
+// provider of an integration (synced unsubscribes), and empty for the subscriber's own actions
+// (signup, confirmation).
+type SubscriberRevision struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	SubscriberID uint      `gorm:"not null;index" json:"subscriber_id"`
+	Version      int       `gorm:"not null" json:"version"` // the subscriber's version after the write
+	Action       string    `gorm:"type:varchar(32);not null" json:"action"`
+	Author       string    `gorm:"type:varchar(255)" json:"author"`
+	Before       *string   `gorm:"type:jsonb" json:"before"` // SubscriberSnapshot, nil for created
+	After        string    `gorm:"type:jsonb;not null" json:"after"`
+	Country      string    `gorm:"type:varchar(2)" json:"country,omitempty"` // where the write came from, with GeoIP enabled
+	Region       string    `gorm:"type:varchar(8)" json:"region,omitempty"`
+	CreatedAt    time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// SubscriberSnapshot is the part of a subscriber its revisions record
+type SubscriberSnapshot struct {
+	Email           string     `json:"email"`
+	Name            string     `json:"name"`
+	Status          string     `json:"status"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	SubscriberTypes []string   `json:"subscriber_types"`
+	Metadata        JSONMap    `json:"metadata"`
+	Version         int        `json:"version"`
+}
+
+// NewSubscriberSnapshot captures s, with its preloaded subscriber_types in name order
+func NewSubscriberSnapshot(s Subscriber) SubscriberSnapshot {
+	types := make([]string, len(s.SubscriberTypes))
+	for i, t := range s.SubscriberTypes {
+		types[i] = t.Name
+	}
+	sort.Strings(types)
+	metadata := s.Metadata
+	if metadata == nil {
+		metadata = JSONMap{}
+	}
+	return SubscriberSnapshot{
+		Email:           s.Email,
+		Name:            s.Name,
+		Status:          s.Status,
+		EmailVerifiedAt: s.EmailVerifiedAt,
+		SubscriberTypes: types,
+		Metadata:        metadata,
+		Version:         s.Version,
+	}
+}
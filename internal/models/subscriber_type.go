@@ -2,12 +2,46 @@ package models
 
 import "time"
 
-// SubscriberType references the Postgres ENUM column, e.g. 'shopper', 'business', etc.
+// DefaultSubscriberTypeNames are the types every database starts with, the values of the
+// former Postgres subscriber_type ENUM
+var DefaultSubscriberTypeNames = []string{"shopper", "business", "driver", "champion", "donor", "developer"}
+
+// How often a subscriber wants to hear about one of their subscriber_types
+const (
+	FrequencyDaily   = "daily"
+	FrequencyWeekly  = "weekly" // the default
+	FrequencyMonthly = "monthly"
+)
+
+// Frequencies lists every valid SubscriberType.Frequency
+var Frequencies = []string{FrequencyDaily, FrequencyWeekly, FrequencyMonthly}
+
+// Channels a subscriber_type is delivered on
+const (
+	ChannelEmail = "email" // the default
+	ChannelSMS   = "sms"
+)
+
+// Channels lists every valid SubscriberType.Channel
+var Channels = []string{ChannelEmail, ChannelSMS}
+
+// SubscriberTypeDefinition is a name subscriber_types may take. The list lives in a table
+// rather than an ENUM so platform admins can extend it without a migration.
+type SubscriberTypeDefinition struct {
+	Name        string    `gorm:"primaryKey;type:varchar(32)" json:"name"`
+	Description string    `gorm:"type:varchar(255)" json:"description"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// SubscriberType is one of the types of a subscriber, e.g. 'shopper', 'business', etc.
 // This table references a single Subscriber record (one subscriber -> many subscriber_types).
+// Frequency and Channel are the subscriber's preferences for that type, used to target campaigns.
 type SubscriberType struct {
 	ID           uint      `gorm:"primaryKey" json:"id"`
-	SubscriberID uint      `json:"subscriber_id"`
-	Name         string    `gorm:"type:subscriber_type;not null" json:"name"` // references the custom ENUM
+	SubscriberID uint      `gorm:"index" json:"subscriber_id"`
+	Name         string    `gorm:"type:varchar(32);not null" json:"name"` // a SubscriberTypeDefinition name
+	Frequency    string    `gorm:"type:varchar(16);not null;default:weekly" json:"frequency"`
+	Channel      string    `gorm:"type:varchar(16);not null;default:email" json:"channel"`
 	CreatedAt    time.Time `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt    time.Time `gorm:"autoUpdateTime" json:"updated_at"`
 }
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// TrustedDevice is a browser or app a user (keyed by email, or phone for SMS sign-ins) chose to
+// remember after verifying a code; it signs in again without one until it expires or is
+// revoked. Only the SHA-256 hash of the device token is stored.
+type TrustedDevice struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	Email      string    `gorm:"type:varchar(255);not null;index" json:"email"`
+	TokenHash  string    `gorm:"type:char(64);not null;uniqueIndex" json:"-"`
+	IP         string    `gorm:"type:varchar(64)" json:"ip"`
+	UserAgent  string    `gorm:"type:varchar(512)" json:"user_agent"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `gorm:"not null" json:"expires_at"`
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// WebAuthnCredential is a passkey registered by a user (keyed by email).
+// Data holds the JSON-encoded webauthn.Credential, CredentialID is duplicated for lookups.
+type WebAuthnCredential struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	Email        string    `gorm:"type:varchar(255);not null;index" json:"email"`
+	CredentialID []byte    `gorm:"type:bytea;not null;uniqueIndex" json:"-"`
+	Data         string    `gorm:"type:jsonb;not null" json:"-"`
+	CreatedAt    time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
@@ -0,0 +1,132 @@
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"fiber-gorm-api/internal/geoip"
+	"fiber-gorm-api/internal/models"
+	"fiber-gorm-api/internal/outbox"
+	sendgridservice "fiber-gorm-api/internal/services"
+
+	"gorm.io/gorm"
+)
+
+// Kinds of sensitive activity platform admins are told about
+const (
+	KindAdminInvited   = "admin_invited"
+	KindAPIKeyCreated  = "api_key_created"
+	KindBulkDelete     = "bulk_delete"
+	KindFailedSignIns  = "failed_signins"
+	KindSignupSpike    = "signup_spike"
+	TopicAdminActivity = "admin.activity"
+)
+
+// Notification modes, from ADMIN_NOTIFICATIONS
+const (
+	ModeImmediate = "immediate"
+	ModeDigest    = "digest"
+	ModeOff       = "off"
+)
+
+// ActivityEvent is the payload of TopicAdminActivity
+type ActivityEvent struct {
+	ActivityID uint `json:"activity_id"`
+}
+
+// Mode is how platform admins are notified, from ADMIN_NOTIFICATIONS: immediate (the default)
+// emails every activity once committed, digest batches them up for SendDigest, off only records them
+func Mode() string {
+	switch mode := os.Getenv("ADMIN_NOTIFICATIONS"); mode {
+	case ModeDigest, ModeOff:
+		return mode
+	default:
+		return ModeImmediate
+	}
+}
+
+// Record stores activity in tx, so it is only reported if the write it describes is committed.
+// It's located where the request of tx's context came from, see geoip.WithIP.
+// In immediate mode an outbox event emails it right after.
+func Record(tx *gorm.DB, activity *models.AdminActivity) error {
+	if activity.Country == "" && tx.Statement.Context != nil {
+		location := geoip.FromContext(tx.Statement.Context)
+		activity.Country, activity.Region = location.Country, location.Region
+	}
+	if err := tx.Create(activity).Error; err != nil {
+		return err
+	}
+	if Mode() != ModeImmediate {
+		return nil
+	}
+	return outbox.Enqueue(tx, TopicAdminActivity, activity.OrgID, ActivityEvent{ActivityID: activity.ID})
+}
+
+// Handler emails the activity of a TopicAdminActivity event, unless a digest already covered it
+func Handler(db *gorm.DB) outbox.Handler {
+	return func(event models.OutboxEvent) error {
+		var payload ActivityEvent
+		if err := json.Unmarshal([]byte(event.Payload), &payload); err != nil {
+			return err
+		}
+		var activity models.AdminActivity
+		err := db.Where("notified_at IS NULL").First(&activity, payload.ActivityID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return send(db, "Admin activity: "+activity.Summary, []models.AdminActivity{activity})
+	}
+}
+
+// SendDigest emails every activity not reported yet in one message
+func SendDigest(db *gorm.DB) error {
+	var activities []models.AdminActivity
+	if err := db.Where("notified_at IS NULL").Order("id ASC").Find(&activities).Error; err != nil {
+		return err
+	}
+	if len(activities) == 0 {
+		return nil
+	}
+	return send(db, fmt.Sprintf("Admin activity digest: %d events", len(activities)), activities)
+}
+
+// send emails activities to the platform admins and marks them notified. Activities nobody
+// could be told about are marked too, so they don't pile up in the next digest.
+func send(db *gorm.DB, subject string, activities []models.AdminActivity) error {
+	var recipients []string
+	err := db.Model(&models.AdminUser{}).
+		Where("org_id = ? AND role = ?", models.DefaultOrgID, models.RoleAdmin).
+		Order("id ASC").
+		Pluck("email", &recipients).Error
+	if err != nil {
+		return err
+	}
+	if len(recipients) == 0 {
+		log.Printf("[WARN] Notify: no platform admin to tell about %d activities", len(activities))
+	}
+
+	lines := make([]string, len(activities))
+	ids := make([]uint, len(activities))
+	for i, a := range activities {
+		lines[i] = a.CreatedAt.UTC().Format(time.RFC3339) + " " + a.Summary
+		if a.Actor != "" {
+			lines[i] += " (by " + a.Actor + ")"
+		}
+		ids[i] = a.ID
+	}
+	text := strings.Join(lines, "\n")
+	for _, to := range recipients {
+		if err := sendgridservice.SendAdminNotificationEmailFunc(to, subject, text); err != nil {
+			return err
+		}
+	}
+	return db.Model(&models.AdminActivity{}).Where("id IN ?", ids).Update("notified_at", time.Now()).Error
+}
@@ -0,0 +1,90 @@
+package notify
+
+import (
+	"testing"
+
+	"fiber-gorm-api/internal/models"
+	sendgridservice "fiber-gorm-api/internal/services"
+	"fiber-gorm-api/internal/testutil/testenv"
+
+	"gorm.io/gorm"
+)
+
+type sentEmail struct {
+	to, subject, text string
+}
+
+// setup opens a fresh database with a platform admin and an editor, and records the emails sent
+func setup(t *testing.T) (*gorm.DB, *[]sentEmail) {
+	conn := testenv.SQLite(t)
+	conn.Create(&[]models.AdminUser{
+		{OrgID: models.DefaultOrgID, Email: "root@example.com", Role: models.RoleAdmin},
+		{OrgID: models.DefaultOrgID, Email: "editor@example.com", Role: models.RoleEditor},
+	})
+
+	var sent []sentEmail
+	original := sendgridservice.SendAdminNotificationEmailFunc
+	sendgridservice.SendAdminNotificationEmailFunc = func(to, subject, text string) error {
+		sent = append(sent, sentEmail{to, subject, text})
+		return nil
+	}
+	t.Cleanup(func() { sendgridservice.SendAdminNotificationEmailFunc = original })
+	return conn, &sent
+}
+
+func TestImmediate(t *testing.T) {
+	conn, sent := setup(t)
+	activity := &models.AdminActivity{OrgID: 2, Kind: KindAPIKeyCreated, Actor: "ops@example.com", Summary: "API key created"}
+	if err := Record(conn, activity); err != nil {
+		t.Fatal(err)
+	}
+
+	var event models.OutboxEvent
+	if err := conn.Where("topic = ?", TopicAdminActivity).First(&event).Error; err != nil {
+		t.Fatalf("Expected an outbox event, got %v", err)
+	}
+	handle := Handler(conn)
+	if err := handle(event); err != nil {
+		t.Fatal(err)
+	}
+	if len(*sent) != 1 || (*sent)[0].to != "root@example.com" {
+		t.Fatalf("Expected one email to the platform admin, got %+v", *sent)
+	}
+
+	// a retried event isn't sent twice
+	if err := handle(event); err != nil {
+		t.Fatal(err)
+	}
+	if len(*sent) != 1 {
+		t.Errorf("Expected no second email, got %d", len(*sent))
+	}
+}
+
+func TestDigest(t *testing.T) {
+	t.Setenv("ADMIN_NOTIFICATIONS", ModeDigest)
+	conn, sent := setup(t)
+	for _, summary := range []string{"first", "second"} {
+		if err := Record(conn, &models.AdminActivity{OrgID: 1, Kind: KindBulkDelete, Summary: summary}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var events int64
+	conn.Model(&models.OutboxEvent{}).Count(&events)
+	if events != 0 {
+		t.Errorf("Expected no outbox event in digest mode, got %d", events)
+	}
+
+	if err := SendDigest(conn); err != nil {
+		t.Fatal(err)
+	}
+	if len(*sent) != 1 || (*sent)[0].subject != "Admin activity digest: 2 events" {
+		t.Fatalf("Expected one digest of 2 events, got %+v", *sent)
+	}
+
+	if err := SendDigest(conn); err != nil {
+		t.Fatal(err)
+	}
+	if len(*sent) != 1 {
+		t.Errorf("Expected nothing left to send, got %d emails", len(*sent))
+	}
+}
@@ -0,0 +1,160 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/github"
+)
+
+// ErrEmailNotVerified is returned when the provider can't vouch for the user's email address.
+var ErrEmailNotVerified = errors.New("provider did not return a verified email")
+
+// Provider is an external identity provider users can sign in with.
+type Provider struct {
+	Name   string
+	Config *oauth2.Config
+
+	// verifier is set for OIDC providers; plain OAuth2 providers (GitHub) look the email up via their API
+	verifier *oidc.IDTokenVerifier
+}
+
+var providers = map[string]*Provider{}
+
+// InitProviders enables every provider whose client credentials are set in the environment.
+// Redirect URLs are built from OAUTH_REDIRECT_BASE_URL, e.g. https://api.mylocal.ing
+func InitProviders() {
+	base := strings.TrimSuffix(os.Getenv("OAUTH_REDIRECT_BASE_URL"), "/")
+	redirectURL := func(name string) string {
+		return base + "/signin/oauth/" + name + "/callback"
+	}
+
+	if id := os.Getenv("OAUTH_GOOGLE_CLIENT_ID"); id != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		issuer, err := oidc.NewProvider(ctx, "https://accounts.google.com")
+		if err != nil {
+			log.Printf("[WARN] Google sign in disabled, OIDC discovery failed: %v", err)
+		} else {
+			providers["google"] = &Provider{
+				Name: "google",
+				Config: &oauth2.Config{
+					ClientID:     id,
+					ClientSecret: os.Getenv("OAUTH_GOOGLE_CLIENT_SECRET"),
+					Endpoint:     issuer.Endpoint(),
+					RedirectURL:  redirectURL("google"),
+					Scopes:       []string{oidc.ScopeOpenID, "email"},
+				},
+				verifier: issuer.Verifier(&oidc.Config{ClientID: id}),
+			}
+		}
+	}
+
+	if id := os.Getenv("OAUTH_GITHUB_CLIENT_ID"); id != "" {
+		providers["github"] = &Provider{
+			Name: "github",
+			Config: &oauth2.Config{
+				ClientID:     id,
+				ClientSecret: os.Getenv("OAUTH_GITHUB_CLIENT_SECRET"),
+				Endpoint:     github.Endpoint,
+				RedirectURL:  redirectURL("github"),
+				Scopes:       []string{"user:email"},
+			},
+		}
+	}
+}
+
+// Get returns an enabled provider by name
+func Get(name string) (*Provider, bool) {
+	p, ok := providers[name]
+	return p, ok
+}
+
+// AuthCodeURL is where the user is sent to authenticate with the provider
+func (p *Provider) AuthCodeURL(state, nonce, pkceVerifier string) string {
+	opts := []oauth2.AuthCodeOption{oauth2.S256ChallengeOption(pkceVerifier)}
+	if p.verifier != nil {
+		opts = append(opts, oidc.Nonce(nonce))
+	}
+	return p.Config.AuthCodeURL(state, opts...)
+}
+
+// Exchange trades the callback code for tokens and returns the user's verified email
+func (p *Provider) Exchange(ctx context.Context, code, nonce, pkceVerifier string) (string, error) {
+	token, err := p.Config.Exchange(ctx, code, oauth2.VerifierOption(pkceVerifier))
+	if err != nil {
+		return "", fmt.Errorf("code exchange failed: %w", err)
+	}
+
+	if p.verifier != nil {
+		return p.emailFromIDToken(ctx, token, nonce)
+	}
+	return p.emailFromGitHub(ctx, token)
+}
+
+func (p *Provider) emailFromIDToken(ctx context.Context, token *oauth2.Token, nonce string) (string, error) {
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return "", errors.New("no id_token in token response")
+	}
+	idToken, err := p.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return "", fmt.Errorf("invalid id_token: %w", err)
+	}
+	if idToken.Nonce != nonce {
+		return "", errors.New("id_token nonce mismatch")
+	}
+
+	var claims struct {
+		Email         string `json:"email"`
+		EmailVerified bool   `json:"email_verified"`
+	}
+	if err := idToken.Claims(&claims); err != nil {
+		return "", err
+	}
+	if claims.Email == "" || !claims.EmailVerified {
+		return "", ErrEmailNotVerified
+	}
+	return claims.Email, nil
+}
+
+func (p *Provider) emailFromGitHub(ctx context.Context, token *oauth2.Token) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.github.com/user/emails", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := p.Config.Client(ctx, token).Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("github returned status %d", resp.StatusCode)
+	}
+
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&emails); err != nil {
+		return "", err
+	}
+	for _, e := range emails {
+		if e.Primary && e.Verified {
+			return e.Email, nil
+		}
+	}
+	return "", ErrEmailNotVerified
+}
@@ -0,0 +1,188 @@
+package outbox
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"fiber-gorm-api/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Subscriber topics, enqueued by every subscriber write
+const (
+	TopicSubscriberCreated = "subscriber.created"
+	TopicSubscriberUpdated = "subscriber.updated"
+	TopicSubscriberDeleted = "subscriber.deleted"
+)
+
+// SubscriberTopics lists every topic carrying a SubscriberEvent payload
+var SubscriberTopics = []string{TopicSubscriberCreated, TopicSubscriberUpdated, TopicSubscriberDeleted}
+
+const (
+	defaultMaxAttempts = 10
+	maxBackoff         = time.Hour
+)
+
+// SubscriberEvent is the payload of the subscriber topics
+type SubscriberEvent struct {
+	SubscriberID uint   `json:"subscriber_id"`
+	Status       string `json:"status,omitempty"`
+}
+
+// Handler performs one side effect of an event. Returning an error retries the event later.
+type Handler func(event models.OutboxEvent) error
+
+// FailedHandler is called in the transaction marking an event failed, with the error it failed
+// on last. Returning an error rolls the batch back, so the event is tried again.
+type FailedHandler func(tx *gorm.DB, event models.OutboxEvent, cause error) error
+
+var (
+	handlers       = map[string][]Handler{}
+	failedHandlers = map[string][]FailedHandler{}
+)
+
+// permanentError is a failure that retrying won't fix
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err as a failure that retrying won't fix: a handler returning it gives the
+// event up at once rather than after OUTBOX_MAX_ATTEMPTS
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// Handle registers h for topic. Handlers run in registration order and must be idempotent:
+// an event is retried as a whole when any of its handlers fails.
+func Handle(topic string, h Handler) {
+	handlers[topic] = append(handlers[topic], h)
+}
+
+// HandleFailed registers h for the events of topic given up on, to keep them as a dead letter
+// or tell someone
+func HandleFailed(topic string, h FailedHandler) {
+	failedHandlers[topic] = append(failedHandlers[topic], h)
+}
+
+// Enqueue records an event in tx, so it is committed (or rolled back) together with the write it describes
+func Enqueue(tx *gorm.DB, topic string, orgID uint, payload interface{}) error {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return tx.Create(&models.OutboxEvent{
+		Topic:       topic,
+		OrgID:       orgID,
+		Payload:     string(raw),
+		AvailableAt: time.Now(),
+	}).Error
+}
+
+// EnqueueSubscriber records a subscriber topic event for s
+func EnqueueSubscriber(tx *gorm.DB, topic string, s *models.Subscriber) error {
+	return Enqueue(tx, topic, s.OrgID, SubscriberEvent{SubscriberID: s.ID, Status: s.Status})
+}
+
+// DecodeSubscriberEvent reads the payload of a subscriber topic event
+func DecodeSubscriberEvent(event models.OutboxEvent) (SubscriberEvent, error) {
+	var payload SubscriberEvent
+	err := json.Unmarshal([]byte(event.Payload), &payload)
+	return payload, err
+}
+
+// maxAttempts is how often an event is tried before it is given up on, from OUTBOX_MAX_ATTEMPTS
+func maxAttempts() int {
+	if n, err := strconv.Atoi(os.Getenv("OUTBOX_MAX_ATTEMPTS")); err == nil && n > 0 {
+		return n
+	}
+	return defaultMaxAttempts
+}
+
+// backoff doubles the retry delay per attempt: 2s, 4s, 8s, ... up to an hour
+func backoff(attempts int) time.Duration {
+	if attempts > 11 {
+		return maxBackoff
+	}
+	return min(time.Duration(1<<attempts)*time.Second, maxBackoff)
+}
+
+// dispatch runs every handler registered for the event's topic
+func dispatch(event models.OutboxEvent) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panic: %v", r)
+		}
+	}()
+	for _, h := range handlers[event.Topic] {
+		if err := h(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Drain dispatches up to limit due events, oldest first, and returns how many succeeded.
+// Rows are locked with SKIP LOCKED, so overlapping runs or several API instances never
+// dispatch the same event concurrently.
+func Drain(db *gorm.DB, limit int) (int, error) {
+	done := 0
+	err := db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		var events []models.OutboxEvent
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("processed_at IS NULL AND failed_at IS NULL AND available_at <= ?", now).
+			Order("id").Limit(limit).
+			Find(&events).Error; err != nil {
+			return err
+		}
+
+		for _, event := range events {
+			if err := dispatch(event); err != nil {
+				attempts := event.Attempts + 1
+				updates := map[string]interface{}{
+					"attempts":     attempts,
+					"last_error":   err.Error(),
+					"available_at": now.Add(backoff(attempts)),
+				}
+				var permanent *permanentError
+				if attempts >= maxAttempts() || errors.As(err, &permanent) {
+					updates["failed_at"] = now
+					log.Printf("[ERROR] Outbox: giving up on %s event %d after %d attempts: %v", event.Topic, event.ID, attempts, err)
+					event.Attempts = attempts
+					for _, h := range failedHandlers[event.Topic] {
+						if err := h(tx, event, err); err != nil {
+							return err
+						}
+					}
+				}
+				if err := tx.Model(&event).Updates(updates).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			if err := tx.Model(&event).Update("processed_at", now).Error; err != nil {
+				return err
+			}
+			done++
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Purge deletes processed events older than retention. Failed events are kept for inspection.
+func Purge(db *gorm.DB, retention time.Duration) (int64, error) {
+	res := db.Where("processed_at IS NOT NULL AND processed_at < ?", time.Now().Add(-retention)).
+		Delete(&models.OutboxEvent{})
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,66 @@
+package outbox
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"fiber-gorm-api/internal/models"
+)
+
+func TestBackoff(t *testing.T) {
+	if got := backoff(1); got != 2*time.Second {
+		t.Errorf("Expected 2s after the first attempt, got %s", got)
+	}
+	if got := backoff(3); got != 8*time.Second {
+		t.Errorf("Expected 8s after the third attempt, got %s", got)
+	}
+	if got := backoff(40); got != maxBackoff {
+		t.Errorf("Expected the backoff to be capped at %s, got %s", maxBackoff, got)
+	}
+}
+
+func TestDispatch(t *testing.T) {
+	var calls []string
+	Handle("test.ok", func(models.OutboxEvent) error {
+		calls = append(calls, "first")
+		return nil
+	})
+	Handle("test.ok", func(models.OutboxEvent) error {
+		calls = append(calls, "second")
+		return nil
+	})
+	Handle("test.fail", func(models.OutboxEvent) error {
+		return errors.New("target down")
+	})
+	Handle("test.panic", func(models.OutboxEvent) error {
+		panic("boom")
+	})
+
+	t.Run("Runs handlers in order", func(t *testing.T) {
+		if err := dispatch(models.OutboxEvent{Topic: "test.ok"}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(calls) != 2 || calls[0] != "first" || calls[1] != "second" {
+			t.Errorf("Expected both handlers in order, got %v", calls)
+		}
+	})
+
+	t.Run("Handler error is returned", func(t *testing.T) {
+		if err := dispatch(models.OutboxEvent{Topic: "test.fail"}); err == nil {
+			t.Error("Expected the handler error")
+		}
+	})
+
+	t.Run("Handler panic becomes an error", func(t *testing.T) {
+		if err := dispatch(models.OutboxEvent{Topic: "test.panic"}); err == nil {
+			t.Error("Expected the panic to be reported as an error")
+		}
+	})
+
+	t.Run("Topic without handlers succeeds", func(t *testing.T) {
+		if err := dispatch(models.OutboxEvent{Topic: "test.none"}); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,135 @@
+package passkey
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"fiber-gorm-api/internal/models"
+	redisclient "fiber-gorm-api/internal/redis"
+
+	"github.com/go-webauthn/webauthn/webauthn"
+	"gorm.io/gorm"
+)
+
+// ceremonyTTL is how long a started registration/login ceremony stays valid
+const ceremonyTTL = 5 * time.Minute
+
+var WebAuthn *webauthn.WebAuthn
+
+// InitWebAuthn configures the relying party from environment variables
+func InitWebAuthn() {
+	rpID := os.Getenv("WEBAUTHN_RP_ID")
+	if rpID == "" {
+		rpID = "mylocal.ing"
+	}
+	displayName := os.Getenv("WEBAUTHN_RP_DISPLAY_NAME")
+	if displayName == "" {
+		displayName = "myLocal"
+	}
+	origins := []string{"https://signin.mylocal.ing"}
+	if env := os.Getenv("WEBAUTHN_RP_ORIGINS"); env != "" {
+		origins = strings.Split(env, ",")
+	}
+
+	wa, err := webauthn.New(&webauthn.Config{
+		RPID:          rpID,
+		RPDisplayName: displayName,
+		RPOrigins:     origins,
+	})
+	if err != nil {
+		log.Fatalf("Could not configure WebAuthn: %v", err)
+	}
+	WebAuthn = wa
+}
+
+// User adapts an email and its stored credentials to the webauthn.User interface
+type User struct {
+	Email       string
+	Credentials []webauthn.Credential
+}
+
+// WebAuthnID is an opaque, stable handle derived from the email (max 64 bytes per spec)
+func (u *User) WebAuthnID() []byte {
+	sum := sha256.Sum256([]byte(strings.ToLower(u.Email)))
+	return sum[:]
+}
+
+func (u *User) WebAuthnName() string                       { return u.Email }
+func (u *User) WebAuthnDisplayName() string                { return u.Email }
+func (u *User) WebAuthnCredentials() []webauthn.Credential { return u.Credentials }
+
+// LoadUser loads every credential registered for email
+func LoadUser(db *gorm.DB, email string) (*User, error) {
+	var rows []models.WebAuthnCredential
+	if err := db.Where("email = ?", email).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	user := &User{Email: email}
+	for _, row := range rows {
+		var cred webauthn.Credential
+		if err := json.Unmarshal([]byte(row.Data), &cred); err != nil {
+			return nil, fmt.Errorf("corrupt webauthn credential %d: %w", row.ID, err)
+		}
+		user.Credentials = append(user.Credentials, cred)
+	}
+	return user, nil
+}
+
+// SaveCredential inserts a new credential, or refreshes the stored one (e.g. its sign counter)
+func SaveCredential(db *gorm.DB, email string, cred *webauthn.Credential) error {
+	data, err := json.Marshal(cred)
+	if err != nil {
+		return err
+	}
+
+	var row models.WebAuthnCredential
+	err = db.Where("credential_id = ?", cred.ID).First(&row).Error
+	if err == gorm.ErrRecordNotFound {
+		return db.Create(&models.WebAuthnCredential{
+			Email:        email,
+			CredentialID: cred.ID,
+			Data:         string(data),
+		}).Error
+	}
+	if err != nil {
+		return err
+	}
+	row.Data = string(data)
+	return db.Save(&row).Error
+}
+
+// Redis key holding the in-flight ceremony ("register" or "login") for email
+func ceremonyKey(kind, email string) string {
+	return "webauthn_" + kind + ":" + email
+}
+
+// SaveCeremony stores the session data of a started ceremony in Redis
+func SaveCeremony(ctx context.Context, kind, email string, data *webauthn.SessionData) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return redisclient.SetValue(ctx, ceremonyKey(kind, email), string(raw), ceremonyTTL)
+}
+
+// TakeCeremony loads and deletes (single-use) the session data of a started ceremony
+func TakeCeremony(ctx context.Context, kind, email string) (*webauthn.SessionData, error) {
+	raw, err := redisclient.GetValue(ctx, ceremonyKey(kind, email))
+	if err != nil || raw == "" {
+		return nil, fmt.Errorf("no %s ceremony in progress", kind)
+	}
+	_ = redisclient.DeleteKey(ctx, ceremonyKey(kind, email))
+
+	var data webauthn.SessionData
+	if err := json.Unmarshal([]byte(raw), &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
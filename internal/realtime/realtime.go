@@ -0,0 +1,92 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"fiber-gorm-api/internal/models"
+	"fiber-gorm-api/internal/outbox"
+	redisclient "fiber-gorm-api/internal/redis"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Topics of the progress of background jobs. Unlike the subscriber topics they're published
+// straight away rather than through the outbox: a missed one is made up for by the next.
+const (
+	TopicImportProgress   = "import.progress"
+	TopicImportFinished   = "import.finished"
+	TopicCampaignProgress = "campaign.progress"
+)
+
+// Event is a change pushed to live admin clients. It carries ids, statuses and counts only,
+// never PII, so clients refetch what they display. ID is the outbox event's, 0 for progress.
+type Event struct {
+	ID           uint      `json:"id"`
+	Topic        string    `json:"topic"`
+	SubscriberID uint      `json:"subscriber_id,omitempty"`
+	ImportID     uint      `json:"import_id,omitempty"`
+	CampaignID   uint      `json:"campaign_id,omitempty"`
+	Run          int       `json:"run,omitempty"`
+	Status       string    `json:"status,omitempty"`
+	Total        int       `json:"total,omitempty"`
+	Processed    int       `json:"processed,omitempty"`
+	Failed       int       `json:"failed,omitempty"`
+	OccurredAt   time.Time `json:"occurred_at"`
+}
+
+// Channel is the Redis pub/sub channel carrying the events of an organization
+func Channel(orgID uint) string {
+	return fmt.Sprintf("events:org:%d", orgID)
+}
+
+// Publish sends an event to the live clients of an organization. Without Redis there are none.
+func Publish(ctx context.Context, orgID uint, event Event) error {
+	if !redisclient.Configured() {
+		return nil
+	}
+	raw, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return redisclient.Publish(ctx, Channel(orgID), string(raw))
+}
+
+// Subscribe listens to the events of an organization until the returned PubSub is closed
+func Subscribe(ctx context.Context, orgID uint) *redis.PubSub {
+	return redisclient.Subscribe(ctx, Channel(orgID))
+}
+
+// PublishSubscriberEvent is an outbox handler forwarding subscriber topic events to the
+// organization's channel. Delivery is at least once: a failed outbox run publishes again.
+func PublishSubscriberEvent(event models.OutboxEvent) error {
+	message, err := encodeSubscriberEvent(event)
+	if err != nil {
+		return err
+	}
+	return redisclient.Publish(redisclient.Ctx, Channel(event.OrgID), message)
+}
+
+func encodeSubscriberEvent(event models.OutboxEvent) (string, error) {
+	payload, err := outbox.DecodeSubscriberEvent(event)
+	if err != nil {
+		return "", err
+	}
+	raw, err := json.Marshal(Event{
+		ID:           event.ID,
+		Topic:        event.Topic,
+		SubscriberID: payload.SubscriberID,
+		Status:       payload.Status,
+		OccurredAt:   event.CreatedAt,
+	})
+	return string(raw), err
+}
+
+// Decode reads an event published on a channel
+func Decode(message string) (Event, error) {
+	var e Event
+	err := json.Unmarshal([]byte(message), &e)
+	return e, err
+}
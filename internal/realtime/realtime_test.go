@@ -0,0 +1,42 @@
+package realtime
+
+import (
+	"testing"
+	"time"
+
+	"fiber-gorm-api/internal/models"
+	"fiber-gorm-api/internal/outbox"
+)
+
+func TestEncodeSubscriberEvent(t *testing.T) {
+	created := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	message, err := encodeSubscriberEvent(models.OutboxEvent{
+		ID:        7,
+		Topic:     outbox.TopicSubscriberUpdated,
+		OrgID:     3,
+		Payload:   `{"subscriber_id":42,"status":"bounced"}`,
+		CreatedAt: created,
+	})
+	if err != nil {
+		t.Fatalf("encode failed: %v", err)
+	}
+
+	event, err := Decode(message)
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if event.ID != 7 || event.Topic != outbox.TopicSubscriberUpdated || event.SubscriberID != 42 ||
+		event.Status != "bounced" || !event.OccurredAt.Equal(created) {
+		t.Errorf("Unexpected event %+v", event)
+	}
+
+	if _, err := encodeSubscriberEvent(models.OutboxEvent{Payload: "not json"}); err == nil {
+		t.Errorf("Expected an error for a malformed payload")
+	}
+}
+
+func TestChannel(t *testing.T) {
+	if got := Channel(3); got != "events:org:3" {
+		t.Errorf("Expected events:org:3, got %s", got)
+	}
+}
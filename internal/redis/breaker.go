@@ -0,0 +1,87 @@
+package redisclient
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"fiber-gorm-api/internal/resilience"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Defaults of REDIS_TIMEOUT_MS (dial, read and write) and REDIS_MAX_RETRIES
+const (
+	defaultTimeout    = time.Second
+	defaultMaxRetries = 2
+)
+
+// breakerHook runs the commands of a client through a circuit breaker (REDIS_BREAKER_THRESHOLD
+// failures in a row open it for REDIS_BREAKER_COOLDOWN_SECONDS). A command failing once the
+// client's retries are spent, or while the circuit is open, fails with a
+// *resilience.UnavailableError.
+type breakerHook struct {
+	breaker *resilience.Breaker
+}
+
+func (h breakerHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+func (h breakerHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		return h.run(func() error { return next(ctx, cmd) })
+	}
+}
+
+func (h breakerHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		return h.run(func() error { return next(ctx, cmds) })
+	}
+}
+
+func (h breakerHook) run(process func() error) error {
+	if err := h.breaker.Allow(); err != nil {
+		return err
+	}
+	err := process()
+	failed := unreachable(err)
+	h.breaker.Record(failed)
+	if failed {
+		return &resilience.UnavailableError{Name: h.breaker.Name, RetryAfter: h.breaker.Cooldown, Err: err}
+	}
+	return err
+}
+
+// unreachable reports whether err means Redis couldn't be reached (connection refused, timeout,
+// pool exhausted) rather than an answer, a missing key or an error reply included
+func unreachable(err error) bool {
+	if err == nil || errors.Is(err, redis.Nil) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var reply redis.Error
+	return !errors.As(err, &reply)
+}
+
+// envInt reads the non-negative integer in the environment variable name, def when unset or
+// invalid
+func envInt(name string, def int) int {
+	if n, err := strconv.Atoi(os.Getenv(name)); err == nil && n >= 0 {
+		return n
+	}
+	return def
+}
+
+// envMillis reads the positive number of milliseconds in the environment variable name, def
+// when unset or invalid
+func envMillis(name string, def time.Duration) time.Duration {
+	if n := envInt(name, 0); n > 0 {
+		return time.Duration(n) * time.Millisecond
+	}
+	return def
+}
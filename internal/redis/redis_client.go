@@ -5,19 +5,59 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"sync"
 	"time"
 
+	"fiber-gorm-api/internal/resilience"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/extra/redisotel/v9"
 	"github.com/redis/go-redis/v9"
 )
 
+// KVStore is the expiring key/value part of Redis: sign-in codes and their counters, sessions,
+// passkey ceremonies and OAuth states
+type KVStore interface {
+	SetValue(ctx context.Context, key, value string, expiration time.Duration) error
+	GetValue(ctx context.Context, key string) (string, error)
+	DeleteKey(ctx context.Context, key string) error
+	Take(ctx context.Context, key string) (string, error)
+	Increment(ctx context.Context, key string, expiration time.Duration) (int64, error)
+	TTL(ctx context.Context, key string) (time.Duration, error)
+}
+
+// Rdb is the session DB client (sign-in codes, sessions, ...)
 var Rdb *redis.Client
+
+// EntityRdb is the entity DB client, used for caching API reads. Nil until InitRedis("entity").
+var EntityRdb *redis.Client
+
+// Store is the session DB as a KVStore, behind SetValue, GetValue, DeleteKey, Take, Increment
+// and TTL. Nil until InitRedis("session").
+var Store KVStore
+
+// Current is the KVStore that Store is when it's called rather than when it's taken, for what
+// is built before Use swaps the client (see testutil)
+var Current KVStore = currentStore{}
+
+// Ctx is the background context of startup and jobs that aren't tied to a request
 var Ctx = context.Background()
 
-// InitRedis initializes the Redis client from environment variables
+var (
+	inProcess     *miniredis.Miniredis
+	inProcessOnce sync.Once
+)
+
+// InitRedis initializes the Redis client for the given usage ("session" or "entity") from environment variables.
+// Without REDIS_HOST, or with API_ENV=test, it uses an in-process Redis instead, so the API and its tests run
+// without a Redis server; its data is lost when the process exits.
 func InitRedis(usage string) {
 	host := os.Getenv("REDIS_HOST")
-	if host == "" {
-		log.Fatalf("You must specificy a Redis host environment variable.")
+	password := os.Getenv("REDIS_PASSWORD") // set via environment secrets if needed
+	if host == "" || os.Getenv("API_ENV") == "test" {
+		host = inProcessAddr()
+		password = ""
+		log.Println("REDIS_HOST unset or API_ENV=test, using an in-process Redis for", usage)
 	}
 
 	var dbType string
@@ -33,31 +73,228 @@ func InitRedis(usage string) {
 		dbNum = 0
 	}
 
-	Rdb = redis.NewClient(&redis.Options{
-		Addr:     host,
-		Password: os.Getenv("REDIS_PASSWORD"), // set via environment secrets if needed
-		DB:       dbNum,
+	// short timeouts and few retries (with jittered backoff), so requests fail fast rather than
+	// hang while Redis is unreachable, and a breaker failing commands at once when it stays so
+	timeout := envMillis("REDIS_TIMEOUT_MS", defaultTimeout)
+	client := redis.NewClient(&redis.Options{
+		Addr:         host,
+		Password:     password,
+		DB:           dbNum,
+		DialTimeout:  timeout,
+		ReadTimeout:  timeout,
+		WriteTimeout: timeout,
+		MaxRetries:   envInt("REDIS_MAX_RETRIES", defaultMaxRetries),
 	})
+	client.AddHook(breakerHook{resilience.NewBreaker("redis "+usage, "REDIS")})
+
+	// a span per command in the trace of its context
+	if err := redisotel.InstrumentTracing(client); err != nil {
+		log.Printf("[WARN] Could not trace Redis commands: %v", err)
+	}
 
 	// test connection
-	_, err = Rdb.Ping(Ctx).Result()
+	_, err = client.Ping(Ctx).Result()
 	if err != nil {
 		log.Fatalf("Could not connect to Redis: %v", err)
 	}
-	log.Println("Connected to Redis on", host)
+	log.Println("Connected to Redis on", host, "db", dbNum, "for", usage)
+
+	Use(usage, client)
+}
+
+// Configured reports whether the session DB is connected, by InitRedis or Use
+func Configured() bool {
+	return Store != nil
+}
+
+// Use makes client the Redis client of usage ("session" or "entity"), as InitRedis does, and
+// returns a func restoring the previous one. Tests use it to run on a Redis of their own.
+func Use(usage string, client *redis.Client) (restore func()) {
+	if usage == "session" {
+		previous, previousStore := Rdb, Store
+		Rdb = client
+		Store = clientStore{client}
+		return func() { Rdb, Store = previous, previousStore }
+	}
+	previous := EntityRdb
+	EntityRdb = client
+	return func() { EntityRdb = previous }
+}
+
+// inProcessAddr starts the in-process Redis on first use and returns its address. Sessions and
+// entities share it, each on its own DB number like on a real server.
+func inProcessAddr() string {
+	inProcessOnce.Do(func() {
+		server, err := miniredis.Run()
+		if err != nil {
+			log.Fatalf("Could not start the in-process Redis: %v", err)
+		}
+		inProcess = server
+	})
+	return inProcess.Addr()
+}
+
+// clientStore is a KVStore on a Redis client
+type clientStore struct {
+	client *redis.Client
+}
+
+func (s clientStore) SetValue(ctx context.Context, key, value string, expiration time.Duration) error {
+	return s.client.Set(ctx, key, value, expiration).Err()
+}
+
+func (s clientStore) GetValue(ctx context.Context, key string) (string, error) {
+	return s.client.Get(ctx, key).Result()
+}
+
+func (s clientStore) DeleteKey(ctx context.Context, key string) error {
+	return s.client.Del(ctx, key).Err()
+}
+
+func (s clientStore) Take(ctx context.Context, key string) (string, error) {
+	return s.client.GetDel(ctx, key).Result()
+}
+
+func (s clientStore) Increment(ctx context.Context, key string, expiration time.Duration) (int64, error) {
+	n, err := s.client.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	if n == 1 && expiration > 0 {
+		if err := s.client.Expire(ctx, key, expiration).Err(); err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+func (s clientStore) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return s.client.TTL(ctx, key).Result()
+}
+
+// currentStore is a KVStore on whatever Store is at each call
+type currentStore struct{}
+
+func (currentStore) SetValue(ctx context.Context, key, value string, expiration time.Duration) error {
+	return Store.SetValue(ctx, key, value, expiration)
+}
+
+func (currentStore) GetValue(ctx context.Context, key string) (string, error) {
+	return Store.GetValue(ctx, key)
+}
+
+func (currentStore) DeleteKey(ctx context.Context, key string) error {
+	return Store.DeleteKey(ctx, key)
+}
+
+func (currentStore) Take(ctx context.Context, key string) (string, error) {
+	return Store.Take(ctx, key)
+}
+
+func (currentStore) Increment(ctx context.Context, key string, expiration time.Duration) (int64, error) {
+	return Store.Increment(ctx, key, expiration)
+}
+
+func (currentStore) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return Store.TTL(ctx, key)
 }
 
 // SetValue stores a string value in Redis with an expiration
-func SetValue(key, value string, expiration time.Duration) error {
-	return Rdb.Set(Ctx, key, value, expiration).Err()
+func SetValue(ctx context.Context, key, value string, expiration time.Duration) error {
+	return Store.SetValue(ctx, key, value, expiration)
 }
 
 // GetValue retrieves a string value from Redis
-func GetValue(key string) (string, error) {
-	return Rdb.Get(Ctx, key).Result()
+func GetValue(ctx context.Context, key string) (string, error) {
+	return Store.GetValue(ctx, key)
 }
 
 // DeleteKey removes a key from Redis
-func DeleteKey(key string) error {
-	return Rdb.Del(Ctx, key).Err()
+func DeleteKey(ctx context.Context, key string) error {
+	return Store.DeleteKey(ctx, key)
+}
+
+// Replace overwrites the value of an existing key with a new expiration, reporting whether the
+// key existed; a missing key is left missing
+func Replace(ctx context.Context, key, value string, expiration time.Duration) (bool, error) {
+	return Rdb.SetXX(ctx, key, value, expiration).Result()
+}
+
+// SetIfAbsent stores value at key with an expiration unless the key exists, reporting whether it
+// did: a lock only one caller holds until it's released or expires
+func SetIfAbsent(ctx context.Context, key, value string, expiration time.Duration) (bool, error) {
+	return Rdb.SetNX(ctx, key, value, expiration).Result()
+}
+
+// deleteIfValueScript deletes KEYS[1] only if it holds ARGV[1]
+var deleteIfValueScript = redis.NewScript(`if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("DEL", KEYS[1]) end return 0`)
+
+// DeleteIfValue deletes key if it still holds value, so a caller whose lock expired and was taken
+// by another doesn't release the other's
+func DeleteIfValue(ctx context.Context, key, value string) error {
+	return deleteIfValueScript.Run(ctx, Rdb, []string{key}, value).Err()
+}
+
+// Take returns the value of key and deletes it in one step, so only one caller ever gets it
+func Take(ctx context.Context, key string) (string, error) {
+	return Store.Take(ctx, key)
+}
+
+// AddToSet adds members to the Redis set stored at key
+func AddToSet(ctx context.Context, key string, members ...string) error {
+	args := make([]interface{}, len(members))
+	for i, m := range members {
+		args[i] = m
+	}
+	return Rdb.SAdd(ctx, key, args...).Err()
+}
+
+// SetMembers returns all members of the Redis set stored at key
+func SetMembers(ctx context.Context, key string) ([]string, error) {
+	return Rdb.SMembers(ctx, key).Result()
+}
+
+// RemoveFromSet removes members from the Redis set stored at key
+func RemoveFromSet(ctx context.Context, key string, members ...string) error {
+	args := make([]interface{}, len(members))
+	for i, m := range members {
+		args[i] = m
+	}
+	return Rdb.SRem(ctx, key, args...).Err()
+}
+
+// Expire sets a TTL on an existing key
+func Expire(ctx context.Context, key string, expiration time.Duration) error {
+	return Rdb.Expire(ctx, key, expiration).Err()
+}
+
+// Increment atomically increments the integer stored at key. The expiration is only
+// applied when the key is created by this call.
+func Increment(ctx context.Context, key string, expiration time.Duration) (int64, error) {
+	return Store.Increment(ctx, key, expiration)
+}
+
+// TTL returns the remaining time to live of a key
+func TTL(ctx context.Context, key string) (time.Duration, error) {
+	return Store.TTL(ctx, key)
+}
+
+// ScanKeys returns every key matching a glob pattern, iterating with SCAN so Redis isn't blocked
+func ScanKeys(ctx context.Context, pattern string) ([]string, error) {
+	var keys []string
+	iter := Rdb.Scan(ctx, 0, pattern, 100).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	return keys, iter.Err()
+}
+
+// Publish sends message to every subscriber of a pub/sub channel
+func Publish(ctx context.Context, channel, message string) error {
+	return Rdb.Publish(ctx, channel, message).Err()
+}
+
+// Subscribe listens on pub/sub channels until the returned PubSub is closed
+func Subscribe(ctx context.Context, channels ...string) *redis.PubSub {
+	return Rdb.Subscribe(ctx, channels...)
 }
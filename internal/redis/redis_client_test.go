@@ -0,0 +1,105 @@
+package redisclient
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"fiber-gorm-api/internal/resilience"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestInProcessRedis(t *testing.T) {
+	t.Setenv("REDIS_HOST", "")
+	t.Setenv("REDIS_ENTITY_DB", "1")
+	InitRedis("session")
+	InitRedis("entity")
+
+	if err := SetValue(Ctx, "signin:code:ada@example.com", "123456", time.Minute); err != nil {
+		t.Fatalf("set failed: %v", err)
+	}
+	if v, err := GetValue(Ctx, "signin:code:ada@example.com"); err != nil || v != "123456" {
+		t.Errorf("Expected 123456, got %q (%v)", v, err)
+	}
+	if n, _ := EntityRdb.Exists(Ctx, "signin:code:ada@example.com").Result(); n != 0 {
+		t.Errorf("Expected the entity DB to be separate, got %d keys", n)
+	}
+	if err := DeleteKey(Ctx, "signin:code:ada@example.com"); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	if _, err := GetValue(Ctx, "signin:code:ada@example.com"); err == nil {
+		t.Error("Expected the deleted key to be gone")
+	}
+
+	if err := SetValue(Ctx, "signin:magic", "ada@example.com", time.Minute); err != nil {
+		t.Fatalf("set failed: %v", err)
+	}
+	if v, err := Take(Ctx, "signin:magic"); err != nil || v != "ada@example.com" {
+		t.Errorf("Expected ada@example.com, got %q (%v)", v, err)
+	}
+	if _, err := Take(Ctx, "signin:magic"); err == nil {
+		t.Error("Expected a taken key to be gone")
+	}
+
+	if n, err := Increment(Ctx, "signin:attempts", time.Minute); err != nil || n != 1 {
+		t.Errorf("Expected 1, got %d (%v)", n, err)
+	}
+	if ttl, _ := TTL(Ctx, "signin:attempts"); ttl <= 0 {
+		t.Errorf("Expected a TTL on the counter, got %s", ttl)
+	}
+
+	sub := Subscribe(Ctx, "events")
+	defer sub.Close()
+	if _, err := sub.Receive(Ctx); err != nil {
+		t.Fatalf("subscribe failed: %v", err)
+	}
+	if err := Publish(Ctx, "events", "hello"); err != nil {
+		t.Fatalf("publish failed: %v", err)
+	}
+	if msg, err := sub.ReceiveMessage(Ctx); err != nil || msg.Payload != "hello" {
+		t.Errorf("Expected hello, got %v (%v)", msg, err)
+	}
+}
+
+func TestBreakerHook(t *testing.T) {
+	server, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	breaker := &resilience.Breaker{Name: "redis test", Threshold: 2, Cooldown: 50 * time.Millisecond}
+	client := redis.NewClient(&redis.Options{Addr: server.Addr(), MaxRetries: -1, DialTimeout: 100 * time.Millisecond})
+	client.AddHook(breakerHook{breaker})
+	defer client.Close()
+
+	if err := client.Get(Ctx, "missing").Err(); !errors.Is(err, redis.Nil) {
+		t.Fatalf("Expected a missing key to be an answer, got %v", err)
+	}
+
+	server.Close()
+	for i := 0; i < 2; i++ {
+		if err := client.Get(Ctx, "key").Err(); !errors.Is(err, resilience.ErrUnavailable) {
+			t.Fatalf("Expected ErrUnavailable while Redis is down, got %v", err)
+		}
+	}
+	if !breaker.Open() {
+		t.Fatal("Expected the circuit open after 2 failures")
+	}
+	if retryAfter, ok := resilience.RetryAfter(client.Get(Ctx, "key").Err()); !ok || retryAfter <= 0 {
+		t.Errorf("Expected the open circuit to fail with a Retry-After, got %s", retryAfter)
+	}
+
+	// back up: the trial after the cooldown closes the circuit
+	if err := server.Restart(); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(60 * time.Millisecond)
+	if err := client.Set(Ctx, "key", "value", 0).Err(); err != nil {
+		t.Fatalf("Expected Redis reachable again, got %v", err)
+	}
+	if breaker.Open() {
+		t.Error("Expected the circuit closed")
+	}
+}
@@ -0,0 +1,232 @@
+package repository
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"fiber-gorm-api/internal/db"
+	"fiber-gorm-api/internal/filterexpr"
+	"fiber-gorm-api/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// exprField compiles the conditions on one field of a filter expression to SQL, bound to args
+type exprField struct {
+	ops     []string
+	compile func(c *filterexpr.Cond, sqlite bool) (sql string, args []interface{}, err error)
+}
+
+var (
+	equalityOps   = []string{":", "=", "!="}
+	comparisonOps = []string{":", "=", "!=", ">", ">=", "<", "<="}
+	dateOps       = []string{":", "=", ">", ">=", "<", "<="}
+)
+
+// exprFields are the fields of a filter expression
+var exprFields = map[string]exprField{
+	"status": {equalityOps, func(c *filterexpr.Cond, _ bool) (string, []interface{}, error) {
+		if !slices.Contains(models.SubscriberStatuses, c.Value) {
+			return "", nil, c.ValueErrorf("Invalid status %s, expected one of %s", c.Value, strings.Join(models.SubscriberStatuses, ", "))
+		}
+		return "subscribers.status " + sqlOp(c.Op) + " ?", []interface{}{c.Value}, nil
+	}},
+	"verified": {[]string{":", "="}, func(c *filterexpr.Cond, _ bool) (string, []interface{}, error) {
+		switch c.Value {
+		case "true":
+			return "subscribers.email_verified_at IS NOT NULL", nil, nil
+		case "false":
+			return "subscribers.email_verified_at IS NULL", nil, nil
+		}
+		return "", nil, c.ValueErrorf("Invalid verified, expected true or false")
+	}},
+	"type":      {equalityOps, typeColumn("name", nil)},
+	"frequency": {equalityOps, typeColumn("frequency", models.Frequencies)},
+	"channel":   {equalityOps, typeColumn("channel", models.Channels)},
+	"tag": {equalityOps, func(c *filterexpr.Cond, sqlite bool) (string, []interface{}, error) {
+		sql := tagConditionOf(sqlite)
+		if c.Op == "!=" {
+			sql = "NOT " + sql
+		}
+		return sql, []interface{}{[]string{c.Value}}, nil
+	}},
+	"email":        {equalityOps, textColumn("subscribers.email")},
+	"name":         {equalityOps, textColumn("subscribers.name")},
+	"country":      {equalityOps, codeColumn("subscribers.country", strings.ToUpper)},
+	"locale":       {equalityOps, codeColumn("subscribers.locale", strings.ToLower)},
+	"utm_source":   {equalityOps, codeColumn("subscribers.utm_source", nil)},
+	"utm_medium":   {equalityOps, codeColumn("subscribers.utm_medium", nil)},
+	"utm_campaign": {equalityOps, codeColumn("subscribers.utm_campaign", nil)},
+	"referrer":     {equalityOps, codeColumn("subscribers.referrer", nil)},
+	"created_at":   {dateOps, dateColumn("subscribers.created_at")},
+	"updated_at":   {dateOps, dateColumn("subscribers.updated_at")},
+	"spam_score": {comparisonOps, func(c *filterexpr.Cond, _ bool) (string, []interface{}, error) {
+		n, err := strconv.Atoi(c.Value)
+		if err != nil {
+			return "", nil, c.ValueErrorf("Invalid spam_score, expected a number")
+		}
+		return "subscribers.spam_score " + sqlOp(c.Op) + " ?", []interface{}{n}, nil
+	}},
+}
+
+// exprAliases are the other names of some fields
+var exprAliases = map[string]string{"subscriber_type": "type", "tags": "tag"}
+
+// sqlOp is the SQL comparison of an operator of a filter expression, ":" meaning equality
+func sqlOp(op string) string {
+	switch op {
+	case ":", "=":
+		return "="
+	case "!=":
+		return "<>"
+	}
+	return op
+}
+
+// typeColumn matches subscribers having a subscriber_type with column equal to the value, one
+// of accepted unless nil
+func typeColumn(column string, accepted []string) func(*filterexpr.Cond, bool) (string, []interface{}, error) {
+	return func(c *filterexpr.Cond, _ bool) (string, []interface{}, error) {
+		if accepted != nil && !slices.Contains(accepted, c.Value) {
+			return "", nil, c.ValueErrorf("Invalid %s %s, expected one of %s", c.Field, c.Value, strings.Join(accepted, ", "))
+		}
+		in := "IN"
+		if c.Op == "!=" {
+			in = "NOT IN"
+		}
+		return "subscribers.id " + in + " (SELECT subscriber_id FROM subscriber_types WHERE " + column + " = ?)", []interface{}{c.Value}, nil
+	}
+}
+
+// textColumn compares column to the value case-insensitively, * in the value matching any text
+func textColumn(column string) func(*filterexpr.Cond, bool) (string, []interface{}, error) {
+	return func(c *filterexpr.Cond, sqlite bool) (string, []interface{}, error) {
+		not := ""
+		if c.Op == "!=" {
+			not = "NOT "
+		}
+		if !strings.Contains(c.Value, "*") {
+			return not + "LOWER(COALESCE(" + column + ", '')) = ?", []interface{}{strings.ToLower(c.Value)}, nil
+		}
+		pattern := strings.ReplaceAll(likeEscaper.Replace(strings.ToLower(c.Value)), "*", "%")
+		return not + "LOWER(COALESCE(" + column + `, '')) LIKE ? ESCAPE '\'`, []interface{}{pattern}, nil
+	}
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// codeColumn compares column to the value, normalized by norm unless nil
+func codeColumn(column string, norm func(string) string) func(*filterexpr.Cond, bool) (string, []interface{}, error) {
+	return func(c *filterexpr.Cond, _ bool) (string, []interface{}, error) {
+		value := c.Value
+		if norm != nil {
+			value = norm(value)
+		}
+		return "COALESCE(" + column + ", '') " + sqlOp(c.Op) + " ?", []interface{}{value}, nil
+	}
+}
+
+// dateColumn compares column to a time, or to a whole day given as a date: created_at>2024-01-01
+// starts on January 2nd
+func dateColumn(column string) func(*filterexpr.Cond, bool) (string, []interface{}, error) {
+	return func(c *filterexpr.Cond, _ bool) (string, []interface{}, error) {
+		if t, err := time.Parse(time.RFC3339, c.Value); err == nil {
+			return column + " " + sqlOp(c.Op) + " ?", []interface{}{t}, nil
+		}
+		day, err := time.Parse(time.DateOnly, c.Value)
+		if err != nil {
+			return "", nil, c.ValueErrorf("Invalid %s, expected a date (2024-01-31) or an RFC 3339 time", c.Field)
+		}
+		next := day.AddDate(0, 0, 1)
+		switch c.Op {
+		case ">":
+			return column + " >= ?", []interface{}{next}, nil
+		case ">=":
+			return column + " >= ?", []interface{}{day}, nil
+		case "<":
+			return column + " < ?", []interface{}{day}, nil
+		case "<=":
+			return column + " < ?", []interface{}{next}, nil
+		}
+		return column + " >= ? AND " + column + " < ?", []interface{}{day, next}, nil
+	}
+}
+
+// compileExpr returns the SQL of a parsed filter expression, for SQLite or Postgres
+func compileExpr(n filterexpr.Node, sqlite bool) (string, []interface{}, error) {
+	switch n := n.(type) {
+	case *filterexpr.And:
+		return compileBinary(n.Left, " AND ", n.Right, sqlite)
+	case *filterexpr.Or:
+		return compileBinary(n.Left, " OR ", n.Right, sqlite)
+	case *filterexpr.Not:
+		sql, args, err := compileExpr(n.X, sqlite)
+		if err != nil {
+			return "", nil, err
+		}
+		return "NOT (" + sql + ")", args, nil
+	case *filterexpr.Cond:
+		name := n.Field
+		if alias, ok := exprAliases[name]; ok {
+			name = alias
+		}
+		field, ok := exprFields[name]
+		if !ok {
+			return "", nil, n.FieldErrorf("Unknown field %s", n.Field)
+		}
+		if !slices.Contains(field.ops, n.Op) {
+			return "", nil, n.Errorf("Operator %s doesn't apply to %s", n.Op, n.Field)
+		}
+		sql, args, err := field.compile(n, sqlite)
+		if err != nil {
+			return "", nil, err
+		}
+		return "(" + sql + ")", args, nil
+	}
+	return "", nil, nil
+}
+
+// compileBinary joins the SQL of left and right with op
+func compileBinary(left filterexpr.Node, op string, right filterexpr.Node, sqlite bool) (string, []interface{}, error) {
+	lsql, largs, err := compileExpr(left, sqlite)
+	if err != nil {
+		return "", nil, err
+	}
+	rsql, rargs, err := compileExpr(right, sqlite)
+	if err != nil {
+		return "", nil, err
+	}
+	return "(" + lsql + op + rsql + ")", append(largs, rargs...), nil
+}
+
+// ExprScope parses a filter expression, e.g. `type:shopper AND NOT tag:vip` (see package
+// filterexpr), and returns it as a query scope. Errors are *filterexpr.Error, safe to return to
+// the client with their position.
+func ExprScope(expr string) (func(*gorm.DB) *gorm.DB, error) {
+	tree, err := filterexpr.Parse(expr)
+	if err != nil {
+		return nil, err
+	}
+	// compiled once to check the fields and values, again for the backend of the query
+	if _, _, err := compileExpr(tree, false); err != nil {
+		return nil, err
+	}
+	return func(conn *gorm.DB) *gorm.DB {
+		sql, args, _ := compileExpr(tree, db.IsSQLite(conn))
+		return conn.Where(sql, args...)
+	}, nil
+}
+
+// ExprTypes returns the conditions of a parsed filter expression on the name of a
+// subscriber_type, for them to be checked against those defined
+func ExprTypes(tree filterexpr.Node) []*filterexpr.Cond {
+	var conds []*filterexpr.Cond
+	for _, c := range filterexpr.Conds(tree) {
+		if c.Field == "type" || exprAliases[c.Field] == "type" {
+			conds = append(conds, c)
+		}
+	}
+	return conds
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"strings"
+)
+
+// referralCodeLength is the length of referral codes: 50 random bits
+const referralCodeLength = 10
+
+// NewReferralCode returns a random referral code, lowercase letters and digits short enough to
+// type from a printed flyer
+func NewReferralCode() string {
+	raw := make([]byte, 7)
+	_, _ = rand.Read(raw)
+	return strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(raw))[:referralCodeLength]
+}
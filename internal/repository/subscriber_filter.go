@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"fiber-gorm-api/internal/db"
+	"fiber-gorm-api/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// SubscriberFilter narrows a subscriber list by status, email verification, the preferences
+// of one subscriber_type, the subscriber_types held, metadata tags, signup date and a filter
+// expression. Empty fields don't filter. It's stored as JSON by export jobs and segments.
+type SubscriberFilter struct {
+	Statuses       []string `json:"status,omitempty"`
+	Verified       string   `json:"verified,omitempty"` // "true" or "false"
+	SubscriberType string   `json:"subscriber_type,omitempty"`
+	Frequency      string   `json:"frequency,omitempty"`
+	Channel        string   `json:"channel,omitempty"`
+	// SubscriberTypes keeps subscribers holding any of them
+	SubscriberTypes []string `json:"subscriber_types,omitempty"`
+	// Tags keeps subscribers with any of them in the "tags" array of their metadata
+	Tags          []string   `json:"tags,omitempty"`
+	CreatedAfter  *time.Time `json:"created_after,omitempty"`
+	CreatedBefore *time.Time `json:"created_before,omitempty"`
+	// Query is a filter expression, see ExprScope
+	Query string `json:"query,omitempty"`
+}
+
+// Map returns f as stored in a jsonb column
+func (f SubscriberFilter) Map() models.JSONMap {
+	raw, _ := json.Marshal(f)
+	m := models.JSONMap{}
+	json.Unmarshal(raw, &m)
+	return m
+}
+
+// FilterFromMap reads back a filter stored with Map
+func FilterFromMap(m models.JSONMap) (SubscriberFilter, error) {
+	var f SubscriberFilter
+	raw, err := json.Marshal(m)
+	if err != nil {
+		return f, err
+	}
+	return f, json.Unmarshal(raw, &f)
+}
+
+// Scope checks f and returns it as a query scope. Errors are safe to return to the client.
+func (f SubscriberFilter) Scope() (func(*gorm.DB) *gorm.DB, error) {
+	byStatus, err := StatusFilter(f.Statuses, f.Verified)
+	if err != nil {
+		return nil, err
+	}
+	byPreference, err := PreferenceFilter(f.SubscriberType, f.Frequency, f.Channel)
+	if err != nil {
+		return nil, err
+	}
+	if f.CreatedAfter != nil && f.CreatedBefore != nil && !f.CreatedAfter.Before(*f.CreatedBefore) {
+		return nil, errors.New("Invalid signup dates, created_after must be before created_before")
+	}
+	byQuery := func(db *gorm.DB) *gorm.DB { return db }
+	if strings.TrimSpace(f.Query) != "" {
+		if byQuery, err = ExprScope(f.Query); err != nil {
+			return nil, err
+		}
+	}
+	return func(db *gorm.DB) *gorm.DB {
+		db = byQuery(byPreference(byStatus(db)))
+		if len(f.SubscriberTypes) > 0 {
+			db = db.Where("subscribers.id IN (SELECT subscriber_id FROM subscriber_types WHERE name IN ?)", f.SubscriberTypes)
+		}
+		if len(f.Tags) > 0 {
+			db = db.Where(tagCondition(db), f.Tags)
+		}
+		if f.CreatedAfter != nil {
+			db = db.Where("subscribers.created_at >= ?", *f.CreatedAfter)
+		}
+		if f.CreatedBefore != nil {
+			db = db.Where("subscribers.created_at < ?", *f.CreatedBefore)
+		}
+		return db
+	}, nil
+}
+
+// tagCondition matches subscribers with any of a list of tags in the "tags" array of their
+// metadata, on the backend of conn
+func tagCondition(conn *gorm.DB) string {
+	return tagConditionOf(db.IsSQLite(conn))
+}
+
+// tagConditionOf is tagCondition on SQLite or Postgres
+func tagConditionOf(sqlite bool) string {
+	if sqlite {
+		return "EXISTS (SELECT 1 FROM json_each(subscribers.metadata, '$.tags') WHERE json_each.value IN ?)"
+	}
+	return "EXISTS (SELECT 1 FROM jsonb_array_elements_text(CASE WHEN jsonb_typeof(subscribers.metadata->'tags') = 'array' " +
+		"THEN subscribers.metadata->'tags' ELSE '[]' END) AS tag WHERE tag IN ?)"
+}
+
+// PreferenceFilter restricts subscribers to those having one subscriber_type matching every
+// non-empty criterion, e.g. the donors who want a monthly email
+func PreferenceFilter(name, frequency, channel string) (func(*gorm.DB) *gorm.DB, error) {
+	if frequency != "" && !slices.Contains(models.Frequencies, frequency) {
+		return nil, errors.New("Invalid frequency: " + frequency)
+	}
+	if channel != "" && !slices.Contains(models.Channels, channel) {
+		return nil, errors.New("Invalid channel: " + channel)
+	}
+
+	var conds []string
+	var args []interface{}
+	for _, cond := range [][2]string{{"name", name}, {"frequency", frequency}, {"channel", channel}} {
+		if cond[1] != "" {
+			conds = append(conds, cond[0]+" = ?")
+			args = append(args, cond[1])
+		}
+	}
+	return func(db *gorm.DB) *gorm.DB {
+		if len(conds) == 0 {
+			return db
+		}
+		return db.Where("subscribers.id IN (SELECT subscriber_id FROM subscriber_types WHERE "+strings.Join(conds, " AND ")+")", args...)
+	}, nil
+}
+
+// StatusFilter restricts subscribers to the given statuses and, when verified is "true" or
+// "false", to those with or without a verified email
+func StatusFilter(statuses []string, verified string) (func(*gorm.DB) *gorm.DB, error) {
+	for _, st := range statuses {
+		if !slices.Contains(models.SubscriberStatuses, st) {
+			return nil, errors.New("Invalid status: " + st)
+		}
+	}
+	if verified != "" && verified != "true" && verified != "false" {
+		return nil, errors.New("Invalid verified, expected true or false")
+	}
+
+	return func(db *gorm.DB) *gorm.DB {
+		if len(statuses) > 0 {
+			db = db.Where("subscribers.status IN ?", statuses)
+		}
+		switch verified {
+		case "true":
+			db = db.Where("subscribers.email_verified_at IS NOT NULL")
+		case "false":
+			db = db.Where("subscribers.email_verified_at IS NULL")
+		}
+		return db
+	}, nil
+}
@@ -0,0 +1,448 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"fiber-gorm-api/internal/db"
+	"fiber-gorm-api/internal/models"
+	"fiber-gorm-api/internal/outbox"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrNotFound is returned when no row matches, including rows of another organization
+	ErrNotFound = errors.New("record not found")
+	// ErrVersionConflict is returned when a subscriber changed after it was read
+	ErrVersionConflict = errors.New("subscriber version is stale")
+)
+
+// SubscriberRepository persists subscribers. Every write records its outbox event in the same
+// transaction, so side effects only ever follow committed changes, and writes to a subscriber's
+// fields a revision authored by the ctx's WithAuthor.
+type SubscriberRepository interface {
+	// Transaction runs fn against a repository bound to one transaction, rolled back when fn fails
+	Transaction(ctx context.Context, fn func(repo SubscriberRepository) error) error
+	// Find loads a subscriber of the organization with its subscriber_types
+	Find(ctx context.Context, orgID, id uint) (*models.Subscriber, error)
+	// FindByEmail loads the oldest subscriber of the organization with the address
+	FindByEmail(ctx context.Context, orgID uint, email string) (*models.Subscriber, error)
+	// FindByConfirmTokenHash loads the subscriber a double opt-in token was issued to, in any organization
+	FindByConfirmTokenHash(ctx context.Context, hash string) (*models.Subscriber, error)
+	// FindByReferralCode loads the subscriber of the organization sharing the referral code
+	FindByReferralCode(ctx context.Context, orgID uint, code string) (*models.Subscriber, error)
+	// Create inserts s, with a new referral code unless it has one
+	Create(ctx context.Context, s *models.Subscriber) error
+	// Update writes email and name only if the row is still at s.Version and bumps it. Non-nil
+	// types replace the subscriber_types (an empty slice removes them all).
+	Update(ctx context.Context, s *models.Subscriber, types []models.SubscriberType) error
+	// Confirm marks the email of s verified, consumes its token and saves s.Status
+	Confirm(ctx context.Context, s *models.Subscriber) error
+	// ReissueConfirmation saves the new double opt-in token hash and send time of s
+	ReissueConfirmation(ctx context.Context, s *models.Subscriber) error
+	// Delete soft-deletes s. Its subscriber_types, notes and history are kept for Undelete
+	// until the cleanup scheduler purges them with it.
+	Delete(ctx context.Context, s *models.Subscriber) error
+	// FindDeleted loads a soft-deleted subscriber of the organization with its subscriber_types
+	FindDeleted(ctx context.Context, orgID, id uint) (*models.Subscriber, error)
+	// Undelete brings the soft-deleted s back as an undeleted revision
+	Undelete(ctx context.Context, s *models.Subscriber) error
+	// Approve saves the status and double opt-in token of s, let out of quarantine, as an
+	// approved revision
+	Approve(ctx context.Context, s *models.Subscriber) error
+	// Merge saves the email, status and metadata of target if it's still at target.Version,
+	// moves the subscriber_types, notes, delivery events and referees of source it lacks to it,
+	// and soft-deletes source
+	Merge(ctx context.Context, target, source *models.Subscriber) error
+	// UpdatePreferences writes the name and status of s, changed by the subscriber themselves,
+	// only if the row is still at s.Version and bumps it. Non-nil types replace the
+	// subscriber_types.
+	UpdatePreferences(ctx context.Context, s *models.Subscriber, types []models.SubscriberType) error
+	// TypeNames lists the names subscriber_types may take
+	TypeNames(ctx context.Context) ([]string, error)
+	// FindRevision loads a revision of the subscriber subscriberID
+	FindRevision(ctx context.Context, subscriberID, revisionID uint) (*models.SubscriberRevision, error)
+	// Restore writes the email, name, status, verification, metadata and subscriber_types of
+	// snapshot to s if it's still at s.Version, as a restored revision
+	Restore(ctx context.Context, s *models.Subscriber, snapshot models.SubscriberSnapshot) error
+}
+
+type subscriberRepository struct {
+	db *gorm.DB
+}
+
+// NewSubscriberRepository returns the Postgres SubscriberRepository. db may be a transaction.
+func NewSubscriberRepository(db *gorm.DB) SubscriberRepository {
+	return &subscriberRepository{db: db}
+}
+
+func (r *subscriberRepository) Transaction(ctx context.Context, fn func(repo SubscriberRepository) error) error {
+	return db.Tx(ctx, r.db, func(tx *gorm.DB) error {
+		return fn(&subscriberRepository{db: tx})
+	})
+}
+
+func (r *subscriberRepository) Find(ctx context.Context, orgID, id uint) (*models.Subscriber, error) {
+	var s models.Subscriber
+	err := r.db.WithContext(ctx).Where("org_id = ?", orgID).Preload("SubscriberTypes").First(&s, id).Error
+	return &s, notFound(err)
+}
+
+func (r *subscriberRepository) FindByEmail(ctx context.Context, orgID uint, email string) (*models.Subscriber, error) {
+	var s models.Subscriber
+	err := r.db.WithContext(ctx).Where("org_id = ? AND email = ?", orgID, email).First(&s).Error
+	return &s, notFound(err)
+}
+
+func (r *subscriberRepository) FindByConfirmTokenHash(ctx context.Context, hash string) (*models.Subscriber, error) {
+	var s models.Subscriber
+	err := r.db.WithContext(ctx).Where("confirm_token_hash = ?", hash).First(&s).Error
+	return &s, notFound(err)
+}
+
+func (r *subscriberRepository) FindByReferralCode(ctx context.Context, orgID uint, code string) (*models.Subscriber, error) {
+	var s models.Subscriber
+	err := r.db.WithContext(ctx).Where("org_id = ? AND referral_code = ?", orgID, code).First(&s).Error
+	return &s, notFound(err)
+}
+
+func (r *subscriberRepository) Create(ctx context.Context, s *models.Subscriber) error {
+	if s.ReferralCode == nil {
+		code := NewReferralCode()
+		s.ReferralCode = &code
+	}
+	return r.inTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Create(s).Error; err != nil {
+			return err
+		}
+		if err := RecordRevision(ctx, tx, s.ID, models.RevisionCreated, nil); err != nil {
+			return err
+		}
+		return outbox.EnqueueSubscriber(tx, outbox.TopicSubscriberCreated, s)
+	})
+}
+
+func (r *subscriberRepository) Update(ctx context.Context, s *models.Subscriber, types []models.SubscriberType) error {
+	return r.inTransaction(ctx, func(tx *gorm.DB) error {
+		before, err := LoadSnapshot(tx, s.ID)
+		if err != nil {
+			return err
+		}
+		res := tx.Model(&models.Subscriber{}).
+			Where("id = ? AND version = ?", s.ID, s.Version).
+			Updates(map[string]interface{}{
+				"email":    s.Email,
+				"name":     s.Name,
+				"metadata": s.Metadata,
+				"locale":   s.Locale,
+				// a new address hasn't been verified yet
+				"email_verified_at": gorm.Expr("CASE WHEN email = ? THEN email_verified_at END", s.Email),
+				"version":           gorm.Expr("version + 1"),
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrVersionConflict
+		}
+		s.Version++
+
+		if types != nil {
+			if err := replaceSubscriberTypes(tx, s.ID, types); err != nil {
+				return err
+			}
+		}
+		if err := RecordRevision(ctx, tx, s.ID, models.RevisionUpdated, before); err != nil {
+			return err
+		}
+		return outbox.EnqueueSubscriber(tx, outbox.TopicSubscriberUpdated, s)
+	})
+}
+
+func (r *subscriberRepository) Confirm(ctx context.Context, s *models.Subscriber) error {
+	return r.inTransaction(ctx, func(tx *gorm.DB) error {
+		before, err := LoadSnapshot(tx, s.ID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(s).Updates(map[string]interface{}{
+			"status":             s.Status,
+			"email_verified_at":  s.EmailVerifiedAt,
+			"confirm_token_hash": nil,
+			"version":            gorm.Expr("version + 1"),
+		}).Error; err != nil {
+			return err
+		}
+		if err := RecordRevision(ctx, tx, s.ID, models.RevisionConfirmed, before); err != nil {
+			return err
+		}
+		return outbox.EnqueueSubscriber(tx, outbox.TopicSubscriberUpdated, s)
+	})
+}
+
+func (r *subscriberRepository) ReissueConfirmation(ctx context.Context, s *models.Subscriber) error {
+	return r.db.WithContext(ctx).Model(s).Updates(map[string]interface{}{
+		"confirm_token_hash": s.ConfirmTokenHash,
+		"confirm_sent_at":    s.ConfirmSentAt,
+	}).Error
+}
+
+func (r *subscriberRepository) Approve(ctx context.Context, s *models.Subscriber) error {
+	return r.inTransaction(ctx, func(tx *gorm.DB) error {
+		before, err := LoadSnapshot(tx, s.ID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(s).Updates(map[string]interface{}{
+			"status":             s.Status,
+			"confirm_token_hash": s.ConfirmTokenHash,
+			"confirm_sent_at":    s.ConfirmSentAt,
+			"version":            gorm.Expr("version + 1"),
+		}).Error; err != nil {
+			return err
+		}
+		if err := RecordRevision(ctx, tx, s.ID, models.RevisionApproved, before); err != nil {
+			return err
+		}
+		return outbox.EnqueueSubscriber(tx, outbox.TopicSubscriberUpdated, s)
+	})
+}
+
+func (r *subscriberRepository) Delete(ctx context.Context, s *models.Subscriber) error {
+	return r.inTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Delete(s).Error; err != nil {
+			return err
+		}
+		return outbox.EnqueueSubscriber(tx, outbox.TopicSubscriberDeleted, s)
+	})
+}
+
+func (r *subscriberRepository) FindDeleted(ctx context.Context, orgID, id uint) (*models.Subscriber, error) {
+	var s models.Subscriber
+	err := r.db.WithContext(ctx).Unscoped().
+		Where("org_id = ? AND deleted_at IS NOT NULL", orgID).
+		Preload("SubscriberTypes").First(&s, id).Error
+	return &s, notFound(err)
+}
+
+func (r *subscriberRepository) Undelete(ctx context.Context, s *models.Subscriber) error {
+	return r.inTransaction(ctx, func(tx *gorm.DB) error {
+		before := models.NewSubscriberSnapshot(*s)
+		res := tx.Unscoped().Model(&models.Subscriber{}).
+			Where("id = ? AND deleted_at IS NOT NULL", s.ID).
+			Updates(map[string]interface{}{
+				"deleted_at": nil,
+				"version":    gorm.Expr("version + 1"),
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		// undeleted, or purged, meanwhile
+		if res.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		s.Version++
+		s.DeletedAt = gorm.DeletedAt{}
+
+		if err := RecordRevision(ctx, tx, s.ID, models.RevisionUndeleted, &before); err != nil {
+			return err
+		}
+		return outbox.EnqueueSubscriber(tx, outbox.TopicSubscriberCreated, s)
+	})
+}
+
+func (r *subscriberRepository) Merge(ctx context.Context, target, source *models.Subscriber) error {
+	return r.inTransaction(ctx, func(tx *gorm.DB) error {
+		before, err := LoadSnapshot(tx, target.ID)
+		if err != nil {
+			return err
+		}
+		res := tx.Model(&models.Subscriber{}).
+			Where("id = ? AND version = ?", target.ID, target.Version).
+			Updates(map[string]interface{}{
+				"email":             target.Email,
+				"email_verified_at": target.EmailVerifiedAt,
+				"status":            target.Status,
+				"metadata":          target.Metadata,
+				"version":           gorm.Expr("version + 1"),
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrVersionConflict
+		}
+		target.Version++
+
+		// types and events the target already has are dropped with the source
+		if err := tx.Model(&models.SubscriberType{}).
+			Where("subscriber_id = ? AND name NOT IN (?)", source.ID,
+				tx.Model(&models.SubscriberType{}).Select("name").Where("subscriber_id = ?", target.ID)).
+			Update("subscriber_id", target.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("subscriber_id = ?", source.ID).Delete(&models.SubscriberType{}).Error; err != nil {
+			return err
+		}
+		// the same webhook event is recorded for every subscriber with the address
+		if err := tx.Model(&models.DeliveryEvent{}).
+			Where("subscriber_id = ? AND sg_event_id NOT IN (?)", source.ID,
+				tx.Model(&models.DeliveryEvent{}).Select("sg_event_id").Where("subscriber_id = ?", target.ID)).
+			Update("subscriber_id", target.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("subscriber_id = ?", source.ID).Delete(&models.DeliveryEvent{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.SubscriberNote{}).Where("subscriber_id = ?", source.ID).
+			Update("subscriber_id", target.ID).Error; err != nil {
+			return err
+		}
+		// the signups the duplicate referred count for the target, which can't refer itself
+		if err := tx.Model(&models.Subscriber{}).Where("referred_by_id = ?", source.ID).
+			Update("referred_by_id", gorm.Expr("CASE WHEN id = ? THEN NULL ELSE ? END", target.ID, target.ID)).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Delete(source).Error; err != nil {
+			return err
+		}
+		if err := RecordRevision(ctx, tx, target.ID, models.RevisionMerged, before); err != nil {
+			return err
+		}
+		if err := outbox.EnqueueSubscriber(tx, outbox.TopicSubscriberDeleted, source); err != nil {
+			return err
+		}
+		return outbox.EnqueueSubscriber(tx, outbox.TopicSubscriberUpdated, target)
+	})
+}
+
+func (r *subscriberRepository) UpdatePreferences(ctx context.Context, s *models.Subscriber, types []models.SubscriberType) error {
+	return r.inTransaction(ctx, func(tx *gorm.DB) error {
+		before, err := LoadSnapshot(tx, s.ID)
+		if err != nil {
+			return err
+		}
+		res := tx.Model(&models.Subscriber{}).
+			Where("id = ? AND version = ?", s.ID, s.Version).
+			Updates(map[string]interface{}{
+				"name":    s.Name,
+				"status":  s.Status,
+				"version": gorm.Expr("version + 1"),
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrVersionConflict
+		}
+		s.Version++
+
+		if types != nil {
+			if err := replaceSubscriberTypes(tx, s.ID, types); err != nil {
+				return err
+			}
+		}
+		if err := RecordRevision(ctx, tx, s.ID, models.RevisionUpdated, before); err != nil {
+			return err
+		}
+		return outbox.EnqueueSubscriber(tx, outbox.TopicSubscriberUpdated, s)
+	})
+}
+
+func (r *subscriberRepository) TypeNames(ctx context.Context) ([]string, error) {
+	var names []string
+	err := r.db.WithContext(ctx).Model(&models.SubscriberTypeDefinition{}).Order("name").Pluck("name", &names).Error
+	return names, err
+}
+
+func (r *subscriberRepository) FindRevision(ctx context.Context, subscriberID, revisionID uint) (*models.SubscriberRevision, error) {
+	var revision models.SubscriberRevision
+	err := r.db.WithContext(ctx).Where("subscriber_id = ?", subscriberID).First(&revision, revisionID).Error
+	return &revision, notFound(err)
+}
+
+func (r *subscriberRepository) Restore(ctx context.Context, s *models.Subscriber, snapshot models.SubscriberSnapshot) error {
+	return r.inTransaction(ctx, func(tx *gorm.DB) error {
+		before, err := LoadSnapshot(tx, s.ID)
+		if err != nil {
+			return err
+		}
+		res := tx.Model(&models.Subscriber{}).
+			Where("id = ? AND version = ?", s.ID, s.Version).
+			Updates(map[string]interface{}{
+				"email":             snapshot.Email,
+				"name":              snapshot.Name,
+				"status":            snapshot.Status,
+				"email_verified_at": snapshot.EmailVerifiedAt,
+				"metadata":          snapshot.Metadata,
+				"version":           gorm.Expr("version + 1"),
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrVersionConflict
+		}
+		s.Version++
+
+		types := make([]models.SubscriberType, len(snapshot.SubscriberTypes))
+		for i, name := range snapshot.SubscriberTypes {
+			types[i] = models.SubscriberType{Name: name}
+		}
+		if err := replaceSubscriberTypes(tx, s.ID, types); err != nil {
+			return err
+		}
+		if err := RecordRevision(ctx, tx, s.ID, models.RevisionRestored, before); err != nil {
+			return err
+		}
+		s.Status = snapshot.Status
+		return outbox.EnqueueSubscriber(tx, outbox.TopicSubscriberUpdated, s)
+	})
+}
+
+// inTransaction runs fn in a transaction, or in a savepoint when r is already bound to one
+func (r *subscriberRepository) inTransaction(ctx context.Context, fn func(tx *gorm.DB) error) error {
+	return db.Tx(ctx, r.db, fn)
+}
+
+// replaceSubscriberTypes swaps all subscriber_types of a subscriber for the given ones. Types
+// the subscriber already has keep the preferences they're given without.
+func replaceSubscriberTypes(tx *gorm.DB, subscriberID uint, types []models.SubscriberType) error {
+	var current []models.SubscriberType
+	if err := tx.Where("subscriber_id = ?", subscriberID).Find(&current).Error; err != nil {
+		return err
+	}
+	for i := range types {
+		for _, c := range current {
+			if c.Name != types[i].Name {
+				continue
+			}
+			if types[i].Frequency == "" {
+				types[i].Frequency = c.Frequency
+			}
+			if types[i].Channel == "" {
+				types[i].Channel = c.Channel
+			}
+		}
+	}
+
+	if err := tx.Where("subscriber_id = ?", subscriberID).Delete(&models.SubscriberType{}).Error; err != nil {
+		return err
+	}
+	if len(types) == 0 {
+		return nil
+	}
+	for i := range types {
+		types[i].SubscriberID = subscriberID
+	}
+	return tx.Create(&types).Error
+}
+
+func notFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
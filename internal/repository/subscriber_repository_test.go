@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"fiber-gorm-api/internal/db"
+	"fiber-gorm-api/internal/models"
+)
+
+// BenchmarkSubscriberCRUD creates, reads, updates and deletes subscribers from parallel
+// goroutines, with and without prepared statements:
+//
+//	go test ./internal/repository -run '^$' -bench SubscriberCRUD -benchmem
+func BenchmarkSubscriberCRUD(b *testing.B) {
+	for _, prepare := range []bool{false, true} {
+		b.Run(fmt.Sprintf("PrepareStmt=%t", prepare), func(b *testing.B) {
+			b.Setenv("DB_PREPARE_STMT", strconv.FormatBool(prepare))
+			conn, err := db.OpenSQLite(filepath.Join(b.TempDir(), "bench.db"))
+			if err != nil {
+				b.Fatal(err)
+			}
+			if conn.Config.PrepareStmt != prepare {
+				b.Fatalf("Expected PrepareStmt %t", prepare)
+			}
+			repo := NewSubscriberRepository(conn)
+			ctx := context.Background()
+
+			var seq atomic.Int64
+			b.ReportAllocs()
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					s := &models.Subscriber{
+						OrgID: models.DefaultOrgID,
+						Email: fmt.Sprintf("bench-%d@example.com", seq.Add(1)),
+						Name:  "Bench",
+					}
+					if err := repo.Create(ctx, s); err != nil {
+						b.Error(err)
+						return
+					}
+					found, err := repo.Find(ctx, models.DefaultOrgID, s.ID)
+					if err != nil {
+						b.Error(err)
+						return
+					}
+					found.Name = "Bench Updated"
+					if err := repo.Update(ctx, found, nil); err != nil {
+						b.Error(err)
+						return
+					}
+					if err := repo.Delete(ctx, found); err != nil {
+						b.Error(err)
+						return
+					}
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+
+	"fiber-gorm-api/internal/geoip"
+	"fiber-gorm-api/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type authorContextKey struct{}
+
+// WithAuthor attaches who is making the writes done with ctx, recorded in subscriber revisions
+func WithAuthor(ctx context.Context, author string) context.Context {
+	return context.WithValue(ctx, authorContextKey{}, author)
+}
+
+// authorFrom returns the author WithAuthor attached to ctx, empty if none
+func authorFrom(ctx context.Context) string {
+	author, _ := ctx.Value(authorContextKey{}).(string)
+	return author
+}
+
+// LoadSnapshot reads the current state of a subscriber, in any organization, for the before
+// side of a revision
+func LoadSnapshot(tx *gorm.DB, id uint) (*models.SubscriberSnapshot, error) {
+	var s models.Subscriber
+	if err := tx.Preload("SubscriberTypes").First(&s, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	snapshot := models.NewSubscriberSnapshot(s)
+	return &snapshot, nil
+}
+
+// RecordRevision stores the state of a subscriber after a write in tx, along with before (nil
+// for a new subscriber), authored by the author of ctx
+func RecordRevision(ctx context.Context, tx *gorm.DB, id uint, action string, before *models.SubscriberSnapshot) error {
+	after, err := LoadSnapshot(tx, id)
+	if err != nil {
+		return err
+	}
+	revision := models.SubscriberRevision{
+		SubscriberID: id,
+		Version:      after.Version,
+		Action:       action,
+		Author:       authorFrom(ctx),
+	}
+	location := geoip.FromContext(ctx)
+	revision.Country, revision.Region = location.Country, location.Region
+	raw, err := json.Marshal(after)
+	if err != nil {
+		return err
+	}
+	revision.After = string(raw)
+	if before != nil {
+		raw, err := json.Marshal(before)
+		if err != nil {
+			return err
+		}
+		encoded := string(raw)
+		revision.Before = &encoded
+	}
+	return tx.Create(&revision).Error
+}
@@ -0,0 +1,200 @@
+// Package resilience keeps a dependency that is down (Redis, the email provider) from hanging
+// the requests that need it: calls are retried with jitter under a per-attempt timeout, and a
+// circuit breaker fails them fast once the dependency keeps failing, until it recovers.
+package resilience
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Breaker defaults, overridden per dependency with <PREFIX>_BREAKER_THRESHOLD and
+// <PREFIX>_BREAKER_COOLDOWN_SECONDS
+const (
+	DefaultThreshold = 5
+	DefaultCooldown  = 10 * time.Second
+)
+
+// ErrUnavailable is wrapped by the errors of calls to a dependency that is down: its circuit is
+// open, or it kept failing through the retries. Handlers answer them with a 503 and Retry-After.
+var ErrUnavailable = errors.New("temporarily unavailable")
+
+// UnavailableError is the error of a call to a dependency that is down
+type UnavailableError struct {
+	Name string
+	// RetryAfter is when the dependency is worth trying again
+	RetryAfter time.Duration
+	// Err is the last failure, nil when the circuit was open and nothing was tried
+	Err error
+}
+
+func (e *UnavailableError) Error() string {
+	if e.Err == nil {
+		return e.Name + " " + ErrUnavailable.Error()
+	}
+	return fmt.Sprintf("%s %s: %v", e.Name, ErrUnavailable, e.Err)
+}
+
+func (e *UnavailableError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{ErrUnavailable}
+	}
+	return []error{ErrUnavailable, e.Err}
+}
+
+// RetryAfter returns when the dependency err says is down is worth trying again, and whether err
+// says so at all
+func RetryAfter(err error) (time.Duration, bool) {
+	var unavailable *UnavailableError
+	if !errors.As(err, &unavailable) {
+		return 0, false
+	}
+	return unavailable.RetryAfter, true
+}
+
+// Breaker is a circuit breaker. After Threshold failures in a row it opens: calls fail at once
+// for Cooldown, then a single trial call goes through, closing it again when it succeeds.
+type Breaker struct {
+	Name      string
+	Threshold int
+	Cooldown  time.Duration
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time // zero while closed
+	trying   bool      // a trial call is running
+}
+
+// NewBreaker returns the breaker of the dependency name, configured by <prefix>_BREAKER_THRESHOLD
+// and <prefix>_BREAKER_COOLDOWN_SECONDS
+func NewBreaker(name, prefix string) *Breaker {
+	return &Breaker{
+		Name:      name,
+		Threshold: envInt(prefix+"_BREAKER_THRESHOLD", DefaultThreshold),
+		Cooldown:  EnvSeconds(prefix+"_BREAKER_COOLDOWN_SECONDS", DefaultCooldown),
+	}
+}
+
+// Allow returns nil when a call may go ahead, an *UnavailableError while the circuit is open.
+// Every allowed call must be followed by Record.
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.openedAt.IsZero() {
+		return nil
+	}
+	if left := b.Cooldown - time.Since(b.openedAt); left > 0 {
+		return &UnavailableError{Name: b.Name, RetryAfter: left}
+	}
+	if b.trying {
+		return &UnavailableError{Name: b.Name, RetryAfter: time.Second}
+	}
+	b.trying = true
+	return nil
+}
+
+// Record counts the outcome of an allowed call: failed reports whether the dependency failed,
+// as opposed to answering, even with an error
+func (b *Breaker) Record(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trying = false
+	if !failed {
+		b.failures = 0
+		b.openedAt = time.Time{}
+		return
+	}
+	b.failures++
+	if b.failures >= b.Threshold || !b.openedAt.IsZero() {
+		b.openedAt = time.Now()
+	}
+}
+
+// Open reports whether the circuit is open, failing calls at once
+func (b *Breaker) Open() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return !b.openedAt.IsZero() && time.Since(b.openedAt) < b.Cooldown
+}
+
+// Policy is how calls to a dependency are retried
+type Policy struct {
+	// Attempts is how many times a call is tried in all; 1 never retries
+	Attempts int
+	// BaseDelay is the most to wait before the first retry, doubling for each next one up to
+	// MaxDelay; the wait is random below it, so callers don't retry in step
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// Timeout bounds each attempt, 0 for none
+	Timeout time.Duration
+}
+
+// Do calls fn through the breaker b under policy p, retrying the failures retryable reports,
+// until it succeeds, fails otherwise, or ctx is done. A dependency still failing after the
+// last attempt, or whose circuit is open, gives an *UnavailableError.
+func Do(ctx context.Context, b *Breaker, p Policy, retryable func(error) bool, fn func(context.Context) error) error {
+	var err error
+	for attempt := 0; attempt < max(p.Attempts, 1); attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(p.delay(attempt)):
+			case <-ctx.Done():
+				return &UnavailableError{Name: b.Name, RetryAfter: b.Cooldown, Err: err}
+			}
+		}
+		if open := b.Allow(); open != nil {
+			return open
+		}
+		err = call(ctx, p.Timeout, fn)
+		failed := err != nil && retryable(err)
+		b.Record(failed)
+		if !failed {
+			return err
+		}
+	}
+	return &UnavailableError{Name: b.Name, RetryAfter: b.Cooldown, Err: err}
+}
+
+func call(ctx context.Context, timeout time.Duration, fn func(context.Context) error) error {
+	if timeout <= 0 {
+		return fn(ctx)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return fn(ctx)
+}
+
+// delay is the random wait before the attempt-th retry: "full jitter" exponential backoff
+func (p Policy) delay(attempt int) time.Duration {
+	ceiling := p.BaseDelay << (attempt - 1)
+	if p.MaxDelay > 0 && (ceiling > p.MaxDelay || ceiling <= 0) {
+		ceiling = p.MaxDelay
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return rand.N(ceiling)
+}
+
+// envInt reads the positive integer in the environment variable name, def when unset or invalid
+func envInt(name string, def int) int {
+	if n, err := strconv.Atoi(os.Getenv(name)); err == nil && n > 0 {
+		return n
+	}
+	return def
+}
+
+// EnvSeconds reads the positive number of seconds in the environment variable name, def when
+// unset or invalid
+func EnvSeconds(name string, def time.Duration) time.Duration {
+	if n, err := strconv.Atoi(os.Getenv(name)); err == nil && n > 0 {
+		return time.Duration(n) * time.Second
+	}
+	return def
+}
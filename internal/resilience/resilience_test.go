@@ -0,0 +1,105 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+var (
+	errDown     = errors.New("connection refused")
+	errRejected = errors.New("bad request")
+)
+
+func retryable(err error) bool { return errors.Is(err, errDown) }
+
+func TestDo(t *testing.T) {
+	ctx := context.Background()
+	policy := Policy{Attempts: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+
+	t.Run("Retries until it succeeds", func(t *testing.T) {
+		b := &Breaker{Name: "test", Threshold: 5, Cooldown: time.Minute}
+		calls := 0
+		err := Do(ctx, b, policy, retryable, func(context.Context) error {
+			if calls++; calls < 3 {
+				return errDown
+			}
+			return nil
+		})
+		if err != nil || calls != 3 {
+			t.Errorf("Expected success on the 3rd call, got %v after %d", err, calls)
+		}
+	})
+
+	t.Run("Other errors aren't retried", func(t *testing.T) {
+		b := &Breaker{Name: "test", Threshold: 1, Cooldown: time.Minute}
+		calls := 0
+		err := Do(ctx, b, policy, retryable, func(context.Context) error { calls++; return errRejected })
+		if !errors.Is(err, errRejected) || errors.Is(err, ErrUnavailable) || calls != 1 {
+			t.Errorf("Expected the error as is after 1 call, got %v after %d", err, calls)
+		}
+		if b.Open() {
+			t.Error("Expected an answer, even an error, to leave the circuit closed")
+		}
+	})
+
+	t.Run("Unavailable once the attempts are spent", func(t *testing.T) {
+		b := &Breaker{Name: "test", Threshold: 10, Cooldown: time.Minute}
+		err := Do(ctx, b, policy, retryable, func(context.Context) error { return errDown })
+		if !errors.Is(err, ErrUnavailable) || !errors.Is(err, errDown) {
+			t.Errorf("Expected ErrUnavailable wrapping the last failure, got %v", err)
+		}
+		if retryAfter, ok := RetryAfter(err); !ok || retryAfter != time.Minute {
+			t.Errorf("Expected a retry after the cooldown, got %s (%t)", retryAfter, ok)
+		}
+	})
+
+	t.Run("Attempts time out", func(t *testing.T) {
+		b := &Breaker{Name: "test", Threshold: 10, Cooldown: time.Minute}
+		p := Policy{Attempts: 1, Timeout: 10 * time.Millisecond}
+		err := Do(ctx, b, p, func(err error) bool { return errors.Is(err, context.DeadlineExceeded) }, func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		if !errors.Is(err, ErrUnavailable) {
+			t.Errorf("Expected the hung call to time out, got %v", err)
+		}
+	})
+}
+
+func TestBreaker(t *testing.T) {
+	b := &Breaker{Name: "test", Threshold: 2, Cooldown: 50 * time.Millisecond}
+
+	for i := 0; i < 2; i++ {
+		if err := b.Allow(); err != nil {
+			t.Fatalf("Expected the closed circuit to allow calls, got %v", err)
+		}
+		b.Record(true)
+	}
+	err := b.Allow()
+	if !b.Open() || !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("Expected the circuit open after 2 failures, got %v", err)
+	}
+
+	// after the cooldown a single trial goes through
+	time.Sleep(60 * time.Millisecond)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Expected a trial call allowed, got %v", err)
+	}
+	if err := b.Allow(); err == nil {
+		t.Error("Expected a single trial at a time")
+	}
+	b.Record(true)
+	if !b.Open() {
+		t.Fatal("Expected a failed trial to open the circuit again")
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	b.Allow()
+	b.Record(false)
+	if b.Open() || b.Allow() != nil {
+		t.Error("Expected a successful trial to close the circuit")
+	}
+	b.Record(false)
+}
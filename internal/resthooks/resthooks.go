@@ -0,0 +1,198 @@
+// Package resthooks implements REST Hooks (https://resthooks.org), the subscription convention
+// of Zapier: a client subscribes a target URL to an event, every such event is POSTed to it, and
+// the subscription ends when the client deletes it or the target answers 410 Gone.
+package resthooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/models"
+	"fiber-gorm-api/internal/outbox"
+	"fiber-gorm-api/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// TopicDelivery is the outbox topic of one event to deliver to one hook
+const TopicDelivery = "rest_hook.delivery"
+
+// sampleSize is how many recent subscribers the samples show
+const sampleSize = 3
+
+// Events lists the events a hook may subscribe to
+var Events = outbox.SubscriberTopics
+
+// Client sends the deliveries. Targets are given 10 seconds to answer.
+var Client = &http.Client{Timeout: 10 * time.Second}
+
+// Delivery is the payload of TopicDelivery
+type Delivery struct {
+	HookID       uint      `json:"hook_id"`
+	EventID      uint      `json:"event_id"`
+	Event        string    `json:"event"`
+	SubscriberID uint      `json:"subscriber_id"`
+	OccurredAt   time.Time `json:"occurred_at"`
+}
+
+// IsEvent reports whether hooks may subscribe to event
+func IsEvent(event string) bool {
+	return slices.Contains(Events, event)
+}
+
+// ValidateTargetURL checks that deliveries can be POSTed to target: an absolute https URL
+func ValidateTargetURL(target string) error {
+	u, err := url.Parse(target)
+	if err != nil || u.Host == "" {
+		return errors.New("target_url must be an absolute URL")
+	}
+	if u.Scheme != "https" {
+		return errors.New("target_url must use https")
+	}
+	return nil
+}
+
+// Fanout is an outbox handler for the subscriber topics queueing a delivery per hook subscribed
+// to the event, so a target that is down only retries its own
+func Fanout(db *gorm.DB) outbox.Handler {
+	return func(event models.OutboxEvent) error {
+		payload, err := outbox.DecodeSubscriberEvent(event)
+		if err != nil {
+			return err
+		}
+		var hooks []models.RestHook
+		if err := db.Where("org_id = ? AND event = ?", event.OrgID, event.Topic).Order("id").Find(&hooks).Error; err != nil {
+			return err
+		}
+		if len(hooks) == 0 {
+			return nil
+		}
+		return db.Transaction(func(tx *gorm.DB) error {
+			for _, hook := range hooks {
+				if err := outbox.Enqueue(tx, TopicDelivery, event.OrgID, Delivery{
+					HookID:       hook.ID,
+					EventID:      event.ID,
+					Event:        event.Topic,
+					SubscriberID: payload.SubscriberID,
+					OccurredAt:   event.CreatedAt,
+				}); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+}
+
+// Deliver is the outbox handler of TopicDelivery POSTing the event, with the subscriber as it is
+// now, to the hook's target. A target answering anything but 2xx is retried with the outbox
+// backoff; one answering 410 Gone is unsubscribed.
+func Deliver(db *gorm.DB) outbox.Handler {
+	return func(event models.OutboxEvent) error {
+		var d Delivery
+		if err := json.Unmarshal([]byte(event.Payload), &d); err != nil {
+			return err
+		}
+		var hook models.RestHook
+		err := db.First(&hook, d.HookID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// unsubscribed since
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		ctx := context.Background()
+		subscriber, err := loadSubscriber(ctx, db, hook.OrgID, d.SubscriberID)
+		if err != nil {
+			return err
+		}
+		body, err := json.Marshal(dto.RestHookPayload{
+			ID:         d.EventID,
+			Event:      d.Event,
+			OccurredAt: d.OccurredAt,
+			Subscriber: dto.NewSubscriberResponse(*subscriber),
+		})
+		if err != nil {
+			return err
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.TargetURL, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", "mylo-rest-hooks")
+		resp, err := Client.Do(req)
+		if err != nil {
+			return fmt.Errorf("rest hook %d: %w", hook.ID, err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode == http.StatusGone {
+			log.Printf("REST hooks: target of hook %d (%s) is gone, unsubscribing it", hook.ID, hook.Event)
+			return db.Delete(&hook).Error
+		}
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return fmt.Errorf("rest hook %d: target answered %d", hook.ID, resp.StatusCode)
+		}
+		return nil
+	}
+}
+
+// loadSubscriber loads the subscriber of a delivery, deleted ones included. A subscriber
+// purged since is described by its id alone.
+func loadSubscriber(ctx context.Context, db *gorm.DB, orgID, id uint) (*models.Subscriber, error) {
+	repo := repository.NewSubscriberRepository(db)
+	s, err := repo.Find(ctx, orgID, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		s, err = repo.FindDeleted(ctx, orgID, id)
+	}
+	if errors.Is(err, repository.ErrNotFound) {
+		return &models.Subscriber{ID: id, OrgID: orgID}, nil
+	}
+	return s, err
+}
+
+// Sample returns payloads of event for the most recent subscribers of the organization, or a
+// made-up one when it has none yet, so no-code tools can show the fields while a zap is built
+func Sample(ctx context.Context, db *gorm.DB, orgID uint, event string) ([]dto.RestHookPayload, error) {
+	var subscribers []models.Subscriber
+	if err := db.WithContext(ctx).Where("org_id = ?", orgID).Preload("SubscriberTypes").
+		Order("id DESC").Limit(sampleSize).Find(&subscribers).Error; err != nil {
+		return nil, err
+	}
+	if len(subscribers) == 0 {
+		now := time.Now()
+		subscribers = []models.Subscriber{{
+			ID:        1,
+			OrgID:     orgID,
+			Email:     "jane.doe@example.com",
+			Name:      "Jane Doe",
+			Status:    models.SubscriberStatusActive,
+			Locale:    "en",
+			Version:   1,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}}
+	}
+	samples := make([]dto.RestHookPayload, len(subscribers))
+	for i, s := range subscribers {
+		samples[i] = dto.RestHookPayload{
+			ID:         s.ID,
+			Event:      event,
+			OccurredAt: s.UpdatedAt,
+			Subscriber: dto.NewSubscriberResponse(s),
+		}
+	}
+	return samples, nil
+}
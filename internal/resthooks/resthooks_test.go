@@ -0,0 +1,108 @@
+package resthooks
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/models"
+	"fiber-gorm-api/internal/outbox"
+	"fiber-gorm-api/internal/testutil/testenv"
+)
+
+func TestValidateTargetURL(t *testing.T) {
+	if err := ValidateTargetURL("https://hooks.zapier.com/hooks/standard/1/abc/"); err != nil {
+		t.Errorf("Expected an https URL accepted, got %v", err)
+	}
+	for _, target := range []string{"", "hooks.zapier.com/abc", "http://hooks.zapier.com/abc", "ftp://example.com/x"} {
+		if ValidateTargetURL(target) == nil {
+			t.Errorf("%q: expected an error", target)
+		}
+	}
+}
+
+func TestDelivery(t *testing.T) {
+	conn := testenv.SQLite(t)
+	var received []dto.RestHookPayload
+	status := http.StatusOK
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload dto.RestHookPayload
+		json.NewDecoder(r.Body).Decode(&payload)
+		received = append(received, payload)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+	original := Client
+	t.Cleanup(func() { Client = original })
+	Client = server.Client()
+
+	ada := models.Subscriber{OrgID: models.DefaultOrgID, Email: "ada@example.com", Name: "Ada", Status: models.SubscriberStatusActive}
+	conn.Create(&ada)
+	created := models.RestHook{OrgID: models.DefaultOrgID, Event: outbox.TopicSubscriberCreated, TargetURL: server.URL + "/created"}
+	deleted := models.RestHook{OrgID: models.DefaultOrgID, Event: outbox.TopicSubscriberDeleted, TargetURL: server.URL + "/deleted"}
+	otherOrg := models.RestHook{OrgID: models.DefaultOrgID + 1, Event: outbox.TopicSubscriberCreated, TargetURL: server.URL + "/other"}
+	for _, h := range []*models.RestHook{&created, &deleted, &otherOrg} {
+		conn.Create(h)
+	}
+
+	if err := outbox.EnqueueSubscriber(conn, outbox.TopicSubscriberCreated, &ada); err != nil {
+		t.Fatal(err)
+	}
+	var event models.OutboxEvent
+	conn.Where("topic = ?", outbox.TopicSubscriberCreated).First(&event)
+	if err := Fanout(conn)(event); err != nil {
+		t.Fatal(err)
+	}
+	var deliveries []models.OutboxEvent
+	conn.Where("topic = ?", TopicDelivery).Find(&deliveries)
+	if len(deliveries) != 1 {
+		t.Fatalf("Expected one delivery, for the hook of the organization on the event, got %d", len(deliveries))
+	}
+
+	t.Run("Delivers", func(t *testing.T) {
+		if err := Deliver(conn)(deliveries[0]); err != nil {
+			t.Fatal(err)
+		}
+		if len(received) != 1 || received[0].ID != event.ID || received[0].Event != outbox.TopicSubscriberCreated ||
+			received[0].Subscriber.Email != "ada@example.com" {
+			t.Errorf("Unexpected payloads %+v", received)
+		}
+	})
+
+	t.Run("Retries failures", func(t *testing.T) {
+		status = http.StatusServiceUnavailable
+		if err := Deliver(conn)(deliveries[0]); err == nil {
+			t.Error("Expected an error to retry the delivery")
+		}
+	})
+
+	t.Run("Unsubscribes gone targets", func(t *testing.T) {
+		status = http.StatusGone
+		if err := Deliver(conn)(deliveries[0]); err != nil {
+			t.Fatal(err)
+		}
+		var n int64
+		conn.Model(&models.RestHook{}).Where("id = ?", created.ID).Count(&n)
+		if n != 0 {
+			t.Error("Expected the hook deleted")
+		}
+		before := len(received)
+		if err := Deliver(conn)(deliveries[0]); err != nil || len(received) != before {
+			t.Errorf("Expected deliveries of a deleted hook dropped, got %v", err)
+		}
+	})
+
+	t.Run("Deleted subscribers", func(t *testing.T) {
+		status = http.StatusOK
+		conn.Delete(&ada)
+		delivery, _ := json.Marshal(Delivery{HookID: deleted.ID, EventID: 99, Event: outbox.TopicSubscriberDeleted, SubscriberID: ada.ID})
+		if err := Deliver(conn)(models.OutboxEvent{Topic: TopicDelivery, Payload: string(delivery)}); err != nil {
+			t.Fatal(err)
+		}
+		if last := received[len(received)-1]; last.Subscriber.ID != ada.ID || last.Subscriber.Email != "ada@example.com" {
+			t.Errorf("Expected the deleted subscriber described, got %+v", last.Subscriber)
+		}
+	})
+}
@@ -0,0 +1,24 @@
+package admin
+
+import (
+	"fiber-gorm-api/internal/handlers"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// RegisterApiKeyRoutes registers API key management under /admin/api-keys (admin scope only).
+func RegisterApiKeyRoutes(adminGroup fiber.Router, db *gorm.DB) {
+	keys := adminGroup.Group("/api-keys", middleware.RequireScope(models.ScopeAdmin))
+
+	// Create (returns the plaintext key once)
+	keys.Post("/", handlers.CreateApiKey(db))
+
+	// Read all
+	keys.Get("/", handlers.GetAllApiKeys(db))
+
+	// Revoke
+	keys.Delete("/:id", handlers.RevokeApiKey(db))
+}
@@ -0,0 +1,150 @@
+package admin
+
+import (
+	"encoding/json"
+	"fiber-gorm-api/internal/db"
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
+	redisclient "fiber-gorm-api/internal/redis"
+	"fiber-gorm-api/internal/session"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestAdminApiKeyRoutes(t *testing.T) {
+	database := db.Connect(true)
+	redisclient.InitRedis("session")
+
+	app := fiber.New()
+	app.Use(middleware.RequireJWTOrAPIKey(database))
+	RegisterSubscriberRoutes(app, database)
+	RegisterApiKeyRoutes(app, database)
+
+	sess, err := session.Create(redisclient.Ctx, "apikey-admin@example.com", models.DefaultOrgID, "", "")
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	token, err := middleware.GenerateJWT(sess.ID)
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+
+	// 1) a signed-in admin creates a read-only key
+	var created dto.CreatedApiKeyResponse
+	t.Run("CreateApiKey - Success", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/api-keys", strings.NewReader(`{"name":"read only","scopes":["read"]}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("Expected 201, got %d", resp.StatusCode)
+		}
+		json.NewDecoder(resp.Body).Decode(&created)
+		if !strings.HasPrefix(created.Key, "mylo_") {
+			t.Errorf("Expected a mylo_ key, got %q", created.Key)
+		}
+	})
+
+	withKey := func(method, url, body string) *http.Request {
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(middleware.APIKeyHeader, created.Key)
+		return req
+	}
+
+	t.Run("ApiKey - Read Allowed", func(t *testing.T) {
+		resp, err := app.Test(withKey("GET", "/subscribers", ""), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected 200, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("ApiKey - Write Forbidden", func(t *testing.T) {
+		resp, err := app.Test(withKey("POST", "/subscribers", `{"email":"k@example.com","name":"K"}`), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("Expected 403 for a read-only key, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("ApiKey - Cannot Manage Keys", func(t *testing.T) {
+		resp, err := app.Test(withKey("GET", "/api-keys", ""), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("Expected 403 without the admin scope, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("RevokeApiKey - Key Stops Working", func(t *testing.T) {
+		req := httptest.NewRequest("DELETE", fmt.Sprintf("/api-keys/%d", created.ID), nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusNoContent {
+			t.Fatalf("Expected 204, got %d", resp.StatusCode)
+		}
+
+		resp, err = app.Test(withKey("GET", "/subscribers", ""), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Expected 401 with a revoked key, got %d", resp.StatusCode)
+		}
+	})
+}
+
+func TestAdminApiKeyRedaction(t *testing.T) {
+	database := db.Connect(true)
+	redisclient.InitRedis("session")
+
+	app := fiber.New()
+	app.Use(middleware.RequireJWTOrAPIKey(database))
+	RegisterSubscriberRoutes(app, database)
+
+	s := models.Subscriber{Email: "jane.redacted@example.com", Name: "Jane"}
+	database.Create(&s)
+
+	getWithScopes := func(scopes string) dto.SubscriberResponse {
+		plaintext := "mylo_test_" + scopes + fmt.Sprint(s.ID)
+		key := models.ApiKey{Name: "redaction " + scopes, Prefix: "mylo_test", KeyHash: middleware.HashAPIKey(plaintext), Scopes: scopes}
+		if err := database.Create(&key).Error; err != nil {
+			t.Fatalf("failed to create key: %v", err)
+		}
+
+		req := httptest.NewRequest("GET", fmt.Sprintf("/subscribers/%d", s.ID), nil)
+		req.Header.Set(middleware.APIKeyHeader, plaintext)
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		var out dto.SubscriberResponse
+		json.NewDecoder(resp.Body).Decode(&out)
+		return out
+	}
+
+	if got := getWithScopes("read"); got.Email != "j***@example.com" {
+		t.Errorf("Expected masked email for a viewer key, got %q", got.Email)
+	}
+	if got := getWithScopes("read,pii"); got.Email != s.Email {
+		t.Errorf("Expected raw email with the pii scope, got %q", got.Email)
+	}
+}
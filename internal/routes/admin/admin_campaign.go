@@ -0,0 +1,42 @@
+package admin
+
+import (
+	"fiber-gorm-api/internal/handlers"
+	"fiber-gorm-api/internal/middleware"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// RegisterCampaignRoutes registers the CRUD of the organization's email campaigns under
+// /admin/campaigns, pausing, resuming and cancelling their runs, and their stats
+func RegisterCampaignRoutes(adminGroup fiber.Router, db *gorm.DB) {
+	campaignGroup := adminGroup.Group("/campaigns", middleware.RequireMethodScope)
+
+	// Read all
+	campaignGroup.Get("/", handlers.GetCampaigns(db))
+
+	// Read one
+	campaignGroup.Get("/:id", handlers.GetCampaign(db))
+
+	// Sends and opens of the current run, and its A/B test
+	campaignGroup.Get("/:id/stats", handlers.GetCampaignStats(db))
+
+	// Create
+	campaignGroup.Post("/", handlers.CreateCampaign(db))
+
+	// Update
+	campaignGroup.Put("/:id", handlers.UpdateCampaign(db))
+
+	// Delete
+	campaignGroup.Delete("/:id", handlers.DeleteCampaign(db))
+
+	// Stop sending until resumed
+	campaignGroup.Post("/:id/pause", handlers.PauseCampaign(db))
+
+	// Schedule again, picking up an interrupted run
+	campaignGroup.Post("/:id/resume", handlers.ResumeCampaign(db))
+
+	// Stop for good
+	campaignGroup.Post("/:id/cancel", handlers.CancelCampaign(db))
+}
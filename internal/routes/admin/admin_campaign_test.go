@@ -0,0 +1,212 @@
+package admin
+
+import (
+	"encoding/json"
+	"fiber-gorm-api/internal/db"
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
+	redisclient "fiber-gorm-api/internal/redis"
+	"fiber-gorm-api/internal/session"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestAdminCampaignRoutes(t *testing.T) {
+	database := db.Connect(true)
+	redisclient.InitRedis("session")
+
+	app := fiber.New()
+	adminGroup := app.Group("/admin", middleware.RequireJWTOrAPIKey(database))
+	RegisterCampaignRoutes(adminGroup, database)
+	RegisterSegmentRoutes(adminGroup, database)
+
+	sess, err := session.Create(redisclient.Ctx, "campaigns@example.com", models.DefaultOrgID, "", "")
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	token, err := middleware.GenerateJWT(sess.ID)
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+	request := func(method, url, body string) *http.Request {
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		return req
+	}
+	send := func(method, url, body string) (*http.Response, dto.CampaignResponse) {
+		t.Helper()
+		resp, err := app.Test(request(method, url, body), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		var campaign dto.CampaignResponse
+		json.NewDecoder(resp.Body).Decode(&campaign)
+		return resp, campaign
+	}
+
+	segment := models.Segment{OrgID: models.DefaultOrgID, Name: fmt.Sprintf("campaign-%d", time.Now().UnixNano()), Filter: models.JSONMap{}}
+	database.Create(&segment)
+
+	t.Run("CreateCampaign - Invalid", func(t *testing.T) {
+		for body, code := range map[string]string{
+			`{"name":"Roundup","text":"Hello"}`:                                                           "missing_subject",
+			`{"name":"Roundup","subject":"News"}`:                                                         "missing_content",
+			`{"name":"Roundup","subject":"News","text":"Hello","timezone":"Mars/Olympus"}`:                "invalid_schedule",
+			`{"name":"Roundup","subject":"News","text":"Hello","send_at":"2020-01-01T09:00"}`:             "invalid_schedule",
+			`{"name":"Roundup","subject":"News","text":"Hello","send_at":"next week"}`:                    "invalid_schedule",
+			`{"name":"Roundup","subject":"News","text":"Hello","recurrence":"@every 5m"}`:                 "invalid_schedule",
+			`{"name":"Roundup","subject":"News","text":"Hello","filter":{"status":["gone"]}}`:             "invalid_filter",
+			`{"name":"Roundup","subject":"News","text":"Hello","segment_id":999999999}`:                   "unknown_segment",
+			`{"name":"Roundup","subject":"News","text":"Hello","filter":{"query":"status:active AND ("}}`: "invalid_filter",
+			`{"name":"Roundup","subject":"News","text":"Hello","test_percent":20}`:                        "invalid_ab_test",
+			`{"name":"Roundup","subject":"News","subject_b":"Olds","text":"Hello","test_percent":80}`:     "invalid_ab_test",
+			`{"name":"Roundup","subject":"News","subject_b":"News","text":"Hello","test_percent":20}`:     "invalid_ab_test",
+		} {
+			resp, err := app.Test(request("POST", "/admin/campaigns", body), -1)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			var got map[string]interface{}
+			json.NewDecoder(resp.Body).Decode(&got)
+			if resp.StatusCode != http.StatusBadRequest || got["code"] != code {
+				t.Errorf("%s: expected 400 %s, got %d %v", body, code, resp.StatusCode, got["code"])
+			}
+		}
+
+		body := fmt.Sprintf(`{"name":"Roundup","subject":"News","text":"Hello","segment_id":%d,"filter":{"status":["active"]}}`, segment.ID)
+		if resp, _ := send("POST", "/admin/campaigns", body); resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected 400 for segment_id and filter, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("CreateCampaign - Timezone", func(t *testing.T) {
+		body := fmt.Sprintf(`{"name":"Launch","subject":"We're open","text":"Hello","segment_id":%d,"send_at":"2099-07-01T09:00","timezone":"America/New_York"}`, segment.ID)
+		resp, campaign := send("POST", "/admin/campaigns", body)
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("Expected 201, got %d", resp.StatusCode)
+		}
+		if resp.Header.Get("Location") != fmt.Sprintf("/admin/campaigns/%d", campaign.ID) {
+			t.Errorf("Expected the Location of the campaign, got %q", resp.Header.Get("Location"))
+		}
+		if campaign.Status != models.CampaignScheduled || campaign.NextRunAt == nil ||
+			!campaign.NextRunAt.Equal(time.Date(2099, 7, 1, 13, 0, 0, 0, time.UTC)) {
+			t.Errorf("Expected it scheduled at 9:00 in New York, got %s at %v", campaign.Status, campaign.NextRunAt)
+		}
+
+		// a recurring one without send_at starts at the next occurrence
+		resp, campaign = send("POST", "/admin/campaigns", `{"name":"Weekly","subject":"This week","text":"Hello","recurrence":"0 9 * * MON","timezone":"Europe/Paris"}`)
+		if resp.StatusCode != http.StatusCreated || campaign.NextRunAt == nil || !campaign.NextRunAt.After(time.Now()) {
+			t.Fatalf("Expected the next Monday scheduled, got %d %v", resp.StatusCode, campaign.NextRunAt)
+		}
+		paris, _ := time.LoadLocation("Europe/Paris")
+		if at := campaign.NextRunAt.In(paris); at.Weekday() != time.Monday || at.Hour() != 9 {
+			t.Errorf("Expected Monday 9:00 in Paris, got %v", at)
+		}
+
+		// neither makes a draft
+		resp, campaign = send("POST", "/admin/campaigns", `{"name":"Someday","subject":"Maybe","html":"<p>Hello</p>"}`)
+		if resp.StatusCode != http.StatusCreated || campaign.Status != models.CampaignDraft || campaign.Timezone != "UTC" {
+			t.Errorf("Expected a draft in UTC, got %d %s %s", resp.StatusCode, campaign.Status, campaign.Timezone)
+		}
+	})
+
+	t.Run("Pause, Resume, Cancel", func(t *testing.T) {
+		_, campaign := send("POST", "/admin/campaigns", `{"name":"Digest","subject":"Digest","text":"Hello","recurrence":"@weekly"}`)
+		url := fmt.Sprintf("/admin/campaigns/%d", campaign.ID)
+
+		for _, step := range []struct {
+			method, path string
+			status       int
+			want         string
+		}{
+			{"POST", "/pause", http.StatusOK, models.CampaignPaused},
+			{"POST", "/pause", http.StatusConflict, ""},
+			{"PUT", "", http.StatusOK, models.CampaignPaused},
+			{"POST", "/resume", http.StatusOK, models.CampaignScheduled},
+			{"POST", "/resume", http.StatusConflict, ""},
+			{"POST", "/cancel", http.StatusOK, models.CampaignCancelled},
+			{"POST", "/cancel", http.StatusConflict, ""},
+			{"POST", "/resume", http.StatusConflict, ""},
+			{"PUT", "", http.StatusConflict, ""},
+		} {
+			resp, got := send(step.method, url+step.path, `{"name":"Digest","subject":"Weekly digest","text":"Hello","recurrence":"@weekly"}`)
+			if resp.StatusCode != step.status || (step.want != "" && got.Status != step.want) {
+				t.Errorf("%s %s: expected %d %s, got %d %s", step.method, step.path, step.status, step.want, resp.StatusCode, got.Status)
+			}
+		}
+
+		if resp, _ := send("DELETE", url, ""); resp.StatusCode != http.StatusNoContent {
+			t.Errorf("Expected a cancelled campaign deleted, got %d", resp.StatusCode)
+		}
+		if resp, _ := send("GET", url, ""); resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Expected 404 once deleted, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("GetCampaignStats - A/B Test", func(t *testing.T) {
+		resp, campaign := send("POST", "/admin/campaigns", `{"name":"A/B","subject":"Subject A","subject_b":"Subject B","text":"Hello","test_percent":20}`)
+		if resp.StatusCode != http.StatusCreated || campaign.TestHours != 4 {
+			t.Fatalf("Expected an A/B test of 4 hours by default, got %d %d", resp.StatusCode, campaign.TestHours)
+		}
+
+		// a run testing: A sent to 2 and opened once, B sent to 1, opened and clicked twice
+		database.Model(&models.Campaign{}).Where("id = ?", campaign.ID).Updates(map[string]interface{}{"status": models.CampaignTesting, "run": 1})
+		tag := fmt.Sprintf("stats-%d", time.Now().UnixNano())
+		link := models.CampaignLink{CampaignID: campaign.ID, URL: "https://example.com/" + tag}
+		database.Create(&link)
+		opened := time.Now()
+		for i, variant := range []string{"a", "a", "b"} {
+			subscriber := models.Subscriber{Email: fmt.Sprintf("%s-%d@example.com", tag, i), Name: "Recipient"}
+			database.Create(&subscriber)
+			send := models.CampaignSend{CampaignID: campaign.ID, Run: 1, SubscriberID: subscriber.ID, Email: subscriber.Email, Variant: variant, Test: true}
+			if i > 0 {
+				send.OpenedAt = &opened
+			}
+			if variant == "b" {
+				send.ClickedAt = &opened
+			}
+			database.Create(&send)
+			if variant == "b" {
+				for j := 0; j < 2; j++ {
+					database.Create(&models.CampaignEvent{CampaignID: campaign.ID, Run: 1, SendID: send.ID, Type: models.CampaignEventClick, LinkID: &link.ID})
+				}
+			}
+		}
+
+		resp, err := app.Test(request("GET", fmt.Sprintf("/admin/campaigns/%d/stats", campaign.ID), ""), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		var stats dto.CampaignStatsResponse
+		json.NewDecoder(resp.Body).Decode(&stats)
+		if resp.StatusCode != http.StatusOK || stats.Sent != 3 || stats.Opened != 2 || stats.Clicked != 1 || stats.ABTest == nil {
+			t.Fatalf("Expected 3 sent, 2 opened, 1 clicked and the test, got %d %+v", resp.StatusCode, stats)
+		}
+		variants := stats.ABTest.Variants
+		if len(variants) != 2 || variants[0].Subject != "Subject A" || variants[0].OpenRate != 0.5 || variants[0].ClickRate != 0 ||
+			variants[1].Subject != "Subject B" || variants[1].OpenRate != 1 || variants[1].ClickRate != 1 {
+			t.Errorf("Expected A opened at 50%% and B opened and clicked at 100%%, got %+v", variants)
+		}
+		if len(stats.Links) != 1 || stats.Links[0].URL != link.URL || stats.Links[0].Clicks != 2 || stats.Links[0].Recipients != 1 {
+			t.Errorf("Expected the link clicked twice by 1 recipient, got %+v", stats.Links)
+		}
+		if stats.ABTest.Winner != "" || stats.ABTest.Remainder != nil {
+			t.Errorf("Expected no winner yet, got %+v", stats.ABTest)
+		}
+	})
+
+	t.Run("DeleteSegment - In Use", func(t *testing.T) {
+		resp, _ := send("DELETE", fmt.Sprintf("/admin/segments/%d", segment.ID), "")
+		if resp.StatusCode != http.StatusConflict {
+			t.Errorf("Expected 409 for the segment of a scheduled campaign, got %d", resp.StatusCode)
+		}
+	})
+}
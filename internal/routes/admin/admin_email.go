@@ -0,0 +1,29 @@
+package admin
+
+import (
+	"fiber-gorm-api/internal/handlers"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// RegisterEmailRoutes registers the log of emails sent under /admin/emails and the sign-in
+// emails given up on under /admin/email-dead-letters. Emails span organizations, so only
+// platform admins see them, and they hold raw addresses, so they need the pii scope too.
+func RegisterEmailRoutes(adminGroup fiber.Router, db *gorm.DB) {
+	platformPII := []fiber.Handler{
+		middleware.RequireScope(models.ScopeAdmin),
+		middleware.RequireScope(models.ScopePII),
+		middleware.RequirePlatformOrg,
+	}
+
+	// Every email sent, or failed to send, with filters
+	emails := adminGroup.Group("/emails", platformPII...)
+	emails.Get("/", handlers.GetEmails(db))
+
+	// Sign-in emails the background queue gave up on
+	letters := adminGroup.Group("/email-dead-letters", platformPII...)
+	letters.Get("/", handlers.GetEmailDeadLetters(db))
+}
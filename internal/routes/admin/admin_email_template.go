@@ -0,0 +1,36 @@
+package admin
+
+import (
+	"fiber-gorm-api/internal/handlers"
+	"fiber-gorm-api/internal/middleware"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// RegisterEmailTemplateRoutes registers the CRUD of the organization's email templates under
+// /admin/email-templates, their preview and test send
+func RegisterEmailTemplateRoutes(adminGroup fiber.Router, db *gorm.DB) {
+	templateGroup := adminGroup.Group("/email-templates", middleware.RequireMethodScope)
+
+	// Read all
+	templateGroup.Get("/", handlers.GetEmailTemplates(db))
+
+	// Read one
+	templateGroup.Get("/:id", handlers.GetEmailTemplate(db))
+
+	// Rendered with sample or given variables
+	templateGroup.Post("/:id/preview", handlers.PreviewEmailTemplate(db))
+
+	// Rendered and emailed to the signed-in admin
+	templateGroup.Post("/:id/test-send", handlers.TestSendEmailTemplate(db))
+
+	// Create
+	templateGroup.Post("/", handlers.CreateEmailTemplate(db))
+
+	// Update
+	templateGroup.Put("/:id", handlers.UpdateEmailTemplate(db))
+
+	// Delete
+	templateGroup.Delete("/:id", handlers.DeleteEmailTemplate(db))
+}
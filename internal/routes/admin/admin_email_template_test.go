@@ -0,0 +1,173 @@
+package admin
+
+import (
+	"encoding/json"
+	"fiber-gorm-api/internal/db"
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
+	redisclient "fiber-gorm-api/internal/redis"
+	sendgridservice "fiber-gorm-api/internal/services"
+	"fiber-gorm-api/internal/session"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestAdminEmailTemplateRoutes(t *testing.T) {
+	database := db.Connect(true)
+	redisclient.InitRedis("session")
+
+	app := fiber.New()
+	adminGroup := app.Group("/admin", middleware.RequireJWTOrAPIKey(database))
+	RegisterEmailTemplateRoutes(adminGroup, database)
+
+	sess, err := session.Create(redisclient.Ctx, "templates@example.com", models.DefaultOrgID, "", "")
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	token, err := middleware.GenerateJWT(sess.ID)
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+	request := func(method, url, body string) *http.Request {
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		return req
+	}
+	decode := func(method, url, body string, out interface{}) *http.Response {
+		t.Helper()
+		resp, err := app.Test(request(method, url, body), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		json.NewDecoder(resp.Body).Decode(out)
+		return resp
+	}
+
+	t.Run("CreateEmailTemplate - Invalid", func(t *testing.T) {
+		for body, want := range map[string]struct {
+			status int
+			code   string
+		}{
+			`{"name":"Market","text":"Hello"}`:                                                          {http.StatusBadRequest, "missing_subject"},
+			`{"name":"Market","subject":"Hello"}`:                                                       {http.StatusBadRequest, "missing_content"},
+			`{"name":"Market","subject":"Hello {{.Name","text":"Hello"}`:                                {http.StatusUnprocessableEntity, "invalid_template"},
+			`{"name":"Market","subject":"Hello","html":"<p>\n{{if .X}}"}`:                               {http.StatusUnprocessableEntity, "invalid_template"},
+			`{"name":"Market","subject":"Hello","format":"rtf","text":"Hello"}`:                         {http.StatusBadRequest, "invalid_format"},
+			`{"name":"Market","subject":"Hello","format":"mjml","text":"Hello"}`:                        {http.StatusBadRequest, "missing_source"},
+			`{"name":"Market","subject":"Hello","format":"mjml","source":"<mjml><mj-body><mj-column>"}`: {http.StatusUnprocessableEntity, "invalid_template"},
+		} {
+			var got dto.TemplateErrorResponse
+			resp := decode("POST", "/admin/email-templates", body, &got)
+			if resp.StatusCode != want.status || got.Code != want.code {
+				t.Errorf("%s: expected %d %s, got %d %s", body, want.status, want.code, resp.StatusCode, got.Code)
+			}
+		}
+
+		var got dto.TemplateErrorResponse
+		decode("POST", "/admin/email-templates", `{"name":"Market","subject":"Hello","html":"<p>\n{{if .X}}"}`, &got)
+		if got.Part != "html" || got.Line != 2 {
+			t.Errorf("Expected the error located in the HTML at line 2, got %+v", got)
+		}
+	})
+
+	var created dto.EmailTemplateResponse
+	t.Run("CreateEmailTemplate", func(t *testing.T) {
+		body := `{"name":"Market day","subject":"See you Saturday, {{.FirstName}}","text":"Hi {{.Name}}, stall {{.Stall}}.","html":"<p>Hi {{.Name}}, stall {{.Stall}}.</p>"}`
+		resp := decode("POST", "/admin/email-templates", body, &created)
+		if resp.StatusCode != http.StatusCreated || resp.Header.Get("Location") != fmt.Sprintf("/admin/email-templates/%d", created.ID) {
+			t.Fatalf("Expected 201 and the Location of the template, got %d %q", resp.StatusCode, resp.Header.Get("Location"))
+		}
+		if created.CreatedBy != "templates@example.com" {
+			t.Errorf("Expected the template created by the caller, got %q", created.CreatedBy)
+		}
+	})
+
+	t.Run("PreviewEmailTemplate", func(t *testing.T) {
+		url := fmt.Sprintf("/admin/email-templates/%d/preview", created.ID)
+		var failed dto.TemplateErrorResponse
+		resp := decode("POST", url, "", &failed)
+		if resp.StatusCode != http.StatusUnprocessableEntity || failed.Code != "invalid_template" || failed.Part != "text" {
+			t.Errorf("Expected the missing Stall reported in the text, got %d %+v", resp.StatusCode, failed)
+		}
+
+		var preview dto.EmailTemplatePreviewResponse
+		resp = decode("POST", url, `{"variables":{"Stall":"12","Name":"Ada <L>"}}`, &preview)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected 200, got %d", resp.StatusCode)
+		}
+		want := dto.EmailTemplatePreviewResponse{
+			Subject: "See you Saturday, Jane",
+			Text:    "Hi Ada <L>, stall 12.",
+			HTML:    "<p>Hi Ada &lt;L&gt;, stall 12.</p>",
+		}
+		if preview != want {
+			t.Errorf("Expected %+v, got %+v", want, preview)
+		}
+	})
+
+	t.Run("TestSendEmailTemplate", func(t *testing.T) {
+		var to, subject, html string
+		original := sendgridservice.SendTemplateTestEmailFunc
+		t.Cleanup(func() { sendgridservice.SendTemplateTestEmailFunc = original })
+		sendgridservice.SendTemplateTestEmailFunc = func(toEmail, s, plainText, htmlContent string) error {
+			to, subject, html = toEmail, s, htmlContent
+			return nil
+		}
+
+		var sent dto.EmailTemplateTestSendResponse
+		resp := decode("POST", fmt.Sprintf("/admin/email-templates/%d/test-send", created.ID), `{"variables":{"Stall":"12"}}`, &sent)
+		if resp.StatusCode != http.StatusOK || to != "templates@example.com" || subject != "[Test] See you Saturday, Jane" || html != "<p>Hi Jane Doe, stall 12.</p>" {
+			t.Errorf("Expected the render sent to the admin, got %d to %q: %q %q", resp.StatusCode, to, subject, html)
+		}
+		if sent.To != to || sent.Subject != subject {
+			t.Errorf("Expected where it was sent, got %+v", sent)
+		}
+	})
+
+	t.Run("CreateEmailTemplate - Markdown and MJML", func(t *testing.T) {
+		var md dto.EmailTemplateResponse
+		resp := decode("POST", "/admin/email-templates", `{"name":"Market","subject":"Hello","format":"markdown","source":"# Hi {{.FirstName}}\n\nSee *you* there"}`, &md)
+		if resp.StatusCode != http.StatusCreated || md.Format != "markdown" || md.Text != md.Source || !strings.Contains(md.HTML, "<h1>Hi {{.FirstName}}</h1>") {
+			t.Fatalf("Expected the HTML compiled from the Markdown, got %d %+v", resp.StatusCode, md)
+		}
+		var preview dto.EmailTemplatePreviewResponse
+		decode("POST", fmt.Sprintf("/admin/email-templates/%d/preview", md.ID), "", &preview)
+		if !strings.Contains(preview.HTML, "<h1>Hi Jane</h1>") || !strings.Contains(preview.HTML, "<em>you</em>") {
+			t.Errorf("Expected the preview of the compiled HTML, got %q", preview.HTML)
+		}
+
+		var failed dto.TemplateErrorResponse
+		body := `{"name":"Market","subject":"Hello","format":"mjml","source":"<mjml>\n  <mj-body>\n    <mj-column>"}`
+		resp = decode("POST", "/admin/email-templates", body, &failed)
+		if resp.StatusCode != http.StatusUnprocessableEntity || failed.Part != "source" || failed.Line != 3 || failed.Column != 5 {
+			t.Errorf("Expected the error located in the source at 3:5, got %d %+v", resp.StatusCode, failed)
+		}
+	})
+
+	t.Run("UpdateEmailTemplate", func(t *testing.T) {
+		url := fmt.Sprintf("/admin/email-templates/%d", created.ID)
+		var updated dto.EmailTemplateResponse
+		resp := decode("PUT", url, `{"name":"Market day","subject":"Saturday","text":"Hi {{.Name}}"}`, &updated)
+		if resp.StatusCode != http.StatusOK || updated.Subject != "Saturday" || updated.HTML != "" || updated.CreatedBy != created.CreatedBy {
+			t.Errorf("Expected the template replaced, got %d %+v", resp.StatusCode, updated)
+		}
+	})
+
+	t.Run("DeleteEmailTemplate", func(t *testing.T) {
+		url := fmt.Sprintf("/admin/email-templates/%d", created.ID)
+		var ignored map[string]interface{}
+		if resp := decode("DELETE", url, "", &ignored); resp.StatusCode != http.StatusNoContent {
+			t.Errorf("Expected 204, got %d", resp.StatusCode)
+		}
+		if resp := decode("POST", url+"/preview", "", &ignored); resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Expected 404 once deleted, got %d", resp.StatusCode)
+		}
+	})
+}
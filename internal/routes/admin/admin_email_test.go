@@ -0,0 +1,121 @@
+package admin_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/models"
+	"fiber-gorm-api/internal/testutil"
+)
+
+func TestAdminEmailRoutes(t *testing.T) {
+	h, _ := testutil.New(t)
+	// a shared Postgres keeps the emails of earlier runs
+	for _, table := range []string{"email_logs", "email_dead_letters"} {
+		if err := h.DB.Exec("DELETE FROM " + table).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	token := h.Token(t, "email-admin@example.com", models.DefaultOrgID)
+	list := func(t *testing.T, url string, status int, out interface{}) http.Header {
+		t.Helper()
+		req := httptest.NewRequest("GET", url, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := h.App.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != status {
+			t.Fatalf("Expected %d, got %d", status, resp.StatusCode)
+		}
+		if out != nil {
+			if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+				t.Fatalf("Decoding: %v", err)
+			}
+		}
+		return resp.Header
+	}
+
+	subscriber := models.Subscriber{OrgID: models.DefaultOrgID, Email: "Ada@example.com", Name: "Ada", Status: models.SubscriberStatusActive}
+	if err := h.DB.Create(&subscriber).Error; err != nil {
+		t.Fatal(err)
+	}
+	yesterday := time.Now().Add(-24 * time.Hour)
+	logs := []models.EmailLog{
+		{Type: "confirmation", ToEmail: "ada@example.com", MessageID: "m1", Status: models.EmailStatusDelivered, CreatedAt: yesterday},
+		{Type: "signin_code", ToEmail: "ada@example.com", Status: models.EmailStatusFailed, Error: "sendgrid returned client error (400)"},
+		{Type: "signin_code", ToEmail: "grace@example.com", MessageID: "m2", Status: models.EmailStatusSent},
+	}
+	if err := h.DB.Create(&logs).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Subscriber emails, newest first", func(t *testing.T) {
+		var got []dto.EmailLogResponse
+		list(t, "/admin/subscribers/"+strconv.FormatUint(uint64(subscriber.ID), 10)+"/emails", http.StatusOK, &got)
+		if len(got) != 2 || got[0].Type != "signin_code" || got[1].Status != models.EmailStatusDelivered {
+			t.Errorf("Expected the two emails to ada, newest first, got %+v", got)
+		}
+	})
+
+	t.Run("Filters", func(t *testing.T) {
+		var got []dto.EmailLogResponse
+		list(t, "/admin/emails?type=signin_code", http.StatusOK, &got)
+		if len(got) != 2 {
+			t.Errorf("Expected 2 sign-in emails, got %d", len(got))
+		}
+		list(t, "/admin/emails?to=ADA@example.com&status=failed", http.StatusOK, &got)
+		if len(got) != 1 || got[0].Error == "" {
+			t.Errorf("Expected the failed email with its error, got %+v", got)
+		}
+		list(t, "/admin/emails?since="+time.Now().Add(-time.Hour).UTC().Format(time.RFC3339), http.StatusOK, &got)
+		if len(got) != 2 {
+			t.Errorf("Expected the 2 emails of the last hour, got %d", len(got))
+		}
+		list(t, "/admin/emails?until=yesterday", http.StatusBadRequest, nil)
+	})
+
+	t.Run("Pages", func(t *testing.T) {
+		var got []dto.EmailLogResponse
+		header := list(t, "/admin/emails?limit=2", http.StatusOK, &got)
+		if len(got) != 2 || header.Get("X-Next-Cursor") == "" {
+			t.Fatalf("Expected a page of 2 and a cursor, got %d", len(got))
+		}
+		list(t, "/admin/emails?limit=2&cursor="+header.Get("X-Next-Cursor"), http.StatusOK, &got)
+		if len(got) != 1 || got[0].ToEmail != "grace@example.com" {
+			t.Errorf("Expected the last email on the next page, got %+v", got)
+		}
+	})
+
+	t.Run("Dead letters", func(t *testing.T) {
+		if err := h.DB.Create(&models.EmailDeadLetter{OutboxEventID: 7, Kind: "magic_link", ToEmail: "ada@example.com", Attempts: 10, LastError: "connection refused"}).Error; err != nil {
+			t.Fatal(err)
+		}
+		var got []dto.EmailDeadLetterResponse
+		list(t, "/admin/email-dead-letters", http.StatusOK, &got)
+		if len(got) != 1 || got[0].Kind != "magic_link" || got[0].Attempts != 10 {
+			t.Errorf("Expected the dead letter, got %+v", got)
+		}
+		list(t, "/admin/email-dead-letters?sort=updated_at", http.StatusBadRequest, nil)
+	})
+
+	t.Run("Platform admins only", func(t *testing.T) {
+		org := models.Organization{Name: "Other", Slug: "other-emails"}
+		if err := h.DB.Create(&org).Error; err != nil {
+			t.Fatal(err)
+		}
+		other := h.Token(t, "other-admin@example.com", org.ID)
+		for _, url := range []string{"/admin/emails", "/admin/email-dead-letters"} {
+			req := httptest.NewRequest("GET", url, nil)
+			req.Header.Set("Authorization", "Bearer "+other)
+			if resp, err := h.App.Test(req, -1); err != nil || resp.StatusCode != http.StatusForbidden {
+				t.Errorf("Expected 403 for %s, got %v (%v)", url, resp, err)
+			}
+		}
+	})
+}
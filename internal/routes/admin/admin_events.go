@@ -0,0 +1,15 @@
+package admin
+
+import (
+	"fiber-gorm-api/internal/handlers"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RegisterEventRoutes registers the live event stream under /admin/events.
+func RegisterEventRoutes(adminGroup fiber.Router) {
+	// Server-Sent Events of subscriber changes in the caller's organization
+	adminGroup.Get("/events", middleware.RequireScope(models.ScopeRead), handlers.StreamEvents())
+}
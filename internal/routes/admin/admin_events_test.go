@@ -0,0 +1,74 @@
+package admin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
+	redisclient "fiber-gorm-api/internal/redis"
+	"fiber-gorm-api/internal/session"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestAdminEventRoutes(t *testing.T) {
+	redisclient.InitRedis("session")
+
+	app := fiber.New()
+	app.Use(middleware.RequireJWT)
+	RegisterEventRoutes(app)
+
+	t.Run("StreamEvents - Unauthorized", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/events", nil)
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Expected 401, got %d", resp.StatusCode)
+		}
+	})
+}
+
+func TestAdminWebSocketRoutes(t *testing.T) {
+	redisclient.InitRedis("session")
+
+	app := fiber.New()
+	app.Use("/ws", middleware.WebSocketBearer)
+	app.Use(middleware.RequireJWT)
+	RegisterWebSocketRoutes(app)
+
+	sess, err := session.Create(redisclient.Ctx, "ws-admin@example.com", models.DefaultOrgID, "10.0.0.1", "ws-browser")
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	token, err := middleware.GenerateJWT(sess.ID)
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+
+	t.Run("AdminWebSocket - Unauthorized", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/ws", nil)
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Expected 401, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("AdminWebSocket - Token as subprotocol, no upgrade", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/ws", nil)
+		req.Header.Set("Sec-WebSocket-Protocol", "bearer, "+token)
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusUpgradeRequired {
+			t.Errorf("Expected 426, got %d", resp.StatusCode)
+		}
+	})
+}
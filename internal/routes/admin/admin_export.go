@@ -0,0 +1,28 @@
+package admin
+
+import (
+	"fiber-gorm-api/internal/handlers"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// RegisterExportRoutes registers background subscriber exports under /admin/exports. Exports
+// hold raw emails, so they need the pii scope.
+func RegisterExportRoutes(adminGroup fiber.Router, db *gorm.DB) {
+	exportGroup := adminGroup.Group("/exports", middleware.RequireMethodScope, middleware.RequireScope(models.ScopePII))
+
+	// Queue
+	exportGroup.Post("/", handlers.CreateExport(db))
+
+	// Status and download link
+	exportGroup.Get("/:id", handlers.GetExport(db))
+}
+
+// RegisterPublicExportRoutes registers the signed download of /admin/exports/:id/download.
+// It must be registered before the authenticated /admin group: the signature is the credential.
+func RegisterPublicExportRoutes(router fiber.Router, corsHandler fiber.Handler, db *gorm.DB) {
+	router.Get("/admin/exports/:id/download", corsHandler, handlers.DownloadExport(db))
+}
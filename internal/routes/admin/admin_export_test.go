@@ -0,0 +1,174 @@
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fiber-gorm-api/internal/db"
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/exports"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
+	redisclient "fiber-gorm-api/internal/redis"
+	"fiber-gorm-api/internal/repository"
+	"fiber-gorm-api/internal/session"
+	"fiber-gorm-api/internal/testutil/testenv"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestAdminExportRoutes(t *testing.T) {
+	testenv.LocalStorage(t)
+
+	database := db.Connect(true)
+	redisclient.InitRedis("session")
+
+	app := fiber.New()
+	RegisterPublicExportRoutes(app, func(c *fiber.Ctx) error { return c.Next() }, database)
+	adminGroup := app.Group("/admin", middleware.RequireJWTOrAPIKey(database))
+	RegisterExportRoutes(adminGroup, database)
+
+	sess, err := session.Create(redisclient.Ctx, "export-admin@example.com", models.DefaultOrgID, "", "")
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	token, err := middleware.GenerateJWT(sess.ID)
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+	request := func(method, url string, body interface{}) *http.Request {
+		var reader io.Reader
+		if body != nil {
+			raw, _ := json.Marshal(body)
+			reader = bytes.NewReader(raw)
+		}
+		req := httptest.NewRequest(method, url, reader)
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+		return req
+	}
+
+	s := models.Subscriber{OrgID: models.DefaultOrgID, Email: "exported@example.com", Name: "Exported", Status: models.SubscriberStatusUnsubscribed}
+	if err := database.Create(&s).Error; err != nil {
+		t.Fatalf("failed to create subscriber: %v", err)
+	}
+
+	segment := models.Segment{OrgID: models.DefaultOrgID, Name: fmt.Sprintf("export-%d", time.Now().UnixNano()),
+		Filter: repository.SubscriberFilter{Statuses: []string{models.SubscriberStatusUnsubscribed}}.Map()}
+	if err := database.Create(&segment).Error; err != nil {
+		t.Fatalf("failed to create segment: %v", err)
+	}
+	unknownSegment := uint(999999)
+
+	t.Run("CreateExport - Invalid filters", func(t *testing.T) {
+		for _, body := range []dto.CreateExportRequest{
+			{Format: "xlsx"},
+			{Format: "csv", Filters: dto.ExportFilters{Status: []string{"nope"}}},
+			{Format: "csv", Filters: dto.ExportFilters{Frequency: "hourly"}},
+			{Format: "csv", SegmentID: &unknownSegment},
+			{Format: "csv", SegmentID: &segment.ID, Filters: dto.ExportFilters{Channel: "sms"}},
+		} {
+			resp, err := app.Test(request("POST", "/admin/exports", body), -1)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			if resp.StatusCode != http.StatusBadRequest {
+				t.Errorf("%+v: expected 400, got %d", body, resp.StatusCode)
+			}
+		}
+	})
+
+	var job dto.ExportJobResponse
+	t.Run("CreateExport - Queues", func(t *testing.T) {
+		body := dto.CreateExportRequest{Format: "csv", Filters: dto.ExportFilters{Status: []string{models.SubscriberStatusUnsubscribed}}}
+		resp, err := app.Test(request("POST", "/admin/exports", body), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusAccepted {
+			t.Fatalf("Expected 202, got %d", resp.StatusCode)
+		}
+		json.NewDecoder(resp.Body).Decode(&job)
+		if job.Status != models.ExportPending || job.DownloadURL != "" || job.RequestedBy != "export-admin@example.com" {
+			t.Errorf("Expected a pending job without a link, got %+v", job)
+		}
+		if loc := resp.Header.Get("Location"); loc != fmt.Sprintf("/admin/exports/%d", job.ID) {
+			t.Errorf("Expected the job in Location, got %q", loc)
+		}
+	})
+
+	t.Run("CreateExport - Segment", func(t *testing.T) {
+		resp, err := app.Test(request("POST", "/admin/exports", dto.CreateExportRequest{Format: "csv", SegmentID: &segment.ID}), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		var queued dto.ExportJobResponse
+		json.NewDecoder(resp.Body).Decode(&queued)
+		if resp.StatusCode != http.StatusAccepted || queued.SegmentID == nil || *queued.SegmentID != segment.ID ||
+			queued.Filters["status"] == nil {
+			t.Errorf("Expected a job with the segment's filter, got %d %+v", resp.StatusCode, queued)
+		}
+	})
+
+	t.Run("GetExport - Download link once done", func(t *testing.T) {
+		if _, err := exports.ProcessPending(database); err != nil {
+			t.Fatalf("Worker failed: %v", err)
+		}
+		resp, err := app.Test(request("GET", fmt.Sprintf("/admin/exports/%d", job.ID), nil), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected 200, got %d", resp.StatusCode)
+		}
+		json.NewDecoder(resp.Body).Decode(&job)
+		if job.Status != models.ExportDone || job.DownloadURL == "" || job.DownloadExpiresAt == nil {
+			t.Fatalf("Expected a done job with a link, got %+v", job)
+		}
+	})
+
+	t.Run("DownloadExport - Signed link, no session", func(t *testing.T) {
+		link, _ := url.Parse(job.DownloadURL)
+		resp, err := app.Test(httptest.NewRequest("GET", link.RequestURI(), nil), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected 200, got %d", resp.StatusCode)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		if !strings.Contains(string(body), "exported@example.com") {
+			t.Errorf("Expected the subscriber in the file, got %s", body)
+		}
+		if !strings.HasPrefix(resp.Header.Get("Content-Disposition"), "attachment") {
+			t.Errorf("Expected an attachment, got %q", resp.Header.Get("Content-Disposition"))
+		}
+
+		query := link.Query()
+		query.Set("signature", strings.Repeat("0", 64))
+		resp, err = app.Test(httptest.NewRequest("GET", link.Path+"?"+query.Encode(), nil), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("Expected 403 for a forged signature, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("GetExport - Unknown", func(t *testing.T) {
+		resp, err := app.Test(request("GET", "/admin/exports/999999", nil), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Expected 404, got %d", resp.StatusCode)
+		}
+	})
+}
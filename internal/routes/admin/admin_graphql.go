@@ -0,0 +1,16 @@
+package admin
+
+import (
+	"fiber-gorm-api/internal/handlers"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// RegisterGraphQLRoutes registers the read-only admin GraphQL endpoint under /admin/graphql.
+func RegisterGraphQLRoutes(adminGroup fiber.Router, db *gorm.DB) {
+	// Queries only, so POST needs just the read scope
+	adminGroup.Post("/graphql", middleware.RequireScope(models.ScopeRead), handlers.GraphQL(db))
+}
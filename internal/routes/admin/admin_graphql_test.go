@@ -0,0 +1,130 @@
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fiber-gorm-api/internal/db"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
+	redisclient "fiber-gorm-api/internal/redis"
+	"fiber-gorm-api/internal/session"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestAdminGraphQLRoutes(t *testing.T) {
+	database := db.Connect(true)
+	redisclient.InitRedis("session")
+
+	app := fiber.New()
+	app.Use(middleware.RequireJWT)
+	RegisterGraphQLRoutes(app, database)
+
+	sess, err := session.Create(redisclient.Ctx, "graphql-admin@example.com", models.DefaultOrgID, "10.0.0.1", "graphql-browser")
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	token, err := middleware.GenerateJWT(sess.ID)
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+
+	s := models.Subscriber{
+		Email:           "graphql-subscriber@example.com",
+		Name:            "GraphQL Subscriber",
+		Status:          models.SubscriberStatusActive,
+		SubscriberTypes: []models.SubscriberType{{Name: "shopper"}},
+	}
+	database.Create(&s)
+
+	post := func(body interface{}) *http.Response {
+		raw, _ := json.Marshal(body)
+		req := httptest.NewRequest("POST", "/graphql", bytes.NewReader(raw))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		return resp
+	}
+
+	t.Run("Query - Nested selection", func(t *testing.T) {
+		resp := post(map[string]interface{}{
+			"query": `query($search: String) {
+				subscribers(filter: {search: $search, subscriberType: "shopper"}) {
+					nodes { id email subscriberTypes { name } }
+				}
+				stats { totalSubscribers }
+			}`,
+			"variables": map[string]interface{}{"search": "graphql-subscriber"},
+		})
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected 200, got %d", resp.StatusCode)
+		}
+
+		var body struct {
+			Data struct {
+				Subscribers struct {
+					Nodes []struct {
+						ID              string `json:"id"`
+						Email           string `json:"email"`
+						SubscriberTypes []struct {
+							Name string `json:"name"`
+						} `json:"subscriberTypes"`
+					} `json:"nodes"`
+				} `json:"subscribers"`
+				Stats struct {
+					TotalSubscribers int64 `json:"totalSubscribers"`
+				} `json:"stats"`
+			} `json:"data"`
+			Errors []interface{} `json:"errors"`
+		}
+		json.NewDecoder(resp.Body).Decode(&body)
+		if len(body.Errors) > 0 {
+			t.Fatalf("Expected no errors, got %v", body.Errors)
+		}
+		nodes := body.Data.Subscribers.Nodes
+		if len(nodes) != 1 || nodes[0].Email != s.Email || len(nodes[0].SubscriberTypes) != 1 {
+			t.Errorf("Expected subscriber %d with its type, got %+v", s.ID, nodes)
+		}
+		if body.Data.Stats.TotalSubscribers < 1 {
+			t.Errorf("Expected at least 1 subscriber, got %d", body.Data.Stats.TotalSubscribers)
+		}
+	})
+
+	t.Run("Query - Invalid status filter", func(t *testing.T) {
+		resp := post(map[string]interface{}{
+			"query": `{ subscribers(filter: {status: ["gone"]}) { nodes { id } } }`,
+		})
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected 200, got %d", resp.StatusCode)
+		}
+		var body struct {
+			Errors []struct {
+				Message string `json:"message"`
+			} `json:"errors"`
+		}
+		json.NewDecoder(resp.Body).Decode(&body)
+		if len(body.Errors) == 0 {
+			t.Errorf("Expected an invalid status error")
+		}
+	})
+
+	t.Run("Query - Unknown field", func(t *testing.T) {
+		resp := post(map[string]interface{}{"query": `{ subscribers { nodes { password } } }`})
+		if resp.StatusCode != http.StatusUnprocessableEntity {
+			t.Errorf("Expected 422, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("Query - Missing query", func(t *testing.T) {
+		resp := post(map[string]interface{}{})
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected 400, got %d", resp.StatusCode)
+		}
+	})
+}
@@ -0,0 +1,28 @@
+package admin
+
+import (
+	"fiber-gorm-api/internal/handlers"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// RegisterImportRoutes registers background subscriber imports under /admin/imports. Imports
+// write raw emails and their error reports list them, so they need the pii scope.
+func RegisterImportRoutes(adminGroup fiber.Router, db *gorm.DB) {
+	importGroup := adminGroup.Group("/imports", middleware.RequireMethodScope, middleware.RequireScope(models.ScopePII))
+
+	// Upload
+	importGroup.Post("/", handlers.CreateImport(db))
+
+	// Progress and error report link
+	importGroup.Get("/:id", handlers.GetImport(db))
+}
+
+// RegisterPublicImportRoutes registers the signed download of /admin/imports/:id/errors.
+// It must be registered before the authenticated /admin group: the signature is the credential.
+func RegisterPublicImportRoutes(router fiber.Router, corsHandler fiber.Handler, db *gorm.DB) {
+	router.Get("/admin/imports/:id/errors", corsHandler, handlers.DownloadImportErrors(db))
+}
@@ -0,0 +1,156 @@
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fiber-gorm-api/internal/db"
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/imports"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
+	redisclient "fiber-gorm-api/internal/redis"
+	"fiber-gorm-api/internal/session"
+	"fiber-gorm-api/internal/testutil/testenv"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestAdminImportRoutes(t *testing.T) {
+	testenv.LocalStorage(t)
+
+	database := db.Connect(true)
+	redisclient.InitRedis("session")
+
+	app := fiber.New()
+	RegisterPublicImportRoutes(app, func(c *fiber.Ctx) error { return c.Next() }, database)
+	adminGroup := app.Group("/admin", middleware.RequireJWTOrAPIKey(database))
+	RegisterImportRoutes(adminGroup, database)
+
+	sess, err := session.Create(redisclient.Ctx, "import-admin@example.com", models.DefaultOrgID, "", "")
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	token, err := middleware.GenerateJWT(sess.ID)
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+	upload := func(fileName, format, content string) *http.Request {
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		if format != "" {
+			form.WriteField("format", format)
+		}
+		if fileName != "" {
+			part, _ := form.CreateFormFile("file", fileName)
+			io.WriteString(part, content)
+		}
+		form.Close()
+		req := httptest.NewRequest("POST", "/admin/imports", &body)
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", form.FormDataContentType())
+		return req
+	}
+	get := func(url string) *http.Request {
+		req := httptest.NewRequest("GET", url, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		return req
+	}
+
+	t.Run("CreateImport - Invalid uploads", func(t *testing.T) {
+		for _, req := range []*http.Request{
+			upload("", "", ""),
+			upload("subscribers.xlsx", "", "email\n"),
+			upload("subscribers.txt", "xml", "email\n"),
+		} {
+			resp, err := app.Test(req, -1)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			if resp.StatusCode != http.StatusBadRequest {
+				t.Errorf("Expected 400, got %d", resp.StatusCode)
+			}
+		}
+	})
+
+	var job dto.ImportJobResponse
+	t.Run("CreateImport - Queues", func(t *testing.T) {
+		content := "email,name\nimported@example.com,Imported\nbroken,Broken\n"
+		resp, err := app.Test(upload("subscribers.txt", "csv", content), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusAccepted {
+			t.Fatalf("Expected 202, got %d", resp.StatusCode)
+		}
+		json.NewDecoder(resp.Body).Decode(&job)
+		if job.Status != models.ImportPending || job.Format != models.ImportFormatCSV || job.FileName != "subscribers.txt" || job.RequestedBy != "import-admin@example.com" {
+			t.Errorf("Expected a pending CSV job, got %+v", job)
+		}
+		if loc := resp.Header.Get("Location"); loc != fmt.Sprintf("/admin/imports/%d", job.ID) {
+			t.Errorf("Expected the job in Location, got %q", loc)
+		}
+	})
+
+	t.Run("GetImport - Progress and error report once done", func(t *testing.T) {
+		if _, err := imports.ProcessPending(database); err != nil {
+			t.Fatalf("Worker failed: %v", err)
+		}
+		resp, err := app.Test(get(fmt.Sprintf("/admin/imports/%d", job.ID)), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected 200, got %d", resp.StatusCode)
+		}
+		json.NewDecoder(resp.Body).Decode(&job)
+		if job.Status != models.ImportDone || job.TotalRows != 2 || job.ProcessedRows != 2 || job.FailedRows != 1 {
+			t.Fatalf("Expected a done job of 2 rows, 1 failed, got %+v", job)
+		}
+		if job.ErrorReportURL == "" || job.ErrorReportExpiresAt == nil {
+			t.Fatalf("Expected an error report link, got %+v", job)
+		}
+	})
+
+	t.Run("DownloadImportErrors - Signed link, no session", func(t *testing.T) {
+		link, _ := url.Parse(job.ErrorReportURL)
+		resp, err := app.Test(httptest.NewRequest("GET", link.RequestURI(), nil), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected 200, got %d", resp.StatusCode)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		if !strings.Contains(string(body), "2,broken,invalid or missing email") {
+			t.Errorf("Expected the failed row in the report, got %s", body)
+		}
+
+		query := link.Query()
+		query.Set("signature", strings.Repeat("0", 64))
+		resp, err = app.Test(httptest.NewRequest("GET", link.Path+"?"+query.Encode(), nil), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("Expected 403 for a forged signature, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("GetImport - Unknown", func(t *testing.T) {
+		resp, err := app.Test(get("/admin/imports/999999"), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Expected 404, got %d", resp.StatusCode)
+		}
+	})
+}
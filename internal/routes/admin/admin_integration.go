@@ -0,0 +1,31 @@
+package admin
+
+import (
+	"fiber-gorm-api/internal/handlers"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// RegisterIntegrationRoutes registers mailing list provider integrations under
+// /admin/integrations (admin scope only: they hold the API key of the organization's account).
+func RegisterIntegrationRoutes(adminGroup fiber.Router, db *gorm.DB) {
+	integrationGroup := adminGroup.Group("/integrations", middleware.RequireScope(models.ScopeAdmin))
+
+	// Read all
+	integrationGroup.Get("/", handlers.GetIntegrations(db))
+
+	// Read one
+	integrationGroup.Get("/:provider", handlers.GetIntegration(db))
+
+	// Connect, change the API key, pause
+	integrationGroup.Put("/:provider", handlers.PutIntegration(db))
+
+	// Lists kept in sync
+	integrationGroup.Put("/:provider/lists", handlers.PutIntegrationLists(db))
+
+	// Disconnect
+	integrationGroup.Delete("/:provider", handlers.DeleteIntegration(db))
+}
@@ -0,0 +1,133 @@
+package admin_test
+
+import (
+	"context"
+	"encoding/json"
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/integrations"
+	"fiber-gorm-api/internal/models"
+	"fiber-gorm-api/internal/testutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stubConnector accepts the API key "good-us1" only
+type stubConnector struct{ apiKey string }
+
+func (s stubConnector) Ping(context.Context) error {
+	if s.apiKey != "good-us1" {
+		return integrations.ErrInvalidCredentials
+	}
+	return nil
+}
+
+func (stubConnector) Upsert(context.Context, string, []integrations.Member) ([]integrations.MemberError, error) {
+	return nil, nil
+}
+
+func (stubConnector) Unsubscribed(context.Context, string, time.Time) ([]integrations.Unsubscribe, error) {
+	return nil, nil
+}
+
+func TestAdminIntegrationRoutes(t *testing.T) {
+	original := integrations.NewConnector
+	t.Cleanup(func() { integrations.NewConnector = original })
+	integrations.NewConnector = func(_ string, apiKey string) (integrations.Connector, error) {
+		return stubConnector{apiKey: apiKey}, nil
+	}
+
+	h, _ := testutil.New(t)
+	app := h.App
+	token := h.Token(t, "integration-admin@example.com", models.DefaultOrgID)
+	request := func(method, url, body string) *http.Request {
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		return req
+	}
+	expect := func(t *testing.T, req *http.Request, status int, out interface{}) {
+		t.Helper()
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != status {
+			t.Fatalf("Expected %d, got %d", status, resp.StatusCode)
+		}
+		if out != nil {
+			json.NewDecoder(resp.Body).Decode(out)
+		}
+	}
+
+	t.Run("PutIntegration - Invalid", func(t *testing.T) {
+		expect(t, request("PUT", "/admin/integrations/convertkit", `{"api_key":"good-us1"}`), http.StatusNotFound, nil)
+		expect(t, request("PUT", "/admin/integrations/mailchimp", `{}`), http.StatusBadRequest, nil)
+
+		var body map[string]string
+		expect(t, request("PUT", "/admin/integrations/mailchimp", `{"api_key":"wrong-us1"}`), http.StatusBadRequest, &body)
+		if body["code"] != "invalid_credentials" {
+			t.Errorf("Expected code invalid_credentials, got %v", body)
+		}
+	})
+
+	t.Run("PutIntegration - Connect", func(t *testing.T) {
+		var integration dto.IntegrationResponse
+		expect(t, request("PUT", "/admin/integrations/mailchimp", `{"api_key":"good-us1"}`), http.StatusOK, &integration)
+		if !integration.Enabled || integration.APIKeyHint != "…" || integration.CreatedBy != "integration-admin@example.com" {
+			t.Errorf("Unexpected integration %+v", integration)
+		}
+
+		expect(t, request("PUT", "/admin/integrations/mailchimp", `{"enabled":false}`), http.StatusOK, &integration)
+		if integration.Enabled {
+			t.Error("Expected the sync paused")
+		}
+	})
+
+	t.Run("PutIntegrationLists", func(t *testing.T) {
+		var body map[string]string
+		expect(t, request("PUT", "/admin/integrations/mailchimp/lists", `{"lists":[{"remote_id":"abc","subscriber_type":"nope"}]}`),
+			http.StatusUnprocessableEntity, &body)
+		if body["code"] != "invalid_subscriber_type" {
+			t.Errorf("Expected code invalid_subscriber_type, got %v", body)
+		}
+		expect(t, request("PUT", "/admin/integrations/mailchimp/lists", `{"lists":[{"remote_id":"abc"},{"remote_id":"abc"}]}`),
+			http.StatusBadRequest, nil)
+
+		var integration dto.IntegrationResponse
+		expect(t, request("PUT", "/admin/integrations/mailchimp/lists", `{"lists":[{"remote_id":"abc"},{"remote_id":"def","subscriber_type":"donor"}]}`),
+			http.StatusOK, &integration)
+		if len(integration.Lists) != 2 || integration.Lists[1].SubscriberType != "donor" {
+			t.Fatalf("Expected the 2 lists mapped, got %+v", integration.Lists)
+		}
+
+		// the mapping kept keeps its id (and its sync cursors)
+		kept := integration.Lists[1].ID
+		expect(t, request("PUT", "/admin/integrations/mailchimp/lists", `{"lists":[{"remote_id":"def","subscriber_type":"donor"}]}`),
+			http.StatusOK, &integration)
+		if len(integration.Lists) != 1 || integration.Lists[0].ID != kept {
+			t.Errorf("Expected list def kept as it was, got %+v", integration.Lists)
+		}
+	})
+
+	t.Run("GetIntegrations", func(t *testing.T) {
+		var all []dto.IntegrationResponse
+		expect(t, request("GET", "/admin/integrations", ""), http.StatusOK, &all)
+		if len(all) != 1 || all[0].Provider != models.ProviderMailchimp {
+			t.Errorf("Expected the mailchimp integration, got %+v", all)
+		}
+		var one map[string]interface{}
+		expect(t, request("GET", "/admin/integrations/mailchimp", ""), http.StatusOK, &one)
+		if _, leaked := one["api_key"]; leaked {
+			t.Error("Expected the API key never returned")
+		}
+	})
+
+	t.Run("DeleteIntegration", func(t *testing.T) {
+		expect(t, request("DELETE", "/admin/integrations/mailchimp", ""), http.StatusNoContent, nil)
+		expect(t, request("GET", "/admin/integrations/mailchimp", ""), http.StatusNotFound, nil)
+		expect(t, request("DELETE", "/admin/integrations/mailchimp", ""), http.StatusNotFound, nil)
+	})
+}
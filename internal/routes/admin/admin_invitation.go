@@ -0,0 +1,35 @@
+package admin
+
+import (
+	"fiber-gorm-api/internal/handlers"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// RegisterInvitationRoutes registers invitation management (admin scope only) under
+// /admin/users/invite and /admin/invitations.
+func RegisterInvitationRoutes(adminGroup fiber.Router, db *gorm.DB) {
+	adminScope := middleware.RequireScope(models.ScopeAdmin)
+
+	// Invite (emails a single-use link)
+	adminGroup.Post("/users/invite", adminScope, handlers.InviteAdmin(db))
+
+	// Read all of my organization's invitations
+	adminGroup.Get("/invitations", adminScope, handlers.GetAllInvitations(db))
+
+	// Revoke
+	adminGroup.Delete("/invitations/:id", adminScope, handlers.RevokeInvitation(db))
+}
+
+// RegisterPublicInvitationRoutes registers the invitee's side of /admin/invitations/:token.
+// They must be registered before the authenticated /admin group: the invitee isn't signed in yet.
+func RegisterPublicInvitationRoutes(router fiber.Router, corsHandler fiber.Handler, db *gorm.DB) {
+	// Preview
+	router.Get("/admin/invitations/:token", corsHandler, handlers.GetInvitation(db))
+
+	// Accept (creates the admin and signs them in)
+	router.Post("/admin/invitations/:token", corsHandler, handlers.AcceptInvitation(db))
+}
@@ -0,0 +1,176 @@
+package admin
+
+import (
+	"encoding/json"
+	"fiber-gorm-api/internal/db"
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
+	redisclient "fiber-gorm-api/internal/redis"
+	sendgridservice "fiber-gorm-api/internal/services"
+	"fiber-gorm-api/internal/session"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestAdminInvitationRoutes(t *testing.T) {
+	database := db.Connect(true)
+	redisclient.InitRedis("session")
+
+	// capture the emailed link instead of sending it
+	var sentLink string
+	sendgridservice.SendInvitationEmailFunc = func(toEmail, orgName, link string) error {
+		sentLink = link
+		return nil
+	}
+
+	app := fiber.New()
+	RegisterPublicInvitationRoutes(app, func(c *fiber.Ctx) error { return c.Next() }, database)
+	adminGroup := app.Group("/admin", middleware.RequireJWTOrAPIKey(database))
+	RegisterInvitationRoutes(adminGroup, database)
+
+	sess, err := session.Create(redisclient.Ctx, "inviting-admin@example.com", models.DefaultOrgID, "", "")
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	token, err := middleware.GenerateJWT(sess.ID)
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+	authed := func(method, url, body string) *http.Request {
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		return req
+	}
+
+	inviteeEmail := fmt.Sprintf("invitee-%d@example.com", time.Now().UnixNano())
+
+	t.Run("InviteAdmin - Unknown Role", func(t *testing.T) {
+		body := fmt.Sprintf(`{"email":"%s","role":"superuser"}`, inviteeEmail)
+		resp, err := app.Test(authed("POST", "/admin/users/invite", body), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected 400, got %d", resp.StatusCode)
+		}
+	})
+
+	var invitation dto.InvitationResponse
+	t.Run("InviteAdmin - Success", func(t *testing.T) {
+		body := fmt.Sprintf(`{"email":"%s","role":"editor"}`, inviteeEmail)
+		resp, err := app.Test(authed("POST", "/admin/users/invite", body), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("Expected 201, got %d", resp.StatusCode)
+		}
+		json.NewDecoder(resp.Body).Decode(&invitation)
+		if invitation.Status != "pending" {
+			t.Errorf("Expected a pending invitation, got %q", invitation.Status)
+		}
+		if sentLink == "" {
+			t.Fatalf("Expected an invitation email to be sent")
+		}
+	})
+
+	t.Run("InviteAdmin - Already Pending", func(t *testing.T) {
+		body := fmt.Sprintf(`{"email":"%s","role":"viewer"}`, inviteeEmail)
+		resp, err := app.Test(authed("POST", "/admin/users/invite", body), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusConflict {
+			t.Errorf("Expected 409, got %d", resp.StatusCode)
+		}
+	})
+
+	invitationToken := sentLink[strings.LastIndex(sentLink, "/")+1:]
+
+	t.Run("GetInvitation - Preview Without Signing In", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/admin/invitations/"+invitationToken, nil)
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected 200, got %d", resp.StatusCode)
+		}
+		var preview dto.InvitationPreviewResponse
+		json.NewDecoder(resp.Body).Decode(&preview)
+		if preview.Email != inviteeEmail || preview.Role != models.RoleEditor {
+			t.Errorf("Unexpected preview %+v", preview)
+		}
+	})
+
+	t.Run("AcceptInvitation - Success", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/admin/invitations/"+invitationToken, nil)
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected 200, got %d", resp.StatusCode)
+		}
+		var tokenResp dto.TokenResponse
+		json.NewDecoder(resp.Body).Decode(&tokenResp)
+		if tokenResp.Token == "" {
+			t.Errorf("Expected the new admin to be signed in")
+		}
+
+		var admin models.AdminUser
+		if err := database.Where("email = ?", inviteeEmail).First(&admin).Error; err != nil {
+			t.Fatalf("Expected an admin user to be created: %v", err)
+		}
+		if admin.Role != models.RoleEditor {
+			t.Errorf("Expected role editor, got %q", admin.Role)
+		}
+	})
+
+	t.Run("AcceptInvitation - Single Use", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/admin/invitations/"+invitationToken, nil)
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusGone {
+			t.Errorf("Expected 410, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("RevokeInvitation - Pending", func(t *testing.T) {
+		body := fmt.Sprintf(`{"email":"revoked-%s","role":"viewer"}`, inviteeEmail)
+		resp, err := app.Test(authed("POST", "/admin/users/invite", body), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		var pending dto.InvitationResponse
+		json.NewDecoder(resp.Body).Decode(&pending)
+		revokedToken := sentLink[strings.LastIndex(sentLink, "/")+1:]
+
+		resp, err = app.Test(authed("DELETE", fmt.Sprintf("/admin/invitations/%d", pending.ID), ""), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected 200, got %d", resp.StatusCode)
+		}
+
+		req := httptest.NewRequest("POST", "/admin/invitations/"+revokedToken, nil)
+		resp, err = app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusGone {
+			t.Errorf("Expected 410 for a revoked invitation, got %d", resp.StatusCode)
+		}
+	})
+}
@@ -0,0 +1,30 @@
+package admin
+
+import (
+	"fiber-gorm-api/internal/handlers"
+	"fiber-gorm-api/internal/middleware"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// RegisterLocalEventRoutes registers the CRUD of the organization's local events under
+// /admin/local-events, /admin/events being the stream of subscriber changes
+func RegisterLocalEventRoutes(adminGroup fiber.Router, db *gorm.DB) {
+	eventGroup := adminGroup.Group("/local-events", middleware.RequireMethodScope)
+
+	// Read all
+	eventGroup.Get("/", handlers.GetLocalEvents(db))
+
+	// Read one
+	eventGroup.Get("/:id", handlers.GetLocalEvent(db))
+
+	// Create
+	eventGroup.Post("/", handlers.CreateLocalEvent(db))
+
+	// Update
+	eventGroup.Put("/:id", handlers.UpdateLocalEvent(db))
+
+	// Delete
+	eventGroup.Delete("/:id", handlers.DeleteLocalEvent(db))
+}
@@ -0,0 +1,167 @@
+package admin
+
+import (
+	"encoding/json"
+	"fiber-gorm-api/internal/db"
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
+	redisclient "fiber-gorm-api/internal/redis"
+	"fiber-gorm-api/internal/session"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestAdminLocalEventRoutes(t *testing.T) {
+	t.Setenv("TRACKING_BASE_URL", "https://api.example.com")
+	database := db.Connect(true)
+	redisclient.InitRedis("session")
+
+	app := fiber.New()
+	adminGroup := app.Group("/admin", middleware.RequireJWTOrAPIKey(database))
+	RegisterLocalEventRoutes(adminGroup, database)
+	RegisterCampaignRoutes(adminGroup, database)
+
+	sess, err := session.Create(redisclient.Ctx, "events@example.com", models.DefaultOrgID, "", "")
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	token, err := middleware.GenerateJWT(sess.ID)
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+	request := func(method, url, body string) *http.Request {
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		return req
+	}
+	send := func(method, url, body string) (*http.Response, dto.EventResponse) {
+		t.Helper()
+		resp, err := app.Test(request(method, url, body), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		var event dto.EventResponse
+		json.NewDecoder(resp.Body).Decode(&event)
+		return resp, event
+	}
+
+	other := models.Event{OrgID: models.DefaultOrgID + 1, Title: "Other", StartsAt: time.Now(), EndsAt: time.Now().Add(time.Hour), Timezone: "UTC"}
+	database.Create(&other)
+
+	t.Run("CreateLocalEvent - Invalid", func(t *testing.T) {
+		for body, code := range map[string]string{
+			`{"starts_at":"2025-07-05T09:00","ends_at":"2025-07-05T14:00"}`:                                                     "missing_title",
+			`{"title":"Market","starts_at":"2025-07-05T09:00"}`:                                                                 "invalid_time",
+			`{"title":"Market","starts_at":"tomorrow","ends_at":"2025-07-05T14:00"}`:                                            "invalid_time",
+			`{"title":"Market","starts_at":"2025-07-05T14:00","ends_at":"2025-07-05T09:00"}`:                                    "invalid_time",
+			`{"title":"Market","starts_at":"2025-07-05T09:00","ends_at":"2025-07-05T14:00","timezone":"Mars/Olympus"}`:          "invalid_time",
+			`{"title":"Market","starts_at":"2025-07-05T09:00","ends_at":"2025-07-05T14:00","url":"javascript:alert(1)"}`:        "invalid_url",
+			fmt.Sprintf(`{"title":"%s","starts_at":"2025-07-05T09:00","ends_at":"2025-07-05T14:00"}`, strings.Repeat("a", 256)): "too_long",
+		} {
+			resp, err := app.Test(request("POST", "/admin/local-events", body), -1)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			var got map[string]interface{}
+			json.NewDecoder(resp.Body).Decode(&got)
+			if resp.StatusCode != http.StatusBadRequest || got["code"] != code {
+				t.Errorf("%.80s: expected 400 %s, got %d %v", body, code, resp.StatusCode, got["code"])
+			}
+		}
+	})
+
+	var created dto.EventResponse
+	t.Run("CreateLocalEvent", func(t *testing.T) {
+		body := `{"title":" Summer market ","location":"Place du Marché","url":"https://market.example.org/summer","starts_at":"2025-07-05T09:00","ends_at":"2025-07-05T14:00","timezone":"Europe/Paris"}`
+		resp, event := send("POST", "/admin/local-events", body)
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("Expected 201, got %d", resp.StatusCode)
+		}
+		if resp.Header.Get("Location") != fmt.Sprintf("/admin/local-events/%d", event.ID) {
+			t.Errorf("Expected the Location of the event, got %q", resp.Header.Get("Location"))
+		}
+		start := time.Date(2025, 7, 5, 7, 0, 0, 0, time.UTC)
+		if event.Title != "Summer market" || !event.StartsAt.Equal(start) || !event.EndsAt.Equal(start.Add(5*time.Hour)) || event.Sequence != 0 {
+			t.Errorf("Expected the event read in its timezone, got %+v", event)
+		}
+		if event.CalendarURL != fmt.Sprintf("https://api.example.com/events/%d/calendar.ics", event.ID) {
+			t.Errorf("Unexpected calendar_url %s", event.CalendarURL)
+		}
+		created = event
+	})
+
+	t.Run("GetLocalEvents", func(t *testing.T) {
+		resp, err := app.Test(request("GET", "/admin/local-events", ""), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		var events []dto.EventResponse
+		json.NewDecoder(resp.Body).Decode(&events)
+		found := false
+		for _, e := range events {
+			found = found || e.ID == created.ID
+			if e.ID == other.ID {
+				t.Error("Expected the events of other organizations left out")
+			}
+		}
+		if resp.StatusCode != http.StatusOK || !found {
+			t.Errorf("Expected the event listed, got %d %+v", resp.StatusCode, events)
+		}
+		if resp, _ := send("GET", fmt.Sprintf("/admin/local-events/%d", other.ID), ""); resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Expected 404 for the event of another organization, got %d", resp.StatusCode)
+		}
+	})
+
+	var campaign dto.CampaignResponse
+	t.Run("CreateCampaign - Event", func(t *testing.T) {
+		for id, status := range map[uint]int{other.ID: http.StatusBadRequest, created.ID: http.StatusCreated} {
+			body := fmt.Sprintf(`{"name":"Summer market","subject":"See you Saturday","text":"Hello","event_id":%d}`, id)
+			resp, err := app.Test(request("POST", "/admin/campaigns", body), -1)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			if resp.StatusCode != status {
+				t.Errorf("Event %d: expected %d, got %d", id, status, resp.StatusCode)
+			}
+			if status == http.StatusCreated {
+				json.NewDecoder(resp.Body).Decode(&campaign)
+			}
+		}
+		if campaign.EventID == nil || *campaign.EventID != created.ID {
+			t.Errorf("Expected the campaign to announce the event, got %+v", campaign.EventID)
+		}
+	})
+
+	t.Run("UpdateLocalEvent", func(t *testing.T) {
+		url := fmt.Sprintf("/admin/local-events/%d", created.ID)
+		resp, event := send("PUT", url, `{"title":"Summer market","starts_at":"2025-07-05T10:00:00Z","ends_at":"2025-07-05T15:00:00Z"}`)
+		if resp.StatusCode != http.StatusOK || event.Sequence != 1 || event.Location != "" || event.Timezone != "UTC" || event.StartsAt.Hour() != 10 {
+			t.Errorf("Expected the event replaced and its sequence incremented, got %d %+v", resp.StatusCode, event)
+		}
+		if resp, _ := send("PUT", url, `{"title":"Summer market"}`); resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected 400 without times, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("DeleteLocalEvent", func(t *testing.T) {
+		url := fmt.Sprintf("/admin/local-events/%d", created.ID)
+		if resp, _ := send("DELETE", url, ""); resp.StatusCode != http.StatusNoContent {
+			t.Errorf("Expected 204, got %d", resp.StatusCode)
+		}
+		if resp, _ := send("GET", url, ""); resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Expected 404 once deleted, got %d", resp.StatusCode)
+		}
+		var kept models.Campaign
+		if err := database.First(&kept, campaign.ID).Error; err != nil || kept.EventID != nil {
+			t.Errorf("Expected the campaign kept without its event, got %+v (%v)", kept.EventID, err)
+		}
+	})
+}
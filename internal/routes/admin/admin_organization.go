@@ -0,0 +1,37 @@
+package admin
+
+import (
+	"fiber-gorm-api/internal/handlers"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// RegisterOrganizationRoutes registers organization management under /admin/organizations.
+// Creating, changing and deleting organizations is reserved to platform admins (default organization).
+func RegisterOrganizationRoutes(adminGroup fiber.Router, db *gorm.DB) {
+	orgs := adminGroup.Group("/organizations")
+	adminScope := middleware.RequireScope(models.ScopeAdmin)
+
+	// Create
+	orgs.Post("/", adminScope, middleware.RequirePlatformOrg, handlers.CreateOrganization(db))
+
+	// Read all (own organization unless platform admin)
+	orgs.Get("/", handlers.GetAllOrganizations(db))
+
+	// Read one
+	orgs.Get("/:id", handlers.GetOrganization(db))
+
+	// Update
+	orgs.Put("/:id", adminScope, middleware.RequirePlatformOrg, handlers.UpdateOrganization(db))
+
+	// Delete (only when empty)
+	orgs.Delete("/:id", adminScope, middleware.RequirePlatformOrg, handlers.DeleteOrganization(db))
+
+	// Admins assigned to the organization
+	orgs.Get("/:id/members", handlers.GetOrganizationMembers(db))
+	orgs.Post("/:id/members", adminScope, handlers.AddOrganizationMember(db))
+	orgs.Delete("/:id/members/:memberId", adminScope, handlers.RemoveOrganizationMember(db))
+}
@@ -0,0 +1,169 @@
+package admin
+
+import (
+	"encoding/json"
+	"fiber-gorm-api/internal/db"
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
+	redisclient "fiber-gorm-api/internal/redis"
+	"fiber-gorm-api/internal/session"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestAdminOrganizationRoutes(t *testing.T) {
+	database := db.Connect(true)
+	redisclient.InitRedis("session")
+
+	app := fiber.New()
+	app.Use(middleware.RequireJWTOrAPIKey(database))
+	RegisterSubscriberRoutes(app, database)
+	RegisterOrganizationRoutes(app, database)
+
+	tokenFor := func(email string, orgID uint) string {
+		sess, err := session.Create(redisclient.Ctx, email, orgID, "", "")
+		if err != nil {
+			t.Fatalf("failed to create session: %v", err)
+		}
+		token, err := middleware.GenerateJWT(sess.ID)
+		if err != nil {
+			t.Fatalf("failed to generate token: %v", err)
+		}
+		return token
+	}
+	request := func(method, url, body, token string) *http.Request {
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		return req
+	}
+
+	platformToken := tokenFor("platform-admin@example.com", models.DefaultOrgID)
+	slug := fmt.Sprintf("org-test-%d", time.Now().UnixNano())
+
+	var org dto.OrganizationResponse
+	t.Run("CreateOrganization - Success", func(t *testing.T) {
+		body := fmt.Sprintf(`{"name":"Test Community","slug":"%s"}`, slug)
+		resp, err := app.Test(request("POST", "/organizations", body, platformToken), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("Expected 201, got %d", resp.StatusCode)
+		}
+		json.NewDecoder(resp.Body).Decode(&org)
+	})
+
+	t.Run("CreateOrganization - Duplicate Slug", func(t *testing.T) {
+		body := fmt.Sprintf(`{"name":"Other","slug":"%s"}`, slug)
+		resp, err := app.Test(request("POST", "/organizations", body, platformToken), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusConflict {
+			t.Errorf("Expected 409, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("CreateOrganization - Invalid Slug", func(t *testing.T) {
+		resp, err := app.Test(request("POST", "/organizations", `{"name":"Bad","slug":"Not A Slug"}`, platformToken), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected 400, got %d", resp.StatusCode)
+		}
+	})
+
+	tenantToken := tokenFor("tenant-admin@example.com", org.ID)
+
+	t.Run("CreateOrganization - Tenant Admin Forbidden", func(t *testing.T) {
+		resp, err := app.Test(request("POST", "/organizations", `{"name":"Nope","slug":"nope-org"}`, tenantToken), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("Expected 403, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("AddOrganizationMember - Success", func(t *testing.T) {
+		email := fmt.Sprintf("member-%d@example.com", time.Now().UnixNano())
+		url := fmt.Sprintf("/organizations/%d/members", org.ID)
+		resp, err := app.Test(request("POST", url, fmt.Sprintf(`{"email":"%s"}`, email), platformToken), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusCreated {
+			t.Errorf("Expected 201, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("Subscribers - Scoped To Organization", func(t *testing.T) {
+		platformSub := models.Subscriber{OrgID: models.DefaultOrgID, Email: "platform-only@example.com", Name: "Platform Only"}
+		database.Create(&platformSub)
+
+		// the tenant can't read another organization's subscriber
+		resp, err := app.Test(request("GET", fmt.Sprintf("/subscribers/%d", platformSub.ID), "", tenantToken), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Expected 404 for a foreign subscriber, got %d", resp.StatusCode)
+		}
+
+		// subscribers the tenant creates land in its organization
+		body := `{"email":"tenant-sub@example.com","name":"Tenant Sub"}`
+		resp, err = app.Test(request("POST", "/subscribers", body, tenantToken), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("Expected 201, got %d", resp.StatusCode)
+		}
+		var created dto.SubscriberResponse
+		json.NewDecoder(resp.Body).Decode(&created)
+		if created.OrgID != org.ID {
+			t.Errorf("Expected org_id %d, got %d", org.ID, created.OrgID)
+		}
+
+		resp, err = app.Test(request("GET", "/subscribers", "", tenantToken), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		var listed []dto.SubscriberResponse
+		json.NewDecoder(resp.Body).Decode(&listed)
+		for _, s := range listed {
+			if s.OrgID != org.ID {
+				t.Errorf("Tenant listing leaked subscriber %d of org %d", s.ID, s.OrgID)
+			}
+		}
+	})
+
+	t.Run("DeleteOrganization - Not Empty", func(t *testing.T) {
+		resp, err := app.Test(request("DELETE", fmt.Sprintf("/organizations/%d", org.ID), "", platformToken), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusConflict {
+			t.Errorf("Expected 409, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("DeleteOrganization - Default Refused", func(t *testing.T) {
+		resp, err := app.Test(request("DELETE", fmt.Sprintf("/organizations/%d", models.DefaultOrgID), "", platformToken), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusConflict {
+			t.Errorf("Expected 409, got %d", resp.StatusCode)
+		}
+	})
+}
@@ -0,0 +1,28 @@
+package admin
+
+import (
+	"fiber-gorm-api/internal/handlers"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// RegisterRestHookRoutes registers REST hooks subscriptions (Zapier) under /admin/rest-hooks.
+// Deliveries hold raw emails, so they need the pii scope.
+func RegisterRestHookRoutes(adminGroup fiber.Router, db *gorm.DB) {
+	hookGroup := adminGroup.Group("/rest-hooks", middleware.RequireMethodScope, middleware.RequireScope(models.ScopePII))
+
+	// Read all
+	hookGroup.Get("/", handlers.GetRestHooks(db))
+
+	// Sample payloads
+	hookGroup.Get("/sample", handlers.GetRestHookSample(db))
+
+	// Subscribe
+	hookGroup.Post("/", handlers.SubscribeRestHook(db))
+
+	// Unsubscribe
+	hookGroup.Delete("/:id", handlers.UnsubscribeRestHook(db))
+}
@@ -0,0 +1,124 @@
+package admin
+
+import (
+	"encoding/json"
+	"fiber-gorm-api/internal/db"
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
+	redisclient "fiber-gorm-api/internal/redis"
+	"fiber-gorm-api/internal/session"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestAdminRestHookRoutes(t *testing.T) {
+	database := db.Connect(true)
+	redisclient.InitRedis("session")
+
+	app := fiber.New()
+	adminGroup := app.Group("/admin", middleware.RequireJWTOrAPIKey(database))
+	RegisterRestHookRoutes(adminGroup, database)
+
+	sess, err := session.Create(redisclient.Ctx, "zapier@example.com", models.DefaultOrgID, "", "")
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	token, err := middleware.GenerateJWT(sess.ID)
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+	request := func(method, url, body string) *http.Request {
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		return req
+	}
+
+	t.Run("SubscribeRestHook - Invalid", func(t *testing.T) {
+		for _, body := range []string{
+			`{"target_url":"https://hooks.zapier.com/1/","event":"subscriber.exploded"}`,
+			`{"target_url":"http://hooks.zapier.com/1/","event":"subscriber.created"}`,
+			`{"event":"subscriber.created"}`,
+		} {
+			resp, err := app.Test(request("POST", "/admin/rest-hooks", body), -1)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			if resp.StatusCode != http.StatusBadRequest {
+				t.Errorf("%s: expected 400, got %d", body, resp.StatusCode)
+			}
+		}
+	})
+
+	var hook dto.RestHookResponse
+	t.Run("SubscribeRestHook - Success", func(t *testing.T) {
+		resp, err := app.Test(request("POST", "/admin/rest-hooks", `{"target_url":"https://hooks.zapier.com/1/","event":"subscriber.created"}`), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("Expected 201, got %d", resp.StatusCode)
+		}
+		json.NewDecoder(resp.Body).Decode(&hook)
+		if hook.ID == 0 || hook.Event != "subscriber.created" || hook.CreatedBy != "zapier@example.com" {
+			t.Errorf("Unexpected hook %+v", hook)
+		}
+	})
+
+	t.Run("GetRestHooks", func(t *testing.T) {
+		resp, err := app.Test(request("GET", "/admin/rest-hooks", ""), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		var hooks []dto.RestHookResponse
+		json.NewDecoder(resp.Body).Decode(&hooks)
+		found := false
+		for _, h := range hooks {
+			found = found || h.ID == hook.ID
+		}
+		if !found {
+			t.Errorf("Expected hook %d listed, got %+v", hook.ID, hooks)
+		}
+	})
+
+	t.Run("GetRestHookSample", func(t *testing.T) {
+		resp, err := app.Test(request("GET", "/admin/rest-hooks/sample?event=subscriber.updated", ""), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected 200, got %d", resp.StatusCode)
+		}
+		var samples []dto.RestHookPayload
+		json.NewDecoder(resp.Body).Decode(&samples)
+		if len(samples) == 0 || samples[0].Event != "subscriber.updated" || samples[0].Subscriber.Email == "" {
+			t.Errorf("Expected sample payloads, got %+v", samples)
+		}
+
+		resp, _ = app.Test(request("GET", "/admin/rest-hooks/sample", ""), -1)
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected 400 without an event, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("UnsubscribeRestHook", func(t *testing.T) {
+		url := fmt.Sprintf("/admin/rest-hooks/%d", hook.ID)
+		resp, err := app.Test(request("DELETE", url, ""), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusNoContent {
+			t.Fatalf("Expected 204, got %d", resp.StatusCode)
+		}
+		resp, _ = app.Test(request("DELETE", url, ""), -1)
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Expected 404 once unsubscribed, got %d", resp.StatusCode)
+		}
+	})
+}
@@ -0,0 +1,22 @@
+package admin
+
+import (
+	"fiber-gorm-api/internal/handlers"
+	"fiber-gorm-api/internal/middleware"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// RegisterReviewQueueRoutes registers triage of the signups the spam checks quarantined under
+// /admin/review-queue.
+func RegisterReviewQueueRoutes(adminGroup fiber.Router, db *gorm.DB) {
+	queue := adminGroup.Group("/review-queue", middleware.RequireMethodScope, middleware.RecordAuthor)
+
+	// Read all
+	queue.Get("/", handlers.GetReviewQueue(db))
+
+	// Let through or delete
+	queue.Post("/:id/approve", handlers.ApproveQuarantinedSubscriber(db))
+	queue.Post("/:id/reject", handlers.RejectQuarantinedSubscriber(db))
+}
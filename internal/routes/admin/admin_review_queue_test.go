@@ -0,0 +1,130 @@
+package admin
+
+import (
+	"encoding/json"
+	"fiber-gorm-api/internal/db"
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
+	redisclient "fiber-gorm-api/internal/redis"
+	"fiber-gorm-api/internal/session"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestAdminReviewQueueRoutes(t *testing.T) {
+	database := db.Connect(true)
+	redisclient.InitRedis("session")
+
+	app := fiber.New()
+	app.Use(middleware.RequireJWTOrAPIKey(database))
+	RegisterReviewQueueRoutes(app, database)
+
+	sess, err := session.Create(redisclient.Ctx, "review-admin@example.com", models.DefaultOrgID, "", "")
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	token, err := middleware.GenerateJWT(sess.ID)
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+	request := func(method, url string) *http.Request {
+		req := httptest.NewRequest(method, url, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		return req
+	}
+
+	quarantine := func(email string) models.Subscriber {
+		s := models.Subscriber{
+			OrgID:       models.DefaultOrgID,
+			Email:       email,
+			Name:        "xKqZvBnT",
+			Status:      models.SubscriberStatusQuarantined,
+			SpamScore:   90,
+			SpamSignals: models.JSONMap{"ip_velocity": 50, "gibberish_name": 40},
+		}
+		if err := database.Create(&s).Error; err != nil {
+			t.Fatalf("failed to create subscriber: %v", err)
+		}
+		return s
+	}
+	approved := quarantine("review-approve@example.com")
+	rejected := quarantine("review-reject@example.com")
+
+	t.Run("GetReviewQueue - Lists quarantined signups", func(t *testing.T) {
+		resp, err := app.Test(request("GET", "/review-queue"), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected 200, got %d", resp.StatusCode)
+		}
+		var items []dto.ReviewQueueItem
+		json.NewDecoder(resp.Body).Decode(&items)
+		found := false
+		for _, item := range items {
+			if item.Status != models.SubscriberStatusQuarantined {
+				t.Errorf("Expected only quarantined subscribers, got %q", item.Status)
+			}
+			if item.ID == approved.ID {
+				found = item.SpamScore == 90 && item.SpamSignals["ip_velocity"] == float64(50)
+			}
+		}
+		if !found {
+			t.Errorf("Expected subscriber %d with its spam signals, got %+v", approved.ID, items)
+		}
+	})
+
+	t.Run("ApproveQuarantinedSubscriber - Activates", func(t *testing.T) {
+		resp, err := app.Test(request("POST", fmt.Sprintf("/review-queue/%d/approve", approved.ID)), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected 200, got %d", resp.StatusCode)
+		}
+		var body dto.SubscriberResponse
+		json.NewDecoder(resp.Body).Decode(&body)
+		if body.Status != models.SubscriberStatusActive {
+			t.Errorf("Expected an active subscriber, got %q", body.Status)
+		}
+
+		// it left the queue
+		resp, err = app.Test(request("POST", fmt.Sprintf("/review-queue/%d/approve", approved.ID)), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusConflict {
+			t.Errorf("Expected 409 approving it again, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("RejectQuarantinedSubscriber - Deletes", func(t *testing.T) {
+		resp, err := app.Test(request("POST", fmt.Sprintf("/review-queue/%d/reject", rejected.ID)), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusNoContent {
+			t.Fatalf("Expected 204, got %d", resp.StatusCode)
+		}
+		var count int64
+		database.Model(&models.Subscriber{}).Where("id = ?", rejected.ID).Count(&count)
+		if count != 0 {
+			t.Errorf("Expected the rejected subscriber to be deleted")
+		}
+	})
+
+	t.Run("RejectQuarantinedSubscriber - Unknown", func(t *testing.T) {
+		resp, err := app.Test(request("POST", "/review-queue/999999/reject"), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Expected 404, got %d", resp.StatusCode)
+		}
+	})
+}
@@ -1,6 +1,7 @@
 package admin
 
 import (
+	"fiber-gorm-api/internal/cache"
 	"fiber-gorm-api/internal/db"
 	"fiber-gorm-api/internal/middleware"
 
@@ -8,19 +9,95 @@ import (
 	"github.com/gofiber/fiber/v2/middleware/cors"
 )
 
-// RegisterAdminRoutes configures the admin group, applying CORS for admin.mylocal.ing
-// and registers all admin route files (subscribers).
+// RegisterAdminRoutes configures the admin group, applying CORS for admin.mylocal.ing (or CORS_ADMIN_ORIGINS)
+// and registers all admin route files (subscribers, review queue, exports, imports, integrations, rest hooks, subscriber types, signup forms, segments, campaigns, short links, local events, email templates, sessions, api keys, stats, organizations, invitations, emails, graphql, events, ws).
 func RegisterAdminRoutes(app *fiber.App) {
-	adminGroup := app.Group("/admin", cors.New(cors.Config{
-		AllowOrigins: "https://admin.mylocal.ing",
-		AllowHeaders: "Origin, Content-Type, Accept",
-	}),
-		middleware.RequireJWT, // <--- Enforce JWT for all admin routes
-	)
-
 	// Initialize DB
 	database := db.Connect(true)
 
+	// Optional read cache in the Redis entity DB
+	cache.Init()
+
+	corsHandler := middleware.CORS("admin", "https://admin.mylocal.ing", cors.Config{
+		AllowHeaders:  "Origin, Content-Type, Accept, Authorization, If-Match, If-None-Match, If-Modified-Since, X-API-Key",
+		ExposeHeaders: "ETag, X-Next-Cursor, X-Total-Count, X-Request-ID",
+	})
+
+	// Invitation links are opened by people who can't sign in yet
+	RegisterPublicInvitationRoutes(app, corsHandler, database)
+
+	// Export downloads and import error reports are signed links that work without a session
+	RegisterPublicExportRoutes(app, corsHandler, database)
+	RegisterPublicImportRoutes(app, corsHandler, database)
+
+	// Browsers can't set headers on a WebSocket upgrade and offer their JWT as a subprotocol
+	app.Use("/admin/ws", middleware.WebSocketBearer)
+
+	adminGroup := app.Group("/admin", corsHandler,
+		middleware.RequireJWTOrAPIKey(database), // <--- Enforce JWT (or an API key) for all admin routes
+	)
+
 	// Subscribers CRUD
 	RegisterSubscriberRoutes(adminGroup, database)
+
+	// Triage of quarantined signups
+	RegisterReviewQueueRoutes(adminGroup, database)
+
+	// Background subscriber exports
+	RegisterExportRoutes(adminGroup, database)
+
+	// Background subscriber imports
+	RegisterImportRoutes(adminGroup, database)
+
+	// Mailing list providers kept in sync (Mailchimp)
+	RegisterIntegrationRoutes(adminGroup, database)
+
+	// Subscriber events pushed to no-code tools (Zapier REST hooks)
+	RegisterRestHookRoutes(adminGroup, database)
+
+	// Allowed subscriber_types names
+	RegisterSubscriberTypeRoutes(adminGroup, database)
+
+	// Public signup forms, each with its own subscriber_types and custom fields
+	RegisterSignupFormRoutes(adminGroup, database)
+
+	// Saved audiences campaigns and exports are targeted at
+	RegisterSegmentRoutes(adminGroup, database)
+
+	// Email campaigns, scheduled once or recurring
+	RegisterCampaignRoutes(adminGroup, database)
+
+	// Short links of campaigns and other outreach, with their clicks
+	RegisterShortLinkRoutes(adminGroup, database)
+
+	// Local events campaigns announce, with their calendar invites
+	RegisterLocalEventRoutes(adminGroup, database)
+
+	// Reusable email templates, previewed and test-sent before they go out
+	RegisterEmailTemplateRoutes(adminGroup, database)
+
+	// Current user's sessions and trusted devices
+	RegisterSessionRoutes(adminGroup, database)
+
+	// API keys for scripts and integrations
+	RegisterApiKeyRoutes(adminGroup, database)
+
+	// Dashboard stats
+	RegisterStatsRoutes(adminGroup, database)
+
+	// Organizations (tenants) and their admins
+	RegisterOrganizationRoutes(adminGroup, database)
+
+	// Inviting new admins
+	RegisterInvitationRoutes(adminGroup, database)
+
+	// Emails sent, and sign-in emails the background queue gave up on
+	RegisterEmailRoutes(adminGroup, database)
+
+	// Flexible read queries for the admin frontend
+	RegisterGraphQLRoutes(adminGroup, database)
+
+	// Live updates of subscriber changes
+	RegisterEventRoutes(adminGroup)
+	RegisterWebSocketRoutes(adminGroup)
 }
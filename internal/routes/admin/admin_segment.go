@@ -0,0 +1,39 @@
+package admin
+
+import (
+	"fiber-gorm-api/internal/handlers"
+	"fiber-gorm-api/internal/middleware"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// RegisterSegmentRoutes registers the CRUD of the organization's saved audiences under
+// /admin/segments, the preview of their members and the validation of filter expressions
+func RegisterSegmentRoutes(adminGroup fiber.Router, db *gorm.DB) {
+	segmentGroup := adminGroup.Group("/segments", middleware.RequireMethodScope)
+
+	// Members a filter would hold, before it's saved
+	segmentGroup.Post("/preview", handlers.PreviewSegmentFilter(db))
+
+	// Parse errors of a filter expression, with their position
+	segmentGroup.Post("/validate", handlers.ValidateFilterExpression(db))
+
+	// Read all
+	segmentGroup.Get("/", handlers.GetSegments(db))
+
+	// Read one
+	segmentGroup.Get("/:id", handlers.GetSegment(db))
+
+	// Members it holds now
+	segmentGroup.Get("/:id/preview", handlers.PreviewSegment(db))
+
+	// Create
+	segmentGroup.Post("/", handlers.CreateSegment(db))
+
+	// Update
+	segmentGroup.Put("/:id", handlers.UpdateSegment(db))
+
+	// Delete
+	segmentGroup.Delete("/:id", handlers.DeleteSegment(db))
+}
@@ -0,0 +1,220 @@
+package admin
+
+import (
+	"encoding/json"
+	"fiber-gorm-api/internal/db"
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
+	redisclient "fiber-gorm-api/internal/redis"
+	"fiber-gorm-api/internal/session"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestAdminSegmentRoutes(t *testing.T) {
+	database := db.Connect(true)
+	redisclient.InitRedis("session")
+
+	app := fiber.New()
+	adminGroup := app.Group("/admin", middleware.RequireJWTOrAPIKey(database))
+	RegisterSegmentRoutes(adminGroup, database)
+	RegisterSubscriberRoutes(adminGroup, database)
+
+	sess, err := session.Create(redisclient.Ctx, "segments@example.com", models.DefaultOrgID, "", "")
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	token, err := middleware.GenerateJWT(sess.ID)
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+	request := func(method, url, body string) *http.Request {
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		return req
+	}
+
+	// tagged members of the segment, an untagged subscriber and one of another status
+	tag := fmt.Sprintf("segment-%d", time.Now().UnixNano())
+	members := []models.Subscriber{
+		{Email: tag + "-1@example.com", Name: "Member", Metadata: models.JSONMap{"tags": []string{tag, "vip"}},
+			SubscriberTypes: []models.SubscriberType{{Name: "donor"}}},
+		{Email: tag + "-2@example.com", Name: "Member", Metadata: models.JSONMap{"tags": []string{tag}},
+			SubscriberTypes: []models.SubscriberType{{Name: "shopper"}}},
+	}
+	others := []models.Subscriber{
+		{Email: tag + "-3@example.com", Name: "Untagged", SubscriberTypes: []models.SubscriberType{{Name: "donor"}}},
+		{Email: tag + "-4@example.com", Name: "Unsubscribed", Status: models.SubscriberStatusUnsubscribed,
+			Metadata: models.JSONMap{"tags": []string{tag}}, SubscriberTypes: []models.SubscriberType{{Name: "donor"}}},
+	}
+	database.Create(&members)
+	database.Create(&others)
+	filter := fmt.Sprintf(`{"status":["active"],"subscriber_types":["donor","shopper"],"tags":[%q]}`, tag)
+
+	t.Run("CreateSegment - Invalid", func(t *testing.T) {
+		for body, code := range map[string]string{
+			`{"name":"Donors","filter":{"status":["gone"]}}`:                                                              "invalid_filter",
+			`{"name":"Donors","filter":{"created_after":"2025-07-01T00:00:00Z","created_before":"2025-01-01T00:00:00Z"}}`: "invalid_filter",
+		} {
+			resp, err := app.Test(request("POST", "/admin/segments", body), -1)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			var got map[string]interface{}
+			json.NewDecoder(resp.Body).Decode(&got)
+			if resp.StatusCode != http.StatusBadRequest || got["code"] != code {
+				t.Errorf("%s: expected 400 %s, got %d %v", body, code, resp.StatusCode, got["code"])
+			}
+		}
+
+		resp, _ := app.Test(request("POST", "/admin/segments", `{"name":" ","filter":{}}`), -1)
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected 400 without a name, got %d", resp.StatusCode)
+		}
+		resp, _ = app.Test(request("POST", "/admin/segments", `{"name":"Donors","filter":{"subscriber_types":["doner"]}}`), -1)
+		if resp.StatusCode != http.StatusUnprocessableEntity {
+			t.Errorf("Expected 422 for an unknown subscriber_type, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("PreviewSegmentFilter - Counts Members", func(t *testing.T) {
+		resp, err := app.Test(request("POST", "/admin/segments/preview", fmt.Sprintf(`{"tags":[%q]}`, tag)), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		var preview dto.SegmentPreviewResponse
+		json.NewDecoder(resp.Body).Decode(&preview)
+		if resp.StatusCode != http.StatusOK || preview.Members != 3 || len(preview.ByStatus) != 2 || preview.ByStatus[0].Count != 2 {
+			t.Errorf("Expected 3 tagged subscribers, 2 of them active, got %d %+v", resp.StatusCode, preview)
+		}
+	})
+
+	var segment dto.SegmentResponse
+	t.Run("CreateSegment - Success", func(t *testing.T) {
+		body := fmt.Sprintf(`{"name":%q,"description":"Tagged donors and shoppers","filter":%s}`, tag, filter)
+		resp, err := app.Test(request("POST", "/admin/segments", body), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		json.NewDecoder(resp.Body).Decode(&segment)
+		if resp.StatusCode != http.StatusCreated || len(segment.Filter.Tags) != 1 || segment.CreatedBy != "segments@example.com" {
+			t.Fatalf("Expected 201 with the filter, got %d %+v", resp.StatusCode, segment)
+		}
+
+		resp, _ = app.Test(request("POST", "/admin/segments", body), -1)
+		if resp.StatusCode != http.StatusConflict {
+			t.Errorf("Expected 409 for a taken name, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("PreviewSegment - Counts Members", func(t *testing.T) {
+		resp, err := app.Test(request("GET", fmt.Sprintf("/admin/segments/%d/preview", segment.ID), ""), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		var preview dto.SegmentPreviewResponse
+		json.NewDecoder(resp.Body).Decode(&preview)
+		if resp.StatusCode != http.StatusOK || preview.Members != 2 {
+			t.Errorf("Expected the 2 members, got %d %+v", resp.StatusCode, preview)
+		}
+	})
+
+	t.Run("GetAllSubscribers - Segment", func(t *testing.T) {
+		resp, err := app.Test(request("GET", fmt.Sprintf("/admin/subscribers?segment=%d&subscriber_type=donor", segment.ID), ""), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		var subs []dto.SubscriberResponse
+		json.NewDecoder(resp.Body).Decode(&subs)
+		if resp.StatusCode != http.StatusOK || len(subs) != 1 || subs[0].ID != members[0].ID {
+			t.Errorf("Expected the donor member only, got %d %+v", resp.StatusCode, subs)
+		}
+
+		resp, _ = app.Test(request("GET", "/admin/subscribers?segment=999999", ""), -1)
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected 400 for an unknown segment, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("ValidateFilterExpression - Positions", func(t *testing.T) {
+		for query, want := range map[string]dto.FilterValidationResponse{
+			`type:donor AND NOT tag:vip`:      {Valid: true},
+			`type:donor AND tga:vip`:          {Error: &dto.FilterError{Position: 15, Length: 3}},
+			`type:doner`:                      {Error: &dto.FilterError{Position: 5, Length: 5}},
+			`(status:active OR created_at>x)`: {Error: &dto.FilterError{Position: 29, Length: 1}},
+			`(status:active`:                  {Error: &dto.FilterError{Position: 0, Length: 1}},
+		} {
+			body, _ := json.Marshal(dto.FilterValidationRequest{Query: query})
+			resp, err := app.Test(request("POST", "/admin/segments/validate", string(body)), -1)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			var got dto.FilterValidationResponse
+			json.NewDecoder(resp.Body).Decode(&got)
+			if resp.StatusCode != http.StatusOK || got.Valid != want.Valid || (got.Error == nil) != (want.Error == nil) {
+				t.Errorf("%s: expected %+v, got %d %+v", query, want, resp.StatusCode, got)
+				continue
+			}
+			if want.Error != nil && (got.Error.Position != want.Error.Position || got.Error.Length != want.Error.Length) {
+				t.Errorf("%s: expected the error at %d+%d, got %+v", query, want.Error.Position, want.Error.Length, got.Error)
+			}
+		}
+	})
+
+	t.Run("GetAllSubscribers - Filter Expression", func(t *testing.T) {
+		expr := url.QueryEscape(fmt.Sprintf("tag:%s AND NOT tag:vip AND (type:shopper OR status:unsubscribed)", tag))
+		resp, err := app.Test(request("GET", "/admin/subscribers?filter="+expr, ""), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		var subs []dto.SubscriberResponse
+		json.NewDecoder(resp.Body).Decode(&subs)
+		if resp.StatusCode != http.StatusOK || len(subs) != 2 || subs[0].ID != members[1].ID || subs[1].ID != others[1].ID {
+			t.Errorf("Expected the shopper and the unsubscribed, got %d %+v", resp.StatusCode, subs)
+		}
+
+		resp, _ = app.Test(request("GET", "/admin/subscribers?filter="+url.QueryEscape("status:active created_at>2024-13-01"), ""), -1)
+		var got map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&got)
+		if resp.StatusCode != http.StatusBadRequest || got["code"] != "invalid_filter" || got["position"] != float64(25) {
+			t.Errorf("Expected 400 invalid_filter at 25, got %d %v", resp.StatusCode, got)
+		}
+	})
+
+	t.Run("UpdateSegment And Delete", func(t *testing.T) {
+		body := fmt.Sprintf(`{"name":%q,"filter":{"tags":[%q],"subscriber_types":["shopper"]}}`, tag, tag)
+		resp, err := app.Test(request("PUT", fmt.Sprintf("/admin/segments/%d", segment.ID), body), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		var updated dto.SegmentResponse
+		json.NewDecoder(resp.Body).Decode(&updated)
+		if resp.StatusCode != http.StatusOK || updated.Description != "" || len(updated.Filter.Status) != 0 {
+			t.Errorf("Expected 200 with the new definition, got %d %+v", resp.StatusCode, updated)
+		}
+
+		resp, _ = app.Test(request("GET", "/admin/segments", ""), -1)
+		var segments []dto.SegmentResponse
+		json.NewDecoder(resp.Body).Decode(&segments)
+		if len(segments) == 0 {
+			t.Errorf("Expected the segment listed")
+		}
+
+		path := fmt.Sprintf("/admin/segments/%d", segment.ID)
+		if resp, _ = app.Test(request("DELETE", path, ""), -1); resp.StatusCode != http.StatusNoContent {
+			t.Errorf("Expected 204, got %d", resp.StatusCode)
+		}
+		if resp, _ = app.Test(request("GET", path, ""), -1); resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Expected 404 once deleted, got %d", resp.StatusCode)
+		}
+	})
+}
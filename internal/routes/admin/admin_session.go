@@ -0,0 +1,29 @@
+package admin
+
+import (
+	"fiber-gorm-api/internal/handlers"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// RegisterSessionRoutes registers the routes for the signed-in user's own sessions under /admin/sessions,
+// and the devices they trusted at sign-in under /admin/trusted-devices.
+func RegisterSessionRoutes(adminGroup fiber.Router, db *gorm.DB) {
+	sessions := adminGroup.Group("/sessions")
+
+	// List my active sessions
+	sessions.Get("/", handlers.ListSessions)
+
+	// Revoke one of my sessions
+	sessions.Delete("/:id", handlers.RevokeSession)
+
+	devices := adminGroup.Group("/trusted-devices")
+
+	// List my trusted devices
+	devices.Get("/", handlers.ListTrustedDevices(db))
+
+	// Forget one, or all, of my trusted devices
+	devices.Delete("/:id", handlers.RevokeTrustedDevice(db))
+	devices.Delete("/", handlers.RevokeTrustedDevices(db))
+}
@@ -0,0 +1,145 @@
+package admin
+
+import (
+	"encoding/json"
+	"fiber-gorm-api/internal/db"
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
+	redisclient "fiber-gorm-api/internal/redis"
+	"fiber-gorm-api/internal/session"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestAdminSessionRoutes(t *testing.T) {
+	redisclient.InitRedis("session")
+
+	database := db.Connect(true)
+	app := fiber.New()
+	app.Use(middleware.RequireJWT)
+	RegisterSessionRoutes(app, database)
+
+	email := "sessions-admin@example.com"
+	laptop, err := session.Create(redisclient.Ctx, email, models.DefaultOrgID, "10.0.0.1", "laptop-browser")
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	phone, err := session.Create(redisclient.Ctx, email, models.DefaultOrgID, "10.0.0.2", "phone-browser")
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	other, err := session.Create(redisclient.Ctx, "someone-else@example.com", models.DefaultOrgID, "10.0.0.3", "other-browser")
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+
+	token, err := middleware.GenerateJWT(laptop.ID)
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+	authed := func(method, url string) *http.Request {
+		req := httptest.NewRequest(method, url, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		return req
+	}
+
+	t.Run("ListSessions - Only My Sessions", func(t *testing.T) {
+		resp, err := app.Test(authed("GET", "/sessions"), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected 200, got %d", resp.StatusCode)
+		}
+
+		var sessions []dto.SessionResponse
+		json.NewDecoder(resp.Body).Decode(&sessions)
+		if len(sessions) != 2 {
+			t.Fatalf("Expected 2 sessions, got %d", len(sessions))
+		}
+		for _, s := range sessions {
+			if s.ID == laptop.ID && !s.Current {
+				t.Errorf("Expected the laptop session to be flagged as current")
+			}
+			if s.ID == other.ID {
+				t.Errorf("Another user's session was listed")
+			}
+		}
+	})
+
+	t.Run("RevokeSession - Other User => 404", func(t *testing.T) {
+		resp, err := app.Test(authed("DELETE", "/sessions/"+other.ID), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Expected 404, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("TrustedDevices - List And Revoke Mine", func(t *testing.T) {
+		expires := time.Now().Add(time.Hour)
+		mine := models.TrustedDevice{Email: email, TokenHash: strings.Repeat("a", 64), UserAgent: "laptop-browser", ExpiresAt: expires}
+		expired := models.TrustedDevice{Email: email, TokenHash: strings.Repeat("b", 64), ExpiresAt: time.Now().Add(-time.Hour)}
+		theirs := models.TrustedDevice{Email: "someone-else@example.com", TokenHash: strings.Repeat("c", 64), ExpiresAt: expires}
+		for _, d := range []*models.TrustedDevice{&mine, &expired, &theirs} {
+			if err := database.Create(d).Error; err != nil {
+				t.Fatalf("failed to create trusted device: %v", err)
+			}
+		}
+
+		resp, err := app.Test(authed("GET", "/trusted-devices"), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		var devices []dto.TrustedDeviceResponse
+		json.NewDecoder(resp.Body).Decode(&devices)
+		if len(devices) != 1 || devices[0].ID != mine.ID {
+			t.Fatalf("Expected only my unexpired device, got %+v", devices)
+		}
+
+		resp, _ = app.Test(authed("DELETE", fmt.Sprintf("/trusted-devices/%d", theirs.ID)), -1)
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Expected 404 revoking another user's device, got %d", resp.StatusCode)
+		}
+		resp, _ = app.Test(authed("DELETE", fmt.Sprintf("/trusted-devices/%d", mine.ID)), -1)
+		if resp.StatusCode != http.StatusNoContent {
+			t.Errorf("Expected 204, got %d", resp.StatusCode)
+		}
+		var left int64
+		database.Model(&models.TrustedDevice{}).Where("id = ?", mine.ID).Count(&left)
+		if left != 0 {
+			t.Errorf("Expected the revoked device to be gone")
+		}
+
+		resp, _ = app.Test(authed("DELETE", "/trusted-devices"), -1)
+		if resp.StatusCode != http.StatusNoContent {
+			t.Errorf("Expected 204 revoking all, got %d", resp.StatusCode)
+		}
+		database.Model(&models.TrustedDevice{}).Where("email = ?", "someone-else@example.com").Count(&left)
+		if left != 1 {
+			t.Errorf("Expected another user's devices to be kept")
+		}
+		database.Where("email = ?", "someone-else@example.com").Delete(&models.TrustedDevice{})
+	})
+
+	t.Run("RevokeSession - Success", func(t *testing.T) {
+		resp, err := app.Test(authed("DELETE", "/sessions/"+phone.ID), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusNoContent {
+			t.Errorf("Expected 204, got %d", resp.StatusCode)
+		}
+		if _, err := session.Get(redisclient.Ctx, phone.ID); err != session.ErrNotFound {
+			t.Errorf("Expected revoked session to be gone, got err=%v", err)
+		}
+	})
+}
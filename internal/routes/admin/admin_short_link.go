@@ -0,0 +1,30 @@
+package admin
+
+import (
+	"fiber-gorm-api/internal/handlers"
+	"fiber-gorm-api/internal/middleware"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// RegisterShortLinkRoutes registers the CRUD of the organization's short links under
+// /admin/short-links
+func RegisterShortLinkRoutes(adminGroup fiber.Router, db *gorm.DB) {
+	linkGroup := adminGroup.Group("/short-links", middleware.RequireMethodScope)
+
+	// Read all
+	linkGroup.Get("/", handlers.GetShortLinks(db))
+
+	// Read one
+	linkGroup.Get("/:id", handlers.GetShortLink(db))
+
+	// Create
+	linkGroup.Post("/", handlers.CreateShortLink(db))
+
+	// Update
+	linkGroup.Put("/:id", handlers.UpdateShortLink(db))
+
+	// Delete
+	linkGroup.Delete("/:id", handlers.DeleteShortLink(db))
+}
@@ -0,0 +1,158 @@
+package admin
+
+import (
+	"encoding/json"
+	"fiber-gorm-api/internal/db"
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
+	redisclient "fiber-gorm-api/internal/redis"
+	"fiber-gorm-api/internal/session"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestAdminShortLinkRoutes(t *testing.T) {
+	t.Setenv("SHORT_LINK_BASE_URL", "https://go.example.com")
+	database := db.Connect(true)
+	redisclient.InitRedis("session")
+
+	app := fiber.New()
+	adminGroup := app.Group("/admin", middleware.RequireJWTOrAPIKey(database))
+	RegisterShortLinkRoutes(adminGroup, database)
+	RegisterCampaignRoutes(adminGroup, database)
+
+	sess, err := session.Create(redisclient.Ctx, "links@example.com", models.DefaultOrgID, "", "")
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	token, err := middleware.GenerateJWT(sess.ID)
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+	request := func(method, url, body string) *http.Request {
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		return req
+	}
+	send := func(method, url, body string) (*http.Response, dto.ShortLinkResponse) {
+		t.Helper()
+		resp, err := app.Test(request(method, url, body), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		var link dto.ShortLinkResponse
+		json.NewDecoder(resp.Body).Decode(&link)
+		return resp, link
+	}
+
+	campaign := models.Campaign{OrgID: models.DefaultOrgID, Name: "Spring", Subject: "Spring", Text: "Hello", Status: models.CampaignDraft, Timezone: "UTC"}
+	database.Create(&campaign)
+	other := models.Campaign{OrgID: models.DefaultOrgID + 1, Name: "Other", Subject: "Other", Text: "Hello", Status: models.CampaignDraft, Timezone: "UTC"}
+	database.Create(&other)
+	code := fmt.Sprintf("spring-%d", time.Now().UnixNano())
+
+	t.Run("CreateShortLink - Invalid", func(t *testing.T) {
+		for body, code := range map[string]string{
+			`{"url":"javascript:alert(1)"}`:                                         "invalid_url",
+			`{"url":"/relative"}`:                                                   "invalid_url",
+			`{"code":"no spaces","url":"https://example.com"}`:                      "invalid_code",
+			`{"code":"ab","url":"https://example.com"}`:                             "invalid_code",
+			`{"code":"-dash","url":"https://example.com"}`:                          "invalid_code",
+			`{"url":"https://example.com","campaign_id":999999999}`:                 "unknown_campaign",
+			fmt.Sprintf(`{"url":"https://example.com","campaign_id":%d}`, other.ID): "unknown_campaign",
+		} {
+			resp, err := app.Test(request("POST", "/admin/short-links", body), -1)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			var got map[string]interface{}
+			json.NewDecoder(resp.Body).Decode(&got)
+			if resp.StatusCode != http.StatusBadRequest || got["code"] != code {
+				t.Errorf("%s: expected 400 %s, got %d %v", body, code, resp.StatusCode, got["code"])
+			}
+		}
+	})
+
+	var created dto.ShortLinkResponse
+	t.Run("CreateShortLink", func(t *testing.T) {
+		body := fmt.Sprintf(`{"code":" %s ","url":"https://market.example.org/spring","campaign_id":%d}`, strings.ToUpper(code), campaign.ID)
+		resp, link := send("POST", "/admin/short-links", body)
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("Expected 201, got %d", resp.StatusCode)
+		}
+		if resp.Header.Get("Location") != fmt.Sprintf("/admin/short-links/%d", link.ID) {
+			t.Errorf("Expected the Location of the link, got %q", resp.Header.Get("Location"))
+		}
+		if link.Code != code || link.ShortURL != "https://go.example.com/l/"+code || link.CampaignID == nil || *link.CampaignID != campaign.ID {
+			t.Errorf("Expected the link of the campaign, got %+v", link)
+		}
+		created = link
+
+		if resp, _ := send("POST", "/admin/short-links", fmt.Sprintf(`{"code":"%s","url":"https://example.com"}`, code)); resp.StatusCode != http.StatusConflict {
+			t.Errorf("Expected 409 for a code taken, got %d", resp.StatusCode)
+		}
+
+		// without a code, one is generated
+		resp, link = send("POST", "/admin/short-links", `{"url":"https://example.com/anywhere"}`)
+		if resp.StatusCode != http.StatusCreated || len(link.Code) != 7 || link.CampaignID != nil {
+			t.Errorf("Expected a generated code, got %d %+v", resp.StatusCode, link)
+		}
+	})
+
+	t.Run("GetShortLinks - By Campaign", func(t *testing.T) {
+		resp, err := app.Test(request("GET", fmt.Sprintf("/admin/short-links?campaign_id=%d", campaign.ID), ""), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		var links []dto.ShortLinkResponse
+		json.NewDecoder(resp.Body).Decode(&links)
+		if resp.StatusCode != http.StatusOK || len(links) != 1 || links[0].ID != created.ID {
+			t.Errorf("Expected the link of the campaign, got %d %+v", resp.StatusCode, links)
+		}
+		if resp, _ := send("GET", "/admin/short-links?campaign_id=x", ""); resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected 400 for an invalid campaign_id, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("GetCampaignStats - Short Links", func(t *testing.T) {
+		database.Model(&models.ShortLink{}).Where("id = ?", created.ID).Update("clicks", 3)
+		resp, err := app.Test(request("GET", fmt.Sprintf("/admin/campaigns/%d/stats", campaign.ID), ""), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		var stats dto.CampaignStatsResponse
+		json.NewDecoder(resp.Body).Decode(&stats)
+		if resp.StatusCode != http.StatusOK || len(stats.ShortLinks) != 1 || stats.ShortLinks[0].Code != code || stats.ShortLinks[0].Clicks != 3 {
+			t.Errorf("Expected the clicks of the short link, got %d %+v", resp.StatusCode, stats.ShortLinks)
+		}
+	})
+
+	t.Run("UpdateShortLink", func(t *testing.T) {
+		url := fmt.Sprintf("/admin/short-links/%d", created.ID)
+		resp, link := send("PUT", url, `{"url":"https://market.example.org/summer"}`)
+		if resp.StatusCode != http.StatusOK || link.Code != code || link.URL != "https://market.example.org/summer" || link.CampaignID != nil || link.Clicks != 3 {
+			t.Errorf("Expected the URL changed, the code and clicks kept, got %d %+v", resp.StatusCode, link)
+		}
+		if resp, _ := send("PUT", url, `{"url":"ftp://example.com"}`); resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected 400 for an invalid URL, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("DeleteShortLink", func(t *testing.T) {
+		url := fmt.Sprintf("/admin/short-links/%d", created.ID)
+		if resp, _ := send("DELETE", url, ""); resp.StatusCode != http.StatusNoContent {
+			t.Errorf("Expected 204, got %d", resp.StatusCode)
+		}
+		if resp, _ := send("GET", url, ""); resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Expected 404 once deleted, got %d", resp.StatusCode)
+		}
+	})
+}
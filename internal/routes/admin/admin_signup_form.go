@@ -0,0 +1,30 @@
+package admin
+
+import (
+	"fiber-gorm-api/internal/handlers"
+	"fiber-gorm-api/internal/middleware"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// RegisterSignupFormRoutes registers the CRUD of the organization's public signup forms under
+// /admin/signup-forms
+func RegisterSignupFormRoutes(adminGroup fiber.Router, db *gorm.DB) {
+	formGroup := adminGroup.Group("/signup-forms", middleware.RequireMethodScope)
+
+	// Read all
+	formGroup.Get("/", handlers.GetSignupForms(db))
+
+	// Read one
+	formGroup.Get("/:id", handlers.GetSignupForm(db))
+
+	// Create
+	formGroup.Post("/", handlers.CreateSignupForm(db))
+
+	// Update
+	formGroup.Put("/:id", handlers.UpdateSignupForm(db))
+
+	// Delete
+	formGroup.Delete("/:id", handlers.DeleteSignupForm(db))
+}
@@ -0,0 +1,166 @@
+package admin
+
+import (
+	"encoding/json"
+	"fiber-gorm-api/internal/db"
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
+	redisclient "fiber-gorm-api/internal/redis"
+	"fiber-gorm-api/internal/session"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestAdminSignupFormRoutes(t *testing.T) {
+	database := db.Connect(true)
+	redisclient.InitRedis("session")
+
+	app := fiber.New()
+	adminGroup := app.Group("/admin", middleware.RequireJWTOrAPIKey(database))
+	RegisterSignupFormRoutes(adminGroup, database)
+
+	sess, err := session.Create(redisclient.Ctx, "forms@example.com", models.DefaultOrgID, "", "")
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	token, err := middleware.GenerateJWT(sess.ID)
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+	request := func(method, url, body string) *http.Request {
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		return req
+	}
+	slug := fmt.Sprintf("market-%d", time.Now().UnixNano())
+
+	t.Run("CreateSignupForm - Invalid", func(t *testing.T) {
+		for body, code := range map[string]string{
+			`{"slug":"Not a slug","name":"Market"}`:                                                                "invalid_slug",
+			`{"slug":"market","name":"Market","fields":[{"name":"email","type":"text"}]}`:                          "invalid_field",
+			`{"slug":"market","name":"Market","fields":[{"name":"size","type":"select"}]}`:                         "invalid_field",
+			`{"slug":"market","name":"Market","fields":[{"name":"a","type":"text"},{"name":"a","type":"number"}]}`: "invalid_field",
+			`{"slug":"market","name":"Market","success_redirect":"javascript:alert(1)"}`:                           "invalid_redirect",
+		} {
+			resp, err := app.Test(request("POST", "/admin/signup-forms", body), -1)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			var got map[string]interface{}
+			json.NewDecoder(resp.Body).Decode(&got)
+			if resp.StatusCode != http.StatusBadRequest || got["code"] != code {
+				t.Errorf("%s: expected 400 %s, got %d %v", body, code, resp.StatusCode, got["code"])
+			}
+		}
+
+		resp, err := app.Test(request("POST", "/admin/signup-forms", `{"slug":"market","name":"Market","subscriber_types":["shoper"]}`), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusUnprocessableEntity {
+			t.Errorf("Expected 422 for an unknown subscriber_type, got %d", resp.StatusCode)
+		}
+	})
+
+	var form dto.SignupFormResponse
+	t.Run("CreateSignupForm - Success", func(t *testing.T) {
+		body := fmt.Sprintf(`{
+			"slug": %q,
+			"name": "Farmers market",
+			"subscriber_types": ["shopper", "driver"],
+			"fields": [
+				{"name": "postcode", "label": "Postcode", "type": "text", "required": true, "options": ["dropped"]},
+				{"name": "stall", "type": "select", "options": ["north", "south"]}
+			],
+			"success_redirect": "https://market.example.org/thanks"
+		}`, slug)
+		resp, err := app.Test(request("POST", "/admin/signup-forms", body), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("Expected 201, got %d", resp.StatusCode)
+		}
+		json.NewDecoder(resp.Body).Decode(&form)
+		if form.ID == 0 || !form.Captcha || len(form.SubscriberTypes) != 2 || form.CreatedBy != "forms@example.com" {
+			t.Errorf("Unexpected form %+v", form)
+		}
+		if len(form.Fields) != 2 || form.Fields[0].Options != nil || len(form.Fields[1].Options) != 2 {
+			t.Errorf("Expected options kept only on the select, got %+v", form.Fields)
+		}
+	})
+
+	t.Run("CreateSignupForm - Slug taken", func(t *testing.T) {
+		resp, err := app.Test(request("POST", "/admin/signup-forms", fmt.Sprintf(`{"slug":%q,"name":"Again"}`, slug)), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusConflict {
+			t.Errorf("Expected 409, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("GetSignupForms", func(t *testing.T) {
+		resp, err := app.Test(request("GET", "/admin/signup-forms", ""), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		var forms []dto.SignupFormResponse
+		json.NewDecoder(resp.Body).Decode(&forms)
+		found := false
+		for _, f := range forms {
+			found = found || f.ID == form.ID
+		}
+		if !found {
+			t.Errorf("Expected form %d listed, got %+v", form.ID, forms)
+		}
+	})
+
+	t.Run("UpdateSignupForm", func(t *testing.T) {
+		url := fmt.Sprintf("/admin/signup-forms/%d", form.ID)
+		resp, err := app.Test(request("PUT", url, fmt.Sprintf(`{"slug":%q,"name":"Renamed","captcha":false}`, slug)), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected 200, got %d", resp.StatusCode)
+		}
+
+		resp, err = app.Test(request("GET", url, ""), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		var got dto.SignupFormResponse
+		json.NewDecoder(resp.Body).Decode(&got)
+		if got.Name != "Renamed" || got.Captcha || len(got.Fields) != 0 || got.CreatedBy != "forms@example.com" {
+			t.Errorf("Expected the form replaced, got %+v", got)
+		}
+	})
+
+	t.Run("DeleteSignupForm", func(t *testing.T) {
+		url := fmt.Sprintf("/admin/signup-forms/%d", form.ID)
+		resp, err := app.Test(request("DELETE", url, ""), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusNoContent {
+			t.Errorf("Expected 204, got %d", resp.StatusCode)
+		}
+
+		resp, err = app.Test(request("GET", url, ""), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Expected 404 once deleted, got %d", resp.StatusCode)
+		}
+	})
+}
@@ -0,0 +1,22 @@
+package admin
+
+import (
+	"fiber-gorm-api/internal/handlers"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// RegisterStatsRoutes registers the admin dashboard stats under /admin/stats.
+func RegisterStatsRoutes(adminGroup fiber.Router, db *gorm.DB) {
+	// Aggregated subscriber stats for the dashboard
+	adminGroup.Get("/stats", handlers.GetStats(db))
+	// Active sessions by device, OS, browser and country
+	adminGroup.Get("/stats/sessions", handlers.GetSessionStats)
+	// Signups and admin activity by country and region, located with GeoIP
+	adminGroup.Get("/stats/geo", handlers.GetGeoStats(db))
+	// Signups by utm_source, utm_medium, utm_campaign and referrer
+	adminGroup.Get("/stats/sources", handlers.GetSourceStats(db))
+	// Signups referred by other subscribers, and who referred the most
+	adminGroup.Get("/stats/referrals", handlers.GetReferralStats(db))
+}
@@ -0,0 +1,246 @@
+package admin
+
+import (
+	"encoding/json"
+	"fiber-gorm-api/internal/db"
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
+	redisclient "fiber-gorm-api/internal/redis"
+	"fiber-gorm-api/internal/session"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestAdminStatsRoutes(t *testing.T) {
+	database := db.Connect(true)
+	redisclient.InitRedis("session")
+
+	app := fiber.New()
+	app.Use(middleware.RequireJWT)
+	RegisterStatsRoutes(app, database)
+
+	sess, err := session.Create(redisclient.Ctx, "stats-admin@example.com", models.DefaultOrgID, "10.0.0.1", "stats-browser")
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	token, err := middleware.GenerateJWT(sess.ID)
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+
+	s := models.Subscriber{
+		Email:           "stats-subscriber@example.com",
+		Name:            "Stats Subscriber",
+		SubscriberTypes: []models.SubscriberType{{Name: "shopper"}},
+	}
+	database.Create(&s)
+
+	t.Run("GetStats - Success", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/stats", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected 200, got %d", resp.StatusCode)
+		}
+
+		var stats dto.StatsResponse
+		json.NewDecoder(resp.Body).Decode(&stats)
+		if stats.TotalSubscribers < 1 {
+			t.Errorf("Expected at least 1 subscriber, got %d", stats.TotalSubscribers)
+		}
+		if stats.ActiveSubscribers+stats.UnsubscribedSubscribers != stats.TotalSubscribers {
+			t.Errorf("Active + unsubscribed should equal total, got %+v", stats)
+		}
+
+		foundShopper := false
+		for _, tc := range stats.ByType {
+			if tc.Name == "shopper" && tc.Count > 0 {
+				foundShopper = true
+			}
+		}
+		if !foundShopper {
+			t.Errorf("Expected a shopper count, got %+v", stats.ByType)
+		}
+		if len(stats.Growth.Daily) == 0 {
+			t.Errorf("Expected today's signup in daily growth")
+		}
+		if len(stats.RecentSignups) == 0 || stats.RecentSignups[0].ID != s.ID {
+			t.Errorf("Expected subscriber %d as most recent signup, got %+v", s.ID, stats.RecentSignups)
+		}
+	})
+
+	t.Run("GetSessionStats - By client", func(t *testing.T) {
+		t.Setenv("GEOIP_DB_PATH", "")
+		iphone := "Mozilla/5.0 (iPhone; CPU iPhone OS 17_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.1 Mobile/15E148 Safari/604.1"
+		if _, err := session.Create(redisclient.Ctx, "stats-phone@example.com", models.DefaultOrgID, "10.0.0.2", iphone); err != nil {
+			t.Fatalf("failed to create session: %v", err)
+		}
+		if _, err := session.Create(redisclient.Ctx, "stats-other-org@example.com", models.DefaultOrgID+1000, "10.0.0.3", iphone); err != nil {
+			t.Fatalf("failed to create session: %v", err)
+		}
+
+		req := httptest.NewRequest("GET", "/stats/sessions", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected 200, got %d", resp.StatusCode)
+		}
+
+		var stats dto.SessionStatsResponse
+		json.NewDecoder(resp.Body).Decode(&stats)
+		count := func(counts []dto.SessionCount, name string) int64 {
+			for _, c := range counts {
+				if c.Name == name {
+					return c.Count
+				}
+			}
+			return 0
+		}
+		if stats.ActiveSessions < 2 {
+			t.Errorf("Expected at least the 2 sessions of the organization, got %d", stats.ActiveSessions)
+		}
+		if count(stats.ByDevice, "mobile") < 1 || count(stats.ByOS, "iOS") < 1 || count(stats.ByBrowser, "Safari") < 1 {
+			t.Errorf("Expected the iPhone session counted, got %+v", stats)
+		}
+		if stats.GeoIPEnabled || count(stats.ByCountry, "unknown") != stats.ActiveSessions {
+			t.Errorf("Expected every country unknown without GeoIP, got %+v", stats.ByCountry)
+		}
+	})
+
+	t.Run("GetGeoStats - Located records", func(t *testing.T) {
+		database.Create(&models.Subscriber{Email: "stats-kiwi@example.com", Name: "Kiwi", Country: "NZ", Region: "AUK"})
+		database.Create(&models.AdminActivity{OrgID: models.DefaultOrgID, Kind: "bulk_delete", Summary: "geo stats", Country: "NZ"})
+
+		req := httptest.NewRequest("GET", "/stats/geo", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected 200, got %d", resp.StatusCode)
+		}
+
+		var stats dto.GeoStatsResponse
+		json.NewDecoder(resp.Body).Decode(&stats)
+		located := func(counts []dto.GeoCount, country, region string) bool {
+			for _, c := range counts {
+				if c.Country == country && c.Region == region && c.Count > 0 {
+					return true
+				}
+			}
+			return false
+		}
+		if !located(stats.SignupsByCountry, "NZ", "") || !located(stats.SignupsByRegion, "NZ", "AUK") {
+			t.Errorf("Expected the NZ signup counted, got %+v", stats)
+		}
+		if !located(stats.ActivityByCountry, "NZ", "") {
+			t.Errorf("Expected the NZ activity counted, got %+v", stats.ActivityByCountry)
+		}
+		for _, c := range stats.SignupsByCountry {
+			if c.Country == "" {
+				t.Errorf("Expected signups without a country left out, got %+v", stats.SignupsByCountry)
+			}
+		}
+	})
+
+	t.Run("GetSourceStats - By channel", func(t *testing.T) {
+		database.Create(&models.Subscriber{Email: "stats-utm@example.com", Name: "UTM", UTMSource: "stats-newsletter", UTMMedium: "email", UTMCampaign: "stats-spring"})
+		database.Create(&models.Subscriber{Email: "stats-referred@example.com", Name: "Referred", Referrer: "stats-forum.example.org"})
+
+		req := httptest.NewRequest("GET", "/stats/sources", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected 200, got %d", resp.StatusCode)
+		}
+
+		var stats dto.SourceStatsResponse
+		json.NewDecoder(resp.Body).Decode(&stats)
+		count := func(counts []dto.SourceCount, name string) int64 {
+			for _, c := range counts {
+				if c.Name == name {
+					return c.Count
+				}
+			}
+			return 0
+		}
+		// without utm_source, the referrer is the source
+		if count(stats.BySource, "stats-newsletter") < 1 || count(stats.BySource, "stats-forum.example.org") < 1 {
+			t.Errorf("Expected both channels counted as sources, got %+v", stats.BySource)
+		}
+		if count(stats.BySource, "none") < 1 || count(stats.ByMedium, "email") < 1 {
+			t.Errorf("Expected subscribers without attribution under none and the email medium, got %+v", stats)
+		}
+		if count(stats.ByReferrer, "stats-forum.example.org") < 1 {
+			t.Errorf("Expected the referrer counted, got %+v", stats.ByReferrer)
+		}
+		found := false
+		for _, c := range stats.ByCampaign {
+			found = found || (c.Source == "stats-newsletter" && c.Campaign == "stats-spring" && c.Count > 0)
+		}
+		if !found {
+			t.Errorf("Expected the campaign counted under its source, got %+v", stats.ByCampaign)
+		}
+
+		req = httptest.NewRequest("GET", "/stats/sources?since=yesterday", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		if resp, _ := app.Test(req, -1); resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected 400 for an invalid since, got %d", resp.StatusCode)
+		}
+	})
+	t.Run("GetReferralStats - Leaderboard", func(t *testing.T) {
+		code := fmt.Sprintf("st%d", time.Now().UnixNano()%1e8)
+		referrer := models.Subscriber{Email: "stats-referrer@example.com", Name: "Referrer", ReferralCode: &code}
+		database.Create(&referrer)
+		for i := 0; i < 3; i++ {
+			database.Create(&models.Subscriber{Email: fmt.Sprintf("stats-referee-%d@example.com", i), Name: "Referee", ReferredByID: &referrer.ID})
+		}
+
+		req := httptest.NewRequest("GET", "/stats/referrals?limit=100", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected 200, got %d", resp.StatusCode)
+		}
+
+		var stats dto.ReferralStatsResponse
+		json.NewDecoder(resp.Body).Decode(&stats)
+		if stats.TotalReferred < 3 || stats.ActiveReferred < 3 || stats.Referrers < 1 {
+			t.Errorf("Expected the 3 referees counted, got %+v", stats)
+		}
+		found := false
+		for _, entry := range stats.Leaderboard {
+			found = found || (entry.SubscriberID == referrer.ID && entry.Referrals == 3 && entry.ReferralCode == code)
+		}
+		if !found {
+			t.Errorf("Expected subscriber %d on the leaderboard with 3 referrals, got %+v", referrer.ID, stats.Leaderboard)
+		}
+
+		for _, query := range []string{"limit=0", "since=yesterday"} {
+			req = httptest.NewRequest("GET", "/stats/referrals?"+query, nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			if resp, _ := app.Test(req, -1); resp.StatusCode != http.StatusBadRequest {
+				t.Errorf("Expected 400 for %s, got %d", query, resp.StatusCode)
+			}
+		}
+	})
+}
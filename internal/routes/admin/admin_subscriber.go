@@ -2,6 +2,8 @@ package admin
 
 import (
 	"fiber-gorm-api/internal/handlers"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
 
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
@@ -10,7 +12,7 @@ import (
 // RegisterSubscriberRoutes registers the CRUD routes for subscribers under /admin/subscribers.
 // NOTE: We don't separately register subscriber_types here as they are embedded in the subscriber routes.
 func RegisterSubscriberRoutes(adminGroup fiber.Router, db *gorm.DB) {
-	subs := adminGroup.Group("/subscribers")
+	subs := adminGroup.Group("/subscribers", middleware.RequireMethodScope, middleware.RecordAuthor)
 
 	// Create
 	subs.Post("/", handlers.CreateSubscriber(db))
@@ -18,6 +20,12 @@ func RegisterSubscriberRoutes(adminGroup fiber.Router, db *gorm.DB) {
 	// Read all
 	subs.Get("/", handlers.GetAllSubscribers(db))
 
+	// Search (registered before /:id so "search" isn't parsed as an id)
+	subs.Get("/search", handlers.SearchSubscribers(db))
+
+	// Bulk create/update/delete in one transaction
+	subs.Post("/batch", handlers.BatchSubscribers(db))
+
 	// Read single
 	subs.Get("/:id", handlers.GetSubscriber(db))
 
@@ -26,4 +34,36 @@ func RegisterSubscriberRoutes(adminGroup fiber.Router, db *gorm.DB) {
 
 	// Delete
 	subs.Delete("/:id", handlers.DeleteSubscriber(db))
+	subs.Post("/:id/undelete", handlers.UndeleteSubscriber(db))
+
+	// GDPR: export everything we hold (raw PII, so pii scope only) and right to be forgotten
+	subs.Get("/:id/gdpr-export", middleware.RequireScope(models.ScopePII), handlers.ExportSubscriberData(db))
+	subs.Post("/:id/anonymize", handlers.AnonymizeSubscriber(db))
+
+	// Fold a duplicate record into this one
+	subs.Post("/:id/merge", handlers.MergeSubscribers(db))
+
+	// Send a pending subscriber a new double opt-in link
+	subs.Post("/:id/resend-confirmation", handlers.ResendSubscriberConfirmation(db))
+
+	// Signed link a subscriber manages their own frequency and channel preferences with
+	subs.Post("/:id/preferences-link", handlers.CreatePreferencesLink(db))
+
+	// Who changed what: before/after snapshots of every write, and rolling back to one
+	subs.Get("/:id/history", handlers.GetSubscriberHistory(db))
+	subs.Post("/:id/history/:revision/restore", handlers.RestoreSubscriberRevision(db))
+
+	// Notes support staff keep about a subscriber
+	subs.Get("/:id/notes", handlers.GetSubscriberNotes(db))
+	subs.Post("/:id/notes", handlers.CreateSubscriberNote(db))
+	subs.Delete("/:id/notes/:noteId", handlers.DeleteSubscriberNote(db))
+
+	// The subscriber's referral code, who referred them and the signups they referred
+	subs.Get("/:id/referrals", handlers.GetSubscriberReferrals(db))
+
+	// Email delivery history (bounces, spam reports, unsubscribes) from the SendGrid webhook
+	subs.Get("/:id/delivery-events", handlers.GetSubscriberDeliveryEvents(db))
+
+	// Emails sent to the subscriber's address, from the email log
+	subs.Get("/:id/emails", handlers.GetSubscriberEmails(db))
 }
@@ -0,0 +1,50 @@
+package admin_test
+
+import (
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"fiber-gorm-api/internal/models"
+	"fiber-gorm-api/internal/testutil"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+)
+
+// BenchmarkCreateSubscriber creates subscribers with POST /admin/subscribers through the whole
+// API on the testutil harness: middleware, the JWT and its session in Redis, validation, and the
+// insert with its revision and outbox event. Requests go straight to the app's handler, without
+// a network connection:
+//
+//	go test ./internal/routes/admin -run '^$' -bench CreateSubscriber -benchmem
+func BenchmarkCreateSubscriber(b *testing.B) {
+	h, _ := testutil.New(b)
+	authorization := "Bearer " + h.Token(b, "bench@example.com", models.DefaultOrgID)
+	handler := h.App.Handler()
+
+	var seq atomic.Int64
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		var ctx fasthttp.RequestCtx
+		for pb.Next() {
+			ctx.Request.Reset()
+			ctx.Response.Reset()
+			// fiber keeps the request's context, cancelled by RequestTimeout once it's served, in
+			// the user values
+			ctx.ResetUserValues()
+			ctx.Request.Header.SetMethod(fasthttp.MethodPost)
+			ctx.Request.SetRequestURI("/admin/subscribers")
+			ctx.Request.Header.SetContentType(fiber.MIMEApplicationJSON)
+			ctx.Request.Header.Set("Authorization", authorization)
+			ctx.Request.SetBodyString(fmt.Sprintf(`{"email":"bench-%d@example.com","name":"Bench"}`, seq.Add(1)))
+			handler(&ctx)
+			if status := ctx.Response.StatusCode(); status != http.StatusCreated {
+				b.Errorf("Expected 201, got %d: %s", status, ctx.Response.Body())
+				return
+			}
+		}
+	})
+}
@@ -2,18 +2,23 @@ package admin
 
 import (
 	"encoding/json"
+	"encoding/xml"
 	"fiber-gorm-api/internal/db"
+	"fiber-gorm-api/internal/dto"
 	"fiber-gorm-api/internal/middleware"
 	"fiber-gorm-api/internal/models"
 	redisclient "fiber-gorm-api/internal/redis"
+	sendgridservice "fiber-gorm-api/internal/services"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/vmihailenco/msgpack/v5"
 )
 
 // Example Admin test that requires JWT.
@@ -62,7 +67,7 @@ func TestAdminSubscriberRoutes(t *testing.T) {
 			userData := `{"email":"admin@example.com"}`
 			redisKey := "session:" + sessionID
 
-			if err := redisclient.SetValue(redisKey, userData, 0); err != nil {
+			if err := redisclient.SetValue(redisclient.Ctx, redisKey, userData, 0); err != nil {
 				return nil, fmt.Errorf("failed to store session in redis: %w", err)
 			}
 			token, err := middleware.GenerateJWT(sessionID)
@@ -75,6 +80,23 @@ func TestAdminSubscriberRoutes(t *testing.T) {
 		return req, nil
 	}
 
+	// currentETag reads a subscriber and returns the ETag needed for If-Match on updates
+	currentETag := func(t *testing.T, id uint) string {
+		req, err := getRequestWithToken("GET", fmt.Sprintf("/subscribers/%d", id), nil, true)
+		if err != nil {
+			t.Fatalf("Failed to create request: %v", err)
+		}
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		etag := resp.Header.Get("ETag")
+		if etag == "" {
+			t.Fatalf("Expected an ETag for subscriber %d", id)
+		}
+		return etag
+	}
+
 	////////////////////////////////////////////////////////////////
 	// FIRST, TEST NO TOKEN => 401
 	////////////////////////////////////////////////////////////////
@@ -219,6 +241,435 @@ func TestAdminSubscriberRoutes(t *testing.T) {
 		}
 	})
 
+	t.Run("GetSubscriber - XML And MessagePack", func(t *testing.T) {
+		s := models.Subscriber{
+			Email:           "test-formats@example.com",
+			Name:            "Format Tester",
+			Metadata:        models.JSONMap{"favourite shop": "bakery"},
+			SubscriberTypes: []models.SubscriberType{{Name: "shopper"}},
+		}
+		database.Create(&s)
+		path := fmt.Sprintf("/subscribers/%d", s.ID)
+
+		get := func(accept string) (*http.Response, []byte) {
+			req, err := getRequestWithToken("GET", path, nil, true)
+			if err != nil {
+				t.Fatalf("Failed to create request: %v", err)
+			}
+			req.Header.Set("Accept", accept)
+			resp, err := app.Test(req, -1)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("Expected 200 for %s, got %d: %s", accept, resp.StatusCode, body)
+			}
+			return resp, body
+		}
+
+		resp, body := get("application/xml")
+		if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "application/xml") {
+			t.Errorf("Expected an XML content type, got %q", ct)
+		}
+		var asXML struct {
+			XMLName         xml.Name `xml:"subscriber"`
+			ID              uint     `xml:"id"`
+			Name            string   `xml:"name"`
+			SubscriberTypes []string `xml:"subscriber_types>item>name"`
+			Shop            string   `xml:"metadata>favourite_shop"`
+		}
+		if err := xml.Unmarshal(body, &asXML); err != nil {
+			t.Fatalf("Invalid XML %s: %v", body, err)
+		}
+		if asXML.ID != s.ID || asXML.Name != "Format Tester" || asXML.Shop != "bakery" ||
+			len(asXML.SubscriberTypes) != 1 || asXML.SubscriberTypes[0] != "shopper" {
+			t.Errorf("Unexpected XML subscriber: %s", body)
+		}
+
+		resp, body = get("application/msgpack")
+		if ct := resp.Header.Get("Content-Type"); ct != "application/msgpack" {
+			t.Errorf("Expected a MessagePack content type, got %q", ct)
+		}
+		var asMsgPack struct {
+			ID       uint                   `msgpack:"id"`
+			Name     string                 `msgpack:"name"`
+			Metadata map[string]interface{} `msgpack:"metadata"`
+		}
+		if err := msgpack.Unmarshal(body, &asMsgPack); err != nil {
+			t.Fatalf("Invalid MessagePack: %v", err)
+		}
+		if asMsgPack.ID != s.ID || asMsgPack.Name != "Format Tester" || asMsgPack.Metadata["favourite shop"] != "bakery" {
+			t.Errorf("Unexpected MessagePack subscriber: %+v", asMsgPack)
+		}
+
+		// JSON stays the default, also for media types the API can't produce
+		if resp, _ := get("text/csv, */*;q=0.1"); !strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+			t.Errorf("Expected JSON by default, got %q", resp.Header.Get("Content-Type"))
+		}
+	})
+
+	t.Run("GetSubscriber - Sparse Fields", func(t *testing.T) {
+		s := models.Subscriber{
+			Email:           "test-sparse@example.com",
+			Name:            "Sparse Tester",
+			SubscriberTypes: []models.SubscriberType{{Name: "shopper"}},
+		}
+		database.Create(&s)
+
+		get := func(path string) (int, []map[string]interface{}) {
+			req, err := getRequestWithToken("GET", path, nil, true)
+			if err != nil {
+				t.Fatalf("Failed to create request: %v", err)
+			}
+			resp, err := app.Test(req, -1)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			var list []map[string]interface{}
+			if json.Unmarshal(body, &list) != nil {
+				var one map[string]interface{}
+				json.Unmarshal(body, &one)
+				list = append(list, one)
+			}
+			return resp.StatusCode, list
+		}
+
+		status, got := get(fmt.Sprintf("/subscribers/%d?fields=id,email", s.ID))
+		if status != http.StatusOK || len(got) != 1 {
+			t.Fatalf("Expected 200 with one subscriber, got %d %v", status, got)
+		}
+		if len(got[0]) != 2 || got[0]["email"] != "test-sparse@example.com" || got[0]["id"] != float64(s.ID) {
+			t.Errorf("Expected only id and email, got %v", got[0])
+		}
+
+		status, got = get("/subscribers?fields=email,subscriber_types&subscriber_type=shopper")
+		if status != http.StatusOK || len(got) == 0 {
+			t.Fatalf("Expected 200 with subscribers, got %d %v", status, got)
+		}
+		for _, sub := range got {
+			types, _ := sub["subscriber_types"].([]interface{})
+			if len(sub) != 2 || len(types) == 0 {
+				t.Errorf("Expected email and the preloaded subscriber_types only, got %v", sub)
+			}
+		}
+
+		if status, _ := get("/subscribers?fields=id,password"); status != http.StatusBadRequest {
+			t.Errorf("Expected 400 for an unknown field, got %d", status)
+		}
+	})
+
+	t.Run("GetSubscriber - Include Relations", func(t *testing.T) {
+		s := models.Subscriber{
+			Email:           "test-include@example.com",
+			Name:            "Include Tester",
+			SubscriberTypes: []models.SubscriberType{{Name: "donor"}},
+		}
+		database.Create(&s)
+		database.Create(&models.SubscriberNote{SubscriberID: s.ID, Author: "admin@example.com", Text: "Prefers SMS"})
+
+		get := func(path string) (int, map[string]interface{}) {
+			req, err := getRequestWithToken("GET", path, nil, true)
+			if err != nil {
+				t.Fatalf("Failed to create request: %v", err)
+			}
+			resp, err := app.Test(req, -1)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			var list []map[string]interface{}
+			if json.Unmarshal(body, &list) == nil {
+				for _, sub := range list {
+					if sub["id"] == float64(s.ID) {
+						return resp.StatusCode, sub
+					}
+				}
+				return resp.StatusCode, nil
+			}
+			var one map[string]interface{}
+			json.Unmarshal(body, &one)
+			return resp.StatusCode, one
+		}
+
+		count := func(v interface{}) int {
+			list, _ := v.([]interface{})
+			return len(list)
+		}
+
+		// lists skip relations unless asked for
+		_, sub := get("/subscribers?subscriber_type=donor")
+		if _, ok := sub["subscriber_types"]; sub == nil || ok || sub["email"] != s.Email {
+			t.Errorf("Expected the subscriber without subscriber_types, got %v", sub)
+		}
+		_, sub = get("/subscribers?subscriber_type=donor&include=subscriber_types,notes")
+		notes, _ := sub["notes"].([]interface{})
+		if count(sub["subscriber_types"]) != 1 || len(notes) != 1 || notes[0].(map[string]interface{})["text"] != "Prefers SMS" {
+			t.Errorf("Expected subscriber_types and notes embedded, got %v", sub)
+		}
+
+		// a single subscriber embeds its subscriber_types unless told otherwise
+		path := fmt.Sprintf("/subscribers/%d", s.ID)
+		if _, sub = get(path); count(sub["subscriber_types"]) != 1 || sub["notes"] != nil {
+			t.Errorf("Expected subscriber_types only, got %v", sub)
+		}
+		if _, sub = get(path + "?include="); sub["subscriber_types"] != nil || sub["name"] != "Include Tester" {
+			t.Errorf("Expected no relations, got %v", sub)
+		}
+		if _, sub = get(path + "?include=notes"); count(sub["notes"]) != 1 || sub["subscriber_types"] != nil {
+			t.Errorf("Expected notes only, got %v", sub)
+		}
+
+		if status, sub := get(path + "?include=campaigns"); status != http.StatusBadRequest || sub["code"] != "invalid_include" {
+			t.Errorf("Expected 400 invalid_include, got %d %v", status, sub)
+		}
+	})
+
+	t.Run("SearchSubscribers - Missing Term", func(t *testing.T) {
+		req, err := getRequestWithToken("GET", "/subscribers/search", nil, true)
+		if err != nil {
+			t.Fatalf("Failed to create request: %v", err)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected 400 for missing search term, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("SearchSubscribers - Partial Match With Type", func(t *testing.T) {
+		s := models.Subscriber{
+			Email:           "searchable-person@example.com",
+			Name:            "Searchable Person",
+			SubscriberTypes: []models.SubscriberType{{Name: "business"}},
+		}
+		database.Create(&s)
+
+		req, err := getRequestWithToken("GET", "/subscribers/search?q=searchable&subscriber_type=business", nil, true)
+		if err != nil {
+			t.Fatalf("Failed to create request: %v", err)
+		}
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected 200, got %d", resp.StatusCode)
+		}
+
+		var results []models.Subscriber
+		json.NewDecoder(resp.Body).Decode(&results)
+		if len(results) == 0 || results[0].ID != s.ID {
+			t.Errorf("Expected subscriber %d as the top search result, got %+v", s.ID, results)
+		}
+	})
+
+	t.Run("GetAllSubscribers - Invalid Cursor", func(t *testing.T) {
+		req, err := getRequestWithToken("GET", "/subscribers?cursor=not-a-cursor", nil, true)
+		if err != nil {
+			t.Fatalf("Failed to create request: %v", err)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected 400 for invalid cursor, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("GetAllSubscribers - Invalid Status Filter", func(t *testing.T) {
+		req, err := getRequestWithToken("GET", "/subscribers?status=active,lost", nil, true)
+		if err != nil {
+			t.Fatalf("Failed to create request: %v", err)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected 400 for invalid status, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("GetAllSubscribers - Deliverable Filter", func(t *testing.T) {
+		req, err := getRequestWithToken("GET", "/subscribers?status=active&verified=false", nil, true)
+		if err != nil {
+			t.Fatalf("Failed to create request: %v", err)
+		}
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected 200, got %d", resp.StatusCode)
+		}
+		var subs []dto.SubscriberResponse
+		json.NewDecoder(resp.Body).Decode(&subs)
+		for _, s := range subs {
+			if s.Status != models.SubscriberStatusActive || s.EmailVerifiedAt != nil {
+				t.Errorf("Expected only active unverified subscribers, got %q verified=%v", s.Status, s.EmailVerifiedAt)
+			}
+		}
+	})
+
+	t.Run("GetAllSubscribers - Cursor Pagination", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			database.Create(&models.Subscriber{
+				Email: fmt.Sprintf("page-%d@example.com", i),
+				Name:  "Paged Person",
+			})
+		}
+
+		seen := map[uint]bool{}
+		url := "/subscribers?limit=2"
+		for pages := 0; url != ""; pages++ {
+			if pages > 1000 {
+				t.Fatalf("Cursor pagination did not terminate")
+			}
+			req, err := getRequestWithToken("GET", url, nil, true)
+			if err != nil {
+				t.Fatalf("Failed to create request: %v", err)
+			}
+			resp, err := app.Test(req, -1)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("Expected 200, got %d", resp.StatusCode)
+			}
+
+			var results []models.Subscriber
+			json.NewDecoder(resp.Body).Decode(&results)
+			if len(results) > 2 {
+				t.Fatalf("Expected at most 2 results per page, got %d", len(results))
+			}
+			for _, r := range results {
+				if seen[r.ID] {
+					t.Errorf("Subscriber %d returned twice", r.ID)
+				}
+				seen[r.ID] = true
+			}
+
+			url = ""
+			if next := resp.Header.Get("X-Next-Cursor"); next != "" {
+				url = "/subscribers?limit=2&cursor=" + next
+			}
+		}
+
+		var total int64
+		database.Model(&models.Subscriber{}).Where("org_id = ?", models.DefaultOrgID).Count(&total)
+		if int64(len(seen)) != total {
+			t.Errorf("Expected %d subscribers across pages, got %d", total, len(seen))
+		}
+	})
+
+	t.Run("GetAllSubscribers - Total Count", func(t *testing.T) {
+		var active int64
+		database.Model(&models.Subscriber{}).Where("org_id = ? AND status = ?", models.DefaultOrgID, models.SubscriberStatusActive).Count(&active)
+
+		total := func(query string) string {
+			req, err := getRequestWithToken("GET", "/subscribers?status=active&"+query, nil, true)
+			if err != nil {
+				t.Fatalf("Failed to create request: %v", err)
+			}
+			resp, err := app.Test(req, -1)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("Expected 200, got %d", resp.StatusCode)
+			}
+			return resp.Header.Get("X-Total-Count")
+		}
+
+		want := fmt.Sprint(active)
+		for _, query := range []string{"", "limit=1", "limit=1&offset=1", "limit=1&offset=100000"} {
+			if got := total(query); got != want {
+				t.Errorf("Expected X-Total-Count %s for %q, got %q", want, query, got)
+			}
+		}
+	})
+
+	t.Run("GetAllSubscribers - If-Modified-Since => 304", func(t *testing.T) {
+		list := func(since string) *http.Response {
+			req, err := getRequestWithToken("GET", "/subscribers?status=active", nil, true)
+			if err != nil {
+				t.Fatalf("Failed to create request: %v", err)
+			}
+			if since != "" {
+				req.Header.Set("If-Modified-Since", since)
+			}
+			resp, err := app.Test(req, -1)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			return resp
+		}
+
+		// Last-Modified is only sent once the second of the latest write is over
+		time.Sleep(time.Until(time.Now().Truncate(time.Second).Add(time.Second)))
+		modified := list("").Header.Get("Last-Modified")
+		lastModified, err := http.ParseTime(modified)
+		if err != nil {
+			t.Fatalf("Expected a Last-Modified header, got %q", modified)
+		}
+		if resp := list(modified); resp.StatusCode != http.StatusNotModified {
+			t.Errorf("Expected 304 for an unchanged list, got %d", resp.StatusCode)
+		}
+
+		// a read in the second of a write gets no date that a later write in that second
+		// would still match
+		sameSecond := models.Subscriber{Email: "modified-same-second@example.com", Name: "Same second", Status: models.SubscriberStatusPending}
+		database.Create(&sameSecond)
+		resp := list(modified)
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected 200 after a write, got %d", resp.StatusCode)
+		}
+		if got := resp.Header.Get("Last-Modified"); got != "" {
+			t.Errorf("Expected no Last-Modified in the second of the latest write, got %s", got)
+		}
+
+		// once that second is over, Last-Modified is the write rounded up to the second
+		roundedUp := sameSecond.UpdatedAt.UTC().Truncate(time.Second)
+		if roundedUp.Before(sameSecond.UpdatedAt) {
+			roundedUp = roundedUp.Add(time.Second)
+		}
+		time.Sleep(time.Until(roundedUp))
+		want := roundedUp.Format(http.TimeFormat)
+		if got := list("").Header.Get("Last-Modified"); got != want {
+			t.Errorf("Expected Last-Modified %s after the second of the write, got %q", want, got)
+		}
+		if resp := list(want); resp.StatusCode != http.StatusNotModified {
+			t.Errorf("Expected 304 for the rounded-up date, got %d", resp.StatusCode)
+		}
+
+		// a write after the caller's copy, even to a subscriber the filter leaves out; its
+		// second isn't over yet, so there's no Last-Modified either
+		later := lastModified.Add(time.Hour)
+		s := models.Subscriber{Email: "modified-later@example.com", Name: "Later", Status: models.SubscriberStatusPending, CreatedAt: later, UpdatedAt: later}
+		database.Create(&s)
+		resp = list(want)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected 200 after a write, got %d", resp.StatusCode)
+		}
+		if got := resp.Header.Get("Last-Modified"); got != "" {
+			t.Errorf("Expected no Last-Modified before the second of the latest write, got %s", got)
+		}
+		if resp := list(later.UTC().Format(http.TimeFormat)); resp.StatusCode != http.StatusNotModified {
+			t.Errorf("Expected 304 since the write, got %d", resp.StatusCode)
+		}
+
+		// deletes count as changes too
+		database.Model(&s).UpdateColumn("deleted_at", later.Add(time.Hour))
+		if resp := list(later.UTC().Format(http.TimeFormat)); resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected 200 after a delete, got %d", resp.StatusCode)
+		}
+	})
+
 	t.Run("UpdateSubscriber - Not Found", func(t *testing.T) {
 		payload := `{"email": "updated@example.com", "name": "Updater"}`
 		req, err := getRequestWithToken("PUT", "/subscribers/999", strings.NewReader(payload), true)
@@ -245,6 +696,7 @@ func TestAdminSubscriberRoutes(t *testing.T) {
 		if err != nil {
 			t.Fatalf("Failed to create request: %v", err)
 		}
+		req.Header.Set("If-Match", currentETag(t, s.ID))
 
 		resp, err := app.Test(req)
 		if err != nil {
@@ -266,6 +718,7 @@ func TestAdminSubscriberRoutes(t *testing.T) {
 		if err != nil {
 			t.Fatalf("Failed to create request: %v", err)
 		}
+		req.Header.Set("If-Match", currentETag(t, s.ID))
 
 		resp, err := app.Test(req, -1)
 		if err != nil {
@@ -289,6 +742,611 @@ func TestAdminSubscriberRoutes(t *testing.T) {
 		}
 	})
 
+	t.Run("GetSubscriber - If-None-Match => 304", func(t *testing.T) {
+		s := models.Subscriber{Email: "etag-read@example.com", Name: "ETag Reader"}
+		database.Create(&s)
+
+		req, err := getRequestWithToken("GET", fmt.Sprintf("/subscribers/%d", s.ID), nil, true)
+		if err != nil {
+			t.Fatalf("Failed to create request: %v", err)
+		}
+		req.Header.Set("If-None-Match", currentETag(t, s.ID))
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusNotModified {
+			t.Errorf("Expected 304, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("UpdateSubscriber - Missing If-Match => 428", func(t *testing.T) {
+		s := models.Subscriber{Email: "etag-missing@example.com", Name: "No Precondition"}
+		database.Create(&s)
+
+		payload := `{"email": "etag-missing@example.com", "name": "Changed"}`
+		req, err := getRequestWithToken("PUT", fmt.Sprintf("/subscribers/%d", s.ID), strings.NewReader(payload), true)
+		if err != nil {
+			t.Fatalf("Failed to create request: %v", err)
+		}
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusPreconditionRequired {
+			t.Errorf("Expected 428, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("UpdateSubscriber - Stale If-Match => 412", func(t *testing.T) {
+		s := models.Subscriber{Email: "etag-stale@example.com", Name: "First Admin"}
+		database.Create(&s)
+		staleETag := currentETag(t, s.ID)
+
+		// a second admin edits the record in between
+		payload := `{"email": "etag-stale@example.com", "name": "Second Admin"}`
+		req, err := getRequestWithToken("PUT", fmt.Sprintf("/subscribers/%d", s.ID), strings.NewReader(payload), true)
+		if err != nil {
+			t.Fatalf("Failed to create request: %v", err)
+		}
+		req.Header.Set("If-Match", staleETag)
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected 200 for the first update, got %d", resp.StatusCode)
+		}
+
+		payload = `{"email": "etag-stale@example.com", "name": "First Admin Again"}`
+		req, err = getRequestWithToken("PUT", fmt.Sprintf("/subscribers/%d", s.ID), strings.NewReader(payload), true)
+		if err != nil {
+			t.Fatalf("Failed to create request: %v", err)
+		}
+		req.Header.Set("If-Match", staleETag)
+		resp, err = app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusPreconditionFailed {
+			t.Errorf("Expected 412, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("UpdateSubscriber - Stale Version => 409", func(t *testing.T) {
+		s := models.Subscriber{Email: "versioned@example.com", Name: "Versioned"}
+		database.Create(&s)
+
+		payload := fmt.Sprintf(`{"email": "versioned@example.com", "name": "Changed", "version": %d}`, s.Version+1)
+		req, err := getRequestWithToken("PUT", fmt.Sprintf("/subscribers/%d", s.ID), strings.NewReader(payload), true)
+		if err != nil {
+			t.Fatalf("Failed to create request: %v", err)
+		}
+		req.Header.Set("If-Match", currentETag(t, s.ID))
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusConflict {
+			t.Errorf("Expected 409, got %d", resp.StatusCode)
+		}
+
+		payload = fmt.Sprintf(`{"email": "versioned@example.com", "name": "Changed", "version": %d}`, s.Version)
+		req, err = getRequestWithToken("PUT", fmt.Sprintf("/subscribers/%d", s.ID), strings.NewReader(payload), true)
+		if err != nil {
+			t.Fatalf("Failed to create request: %v", err)
+		}
+		req.Header.Set("If-Match", currentETag(t, s.ID))
+		resp, err = app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected 200, got %d", resp.StatusCode)
+		}
+
+		var updated models.Subscriber
+		json.NewDecoder(resp.Body).Decode(&updated)
+		if updated.Version != s.Version+1 {
+			t.Errorf("Expected version %d after update, got %d", s.Version+1, updated.Version)
+		}
+	})
+
+	t.Run("GDPRExport - Success", func(t *testing.T) {
+		s := models.Subscriber{Email: "export-me@example.com", Name: "Exporter"}
+		database.Create(&s)
+		database.Create(&models.EmailLog{Type: "confirmation", ToEmail: s.Email, Status: models.EmailStatusDelivered})
+		campaign := models.Campaign{OrgID: models.DefaultOrgID, Name: "Export", Subject: "Export", Text: "Hello", Status: models.CampaignSent, Timezone: "UTC"}
+		database.Create(&campaign)
+		database.Create(&models.CampaignSend{CampaignID: campaign.ID, Run: 1, SubscriberID: s.ID, Email: s.Email})
+
+		req, err := getRequestWithToken("GET", fmt.Sprintf("/subscribers/%d/gdpr-export", s.ID), nil, true)
+		if err != nil {
+			t.Fatalf("Failed to create request: %v", err)
+		}
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected 200, got %d", resp.StatusCode)
+		}
+
+		var export dto.GDPRExport
+		json.NewDecoder(resp.Body).Decode(&export)
+		if export.Subscriber.Email != s.Email {
+			t.Errorf("Expected export to contain the raw email, got %q", export.Subscriber.Email)
+		}
+		if len(export.Emails) != 1 || export.Emails[0].ToEmail != s.Email {
+			t.Errorf("Expected the email sent to them in the export, got %+v", export.Emails)
+		}
+		if len(export.CampaignSends) != 1 || export.CampaignSends[0].Email != s.Email {
+			t.Errorf("Expected the campaign email sent to them in the export, got %+v", export.CampaignSends)
+		}
+	})
+
+	t.Run("AnonymizeSubscriber - Success", func(t *testing.T) {
+		s := models.Subscriber{
+			Email:           "forget-me@example.com",
+			Name:            "Forgettable",
+			SubscriberTypes: []models.SubscriberType{{Name: "donor"}},
+		}
+		database.Create(&s)
+		database.Create(&models.TrustedDevice{Email: s.Email, TokenHash: strings.Repeat("f", 64), ExpiresAt: time.Now().Add(time.Hour)})
+		sent := models.EmailLog{Type: "confirmation", ToEmail: s.Email, Status: models.EmailStatusFailed, Error: "rejected " + s.Email}
+		database.Create(&sent)
+		campaign := models.Campaign{OrgID: models.DefaultOrgID, Name: "Forget", Subject: "Forget", Text: "Hello", Status: models.CampaignSent, Timezone: "UTC"}
+		database.Create(&campaign)
+		campaignSend := models.CampaignSend{CampaignID: campaign.ID, Run: 1, SubscriberID: s.ID, Email: s.Email}
+		database.Create(&campaignSend)
+
+		path := fmt.Sprintf("/subscribers/%d/anonymize", s.ID)
+		req, err := getRequestWithToken("POST", path, nil, true)
+		if err != nil {
+			t.Fatalf("Failed to create request: %v", err)
+		}
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected 200, got %d", resp.StatusCode)
+		}
+
+		var check models.Subscriber
+		database.Preload("SubscriberTypes").First(&check, s.ID)
+		if check.Email == s.Email || check.Name != "" || check.AnonymizedAt == nil {
+			t.Errorf("Expected PII to be scrubbed, got %+v", check)
+		}
+		if len(check.SubscriberTypes) != 1 {
+			t.Errorf("Expected subscriber_types to be kept for stats, got %d", len(check.SubscriberTypes))
+		}
+		database.First(&sent, sent.ID)
+		if sent.ToEmail == s.Email || sent.Error != "" {
+			t.Errorf("Expected the logged email scrubbed, got %+v", sent)
+		}
+		database.First(&campaignSend, campaignSend.ID)
+		if campaignSend.Email == s.Email {
+			t.Errorf("Expected the campaign send scrubbed, got %+v", campaignSend)
+		}
+		var devices int64
+		database.Model(&models.TrustedDevice{}).Where("email = ?", s.Email).Count(&devices)
+		if devices != 0 {
+			t.Errorf("Expected the trusted devices deleted, got %d", devices)
+		}
+
+		// a second attempt is a conflict
+		req, _ = getRequestWithToken("POST", path, nil, true)
+		resp, err = app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusConflict {
+			t.Errorf("Expected 409 when anonymizing twice, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("BatchSubscribers - All Or Nothing", func(t *testing.T) {
+		s := models.Subscriber{Email: "batch-keep@example.com", Name: "Batch Keep"}
+		database.Create(&s)
+
+		// the second op fails, so the first must be rolled back
+		payload := fmt.Sprintf(`{"operations":[
+			{"op":"update","id":%d,"subscriber":{"email":"batch-changed@example.com","name":"Changed"}},
+			{"op":"delete","id":999999}
+		]}`, s.ID)
+		req, err := getRequestWithToken("POST", "/subscribers/batch", strings.NewReader(payload), true)
+		if err != nil {
+			t.Fatalf("Failed to create request: %v", err)
+		}
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusUnprocessableEntity {
+			t.Errorf("Expected 422, got %d", resp.StatusCode)
+		}
+
+		var result dto.BatchResponse
+		json.NewDecoder(resp.Body).Decode(&result)
+		if result.Committed || len(result.Results) != 2 || result.Results[1].Status != http.StatusNotFound {
+			t.Errorf("Unexpected batch result: %+v", result)
+		}
+
+		var check models.Subscriber
+		database.First(&check, s.ID)
+		if check.Email != "batch-keep@example.com" {
+			t.Errorf("Expected update to be rolled back, got email %s", check.Email)
+		}
+	})
+
+	t.Run("BatchSubscribers - Success", func(t *testing.T) {
+		payload := `{"operations":[
+			{"op":"create","subscriber":{"email":"batch-a@example.com","name":"A"}},
+			{"op":"create","subscriber":{"email":"batch-b@example.com","name":"B","subscriber_types":[{"name":"driver"}]}}
+		]}`
+		req, err := getRequestWithToken("POST", "/subscribers/batch", strings.NewReader(payload), true)
+		if err != nil {
+			t.Fatalf("Failed to create request: %v", err)
+		}
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected 200, got %d", resp.StatusCode)
+		}
+
+		var result dto.BatchResponse
+		json.NewDecoder(resp.Body).Decode(&result)
+		if !result.Committed || len(result.Results) != 2 || result.Results[1].Subscriber == nil {
+			t.Errorf("Unexpected batch result: %+v", result)
+		}
+	})
+
+	t.Run("DeliveryEvents - Subscriber Not Found", func(t *testing.T) {
+		req, err := getRequestWithToken("GET", "/subscribers/999999/delivery-events", nil, true)
+		if err != nil {
+			t.Fatalf("Failed to create request: %v", err)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Expected 404 for non-existent subscriber, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("ResendConfirmation - Cooldown And Not Pending", func(t *testing.T) {
+		var sent []string
+		original := sendgridservice.SendConfirmationEmailFunc
+		sendgridservice.SendConfirmationEmailFunc = func(email, locale, link string) error {
+			sent = append(sent, link)
+			return nil
+		}
+		defer func() { sendgridservice.SendConfirmationEmailFunc = original }()
+
+		hash := "resend-test-hash"
+		longAgo := time.Now().Add(-time.Hour)
+		pending := models.Subscriber{
+			Email: "pending-resend@example.com", Name: "Pending Person",
+			Status: models.SubscriberStatusPending, ConfirmTokenHash: &hash, ConfirmSentAt: &longAgo,
+		}
+		database.Create(&pending)
+
+		resend := func(id uint) *http.Response {
+			req, err := getRequestWithToken("POST", fmt.Sprintf("/subscribers/%d/resend-confirmation", id), nil, true)
+			if err != nil {
+				t.Fatalf("Failed to create request: %v", err)
+			}
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			return resp
+		}
+
+		if resp := resend(pending.ID); resp.StatusCode != http.StatusOK || len(sent) != 1 {
+			t.Fatalf("Expected 200 and one email, got %d and %d", resp.StatusCode, len(sent))
+		}
+		if resp := resend(pending.ID); resp.StatusCode != http.StatusTooManyRequests || resp.Header.Get("Retry-After") == "" {
+			t.Errorf("Expected 429 with Retry-After within the cooldown, got %d", resp.StatusCode)
+		}
+
+		active := models.Subscriber{Email: "active-resend@example.com", Name: "Active Person"}
+		database.Create(&active)
+		if resp := resend(active.ID); resp.StatusCode != http.StatusConflict {
+			t.Errorf("Expected 409 for an active subscriber, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("Notes And Metadata", func(t *testing.T) {
+		payload := `{"email": "noted@example.com", "name": "Noted", "metadata": {"crm_id": "A-42"}}`
+		req, err := getRequestWithToken("POST", "/subscribers", strings.NewReader(payload), true)
+		if err != nil {
+			t.Fatalf("Failed to create request: %v", err)
+		}
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		var created models.Subscriber
+		json.NewDecoder(resp.Body).Decode(&created)
+		if resp.StatusCode != http.StatusCreated || created.Metadata["crm_id"] != "A-42" {
+			t.Fatalf("Expected 201 with metadata, got %d and %v", resp.StatusCode, created.Metadata)
+		}
+
+		notesPath := fmt.Sprintf("/subscribers/%d/notes", created.ID)
+		req, _ = getRequestWithToken("POST", notesPath, strings.NewReader(`{"text": "Prefers the digest"}`), true)
+		resp, err = app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		var note dto.SubscriberNoteResponse
+		json.NewDecoder(resp.Body).Decode(&note)
+		if resp.StatusCode != http.StatusCreated || note.Text != "Prefers the digest" {
+			t.Fatalf("Expected 201 with the note, got %d", resp.StatusCode)
+		}
+
+		req, _ = getRequestWithToken("POST", notesPath, strings.NewReader(`{"text": "  "}`), true)
+		if resp, _ = app.Test(req, -1); resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected 400 for an empty note, got %d", resp.StatusCode)
+		}
+
+		req, _ = getRequestWithToken("GET", notesPath, nil, true)
+		resp, _ = app.Test(req, -1)
+		var notes []dto.SubscriberNoteResponse
+		json.NewDecoder(resp.Body).Decode(&notes)
+		if len(notes) != 1 {
+			t.Fatalf("Expected 1 note, got %d", len(notes))
+		}
+
+		req, _ = getRequestWithToken("DELETE", fmt.Sprintf("%s/%d", notesPath, note.ID), nil, true)
+		if resp, _ = app.Test(req, -1); resp.StatusCode != http.StatusNoContent {
+			t.Errorf("Expected 204, got %d", resp.StatusCode)
+		}
+		req, _ = getRequestWithToken("DELETE", fmt.Sprintf("%s/%d", notesPath, note.ID), nil, true)
+		if resp, _ = app.Test(req, -1); resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Expected 404 for a deleted note, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("Referrals - Code And History", func(t *testing.T) {
+		req, _ := getRequestWithToken("POST", "/subscribers", strings.NewReader(`{"email": "referrer@example.com", "name": "Referrer"}`), true)
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		var referrer dto.SubscriberResponse
+		json.NewDecoder(resp.Body).Decode(&referrer)
+		if resp.StatusCode != http.StatusCreated || len(referrer.ReferralCode) != 10 {
+			t.Fatalf("Expected 201 with a referral code, got %d and %q", resp.StatusCode, referrer.ReferralCode)
+		}
+
+		first := models.Subscriber{Email: "referee-1@example.com", Name: "First", ReferredByID: &referrer.ID}
+		second := models.Subscriber{Email: "referee-2@example.com", Name: "Second", Status: models.SubscriberStatusPending, ReferredByID: &referrer.ID}
+		database.Create(&first)
+		database.Create(&second)
+
+		req, _ = getRequestWithToken("GET", fmt.Sprintf("/subscribers/%d/referrals", referrer.ID), nil, true)
+		resp, err = app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		var referrals dto.SubscriberReferralsResponse
+		json.NewDecoder(resp.Body).Decode(&referrals)
+		if resp.StatusCode != http.StatusOK || referrals.ReferralCode != referrer.ReferralCode {
+			t.Fatalf("Expected 200 with the referral code, got %d and %+v", resp.StatusCode, referrals)
+		}
+		if referrals.Referrals != 2 || referrals.Active != 1 || len(referrals.Referees) != 2 || referrals.Referees[0].ID != second.ID {
+			t.Errorf("Expected 2 referees newest first, 1 active, got %+v", referrals)
+		}
+
+		req, _ = getRequestWithToken("GET", fmt.Sprintf("/subscribers/%d/referrals", first.ID), nil, true)
+		resp, _ = app.Test(req, -1)
+		json.NewDecoder(resp.Body).Decode(&referrals)
+		if referrals.ReferredBy == nil || referrals.ReferredBy.ID != referrer.ID || len(referrals.Referees) != 0 {
+			t.Errorf("Expected the referee referred by %d, got %+v", referrer.ID, referrals)
+		}
+	})
+
+	t.Run("MergeSubscribers - Moves Types, Notes And Events", func(t *testing.T) {
+		verified := time.Now()
+		target := models.Subscriber{
+			Email: "merge-target@example.com", Name: "Target",
+			SubscriberTypes: []models.SubscriberType{{Name: "shopper"}},
+		}
+		source := models.Subscriber{
+			Email: "merge-source@example.com", Name: "Source", EmailVerifiedAt: &verified,
+			SubscriberTypes: []models.SubscriberType{{Name: "shopper"}, {Name: "donor"}},
+		}
+		database.Create(&target)
+		database.Create(&source)
+		database.Create(&models.SubscriberNote{SubscriberID: source.ID, Author: "admin@example.com", Text: "Duplicate"})
+		database.Create(&models.DeliveryEvent{SubscriberID: target.ID, Event: "delivered", SGEventID: "merge-shared", OccurredAt: verified})
+		database.Create(&models.DeliveryEvent{SubscriberID: source.ID, Event: "delivered", SGEventID: "merge-shared", OccurredAt: verified})
+		database.Create(&models.DeliveryEvent{SubscriberID: source.ID, Event: "delivered", SGEventID: "merge-own", OccurredAt: verified})
+		referee := models.Subscriber{Email: "merge-referee@example.com", Name: "Referee", ReferredByID: &source.ID}
+		database.Create(&referee)
+
+		path := fmt.Sprintf("/subscribers/%d/merge", target.ID)
+		req, _ := getRequestWithToken("POST", path, strings.NewReader(fmt.Sprintf(`{"source_id": %d}`, target.ID)), true)
+		if resp, _ := app.Test(req, -1); resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected 400 merging into itself, got %d", resp.StatusCode)
+		}
+
+		body := fmt.Sprintf(`{"source_id": %d, "email_rule": "verified"}`, source.ID)
+		req, _ = getRequestWithToken("POST", path, strings.NewReader(body), true)
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		var merged models.Subscriber
+		json.NewDecoder(resp.Body).Decode(&merged)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected 200, got %d", resp.StatusCode)
+		}
+		if merged.Email != "merge-source@example.com" || len(merged.SubscriberTypes) != 2 {
+			t.Errorf("Expected the verified address and 2 types, got %s and %d", merged.Email, len(merged.SubscriberTypes))
+		}
+
+		var notes, events, leftover int64
+		database.Model(&models.SubscriberNote{}).Where("subscriber_id = ?", target.ID).Count(&notes)
+		database.Model(&models.DeliveryEvent{}).Where("subscriber_id = ?", target.ID).Count(&events)
+		database.Model(&models.Subscriber{}).Where("id = ?", source.ID).Count(&leftover)
+		if notes != 1 || events != 2 || leftover != 0 {
+			t.Errorf("Expected 1 note, 2 events and the source deleted, got %d, %d and %d", notes, events, leftover)
+		}
+		database.First(&referee, referee.ID)
+		if referee.ReferredByID == nil || *referee.ReferredByID != target.ID {
+			t.Errorf("Expected the referee of the source moved to the target, got %v", referee.ReferredByID)
+		}
+	})
+
+	t.Run("History - Records Who Changed What", func(t *testing.T) {
+		payload := `{"email": "history@example.com", "name": "Before", "metadata": {"plan": "free"}}`
+		req, _ := getRequestWithToken("POST", "/subscribers", strings.NewReader(payload), true)
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		var created models.Subscriber
+		json.NewDecoder(resp.Body).Decode(&created)
+
+		payload = `{"email": "history@example.com", "name": "After", "metadata": {"plan": "pro"}}`
+		req, _ = getRequestWithToken("PUT", fmt.Sprintf("/subscribers/%d", created.ID), strings.NewReader(payload), true)
+		req.Header.Set("If-Match", currentETag(t, created.ID))
+		if resp, _ = app.Test(req, -1); resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected 200 updating, got %d", resp.StatusCode)
+		}
+
+		req, _ = getRequestWithToken("GET", fmt.Sprintf("/subscribers/%d/history", created.ID), nil, true)
+		resp, err = app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		var history []dto.SubscriberRevisionResponse
+		json.NewDecoder(resp.Body).Decode(&history)
+		if resp.StatusCode != http.StatusOK || len(history) != 2 {
+			t.Fatalf("Expected 200 with 2 revisions, got %d and %d", resp.StatusCode, len(history))
+		}
+
+		latest := history[0]
+		if latest.Action != models.RevisionUpdated || latest.Author != "admin@example.com" || latest.Before == nil {
+			t.Errorf("Expected an update by admin@example.com with a before snapshot, got %+v", latest)
+		}
+		fields := []string{}
+		for _, change := range latest.Changes {
+			fields = append(fields, change.Field)
+		}
+		if strings.Join(fields, ",") != "name,metadata.plan" {
+			t.Errorf("Expected name and metadata.plan to change, got %v", fields)
+		}
+		if history[1].Action != models.RevisionCreated || history[1].Before != nil {
+			t.Errorf("Expected the creation without a before snapshot, got %+v", history[1])
+		}
+
+		req, _ = getRequestWithToken("GET", fmt.Sprintf("/subscribers/%d/history?limit=1&before=%d", created.ID, latest.ID), nil, true)
+		resp, _ = app.Test(req, -1)
+		history = nil
+		json.NewDecoder(resp.Body).Decode(&history)
+		if len(history) != 1 || history[0].Action != models.RevisionCreated {
+			t.Fatalf("Expected the next page to hold the creation, got %d revisions", len(history))
+		}
+
+		restorePath := fmt.Sprintf("/subscribers/%d/history/%d/restore", created.ID, history[0].ID)
+		req, _ = getRequestWithToken("POST", restorePath, nil, true)
+		resp, err = app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		var restored models.Subscriber
+		json.NewDecoder(resp.Body).Decode(&restored)
+		if resp.StatusCode != http.StatusOK || restored.Name != "Before" || restored.Metadata["plan"] != "free" {
+			t.Errorf("Expected 200 with the original name and metadata, got %d, %s and %v", resp.StatusCode, restored.Name, restored.Metadata)
+		}
+
+		var latestAction string
+		database.Model(&models.SubscriberRevision{}).Select("action").
+			Where("subscriber_id = ?", created.ID).Order("id DESC").Limit(1).Scan(&latestAction)
+		if latestAction != models.RevisionRestored {
+			t.Errorf("Expected the rollback to be recorded as a restored revision, got %q", latestAction)
+		}
+
+		req, _ = getRequestWithToken("POST", fmt.Sprintf("/subscribers/%d/history/999999/restore", created.ID), nil, true)
+		if resp, _ = app.Test(req, -1); resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Expected 404 for an unknown revision, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("Preferences - Campaign Filter And Link", func(t *testing.T) {
+		email := fmt.Sprintf("prefs-%d@example.com", time.Now().UnixNano())
+		payload := fmt.Sprintf(`{"email": "%s", "name": "Prefs", "subscriber_types": [{"name": "donor", "frequency": "monthly", "channel": "sms"}, {"name": "shopper"}]}`, email)
+		req, _ := getRequestWithToken("POST", "/subscribers", strings.NewReader(payload), true)
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		var created dto.SubscriberResponse
+		json.NewDecoder(resp.Body).Decode(&created)
+		if resp.StatusCode != http.StatusCreated || len(created.SubscriberTypes) != 2 {
+			t.Fatalf("Expected 201 with 2 types, got %d", resp.StatusCode)
+		}
+		for _, st := range created.SubscriberTypes {
+			if st.Name == "shopper" && (st.Frequency != models.FrequencyWeekly || st.Channel != models.ChannelEmail) {
+				t.Errorf("Expected shopper to default to weekly email, got %s %s", st.Frequency, st.Channel)
+			}
+		}
+
+		targets := func(query string) bool {
+			req, _ := getRequestWithToken("GET", "/subscribers?"+query, nil, true)
+			resp, _ := app.Test(req, -1)
+			var subs []dto.SubscriberResponse
+			json.NewDecoder(resp.Body).Decode(&subs)
+			for _, s := range subs {
+				if s.ID == created.ID {
+					return true
+				}
+			}
+			return false
+		}
+		if !targets("subscriber_type=donor&frequency=monthly&channel=sms") {
+			t.Errorf("Expected monthly sms donors to include the subscriber")
+		}
+		// both criteria must hold for the same subscriber_type
+		if targets("subscriber_type=shopper&channel=sms") {
+			t.Errorf("Expected sms shoppers not to include the subscriber")
+		}
+
+		req, _ = getRequestWithToken("GET", "/subscribers?frequency=hourly", nil, true)
+		if resp, _ = app.Test(req, -1); resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected 400 for an invalid frequency, got %d", resp.StatusCode)
+		}
+		payload = fmt.Sprintf(`{"email": "%s", "name": "Prefs", "subscriber_types": [{"name": "donor", "channel": "fax"}]}`, email)
+		req, _ = getRequestWithToken("PUT", fmt.Sprintf("/subscribers/%d", created.ID), strings.NewReader(payload), true)
+		req.Header.Set("If-Match", currentETag(t, created.ID))
+		if resp, _ = app.Test(req, -1); resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected 400 for an invalid channel, got %d", resp.StatusCode)
+		}
+
+		req, _ = getRequestWithToken("POST", fmt.Sprintf("/subscribers/%d/preferences-link", created.ID), nil, true)
+		resp, err = app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		var link dto.PreferencesLinkResponse
+		json.NewDecoder(resp.Body).Decode(&link)
+		if resp.StatusCode != http.StatusOK || !strings.Contains(link.URL, "/preferences/") || !link.ExpiresAt.After(time.Now()) {
+			t.Errorf("Expected 200 with a link, got %d and %+v", resp.StatusCode, link)
+		}
+
+		req, _ = getRequestWithToken("POST", "/subscribers/999999/preferences-link", nil, true)
+		if resp, _ = app.Test(req, -1); resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Expected 404 for an unknown subscriber, got %d", resp.StatusCode)
+		}
+	})
+
 	t.Run("DeleteSubscriber - Not Found", func(t *testing.T) {
 		req, err := getRequestWithToken("DELETE", "/subscribers/999", nil, true)
 		if err != nil {
@@ -329,4 +1387,63 @@ func TestAdminSubscriberRoutes(t *testing.T) {
 			t.Errorf("Expected subscriber to be deleted, but it still exists.")
 		}
 	})
+	t.Run("UndeleteSubscriber", func(t *testing.T) {
+		payload := `{"email": "undelete-me@example.com", "name": "Undelete", "subscriber_types": [{"name": "donor"}]}`
+		req, _ := getRequestWithToken("POST", "/subscribers", strings.NewReader(payload), true)
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		var created dto.SubscriberResponse
+		json.NewDecoder(resp.Body).Decode(&created)
+		path := fmt.Sprintf("/subscribers/%d", created.ID)
+		database.Create(&models.SubscriberNote{SubscriberID: created.ID, Author: "admin@example.com", Text: "Kept"})
+
+		undelete := func() *http.Response {
+			req, _ := getRequestWithToken("POST", path+"/undelete", nil, true)
+			resp, err := app.Test(req, -1)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			return resp
+		}
+		if resp := undelete(); resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Expected 404 for a subscriber that isn't deleted, got %d", resp.StatusCode)
+		}
+
+		req, _ = getRequestWithToken("DELETE", path, nil, true)
+		if resp, _ = app.Test(req, -1); resp.StatusCode != http.StatusNoContent {
+			t.Fatalf("Expected 204 deleting, got %d", resp.StatusCode)
+		}
+		resp = undelete()
+		var restored dto.SubscriberResponse
+		json.NewDecoder(resp.Body).Decode(&restored)
+		if resp.StatusCode != http.StatusOK || len(restored.SubscriberTypes) != 1 || restored.Version != created.Version+1 {
+			t.Fatalf("Expected 200 with the subscriber_types back and a bumped version, got %d and %+v", resp.StatusCode, restored)
+		}
+		var notes int64
+		database.Model(&models.SubscriberNote{}).Where("subscriber_id = ?", created.ID).Count(&notes)
+		if notes != 1 {
+			t.Errorf("Expected the note to be kept, found %d", notes)
+		}
+		req, _ = getRequestWithToken("GET", path+"/history", nil, true)
+		resp, _ = app.Test(req, -1)
+		var history []dto.SubscriberRevisionResponse
+		json.NewDecoder(resp.Body).Decode(&history)
+		if len(history) == 0 || history[0].Action != models.RevisionUndeleted {
+			t.Errorf("Expected an undeleted revision, got %+v", history)
+		}
+
+		// past the undo window, due to be purged
+		req, _ = getRequestWithToken("DELETE", path, nil, true)
+		app.Test(req, -1)
+		database.Unscoped().Model(&models.Subscriber{}).Where("id = ?", created.ID).
+			UpdateColumn("deleted_at", time.Now().Add(-31*24*time.Hour))
+		resp = undelete()
+		var body map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&body)
+		if resp.StatusCode != http.StatusGone || body["code"] != "undo_window_expired" {
+			t.Errorf("Expected 410 undo_window_expired, got %d and %v", resp.StatusCode, body)
+		}
+	})
 }
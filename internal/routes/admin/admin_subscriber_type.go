@@ -0,0 +1,26 @@
+package admin
+
+import (
+	"fiber-gorm-api/internal/handlers"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// RegisterSubscriberTypeRoutes registers the subscriber type catalog under /admin/subscriber-types.
+// Types are shared by every organization, so only platform admins change them.
+func RegisterSubscriberTypeRoutes(adminGroup fiber.Router, db *gorm.DB) {
+	types := adminGroup.Group("/subscriber-types", middleware.RequireMethodScope)
+	adminScope := middleware.RequireScope(models.ScopeAdmin)
+
+	// Read all
+	types.Get("/", handlers.GetSubscriberTypes(db))
+
+	// Create
+	types.Post("/", adminScope, middleware.RequirePlatformOrg, handlers.CreateSubscriberType(db))
+
+	// Delete (only when no subscriber has it)
+	types.Delete("/:name", adminScope, middleware.RequirePlatformOrg, handlers.DeleteSubscriberType(db))
+}
@@ -0,0 +1,189 @@
+package admin
+
+import (
+	"encoding/json"
+	"fiber-gorm-api/internal/db"
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
+	redisclient "fiber-gorm-api/internal/redis"
+	"fiber-gorm-api/internal/session"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestAdminSubscriberTypeRoutes(t *testing.T) {
+	database := db.Connect(true)
+	redisclient.InitRedis("session")
+
+	app := fiber.New()
+	app.Use(middleware.RequireJWTOrAPIKey(database))
+	RegisterSubscriberRoutes(app, database)
+	RegisterSubscriberTypeRoutes(app, database)
+
+	tokenFor := func(email string, orgID uint) string {
+		sess, err := session.Create(redisclient.Ctx, email, orgID, "", "")
+		if err != nil {
+			t.Fatalf("failed to create session: %v", err)
+		}
+		token, err := middleware.GenerateJWT(sess.ID)
+		if err != nil {
+			t.Fatalf("failed to generate token: %v", err)
+		}
+		return token
+	}
+	request := func(method, url, body, token string) *http.Request {
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		return req
+	}
+
+	platformToken := tokenFor("platform-admin@example.com", models.DefaultOrgID)
+	name := fmt.Sprintf("volunteer_%d", time.Now().UnixNano()%1000000)
+
+	t.Run("GetSubscriberTypes - Defaults", func(t *testing.T) {
+		resp, err := app.Test(request("GET", "/subscriber-types", "", platformToken), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected 200, got %d", resp.StatusCode)
+		}
+		var types []dto.SubscriberTypeDefinitionResponse
+		json.NewDecoder(resp.Body).Decode(&types)
+		names := map[string]bool{}
+		for _, st := range types {
+			names[st.Name] = true
+		}
+		for _, d := range models.DefaultSubscriberTypeNames {
+			if !names[d] {
+				t.Errorf("Expected default type %q to be listed", d)
+			}
+		}
+	})
+
+	t.Run("CreateSubscriber - Unknown Type", func(t *testing.T) {
+		body := fmt.Sprintf(`{"email":"types-%d@example.com","name":"Types","subscriber_types":[{"name":"%s"}]}`, time.Now().UnixNano(), name)
+		resp, err := app.Test(request("POST", "/subscribers", body, platformToken), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusUnprocessableEntity {
+			t.Fatalf("Expected 422, got %d", resp.StatusCode)
+		}
+		var invalid dto.InvalidSubscriberTypesResponse
+		json.NewDecoder(resp.Body).Decode(&invalid)
+		if invalid.Code != "invalid_subscriber_type" || len(invalid.Invalid) != 1 || invalid.Invalid[0] != name {
+			t.Errorf("Expected %s to be reported as invalid, got %+v", name, invalid)
+		}
+		if !slices.Contains(invalid.Accepted, "shopper") {
+			t.Errorf("Expected the accepted types to be listed, got %v", invalid.Accepted)
+		}
+	})
+
+	t.Run("CreateSubscriberType - Success", func(t *testing.T) {
+		body := fmt.Sprintf(`{"name":"%s","description":"Helps out at local events"}`, name)
+		resp, err := app.Test(request("POST", "/subscriber-types", body, platformToken), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("Expected 201, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("CreateSubscriberType - Duplicate", func(t *testing.T) {
+		resp, err := app.Test(request("POST", "/subscriber-types", fmt.Sprintf(`{"name":"%s"}`, name), platformToken), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusConflict {
+			t.Errorf("Expected 409, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("CreateSubscriberType - Invalid Name", func(t *testing.T) {
+		resp, err := app.Test(request("POST", "/subscriber-types", `{"name":"Not A Type"}`, platformToken), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected 400, got %d", resp.StatusCode)
+		}
+	})
+
+	org := models.Organization{Name: "Types Tenant", Slug: fmt.Sprintf("types-%d", time.Now().UnixNano())}
+	if err := database.Create(&org).Error; err != nil {
+		t.Fatalf("failed to create organization: %v", err)
+	}
+	tenantToken := tokenFor("types-tenant-admin@example.com", org.ID)
+
+	t.Run("CreateSubscriberType - Tenant Admin Forbidden", func(t *testing.T) {
+		resp, err := app.Test(request("POST", "/subscriber-types", `{"name":"tenant_type"}`, tenantToken), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("Expected 403, got %d", resp.StatusCode)
+		}
+	})
+
+	var subscriber dto.SubscriberResponse
+	t.Run("CreateSubscriber - New Type", func(t *testing.T) {
+		body := fmt.Sprintf(`{"email":"types-%d@example.com","name":"Types","subscriber_types":[{"name":"%s"}]}`, time.Now().UnixNano(), name)
+		resp, err := app.Test(request("POST", "/subscribers", body, platformToken), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("Expected 201, got %d", resp.StatusCode)
+		}
+		json.NewDecoder(resp.Body).Decode(&subscriber)
+	})
+
+	t.Run("DeleteSubscriberType - In Use", func(t *testing.T) {
+		resp, err := app.Test(request("DELETE", "/subscriber-types/"+name, "", platformToken), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusConflict {
+			t.Fatalf("Expected 409, got %d", resp.StatusCode)
+		}
+		var body map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&body)
+		if body["code"] != "type_in_use" {
+			t.Errorf("Expected code type_in_use, got %v", body["code"])
+		}
+	})
+
+	t.Run("DeleteSubscriberType - Success", func(t *testing.T) {
+		if err := database.Where("subscriber_id = ?", subscriber.ID).Delete(&models.SubscriberType{}).Error; err != nil {
+			t.Fatalf("failed to clear types: %v", err)
+		}
+		resp, err := app.Test(request("DELETE", "/subscriber-types/"+name, "", platformToken), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusNoContent {
+			t.Errorf("Expected 204, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("DeleteSubscriberType - Not Found", func(t *testing.T) {
+		resp, err := app.Test(request("DELETE", "/subscriber-types/"+name, "", platformToken), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Expected 404, got %d", resp.StatusCode)
+		}
+	})
+}
@@ -0,0 +1,15 @@
+package admin
+
+import (
+	"fiber-gorm-api/internal/handlers"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RegisterWebSocketRoutes registers the real-time notification socket under /admin/ws.
+// The upgrade is authenticated like every admin route, see middleware.WebSocketBearer for browsers.
+func RegisterWebSocketRoutes(adminGroup fiber.Router) {
+	adminGroup.Get("/ws", middleware.RequireScope(models.ScopeRead), handlers.AdminWebSocket())
+}
@@ -0,0 +1,20 @@
+package events
+
+import (
+	"fiber-gorm-api/internal/db"
+	"fiber-gorm-api/internal/handlers"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RegisterRoutes sets up the calendar invites of local events under /events. They're fetched
+// by calendars and linked from emails, so no CORS or JWT.
+func RegisterRoutes(app *fiber.App) {
+	eventGroup := app.Group("/events")
+
+	// Initialize DB
+	database := db.Connect(false)
+
+	// iCalendar invite
+	eventGroup.Get("/:id/calendar.ics", handlers.GetEventCalendar(database))
+}
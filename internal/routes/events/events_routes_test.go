@@ -0,0 +1,63 @@
+package events
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"fiber-gorm-api/internal/db"
+	"fiber-gorm-api/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestEventCalendarRoute(t *testing.T) {
+	database := db.Connect(true)
+	app := fiber.New()
+	RegisterRoutes(app)
+
+	start := time.Date(2025, 7, 5, 7, 0, 0, 0, time.UTC)
+	event := models.Event{OrgID: models.DefaultOrgID, Title: "Summer market", Location: "Place du Marché", StartsAt: start, EndsAt: start.Add(5 * time.Hour), Timezone: "Europe/Paris", Sequence: 1}
+	database.Create(&event)
+
+	get := func(path string) (int, string, http.Header) {
+		t.Helper()
+		resp, err := app.Test(httptest.NewRequest("GET", path, nil))
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body), resp.Header
+	}
+
+	t.Run("GetEventCalendar", func(t *testing.T) {
+		status, body, header := get("/events/" + strconv.FormatUint(uint64(event.ID), 10) + "/calendar.ics")
+		if status != fiber.StatusOK {
+			t.Fatalf("Expected 200, got %d", status)
+		}
+		if ct := header.Get("Content-Type"); !strings.HasPrefix(ct, "text/calendar") {
+			t.Errorf("Expected a calendar, got %s", ct)
+		}
+		if cd := header.Get("Content-Disposition"); cd != `attachment; filename="summer-market.ics"` {
+			t.Errorf("Unexpected Content-Disposition %s", cd)
+		}
+		for _, want := range []string{"BEGIN:VCALENDAR\r\n", "SUMMARY:Summer market\r\n", "DTSTART:20250705T070000Z\r\n", "SEQUENCE:1\r\n"} {
+			if !strings.Contains(body, want) {
+				t.Errorf("Expected %q in\n%s", want, body)
+			}
+		}
+	})
+
+	t.Run("GetEventCalendar - Not Found", func(t *testing.T) {
+		if status, _, _ := get("/events/999999999/calendar.ics"); status != fiber.StatusNotFound {
+			t.Errorf("Expected 404, got %d", status)
+		}
+		if status, _, _ := get("/events/x/calendar.ics"); status != fiber.StatusBadRequest {
+			t.Errorf("Expected 400, got %d", status)
+		}
+	})
+}
@@ -0,0 +1,20 @@
+package links
+
+import (
+	"fiber-gorm-api/internal/db"
+	"fiber-gorm-api/internal/handlers"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RegisterRoutes sets up the short links under /l. They're opened by anyone the links were
+// handed to, so no CORS or JWT.
+func RegisterRoutes(app *fiber.App) {
+	linkGroup := app.Group("/l")
+
+	// Initialize DB
+	database := db.Connect(false)
+
+	// Redirect, counting the click
+	linkGroup.Get("/:code", handlers.FollowShortLink(database))
+}
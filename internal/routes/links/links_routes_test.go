@@ -0,0 +1,65 @@
+package links
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"fiber-gorm-api/internal/db"
+	"fiber-gorm-api/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestShortLinkRoute(t *testing.T) {
+	database := db.Connect(true)
+	app := fiber.New()
+	RegisterRoutes(app)
+
+	link := models.ShortLink{OrgID: models.DefaultOrgID, Code: fmt.Sprintf("go-%d", time.Now().UnixNano()), URL: "https://market.example.org/spring?utm_source=newsletter"}
+	database.Create(&link)
+
+	follow := func(code, userAgent string) int {
+		t.Helper()
+		req := httptest.NewRequest("GET", "/l/"+code, nil)
+		req.Header.Set("User-Agent", userAgent)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode == fiber.StatusFound && resp.Header.Get("Location") != link.URL {
+			t.Errorf("Expected a redirect to the link, got %s", resp.Header.Get("Location"))
+		}
+		return resp.StatusCode
+	}
+	clicks := func() (int, *time.Time) {
+		var got models.ShortLink
+		database.First(&got, link.ID)
+		return got.Clicks, got.LastClickedAt
+	}
+
+	t.Run("FollowShortLink - Unknown Code", func(t *testing.T) {
+		if status := follow(link.Code+"-nope", "Mozilla/5.0"); status != fiber.StatusNotFound {
+			t.Errorf("Expected 404, got %d", status)
+		}
+	})
+
+	t.Run("FollowShortLink - Bots Not Counted", func(t *testing.T) {
+		if status := follow(link.Code, "Mozilla/5.0 (compatible; Googlebot/2.1)"); status != fiber.StatusFound {
+			t.Errorf("Expected a redirect, got %d", status)
+		}
+		if n, _ := clicks(); n != 0 {
+			t.Errorf("Expected no click counted, got %d", n)
+		}
+	})
+
+	t.Run("FollowShortLink - Counts Clicks", func(t *testing.T) {
+		follow(link.Code, "Mozilla/5.0")
+		follow(strings.ToUpper(link.Code), "Mozilla/5.0")
+		if n, at := clicks(); n != 2 || at == nil {
+			t.Errorf("Expected 2 clicks counted, got %d at %v", n, at)
+		}
+	})
+}
@@ -0,0 +1,29 @@
+package preferences
+
+import (
+	"fiber-gorm-api/internal/db"
+	"fiber-gorm-api/internal/handlers"
+	"fiber-gorm-api/internal/middleware"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/cors"
+)
+
+// RegisterRoutes registers the public preferences page routes. The emailed token of the link
+// identifies the subscriber, no sign in.
+func RegisterRoutes(app *fiber.App) {
+	prefs := app.Group("/preferences", middleware.CORS("preferences", "https://signup.mylocal.ing", cors.Config{
+		AllowHeaders: "Origin, Content-Type, Accept, X-Org, X-Captcha-Token",
+	}))
+
+	// Initialize DB
+	database := db.Connect(false)
+
+	// Email a link to the subscriber with the address, in the organization named by X-Org / ?org=.
+	// Bot checks run first, as for signups.
+	prefs.Post("/", middleware.RejectBots(), middleware.ResolveOrg(database), handlers.RequestPreferencesLink(database))
+
+	// The page behind the link: view, then change name, subscriber_types or subscription
+	prefs.Get("/:token", handlers.GetPreferences(database))
+	prefs.Put("/:token", handlers.UpdatePreferences(database))
+}
@@ -0,0 +1,179 @@
+package preferences
+
+import (
+	"context"
+	"encoding/json"
+	"fiber-gorm-api/internal/db"
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/models"
+	redisclient "fiber-gorm-api/internal/redis"
+	"fiber-gorm-api/internal/repository"
+	"fiber-gorm-api/internal/service"
+	sendgridservice "fiber-gorm-api/internal/services"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestPreferencesRoutes(t *testing.T) {
+	redisclient.InitRedis("session")
+	var emailed []string
+	original := sendgridservice.SendPreferencesEmailFunc
+	sendgridservice.SendPreferencesEmailFunc = func(email, link string) error {
+		emailed = append(emailed, link)
+		return nil
+	}
+	t.Cleanup(func() { sendgridservice.SendPreferencesEmailFunc = original })
+
+	app := fiber.New()
+	RegisterRoutes(app)
+
+	svc := service.NewSubscriberService(repository.NewSubscriberRepository(db.Connect(true)))
+	ctx := context.Background()
+	email := fmt.Sprintf("preferences-%d@example.com", time.Now().UnixNano())
+	subscriber, err := svc.Create(ctx, service.CreateSubscriberInput{
+		OrgID: models.DefaultOrgID,
+		Email: email,
+		Name:  "Prefs",
+		SubscriberTypes: []models.SubscriberType{
+			{Name: "shopper"},
+			{Name: "donor", Frequency: models.FrequencyMonthly},
+		},
+	})
+	if err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	link, _, err := svc.PreferencesLink(ctx, subscriber.OrgID, subscriber.ID)
+	if err != nil {
+		t.Fatalf("link failed: %v", err)
+	}
+	path := "/preferences/" + link[strings.LastIndex(link, "/")+1:]
+
+	request := func(method, url, body string) *http.Request {
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		return req
+	}
+	decode := func(resp *http.Response) (dto.PreferencesResponse, map[string]dto.SubscriberTypePreferences) {
+		var prefs dto.PreferencesResponse
+		json.NewDecoder(resp.Body).Decode(&prefs)
+		byName := map[string]dto.SubscriberTypePreferences{}
+		for _, st := range prefs.SubscriberTypes {
+			byName[st.Name] = st
+		}
+		return prefs, byName
+	}
+
+	t.Run("GetPreferences - Success", func(t *testing.T) {
+		resp, err := app.Test(request("GET", path, ""), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected 200, got %d", resp.StatusCode)
+		}
+		prefs, types := decode(resp)
+		if prefs.Email == email || !prefs.Subscribed || prefs.Name != "Prefs" {
+			t.Errorf("Expected a masked address, subscribed, got %+v", prefs)
+		}
+		if types["shopper"].Frequency != models.FrequencyWeekly || types["donor"].Frequency != models.FrequencyMonthly {
+			t.Errorf("Expected weekly shopper and monthly donor, got %+v", types)
+		}
+	})
+
+	t.Run("GetPreferences - Unknown Token", func(t *testing.T) {
+		resp, err := app.Test(request("GET", path+"x", ""), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Expected 404, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("UpdatePreferences - Name, Types And Unsubscribe", func(t *testing.T) {
+		body := `{"name": "Prefs Renamed", "subscriber_types": [{"name": "donor", "channel": "sms"}, {"name": "driver"}], "subscribed": false}`
+		resp, err := app.Test(request("PUT", path, body), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected 200, got %d", resp.StatusCode)
+		}
+		prefs, types := decode(resp)
+		if prefs.Name != "Prefs Renamed" || prefs.Subscribed || prefs.Status != models.SubscriberStatusUnsubscribed {
+			t.Errorf("Expected the new name and an unsubscribe, got %+v", prefs)
+		}
+		if _, ok := types["shopper"]; ok || len(types) != 2 {
+			t.Errorf("Expected shopper to be replaced by driver, got %+v", types)
+		}
+		if types["donor"].Frequency != models.FrequencyMonthly || types["donor"].Channel != models.ChannelSMS {
+			t.Errorf("Expected donor to stay monthly, now by sms, got %+v", types["donor"])
+		}
+	})
+
+	t.Run("UpdatePreferences - Subscribe Again", func(t *testing.T) {
+		resp, err := app.Test(request("PUT", path, `{"subscribed": true}`), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		prefs, types := decode(resp)
+		if resp.StatusCode != http.StatusOK || prefs.Status != models.SubscriberStatusActive || len(types) != 2 {
+			t.Errorf("Expected 200, active with the types untouched, got %d and %+v", resp.StatusCode, prefs)
+		}
+	})
+
+	t.Run("UpdatePreferences - Unknown Type", func(t *testing.T) {
+		resp, err := app.Test(request("PUT", path, `{"subscriber_types": [{"name": "astronaut"}]}`), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusUnprocessableEntity {
+			t.Errorf("Expected 422, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("UpdatePreferences - Invalid Frequency", func(t *testing.T) {
+		resp, err := app.Test(request("PUT", path, `{"subscriber_types": [{"name": "donor", "frequency": "hourly"}]}`), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected 400, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("RequestPreferencesLink - Emails Known Addresses Only", func(t *testing.T) {
+		for _, address := range []string{"nobody-" + email, email} {
+			resp, err := app.Test(request("POST", "/preferences", fmt.Sprintf(`{"email": "%s"}`, address)), -1)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			if resp.StatusCode != http.StatusAccepted {
+				t.Errorf("Expected 202 for %s, got %d", address, resp.StatusCode)
+			}
+		}
+		if len(emailed) != 1 {
+			t.Fatalf("Expected one preferences email, got %v", emailed)
+		}
+		resp, _ := app.Test(request("GET", "/preferences/"+emailed[0][strings.LastIndex(emailed[0], "/")+1:], ""), -1)
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected the emailed link to work, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("RequestPreferencesLink - Invalid Email", func(t *testing.T) {
+		resp, err := app.Test(request("POST", "/preferences", `{"email": "nope"}`), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected 400, got %d", resp.StatusCode)
+		}
+	})
+}
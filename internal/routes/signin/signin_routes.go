@@ -1,7 +1,11 @@
 package signin
 
 import (
+	"fiber-gorm-api/internal/db"
 	"fiber-gorm-api/internal/handlers"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/oauth"
+	"fiber-gorm-api/internal/passkey"
 	redisclient "fiber-gorm-api/internal/redis"
 
 	"github.com/gofiber/fiber/v2"
@@ -10,17 +14,51 @@ import (
 
 // RegisterRoutes sets up sign in routes under /signin
 func RegisterRoutes(app *fiber.App) {
-	signinGroup := app.Group("/signin", cors.New(cors.Config{
-		AllowOrigins: "https://signin.mylocal.ing",
-		AllowHeaders: "Origin, Content-Type, Accept",
+	signinGroup := app.Group("/signin", middleware.CORS("signin", "https://signin.mylocal.ing", cors.Config{
+		AllowHeaders: "Origin, Content-Type, Accept, Authorization, X-Device-Token",
 	}))
 
+	// Keys verifying our JWTs, for services that accept them without sharing a secret
+	app.Get("/.well-known/jwks.json", handlers.JWKS)
+
 	// Initialize Redis
 	redisclient.InitRedis("session")
 
+	// Initialize DB (admin organizations, passkeys, trusted devices, queued emails)
+	database := db.Connect(false)
+
+	// Codes and counters in Redis, emails queued for the outbox worker, sessions in Redis
+	signin := handlers.NewSigninHandler(database, redisclient.Current,
+		handlers.NewQueuedEmailSender(database), handlers.NewSessionTokenIssuer(database), handlers.SystemClock)
+
 	// Request a code by email
-	signinGroup.Post("/request", handlers.RequestSignIn)
+	signinGroup.Post("/request", signin.RequestSignIn)
 
 	// Verify the code to get a JWT
-	signinGroup.Post("/verify", handlers.VerifySignIn)
+	signinGroup.Post("/verify", signin.VerifySignIn)
+
+	// Open an emailed sign-in link: redirects with the JWT
+	signinGroup.Get("/magic/:token", signin.MagicLinkSignIn)
+
+	// The email last used on this browser, in a signed cookie, for the sign-in form to prefill
+	signinGroup.Get("/remembered-email", handlers.RememberedEmail)
+	signinGroup.Put("/remembered-email", handlers.RememberEmail)
+	signinGroup.Delete("/remembered-email", handlers.ForgetEmail)
+
+	// Passkeys (WebAuthn)
+	passkey.InitWebAuthn()
+	webauthnGroup := signinGroup.Group("/webauthn")
+
+	// Registering a passkey requires being signed in already (e.g. with an email code)
+	webauthnGroup.Post("/register/begin", middleware.RequireJWT, handlers.WebAuthnRegisterBegin(database))
+	webauthnGroup.Post("/register/finish", middleware.RequireJWT, handlers.WebAuthnRegisterFinish(database))
+
+	// Sign in with a registered passkey instead of an email code
+	webauthnGroup.Post("/login/begin", handlers.WebAuthnLoginBegin(database))
+	webauthnGroup.Post("/login/finish", handlers.WebAuthnLoginFinish(database))
+
+	// Sign in with an external identity provider (Google, GitHub)
+	oauth.InitProviders()
+	signinGroup.Get("/oauth/:provider/start", handlers.OAuthStart)
+	signinGroup.Get("/oauth/:provider/callback", handlers.OAuthCallback(database))
 }
@@ -1,9 +1,18 @@
 package signin
 
 import (
+	"context"
+	"encoding/base64"
 	"encoding/json"
+	"fiber-gorm-api/internal/db"
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/handlers"
+	"fiber-gorm-api/internal/mailqueue"
+	"fiber-gorm-api/internal/models"
 	redisclient "fiber-gorm-api/internal/redis"
+	"fiber-gorm-api/internal/resilience"
 	sendgridservice "fiber-gorm-api/internal/services"
+	"fiber-gorm-api/internal/sms"
 	"fmt"
 	"log"
 	"net/http"
@@ -18,14 +27,27 @@ import (
 // We'll override the actual SendGrid call so the tests won't fail
 // if there's no real API key.
 func init() {
-	sendgridservice.SendCodeEmailFunc = func(toEmail, code string) error {
+	sendgridservice.SendCodeEmailFunc = func(toEmail, locale, code string, _ ...sendgridservice.Attachment) error {
 		log.Printf("[TEST-MOCK] Skipping real SendGrid call => code: %s, email: %s\n", code, toEmail)
 		return nil
 	}
+	sms.Sender = texts
 }
 
+// textRecorder stands in for Twilio and keeps the text messages sent
+type textRecorder struct {
+	sent map[string]string
+}
+
+func (r *textRecorder) Send(ctx context.Context, to, body string) error {
+	r.sent[to] = body
+	return nil
+}
+
+var texts = &textRecorder{sent: map[string]string{}}
+
 // Setup function:
-//   - Connects to real Redis from environment
+//   - Connects to Redis from environment (in-process without REDIS_HOST)
 //   - Optionally flushes data
 //   - Returns a fiber.App with sign-in routes
 func setupSignInTestApp(t *testing.T) *fiber.App {
@@ -74,12 +96,52 @@ func TestSignInRequest_Valid(t *testing.T) {
 
 	// (Optional) We could confirm that a code now exists in Redis
 	codeKey := "signin_code:request_valid@example.com"
-	storedCode, err := redisclient.GetValue(codeKey)
+	storedCode, err := redisclient.GetValue(redisclient.Ctx, codeKey)
 	if err != nil || storedCode == "" {
 		t.Errorf("Expected a code to be stored in Redis. Key: %s, got: %q", codeKey, storedCode)
 	}
 }
 
+func TestSignInRequest_CodeSettings(t *testing.T) {
+	app := setupSignInTestApp(t)
+	request := func(email string) string {
+		t.Helper()
+		req := httptest.NewRequest("POST", "/signin/request", strings.NewReader(`{"email": "`+email+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		if resp, err := app.Test(req, -1); err != nil || resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected 200, got %v (%v)", resp, err)
+		}
+		code, _ := redisclient.GetValue(redisclient.Ctx, "signin_code:"+email)
+		return code
+	}
+
+	t.Setenv("SIGNIN_CODE_LENGTH", "8")
+	t.Setenv("SIGNIN_CODE_ALPHABET", "alphanumeric")
+	t.Setenv("SIGNIN_CODE_TTL_MINUTES", "2")
+	code := request("code_settings@example.com")
+	if len(code) != 8 || strings.Trim(code, "0123456789ABCDEFGHJKLMNPQRSTUVWXYZ") != "" {
+		t.Errorf("Expected 8 digits and uppercase letters, got %q", code)
+	}
+	if ttl, _ := redisclient.TTL(redisclient.Ctx, "signin_code:code_settings@example.com"); ttl <= time.Minute || ttl > 2*time.Minute {
+		t.Errorf("Expected the code to expire in 2 minutes, got %s", ttl)
+	}
+
+	// letters may be typed in lowercase
+	body := fmt.Sprintf(`{"email": "code_settings@example.com", "code": " %s "}`, strings.ToLower(code))
+	req := httptest.NewRequest("POST", "/signin/verify", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if resp, err := app.Test(req, -1); err != nil || resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected the lowercase code accepted, got %v (%v)", resp, err)
+	}
+
+	// out of range lengths fall back to 6 digits
+	t.Setenv("SIGNIN_CODE_LENGTH", "12")
+	t.Setenv("SIGNIN_CODE_ALPHABET", "")
+	if code := request("code_settings_default@example.com"); len(code) != 6 || strings.Trim(code, "0123456789") != "" {
+		t.Errorf("Expected 6 digits, got %q", code)
+	}
+}
+
 func TestSignInRequest_RepeatedRequest(t *testing.T) {
 	app := setupSignInTestApp(t)
 
@@ -96,7 +158,7 @@ func TestSignInRequest_RepeatedRequest(t *testing.T) {
 		t.Errorf("Expected 200, got %d (resp1)", resp1.StatusCode)
 	}
 	codeKey := "signin_code:repeated@example.com"
-	firstCode, _ := redisclient.GetValue(codeKey)
+	firstCode, _ := redisclient.GetValue(redisclient.Ctx, codeKey)
 	if firstCode == "" {
 		t.Errorf("Expected code in redis after first request")
 	}
@@ -114,7 +176,7 @@ func TestSignInRequest_RepeatedRequest(t *testing.T) {
 	}
 
 	// 3) Check if the stored code is overwritten or not
-	secondCode, _ := redisclient.GetValue(codeKey)
+	secondCode, _ := redisclient.GetValue(redisclient.Ctx, codeKey)
 	if secondCode == "" {
 		t.Errorf("Expected a code in redis after second request")
 	}
@@ -125,6 +187,403 @@ func TestSignInRequest_RepeatedRequest(t *testing.T) {
 	}
 }
 
+func TestSignInRequest_SMS(t *testing.T) {
+	app := setupSignInTestApp(t)
+
+	request := func(body string) (int, map[string]string) {
+		req := httptest.NewRequest("POST", "/signin/request", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		var result map[string]string
+		_ = json.NewDecoder(resp.Body).Decode(&result)
+		return resp.StatusCode, result
+	}
+
+	// 1) a phone alone is texted, normalized to E.164
+	status, _ := request(`{"phone": "+1 (555) 010-2030"}`)
+	if status != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", status)
+	}
+	code, _ := redisclient.GetValue(redisclient.Ctx, "signin_code:+15550102030")
+	if code == "" || texts.sent["+15550102030"] != "Your sign-in code is: "+code {
+		t.Errorf("Expected the stored code %q to be texted, got %q", code, texts.sent["+15550102030"])
+	}
+
+	// 2) the phone signs in with that code
+	body := fmt.Sprintf(`{"phone":"+15550102030","code":"%s"}`, code)
+	req := httptest.NewRequest("POST", "/signin/verify", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("Request error: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected 200 verifying the texted code, got %d", resp.StatusCode)
+	}
+
+	// 3) invalid numbers and channels are refused
+	if status, result := request(`{"phone": "555-0102"}`); status != http.StatusBadRequest || result["code"] != "invalid_phone" {
+		t.Errorf("Expected 400 invalid_phone, got %d %v", status, result)
+	}
+	if status, result := request(`{"email": "a@example.com", "channel": "pigeon"}`); status != http.StatusBadRequest || result["code"] != "invalid_channel" {
+		t.Errorf("Expected 400 invalid_channel, got %d %v", status, result)
+	}
+	if status, _ := request(`{"email": "a@example.com", "channel": "sms"}`); status != http.StatusBadRequest {
+		t.Errorf("Expected 400 for sms without a phone, got %d", status)
+	}
+
+	// 4) texts are limited separately from emails: 3 per hour by default, one sent above
+	request(`{"phone": "+15550102030"}`)
+	request(`{"phone": "+15550102030"}`)
+	if status, result := request(`{"phone": "+15550102030"}`); status != http.StatusTooManyRequests || result["code"] != "too_many_requests" {
+		t.Errorf("Expected 429 on the fourth text of the hour, got %d %v", status, result)
+	}
+	if status, _ := request(`{"email": "sms-limit@example.com"}`); status != http.StatusOK {
+		t.Errorf("Expected emails to keep their own limit, got %d", status)
+	}
+}
+
+// queuedEmail returns the last sign-in email queued for to
+func queuedEmail(t *testing.T, to string) mailqueue.Email {
+	t.Helper()
+	var events []models.OutboxEvent
+	db.Connect(false).Where("topic = ?", mailqueue.TopicSend).Order("id DESC").Limit(50).Find(&events)
+	for _, event := range events {
+		var email mailqueue.Email
+		if err := json.Unmarshal([]byte(event.Payload), &email); err == nil && email.To == to {
+			return email
+		}
+	}
+	t.Fatalf("Expected an email queued for %s", to)
+	return mailqueue.Email{}
+}
+
+// emailRecorder stands in for the email queue and keeps the emails sent
+type emailRecorder struct {
+	sent []mailqueue.Email
+}
+
+func (r *emailRecorder) Send(ctx context.Context, email mailqueue.Email) error {
+	r.sent = append(r.sent, email)
+	return nil
+}
+
+// namedTokens issues tokens naming who signed in, without sessions
+type namedTokens struct{}
+
+func (namedTokens) Issue(ctx context.Context, email, ip, userAgent string) (string, error) {
+	return "token-for-" + email, nil
+}
+
+// fixedClock is always at the same time
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time {
+	return time.Time(c)
+}
+
+func TestSigninHandler_InjectedDependencies(t *testing.T) {
+	setupSignInTestApp(t)
+	emails := &emailRecorder{}
+	now := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	signin := handlers.NewSigninHandler(db.Connect(false), redisclient.Current, emails, namedTokens{}, fixedClock(now))
+	app := fiber.New()
+	app.Post("/signin/request", signin.RequestSignIn)
+	app.Post("/signin/verify", signin.VerifySignIn)
+	post := func(url, body string) *http.Response {
+		t.Helper()
+		req := httptest.NewRequest("POST", url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		return resp
+	}
+
+	if resp := post("/signin/request", `{"email": "injected@example.com"}`); resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", resp.StatusCode)
+	}
+	if len(emails.sent) != 1 || emails.sent[0].To != "injected@example.com" || !emails.sent[0].ExpiresAt.Equal(now.Add(5*time.Minute)) {
+		t.Fatalf("Expected the code sent, expiring 5 minutes after the clock, got %+v", emails.sent)
+	}
+
+	resp := post("/signin/verify", fmt.Sprintf(`{"email": "injected@example.com", "code": "%s"}`, emails.sent[0].Secret))
+	var result dto.TokenResponse
+	_ = json.NewDecoder(resp.Body).Decode(&result)
+	if resp.StatusCode != http.StatusOK || result.Token != "token-for-injected@example.com" {
+		t.Errorf("Expected the token of the issuer, got %d %+v", resp.StatusCode, result)
+	}
+}
+
+func TestSignInRequest_ProviderDown(t *testing.T) {
+	app := setupSignInTestApp(t)
+	original := sendgridservice.SendCodeEmailFunc
+	t.Cleanup(func() { sendgridservice.SendCodeEmailFunc = original })
+	called := false
+	sendgridservice.SendCodeEmailFunc = func(string, string, string, ...sendgridservice.Attachment) error {
+		called = true
+		return &resilience.UnavailableError{Name: "sendgrid", RetryAfter: 7 * time.Second}
+	}
+
+	req := httptest.NewRequest("POST", "/signin/request", strings.NewReader(`{"email": "provider-down@example.com"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200 without waiting on the provider, got %d", resp.StatusCode)
+	}
+	if called {
+		t.Error("Expected the email to be sent in the background, not by the request")
+	}
+	code, _ := redisclient.GetValue(redisclient.Ctx, "signin_code:provider-down@example.com")
+	email := queuedEmail(t, "provider-down@example.com")
+	if email.Kind != mailqueue.KindSignInCode || email.Secret != code || code == "" {
+		t.Errorf("Expected the stored code queued, got %+v (stored %q)", email, code)
+	}
+	if !email.ExpiresAt.After(time.Now()) {
+		t.Errorf("Expected the email to expire with the code, got %s", email.ExpiresAt)
+	}
+}
+
+func TestSignInRequest_KnownRecipientsOnly(t *testing.T) {
+	app := setupSignInTestApp(t)
+	t.Setenv("SIGNIN_CODE_RECIPIENTS", "known")
+	t.Setenv("SIGNIN_RESPONSE_FLOOR_MS", "100")
+	known := models.Subscriber{OrgID: models.DefaultOrgID, Email: fmt.Sprintf("known-%d@example.com", time.Now().UnixNano()), Name: "Known"}
+	conn := db.Connect(false)
+	if err := conn.Create(&known).Error; err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Unscoped().Delete(&known) })
+
+	request := func(email string) (int, string, time.Duration) {
+		t.Helper()
+		start := time.Now()
+		req := httptest.NewRequest("POST", "/signin/request", strings.NewReader(`{"email": "`+email+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		var result map[string]string
+		_ = json.NewDecoder(resp.Body).Decode(&result)
+		return resp.StatusCode, result["message"], time.Since(start)
+	}
+
+	knownStatus, knownMessage, took := request(strings.ToUpper(known.Email[:1]) + known.Email[1:])
+	if took < 100*time.Millisecond {
+		t.Errorf("Expected the answer after the floor, took %s", took)
+	}
+	if code, _ := redisclient.GetValue(redisclient.Ctx, "signin_code:"+strings.ToUpper(known.Email[:1])+known.Email[1:]); code == "" {
+		t.Error("Expected a code for the known address")
+	}
+
+	unknownStatus, unknownMessage, took := request("nobody-known@example.com")
+	if unknownStatus != knownStatus || unknownMessage != knownMessage {
+		t.Errorf("Expected the same answer for an unknown address, got %d %q and %d %q", unknownStatus, unknownMessage, knownStatus, knownMessage)
+	}
+	if took < 100*time.Millisecond {
+		t.Errorf("Expected the answer after the floor, took %s", took)
+	}
+	if code, _ := redisclient.GetValue(redisclient.Ctx, "signin_code:nobody-known@example.com"); code != "" {
+		t.Error("Expected no code for an unknown address")
+	}
+}
+
+func TestSignInRequest_MagicLink(t *testing.T) {
+	app := setupSignInTestApp(t)
+	t.Setenv("SIGNIN_MAGIC_LINK_REDIRECT_URL", "https://signin.example.com/done")
+
+	// 1) method link emails a link instead of a code
+	req := httptest.NewRequest("POST", "/signin/request", strings.NewReader(`{"email": "magic@example.com", "method": "link"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", resp.StatusCode)
+	}
+	link := queuedEmail(t, "magic@example.com").Secret
+	token := link[strings.LastIndex(link, "/")+1:]
+	if !strings.HasPrefix(link, "https://api.mylocal.ing/signin/magic/") || token == "" {
+		t.Fatalf("Expected a magic link, got %q", link)
+	}
+	if code, _ := redisclient.GetValue(redisclient.Ctx, "signin_code:magic@example.com"); code != "" {
+		t.Errorf("Expected no code to be stored for a link, got %q", code)
+	}
+
+	// 2) opening it redirects with a JWT, once
+	open := func() string {
+		resp, err := app.Test(httptest.NewRequest("GET", "/signin/magic/"+token, nil), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusFound {
+			t.Fatalf("Expected 302, got %d", resp.StatusCode)
+		}
+		return resp.Header.Get("Location")
+	}
+	if location := open(); !strings.HasPrefix(location, "https://signin.example.com/done#token=") {
+		t.Errorf("Expected a redirect with the token, got %q", location)
+	}
+	if location := open(); location != "https://signin.example.com/done#error=invalid_link" {
+		t.Errorf("Expected a used link to be refused, got %q", location)
+	}
+
+	// 3) links aren't texted
+	req = httptest.NewRequest("POST", "/signin/request", strings.NewReader(`{"phone": "+15550102030", "method": "link"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err = app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	var result map[string]string
+	_ = json.NewDecoder(resp.Body).Decode(&result)
+	if resp.StatusCode != http.StatusBadRequest || result["code"] != "invalid_method" {
+		t.Errorf("Expected 400 invalid_method, got %d %v", resp.StatusCode, result)
+	}
+}
+
+func TestSignIn_TrustedDevice(t *testing.T) {
+	app := setupSignInTestApp(t)
+
+	email := "trusted@example.com"
+	if err := redisclient.SetValue(redisclient.Ctx, "signin_code:"+email, "246810", 5*time.Minute); err != nil {
+		t.Fatalf("Failed to set code: %v", err)
+	}
+
+	// 1) verifying with trust_device returns a device token and sets it as a cookie
+	body := fmt.Sprintf(`{"email":"%s","code":"246810","trust_device":true}`, email)
+	req := httptest.NewRequest("POST", "/signin/verify", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("Request error: %v", err)
+	}
+	var verified dto.TokenResponse
+	_ = json.NewDecoder(resp.Body).Decode(&verified)
+	if resp.StatusCode != http.StatusOK || verified.Token == "" || verified.DeviceToken == "" {
+		t.Fatalf("Expected a session and a device token, got %d %+v", resp.StatusCode, verified)
+	}
+	var cookie *http.Cookie
+	for _, c := range resp.Cookies() {
+		if c.Name == "mylo_trusted_device" {
+			cookie = c
+		}
+	}
+	if cookie == nil || cookie.Value != verified.DeviceToken || !cookie.HttpOnly {
+		t.Fatalf("Expected an HttpOnly device cookie, got %+v", cookie)
+	}
+
+	request := func(email string, device func(*http.Request)) dto.TokenResponse {
+		req := httptest.NewRequest("POST", "/signin/request", strings.NewReader(fmt.Sprintf(`{"email":"%s"}`, email)))
+		req.Header.Set("Content-Type", "application/json")
+		device(req)
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Request error: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected 200, got %d", resp.StatusCode)
+		}
+		var result dto.TokenResponse
+		_ = json.NewDecoder(resp.Body).Decode(&result)
+		return result
+	}
+
+	// 2) the trusted device gets a session without a code, by cookie or header
+	_ = redisclient.DeleteKey(redisclient.Ctx, "signin_code:"+email)
+	if result := request(email, func(r *http.Request) { r.AddCookie(cookie) }); result.Token == "" {
+		t.Errorf("Expected a session for the trusted device")
+	}
+	if result := request(email, func(r *http.Request) { r.Header.Set("X-Device-Token", verified.DeviceToken) }); result.Token == "" {
+		t.Errorf("Expected a session for the trusted device token")
+	}
+	if code, _ := redisclient.GetValue(redisclient.Ctx, "signin_code:"+email); code != "" {
+		t.Errorf("Expected no code to be sent to a trusted device")
+	}
+
+	// 3) the device is only trusted by the address that trusted it
+	if result := request("someone-else@example.com", func(r *http.Request) { r.AddCookie(cookie) }); result.Token != "" {
+		t.Errorf("Expected another address to get a code, not a session")
+	}
+}
+
+func TestRememberedEmail(t *testing.T) {
+	app := setupSignInTestApp(t)
+	t.Setenv("SIGNIN_REMEMBER_EMAIL_KEY", "first-key")
+	call := func(method, cookie, body string) *http.Response {
+		t.Helper()
+		req := httptest.NewRequest(method, "/signin/remembered-email", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if cookie != "" {
+			req.Header.Set("Cookie", "mylo_remembered_email="+cookie)
+		}
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		return resp
+	}
+	cookieOf := func(resp *http.Response) string {
+		for _, c := range resp.Cookies() {
+			if c.Name == "mylo_remembered_email" {
+				return c.Value
+			}
+		}
+		return ""
+	}
+	remembered := func(resp *http.Response) string {
+		var got dto.RememberedEmailResponse
+		_ = json.NewDecoder(resp.Body).Decode(&got)
+		return got.Email
+	}
+
+	if resp := call("PUT", "", `{"email": "not an email"}`); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid email, got %d", resp.StatusCode)
+	}
+	cookie := cookieOf(call("PUT", "", `{"email": "remember@example.com"}`))
+	if cookie == "" || !strings.Contains(cookie, ".") {
+		t.Fatalf("Expected a signed cookie, got %q", cookie)
+	}
+
+	if resp := call("GET", cookie, ""); resp.StatusCode != http.StatusOK || remembered(resp) != "remember@example.com" {
+		t.Errorf("Expected the remembered email, got %d", resp.StatusCode)
+	}
+	encoded, _, _ := strings.Cut(cookie, ".")
+	forged := base64.RawURLEncoding.EncodeToString([]byte("forged@example.com")) + cookie[len(encoded):]
+	if resp := call("GET", forged, ""); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected 404 for a forged cookie, got %d", resp.StatusCode)
+	}
+
+	// rotated: the old key is still accepted, and its cookie moved to the new one
+	t.Setenv("SIGNIN_REMEMBER_EMAIL_KEY", "second-key")
+	t.Setenv("SIGNIN_REMEMBER_EMAIL_PREVIOUS_KEYS", "first-key")
+	resp := call("GET", cookie, "")
+	resigned := cookieOf(resp)
+	if resp.StatusCode != http.StatusOK || resigned == "" || resigned == cookie {
+		t.Fatalf("Expected the cookie re-signed with the new key, got %d %q", resp.StatusCode, resigned)
+	}
+	t.Setenv("SIGNIN_REMEMBER_EMAIL_PREVIOUS_KEYS", "")
+	if resp := call("GET", cookie, ""); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected the old cookie refused once its key is dropped, got %d", resp.StatusCode)
+	}
+	if resp := call("GET", resigned, ""); resp.StatusCode != http.StatusOK || remembered(resp) != "remember@example.com" {
+		t.Errorf("Expected the re-signed cookie accepted, got %d", resp.StatusCode)
+	}
+
+	if resp := call("DELETE", resigned, ""); resp.StatusCode != http.StatusNoContent || !strings.Contains(resp.Header.Get("Set-Cookie"), "1970") {
+		t.Errorf("Expected the cookie expired, got %d %q", resp.StatusCode, resp.Header.Get("Set-Cookie"))
+	}
+}
+
 func TestSignInVerify_NoCodeInRedis(t *testing.T) {
 	app := setupSignInTestApp(t)
 
@@ -147,7 +606,7 @@ func TestSignInVerify_InvalidCode(t *testing.T) {
 	// 1) store a code
 	email := "invalidcode@example.com"
 	codeKey := fmt.Sprintf("signin_code:%s", email)
-	if err := redisclient.SetValue(codeKey, "999999", 5*time.Minute); err != nil {
+	if err := redisclient.SetValue(redisclient.Ctx, codeKey, "999999", 5*time.Minute); err != nil {
 		t.Fatalf("Failed to set code in redis: %v", err)
 	}
 
@@ -173,7 +632,7 @@ func TestSignInVerify_Valid(t *testing.T) {
 	codeKey := "signin_code:" + email
 
 	// 1) store the code in redis
-	if err := redisclient.SetValue(codeKey, code, 5*time.Minute); err != nil {
+	if err := redisclient.SetValue(redisclient.Ctx, codeKey, code, 5*time.Minute); err != nil {
 		t.Fatalf("Failed to set code in redis: %v", err)
 	}
 
@@ -199,7 +658,7 @@ func TestSignInVerify_Valid(t *testing.T) {
 	}
 
 	// 4) confirm the code was removed (single-use)
-	val, _ := redisclient.GetValue(codeKey)
+	val, _ := redisclient.GetValue(redisclient.Ctx, codeKey)
 	if val != "" {
 		t.Errorf("Expected code to be removed after successful verify, but got '%s'", val)
 	}
@@ -217,7 +676,7 @@ func TestSignInVerify_RepeatedUse(t *testing.T) {
 	email := "oneuse@example.com"
 	code := "987654"
 	key := "signin_code:" + email
-	if err := redisclient.SetValue(key, code, 5*time.Minute); err != nil {
+	if err := redisclient.SetValue(redisclient.Ctx, key, code, 5*time.Minute); err != nil {
 		t.Fatalf("Failed to set code: %v", err)
 	}
 
@@ -260,3 +719,103 @@ func TestSignInVerify_EmptyBody(t *testing.T) {
 		t.Errorf("Expected 400 for missing email/code, got %d", resp.StatusCode)
 	}
 }
+
+func TestSignInVerify_LockoutAfterRepeatedFailures(t *testing.T) {
+	app := setupSignInTestApp(t)
+
+	email := "bruteforce@example.com"
+	if err := redisclient.SetValue(redisclient.Ctx, "signin_code:"+email, "111111", 5*time.Minute); err != nil {
+		t.Fatalf("Failed to set code: %v", err)
+	}
+
+	verify := func(code string) int {
+		body := fmt.Sprintf(`{"email":"%s","code":"%s"}`, email, code)
+		req := httptest.NewRequest("POST", "/signin/verify", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Request error: %v", err)
+		}
+		return resp.StatusCode
+	}
+
+	// 1) the first four wrong guesses are plain 401s
+	for i := 0; i < 4; i++ {
+		if status := verify("000000"); status != http.StatusUnauthorized {
+			t.Fatalf("Expected 401 on wrong guess %d, got %d", i+1, status)
+		}
+	}
+
+	// 2) the fifth wrong guess invalidates the code
+	if status := verify("000000"); status != http.StatusTooManyRequests {
+		t.Errorf("Expected 429 on the fifth wrong guess, got %d", status)
+	}
+	if val, _ := redisclient.GetValue(redisclient.Ctx, "signin_code:"+email); val != "" {
+		t.Errorf("Expected code to be invalidated after too many attempts, got '%s'", val)
+	}
+
+	// 3) even the right code is refused while locked
+	if status := verify("111111"); status != http.StatusLocked {
+		t.Errorf("Expected 423 while locked, got %d", status)
+	}
+}
+
+func TestWebAuthnLoginBegin_NoPasskey(t *testing.T) {
+	app := setupSignInTestApp(t)
+
+	body := `{"email":"no-passkey@example.com"}`
+	req := httptest.NewRequest("POST", "/signin/webauthn/login/begin", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("Request error: %v", err)
+	}
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400 when no passkey is registered, got %d", resp.StatusCode)
+	}
+}
+
+func TestWebAuthnRegisterBegin_RequiresToken(t *testing.T) {
+	app := setupSignInTestApp(t)
+
+	req := httptest.NewRequest("POST", "/signin/webauthn/register/begin", nil)
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Request error: %v", err)
+	}
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without a token, got %d", resp.StatusCode)
+	}
+}
+
+func TestOAuthStart_UnknownProvider(t *testing.T) {
+	app := setupSignInTestApp(t)
+
+	req := httptest.NewRequest("GET", "/signin/oauth/myspace/start", nil)
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Request error: %v", err)
+	}
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown provider, got %d", resp.StatusCode)
+	}
+}
+
+func TestJWKS(t *testing.T) {
+	app := setupSignInTestApp(t)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/.well-known/jwks.json", nil), -1)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", resp.StatusCode)
+	}
+
+	// HS256 by default, whose secret isn't published
+	var jwks dto.JWKSResponse
+	if err := json.NewDecoder(resp.Body).Decode(&jwks); err != nil || jwks.Keys == nil {
+		t.Errorf("Expected a key set, got %+v (%v)", jwks, err)
+	}
+}
@@ -1,25 +1,60 @@
 package signup
 
 import (
+	"strings"
+
 	"fiber-gorm-api/internal/db"
 	"fiber-gorm-api/internal/handlers"
+	"fiber-gorm-api/internal/middleware"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 )
 
-// RegisterRoutes registers the signup group route with create-only for subscribers.
+// signupOrigins are the default CORS origins of the signup routes
+const signupOrigins = "https://signup.mylocal.ing"
+
+// RegisterRoutes registers the signup group routes: create-only for subscribers, plus the double opt-in confirmation,
+// and the signup forms with their embeddable widget, which partner sites may use too.
 func RegisterRoutes(app *fiber.App) {
-	signupGroup := app.Group("/signup", cors.New(cors.Config{
-		AllowOrigins: "https://signup.mylocal.ing",
-		AllowHeaders: "Origin, Content-Type, Accept",
-	}))
+	corsConfig := cors.Config{
+		AllowHeaders: "Origin, Content-Type, Accept, X-Org, X-Captcha-Token",
+	}
+	partnerCORS := middleware.PartnerCORS("signup", signupOrigins, corsConfig)
+
+	// the embeddable routes answer CORS with the partner sites below
+	corsConfig.Next = func(c *fiber.Ctx) bool {
+		return strings.HasPrefix(c.Path(), "/signup/forms/") || strings.HasPrefix(c.Path(), "/signup/widget/")
+	}
+	signupGroup := app.Group("/signup", middleware.CORS("signup", signupOrigins, corsConfig))
 
 	subs := signupGroup.Group("/subscribers")
 
 	// Initialize DB
 	database := db.Connect(false)
 
-	// Create only
-	subs.Post("/", handlers.CreateSubscriber(database))
+	// Create only, in the organization named by X-Org / ?org= (default organization otherwise).
+	// Honeypot / captcha checks run first so bots never reach the database.
+	subs.Post("/", middleware.RejectBots(), middleware.ResolveOrg(database), handlers.SignupSubscriber(database))
+
+	// Double opt-in: the emailed link posts its token here
+	subs.Post("/confirm", handlers.ConfirmSubscriber(database))
+
+	// Forms defined under /admin/signup-forms. The form is read before the bot checks, as it
+	// decides whether a captcha is required.
+	forms := signupGroup.Group("/forms", partnerCORS)
+	forms.Post("/:slug/submit",
+		middleware.ResolveOrg(database),
+		handlers.LoadSignupForm(database),
+		middleware.RejectBotsWhen(handlers.SignupFormCaptcha),
+		handlers.SubmitSignupForm(database),
+	)
+
+	// The widget partner sites embed to show a form, framed or as JSON(P) config
+	widget := signupGroup.Group("/widget", partnerCORS)
+	widget.Get("/:slug",
+		middleware.ResolveOrg(database),
+		handlers.LoadSignupForm(database),
+		handlers.GetSignupWidget(middleware.EmbedOrigins("signup", signupOrigins)),
+	)
 }
@@ -2,15 +2,28 @@ package signup
 
 import (
 	"encoding/json"
+	"fiber-gorm-api/internal/db"
+	"fiber-gorm-api/internal/dto"
+	"fiber-gorm-api/internal/middleware"
 	"fiber-gorm-api/internal/models"
+	redisclient "fiber-gorm-api/internal/redis"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 )
 
+func TestMain(m *testing.M) {
+	redisclient.InitRedis("session") // in-process unless REDIS_HOST is set
+	os.Exit(m.Run())
+}
+
 func TestSignupSubscriberRoute(t *testing.T) {
 	app := fiber.New()
 	RegisterRoutes(app)
@@ -78,4 +91,385 @@ func TestSignupSubscriberRoute(t *testing.T) {
 			t.Errorf("Expected 1 subscriber_type, got %d", len(created.SubscriberTypes))
 		}
 	})
+
+	t.Run("CreateSubscriber signup - locale from Accept-Language", func(t *testing.T) {
+		localized := fiber.New()
+		localized.Use(middleware.Locale())
+		RegisterRoutes(localized)
+
+		payload := `{"email": "signup-fr@example.com", "name": "Signup FR"}`
+		req := httptest.NewRequest("POST", "/signup/subscribers", strings.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept-Language", "fr-CA,fr;q=0.9,en;q=0.8")
+		resp, err := localized.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("Expected 201, got %d", resp.StatusCode)
+		}
+		var created models.Subscriber
+		json.NewDecoder(resp.Body).Decode(&created)
+		if created.Locale != "fr" {
+			t.Errorf("Expected locale fr, got %q", created.Locale)
+		}
+
+		payload = `{"email": "signup-xx@example.com", "name": "Signup XX", "locale": "xx"}`
+		req = httptest.NewRequest("POST", "/signup/subscribers", strings.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept-Language", "es")
+		resp, err = localized.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		var body map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&body)
+		if resp.StatusCode != 400 || body["code"] != "invalid_locale" {
+			t.Errorf("Expected 400 invalid_locale, got %d %v", resp.StatusCode, body)
+		}
+		if body["error"] != "el idioma debe ser en, fr o es" {
+			t.Errorf("Expected the error in Spanish, got %v", body["error"])
+		}
+	})
+
+	t.Run("CreateSubscriber signup - disposable email domain", func(t *testing.T) {
+		payload := `{"email": "throwaway@mailinator.com", "name": "Throwaway"}`
+		req := httptest.NewRequest("POST", "/signup/subscribers", strings.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != 400 {
+			t.Errorf("Expected 400 for a disposable domain, got %d", resp.StatusCode)
+		}
+		var body map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&body)
+		if body["code"] != "disposable_email" {
+			t.Errorf("Expected code disposable_email, got %v", body["code"])
+		}
+	})
+
+	t.Run("CreateSubscriber signup - invalid referrer", func(t *testing.T) {
+		payload := `{"email": "signup-utm@example.com", "name": "Signup UTM", "utm_source": "newsletter", "referrer": "not a url"}`
+		req := httptest.NewRequest("POST", "/signup/subscribers", strings.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		var body map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&body)
+		if resp.StatusCode != 400 || body["code"] != "invalid_attribution" {
+			t.Errorf("Expected 400 invalid_attribution, got %d %v", resp.StatusCode, body)
+		}
+	})
+
+	t.Run("CreateSubscriber signup - likely spam is quarantined", func(t *testing.T) {
+		t.Setenv("SPAM_QUARANTINE_SCORE", "40")
+		payload := `{"email": "spam-signup@example.com", "name": "xKqZvBnT"}`
+		req := httptest.NewRequest("POST", "/signup/subscribers", strings.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != 201 {
+			t.Fatalf("Expected 201, got %d", resp.StatusCode)
+		}
+		var body map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&body)
+		if body["status"] != models.SubscriberStatusQuarantined {
+			t.Errorf("Expected a quarantined subscriber, got %v", body["status"])
+		}
+	})
+
+	t.Run("CreateSubscriber signup - unknown organization", func(t *testing.T) {
+		payload := `{"email": "org-signup@example.com", "name": "Org Signup"}`
+		req := httptest.NewRequest("POST", "/signup/subscribers?org=no-such-community", strings.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Expected 404 for unknown organization, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("ConfirmSubscriber - missing token", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/signup/subscribers/confirm", strings.NewReader(`{}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != 400 {
+			t.Errorf("Expected 400 for a missing token, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("ConfirmSubscriber - unknown token", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/signup/subscribers/confirm", strings.NewReader(`{"token": "not-a-real-token"}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Expected 404 for an unknown token, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("CORS preflight - signup origin allowed", func(t *testing.T) {
+		req := httptest.NewRequest("OPTIONS", "/signup/subscribers", nil)
+		req.Header.Set("Origin", "https://signup.mylocal.ing")
+		req.Header.Set("Access-Control-Request-Method", "POST")
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if got := resp.Header.Get("Access-Control-Allow-Origin"); got != "https://signup.mylocal.ing" {
+			t.Errorf("Expected the signup origin to be allowed, got %q", got)
+		}
+	})
+}
+
+func TestSignupBotProtection(t *testing.T) {
+	t.Setenv("SIGNUP_HONEYPOT_FIELD", "website")
+	t.Setenv("CAPTCHA_PROVIDER", "turnstile")
+	t.Setenv("CAPTCHA_SECRET", "test-secret")
+
+	app := fiber.New()
+	RegisterRoutes(app)
+
+	t.Run("CreateSubscriber signup - honeypot filled", func(t *testing.T) {
+		payload := `{"email": "bot@example.com", "name": "Bot", "website": "http://spam.example.com"}`
+		req := httptest.NewRequest("POST", "/signup/subscribers", strings.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != 400 {
+			t.Errorf("Expected 400 for a filled honeypot, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("CreateSubscriber signup - missing captcha token", func(t *testing.T) {
+		payload := `{"email": "human@example.com", "name": "Human", "website": ""}`
+		req := httptest.NewRequest("POST", "/signup/subscribers", strings.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != 400 {
+			t.Errorf("Expected 400 without a captcha token, got %d", resp.StatusCode)
+		}
+		var body map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&body)
+		if body["code"] != "captcha_required" {
+			t.Errorf("Expected code captcha_required, got %v", body["code"])
+		}
+	})
+}
+
+func TestSubmitSignupForm(t *testing.T) {
+	t.Setenv("SIGNUP_HONEYPOT_FIELD", "website")
+	t.Setenv("CAPTCHA_PROVIDER", "turnstile")
+	t.Setenv("CAPTCHA_SECRET", "test-secret")
+
+	app := fiber.New()
+	RegisterRoutes(app)
+
+	// the captcha is off, or every submission would need a real token
+	database := db.Connect(true)
+	stamp := time.Now().UnixNano()
+	form := models.SignupForm{
+		OrgID:           models.DefaultOrgID,
+		Slug:            fmt.Sprintf("market-%d", stamp),
+		Name:            "Farmers market",
+		SubscriberTypes: "shopper,driver",
+		Fields: models.SignupFormFields{
+			{Name: "postcode", Type: models.FieldText, Required: true},
+			{Name: "stalls", Type: models.FieldNumber},
+			{Name: "terms", Type: models.FieldCheckbox, Required: true},
+		},
+		SuccessRedirect: "https://market.example.org/thanks",
+	}
+	if err := database.Create(&form).Error; err != nil {
+		t.Fatalf("failed to create form: %v", err)
+	}
+	// false is a zero value, which Create leaves to the column default
+	database.Model(&form).Update("captcha", false)
+	url := "/signup/forms/" + form.Slug + "/submit"
+	submit := func(contentType, body string) *http.Response {
+		req := httptest.NewRequest("POST", url, strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		return resp
+	}
+
+	t.Run("Unknown form", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/signup/forms/no-such-form/submit", strings.NewReader(`{}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Expected 404, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("Invalid submissions", func(t *testing.T) {
+		for body, code := range map[string]string{
+			`{"email":"a@example.com","name":"Ann","fields":{"terms":true}}`:                                   "missing_field",
+			`{"email":"a@example.com","name":"Ann","fields":{"postcode":"1010","terms":false}}`:                "missing_field",
+			`{"email":"a@example.com","name":"Ann","fields":{"postcode":"1010","terms":true,"age":3}}`:         "unknown_field",
+			`{"email":"a@example.com","name":"Ann","fields":{"postcode":"1010","terms":true,"stalls":"many"}}`: "invalid_field",
+			`{"email":"a@example.com","name":"Ann","website":"http://spam.example.com"}`:                       "bot_detected",
+		} {
+			resp := submit("application/json", body)
+			var got map[string]interface{}
+			json.NewDecoder(resp.Body).Decode(&got)
+			if resp.StatusCode != http.StatusBadRequest || got["code"] != code {
+				t.Errorf("%s: expected 400 %s, got %d %v", body, code, resp.StatusCode, got["code"])
+			}
+		}
+
+		resp := submit("application/json", `{"email":"a@example.com","name":"Ann","subscriber_types":["donor"],"fields":{"postcode":"1010","terms":true}}`)
+		if resp.StatusCode != http.StatusUnprocessableEntity {
+			t.Errorf("Expected 422 for a type the form doesn't offer, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("JSON submission", func(t *testing.T) {
+		email := fmt.Sprintf("form-json-%d@example.com", stamp)
+		resp := submit("application/json", fmt.Sprintf(`{"email":%q,"name":"Ann Example","subscriber_types":["driver"],"fields":{"postcode":" 1010 ","stalls":2,"terms":true}}`, email))
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("Expected 201, got %d", resp.StatusCode)
+		}
+		var body dto.SignupFormSubmitResponse
+		json.NewDecoder(resp.Body).Decode(&body)
+		if body.RedirectURL != form.SuccessRedirect {
+			t.Errorf("Expected the success redirect, got %+v", body)
+		}
+
+		var created models.Subscriber
+		if err := database.Preload("SubscriberTypes").Where("email = ?", email).First(&created).Error; err != nil {
+			t.Fatalf("Expected the subscriber created: %v", err)
+		}
+		if created.Metadata["postcode"] != "1010" || created.Metadata["stalls"] != 2.0 || created.Metadata["terms"] != true {
+			t.Errorf("Expected the custom fields in the metadata, got %v", created.Metadata)
+		}
+		if len(created.SubscriberTypes) != 1 || created.SubscriberTypes[0].Name != "driver" {
+			t.Errorf("Expected the picked subscriber_type, got %+v", created.SubscriberTypes)
+		}
+	})
+
+	t.Run("HTML form post", func(t *testing.T) {
+		email := fmt.Sprintf("form-html-%d@example.com", stamp)
+		resp := submit(fiber.MIMEApplicationForm, "email="+email+"&name=Bob+Example&postcode=2020&terms=on&website=")
+		if resp.StatusCode != http.StatusSeeOther || resp.Header.Get("Location") != form.SuccessRedirect {
+			t.Fatalf("Expected a 303 to the success redirect, got %d %q", resp.StatusCode, resp.Header.Get("Location"))
+		}
+
+		var created models.Subscriber
+		if err := database.Preload("SubscriberTypes").Where("email = ?", email).First(&created).Error; err != nil {
+			t.Fatalf("Expected the subscriber created: %v", err)
+		}
+		if created.Metadata["terms"] != true || len(created.SubscriberTypes) != 2 {
+			t.Errorf("Expected every type of the form and the checkbox checked, got %+v", created)
+		}
+	})
+}
+
+func TestSignupWidget(t *testing.T) {
+	t.Setenv("CORS_SIGNUP_PARTNER_ORIGINS", "https://bakery.example.com")
+	t.Setenv("SIGNUP_HONEYPOT_FIELD", "website")
+
+	app := fiber.New()
+	app.Use(middleware.Locale())
+	RegisterRoutes(app)
+
+	form := models.SignupForm{
+		OrgID:           models.DefaultOrgID,
+		Slug:            fmt.Sprintf("bakery-%d", time.Now().UnixNano()),
+		Name:            "Bakery <news>",
+		SubscriberTypes: "shopper",
+		Fields:          models.SignupFormFields{{Name: "favourite", Label: "Favourite bread", Type: models.FieldSelect, Options: []string{"rye", "sourdough"}}},
+	}
+	if err := db.Connect(true).Create(&form).Error; err != nil {
+		t.Fatalf("failed to create form: %v", err)
+	}
+	get := func(url string) *http.Response {
+		resp, err := app.Test(httptest.NewRequest("GET", url, nil), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		return resp
+	}
+	url := "/signup/widget/" + form.Slug
+
+	t.Run("HTML", func(t *testing.T) {
+		resp := get(url + "?lang=fr")
+		if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") {
+			t.Fatalf("Expected an HTML page, got %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+		}
+		csp := resp.Header.Get("Content-Security-Policy")
+		if !strings.Contains(csp, "frame-ancestors https://signup.mylocal.ing https://bakery.example.com") {
+			t.Errorf("Expected the partner allowed to frame the widget, got %q", csp)
+		}
+		page, _ := io.ReadAll(resp.Body)
+		for _, want := range []string{"Bakery &lt;news&gt;", "Favourite bread", "<option>sourdough</option>", `name="website"`, "S&#39;inscrire", form.Slug} {
+			if !strings.Contains(string(page), want) {
+				t.Errorf("Expected %q in the page", want)
+			}
+		}
+	})
+
+	t.Run("JSON and JSONP", func(t *testing.T) {
+		var config dto.SignupWidgetConfig
+		json.NewDecoder(get(url + "?format=json&org=default").Body).Decode(&config)
+		if !strings.HasSuffix(config.SubmitURL, "/signup/forms/"+form.Slug+"/submit?org=default") || len(config.Fields) != 1 || config.HoneypotField != "website" {
+			t.Errorf("Unexpected config %+v", config)
+		}
+
+		body, _ := io.ReadAll(get(url + "?callback=mylo.render").Body)
+		if !strings.HasPrefix(string(body), "mylo.render({") {
+			t.Errorf("Expected the config wrapped in the callback, got %s", body)
+		}
+		if resp := get(url + "?callback=alert(1)//"); resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected 400 for a callback that isn't an identifier, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("CORS preflight - partner origin", func(t *testing.T) {
+		for path, want := range map[string]string{
+			"/signup/forms/" + form.Slug + "/submit": "https://bakery.example.com",
+			"/signup/subscribers":                    "",
+		} {
+			req := httptest.NewRequest("OPTIONS", path, nil)
+			req.Header.Set("Origin", "https://bakery.example.com")
+			req.Header.Set("Access-Control-Request-Method", "POST")
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			if got := resp.Header.Get("Access-Control-Allow-Origin"); got != want {
+				t.Errorf("%s: expected Access-Control-Allow-Origin %q, got %q", path, want, got)
+			}
+		}
+	})
+
+	t.Run("Unknown form", func(t *testing.T) {
+		if resp := get("/signup/widget/no-such-form"); resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Expected 404, got %d", resp.StatusCode)
+		}
+	})
 }
@@ -0,0 +1,22 @@
+package tracking
+
+import (
+	"fiber-gorm-api/internal/db"
+	"fiber-gorm-api/internal/handlers"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RegisterRoutes sets up the tracking of campaign emails under /t. They're loaded by mail
+// clients, authenticated by the signed token of the recipient, so no CORS or JWT.
+func RegisterRoutes(app *fiber.App) {
+	trackingGroup := app.Group("/t")
+
+	// Initialize DB
+	database := db.Connect(false)
+
+	// Open pixel
+	trackingGroup.Get("/o/:token", handlers.TrackOpen(database))
+	// Tracked links
+	trackingGroup.Get("/c/:token", handlers.TrackClick(database))
+}
@@ -0,0 +1,138 @@
+package tracking
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"fiber-gorm-api/internal/db"
+	"fiber-gorm-api/internal/models"
+	"fiber-gorm-api/internal/tracking"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const reader = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko)"
+
+func TestTrackingRoutes(t *testing.T) {
+	database := db.Connect(true)
+	app := fiber.New()
+	RegisterRoutes(app)
+
+	subscriber := models.Subscriber{OrgID: models.DefaultOrgID, Email: fmt.Sprintf("open-%d@example.com", time.Now().UnixNano()), Status: models.SubscriberStatusActive}
+	database.Create(&subscriber)
+	campaign := models.Campaign{OrgID: models.DefaultOrgID, Name: "Opens", Subject: "Hello", Text: "Hello", Status: models.CampaignSent, Run: 1}
+	database.Create(&campaign)
+	sentAt := time.Now().Add(-time.Hour)
+	send := models.CampaignSend{CampaignID: campaign.ID, Run: 1, SubscriberID: subscriber.ID, Email: subscriber.Email, Variant: "a", SentAt: &sentAt}
+	database.Create(&send)
+	link := models.CampaignLink{CampaignID: campaign.ID, URL: "https://example.com/this-week?utm_source=email"}
+	database.Create(&link)
+
+	get := func(path, userAgent string) *http.Response {
+		t.Helper()
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("User-Agent", userAgent)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		return resp
+	}
+	open := func(token, userAgent string) {
+		t.Helper()
+		resp := get("/t/o/"+token, userAgent)
+		body, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode != fiber.StatusOK || resp.Header.Get("Content-Type") != "image/gif" || len(body) != len(tracking.Pixel) {
+			t.Errorf("%s: expected the pixel, got %d %s", token, resp.StatusCode, resp.Header.Get("Content-Type"))
+		}
+		if resp.Header.Get("Cache-Control") != "no-store, max-age=0" {
+			t.Errorf("Expected the pixel not cached, got %q", resp.Header.Get("Cache-Control"))
+		}
+	}
+	reload := func() models.CampaignSend {
+		var got models.CampaignSend
+		database.First(&got, send.ID)
+		return got
+	}
+	events := func() int64 {
+		var n int64
+		database.Model(&models.CampaignEvent{}).Where("send_id = ?", send.ID).Count(&n)
+		return n
+	}
+	now := time.Now()
+
+	t.Run("TrackOpen - Unknown Or Forged Token", func(t *testing.T) {
+		open(tracking.Token(tracking.KindOpen, send.ID+1000000, 0, now), reader)
+		open(fmt.Sprintf("%d.0.0.garbage", send.ID), reader)
+		open(tracking.Token(tracking.KindClick, send.ID, 0, now), reader)
+		if reload().OpenedAt != nil || events() != 0 {
+			t.Error("Expected no open recorded")
+		}
+	})
+
+	t.Run("TrackOpen - Bots And Expired Tokens", func(t *testing.T) {
+		open(tracking.Token(tracking.KindOpen, send.ID, 0, now), "")
+		open(tracking.Token(tracking.KindOpen, send.ID, 0, now), "Mozilla/5.0 (compatible; bingbot/2.0)")
+		open(tracking.Token(tracking.KindOpen, send.ID, 0, now.Add(-200*24*time.Hour)), reader)
+		if reload().OpenedAt != nil || events() != 0 {
+			t.Error("Expected no open recorded")
+		}
+	})
+
+	t.Run("TrackOpen - Records Opens", func(t *testing.T) {
+		open(tracking.Token(tracking.KindOpen, send.ID, 0, now), reader)
+		first := reload().OpenedAt
+		if first == nil {
+			t.Fatal("Expected the open recorded")
+		}
+		open(tracking.Token(tracking.KindOpen, send.ID, 0, now), reader)
+		if again := reload().OpenedAt; again == nil || !again.Equal(*first) {
+			t.Errorf("Expected the first open kept, got %v", again)
+		}
+		if events() != 2 {
+			t.Errorf("Expected both opens logged, got %d", events())
+		}
+	})
+
+	t.Run("TrackClick - Unknown Or Forged Token", func(t *testing.T) {
+		for _, token := range []string{
+			tracking.Token(tracking.KindClick, send.ID, link.ID+1000000, now),
+			tracking.Token(tracking.KindOpen, send.ID, link.ID, now),
+			tracking.Token(tracking.KindClick, send.ID, 0, now),
+			"garbage",
+		} {
+			if resp := get("/t/c/"+token, reader); resp.StatusCode != fiber.StatusNotFound {
+				t.Errorf("%s: expected 404, got %d", token, resp.StatusCode)
+			}
+		}
+	})
+
+	t.Run("TrackClick - Redirects Bots Without Counting", func(t *testing.T) {
+		resp := get("/t/c/"+tracking.Token(tracking.KindClick, send.ID, link.ID, now), "curl/8.4.0")
+		if resp.StatusCode != fiber.StatusFound || resp.Header.Get("Location") != link.URL {
+			t.Errorf("Expected a redirect to the link, got %d %s", resp.StatusCode, resp.Header.Get("Location"))
+		}
+		if reload().ClickedAt != nil {
+			t.Error("Expected no click recorded")
+		}
+	})
+
+	t.Run("TrackClick - Records Clicks", func(t *testing.T) {
+		resp := get("/t/c/"+tracking.Token(tracking.KindClick, send.ID, link.ID, now), reader)
+		if resp.StatusCode != fiber.StatusFound || resp.Header.Get("Location") != link.URL {
+			t.Errorf("Expected a redirect to the link, got %d %s", resp.StatusCode, resp.Header.Get("Location"))
+		}
+		if reload().ClickedAt == nil {
+			t.Error("Expected the click recorded")
+		}
+		var click models.CampaignEvent
+		database.Where("send_id = ? AND type = ?", send.ID, models.CampaignEventClick).First(&click)
+		if click.LinkID == nil || *click.LinkID != link.ID || click.UserAgent != reader {
+			t.Errorf("Expected the click on the link logged, got %+v", click)
+		}
+	})
+}
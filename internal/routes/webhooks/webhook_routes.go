@@ -0,0 +1,20 @@
+package webhooks
+
+import (
+	"fiber-gorm-api/internal/db"
+	"fiber-gorm-api/internal/handlers"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RegisterRoutes sets up inbound provider webhooks under /webhooks.
+// They are server-to-server calls authenticated by the provider's signature, so no CORS or JWT.
+func RegisterRoutes(app *fiber.App) {
+	webhookGroup := app.Group("/webhooks")
+
+	// Initialize DB
+	database := db.Connect(false)
+
+	// SendGrid Event Webhook: bounces, spam reports, unsubscribes
+	webhookGroup.Post("/sendgrid", handlers.SendGridWebhook(database))
+}
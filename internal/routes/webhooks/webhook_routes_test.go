@@ -0,0 +1,95 @@
+package webhooks
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	sendgridservice "fiber-gorm-api/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestSendGridWebhookRoute(t *testing.T) {
+	app := fiber.New()
+	RegisterRoutes(app)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+
+	payload := `[{"email": "nobody-webhook@example.com", "event": "bounce", "type": "bounce", "timestamp": 1700000000, "sg_event_id": "test-event-1"}]`
+	timestamp := "1700000000"
+	sign := func(body string) string {
+		digest := sha256.Sum256([]byte(timestamp + body))
+		sig, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+		if err != nil {
+			t.Fatalf("Failed to sign payload: %v", err)
+		}
+		return base64.StdEncoding.EncodeToString(sig)
+	}
+	newRequest := func(signature string) *http.Request {
+		req := httptest.NewRequest("POST", "/webhooks/sendgrid", strings.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(sendgridservice.EventTimestampHeader, timestamp)
+		if signature != "" {
+			req.Header.Set(sendgridservice.EventSignatureHeader, signature)
+		}
+		return req
+	}
+
+	t.Run("SendGridWebhook - Not Configured => 503", func(t *testing.T) {
+		t.Setenv("SENDGRID_WEBHOOK_PUBLIC_KEY", "")
+		resp, err := app.Test(newRequest(sign(payload)))
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusServiceUnavailable {
+			t.Errorf("Expected 503 without a public key, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Setenv("SENDGRID_WEBHOOK_PUBLIC_KEY", base64.StdEncoding.EncodeToString(der))
+
+	t.Run("SendGridWebhook - Missing Signature => 401", func(t *testing.T) {
+		resp, err := app.Test(newRequest(""))
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Expected 401 without a signature, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("SendGridWebhook - Tampered Payload => 401", func(t *testing.T) {
+		resp, err := app.Test(newRequest(sign(`[]`)))
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Expected 401 for a signature over another payload, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("SendGridWebhook - Signed Batch => 204", func(t *testing.T) {
+		resp, err := app.Test(newRequest(sign(payload)), -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusNoContent {
+			t.Errorf("Expected 204 for a signed batch, got %d", resp.StatusCode)
+		}
+	})
+}
@@ -0,0 +1,111 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"fiber-gorm-api/internal/exports"
+	"fiber-gorm-api/internal/imports"
+	"fiber-gorm-api/internal/models"
+	redisclient "fiber-gorm-api/internal/redis"
+	"fiber-gorm-api/internal/session"
+	"fiber-gorm-api/internal/storage"
+
+	"gorm.io/gorm"
+)
+
+// expiringKeyPatterns are Redis keys that are always written with a TTL.
+// Any of them found without one (e.g. left behind by an older release) is deleted.
+var expiringKeyPatterns = []string{
+	"signin_code:*",
+	"signin_attempts:*",
+	"signin_lock:*",
+	"signin_failures:*",
+	"signup_rate:*",
+	"signup_alert:*",
+	"spam_ip:*",
+	"oauth_state:*",
+	"webauthn_*",
+	"session:*",
+}
+
+// PurgeRedis prunes what expired sessions left in the session store (ids in the per-user
+// indexes of Redis, rows in Postgres) and deletes sign-in, OAuth, WebAuthn and session keys
+// that never got an expiry.
+func PurgeRedis() {
+	pruned, err := session.Prune(redisclient.Ctx)
+	if err != nil {
+		log.Printf("[WARN] Cleanup: pruning expired sessions failed: %v", err)
+	}
+
+	stale := 0
+	for _, pattern := range expiringKeyPatterns {
+		keys, err := redisclient.ScanKeys(redisclient.Ctx, pattern)
+		if err != nil {
+			log.Printf("[WARN] Cleanup: scanning %s failed: %v", pattern, err)
+			continue
+		}
+		for _, key := range keys {
+			// -1 means the key exists but has no expiry
+			if ttl, err := redisclient.TTL(redisclient.Ctx, key); err == nil && ttl == -1 {
+				if err := redisclient.DeleteKey(redisclient.Ctx, key); err == nil {
+					stale++
+				}
+			}
+		}
+	}
+	log.Printf("Cleanup: pruned %d expired session entries, deleted %d keys without expiry", pruned, stale)
+}
+
+// PurgeDeletedSubscribers hard-deletes subscribers soft-deleted longer than retention ago,
+// with their subscriber_types, notes and revisions
+func PurgeDeletedSubscribers(db *gorm.DB, retention time.Duration) {
+	cutoff := time.Now().Add(-retention)
+	purged := db.Unscoped().Model(&models.Subscriber{}).Select("id").
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff)
+	for _, child := range []interface{}{&models.SubscriberType{}, &models.SubscriberNote{}, &models.SubscriberRevision{}} {
+		if err := db.Where("subscriber_id IN (?)", purged).Delete(child).Error; err != nil {
+			log.Printf("[WARN] Cleanup: purging the relations of deleted subscribers failed: %v", err)
+			return
+		}
+	}
+	res := db.Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+		Delete(&models.Subscriber{})
+	if res.Error != nil {
+		log.Printf("[WARN] Cleanup: purging deleted subscribers failed: %v", res.Error)
+		return
+	}
+	log.Printf("Cleanup: purged %d subscribers deleted before %s", res.RowsAffected, cutoff.Format(time.RFC3339))
+}
+
+// PurgeExports deletes expired export files and their jobs
+func PurgeExports(db *gorm.DB) {
+	n, err := exports.Purge(db, time.Now())
+	if err != nil {
+		log.Printf("[WARN] Cleanup: purging expired exports failed: %v", err)
+		return
+	}
+	log.Printf("Cleanup: purged %d expired exports", n)
+}
+
+// PurgeImports deletes expired import jobs and their error reports
+func PurgeImports(db *gorm.DB) {
+	n, err := imports.Purge(db, time.Now())
+	if err != nil {
+		log.Printf("[WARN] Cleanup: purging expired imports failed: %v", err)
+		return
+	}
+	log.Printf("Cleanup: purged %d expired imports", n)
+}
+
+// PurgeStorage deletes the stored files past the retention of their kind (see storage.Rules)
+func PurgeStorage() {
+	n, err := storage.Cleanup(context.Background(), storage.Default, storage.Rules(), time.Now())
+	if err != nil {
+		log.Printf("[WARN] Cleanup: purging stored files failed after %d: %v", n, err)
+		return
+	}
+	log.Printf("Cleanup: purged %d stored files past their retention", n)
+}
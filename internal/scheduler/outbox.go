@@ -0,0 +1,85 @@
+package scheduler
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"fiber-gorm-api/internal/cache"
+	"fiber-gorm-api/internal/campaigns"
+	"fiber-gorm-api/internal/mailqueue"
+	"fiber-gorm-api/internal/models"
+	"fiber-gorm-api/internal/notify"
+	"fiber-gorm-api/internal/outbox"
+	"fiber-gorm-api/internal/realtime"
+	redisclient "fiber-gorm-api/internal/redis"
+	"fiber-gorm-api/internal/resthooks"
+
+	"gorm.io/gorm"
+)
+
+const (
+	outboxBatchSize            = 100
+	defaultOutboxRetentionDays = 7
+)
+
+// registerOutboxHandlers wires the side effects of subscriber writes. Request handlers also
+// invalidate the cache right away; the outbox makes sure it happens even if Redis was down then.
+// Live admin clients (GET /admin/events) are notified once the write is committed, as are
+// platform admins of sensitive activity in immediate mode. Each REST hook subscribed to a
+// subscriber event gets its own delivery event, retried apart from the others. Sign-in emails
+// are sent from here too, and kept as dead letters once given up on; so are campaign emails,
+// without dead letters.
+func registerOutboxHandlers(db *gorm.DB) {
+	for _, topic := range outbox.SubscriberTopics {
+		outbox.Handle(topic, invalidateSubscriberCache)
+		outbox.Handle(topic, realtime.PublishSubscriberEvent)
+		outbox.Handle(topic, resthooks.Fanout(db))
+	}
+	outbox.Handle(resthooks.TopicDelivery, resthooks.Deliver(db))
+	outbox.Handle(notify.TopicAdminActivity, notify.Handler(db))
+	outbox.Handle(mailqueue.TopicSend, mailqueue.Send)
+	outbox.HandleFailed(mailqueue.TopicSend, mailqueue.DeadLetter)
+	outbox.Handle(campaigns.TopicSend, campaigns.Deliver(db))
+}
+
+func invalidateSubscriberCache(event models.OutboxEvent) error {
+	payload, err := outbox.DecodeSubscriberEvent(event)
+	if err != nil {
+		return err
+	}
+	return cache.InvalidateSubscriber(redisclient.Ctx, payload.SubscriberID)
+}
+
+// outboxRetention is how long processed outbox events are kept, from OUTBOX_RETENTION_DAYS
+func outboxRetention() time.Duration {
+	if n, err := strconv.Atoi(os.Getenv("OUTBOX_RETENTION_DAYS")); err == nil && n > 0 {
+		return time.Duration(n) * 24 * time.Hour
+	}
+	return defaultOutboxRetentionDays * 24 * time.Hour
+}
+
+// DrainOutbox dispatches due outbox events until none are left or a batch fails
+func DrainOutbox(db *gorm.DB) {
+	for {
+		done, err := outbox.Drain(db, outboxBatchSize)
+		if err != nil {
+			log.Printf("[WARN] Outbox: drain failed: %v", err)
+			return
+		}
+		if done < outboxBatchSize {
+			return
+		}
+	}
+}
+
+// PurgeOutbox deletes processed outbox events older than the retention window
+func PurgeOutbox(db *gorm.DB, retention time.Duration) {
+	n, err := outbox.Purge(db, retention)
+	if err != nil {
+		log.Printf("[WARN] Cleanup: purging outbox events failed: %v", err)
+		return
+	}
+	log.Printf("Cleanup: purged %d processed outbox events", n)
+}
@@ -0,0 +1,121 @@
+package scheduler
+
+import (
+	"log"
+	"os"
+	"time"
+
+	"fiber-gorm-api/internal/anomaly"
+	"fiber-gorm-api/internal/campaigns"
+	"fiber-gorm-api/internal/db"
+	"fiber-gorm-api/internal/exports"
+	"fiber-gorm-api/internal/imports"
+	"fiber-gorm-api/internal/integrations"
+	"fiber-gorm-api/internal/notify"
+	redisclient "fiber-gorm-api/internal/redis"
+	"fiber-gorm-api/internal/service"
+
+	"github.com/robfig/cron/v3"
+)
+
+const (
+	defaultRedisCleanupSchedule      = "@every 1h"
+	defaultSubscriberCleanupSchedule = "@daily"
+	defaultOutboxDrainSchedule       = "@every 5s"
+	defaultAdminDigestSchedule       = "@daily"
+	defaultSignupMonitorSchedule     = "@every 5m"
+	defaultExportWorkerSchedule      = "@every 10s"
+	defaultExportCleanupSchedule     = "@every 1h"
+	defaultImportWorkerSchedule      = "@every 10s"
+	defaultImportCleanupSchedule     = "@every 1h"
+	defaultIntegrationSyncSchedule   = "@every 15m"
+	defaultStorageCleanupSchedule    = "@every 6h"
+	defaultCampaignDispatchSchedule  = "@every 30s"
+)
+
+// Start registers the outbox worker and the periodic cleanup jobs and starts the scheduler in the background.
+// Schedules are cron expressions (or @every / @daily descriptors) read from OUTBOX_DRAIN_SCHEDULE,
+// CLEANUP_REDIS_SCHEDULE, CLEANUP_SUBSCRIBERS_SCHEDULE, CLEANUP_EXPORTS_SCHEDULE,
+// CLEANUP_IMPORTS_SCHEDULE, CLEANUP_STORAGE_SCHEDULE, SIGNUP_MONITOR_SCHEDULE,
+// EXPORT_WORKER_SCHEDULE, IMPORT_WORKER_SCHEDULE, INTEGRATIONS_SYNC_SCHEDULE,
+// CAMPAIGN_DISPATCH_SCHEDULE and ADMIN_NOTIFICATIONS_DIGEST_SCHEDULE; "off" disables a job. The admin activity digest only runs with ADMIN_NOTIFICATIONS=digest.
+func Start() *cron.Cron {
+	database := db.Connect(true)
+	c := cron.New()
+
+	registerOutboxHandlers(database)
+	register(c, "outbox drain", schedule("OUTBOX_DRAIN_SCHEDULE", defaultOutboxDrainSchedule), func() {
+		DrainOutbox(database)
+	})
+
+	register(c, "redis cleanup", schedule("CLEANUP_REDIS_SCHEDULE", defaultRedisCleanupSchedule), PurgeRedis)
+	register(c, "subscriber cleanup", schedule("CLEANUP_SUBSCRIBERS_SCHEDULE", defaultSubscriberCleanupSchedule), func() {
+		PurgeDeletedSubscribers(database, service.DeletionWindow())
+		PurgeOutbox(database, outboxRetention())
+	})
+	register(c, "export cleanup", schedule("CLEANUP_EXPORTS_SCHEDULE", defaultExportCleanupSchedule), func() {
+		PurgeExports(database)
+	})
+	register(c, "import cleanup", schedule("CLEANUP_IMPORTS_SCHEDULE", defaultImportCleanupSchedule), func() {
+		PurgeImports(database)
+	})
+	register(c, "storage cleanup", schedule("CLEANUP_STORAGE_SCHEDULE", defaultStorageCleanupSchedule), PurgeStorage)
+
+	register(c, "signup monitor", schedule("SIGNUP_MONITOR_SCHEDULE", defaultSignupMonitorSchedule), func() {
+		if _, err := anomaly.CheckSignups(redisclient.Ctx, database, time.Now()); err != nil {
+			log.Printf("[WARN] Signup monitor: alerting failed: %v", err)
+		}
+	})
+	register(c, "export worker", schedule("EXPORT_WORKER_SCHEDULE", defaultExportWorkerSchedule), func() {
+		if _, err := exports.ProcessPending(database); err != nil {
+			log.Printf("[WARN] Exports: claiming pending exports failed: %v", err)
+		}
+	})
+	register(c, "import worker", schedule("IMPORT_WORKER_SCHEDULE", defaultImportWorkerSchedule), func() {
+		if _, err := imports.ProcessPending(database); err != nil {
+			log.Printf("[WARN] Imports: claiming pending imports failed: %v", err)
+		}
+	})
+	register(c, "campaign dispatcher", schedule("CAMPAIGN_DISPATCH_SCHEDULE", defaultCampaignDispatchSchedule), func() {
+		if _, err := campaigns.ProcessDue(database, time.Now()); err != nil {
+			log.Printf("[WARN] Campaigns: listing due campaigns failed: %v", err)
+		}
+	})
+	register(c, "integration sync", schedule("INTEGRATIONS_SYNC_SCHEDULE", defaultIntegrationSyncSchedule), func() {
+		if _, err := integrations.SyncAll(database); err != nil {
+			log.Printf("[WARN] Integrations: listing integrations to sync failed: %v", err)
+		}
+	})
+	if notify.Mode() == notify.ModeDigest {
+		register(c, "admin activity digest", schedule("ADMIN_NOTIFICATIONS_DIGEST_SCHEDULE", defaultAdminDigestSchedule), func() {
+			if err := notify.SendDigest(database); err != nil {
+				log.Printf("[WARN] Notify: sending the admin activity digest failed: %v", err)
+			}
+		})
+	}
+
+	c.Start()
+	return c
+}
+
+// schedule reads a schedule from env, falling back to def
+func schedule(env, def string) string {
+	if spec := os.Getenv(env); spec != "" {
+		return spec
+	}
+	return def
+}
+
+// register adds a job unless its schedule is "off". Invalid schedules are logged and skipped
+// rather than stopping the API from starting.
+func register(c *cron.Cron, name, spec string, job func()) {
+	if spec == "off" {
+		log.Printf("Scheduler: %s disabled", name)
+		return
+	}
+	if _, err := c.AddFunc(spec, job); err != nil {
+		log.Printf("[WARN] Scheduler: invalid schedule %q for %s: %v", spec, name, err)
+		return
+	}
+	log.Printf("Scheduler: %s scheduled %q", name, spec)
+}
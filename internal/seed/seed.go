@@ -0,0 +1,195 @@
+// Package seed fills a development database with realistic fake data: subscribers with
+// subscriber_types, tags and notes, and admin users, spread over the past year so lists,
+// filters and stats have something to show.
+package seed
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"fiber-gorm-api/internal/models"
+	"fiber-gorm-api/internal/repository"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"gorm.io/gorm"
+)
+
+// batchSize is how many subscribers are inserted per statement
+const batchSize = 500
+
+// domains are reserved for examples (RFC 2606): nothing seeded can ever reach a real inbox
+var domains = []string{"example.com", "example.org", "example.net"}
+
+// tags are stored in the metadata of subscribers, under "tags"
+var tags = []string{"vip", "volunteer", "newsletter", "early-adopter", "local-business", "press", "event-2026", "partner"}
+
+var sources = []string{"website", "event", "referral", "import", "social"}
+
+// statuses and locales are weighted like a real list: mostly active English speakers
+var (
+	statuses      = []interface{}{models.SubscriberStatusActive, models.SubscriberStatusPending, models.SubscriberStatusUnsubscribed, models.SubscriberStatusBounced, models.SubscriberStatusQuarantined}
+	statusWeights = []float32{70, 15, 8, 5, 2}
+	locales       = []interface{}{"en", "fr", "es"}
+	localeWeights = []float32{80, 12, 8}
+)
+
+// adminRoles are given in turn to the admins seeded
+var adminRoles = []string{models.RoleAdmin, models.RoleEditor, models.RoleViewer}
+
+// Options sizes the data seeded
+type Options struct {
+	OrgID       uint
+	Subscribers int
+	Admins      int
+	// Seed makes the data of a run on an empty database reproducible; 0 picks a random one
+	Seed int64
+}
+
+// Result counts what was seeded
+type Result struct {
+	Subscribers int
+	Admins      int
+	Notes       int
+}
+
+// Run adds fake admins and subscribers to the organization. Rows are written straight to
+// their tables: no outbox event is recorded, so nothing is emailed, synced or sent to hooks.
+func Run(ctx context.Context, conn *gorm.DB, opts Options) (Result, error) {
+	var result Result
+	faker := gofakeit.New(opts.Seed)
+	conn = conn.WithContext(ctx)
+
+	admins, err := seedAdmins(conn, faker, opts)
+	if err != nil {
+		return result, err
+	}
+	result.Admins = len(admins)
+
+	typeNames, err := repository.NewSubscriberRepository(conn).TypeNames(ctx)
+	if err != nil {
+		return result, err
+	}
+	// emails are numbered after the subscribers already there, so a second run adds to the first
+	var existing int64
+	if err := conn.Unscoped().Model(&models.Subscriber{}).Count(&existing).Error; err != nil {
+		return result, err
+	}
+	now := time.Now()
+	for done := 0; done < opts.Subscribers; {
+		batch := make([]models.Subscriber, min(batchSize, opts.Subscribers-done))
+		for i := range batch {
+			batch[i] = fakeSubscriber(faker, opts.OrgID, typeNames, int(existing)+done+i+1, now)
+		}
+		if err := conn.Create(&batch).Error; err != nil {
+			return result, err
+		}
+		notes, err := seedNotes(conn, faker, batch, admins)
+		if err != nil {
+			return result, err
+		}
+		result.Notes += notes
+		done += len(batch)
+		result.Subscribers = done
+	}
+	return result, nil
+}
+
+// seedAdmins adds opts.Admins admins with the three roles in turn, and returns the emails of
+// every admin of the organization, the authors of the notes
+func seedAdmins(conn *gorm.DB, faker *gofakeit.Faker, opts Options) ([]string, error) {
+	var existing int64
+	if err := conn.Model(&models.AdminUser{}).Count(&existing).Error; err != nil {
+		return nil, err
+	}
+	for i := 0; i < opts.Admins; i++ {
+		admin := models.AdminUser{
+			OrgID: opts.OrgID,
+			Email: fakeEmail(faker.FirstName(), faker.LastName(), int(existing)+i+1, domains[0]),
+			Role:  adminRoles[i%len(adminRoles)],
+		}
+		if err := conn.Create(&admin).Error; err != nil {
+			return nil, err
+		}
+	}
+	var emails []string
+	err := conn.Model(&models.AdminUser{}).Where("org_id = ?", opts.OrgID).Order("id").Pluck("email", &emails).Error
+	return emails, err
+}
+
+// fakeEmail is first.last.n@domain, n keeping namesakes apart
+func fakeEmail(first, last string, n int, domain string) string {
+	local := strings.ToLower(first + "." + last)
+	local = strings.NewReplacer(" ", "", "'", "").Replace(local)
+	return fmt.Sprintf("%s.%d@%s", local, n, domain)
+}
+
+// fakeSubscriber makes up the nth subscriber, who signed up in the past year
+func fakeSubscriber(faker *gofakeit.Faker, orgID uint, typeNames []string, n int, now time.Time) models.Subscriber {
+	first, last := faker.FirstName(), faker.LastName()
+	created := faker.DateRange(now.AddDate(-1, 0, 0), now)
+	status, _ := faker.Weighted(statuses, statusWeights)
+	locale, _ := faker.Weighted(locales, localeWeights)
+
+	s := models.Subscriber{
+		OrgID:     orgID,
+		Email:     fakeEmail(first, last, n, faker.RandomString(domains)),
+		Name:      first + " " + last,
+		Status:    status.(string),
+		Locale:    locale.(string),
+		Version:   1,
+		CreatedAt: created,
+		UpdatedAt: faker.DateRange(created, now),
+		Metadata: models.JSONMap{
+			"source": faker.RandomString(sources),
+			"city":   faker.City(),
+		},
+	}
+	if s.Status != models.SubscriberStatusPending && s.Status != models.SubscriberStatusQuarantined {
+		verified := faker.DateRange(created, s.UpdatedAt)
+		s.EmailVerifiedAt = &verified
+	}
+	if s.Status == models.SubscriberStatusQuarantined {
+		s.SpamScore = faker.Number(60, 100)
+	}
+	if n := faker.Number(0, 2); n > 0 {
+		s.Metadata["tags"] = pick(faker, tags, n)
+	}
+	for _, name := range pick(faker, typeNames, faker.Number(0, 3)) {
+		s.SubscriberTypes = append(s.SubscriberTypes, models.SubscriberType{
+			Name:      name,
+			Frequency: faker.RandomString(models.Frequencies),
+			Channel:   faker.RandomString(models.Channels),
+		})
+	}
+	return s
+}
+
+// seedNotes gives about one subscriber in five a note by one of the admins
+func seedNotes(conn *gorm.DB, faker *gofakeit.Faker, subscribers []models.Subscriber, admins []string) (int, error) {
+	if len(admins) == 0 {
+		return 0, nil
+	}
+	var notes []models.SubscriberNote
+	for _, s := range subscribers {
+		if faker.Number(1, 5) == 1 {
+			notes = append(notes, models.SubscriberNote{
+				SubscriberID: s.ID,
+				Author:       faker.RandomString(admins),
+				Text:         faker.Sentence(12),
+			})
+		}
+	}
+	if len(notes) == 0 {
+		return 0, nil
+	}
+	return len(notes), conn.Create(&notes).Error
+}
+
+// pick returns n distinct values of from, at most all of them
+func pick(faker *gofakeit.Faker, from []string, n int) []string {
+	shuffled := append([]string(nil), from...)
+	faker.ShuffleStrings(shuffled)
+	return shuffled[:min(n, len(shuffled))]
+}
@@ -0,0 +1,73 @@
+package seed
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"fiber-gorm-api/internal/models"
+	"fiber-gorm-api/internal/testutil/testenv"
+)
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+	conn := testenv.SQLite(t)
+	opts := Options{OrgID: models.DefaultOrgID, Subscribers: 40, Admins: 3, Seed: 42}
+	result, err := Run(ctx, conn, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Subscribers != 40 || result.Admins != 3 {
+		t.Errorf("Unexpected result %+v", result)
+	}
+
+	var subscribers []models.Subscriber
+	conn.Preload("SubscriberTypes").Order("id").Find(&subscribers)
+	if len(subscribers) != 40 {
+		t.Fatalf("Expected 40 subscribers, got %d", len(subscribers))
+	}
+	statuses := map[string]bool{}
+	for _, s := range subscribers {
+		statuses[s.Status] = true
+		if domain := s.Email[strings.LastIndex(s.Email, "@")+1:]; !slices.Contains(domains, domain) {
+			t.Errorf("Expected a reserved example domain, got %s", s.Email)
+		}
+		for _, st := range s.SubscriberTypes {
+			if st.Frequency == "" || st.Channel == "" {
+				t.Errorf("Expected the preferences of %s set, got %+v", s.Email, st)
+			}
+		}
+	}
+	if !statuses[models.SubscriberStatusActive] || len(statuses) < 2 {
+		t.Errorf("Expected a mix of statuses, got %v", statuses)
+	}
+
+	var roles []string
+	conn.Model(&models.AdminUser{}).Order("id").Pluck("role", &roles)
+	if strings.Join(roles, ",") != "admin,editor,viewer" {
+		t.Errorf("Expected the three roles, got %v", roles)
+	}
+
+	t.Run("The same seed gives the same people", func(t *testing.T) {
+		other := testenv.SQLite(t)
+		Run(ctx, other, opts)
+		var first models.Subscriber
+		other.Order("id").First(&first)
+		if first.Email != subscribers[0].Email || first.Name != subscribers[0].Name {
+			t.Errorf("Expected %s, got %s", subscribers[0].Email, first.Email)
+		}
+	})
+
+	t.Run("A second run adds to the first", func(t *testing.T) {
+		if _, err := Run(ctx, conn, opts); err != nil {
+			t.Fatal(err)
+		}
+		var count, admins int64
+		conn.Model(&models.Subscriber{}).Count(&count)
+		conn.Model(&models.AdminUser{}).Count(&admins)
+		if count != 80 || admins != 6 {
+			t.Errorf("Expected 80 subscribers and 6 admins, got %d and %d", count, admins)
+		}
+	})
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"fiber-gorm-api/internal/db"
+	"fiber-gorm-api/internal/middleware"
+	"fiber-gorm-api/internal/models"
+	"fiber-gorm-api/internal/notify"
+
+	"gorm.io/gorm"
+)
+
+// APIKeyPrefix marks our keys so they're recognisable in secret scanners and logs
+const APIKeyPrefix = "mylo_"
+
+// ErrAdminExists is returned when adding an admin whose email already belongs to an organization
+var ErrAdminExists = errors.New("this admin already belongs to an organization")
+
+// AddAdmin makes email an admin of the organization with role (admin when empty). Shared by
+// POST /admin/organizations/{id}/members and myloctl.
+func AddAdmin(ctx context.Context, conn *gorm.DB, orgID uint, email, role string) (*models.AdminUser, error) {
+	email = strings.TrimSpace(email)
+	if !IsValidEmail(email) {
+		return nil, ErrInvalidEmail
+	}
+	if role == "" {
+		role = models.RoleAdmin
+	}
+	if _, ok := models.RoleScopes[role]; !ok {
+		return nil, &ValidationError{Message: "Unknown role: " + role}
+	}
+
+	conn = conn.WithContext(ctx)
+	var count int64
+	if err := conn.Model(&models.AdminUser{}).Where("email = ?", email).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, ErrAdminExists
+	}
+	admin := models.AdminUser{OrgID: orgID, Email: email, Role: role}
+	if err := conn.Create(&admin).Error; err != nil {
+		return nil, err
+	}
+	return &admin, nil
+}
+
+// ValidateAPIKeyScopes checks that scopes grants at least one of models.ValidScopes, and
+// nothing else
+func ValidateAPIKeyScopes(scopes []string) error {
+	if len(scopes) == 0 {
+		return &ValidationError{Message: "At least one scope is required"}
+	}
+	for _, scope := range scopes {
+		if !slices.Contains(models.ValidScopes, scope) {
+			return &ValidationError{Message: "Unknown scope: " + scope}
+		}
+	}
+	return nil
+}
+
+// IssueAPIKey generates the secret of key, stores key with its hash and records the activity
+// for platform admins. The returned plaintext is never stored: it can only be shown once.
+// Name and scopes are validated by the caller.
+func IssueAPIKey(ctx context.Context, conn *gorm.DB, key *models.ApiKey) (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	plaintext := APIKeyPrefix + base64.RawURLEncoding.EncodeToString(raw)
+	key.Prefix = plaintext[:len(APIKeyPrefix)+7]
+	key.KeyHash = middleware.HashAPIKey(plaintext)
+
+	err := db.Tx(ctx, conn, func(tx *gorm.DB) error {
+		if err := tx.Create(key).Error; err != nil {
+			return err
+		}
+		return notify.Record(tx, &models.AdminActivity{
+			OrgID:   key.OrgID,
+			Kind:    notify.KindAPIKeyCreated,
+			Actor:   key.CreatedBy,
+			Summary: fmt.Sprintf("API key %q (%s) created with scopes %s", key.Name, key.Prefix, key.Scopes),
+		})
+	})
+	if err != nil {
+		return "", err
+	}
+	return plaintext, nil
+}
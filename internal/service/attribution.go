@@ -0,0 +1,50 @@
+package service
+
+import (
+	"net/url"
+	"strings"
+)
+
+// maxUTMLength bounds each utm_* value of a signup
+const maxUTMLength = 100
+
+// ErrInvalidAttribution rejects utm_* values that are too long, or a referrer that isn't an
+// http(s) URL
+var ErrInvalidAttribution = &ValidationError{
+	Message: "utm_source, utm_medium and utm_campaign are limited to 100 characters and referrer must be an http(s) URL",
+	Code:    "invalid_attribution",
+}
+
+// Attribution is where a public signup came from: the utm_* parameters of the link followed and
+// the page linking to the signup form
+type Attribution struct {
+	Source   string
+	Medium   string
+	Campaign string
+	Referrer string
+}
+
+// Normalize returns a as stored, trimmed: utm_source and utm_medium lowercased, as the same
+// channel is often tagged Facebook and facebook, and the referrer reduced to its host without
+// www., since the rest of its URL can hold personal data
+func (a Attribution) Normalize() (Attribution, error) {
+	out := Attribution{
+		Source:   strings.ToLower(strings.TrimSpace(a.Source)),
+		Medium:   strings.ToLower(strings.TrimSpace(a.Medium)),
+		Campaign: strings.TrimSpace(a.Campaign),
+	}
+	for _, value := range []string{out.Source, out.Medium, out.Campaign} {
+		if len(value) > maxUTMLength {
+			return Attribution{}, ErrInvalidAttribution
+		}
+	}
+
+	if referrer := strings.TrimSpace(a.Referrer); referrer != "" {
+		u, err := url.Parse(referrer)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+			return Attribution{}, ErrInvalidAttribution
+		}
+		out.Referrer = strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	}
+	return out, nil
+}
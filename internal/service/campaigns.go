@@ -0,0 +1,98 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"fiber-gorm-api/internal/campaigns"
+	"fiber-gorm-api/internal/models"
+)
+
+const (
+	// maxCampaignBody bounds the text and the HTML of a campaign, in bytes
+	maxCampaignBody = 512 * 1024
+	// Bounds of the share of the audience in the test group of an A/B test
+	minTestPercent = 2
+	maxTestPercent = 50
+	// defaultTestHours is how long an A/B test measures opens when not set, maxTestHours at most
+	defaultTestHours = 4
+	maxTestHours     = 72
+)
+
+// invalidSchedule rejects the schedule of a campaign
+func invalidSchedule(message string) error {
+	return &ValidationError{Message: message, Code: "invalid_schedule"}
+}
+
+// invalidABTest rejects the A/B test settings of a campaign
+func invalidABTest(message string) error {
+	return &ValidationError{Message: message, Code: "invalid_ab_test"}
+}
+
+// ValidateCampaign checks the content and A/B test of a campaign and sets its first run: at
+// sendAt (see campaigns.ParseSendAt), which must not be past, else at the next occurrence of its
+// recurrence after now. A campaign with neither stays a draft.
+func ValidateCampaign(campaign *models.Campaign, sendAt string, now time.Time) error {
+	switch {
+	case campaign.Name == "":
+		return ErrMissingName
+	case campaign.Subject == "":
+		return &ValidationError{Message: "missing subject", Code: "missing_subject"}
+	case strings.TrimSpace(campaign.Text) == "" && strings.TrimSpace(campaign.HTML) == "":
+		return &ValidationError{Message: "missing text or html", Code: "missing_content"}
+	case len(campaign.Text) > maxCampaignBody || len(campaign.HTML) > maxCampaignBody:
+		return &ValidationError{Message: "text and html are limited to 512KB each", Code: "content_too_large"}
+	}
+	if err := validateABTest(campaign); err != nil {
+		return err
+	}
+	if _, err := campaigns.Location(campaign.Timezone); err != nil {
+		return invalidSchedule(err.Error())
+	}
+
+	campaign.NextRunAt = nil
+	if sendAt = strings.TrimSpace(sendAt); sendAt != "" {
+		at, err := campaigns.ParseSendAt(sendAt, campaign.Timezone)
+		if err != nil {
+			return invalidSchedule(err.Error())
+		}
+		// a minute of leeway for "now"
+		if at.Before(now.Add(-time.Minute)) {
+			return invalidSchedule("send_at is in the past")
+		}
+		at = at.UTC()
+		campaign.NextRunAt = &at
+	}
+	if campaign.Recurrence != "" {
+		next, err := campaigns.NextRun(campaign.Recurrence, campaign.Timezone, now)
+		if err != nil {
+			return invalidSchedule(err.Error())
+		}
+		if campaign.NextRunAt == nil {
+			campaign.NextRunAt = &next
+		}
+	}
+	return nil
+}
+
+// validateABTest checks the A/B test of a campaign, if any, defaulting its duration
+func validateABTest(campaign *models.Campaign) error {
+	switch {
+	case campaign.SubjectB == "" && campaign.TestPercent == 0:
+		campaign.TestHours = 0
+		return nil
+	case campaign.SubjectB == "":
+		return invalidABTest("subject_b is required to A/B test the subject")
+	case campaign.SubjectB == campaign.Subject:
+		return invalidABTest("subject_b must differ from subject")
+	case campaign.TestPercent < minTestPercent || campaign.TestPercent > maxTestPercent:
+		return invalidABTest(fmt.Sprintf("test_percent must be between %d and %d", minTestPercent, maxTestPercent))
+	case campaign.TestHours < 0 || campaign.TestHours > maxTestHours:
+		return invalidABTest(fmt.Sprintf("test_hours must be between 1 and %d", maxTestHours))
+	}
+	if campaign.TestHours == 0 {
+		campaign.TestHours = defaultTestHours
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"log"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultDisposableDomains is a short list of the most common throwaway providers.
+// EMAIL_DOMAIN_BLOCKLIST and EMAIL_DOMAIN_BLOCKLIST_FILE extend it.
+var defaultDisposableDomains = []string{
+	"mailinator.com",
+	"guerrillamail.com",
+	"guerrillamail.net",
+	"sharklasers.com",
+	"10minutemail.com",
+	"temp-mail.org",
+	"tempmail.com",
+	"throwawaymail.com",
+	"yopmail.com",
+	"trashmail.com",
+	"getnada.com",
+	"dispostable.com",
+	"maildrop.cc",
+	"fakeinbox.com",
+}
+
+var (
+	blockedDomains     map[string]bool
+	blockedDomainsOnce sync.Once
+)
+
+// loadBlockedDomains merges the built-in list with EMAIL_DOMAIN_BLOCKLIST (comma separated)
+// and EMAIL_DOMAIN_BLOCKLIST_FILE (one domain per line, # comments allowed)
+func loadBlockedDomains() map[string]bool {
+	blockedDomainsOnce.Do(func() {
+		blockedDomains = map[string]bool{}
+		add := func(d string) {
+			if d = strings.ToLower(strings.TrimSpace(d)); d != "" && !strings.HasPrefix(d, "#") {
+				blockedDomains[d] = true
+			}
+		}
+
+		for _, d := range defaultDisposableDomains {
+			add(d)
+		}
+		for _, d := range strings.Split(os.Getenv("EMAIL_DOMAIN_BLOCKLIST"), ",") {
+			add(d)
+		}
+		if path := os.Getenv("EMAIL_DOMAIN_BLOCKLIST_FILE"); path != "" {
+			f, err := os.Open(path)
+			if err != nil {
+				log.Printf("[WARN] could not read EMAIL_DOMAIN_BLOCKLIST_FILE: %v", err)
+				return
+			}
+			defer f.Close()
+			scanner := bufio.NewScanner(f)
+			for scanner.Scan() {
+				add(scanner.Text())
+			}
+		}
+	})
+	return blockedDomains
+}
+
+// isBlockedDomain matches the domain and every parent domain, so sub.mailinator.com is blocked too
+func isBlockedDomain(domain string) bool {
+	blocked := loadBlockedDomains()
+	for d := domain; d != ""; {
+		if blocked[d] {
+			return true
+		}
+		i := strings.IndexByte(d, '.')
+		if i < 0 {
+			break
+		}
+		d = d[i+1:]
+	}
+	return false
+}
+
+// domainAcceptsMail looks up the MX records of domain, falling back to its A/AAAA records
+// (the implicit MX of RFC 5321). DNS failures other than "not found" don't reject the address.
+func domainAcceptsMail(domain string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	mxs, err := net.DefaultResolver.LookupMX(ctx, domain)
+	if err == nil {
+		// a single "." record is a null MX: the domain explicitly accepts no mail
+		return !(len(mxs) == 1 && mxs[0].Host == ".")
+	}
+	var dnsErr *net.DNSError
+	if !errors.As(err, &dnsErr) || !dnsErr.IsNotFound {
+		log.Printf("[WARN] MX lookup for %s failed, accepting the address: %v", domain, err)
+		return true
+	}
+
+	addrs, err := net.DefaultResolver.LookupHost(ctx, domain)
+	return err == nil && len(addrs) > 0
+}
+
+// checkEmailDomain rejects disposable domains and, with EMAIL_MX_CHECK=true, domains that can't receive mail
+func checkEmailDomain(email string) error {
+	at := strings.LastIndexByte(email, '@')
+	if at < 0 {
+		return nil
+	}
+	domain := strings.ToLower(strings.TrimSuffix(email[at+1:], "."))
+
+	if isBlockedDomain(domain) {
+		return ErrDisposableEmail
+	}
+	if os.Getenv("EMAIL_MX_CHECK") == "true" && !domainAcceptsMail(domain) {
+		return ErrUndeliverableEmail
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"slices"
+	"strings"
+
+	"fiber-gorm-api/internal/models"
+	"fiber-gorm-api/internal/templates"
+)
+
+// ValidateEmailTemplate checks an email template before it's saved, as the content of a
+// campaign, then compiles the HTML of one authored in Markdown or MJML and checks that it
+// parses, returning a *templates.Error when it doesn't
+func ValidateEmailTemplate(tmpl *models.EmailTemplate) error {
+	if tmpl.Format == "" {
+		tmpl.Format = templates.FormatHTML
+	}
+	compiled := tmpl.Format != templates.FormatHTML
+	switch {
+	case tmpl.Name == "":
+		return ErrMissingName
+	case tmpl.Subject == "":
+		return &ValidationError{Message: "missing subject", Code: "missing_subject"}
+	case !slices.Contains(templates.Formats, tmpl.Format):
+		return &ValidationError{Message: "format must be html, markdown or mjml", Code: "invalid_format"}
+	case compiled && strings.TrimSpace(tmpl.Source) == "":
+		return &ValidationError{Message: "missing source", Code: "missing_source"}
+	case !compiled && strings.TrimSpace(tmpl.Text) == "" && strings.TrimSpace(tmpl.HTML) == "":
+		return &ValidationError{Message: "missing text or html", Code: "missing_content"}
+	case len(tmpl.Text) > maxCampaignBody || len(tmpl.HTML) > maxCampaignBody || len(tmpl.Source) > maxCampaignBody:
+		return &ValidationError{Message: "text, html and source are limited to 512KB each", Code: "content_too_large"}
+	}
+	if !compiled {
+		tmpl.Source = ""
+	}
+	if err := templates.Compile(tmpl); err != nil {
+		return err
+	}
+	return templates.Check(*tmpl)
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"strings"
+
+	"fiber-gorm-api/internal/campaigns"
+	"fiber-gorm-api/internal/models"
+)
+
+// Bounds of the fields of an event, those of its columns
+const (
+	maxEventTitle       = 255
+	maxEventLocation    = 255
+	maxEventURL         = 2048
+	maxEventDescription = 64 * 1024
+)
+
+// invalidEventTime rejects when an event takes place
+func invalidEventTime(message string) error {
+	return &ValidationError{Message: message, Code: "invalid_time"}
+}
+
+// ValidateEvent checks an event before it's saved and sets when it starts and ends: at startsAt
+// and endsAt (see campaigns.ParseSendAt) in its timezone, stored in UTC
+func ValidateEvent(event *models.Event, startsAt, endsAt string) error {
+	switch {
+	case event.Title == "":
+		return &ValidationError{Message: "missing title", Code: "missing_title"}
+	case len(event.Title) > maxEventTitle || len(event.Location) > maxEventLocation:
+		return &ValidationError{Message: "title and location are limited to 255 characters", Code: "too_long"}
+	case len(event.Description) > maxEventDescription:
+		return &ValidationError{Message: "description is limited to 64KB", Code: "too_long"}
+	case event.URL != "" && !httpURL(event.URL, maxEventURL):
+		return &ValidationError{Message: "url must be an http(s) URL of at most 2048 characters", Code: "invalid_url"}
+	}
+	if event.Timezone == "" {
+		event.Timezone = "UTC"
+	}
+	if _, err := campaigns.Location(event.Timezone); err != nil {
+		return invalidEventTime(err.Error())
+	}
+
+	startsAt, endsAt = strings.TrimSpace(startsAt), strings.TrimSpace(endsAt)
+	if startsAt == "" || endsAt == "" {
+		return invalidEventTime("starts_at and ends_at are required")
+	}
+	start, err := campaigns.ParseSendAt(startsAt, event.Timezone)
+	if err != nil {
+		return invalidEventTime("starts_at must be an RFC 3339 time or a local 2006-01-02T15:04")
+	}
+	end, err := campaigns.ParseSendAt(endsAt, event.Timezone)
+	if err != nil {
+		return invalidEventTime("ends_at must be an RFC 3339 time or a local 2006-01-02T15:04")
+	}
+	if !end.After(start) {
+		return invalidEventTime("ends_at must be after starts_at")
+	}
+	event.StartsAt, event.EndsAt = start.UTC(), end.UTC()
+	return nil
+}
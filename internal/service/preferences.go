@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"fiber-gorm-api/internal/cache"
+	"fiber-gorm-api/internal/models"
+	redisclient "fiber-gorm-api/internal/redis"
+	"fiber-gorm-api/internal/repository"
+	sendgridservice "fiber-gorm-api/internal/services"
+	"fiber-gorm-api/internal/telemetry"
+)
+
+// Preferences links let a subscriber change their name, subscriber_types and subscription
+// without an account. The token is random; Redis maps its hash to the subscriber until the
+// link expires, so nothing is stored in the database and a link can be revoked by deleting
+// its key.
+
+const (
+	defaultPreferencesLinkTTL = 30 * 24 * time.Hour
+	defaultPreferencesBaseURL = "https://signup.mylocal.ing/preferences/"
+	// preferencesEmailCooldown is the least time between two preferences emails to a subscriber
+	preferencesEmailCooldown = 5 * time.Minute
+)
+
+// ErrInvalidPreferencesLink is returned for preferences tokens that were never issued, have
+// expired or whose subscriber is gone
+var ErrInvalidPreferencesLink = errors.New("preferences link is invalid or has expired")
+
+// UpdatePreferencesInput is what a subscriber may change about themselves on the preferences page
+type UpdatePreferencesInput struct {
+	// Name replaces the name unless nil
+	Name *string
+	// SubscriberTypes replaces the subscriber_types unless nil; an empty slice removes them all.
+	// Types the subscriber keeps keep the preferences they're given without.
+	SubscriberTypes []models.SubscriberType
+	// Subscribed false unsubscribes, true subscribes again after an unsubscribe; nil leaves
+	// the status as is
+	Subscribed *bool
+}
+
+func (s *subscriberService) PreferencesLink(ctx context.Context, orgID, id uint) (_ string, _ time.Time, err error) {
+	ctx, span := telemetry.Start(ctx, "subscriber.preferences_link")
+	defer telemetry.End(span, &err)
+
+	subscriber, err := s.repo.Find(ctx, orgID, id)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	if subscriber.AnonymizedAt != nil {
+		return "", time.Time{}, ErrAnonymized
+	}
+	return s.issuePreferencesLink(ctx, subscriber)
+}
+
+func (s *subscriberService) SendPreferencesLink(ctx context.Context, orgID uint, email string) (err error) {
+	ctx, span := telemetry.Start(ctx, "subscriber.send_preferences_link")
+	defer telemetry.End(span, &err)
+
+	// unknown addresses look the same to the caller as known ones
+	subscriber, err := s.repo.FindByEmail(ctx, orgID, email)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if subscriber.AnonymizedAt != nil {
+		return nil
+	}
+
+	// one email per cooldown, so the public endpoint can't be used to flood an inbox
+	sentKey := preferencesSentKey(subscriber.ID)
+	if sent, _ := redisclient.GetValue(ctx, sentKey); sent != "" {
+		return nil
+	}
+	link, _, err := s.issuePreferencesLink(ctx, subscriber)
+	if err != nil {
+		return err
+	}
+	if err := redisclient.SetValue(ctx, sentKey, "1", preferencesEmailCooldown); err != nil {
+		return err
+	}
+	return telemetry.Trace(ctx, "sendgrid.send_preferences", func() error {
+		return sendgridservice.SendPreferencesEmailFunc(subscriber.Email, link)
+	})
+}
+
+func (s *subscriberService) Preferences(ctx context.Context, token string) (_ *models.Subscriber, err error) {
+	ctx, span := telemetry.Start(ctx, "subscriber.preferences")
+	defer telemetry.End(span, &err)
+
+	return s.preferencesSubscriber(ctx, token)
+}
+
+func (s *subscriberService) UpdatePreferences(ctx context.Context, token string, in UpdatePreferencesInput) (_ *models.Subscriber, err error) {
+	ctx, span := telemetry.Start(ctx, "subscriber.update_preferences")
+	defer telemetry.End(span, &err)
+
+	subscriber, err := s.preferencesSubscriber(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if in.Name != nil {
+		if strings.TrimSpace(*in.Name) == "" {
+			return nil, ErrMissingName
+		}
+		subscriber.Name = *in.Name
+	}
+	for _, t := range in.SubscriberTypes {
+		if err := validatePreferences(t); err != nil {
+			return nil, err
+		}
+	}
+	if err := CheckSubscriberTypes(ctx, s.repo, in.SubscriberTypes); err != nil {
+		return nil, err
+	}
+	if in.Subscribed != nil {
+		switch {
+		case !*in.Subscribed:
+			subscriber.Status = models.SubscriberStatusUnsubscribed
+		case subscriber.Status == models.SubscriberStatusUnsubscribed:
+			// a bounced address stays bounced, a pending one still has to confirm
+			subscriber.Status = models.SubscriberStatusActive
+		}
+	}
+
+	if err := s.repo.UpdatePreferences(ctx, subscriber, in.SubscriberTypes); err != nil {
+		return nil, err
+	}
+
+	cache.InvalidateSubscriber(ctx, subscriber.ID)
+	return s.repo.Find(ctx, subscriber.OrgID, subscriber.ID)
+}
+
+// issuePreferencesLink stores a new preferences token of subscriber in Redis and returns its
+// link and expiry
+func (s *subscriberService) issuePreferencesLink(ctx context.Context, subscriber *models.Subscriber) (string, time.Time, error) {
+	token := randomToken(32)
+	ttl := preferencesLinkTTL()
+	value := fmt.Sprintf("%d:%d", subscriber.OrgID, subscriber.ID)
+	if err := redisclient.SetValue(ctx, preferencesLinkKey(token), value, ttl); err != nil {
+		return "", time.Time{}, err
+	}
+	return preferencesLink(token), s.now().Add(ttl), nil
+}
+
+// preferencesSubscriber loads the subscriber behind a preferences token
+func (s *subscriberService) preferencesSubscriber(ctx context.Context, token string) (*models.Subscriber, error) {
+	value, err := redisclient.GetValue(ctx, preferencesLinkKey(token))
+	if err != nil || value == "" {
+		return nil, ErrInvalidPreferencesLink
+	}
+	org, id, _ := strings.Cut(value, ":")
+	orgID, errOrg := strconv.ParseUint(org, 10, 64)
+	subscriberID, errID := strconv.ParseUint(id, 10, 64)
+	if errOrg != nil || errID != nil {
+		return nil, ErrInvalidPreferencesLink
+	}
+
+	subscriber, err := s.repo.Find(ctx, uint(orgID), uint(subscriberID))
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrInvalidPreferencesLink
+	}
+	if err != nil {
+		return nil, err
+	}
+	if subscriber.AnonymizedAt != nil {
+		return nil, ErrInvalidPreferencesLink
+	}
+	return subscriber, nil
+}
+
+// preferencesLinkKey is the Redis key holding "orgID:id" of the subscriber a preferences token
+// was issued to. Only the hash of the token is stored.
+func preferencesLinkKey(token string) string {
+	return "preferences_link:" + hashToken(token)
+}
+
+// preferencesSentKey is the Redis key set while a subscriber's preferences email cools down
+func preferencesSentKey(id uint) string {
+	return "preferences_sent:" + strconv.FormatUint(uint64(id), 10)
+}
+
+// preferencesLinkTTL is how long a preferences link stays valid, from PREFERENCES_LINK_TTL_DAYS
+func preferencesLinkTTL() time.Duration {
+	if n, err := strconv.Atoi(os.Getenv("PREFERENCES_LINK_TTL_DAYS")); err == nil && n > 0 {
+		return time.Duration(n) * 24 * time.Hour
+	}
+	return defaultPreferencesLinkTTL
+}
+
+// preferencesLink is the URL of a preferences page, PREFERENCES_URL followed by the token
+func preferencesLink(token string) string {
+	base := os.Getenv("PREFERENCES_URL")
+	if base == "" {
+		base = defaultPreferencesBaseURL
+	}
+	return base + token
+}
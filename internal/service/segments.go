@@ -0,0 +1,80 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"fiber-gorm-api/internal/filterexpr"
+	"fiber-gorm-api/internal/repository"
+)
+
+// maxSegmentTags bounds the tags a segment matches
+const maxSegmentTags = 50
+
+// invalidFilter rejects the filter of a segment
+func invalidFilter(message string) error {
+	return &ValidationError{Message: message, Code: "invalid_filter"}
+}
+
+// ValidateSegmentFilter checks the filter of a segment before it's saved or previewed: it may
+// only name statuses, frequencies, channels and subscriber_types (among accepted) that exist,
+// its signup dates must be in order and its query must parse, an error of the query being a
+// *filterexpr.Error. Blank and repeated tags and subscriber_types are dropped.
+func ValidateSegmentFilter(filter *repository.SubscriberFilter, accepted []string) error {
+	trimmed := func(values []string) []string {
+		var out []string
+		for _, v := range values {
+			if v = strings.TrimSpace(v); v != "" && !slices.Contains(out, v) {
+				out = append(out, v)
+			}
+		}
+		return out
+	}
+	filter.SubscriberTypes = trimmed(filter.SubscriberTypes)
+	filter.Tags = trimmed(filter.Tags)
+	filter.Query = strings.TrimSpace(filter.Query)
+	if len(filter.Tags) > maxSegmentTags {
+		return invalidFilter(fmt.Sprintf("a segment matches at most %d tags", maxSegmentTags))
+	}
+	if _, err := filter.Scope(); err != nil {
+		var exprErr *filterexpr.Error
+		if errors.As(err, &exprErr) {
+			return err
+		}
+		return invalidFilter(err.Error())
+	}
+	if err := ValidateFilterExpr(filter.Query, accepted); err != nil {
+		return err
+	}
+
+	var invalid []string
+	for _, name := range append(slices.Clone(filter.SubscriberTypes), filter.SubscriberType) {
+		if name != "" && !slices.Contains(accepted, name) && !slices.Contains(invalid, name) {
+			invalid = append(invalid, name)
+		}
+	}
+	if len(invalid) > 0 {
+		return &InvalidTypesError{Invalid: invalid, Accepted: accepted}
+	}
+	return nil
+}
+
+// ValidateFilterExpr checks a filter expression, which may only name the subscriber_types among
+// accepted. Errors are *filterexpr.Error.
+func ValidateFilterExpr(expr string, accepted []string) error {
+	if strings.TrimSpace(expr) == "" {
+		return nil
+	}
+	if _, err := repository.ExprScope(expr); err != nil {
+		return err
+	}
+	tree, _ := filterexpr.Parse(expr)
+	for _, c := range repository.ExprTypes(tree) {
+		if !slices.Contains(accepted, c.Value) {
+			return c.ValueErrorf("Unknown subscriber_type %s, expected one of %s", c.Value, strings.Join(accepted, ", "))
+		}
+	}
+	return nil
+}
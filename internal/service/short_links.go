@@ -0,0 +1,36 @@
+package service
+
+import (
+	"net/url"
+	"regexp"
+
+	"fiber-gorm-api/internal/models"
+)
+
+// maxShortLinkURL bounds the URL a short link redirects to
+const maxShortLinkURL = 2048
+
+var shortLinkCodePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,30}[a-z0-9]$`)
+
+var (
+	ErrInvalidShortLinkCode = &ValidationError{Message: "code must be 3 to 32 lowercase letters, digits and dashes", Code: "invalid_code"}
+	ErrInvalidShortLinkURL  = &ValidationError{Message: "url must be an http(s) URL of at most 2048 characters", Code: "invalid_url"}
+)
+
+// ValidateShortLink checks a short link before it's saved: its code, unless one is to be
+// generated, and the URL it redirects to
+func ValidateShortLink(link *models.ShortLink) error {
+	if link.Code != "" && !shortLinkCodePattern.MatchString(link.Code) {
+		return ErrInvalidShortLinkCode
+	}
+	if !httpURL(link.URL, maxShortLinkURL) {
+		return ErrInvalidShortLinkURL
+	}
+	return nil
+}
+
+// httpURL reports whether raw is an absolute http(s) URL of at most max characters
+func httpURL(raw string, max int) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" && len(raw) <= max
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"fiber-gorm-api/internal/models"
+)
+
+// Limits of signup forms
+const (
+	maxFormFields       = 20
+	maxFieldOptions     = 50
+	maxFieldValueLength = 500
+)
+
+var (
+	formSlugPattern  = regexp.MustCompile(`^[a-z0-9](?:[a-z0-9-]{0,62}[a-z0-9])?$`)
+	fieldNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,31}$`)
+
+	// ReservedFieldNames are the parameters of a submission custom fields can't shadow
+	ReservedFieldNames = []string{
+		"email", "name", "subscriber_types", "fields", "locale", "captcha_token",
+		"utm_source", "utm_medium", "utm_campaign", "referrer", "ref",
+	}
+)
+
+var (
+	ErrInvalidFormSlug = &ValidationError{Message: "slug must be 1 to 64 lowercase letters, digits and dashes", Code: "invalid_slug"}
+	ErrInvalidRedirect = &ValidationError{Message: "success_redirect must be an http(s) URL", Code: "invalid_redirect"}
+)
+
+// invalidField rejects the definition, or the value, of a custom field
+func invalidField(format string, args ...interface{}) error {
+	return &ValidationError{Message: fmt.Sprintf(format, args...), Code: "invalid_field"}
+}
+
+// ValidateSignupForm checks a form before it's saved: its slug, name, custom fields and success
+// redirect, and that it only offers subscriber_types of accepted. Options of fields that aren't
+// selects are dropped.
+func ValidateSignupForm(form *models.SignupForm, accepted []string) error {
+	if !formSlugPattern.MatchString(form.Slug) {
+		return ErrInvalidFormSlug
+	}
+	if strings.TrimSpace(form.Name) == "" {
+		return ErrMissingName
+	}
+
+	var invalid []string
+	for _, name := range form.TypeNames() {
+		if !slices.Contains(accepted, name) {
+			invalid = append(invalid, name)
+		}
+	}
+	if len(invalid) > 0 {
+		return &InvalidTypesError{Invalid: invalid, Accepted: accepted}
+	}
+
+	if len(form.Fields) > maxFormFields {
+		return invalidField("a form has at most %d fields", maxFormFields)
+	}
+	seen := map[string]bool{}
+	for i, field := range form.Fields {
+		switch {
+		case !fieldNamePattern.MatchString(field.Name):
+			return invalidField("field name %q must be a lowercase letter then up to 31 letters, digits and underscores", field.Name)
+		case slices.Contains(ReservedFieldNames, field.Name):
+			return invalidField("field name %q is reserved", field.Name)
+		case seen[field.Name]:
+			return invalidField("field %s is defined twice", field.Name)
+		case !slices.Contains(models.FieldTypes, field.Type):
+			return invalidField("field %s has type %q, expected one of %s", field.Name, field.Type, strings.Join(models.FieldTypes, ", "))
+		case field.Type == models.FieldSelect && (len(field.Options) == 0 || len(field.Options) > maxFieldOptions):
+			return invalidField("select field %s needs 1 to %d options", field.Name, maxFieldOptions)
+		}
+		seen[field.Name] = true
+		if field.Type != models.FieldSelect {
+			form.Fields[i].Options = nil
+		}
+	}
+
+	if form.SuccessRedirect != "" {
+		u, err := url.Parse(form.SuccessRedirect)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return ErrInvalidRedirect
+		}
+	}
+	return nil
+}
+
+// SignupFormTypes returns the subscriber_types a submission of form picked, all those the form
+// offers when it picked none
+func SignupFormTypes(form models.SignupForm, picked []string) ([]models.SubscriberType, error) {
+	offered := form.TypeNames()
+	if len(picked) == 0 {
+		picked = offered
+	}
+	var invalid []string
+	types := make([]models.SubscriberType, 0, len(picked))
+	for _, name := range picked {
+		if !slices.Contains(offered, name) {
+			invalid = append(invalid, name)
+			continue
+		}
+		types = append(types, models.SubscriberType{Name: name})
+	}
+	if len(invalid) > 0 {
+		return nil, &InvalidTypesError{Invalid: invalid, Accepted: offered}
+	}
+	return types, nil
+}
+
+// SignupFormValues checks the custom field values of a submission of form and returns them
+// typed, for the subscriber's metadata. HTML forms post every value as a string: numbers and
+// checkboxes ("on", "true" or "1") are parsed from them. A required checkbox must be checked.
+func SignupFormValues(form models.SignupForm, values map[string]interface{}) (models.JSONMap, error) {
+	for name := range values {
+		if !slices.ContainsFunc(form.Fields, func(f models.SignupFormField) bool { return f.Name == name }) {
+			return nil, &ValidationError{Message: "unknown field " + name, Code: "unknown_field"}
+		}
+	}
+
+	out := models.JSONMap{}
+	for _, field := range form.Fields {
+		raw, present := values[field.Name]
+		if s, ok := raw.(string); ok {
+			raw = strings.TrimSpace(s)
+			present = raw != ""
+		}
+		if !present || raw == nil {
+			if field.Required {
+				return nil, &ValidationError{Message: "missing field " + field.Name, Code: "missing_field"}
+			}
+			continue
+		}
+
+		value, err := fieldValue(field, raw)
+		if err != nil {
+			return nil, err
+		}
+		if field.Required && value == false {
+			return nil, &ValidationError{Message: "field " + field.Name + " must be checked", Code: "missing_field"}
+		}
+		out[field.Name] = value
+	}
+	return out, nil
+}
+
+// fieldValue returns raw, present and trimmed if a string, as the value of field
+func fieldValue(field models.SignupFormField, raw interface{}) (interface{}, error) {
+	switch field.Type {
+	case models.FieldNumber:
+		switch v := raw.(type) {
+		case float64:
+			return v, nil
+		case string:
+			if n, err := strconv.ParseFloat(v, 64); err == nil {
+				return n, nil
+			}
+		}
+		return nil, invalidField("field %s must be a number", field.Name)
+	case models.FieldCheckbox:
+		switch v := raw.(type) {
+		case bool:
+			return v, nil
+		case string:
+			return v == "on" || v == "true" || v == "1", nil
+		}
+		return nil, invalidField("field %s must be true or false", field.Name)
+	case models.FieldSelect:
+		if v, ok := raw.(string); ok && slices.Contains(field.Options, v) {
+			return v, nil
+		}
+		return nil, invalidField("field %s must be one of %s", field.Name, strings.Join(field.Options, ", "))
+	default:
+		v, ok := raw.(string)
+		if !ok || len(v) > maxFieldValueLength {
+			return nil, invalidField("field %s must be a text of at most %d characters", field.Name, maxFieldValueLength)
+		}
+		return v, nil
+	}
+}
@@ -0,0 +1,731 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"fiber-gorm-api/internal/cache"
+	"fiber-gorm-api/internal/i18n"
+	"fiber-gorm-api/internal/models"
+	"fiber-gorm-api/internal/repository"
+	sendgridservice "fiber-gorm-api/internal/services"
+	"fiber-gorm-api/internal/telemetry"
+)
+
+// ValidationError rejects the input of a subscriber write. Code is set for problems a
+// frontend tells apart from typos.
+type ValidationError struct {
+	Message string
+	Code    string
+}
+
+func (e *ValidationError) Error() string { return e.Message }
+
+// InvalidTypesError rejects subscriber_types names that aren't defined. It unwraps to
+// ErrInvalidType.
+type InvalidTypesError struct {
+	Invalid  []string
+	Accepted []string
+}
+
+func (e *InvalidTypesError) Error() string {
+	return fmt.Sprintf("unknown subscriber_type %s, accepted are %s",
+		strings.Join(e.Invalid, ", "), strings.Join(e.Accepted, ", "))
+}
+
+func (e *InvalidTypesError) Unwrap() error { return ErrInvalidType }
+
+var (
+	ErrInvalidEmail       = &ValidationError{Message: "invalid or missing email"}
+	ErrMissingName        = &ValidationError{Message: "missing name"}
+	ErrInvalidType        = &ValidationError{Message: "unknown subscriber_type", Code: "invalid_subscriber_type"}
+	ErrDisposableEmail    = &ValidationError{Message: "disposable email addresses are not accepted", Code: "disposable_email"}
+	ErrUndeliverableEmail = &ValidationError{Message: "email domain does not accept mail", Code: "undeliverable_email_domain"}
+	ErrMetadataTooLarge   = &ValidationError{Message: "metadata is too large", Code: "metadata_too_large"}
+	ErrMergeIntoSelf      = &ValidationError{Message: "cannot merge a subscriber into itself", Code: "merge_into_self"}
+	ErrInvalidEmailRule   = &ValidationError{Message: "email_rule must be target, source or verified", Code: "invalid_email_rule"}
+	ErrInvalidFrequency   = &ValidationError{Message: "frequency must be daily, weekly or monthly", Code: "invalid_frequency"}
+	ErrInvalidChannel     = &ValidationError{Message: "channel must be email or sms", Code: "invalid_channel"}
+	ErrInvalidLocale      = &ValidationError{Message: "locale must be en, fr or es", Code: "invalid_locale"}
+)
+
+var (
+	// ErrNotFound is returned for subscribers that don't exist in the caller's organization
+	ErrNotFound = repository.ErrNotFound
+	// ErrVersionConflict is returned when the subscriber was modified by someone else
+	ErrVersionConflict = repository.ErrVersionConflict
+	// ErrInvalidToken is returned for unknown or already used confirmation tokens
+	ErrInvalidToken = errors.New("confirmation link is invalid or was already used")
+	// ErrTokenExpired is returned for confirmation tokens older than the confirmation TTL
+	ErrTokenExpired = errors.New("confirmation link has expired")
+	// ErrNotPending is returned when resending the confirmation of a subscriber who isn't
+	// waiting for one (already confirmed, unsubscribed, ...)
+	ErrNotPending = errors.New("subscriber is not pending confirmation")
+	// ErrAnonymized is returned when merging or restoring a subscriber whose personal data was erased
+	ErrAnonymized = errors.New("subscriber is anonymized")
+	// ErrRevisionNotFound is returned for revisions that don't belong to the subscriber
+	ErrRevisionNotFound = errors.New("revision not found")
+	// ErrUndoWindowExpired is returned when undeleting a subscriber deleted longer than the
+	// DeletionWindow ago, due to be purged
+	ErrUndoWindowExpired = errors.New("subscriber was deleted too long ago to be undeleted")
+	// ErrNotQuarantined is returned when reviewing a subscriber who isn't quarantined
+	ErrNotQuarantined = errors.New("subscriber is not quarantined")
+)
+
+// Email rules of a merge: whose address, with its verification and status, the merged
+// subscriber keeps
+const (
+	MergeKeepTarget   = "target" // the default
+	MergeKeepSource   = "source"
+	MergeKeepVerified = "verified" // the verified one; the target's when both or neither are
+)
+
+// ResendTooSoonError is returned when a confirmation email was sent less than the resend
+// cooldown ago
+type ResendTooSoonError struct {
+	RetryAfter time.Duration
+}
+
+func (e *ResendTooSoonError) Error() string { return "confirmation email was sent recently" }
+
+const (
+	defaultConfirmationTTL     = 7 * 24 * time.Hour
+	defaultConfirmationBaseURL = "https://signup.mylocal.ing/confirm/"
+	defaultResendCooldown      = 5 * time.Minute
+	defaultDeletionWindowDays  = 30
+	// maxMetadataBytes bounds the JSON encoding of a subscriber's metadata
+	maxMetadataBytes = 16 * 1024
+)
+
+// A more robust email regex to ensure an address-like format.
+// (Though there's no perfect regex for all valid emails, this is a decent approach.)
+var emailRegex = regexp.MustCompile(`^[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}$`)
+
+// IsValidEmail reports whether email looks like an address
+func IsValidEmail(email string) bool {
+	return emailRegex.MatchString(email)
+}
+
+// ValidateSubscriber performs stricter checks on email and name. The subscriber_types are
+// checked against the database by CheckSubscriberTypes.
+func ValidateSubscriber(sub *models.Subscriber) error {
+	// Email must not be empty, must contain '@', must match our robust pattern
+	if sub.Email == "" || !IsValidEmail(sub.Email) {
+		return ErrInvalidEmail
+	}
+
+	// No throwaway or undeliverable domains (see email_domain.go)
+	if err := checkEmailDomain(sub.Email); err != nil {
+		return err
+	}
+
+	// Name must be non-empty
+	if strings.TrimSpace(sub.Name) == "" {
+		return ErrMissingName
+	}
+
+	// Metadata is free-form, but not a place to store documents
+	if sub.Metadata != nil {
+		raw, err := json.Marshal(sub.Metadata)
+		if err != nil || len(raw) > maxMetadataBytes {
+			return ErrMetadataTooLarge
+		}
+	}
+
+	// An empty locale keeps the current one, or the default of a new subscriber
+	if sub.Locale != "" && !i18n.Supported(sub.Locale) {
+		return ErrInvalidLocale
+	}
+
+	// Empty preferences keep the current ones (see SubscriberTypeRequest)
+	for _, t := range sub.SubscriberTypes {
+		if err := validatePreferences(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validatePreferences checks the frequency and channel of a subscriber_type, when set
+func validatePreferences(t models.SubscriberType) error {
+	if t.Frequency != "" && !slices.Contains(models.Frequencies, t.Frequency) {
+		return ErrInvalidFrequency
+	}
+	if t.Channel != "" && !slices.Contains(models.Channels, t.Channel) {
+		return ErrInvalidChannel
+	}
+	return nil
+}
+
+// CheckSubscriberTypes rejects subscriber_types whose name isn't defined (see
+// /admin/subscriber-types) with an *InvalidTypesError listing every unknown name
+func CheckSubscriberTypes(ctx context.Context, repo repository.SubscriberRepository, types []models.SubscriberType) error {
+	if len(types) == 0 {
+		return nil
+	}
+	accepted, err := repo.TypeNames(ctx)
+	if err != nil {
+		return err
+	}
+	var invalid []string
+	for _, t := range types {
+		if !slices.Contains(accepted, t.Name) && !slices.Contains(invalid, t.Name) {
+			invalid = append(invalid, t.Name)
+		}
+	}
+	if len(invalid) > 0 {
+		return &InvalidTypesError{Invalid: invalid, Accepted: accepted}
+	}
+	return nil
+}
+
+// CreateSubscriberInput is a new subscriber of an organization
+type CreateSubscriberInput struct {
+	OrgID           uint
+	Email           string
+	Name            string
+	SubscriberTypes []models.SubscriberType
+	Metadata        models.JSONMap
+	// Locale is the language of the emails sent to the subscriber, i18n.DefaultLocale if empty
+	Locale string
+	// DoubleOptIn keeps the subscriber pending until the emailed confirmation link is opened
+	DoubleOptIn bool
+	// SpamScore and SpamSignals are the spam scoring of a public signup. Quarantine creates the
+	// subscriber quarantined, without emailing a confirmation, until an admin reviews it.
+	SpamScore   int
+	SpamSignals models.JSONMap
+	Quarantine  bool
+	// Country and Region are where a public signup came from, see geoip.Location
+	Country string
+	Region  string
+	// Attribution is the channel a public signup came from, normalized before it's stored
+	Attribution Attribution
+	// Ref is the referral code of the subscriber who referred a public signup; unknown codes are
+	// ignored
+	Ref string
+}
+
+// UpdateSubscriberInput replaces the email, name and optionally the subscriber_types and
+// metadata of a subscriber
+type UpdateSubscriberInput struct {
+	OrgID uint
+	ID    uint
+	Email string
+	Name  string
+	// SubscriberTypes replaces the subscriber_types unless nil; an empty slice removes them all
+	SubscriberTypes []models.SubscriberType
+	// Metadata replaces the metadata unless nil; an empty map clears it
+	Metadata models.JSONMap
+	// Locale replaces the locale unless empty
+	Locale string
+	// Version, when set, must equal the stored version or the update fails with ErrVersionConflict
+	Version *int
+}
+
+// MergeSubscribersInput folds the duplicate SourceID into TargetID
+type MergeSubscribersInput struct {
+	OrgID    uint
+	TargetID uint
+	SourceID uint
+	// EmailRule picks the address kept, MergeKeepTarget when empty
+	EmailRule string
+	// Version, when set, must equal the target's stored version or the merge fails with ErrVersionConflict
+	Version *int
+}
+
+// SubscriberService holds the business rules of subscriber writes, independent of the transport
+type SubscriberService interface {
+	Create(ctx context.Context, in CreateSubscriberInput) (*models.Subscriber, error)
+	Get(ctx context.Context, orgID, id uint) (*models.Subscriber, error)
+	Update(ctx context.Context, in UpdateSubscriberInput) (*models.Subscriber, error)
+	Delete(ctx context.Context, orgID, id uint) error
+	// Undelete brings back a subscriber deleted less than the DeletionWindow ago, with the
+	// subscriber_types, notes and history it had
+	Undelete(ctx context.Context, orgID, id uint) (*models.Subscriber, error)
+	// Approve lets a quarantined signup through: active, or with doubleOptIn pending and
+	// emailed the confirmation link it was held back from
+	Approve(ctx context.Context, orgID, id uint, doubleOptIn bool) (*models.Subscriber, error)
+	// Reject deletes a quarantined signup; it can be undeleted like any other deletion
+	Reject(ctx context.Context, orgID, id uint) error
+	// Confirm activates the subscriber behind a double opt-in token
+	Confirm(ctx context.Context, token string) (*models.Subscriber, error)
+	// ResendConfirmation emails a pending subscriber a new confirmation link, which replaces
+	// the previous one
+	ResendConfirmation(ctx context.Context, orgID, id uint) (*models.Subscriber, error)
+	// Merge moves everything attached to the source subscriber to the target and soft-deletes
+	// the source, returning the merged target
+	Merge(ctx context.Context, in MergeSubscribersInput) (*models.Subscriber, error)
+	// Restore applies the state a subscriber had after one of its revisions, except that an
+	// opt-out or bounce since then is kept
+	Restore(ctx context.Context, orgID, id, revisionID uint) (*models.Subscriber, error)
+	// PreferencesLink returns a new link letting a subscriber manage their own preferences,
+	// and when it expires
+	PreferencesLink(ctx context.Context, orgID, id uint) (string, time.Time, error)
+	// SendPreferencesLink emails a new preferences link to the subscriber of the organization
+	// with the address, if there is one and it wasn't sent one recently
+	SendPreferencesLink(ctx context.Context, orgID uint, email string) error
+	// Preferences loads the subscriber a preferences link was issued to
+	Preferences(ctx context.Context, token string) (*models.Subscriber, error)
+	// UpdatePreferences changes the name, subscriber_types or subscription of the subscriber
+	// behind a preferences link
+	UpdatePreferences(ctx context.Context, token string, in UpdatePreferencesInput) (*models.Subscriber, error)
+}
+
+type subscriberService struct {
+	repo repository.SubscriberRepository
+	now  func() time.Time
+}
+
+// NewSubscriberService returns the SubscriberService backed by repo
+func NewSubscriberService(repo repository.SubscriberRepository) SubscriberService {
+	return &subscriberService{repo: repo, now: time.Now}
+}
+
+func (s *subscriberService) Create(ctx context.Context, in CreateSubscriberInput) (_ *models.Subscriber, err error) {
+	ctx, span := telemetry.Start(ctx, "subscriber.create")
+	defer telemetry.End(span, &err)
+
+	subscriber := &models.Subscriber{
+		OrgID:           in.OrgID,
+		Email:           in.Email,
+		Name:            in.Name,
+		Status:          models.SubscriberStatusActive,
+		SubscriberTypes: in.SubscriberTypes,
+		Metadata:        in.Metadata,
+		Locale:          in.Locale,
+		SpamScore:       in.SpamScore,
+		SpamSignals:     in.SpamSignals,
+		Country:         in.Country,
+		Region:          in.Region,
+	}
+	if err := ValidateSubscriber(subscriber); err != nil {
+		return nil, err
+	}
+	attribution, err := in.Attribution.Normalize()
+	if err != nil {
+		return nil, err
+	}
+	subscriber.UTMSource, subscriber.UTMMedium, subscriber.UTMCampaign = attribution.Source, attribution.Medium, attribution.Campaign
+	subscriber.Referrer = attribution.Referrer
+	if err := CheckSubscriberTypes(ctx, s.repo, subscriber.SubscriberTypes); err != nil {
+		return nil, err
+	}
+	// a mistyped referral link doesn't keep anyone from signing up
+	if ref := strings.ToLower(strings.TrimSpace(in.Ref)); ref != "" {
+		referrer, err := s.repo.FindByReferralCode(ctx, in.OrgID, ref)
+		switch {
+		case err == nil:
+			subscriber.ReferredByID = &referrer.ID
+		case !errors.Is(err, repository.ErrNotFound):
+			return nil, err
+		}
+	}
+	if subscriber.Locale == "" {
+		subscriber.Locale = i18n.DefaultLocale
+	}
+
+	if in.Quarantine {
+		subscriber.Status = models.SubscriberStatusQuarantined
+	}
+	if !in.DoubleOptIn || in.Quarantine {
+		if err := s.repo.Create(ctx, subscriber); err != nil {
+			return nil, err
+		}
+	} else {
+		token := randomToken(32)
+		hash := hashToken(token)
+		now := s.now()
+		subscriber.Status = models.SubscriberStatusPending
+		subscriber.ConfirmTokenHash = &hash
+		subscriber.ConfirmSentAt = &now
+
+		// a failed send rolls the insert back, so the address can sign up again
+		err := s.repo.Transaction(ctx, func(repo repository.SubscriberRepository) error {
+			if err := repo.Create(ctx, subscriber); err != nil {
+				return err
+			}
+			return sendConfirmation(ctx, subscriber, token)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	cache.InvalidateSubscriber(ctx, subscriber.ID)
+	return s.repo.Find(ctx, subscriber.OrgID, subscriber.ID)
+}
+
+func (s *subscriberService) Get(ctx context.Context, orgID, id uint) (_ *models.Subscriber, err error) {
+	ctx, span := telemetry.Start(ctx, "subscriber.get")
+	defer telemetry.End(span, &err)
+
+	return s.repo.Find(ctx, orgID, id)
+}
+
+func (s *subscriberService) Update(ctx context.Context, in UpdateSubscriberInput) (_ *models.Subscriber, err error) {
+	ctx, span := telemetry.Start(ctx, "subscriber.update")
+	defer telemetry.End(span, &err)
+
+	existing, err := s.repo.Find(ctx, in.OrgID, in.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ValidateSubscriber(&models.Subscriber{
+		Email: in.Email, Name: in.Name, SubscriberTypes: in.SubscriberTypes, Metadata: in.Metadata, Locale: in.Locale,
+	}); err != nil {
+		return nil, err
+	}
+	if err := CheckSubscriberTypes(ctx, s.repo, in.SubscriberTypes); err != nil {
+		return nil, err
+	}
+
+	// Optimistic locking: the caller may state which version it edited
+	if in.Version != nil && *in.Version != existing.Version {
+		return nil, ErrVersionConflict
+	}
+
+	// guarded by the version we loaded, so a concurrent write in between is rejected too
+	existing.Email = in.Email
+	existing.Name = in.Name
+	if in.Metadata != nil {
+		existing.Metadata = in.Metadata
+	}
+	if in.Locale != "" {
+		existing.Locale = in.Locale
+	}
+	if err := s.repo.Update(ctx, existing, in.SubscriberTypes); err != nil {
+		return nil, err
+	}
+
+	cache.InvalidateSubscriber(ctx, existing.ID)
+	return s.repo.Find(ctx, existing.OrgID, existing.ID)
+}
+
+func (s *subscriberService) Delete(ctx context.Context, orgID, id uint) (err error) {
+	ctx, span := telemetry.Start(ctx, "subscriber.delete")
+	defer telemetry.End(span, &err)
+
+	subscriber, err := s.repo.Find(ctx, orgID, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, subscriber); err != nil {
+		return err
+	}
+	cache.InvalidateSubscriber(ctx, subscriber.ID)
+	return nil
+}
+
+func (s *subscriberService) Undelete(ctx context.Context, orgID, id uint) (_ *models.Subscriber, err error) {
+	ctx, span := telemetry.Start(ctx, "subscriber.undelete")
+	defer telemetry.End(span, &err)
+
+	subscriber, err := s.repo.FindDeleted(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	// the cleanup scheduler may not have purged it yet
+	if s.now().Sub(subscriber.DeletedAt.Time) > DeletionWindow() {
+		return nil, ErrUndoWindowExpired
+	}
+	if err := s.repo.Undelete(ctx, subscriber); err != nil {
+		return nil, err
+	}
+
+	cache.InvalidateSubscriber(ctx, subscriber.ID)
+	return s.repo.Find(ctx, subscriber.OrgID, subscriber.ID)
+}
+
+func (s *subscriberService) Approve(ctx context.Context, orgID, id uint, doubleOptIn bool) (_ *models.Subscriber, err error) {
+	ctx, span := telemetry.Start(ctx, "subscriber.approve")
+	defer telemetry.End(span, &err)
+
+	subscriber, err := s.repo.Find(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	if subscriber.Status != models.SubscriberStatusQuarantined {
+		return nil, ErrNotQuarantined
+	}
+
+	if !doubleOptIn {
+		subscriber.Status = models.SubscriberStatusActive
+		err = s.repo.Approve(ctx, subscriber)
+	} else {
+		token := randomToken(32)
+		hash := hashToken(token)
+		now := s.now()
+		subscriber.Status = models.SubscriberStatusPending
+		subscriber.ConfirmTokenHash = &hash
+		subscriber.ConfirmSentAt = &now
+		// a failed send keeps the subscriber quarantined, to be approved again
+		err = s.repo.Transaction(ctx, func(repo repository.SubscriberRepository) error {
+			if err := repo.Approve(ctx, subscriber); err != nil {
+				return err
+			}
+			return sendConfirmation(ctx, subscriber, token)
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	cache.InvalidateSubscriber(ctx, subscriber.ID)
+	return s.repo.Find(ctx, orgID, id)
+}
+
+func (s *subscriberService) Reject(ctx context.Context, orgID, id uint) (err error) {
+	ctx, span := telemetry.Start(ctx, "subscriber.reject")
+	defer telemetry.End(span, &err)
+
+	subscriber, err := s.repo.Find(ctx, orgID, id)
+	if err != nil {
+		return err
+	}
+	if subscriber.Status != models.SubscriberStatusQuarantined {
+		return ErrNotQuarantined
+	}
+	if err := s.repo.Delete(ctx, subscriber); err != nil {
+		return err
+	}
+	cache.InvalidateSubscriber(ctx, subscriber.ID)
+	return nil
+}
+
+func (s *subscriberService) Confirm(ctx context.Context, token string) (_ *models.Subscriber, err error) {
+	ctx, span := telemetry.Start(ctx, "subscriber.confirm")
+	defer telemetry.End(span, &err)
+
+	subscriber, err := s.repo.FindByConfirmTokenHash(ctx, hashToken(token))
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	if subscriber.ConfirmSentAt != nil && now.After(subscriber.ConfirmSentAt.Add(confirmationTTL())) {
+		return nil, ErrTokenExpired
+	}
+
+	subscriber.EmailVerifiedAt = &now
+	// a bounce or unsubscribe that arrived in the meantime wins over the confirmation
+	if subscriber.Status == models.SubscriberStatusPending {
+		subscriber.Status = models.SubscriberStatusActive
+	}
+	if err := s.repo.Confirm(ctx, subscriber); err != nil {
+		return nil, err
+	}
+
+	cache.InvalidateSubscriber(ctx, subscriber.ID)
+	return subscriber, nil
+}
+
+func (s *subscriberService) ResendConfirmation(ctx context.Context, orgID, id uint) (_ *models.Subscriber, err error) {
+	ctx, span := telemetry.Start(ctx, "subscriber.resend_confirmation")
+	defer telemetry.End(span, &err)
+
+	subscriber, err := s.repo.Find(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	if subscriber.Status != models.SubscriberStatusPending || subscriber.ConfirmTokenHash == nil {
+		return nil, ErrNotPending
+	}
+
+	now := s.now()
+	if subscriber.ConfirmSentAt != nil {
+		if wait := subscriber.ConfirmSentAt.Add(resendCooldown()).Sub(now); wait > 0 {
+			return nil, &ResendTooSoonError{RetryAfter: wait}
+		}
+	}
+
+	// the new link restarts the confirmation TTL; a failed send keeps the previous link valid
+	token := randomToken(32)
+	hash := hashToken(token)
+	subscriber.ConfirmTokenHash = &hash
+	subscriber.ConfirmSentAt = &now
+	err = s.repo.Transaction(ctx, func(repo repository.SubscriberRepository) error {
+		if err := repo.ReissueConfirmation(ctx, subscriber); err != nil {
+			return err
+		}
+		return sendConfirmation(ctx, subscriber, token)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return subscriber, nil
+}
+
+func (s *subscriberService) Merge(ctx context.Context, in MergeSubscribersInput) (_ *models.Subscriber, err error) {
+	ctx, span := telemetry.Start(ctx, "subscriber.merge")
+	defer telemetry.End(span, &err)
+
+	if in.TargetID == in.SourceID {
+		return nil, ErrMergeIntoSelf
+	}
+	rule := in.EmailRule
+	if rule == "" {
+		rule = MergeKeepTarget
+	}
+	if !slices.Contains([]string{MergeKeepTarget, MergeKeepSource, MergeKeepVerified}, rule) {
+		return nil, ErrInvalidEmailRule
+	}
+
+	target, err := s.repo.Find(ctx, in.OrgID, in.TargetID)
+	if err != nil {
+		return nil, err
+	}
+	source, err := s.repo.Find(ctx, in.OrgID, in.SourceID)
+	if err != nil {
+		return nil, err
+	}
+	if target.AnonymizedAt != nil || source.AnonymizedAt != nil {
+		return nil, ErrAnonymized
+	}
+	if in.Version != nil && *in.Version != target.Version {
+		return nil, ErrVersionConflict
+	}
+
+	// the status belongs to the address: a bounce or an opt-out follows it
+	if rule == MergeKeepSource || (rule == MergeKeepVerified && target.EmailVerifiedAt == nil && source.EmailVerifiedAt != nil) {
+		target.Email = source.Email
+		target.EmailVerifiedAt = source.EmailVerifiedAt
+		target.Status = source.Status
+	}
+	// keys set on both keep the target's value
+	metadata := models.JSONMap{}
+	for k, v := range source.Metadata {
+		metadata[k] = v
+	}
+	for k, v := range target.Metadata {
+		metadata[k] = v
+	}
+	target.Metadata = metadata
+
+	if err := s.repo.Merge(ctx, target, source); err != nil {
+		return nil, err
+	}
+
+	cache.InvalidateSubscriber(ctx, target.ID, source.ID)
+	return s.repo.Find(ctx, target.OrgID, target.ID)
+}
+
+func (s *subscriberService) Restore(ctx context.Context, orgID, id, revisionID uint) (_ *models.Subscriber, err error) {
+	ctx, span := telemetry.Start(ctx, "subscriber.restore")
+	defer telemetry.End(span, &err)
+
+	subscriber, err := s.repo.Find(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	if subscriber.AnonymizedAt != nil {
+		return nil, ErrAnonymized
+	}
+	revision, err := s.repo.FindRevision(ctx, subscriber.ID, revisionID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrRevisionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var snapshot models.SubscriberSnapshot
+	if err := json.Unmarshal([]byte(revision.After), &snapshot); err != nil {
+		return nil, err
+	}
+
+	// an address that bounced or opted out since stays that way, whatever it was back then
+	if subscriber.Status == models.SubscriberStatusBounced || subscriber.Status == models.SubscriberStatusUnsubscribed {
+		snapshot.Status = subscriber.Status
+	}
+	types := make([]models.SubscriberType, len(snapshot.SubscriberTypes))
+	for i, name := range snapshot.SubscriberTypes {
+		types[i] = models.SubscriberType{Name: name}
+	}
+	if err := ValidateSubscriber(&models.Subscriber{
+		Email: snapshot.Email, Name: snapshot.Name, SubscriberTypes: types, Metadata: snapshot.Metadata,
+	}); err != nil {
+		return nil, err
+	}
+	// a type may have been removed since
+	if err := CheckSubscriberTypes(ctx, s.repo, types); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Restore(ctx, subscriber, snapshot); err != nil {
+		return nil, err
+	}
+
+	cache.InvalidateSubscriber(ctx, subscriber.ID)
+	return s.repo.Find(ctx, subscriber.OrgID, subscriber.ID)
+}
+
+// sendConfirmation emails the double opt-in link of token to subscriber, in their locale
+func sendConfirmation(ctx context.Context, subscriber *models.Subscriber, token string) error {
+	return telemetry.Trace(ctx, "sendgrid.send_confirmation", func() error {
+		return sendgridservice.SendConfirmationEmailFunc(subscriber.Email, subscriber.Locale, confirmationLink(token))
+	})
+}
+
+// resendCooldown is the least time between two confirmation emails to a subscriber, from
+// SUBSCRIBER_RESEND_COOLDOWN_MINUTES
+func resendCooldown() time.Duration {
+	if n, err := strconv.Atoi(os.Getenv("SUBSCRIBER_RESEND_COOLDOWN_MINUTES")); err == nil && n >= 0 {
+		return time.Duration(n) * time.Minute
+	}
+	return defaultResendCooldown
+}
+
+// confirmationTTL is how long a double opt-in link stays valid, from SUBSCRIBER_CONFIRMATION_TTL_HOURS
+func confirmationTTL() time.Duration {
+	if n, err := strconv.Atoi(os.Getenv("SUBSCRIBER_CONFIRMATION_TTL_HOURS")); err == nil && n > 0 {
+		return time.Duration(n) * time.Hour
+	}
+	return defaultConfirmationTTL
+}
+
+// DeletionWindow is how long a deleted subscriber can be undeleted before the cleanup
+// scheduler purges it for good, from SUBSCRIBER_RETENTION_DAYS
+func DeletionWindow() time.Duration {
+	days := defaultDeletionWindowDays
+	if n, err := strconv.Atoi(os.Getenv("SUBSCRIBER_RETENTION_DAYS")); err == nil && n >= 0 {
+		days = n
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// confirmationLink is the URL emailed to a new subscriber, SUBSCRIBER_CONFIRMATION_URL followed by the token
+func confirmationLink(token string) string {
+	base := os.Getenv("SUBSCRIBER_CONFIRMATION_URL")
+	if base == "" {
+		base = defaultConfirmationBaseURL
+	}
+	return base + token
+}
+
+// randomToken returns a URL-safe random string
+func randomToken(length int) string {
+	raw := make([]byte, length)
+	_, _ = rand.Read(raw)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// hashToken returns the hex SHA-256 of a confirmation token, as stored in confirm_token_hash
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,765 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"fiber-gorm-api/internal/models"
+	redisclient "fiber-gorm-api/internal/redis"
+	"fiber-gorm-api/internal/repository"
+	sendgridservice "fiber-gorm-api/internal/services"
+
+	"gorm.io/gorm"
+)
+
+// memoryRepository is an in-memory SubscriberRepository; Transaction works on a copy that is
+// only kept when fn succeeds
+type memoryRepository struct {
+	rows      map[uint]models.Subscriber
+	deleted   map[uint]models.Subscriber
+	revisions map[uint]models.SubscriberRevision
+	nextID    uint
+}
+
+func newMemoryRepository() *memoryRepository {
+	return &memoryRepository{
+		rows:      map[uint]models.Subscriber{},
+		deleted:   map[uint]models.Subscriber{},
+		revisions: map[uint]models.SubscriberRevision{},
+		nextID:    1,
+	}
+}
+
+func (r *memoryRepository) Transaction(ctx context.Context, fn func(repo repository.SubscriberRepository) error) error {
+	tx := &memoryRepository{rows: map[uint]models.Subscriber{}, deleted: r.deleted, revisions: r.revisions, nextID: r.nextID}
+	for id, s := range r.rows {
+		tx.rows[id] = s
+	}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	r.rows, r.nextID = tx.rows, tx.nextID
+	return nil
+}
+
+func (r *memoryRepository) Find(ctx context.Context, orgID, id uint) (*models.Subscriber, error) {
+	s, ok := r.rows[id]
+	if !ok || s.OrgID != orgID {
+		return nil, repository.ErrNotFound
+	}
+	return &s, nil
+}
+
+func (r *memoryRepository) FindByEmail(ctx context.Context, orgID uint, email string) (*models.Subscriber, error) {
+	for id := uint(1); id < r.nextID; id++ {
+		if s, ok := r.rows[id]; ok && s.OrgID == orgID && s.Email == email {
+			return &s, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r *memoryRepository) FindByConfirmTokenHash(ctx context.Context, hash string) (*models.Subscriber, error) {
+	for _, s := range r.rows {
+		if s.ConfirmTokenHash != nil && *s.ConfirmTokenHash == hash {
+			return &s, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r *memoryRepository) FindByReferralCode(ctx context.Context, orgID uint, code string) (*models.Subscriber, error) {
+	for _, s := range r.rows {
+		if s.OrgID == orgID && s.ReferralCode != nil && *s.ReferralCode == code {
+			return &s, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r *memoryRepository) Create(ctx context.Context, s *models.Subscriber) error {
+	s.ID = r.nextID
+	r.nextID++
+	r.rows[s.ID] = *s
+	return nil
+}
+
+func (r *memoryRepository) Update(ctx context.Context, s *models.Subscriber, types []models.SubscriberType) error {
+	stored, ok := r.rows[s.ID]
+	if !ok || stored.Version != s.Version {
+		return repository.ErrVersionConflict
+	}
+	s.Version++
+	if types != nil {
+		s.SubscriberTypes = types
+	}
+	r.rows[s.ID] = *s
+	return nil
+}
+
+func (r *memoryRepository) Confirm(ctx context.Context, s *models.Subscriber) error {
+	s.ConfirmTokenHash = nil
+	s.Version++
+	r.rows[s.ID] = *s
+	return nil
+}
+
+func (r *memoryRepository) ReissueConfirmation(ctx context.Context, s *models.Subscriber) error {
+	stored := r.rows[s.ID]
+	stored.ConfirmTokenHash, stored.ConfirmSentAt = s.ConfirmTokenHash, s.ConfirmSentAt
+	r.rows[s.ID] = stored
+	return nil
+}
+
+func (r *memoryRepository) Delete(ctx context.Context, s *models.Subscriber) error {
+	s.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	r.deleted[s.ID] = *s
+	delete(r.rows, s.ID)
+	return nil
+}
+
+func (r *memoryRepository) FindDeleted(ctx context.Context, orgID, id uint) (*models.Subscriber, error) {
+	s, ok := r.deleted[id]
+	if !ok || s.OrgID != orgID {
+		return nil, repository.ErrNotFound
+	}
+	return &s, nil
+}
+
+func (r *memoryRepository) Undelete(ctx context.Context, s *models.Subscriber) error {
+	if _, ok := r.deleted[s.ID]; !ok {
+		return repository.ErrNotFound
+	}
+	s.Version++
+	s.DeletedAt = gorm.DeletedAt{}
+	r.rows[s.ID] = *s
+	delete(r.deleted, s.ID)
+	return nil
+}
+
+func (r *memoryRepository) Approve(ctx context.Context, s *models.Subscriber) error {
+	s.Version++
+	r.rows[s.ID] = *s
+	return nil
+}
+
+func (r *memoryRepository) Merge(ctx context.Context, target, source *models.Subscriber) error {
+	stored, ok := r.rows[target.ID]
+	if !ok || stored.Version != target.Version {
+		return repository.ErrVersionConflict
+	}
+	target.Version++
+	for _, t := range source.SubscriberTypes {
+		if !slices.ContainsFunc(target.SubscriberTypes, func(have models.SubscriberType) bool { return have.Name == t.Name }) {
+			target.SubscriberTypes = append(target.SubscriberTypes, t)
+		}
+	}
+	r.rows[target.ID] = *target
+	delete(r.rows, source.ID)
+	return nil
+}
+
+func (r *memoryRepository) UpdatePreferences(ctx context.Context, s *models.Subscriber, types []models.SubscriberType) error {
+	stored, ok := r.rows[s.ID]
+	if !ok || stored.Version != s.Version {
+		return repository.ErrVersionConflict
+	}
+	s.Version++
+	if types != nil {
+		s.SubscriberTypes = types
+	}
+	r.rows[s.ID] = *s
+	return nil
+}
+
+func (r *memoryRepository) TypeNames(ctx context.Context) ([]string, error) {
+	return models.DefaultSubscriberTypeNames, nil
+}
+
+func (r *memoryRepository) FindRevision(ctx context.Context, subscriberID, revisionID uint) (*models.SubscriberRevision, error) {
+	revision, ok := r.revisions[revisionID]
+	if !ok || revision.SubscriberID != subscriberID {
+		return nil, repository.ErrNotFound
+	}
+	return &revision, nil
+}
+
+func (r *memoryRepository) Restore(ctx context.Context, s *models.Subscriber, snapshot models.SubscriberSnapshot) error {
+	stored, ok := r.rows[s.ID]
+	if !ok || stored.Version != s.Version {
+		return repository.ErrVersionConflict
+	}
+	s.Version++
+	s.Email, s.Name, s.Status, s.Metadata = snapshot.Email, snapshot.Name, snapshot.Status, snapshot.Metadata
+	s.SubscriberTypes = nil
+	for _, name := range snapshot.SubscriberTypes {
+		s.SubscriberTypes = append(s.SubscriberTypes, models.SubscriberType{Name: name})
+	}
+	r.rows[s.ID] = *s
+	return nil
+}
+
+// stubConfirmationEmail records the confirmation links sent during a test
+func stubConfirmationEmail(t *testing.T, err error) *[]string {
+	var links []string
+	original := sendgridservice.SendConfirmationEmailFunc
+	sendgridservice.SendConfirmationEmailFunc = func(email, locale, link string) error {
+		links = append(links, link)
+		return err
+	}
+	t.Cleanup(func() { sendgridservice.SendConfirmationEmailFunc = original })
+	return &links
+}
+
+func TestCreateValidation(t *testing.T) {
+	svc := NewSubscriberService(newMemoryRepository())
+	ctx := context.Background()
+
+	cases := []struct {
+		email, name string
+		want        error
+	}{
+		{"not-an-email", "Ada", ErrInvalidEmail},
+		{"ada@mailinator.com", "Ada", ErrDisposableEmail},
+		{"ada@example.com", "  ", ErrMissingName},
+	}
+	for _, tc := range cases {
+		_, err := svc.Create(ctx, CreateSubscriberInput{OrgID: 1, Email: tc.email, Name: tc.name})
+		if !errors.Is(err, tc.want) {
+			t.Errorf("%s: expected %v, got %v", tc.email, tc.want, err)
+		}
+	}
+
+	_, err := svc.Create(ctx, CreateSubscriberInput{
+		OrgID: 1, Email: "ada@example.com", Name: "Ada",
+		SubscriberTypes: []models.SubscriberType{{Name: "astronaut"}, {Name: "shopper"}, {Name: "astronaut"}, {Name: "donr"}},
+	})
+	if !errors.Is(err, ErrInvalidType) {
+		t.Errorf("Expected ErrInvalidType, got %v", err)
+	}
+	var invalid *InvalidTypesError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("Expected an InvalidTypesError, got %T", err)
+	}
+	if !slices.Equal(invalid.Invalid, []string{"astronaut", "donr"}) {
+		t.Errorf("Expected invalid astronaut and donr, got %v", invalid.Invalid)
+	}
+	if !slices.Equal(invalid.Accepted, models.DefaultSubscriberTypeNames) {
+		t.Errorf("Expected the accepted types to be listed, got %v", invalid.Accepted)
+	}
+
+	_, err = svc.Create(ctx, CreateSubscriberInput{OrgID: 1, Email: "ada@example.com", Name: "Ada", Locale: "de"})
+	if !errors.Is(err, ErrInvalidLocale) {
+		t.Errorf("Expected ErrInvalidLocale, got %v", err)
+	}
+
+	created, err := svc.Create(ctx, CreateSubscriberInput{OrgID: 1, Email: "ada@example.com", Name: "Ada"})
+	if err != nil {
+		t.Fatalf("Expected a valid subscriber to be created, got %v", err)
+	}
+	if created.Locale != "en" {
+		t.Errorf("Expected the default locale en, got %q", created.Locale)
+	}
+}
+
+func TestCreateDoubleOptIn(t *testing.T) {
+	links := stubConfirmationEmail(t, nil)
+	repo := newMemoryRepository()
+	svc := NewSubscriberService(repo)
+
+	subscriber, err := svc.Create(context.Background(), CreateSubscriberInput{
+		OrgID: 1, Email: "ada@example.com", Name: "Ada", DoubleOptIn: true,
+	})
+	if err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	if subscriber.Status != models.SubscriberStatusPending || subscriber.ConfirmTokenHash == nil {
+		t.Errorf("Expected a pending subscriber with a token, got %+v", subscriber)
+	}
+	if len(*links) != 1 || !strings.HasPrefix((*links)[0], defaultConfirmationBaseURL) {
+		t.Fatalf("Expected one confirmation link, got %v", *links)
+	}
+
+	token := strings.TrimPrefix((*links)[0], defaultConfirmationBaseURL)
+	confirmed, err := svc.Confirm(context.Background(), token)
+	if err != nil {
+		t.Fatalf("confirm failed: %v", err)
+	}
+	if confirmed.Status != models.SubscriberStatusActive || confirmed.EmailVerifiedAt == nil {
+		t.Errorf("Expected an active, verified subscriber, got %+v", confirmed)
+	}
+
+	if _, err := svc.Confirm(context.Background(), token); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Expected a used token to be invalid, got %v", err)
+	}
+}
+
+func TestCreateDoubleOptInSendFailure(t *testing.T) {
+	stubConfirmationEmail(t, errors.New("sendgrid down"))
+	repo := newMemoryRepository()
+	svc := NewSubscriberService(repo)
+
+	_, err := svc.Create(context.Background(), CreateSubscriberInput{
+		OrgID: 1, Email: "ada@example.com", Name: "Ada", DoubleOptIn: true,
+	})
+	if err == nil {
+		t.Fatal("Expected the failed send to fail the create")
+	}
+	if len(repo.rows) != 0 {
+		t.Errorf("Expected the insert to be rolled back, got %d rows", len(repo.rows))
+	}
+}
+
+func TestCreateQuarantined(t *testing.T) {
+	links := stubConfirmationEmail(t, nil)
+	svc := NewSubscriberService(newMemoryRepository())
+
+	subscriber, err := svc.Create(context.Background(), CreateSubscriberInput{
+		OrgID: 1, Email: "ada@example.com", Name: "Ada", DoubleOptIn: true,
+		SpamScore: 90, SpamSignals: models.JSONMap{"ip_velocity": 50, "gibberish_name": 40}, Quarantine: true,
+	})
+	if err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	if subscriber.Status != models.SubscriberStatusQuarantined || subscriber.SpamScore != 90 {
+		t.Errorf("Expected a quarantined subscriber scored 90, got %+v", subscriber)
+	}
+	if len(*links) != 0 {
+		t.Errorf("Expected no confirmation email before review, got %v", *links)
+	}
+}
+
+func TestCreateAttribution(t *testing.T) {
+	svc := NewSubscriberService(newMemoryRepository())
+
+	subscriber, err := svc.Create(context.Background(), CreateSubscriberInput{
+		OrgID: 1, Email: "ada@example.com", Name: "Ada",
+		Attribution: Attribution{Source: " Facebook ", Medium: "Social", Campaign: "Spring-Meetup", Referrer: "https://www.Forum.example.org/t/42?u=ada"},
+	})
+	if err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	if subscriber.UTMSource != "facebook" || subscriber.UTMMedium != "social" || subscriber.UTMCampaign != "Spring-Meetup" {
+		t.Errorf("Expected the utm values trimmed, source and medium lowercased, got %+v", subscriber)
+	}
+	if subscriber.Referrer != "forum.example.org" {
+		t.Errorf("Expected only the referrer host kept, got %q", subscriber.Referrer)
+	}
+
+	for _, attribution := range []Attribution{
+		{Referrer: "javascript:alert(1)"},
+		{Referrer: "forum.example.org"},
+		{Campaign: strings.Repeat("x", maxUTMLength+1)},
+	} {
+		_, err := svc.Create(context.Background(), CreateSubscriberInput{OrgID: 1, Email: "grace@example.com", Name: "Grace", Attribution: attribution})
+		if !errors.Is(err, ErrInvalidAttribution) {
+			t.Errorf("Expected %+v rejected, got %v", attribution, err)
+		}
+	}
+}
+
+func TestCreateReferred(t *testing.T) {
+	repo := newMemoryRepository()
+	svc := NewSubscriberService(repo)
+
+	code := "k7m2q9xw4d"
+	referrer := models.Subscriber{OrgID: 1, Email: "ada@example.com", Name: "Ada", ReferralCode: &code}
+	repo.Create(context.Background(), &referrer)
+
+	referee, err := svc.Create(context.Background(), CreateSubscriberInput{OrgID: 1, Email: "grace@example.com", Name: "Grace", Ref: " K7M2Q9XW4D "})
+	if err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	if referee.ReferredByID == nil || *referee.ReferredByID != referrer.ID {
+		t.Errorf("Expected the signup referred by %d, got %v", referrer.ID, referee.ReferredByID)
+	}
+
+	for _, in := range []CreateSubscriberInput{
+		{OrgID: 1, Email: "alan@example.com", Name: "Alan", Ref: "unknown"},
+		{OrgID: 2, Email: "edsger@example.com", Name: "Edsger", Ref: code},
+	} {
+		referee, err := svc.Create(context.Background(), in)
+		if err != nil {
+			t.Fatalf("Expected the signup of %+v kept, got %v", in, err)
+		}
+		if referee.ReferredByID != nil {
+			t.Errorf("Expected no referrer for %+v, got %d", in, *referee.ReferredByID)
+		}
+	}
+}
+
+func TestApprove(t *testing.T) {
+	links := stubConfirmationEmail(t, nil)
+	svc := NewSubscriberService(newMemoryRepository())
+	ctx := context.Background()
+
+	create := func(email string) *models.Subscriber {
+		s, err := svc.Create(ctx, CreateSubscriberInput{OrgID: 1, Email: email, Name: "Ada", Quarantine: true})
+		if err != nil {
+			t.Fatalf("create failed: %v", err)
+		}
+		return s
+	}
+
+	approved, err := svc.Approve(ctx, 1, create("ada@example.com").ID, false)
+	if err != nil || approved.Status != models.SubscriberStatusActive {
+		t.Fatalf("Expected an active subscriber, got %+v (%v)", approved, err)
+	}
+	if _, err := svc.Approve(ctx, 1, approved.ID, false); !errors.Is(err, ErrNotQuarantined) {
+		t.Errorf("Expected ErrNotQuarantined approving twice, got %v", err)
+	}
+
+	pending, err := svc.Approve(ctx, 1, create("grace@example.com").ID, true)
+	if err != nil || pending.Status != models.SubscriberStatusPending {
+		t.Fatalf("Expected a pending subscriber, got %+v (%v)", pending, err)
+	}
+	if len(*links) != 1 {
+		t.Errorf("Expected the confirmation link sent on approval, got %v", *links)
+	}
+
+	rejected := create("mallory@example.com")
+	if err := svc.Reject(ctx, 1, rejected.ID); err != nil {
+		t.Fatalf("reject failed: %v", err)
+	}
+	if _, err := svc.Get(ctx, 1, rejected.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected the rejected subscriber deleted, got %v", err)
+	}
+	if err := svc.Reject(ctx, 1, approved.ID); !errors.Is(err, ErrNotQuarantined) {
+		t.Errorf("Expected ErrNotQuarantined rejecting an active subscriber, got %v", err)
+	}
+}
+
+func TestConfirmExpired(t *testing.T) {
+	links := stubConfirmationEmail(t, nil)
+	svc := NewSubscriberService(newMemoryRepository()).(*subscriberService)
+
+	if _, err := svc.Create(context.Background(), CreateSubscriberInput{
+		OrgID: 1, Email: "ada@example.com", Name: "Ada", DoubleOptIn: true,
+	}); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+
+	svc.now = func() time.Time { return time.Now().Add(defaultConfirmationTTL + time.Hour) }
+	token := strings.TrimPrefix((*links)[0], defaultConfirmationBaseURL)
+	if _, err := svc.Confirm(context.Background(), token); !errors.Is(err, ErrTokenExpired) {
+		t.Errorf("Expected ErrTokenExpired, got %v", err)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	svc := NewSubscriberService(newMemoryRepository())
+	ctx := context.Background()
+
+	subscriber, err := svc.Create(ctx, CreateSubscriberInput{OrgID: 1, Email: "ada@example.com", Name: "Ada"})
+	if err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+
+	stale := subscriber.Version + 1
+	_, err = svc.Update(ctx, UpdateSubscriberInput{OrgID: 1, ID: subscriber.ID, Email: "ada@example.com", Name: "Ada L", Version: &stale})
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("Expected ErrVersionConflict, got %v", err)
+	}
+
+	_, err = svc.Update(ctx, UpdateSubscriberInput{OrgID: 2, ID: subscriber.ID, Email: "ada@example.com", Name: "Ada L"})
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for another organization, got %v", err)
+	}
+
+	updated, err := svc.Update(ctx, UpdateSubscriberInput{OrgID: 1, ID: subscriber.ID, Email: "ada@example.com", Name: "Ada L", Version: &subscriber.Version})
+	if err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+	if updated.Name != "Ada L" || updated.Version != subscriber.Version+1 {
+		t.Errorf("Expected the new name and a bumped version, got %+v", updated)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	svc := NewSubscriberService(newMemoryRepository())
+	ctx := context.Background()
+
+	subscriber, err := svc.Create(ctx, CreateSubscriberInput{OrgID: 1, Email: "ada@example.com", Name: "Ada"})
+	if err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	if err := svc.Delete(ctx, 2, subscriber.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for another organization, got %v", err)
+	}
+	if err := svc.Delete(ctx, 1, subscriber.ID); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	if _, err := svc.Get(ctx, 1, subscriber.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected the subscriber to be gone, got %v", err)
+	}
+}
+
+func TestUndelete(t *testing.T) {
+	svc := NewSubscriberService(newMemoryRepository()).(*subscriberService)
+	ctx := context.Background()
+
+	subscriber, err := svc.Create(ctx, CreateSubscriberInput{
+		OrgID: 1, Email: "ada@example.com", Name: "Ada",
+		SubscriberTypes: []models.SubscriberType{{Name: models.DefaultSubscriberTypeNames[0]}},
+	})
+	if err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	if _, err := svc.Undelete(ctx, 1, subscriber.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a subscriber that isn't deleted, got %v", err)
+	}
+	if err := svc.Delete(ctx, 1, subscriber.ID); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	if _, err := svc.Undelete(ctx, 2, subscriber.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for another organization, got %v", err)
+	}
+
+	svc.now = func() time.Time { return time.Now().Add(DeletionWindow() + time.Hour) }
+	if _, err := svc.Undelete(ctx, 1, subscriber.ID); !errors.Is(err, ErrUndoWindowExpired) {
+		t.Errorf("Expected ErrUndoWindowExpired past the window, got %v", err)
+	}
+
+	svc.now = time.Now
+	undeleted, err := svc.Undelete(ctx, 1, subscriber.ID)
+	if err != nil {
+		t.Fatalf("undelete failed: %v", err)
+	}
+	if undeleted.Name != "Ada" || len(undeleted.SubscriberTypes) != 1 || undeleted.Version != subscriber.Version+1 {
+		t.Errorf("Expected the subscriber back with its type and a bumped version, got %+v", undeleted)
+	}
+	if _, err := svc.Get(ctx, 1, subscriber.ID); err != nil {
+		t.Errorf("Expected the subscriber to be readable again, got %v", err)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	svc := NewSubscriberService(newMemoryRepository())
+	ctx := context.Background()
+
+	target, err := svc.Create(ctx, CreateSubscriberInput{
+		OrgID: 1, Email: "ada@example.com", Name: "Ada",
+		SubscriberTypes: []models.SubscriberType{{Name: "shopper"}},
+		Metadata:        models.JSONMap{"plan": "free", "crm_id": "A-1"},
+	})
+	if err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	source, err := svc.Create(ctx, CreateSubscriberInput{
+		OrgID: 1, Email: "ada.lovelace@example.com", Name: "Ada L",
+		SubscriberTypes: []models.SubscriberType{{Name: "shopper"}, {Name: "donor"}},
+		Metadata:        models.JSONMap{"plan": "pro", "source": "import"},
+	})
+	if err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+
+	if _, err := svc.Merge(ctx, MergeSubscribersInput{OrgID: 1, TargetID: target.ID, SourceID: target.ID}); !errors.Is(err, ErrMergeIntoSelf) {
+		t.Errorf("Expected ErrMergeIntoSelf, got %v", err)
+	}
+	if _, err := svc.Merge(ctx, MergeSubscribersInput{OrgID: 1, TargetID: target.ID, SourceID: source.ID, EmailRule: "newest"}); !errors.Is(err, ErrInvalidEmailRule) {
+		t.Errorf("Expected ErrInvalidEmailRule, got %v", err)
+	}
+	if _, err := svc.Merge(ctx, MergeSubscribersInput{OrgID: 2, TargetID: target.ID, SourceID: source.ID}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for another organization, got %v", err)
+	}
+
+	merged, err := svc.Merge(ctx, MergeSubscribersInput{OrgID: 1, TargetID: target.ID, SourceID: source.ID, EmailRule: MergeKeepSource})
+	if err != nil {
+		t.Fatalf("merge failed: %v", err)
+	}
+	if merged.Email != "ada.lovelace@example.com" || merged.Name != "Ada" {
+		t.Errorf("Expected the source's email and the target's name, got %s and %s", merged.Email, merged.Name)
+	}
+	if len(merged.SubscriberTypes) != 2 {
+		t.Errorf("Expected shopper and donor, got %d types", len(merged.SubscriberTypes))
+	}
+	if merged.Metadata["plan"] != "free" || merged.Metadata["source"] != "import" {
+		t.Errorf("Expected the target's metadata over the source's, got %v", merged.Metadata)
+	}
+	if _, err := svc.Get(ctx, 1, source.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected the source to be gone, got %v", err)
+	}
+}
+
+func TestRestore(t *testing.T) {
+	repo := newMemoryRepository()
+	svc := NewSubscriberService(repo)
+	ctx := context.Background()
+
+	subscriber, err := svc.Create(ctx, CreateSubscriberInput{OrgID: 1, Email: "ada@example.com", Name: "Ada L"})
+	if err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	repo.revisions[7] = models.SubscriberRevision{
+		ID: 7, SubscriberID: subscriber.ID, Action: models.RevisionCreated,
+		After: `{"email":"ada@example.com","name":"Ada","status":"active","subscriber_types":["donor"],"metadata":{"plan":"free"}}`,
+	}
+	// she opted out after the revision
+	unsubscribed := repo.rows[subscriber.ID]
+	unsubscribed.Status = models.SubscriberStatusUnsubscribed
+	repo.rows[subscriber.ID] = unsubscribed
+
+	if _, err := svc.Restore(ctx, 1, subscriber.ID, 8); !errors.Is(err, ErrRevisionNotFound) {
+		t.Errorf("Expected ErrRevisionNotFound, got %v", err)
+	}
+	if _, err := svc.Restore(ctx, 2, subscriber.ID, 7); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for another organization, got %v", err)
+	}
+
+	restored, err := svc.Restore(ctx, 1, subscriber.ID, 7)
+	if err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+	if restored.Name != "Ada" || len(restored.SubscriberTypes) != 1 || restored.Metadata["plan"] != "free" {
+		t.Errorf("Expected the revision's name, types and metadata, got %+v", restored)
+	}
+	if restored.Status != models.SubscriberStatusUnsubscribed {
+		t.Errorf("Expected the opt-out to be kept, got status %s", restored.Status)
+	}
+}
+
+func TestResendConfirmation(t *testing.T) {
+	links := stubConfirmationEmail(t, nil)
+	svc := NewSubscriberService(newMemoryRepository()).(*subscriberService)
+	ctx := context.Background()
+
+	subscriber, err := svc.Create(ctx, CreateSubscriberInput{OrgID: 1, Email: "ada@example.com", Name: "Ada", DoubleOptIn: true})
+	if err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+
+	var tooSoon *ResendTooSoonError
+	if _, err := svc.ResendConfirmation(ctx, 1, subscriber.ID); !errors.As(err, &tooSoon) || tooSoon.RetryAfter <= 0 {
+		t.Fatalf("Expected a ResendTooSoonError within the cooldown, got %v", err)
+	}
+	if _, err := svc.ResendConfirmation(ctx, 2, subscriber.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for another organization, got %v", err)
+	}
+
+	svc.now = func() time.Time { return time.Now().Add(defaultResendCooldown + time.Minute) }
+	if _, err := svc.ResendConfirmation(ctx, 1, subscriber.ID); err != nil {
+		t.Fatalf("resend failed: %v", err)
+	}
+	if len(*links) != 2 || (*links)[0] == (*links)[1] {
+		t.Fatalf("Expected a second, different link, got %v", *links)
+	}
+
+	// only the new link works
+	oldToken := strings.TrimPrefix((*links)[0], defaultConfirmationBaseURL)
+	if _, err := svc.Confirm(ctx, oldToken); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Expected the previous link to be invalid, got %v", err)
+	}
+	newToken := strings.TrimPrefix((*links)[1], defaultConfirmationBaseURL)
+	if _, err := svc.Confirm(ctx, newToken); err != nil {
+		t.Fatalf("confirm failed: %v", err)
+	}
+
+	if _, err := svc.ResendConfirmation(ctx, 1, subscriber.ID); !errors.Is(err, ErrNotPending) {
+		t.Errorf("Expected ErrNotPending once confirmed, got %v", err)
+	}
+}
+
+func TestPreferences(t *testing.T) {
+	redisclient.InitRedis("session")
+	var links []string
+	original := sendgridservice.SendPreferencesEmailFunc
+	sendgridservice.SendPreferencesEmailFunc = func(email, link string) error {
+		links = append(links, link)
+		return nil
+	}
+	t.Cleanup(func() { sendgridservice.SendPreferencesEmailFunc = original })
+
+	svc := NewSubscriberService(newMemoryRepository())
+	ctx := context.Background()
+
+	email := fmt.Sprintf("preferences-%d@example.com", time.Now().UnixNano())
+	subscriber, err := svc.Create(ctx, CreateSubscriberInput{
+		OrgID: 1, Email: email, Name: "Ada",
+		SubscriberTypes: []models.SubscriberType{
+			{Name: "shopper", Frequency: models.FrequencyWeekly, Channel: models.ChannelEmail},
+			{Name: "donor", Frequency: models.FrequencyMonthly, Channel: models.ChannelEmail},
+		},
+	})
+	if err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+
+	link, expires, err := svc.PreferencesLink(ctx, 1, subscriber.ID)
+	if err != nil {
+		t.Fatalf("link failed: %v", err)
+	}
+	if !strings.HasPrefix(link, defaultPreferencesBaseURL) || !expires.After(time.Now().Add(defaultPreferencesLinkTTL-time.Minute)) {
+		t.Fatalf("Expected a link valid for the default TTL, got %s until %s", link, expires)
+	}
+	token := strings.TrimPrefix(link, defaultPreferencesBaseURL)
+
+	if got, err := svc.Preferences(ctx, token); err != nil || got.ID != subscriber.ID {
+		t.Fatalf("Expected the link to load the subscriber, got %+v, %v", got, err)
+	}
+	if _, err := svc.Preferences(ctx, token+"x"); !errors.Is(err, ErrInvalidPreferencesLink) {
+		t.Errorf("Expected an unknown token to be invalid, got %v", err)
+	}
+
+	name, unsubscribe := "Ada Lovelace", false
+	updated, err := svc.UpdatePreferences(ctx, token, UpdatePreferencesInput{
+		Name:            &name,
+		SubscriberTypes: []models.SubscriberType{{Name: "shopper", Frequency: models.FrequencyDaily}, {Name: "driver"}},
+		Subscribed:      &unsubscribe,
+	})
+	if err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+	if updated.Name != name || updated.Status != models.SubscriberStatusUnsubscribed || len(updated.SubscriberTypes) != 2 {
+		t.Errorf("Expected the new name, types and an unsubscribe, got %+v", updated)
+	}
+
+	invalid := []struct {
+		in   UpdatePreferencesInput
+		want error
+	}{
+		{UpdatePreferencesInput{SubscriberTypes: []models.SubscriberType{{Name: "shopper", Channel: "pigeon"}}}, ErrInvalidChannel},
+		{UpdatePreferencesInput{SubscriberTypes: []models.SubscriberType{{Name: "astronaut"}}}, ErrInvalidType},
+		{UpdatePreferencesInput{Name: new(string)}, ErrMissingName},
+	}
+	for _, tc := range invalid {
+		if _, err := svc.UpdatePreferences(ctx, token, tc.in); !errors.Is(err, tc.want) {
+			t.Errorf("Expected %v, got %v", tc.want, err)
+		}
+	}
+
+	resubscribe := true
+	if updated, err = svc.UpdatePreferences(ctx, token, UpdatePreferencesInput{Subscribed: &resubscribe}); err != nil || updated.Status != models.SubscriberStatusActive {
+		t.Errorf("Expected resubscribing to make the subscriber active, got %+v, %v", updated, err)
+	}
+
+	// emailed links: unknown addresses get nothing, known ones one email per cooldown
+	if err := svc.SendPreferencesLink(ctx, 1, "nobody@example.com"); err != nil || len(links) != 0 {
+		t.Fatalf("Expected no email for an unknown address, got %v, %v", links, err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := svc.SendPreferencesLink(ctx, 1, email); err != nil {
+			t.Fatalf("send failed: %v", err)
+		}
+	}
+	if len(links) != 1 {
+		t.Fatalf("Expected one email within the cooldown, got %v", links)
+	}
+	emailed := strings.TrimPrefix(links[0], defaultPreferencesBaseURL)
+	if _, err := svc.Preferences(ctx, emailed); err != nil {
+		t.Errorf("Expected the emailed link to work, got %v", err)
+	}
+
+	// expiry is the Redis key's
+	if err := redisclient.DeleteKey(ctx, preferencesLinkKey(token)); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	if _, err := svc.Preferences(ctx, token); !errors.Is(err, ErrInvalidPreferencesLink) {
+		t.Errorf("Expected an expired link to be invalid, got %v", err)
+	}
+}
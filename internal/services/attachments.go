@@ -0,0 +1,154 @@
+package sendgridservice
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sendgrid/sendgrid-go/helpers/mail"
+)
+
+// Limits of the attachments of an email, well within SendGrid's 30MB per email
+const (
+	MaxAttachments      = 10
+	MaxAttachmentSize   = 2 << 20
+	MaxAttachmentsTotal = 10 << 20
+	maxFilename         = 255
+)
+
+// attachmentTypes are the content types an email can carry, with the extensions of their files.
+// Binary types are checked against their content, so a file can't pass for another.
+var attachmentTypes = map[string][]string{
+	"text/calendar":   {".ics"},
+	"text/plain":      {".txt"},
+	"text/csv":        {".csv"},
+	"application/pdf": {".pdf"},
+	"image/png":       {".png"},
+	"image/jpeg":      {".jpg", ".jpeg"},
+	"image/gif":       {".gif"},
+}
+
+// Attachment is a file sent with an email, such as the .ics invite of an event or a receipt.
+// ContentType may carry parameters, e.g. "text/calendar; method=REQUEST".
+type Attachment struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Content     []byte `json:"content"`
+}
+
+// AttachmentRef is what the email log keeps of an attachment: enough to tell which file was
+// sent, without its content
+type AttachmentRef struct {
+	Filename    string
+	ContentType string
+	Size        int
+	SHA256      string
+}
+
+// AttachmentError is an attachment that can't be sent. Sending again won't help, so it isn't
+// Retryable.
+type AttachmentError struct {
+	Filename string
+	Message  string
+}
+
+func (e *AttachmentError) Error() string {
+	if e.Filename == "" {
+		return "attachments: " + e.Message
+	}
+	return fmt.Sprintf("attachment %q: %s", e.Filename, e.Message)
+}
+
+// ValidateAttachments checks the attachments of an email: how many, their size, and that each
+// is a file of an allowed type named after it, returning the *AttachmentError of the first that
+// isn't
+func ValidateAttachments(attachments []Attachment) error {
+	if len(attachments) > MaxAttachments {
+		return &AttachmentError{Message: fmt.Sprintf("at most %d attachments per email", MaxAttachments)}
+	}
+	total := 0
+	for _, a := range attachments {
+		if err := validateAttachment(a); err != nil {
+			return err
+		}
+		total += len(a.Content)
+	}
+	if total > MaxAttachmentsTotal {
+		return &AttachmentError{Message: fmt.Sprintf("attachments are limited to %dMB per email", MaxAttachmentsTotal>>20)}
+	}
+	return nil
+}
+
+func validateAttachment(a Attachment) error {
+	invalid := func(format string, args ...interface{}) error {
+		return &AttachmentError{Filename: a.Filename, Message: fmt.Sprintf(format, args...)}
+	}
+	switch {
+	case strings.TrimSpace(a.Filename) == "":
+		return invalid("missing filename")
+	case len(a.Filename) > maxFilename || strings.ContainsAny(a.Filename, `/\`) ||
+		strings.IndexFunc(a.Filename, func(r rune) bool { return r < ' ' || r == 0x7f }) >= 0:
+		return invalid("invalid filename")
+	case len(a.Content) == 0:
+		return invalid("empty")
+	case len(a.Content) > MaxAttachmentSize:
+		return invalid("larger than %dMB", MaxAttachmentSize>>20)
+	}
+
+	contentType, _, err := mime.ParseMediaType(a.ContentType)
+	if err != nil {
+		return invalid("invalid content type %q", a.ContentType)
+	}
+	extensions, ok := attachmentTypes[contentType]
+	if !ok {
+		return invalid("content type %s isn't allowed", contentType)
+	}
+	ext := strings.ToLower(filepath.Ext(a.Filename))
+	named := false
+	for _, e := range extensions {
+		named = named || e == ext
+	}
+	if !named {
+		return invalid("a %s file is named %s", contentType, strings.Join(extensions, " or "))
+	}
+
+	if strings.HasPrefix(contentType, "text/") {
+		if !utf8.Valid(a.Content) || strings.ContainsRune(string(a.Content), 0) {
+			return invalid("isn't UTF-8 text")
+		}
+		if contentType == "text/calendar" && !strings.HasPrefix(strings.TrimSpace(string(a.Content)), "BEGIN:VCALENDAR") {
+			return invalid("isn't an iCalendar file")
+		}
+		return nil
+	}
+	if detected, _, _ := mime.ParseMediaType(http.DetectContentType(a.Content)); detected != contentType {
+		return invalid("content isn't %s", contentType)
+	}
+	return nil
+}
+
+// Ref returns what the email log keeps of a
+func (a Attachment) Ref() AttachmentRef {
+	sum := sha256.Sum256(a.Content)
+	return AttachmentRef{
+		Filename:    a.Filename,
+		ContentType: a.ContentType,
+		Size:        len(a.Content),
+		SHA256:      hex.EncodeToString(sum[:]),
+	}
+}
+
+// sendgridAttachment is a for the SendGrid API, base64 encoded
+func sendgridAttachment(a Attachment) *mail.Attachment {
+	return mail.NewAttachment().
+		SetContent(base64.StdEncoding.EncodeToString(a.Content)).
+		SetType(a.ContentType).
+		SetFilename(a.Filename).
+		SetDisposition("attachment")
+}
@@ -0,0 +1,52 @@
+package sendgridservice
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateAttachments(t *testing.T) {
+	invite := Attachment{Filename: "market.ics", ContentType: "text/calendar; method=REQUEST", Content: []byte("BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n")}
+	receipt := Attachment{Filename: "Receipt.PDF", ContentType: "application/pdf", Content: []byte("%PDF-1.7\n...")}
+	if err := ValidateAttachments([]Attachment{invite, receipt}); err != nil {
+		t.Errorf("Expected the invite and receipt accepted, got %v", err)
+	}
+	if err := ValidateAttachments(nil); err != nil {
+		t.Errorf("Expected no attachments accepted, got %v", err)
+	}
+
+	for name, attachments := range map[string][]Attachment{
+		"no filename":     {{ContentType: "text/plain", Content: []byte("hi")}},
+		"path":            {{Filename: "../etc/passwd.txt", ContentType: "text/plain", Content: []byte("hi")}},
+		"empty":           {{Filename: "a.txt", ContentType: "text/plain"}},
+		"type":            {{Filename: "run.exe", ContentType: "application/octet-stream", Content: []byte("MZ")}},
+		"extension":       {{Filename: "invite.txt", ContentType: "text/calendar", Content: invite.Content}},
+		"not a calendar":  {{Filename: "invite.ics", ContentType: "text/calendar", Content: []byte("hello")}},
+		"not a pdf":       {{Filename: "receipt.pdf", ContentType: "application/pdf", Content: []byte("<html></html>")}},
+		"binary text":     {{Filename: "a.txt", ContentType: "text/plain", Content: []byte{0xff, 0xfe, 0}}},
+		"too large":       {{Filename: "a.txt", ContentType: "text/plain", Content: bytes.Repeat([]byte("a"), MaxAttachmentSize+1)}},
+		"too many":        make([]Attachment, MaxAttachments+1),
+		"too large total": {receipt, receipt, receipt, receipt, receipt, receipt},
+	} {
+		if name == "too large total" {
+			for i := range attachments {
+				attachments[i].Content = append([]byte("%PDF-"), bytes.Repeat([]byte("a"), MaxAttachmentSize-10)...)
+			}
+		}
+		var attachmentErr *AttachmentError
+		if err := ValidateAttachments(attachments); !errors.As(err, &attachmentErr) {
+			t.Errorf("%s: expected an AttachmentError, got %v", name, err)
+		} else if Retryable(err) {
+			t.Errorf("%s: expected not retryable", name)
+		}
+	}
+}
+
+func TestAttachmentRef(t *testing.T) {
+	ref := Attachment{Filename: "a.txt", ContentType: "text/plain", Content: []byte("test")}.Ref()
+	if ref.Size != 4 || !strings.HasPrefix(ref.SHA256, "9f86d081884c7d65") || ref.Filename != "a.txt" {
+		t.Errorf("Unexpected ref %+v", ref)
+	}
+}
@@ -0,0 +1,59 @@
+package sendgridservice
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"os"
+)
+
+// Headers SendGrid signs its Event Webhook requests with.
+const (
+	EventSignatureHeader = "X-Twilio-Email-Event-Webhook-Signature"
+	EventTimestampHeader = "X-Twilio-Email-Event-Webhook-Timestamp"
+)
+
+var (
+	// ErrWebhookNotConfigured is returned when SENDGRID_WEBHOOK_PUBLIC_KEY is not set
+	ErrWebhookNotConfigured = errors.New("SENDGRID_WEBHOOK_PUBLIC_KEY not set, cannot verify events")
+	// ErrInvalidSignature is returned for unsigned or tampered event payloads
+	ErrInvalidSignature = errors.New("invalid event webhook signature")
+)
+
+// VerifyEventSignature checks a signed Event Webhook request: an ECDSA signature over the
+// timestamp header followed by the raw body, verified with the base64 public key from
+// SENDGRID_WEBHOOK_PUBLIC_KEY (Mail Settings > Signed Event Webhook in the SendGrid console).
+func VerifyEventSignature(payload []byte, signature, timestamp string) error {
+	encodedKey := os.Getenv("SENDGRID_WEBHOOK_PUBLIC_KEY")
+	if encodedKey == "" {
+		return ErrWebhookNotConfigured
+	}
+	der, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil {
+		return err
+	}
+	parsed, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return err
+	}
+	key, ok := parsed.(*ecdsa.PublicKey)
+	if !ok {
+		return errors.New("SENDGRID_WEBHOOK_PUBLIC_KEY is not an ECDSA key")
+	}
+
+	if signature == "" || timestamp == "" {
+		return ErrInvalidSignature
+	}
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	digest := sha256.Sum256(append([]byte(timestamp), payload...))
+	if !ecdsa.VerifyASN1(key, digest[:], sig) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
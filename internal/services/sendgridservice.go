@@ -1,9 +1,19 @@
 package sendgridservice
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"html"
 	"log"
+	"net/http"
 	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"fiber-gorm-api/internal/i18n"
+	"fiber-gorm-api/internal/resilience"
 
 	"github.com/sendgrid/sendgrid-go"
 	"github.com/sendgrid/sendgrid-go/helpers/mail"
@@ -12,8 +22,187 @@ import (
 // SendCodeEmailFunc is a variable you can override in tests for mocking.
 var SendCodeEmailFunc = defaultSendCodeEmail
 
-// SendCodeEmail uses the official SendGrid client to send a sign-in code email.
-func defaultSendCodeEmail(toEmail, code string) error {
+// SendInvitationEmailFunc is a variable you can override in tests for mocking.
+var SendInvitationEmailFunc = defaultSendInvitationEmail
+
+// SendConfirmationEmailFunc is a variable you can override in tests for mocking.
+var SendConfirmationEmailFunc = defaultSendConfirmationEmail
+
+// SendPreferencesEmailFunc is a variable you can override in tests for mocking.
+var SendPreferencesEmailFunc = defaultSendPreferencesEmail
+
+// SendMagicLinkEmailFunc is a variable you can override in tests for mocking.
+var SendMagicLinkEmailFunc = defaultSendMagicLinkEmail
+
+// SendAdminNotificationEmailFunc is a variable you can override in tests for mocking.
+var SendAdminNotificationEmailFunc = defaultSendAdminNotificationEmail
+
+// SendCampaignEmailFunc is a variable you can override in tests for mocking.
+var SendCampaignEmailFunc = defaultSendCampaignEmail
+
+// SendTemplateTestEmailFunc is a variable you can override in tests for mocking.
+var SendTemplateTestEmailFunc = defaultSendTemplateTestEmail
+
+// Types of the emails sent, as recorded in the email log
+const (
+	EmailTypeSignInCode        = "signin_code"
+	EmailTypeMagicLink         = "magic_link"
+	EmailTypeInvitation        = "invitation"
+	EmailTypeConfirmation      = "confirmation"
+	EmailTypePreferences       = "preferences"
+	EmailTypeAdminNotification = "admin_notification"
+	EmailTypeCampaign          = "campaign"
+	EmailTypeTemplateTest      = "template_test"
+)
+
+// SentEmail is an email sendEmail handed to SendGrid, or failed to
+type SentEmail struct {
+	Type string
+	To   string
+	// MessageID is SendGrid's X-Message-Id, the prefix of the sg_message_id of its events
+	MessageID string
+	// Err is why the email couldn't be sent, nil once SendGrid accepted it
+	Err error
+	// Attachments are the files sent with the email, without their content
+	Attachments []AttachmentRef
+}
+
+// Log is told about every email sent, or failed to, once SendGrid answered for good (after the
+// retries); nil logs nothing. See emaillog.
+var Log func(SentEmail)
+
+// SendCodeEmail uses the official SendGrid client to send a sign-in code email in locale.
+func defaultSendCodeEmail(toEmail, locale, code string, attachments ...Attachment) error {
+	return sendLocalizedEmail(EmailTypeSignInCode, toEmail, locale, "signin_code", map[string]string{"Code": code}, attachments...)
+}
+
+// SendMagicLinkEmail sends, in locale, a single-use link signing toEmail in.
+func defaultSendMagicLinkEmail(toEmail, locale, link string, attachments ...Attachment) error {
+	return sendLocalizedEmail(EmailTypeMagicLink, toEmail, locale, "magic_link", map[string]string{"Link": link}, attachments...)
+}
+
+// SendInvitationEmail sends a single-use link inviting toEmail to administer an organization.
+func defaultSendInvitationEmail(toEmail, orgName, link string) error {
+	subject := fmt.Sprintf("You're invited to administer %s", orgName)
+	plainText := fmt.Sprintf("You have been invited to administer %s.\n\nAccept the invitation here: %s\n\nThe link can only be used once.", orgName, link)
+	htmlContent := fmt.Sprintf("You have been invited to administer <strong>%s</strong>.<br><a href=\"%s\">Accept the invitation</a><br>The link can only be used once.", html.EscapeString(orgName), link)
+	return sendEmail(EmailTypeInvitation, toEmail, subject, plainText, htmlContent)
+}
+
+// SendConfirmationEmail sends, in locale, the double opt-in link a new subscriber must open to confirm their address.
+func defaultSendConfirmationEmail(toEmail, locale, link string) error {
+	return sendLocalizedEmail(EmailTypeConfirmation, toEmail, locale, "confirmation", map[string]string{"Link": link})
+}
+
+// SendPreferencesEmail sends the link a subscriber manages their subscription with.
+func defaultSendPreferencesEmail(toEmail, link string) error {
+	subject := "Manage your subscription"
+	plainText := fmt.Sprintf("Change your name, what you hear about and how often, or unsubscribe here: %s\n\nIf you didn't ask for this link, just ignore this email.", link)
+	htmlContent := fmt.Sprintf("<a href=\"%s\">Manage your subscription</a><br>Change your name, what you hear about and how often, or unsubscribe.<br>If you didn't ask for this link, just ignore this email.", link)
+	return sendEmail(EmailTypePreferences, toEmail, subject, plainText, htmlContent)
+}
+
+// SendAdminNotificationEmail tells a platform admin about sensitive activity, text being one
+// line per event.
+func defaultSendAdminNotificationEmail(toEmail, subject, text string) error {
+	return sendEmail(EmailTypeAdminNotification, toEmail, subject, text, TextHTML(text))
+}
+
+// TextHTML returns the HTML version of a plain text email, its lines kept
+func TextHTML(text string) string {
+	return strings.ReplaceAll(html.EscapeString(text), "\n", "<br>")
+}
+
+// SendCampaignEmail sends the email of a campaign, with the calendar invite of the event it
+// announces if any. Without htmlContent, the text is sent as HTML too.
+func defaultSendCampaignEmail(toEmail, subject, plainText, htmlContent string, attachments ...Attachment) error {
+	if htmlContent == "" {
+		htmlContent = TextHTML(plainText)
+	}
+	return sendEmail(EmailTypeCampaign, toEmail, subject, plainText, htmlContent, attachments...)
+}
+
+// SendTemplateTestEmail sends an email template rendered for a test to the admin who asked. As
+// for campaigns, the text is sent as HTML without htmlContent.
+func defaultSendTemplateTestEmail(toEmail, subject, plainText, htmlContent string) error {
+	if htmlContent == "" {
+		htmlContent = TextHTML(plainText)
+	}
+	return sendEmail(EmailTypeTemplateTest, toEmail, subject, plainText, htmlContent)
+}
+
+// sendLocalizedEmail renders the i18n email template name in locale and sends it as an email of
+// emailType.
+func sendLocalizedEmail(emailType, toEmail, locale, name string, data interface{}, attachments ...Attachment) error {
+	email, err := i18n.RenderEmail(locale, name, data)
+	if err != nil {
+		return err
+	}
+	return sendEmail(emailType, toEmail, email.Subject, email.Text, email.HTML, attachments...)
+}
+
+// sendgridBreaker stops sending for a while once SendGrid keeps failing (see resilience.Breaker)
+var sendgridBreaker = resilience.NewBreaker("sendgrid", "SENDGRID")
+
+// sendgridPolicy retries sends failing on the network, a 429 or a 5xx: SENDGRID_MAX_RETRIES
+// times (2 by default), each send limited to SENDGRID_TIMEOUT_SECONDS (10 by default)
+func sendgridPolicy() resilience.Policy {
+	retries := 2
+	if n, err := strconv.Atoi(os.Getenv("SENDGRID_MAX_RETRIES")); err == nil && n >= 0 {
+		retries = n
+	}
+	return resilience.Policy{
+		Attempts:  retries + 1,
+		BaseDelay: 200 * time.Millisecond,
+		MaxDelay:  2 * time.Second,
+		Timeout:   resilience.EnvSeconds("SENDGRID_TIMEOUT_SECONDS", 10*time.Second),
+	}
+}
+
+// sendgridStatusError is a send SendGrid answered with an error status
+type sendgridStatusError struct {
+	status int
+	body   string
+}
+
+func (e *sendgridStatusError) Error() string {
+	if e.status >= 500 {
+		return fmt.Sprintf("sendgrid returned server error (%d): %s", e.status, e.body)
+	}
+	return fmt.Sprintf("sendgrid returned client error (%d): %s", e.status, e.body)
+}
+
+// Retryable reports whether a send failing with err may succeed if tried again: the request
+// didn't get through, SendGrid was overloaded or rate limited it, or its circuit is open
+func Retryable(err error) bool {
+	var status *sendgridStatusError
+	if errors.As(err, &status) {
+		return status.status == http.StatusTooManyRequests || status.status >= 500
+	}
+	var attachment *AttachmentError
+	return !errors.As(err, &attachment)
+}
+
+// sendEmail uses the official SendGrid client to send a single email of emailType with
+// attachments, retrying and breaking the circuit as sendgridPolicy and sendgridBreaker say, and
+// tells Log how it went. While SendGrid is down the error wraps resilience.ErrUnavailable;
+// attachments that can't be sent are an *AttachmentError.
+func sendEmail(emailType, toEmail, subject, plainText, htmlContent string, attachments ...Attachment) (err error) {
+	sent := SentEmail{Type: emailType, To: toEmail}
+	for _, a := range attachments {
+		sent.Attachments = append(sent.Attachments, a.Ref())
+	}
+	defer func() {
+		if Log != nil {
+			sent.Err = err
+			Log(sent)
+		}
+	}()
+
+	if err := ValidateAttachments(attachments); err != nil {
+		return err
+	}
+
 	apiKey := os.Getenv("SENDGRID_API_KEY")
 	if apiKey == "" {
 		return fmt.Errorf("SENDGRID_API_KEY not set, cannot send email")
@@ -27,30 +216,24 @@ func defaultSendCodeEmail(toEmail, code string) error {
 
 	from := mail.NewEmail("MyApp", fromAddress)
 	to := mail.NewEmail("", toEmail)
-	subject := "Your Sign-In Code"
-
-	plainText := fmt.Sprintf("Your sign-in code is: %s\n\nUse this code to finish signing in.", code)
-	htmlContent := fmt.Sprintf("<strong>Your sign-in code is: %s</strong><br>Use this code to finish signing in.", code)
 
 	message := mail.NewSingleEmail(from, subject, to, plainText, htmlContent)
-
-	client := sendgrid.NewSendClient(apiKey)
-	response, err := client.Send(message)
-	if err != nil {
-		return fmt.Errorf("failed to send email via sendgrid: %w", err)
+	for _, a := range attachments {
+		message.AddAttachment(sendgridAttachment(a))
 	}
 
-	// For debugging/logging:
-	if response.StatusCode >= 300 {
-		log.Printf("[SendGrid] Non-success status code: %d\nBody: %s\n", response.StatusCode, response.Body)
-		if response.StatusCode >= 400 && response.StatusCode < 500 {
-			return fmt.Errorf("sendgrid returned client error (%d): %s", response.StatusCode, response.Body)
-		} else if response.StatusCode >= 500 {
-			return fmt.Errorf("sendgrid returned server error (%d): %s", response.StatusCode, response.Body)
+	client := sendgrid.NewSendClient(apiKey)
+	return resilience.Do(context.Background(), sendgridBreaker, sendgridPolicy(), Retryable, func(ctx context.Context) error {
+		response, err := client.SendWithContext(ctx, message)
+		if err != nil {
+			return fmt.Errorf("failed to send email via sendgrid: %w", err)
 		}
-	} else {
+		if response.StatusCode >= 400 {
+			log.Printf("[SendGrid] Non-success status code: %d\nBody: %s\n", response.StatusCode, response.Body)
+			return &sendgridStatusError{status: response.StatusCode, body: response.Body}
+		}
+		sent.MessageID = http.Header(response.Headers).Get("X-Message-Id")
 		log.Printf("[SendGrid] Email sent successfully to %s, status: %d\n", toEmail, response.StatusCode)
-	}
-
-	return nil
+		return nil
+	})
 }
@@ -0,0 +1,130 @@
+package session
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	redisclient "fiber-gorm-api/internal/redis"
+)
+
+// revokedChannel announces revoked sessions to every process, so each drops them from its
+// cache: session ids separated by spaces, or "*" for all of them
+const revokedChannel = "sessions:revoked"
+
+// maxCached bounds the cache; once full, expired entries are dropped, then all of them
+const maxCached = 10000
+
+type cachedSession struct {
+	sess  Session
+	until time.Time
+}
+
+var (
+	cacheMu sync.Mutex
+	cached  = map[string]cachedSession{}
+	// generation moves on every revocation, so a read racing one isn't cached after it
+	generation uint64
+	listenOnce sync.Once
+)
+
+// CacheTTL is how long a process keeps the sessions it authenticated requests with in memory,
+// SESSION_CACHE_TTL_SECONDS; 0, the default, turns the cache off. Revocations reach every
+// process at once through Redis, but one that missed them (cut off from Redis) may accept a
+// revoked session for up to the TTL.
+func CacheTTL() time.Duration {
+	return durationFromEnv("SESSION_CACHE_TTL_SECONDS", 0)
+}
+
+// Lookup is Get for authenticating requests: with CacheTTL set, a session read less than the
+// TTL ago is served from memory, so busy clients don't wait on Redis for every request
+func Lookup(ctx context.Context, id string) (*Session, error) {
+	ttl := CacheTTL()
+	if ttl <= 0 {
+		return Get(ctx, id)
+	}
+	listenOnce.Do(func() { go listenRevoked() })
+
+	now := time.Now()
+	cacheMu.Lock()
+	entry, ok := cached[id]
+	gen := generation
+	cacheMu.Unlock()
+	if ok && now.Before(entry.until) {
+		sess := entry.sess
+		return &sess, nil
+	}
+
+	sess, err := Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	if gen == generation {
+		if len(cached) >= maxCached {
+			pruneCache(now)
+		}
+		cached[id] = cachedSession{sess: *sess, until: now.Add(ttl)}
+	}
+	return sess, nil
+}
+
+// pruneCache drops the expired sessions, or every one when none has expired. cacheMu is held.
+func pruneCache(now time.Time) {
+	for id, entry := range cached {
+		if !now.Before(entry.until) {
+			delete(cached, id)
+		}
+	}
+	if len(cached) >= maxCached {
+		cached = map[string]cachedSession{}
+	}
+}
+
+// refreshCached updates the cached copy of sess after Touch wrote it, keeping its expiry
+func refreshCached(sess *Session) {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	if entry, ok := cached[sess.ID]; ok {
+		entry.sess = *sess
+		cached[sess.ID] = entry
+	}
+}
+
+// forget drops the sessions with ids from the cache of this process, or all of them for "*"
+func forget(ids ...string) {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	generation++
+	for _, id := range ids {
+		if id == "*" {
+			cached = map[string]cachedSession{}
+			return
+		}
+		delete(cached, id)
+	}
+}
+
+// announceRevoked drops revoked sessions from the cache of every process. The sessions are
+// already gone from Redis: a process missing the message only keeps them until they expire
+// from its cache.
+func announceRevoked(ctx context.Context, ids ...string) {
+	if len(ids) == 0 {
+		return
+	}
+	forget(ids...)
+	if err := redisclient.Publish(ctx, revokedChannel, strings.Join(ids, " ")); err != nil {
+		log.Printf("[WARN] Could not announce revoked sessions: %v", err)
+	}
+}
+
+// listenRevoked applies the revocations announced by every process to the cache of this one
+func listenRevoked() {
+	pubsub := redisclient.Subscribe(redisclient.Ctx, revokedChannel)
+	for msg := range pubsub.Channel() {
+		forget(strings.Fields(msg.Payload)...)
+	}
+}
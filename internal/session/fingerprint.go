@@ -0,0 +1,62 @@
+package session
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"os"
+	"strings"
+)
+
+// What to do with a session used from a client unlike the one that signed in, from
+// SESSION_FINGERPRINT: nothing (off, the default), log it, or refuse it (enforce)
+const (
+	FingerprintOff     = "off"
+	FingerprintLog     = "log"
+	FingerprintEnforce = "enforce"
+)
+
+// FingerprintMode is SESSION_FINGERPRINT
+func FingerprintMode() string {
+	switch mode := os.Getenv("SESSION_FINGERPRINT"); mode {
+	case FingerprintLog, FingerprintEnforce:
+		return mode
+	default:
+		return FingerprintOff
+	}
+}
+
+// Fingerprint identifies the client a session is used from, without keeping what it's made of:
+// a hash of its User-Agent and a hash of its network, the /24 of an IPv4 or the /48 of an IPv6
+func Fingerprint(ip, userAgent string) string {
+	return fingerprintHash(userAgent) + "." + fingerprintHash(networkOf(ip))
+}
+
+// FingerprintChanged reports whether the client with fingerprint current is drastically unlike
+// the one recorded: another browser on another network. Either alone is common (a browser update,
+// a phone leaving wifi) and tolerated. Sessions recorded without a fingerprint never change.
+func FingerprintChanged(recorded, current string) bool {
+	if recorded == "" {
+		return false
+	}
+	recordedAgent, recordedNetwork, _ := strings.Cut(recorded, ".")
+	currentAgent, currentNetwork, _ := strings.Cut(current, ".")
+	return recordedAgent != currentAgent && recordedNetwork != currentNetwork
+}
+
+func fingerprintHash(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:8])
+}
+
+// networkOf is the network ip belongs to, ip itself when it can't be parsed
+func networkOf(ip string) string {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return ip
+	}
+	if v4 := parsed.To4(); v4 != nil {
+		return v4.Mask(net.CIDRMask(24, 32)).String()
+	}
+	return parsed.Mask(net.CIDRMask(48, 128)).String()
+}
@@ -0,0 +1,118 @@
+package session
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// NewMemoryStore returns a Store keeping sessions in the memory of the process: lost on restart
+// and unknown to other instances, for development and single-instance deployments
+func NewMemoryStore() Store {
+	return &memoryStore{sessions: map[string]memorySession{}}
+}
+
+type memorySession struct {
+	sess  Session
+	until time.Time
+}
+
+type memoryStore struct {
+	mu       sync.Mutex
+	sessions map[string]memorySession
+}
+
+// live returns the session with id unless it's missing or expired. mu is held.
+func (s *memoryStore) live(id string, now time.Time) (memorySession, bool) {
+	entry, ok := s.sessions[id]
+	return entry, ok && now.Before(entry.until)
+}
+
+func (s *memoryStore) Get(ctx context.Context, id string) (*Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.live(id, time.Now())
+	if !ok {
+		return nil, ErrNotFound
+	}
+	sess := entry.sess
+	return &sess, nil
+}
+
+func (s *memoryStore) Set(ctx context.Context, sess *Session, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions[sess.ID] = memorySession{sess: *sess, until: time.Now().Add(ttl)}
+	return nil
+}
+
+func (s *memoryStore) Touch(ctx context.Context, sess *Session, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if _, ok := s.live(sess.ID, now); !ok {
+		return false, nil
+	}
+	s.sessions[sess.ID] = memorySession{sess: *sess, until: now.Add(ttl)}
+	return true, nil
+}
+
+func (s *memoryStore) Delete(ctx context.Context, email string, ids ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range ids {
+		if entry, ok := s.sessions[id]; ok && entry.sess.Email == email {
+			delete(s.sessions, id)
+		}
+	}
+	return nil
+}
+
+func (s *memoryStore) List(ctx context.Context, email string) ([]Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	var sessions []Session
+	for id := range s.sessions {
+		if entry, ok := s.live(id, now); ok && entry.sess.Email == email {
+			sessions = append(sessions, entry.sess)
+		}
+	}
+	return sessions, nil
+}
+
+func (s *memoryStore) All(ctx context.Context) ([]Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	var sessions []Session
+	for id := range s.sessions {
+		if entry, ok := s.live(id, now); ok {
+			sessions = append(sessions, entry.sess)
+		}
+	}
+	return sessions, nil
+}
+
+func (s *memoryStore) DeleteAll(ctx context.Context) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := len(s.sessions)
+	s.sessions = map[string]memorySession{}
+	return n, nil
+}
+
+// Prune drops the expired sessions
+func (s *memoryStore) Prune(ctx context.Context) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	removed := 0
+	for id := range s.sessions {
+		if _, ok := s.live(id, now); !ok {
+			delete(s.sessions, id)
+			removed++
+		}
+	}
+	return removed, nil
+}
@@ -0,0 +1,106 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"fiber-gorm-api/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// NewPostgresStore returns the Store keeping sessions in the sessions table of db, for
+// deployments without Redis. Expired rows are skipped, and deleted by Prune.
+func NewPostgresStore(db *gorm.DB) Store {
+	return postgresStore{db: db}
+}
+
+type postgresStore struct {
+	db *gorm.DB
+}
+
+func (s postgresStore) Get(ctx context.Context, id string) (*Session, error) {
+	var row models.Session
+	err := s.db.WithContext(ctx).Where("id = ? AND expires_at > ?", id, time.Now().UTC()).First(&row).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decode(row.ID, row.Data)
+}
+
+func (s postgresStore) Set(ctx context.Context, sess *Session, ttl time.Duration) error {
+	raw, err := encode(sess)
+	if err != nil {
+		return err
+	}
+	return s.db.WithContext(ctx).Create(&models.Session{
+		ID:        sess.ID,
+		Email:     sess.Email,
+		Data:      raw,
+		ExpiresAt: time.Now().UTC().Add(ttl),
+	}).Error
+}
+
+func (s postgresStore) Touch(ctx context.Context, sess *Session, ttl time.Duration) (bool, error) {
+	raw, err := encode(sess)
+	if err != nil {
+		return false, err
+	}
+	now := time.Now().UTC()
+	res := s.db.WithContext(ctx).Model(&models.Session{}).
+		Where("id = ? AND expires_at > ?", sess.ID, now).
+		Updates(map[string]interface{}{"data": raw, "expires_at": now.Add(ttl)})
+	return res.RowsAffected > 0, res.Error
+}
+
+func (s postgresStore) Delete(ctx context.Context, email string, ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return s.db.WithContext(ctx).Where("email = ? AND id IN ?", email, ids).Delete(&models.Session{}).Error
+}
+
+func (s postgresStore) List(ctx context.Context, email string) ([]Session, error) {
+	var rows []models.Session
+	if err := s.db.WithContext(ctx).Where("email = ? AND expires_at > ?", email, time.Now().UTC()).
+		Order("expires_at").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return decodeRows(rows)
+}
+
+func (s postgresStore) All(ctx context.Context) ([]Session, error) {
+	var rows []models.Session
+	if err := s.db.WithContext(ctx).Where("expires_at > ?", time.Now().UTC()).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return decodeRows(rows)
+}
+
+// decodeRows returns the sessions stored in rows
+func decodeRows(rows []models.Session) ([]Session, error) {
+	sessions := make([]Session, 0, len(rows))
+	for _, row := range rows {
+		sess, err := decode(row.ID, row.Data)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, *sess)
+	}
+	return sessions, nil
+}
+
+func (s postgresStore) DeleteAll(ctx context.Context) (int, error) {
+	res := s.db.WithContext(ctx).Where("1 = 1").Delete(&models.Session{})
+	return int(res.RowsAffected), res.Error
+}
+
+// Prune deletes the rows of expired sessions
+func (s postgresStore) Prune(ctx context.Context) (int, error) {
+	res := s.db.WithContext(ctx).Where("expires_at <= ?", time.Now().UTC()).Delete(&models.Session{})
+	return int(res.RowsAffected), res.Error
+}
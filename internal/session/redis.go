@@ -0,0 +1,168 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	redisclient "fiber-gorm-api/internal/redis"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// NewRedisStore returns the Store keeping each session under "session:<id>" in the session DB
+// of Redis, indexed in the set "user_sessions:<email>"
+func NewRedisStore() Store {
+	return redisStore{}
+}
+
+// redisStore goes through the redisclient functions, so it follows the client redisclient.Use sets
+type redisStore struct{}
+
+// userSessionsKey returns the Redis set key indexing every session id of a user
+func userSessionsKey(email string) string {
+	return "user_sessions:" + email
+}
+
+func (redisStore) Get(ctx context.Context, id string) (*Session, error) {
+	raw, err := redisclient.GetValue(ctx, Key(id))
+	if errors.Is(err, redis.Nil) || (err == nil && raw == "") {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decode(id, raw)
+}
+
+func (redisStore) Set(ctx context.Context, sess *Session, ttl time.Duration) error {
+	raw, err := encode(sess)
+	if err != nil {
+		return err
+	}
+	if err := redisclient.SetValue(ctx, Key(sess.ID), raw, ttl); err != nil {
+		return err
+	}
+	if err := redisclient.AddToSet(ctx, userSessionsKey(sess.Email), sess.ID); err != nil {
+		return err
+	}
+	// the index lives as long as the newest session can
+	_ = redisclient.Expire(ctx, userSessionsKey(sess.Email), MaxLifetime())
+	return nil
+}
+
+func (redisStore) Touch(ctx context.Context, sess *Session, ttl time.Duration) (bool, error) {
+	raw, err := encode(sess)
+	if err != nil {
+		return false, err
+	}
+	return redisclient.Replace(ctx, Key(sess.ID), raw, ttl)
+}
+
+func (redisStore) Delete(ctx context.Context, email string, ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	for _, id := range ids {
+		if err := redisclient.DeleteKey(ctx, Key(id)); err != nil {
+			return err
+		}
+	}
+	// Redis drops the set with its last member
+	return redisclient.RemoveFromSet(ctx, userSessionsKey(email), ids...)
+}
+
+// List prunes the ids of expired sessions from the index of email on the way
+func (s redisStore) List(ctx context.Context, email string) ([]Session, error) {
+	ids, err := redisclient.SetMembers(ctx, userSessionsKey(email))
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]Session, 0, len(ids))
+	for _, id := range ids {
+		sess, err := s.Get(ctx, id)
+		if errors.Is(err, ErrNotFound) {
+			_ = redisclient.RemoveFromSet(ctx, userSessionsKey(email), id)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, *sess)
+	}
+	return sessions, nil
+}
+
+func (s redisStore) All(ctx context.Context) ([]Session, error) {
+	keys, err := redisclient.ScanKeys(ctx, Key("*"))
+	if err != nil {
+		return nil, err
+	}
+	sessions := make([]Session, 0, len(keys))
+	for _, key := range keys {
+		sess, err := s.Get(ctx, strings.TrimPrefix(key, Key("")))
+		if errors.Is(err, ErrNotFound) {
+			continue // expired since the scan
+		}
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, *sess)
+	}
+	return sessions, nil
+}
+
+func (redisStore) DeleteAll(ctx context.Context) (int, error) {
+	keys, err := redisclient.ScanKeys(ctx, Key("*"))
+	if err != nil {
+		return 0, err
+	}
+	for _, key := range keys {
+		if err := redisclient.DeleteKey(ctx, key); err != nil {
+			return 0, err
+		}
+	}
+	indexes, err := redisclient.ScanKeys(ctx, userSessionsKey("*"))
+	if err != nil {
+		return len(keys), err
+	}
+	for _, key := range indexes {
+		if err := redisclient.DeleteKey(ctx, key); err != nil {
+			return len(keys), err
+		}
+	}
+	return len(keys), nil
+}
+
+// Prune drops expired session ids from every user's session set and deletes sets left empty
+func (s redisStore) Prune(ctx context.Context) (int, error) {
+	keys, err := redisclient.ScanKeys(ctx, userSessionsKey("*"))
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, key := range keys {
+		ids, err := redisclient.SetMembers(ctx, key)
+		if err != nil {
+			return removed, err
+		}
+		live := 0
+		for _, id := range ids {
+			if _, err := s.Get(ctx, id); errors.Is(err, ErrNotFound) {
+				if err := redisclient.RemoveFromSet(ctx, key, id); err != nil {
+					return removed, err
+				}
+				removed++
+				continue
+			}
+			live++
+		}
+		if live == 0 {
+			_ = redisclient.DeleteKey(ctx, key)
+		}
+	}
+	return removed, nil
+}
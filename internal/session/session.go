@@ -0,0 +1,226 @@
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"os"
+	"strconv"
+	"time"
+
+	"fiber-gorm-api/internal/geoip"
+	"fiber-gorm-api/internal/useragent"
+)
+
+// Default session lifetimes. A session expires after DefaultIdleTimeout without requests, and
+// DefaultMaxLifetime after sign-in whatever its activity.
+const (
+	DefaultIdleTimeout = 24 * time.Hour
+	DefaultMaxLifetime = 7 * 24 * time.Hour
+)
+
+// touchInterval is how stale last_seen_at may get before a request refreshes it (and the
+// expiry), so busy clients don't rewrite their session on every request
+const touchInterval = time.Minute
+
+// ErrNotFound is returned when a session doesn't exist or has expired.
+var ErrNotFound = errors.New("session not found")
+
+// Session is the profile kept by the Store, in Redis under "session:<id>" by default, for each
+// signed-in device.
+type Session struct {
+	ID         string    `json:"-"`
+	Email      string    `json:"email"`
+	OrgID      uint      `json:"org_id,omitempty"`
+	Role       string    `json:"role,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	IP         string    `json:"ip,omitempty"`
+	UserAgent  string    `json:"user_agent,omitempty"`
+	// Fingerprint is that of the client that signed in, see Fingerprint
+	Fingerprint string `json:"fingerprint,omitempty"`
+	// Device, OS and Browser are parsed from UserAgent at sign-in, see useragent.Parse
+	Device  string `json:"device,omitempty"`
+	OS      string `json:"os,omitempty"`
+	Browser string `json:"browser,omitempty"`
+	// Country and Region are where IP was at sign-in when GeoIP is enabled
+	Country string `json:"country,omitempty"`
+	Region  string `json:"region,omitempty"`
+}
+
+// Client is the device, OS and browser of sess, parsed from its User-Agent for sessions
+// stored before they were recorded
+func (s Session) Client() useragent.Info {
+	if s.Device == "" {
+		return useragent.Parse(s.UserAgent)
+	}
+	return useragent.Info{Device: s.Device, OS: s.OS, Browser: s.Browser}
+}
+
+// IdleTimeout is how long a session lives without requests, SESSION_IDLE_TIMEOUT_SECONDS
+func IdleTimeout() time.Duration {
+	return durationFromEnv("SESSION_IDLE_TIMEOUT_SECONDS", DefaultIdleTimeout)
+}
+
+// MaxLifetime is how long a session lives at most after sign-in, SESSION_MAX_LIFETIME_SECONDS
+func MaxLifetime() time.Duration {
+	return durationFromEnv("SESSION_MAX_LIFETIME_SECONDS", DefaultMaxLifetime)
+}
+
+func durationFromEnv(name string, def time.Duration) time.Duration {
+	if n, err := strconv.Atoi(os.Getenv(name)); err == nil && n > 0 {
+		return time.Duration(n) * time.Second
+	}
+	return def
+}
+
+// expiry is how long sess may live from now: a full idle timeout, cut short by its max
+// lifetime. Sessions without a creation time are only subject to the idle timeout.
+func expiry(sess *Session, now time.Time) time.Duration {
+	ttl := IdleTimeout()
+	if !sess.CreatedAt.IsZero() {
+		if left := sess.CreatedAt.Add(MaxLifetime()).Sub(now); left < ttl {
+			ttl = left
+		}
+	}
+	return ttl
+}
+
+// Key returns the Redis key holding the session with the given id
+func Key(id string) string {
+	return "session:" + id
+}
+
+// Create stores a new session for email, signed in to orgID, and indexes it under the user's session set
+func Create(ctx context.Context, email string, orgID uint, ip, userAgent string) (*Session, error) {
+	return CreateWithRole(ctx, email, orgID, "", ip, userAgent)
+}
+
+// CreateWithRole is Create for admins holding a role; the role limits the session's scopes.
+// An empty role grants every scope.
+func CreateWithRole(ctx context.Context, email string, orgID uint, role, ip, userAgent string) (*Session, error) {
+	now := time.Now().UTC()
+	sess := &Session{
+		ID:         randomID(16),
+		Email:      email,
+		OrgID:      orgID,
+		Role:       role,
+		CreatedAt:  now,
+		LastSeenAt: now,
+		IP:         ip,
+		UserAgent:  userAgent,
+		// recorded whatever SESSION_FINGERPRINT says, so turning it on covers existing sessions
+		Fingerprint: Fingerprint(ip, userAgent),
+	}
+	location := geoip.Lookup(ip)
+	sess.Country, sess.Region = location.Country, location.Region
+	client := useragent.Parse(userAgent)
+	sess.Device, sess.OS, sess.Browser = client.Device, client.OS, client.Browser
+	if err := store.Set(ctx, sess, expiry(sess, now)); err != nil {
+		return nil, err
+	}
+	return sess, nil
+}
+
+// Get loads a session by id
+func Get(ctx context.Context, id string) (*Session, error) {
+	return store.Get(ctx, id)
+}
+
+// Touch records a request on sess: last_seen_at moves to now and the session expires a full
+// idle timeout later, though never past its max lifetime. It's a no-op when sess was seen less
+// than touchInterval ago, and returns ErrNotFound once the session is over its max lifetime or
+// has been revoked meanwhile.
+func Touch(ctx context.Context, sess *Session) error {
+	now := time.Now().UTC()
+	if now.Sub(sess.LastSeenAt) < touchInterval {
+		return nil
+	}
+
+	ttl := expiry(sess, now)
+	if ttl <= 0 {
+		_ = store.Delete(ctx, sess.Email, sess.ID)
+		forget(sess.ID)
+		return ErrNotFound
+	}
+
+	touched := *sess
+	touched.LastSeenAt = now
+	// only overwrite a live session, so a concurrent Revoke can't be undone
+	ok, err := store.Touch(ctx, &touched, ttl)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		forget(sess.ID)
+		return ErrNotFound
+	}
+	sess.LastSeenAt = now
+	refreshCached(sess)
+	return nil
+}
+
+// ListForEmail returns every active session of a user
+func ListForEmail(ctx context.Context, email string) ([]Session, error) {
+	return store.List(ctx, email)
+}
+
+// ListAll returns every active session of every user, for aggregate stats
+func ListAll(ctx context.Context) ([]Session, error) {
+	return store.All(ctx)
+}
+
+// Revoke deletes a session belonging to email. Sessions of other users are reported as not found.
+func Revoke(ctx context.Context, email, id string) error {
+	sess, err := Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if sess.Email != email {
+		return ErrNotFound
+	}
+	if err := store.Delete(ctx, email, id); err != nil {
+		return err
+	}
+	announceRevoked(ctx, id)
+	return nil
+}
+
+// Prune drops what expired sessions left behind in the store: their ids in the per-user
+// indexes of Redis, their rows in Postgres. It returns the number of entries removed.
+func Prune(ctx context.Context) (int, error) {
+	return store.Prune(ctx)
+}
+
+// randomID returns a URL-safe random string
+func randomID(length int) string {
+	raw := make([]byte, length)
+	_, _ = rand.Read(raw)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// RevokeAllForEmail deletes every session of a user
+func RevokeAllForEmail(ctx context.Context, email string) error {
+	sessions, err := store.List(ctx, email)
+	if err != nil {
+		return err
+	}
+	ids := make([]string, len(sessions))
+	for i, sess := range sessions {
+		ids[i] = sess.ID
+	}
+	if err := store.Delete(ctx, email, ids...); err != nil {
+		return err
+	}
+	announceRevoked(ctx, ids...)
+	return nil
+}
+
+// RevokeAll deletes every session of every user, signing everyone out, and returns how many
+// sessions it deleted
+func RevokeAll(ctx context.Context) (int, error) {
+	n, err := store.DeleteAll(ctx)
+	announceRevoked(ctx, "*")
+	return n, err
+}
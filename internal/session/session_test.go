@@ -0,0 +1,164 @@
+package session
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	redisclient "fiber-gorm-api/internal/redis"
+)
+
+func TestTouch(t *testing.T) {
+	t.Setenv("REDIS_HOST", "")
+	t.Setenv("SESSION_IDLE_TIMEOUT_SECONDS", "3600")
+	t.Setenv("SESSION_MAX_LIFETIME_SECONDS", "7200")
+	redisclient.InitRedis("session")
+	ctx := redisclient.Ctx
+
+	sess, err := Create(ctx, "ada@example.com", 1, "127.0.0.1", "test")
+	if err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	if ttl, _ := redisclient.TTL(ctx, Key(sess.ID)); ttl > time.Hour || ttl < 59*time.Minute {
+		t.Errorf("Expected a new session to expire after the idle timeout, got %s", ttl)
+	}
+
+	// seen just now: nothing to write
+	if err := Touch(ctx, sess); err != nil {
+		t.Fatalf("touch failed: %v", err)
+	}
+
+	// 90 minutes in, idle for 10: the expiry slides, capped by the 2h max lifetime
+	sess.CreatedAt = time.Now().Add(-90 * time.Minute)
+	sess.LastSeenAt = time.Now().Add(-10 * time.Minute)
+	if err := Touch(ctx, sess); err != nil {
+		t.Fatalf("touch failed: %v", err)
+	}
+	if ttl, _ := redisclient.TTL(ctx, Key(sess.ID)); ttl > 30*time.Minute || ttl < 29*time.Minute {
+		t.Errorf("Expected the expiry to be capped at the max lifetime, got %s", ttl)
+	}
+	stored, err := Get(ctx, sess.ID)
+	if err != nil {
+		t.Fatalf("get failed: %v", err)
+	}
+	if time.Since(stored.LastSeenAt) > time.Minute {
+		t.Errorf("Expected last_seen_at to be recorded, got %s", stored.LastSeenAt)
+	}
+
+	// past its max lifetime the session is over, whatever its activity
+	sess.CreatedAt = time.Now().Add(-3 * time.Hour)
+	sess.LastSeenAt = time.Time{}
+	if err := Touch(ctx, sess); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound past the max lifetime, got %v", err)
+	}
+	if _, err := Get(ctx, sess.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected the session to be deleted, got %v", err)
+	}
+
+	// a revoked session isn't brought back
+	revoked, _ := Create(ctx, "ada@example.com", 1, "127.0.0.1", "test")
+	if err := Revoke(ctx, "ada@example.com", revoked.ID); err != nil {
+		t.Fatalf("revoke failed: %v", err)
+	}
+	revoked.LastSeenAt = time.Time{}
+	if err := Touch(ctx, revoked); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a revoked session, got %v", err)
+	}
+	if _, err := Get(ctx, revoked.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected the revoked session to stay gone, got %v", err)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	t.Setenv("REDIS_HOST", "")
+	redisclient.InitRedis("session")
+	ctx := redisclient.Ctx
+	forget("*")
+
+	t.Run("Off by default", func(t *testing.T) {
+		sess, _ := Create(ctx, "grace@example.com", 1, "", "")
+		if _, err := Lookup(ctx, sess.ID); err != nil {
+			t.Fatalf("lookup failed: %v", err)
+		}
+		redisclient.DeleteKey(ctx, Key(sess.ID))
+		if _, err := Lookup(ctx, sess.ID); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected ErrNotFound without a cache, got %v", err)
+		}
+	})
+
+	t.Setenv("SESSION_CACHE_TTL_SECONDS", "60")
+
+	t.Run("Cached", func(t *testing.T) {
+		sess, _ := Create(ctx, "grace@example.com", 1, "", "")
+		if _, err := Lookup(ctx, sess.ID); err != nil {
+			t.Fatalf("lookup failed: %v", err)
+		}
+		// gone from Redis behind the cache's back: still served from memory
+		redisclient.DeleteKey(ctx, Key(sess.ID))
+		if cachedSess, err := Lookup(ctx, sess.ID); err != nil || cachedSess.Email != "grace@example.com" || cachedSess.ID != sess.ID {
+			t.Errorf("Expected the cached session, got %+v (%v)", cachedSess, err)
+		}
+	})
+
+	t.Run("Revoked sessions are dropped", func(t *testing.T) {
+		sess, _ := Create(ctx, "grace@example.com", 1, "", "")
+		Lookup(ctx, sess.ID)
+		if err := Revoke(ctx, "grace@example.com", sess.ID); err != nil {
+			t.Fatalf("revoke failed: %v", err)
+		}
+		if _, err := Lookup(ctx, sess.ID); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected ErrNotFound once revoked, got %v", err)
+		}
+
+		other, _ := Create(ctx, "linus@example.com", 1, "", "")
+		Lookup(ctx, other.ID)
+		if err := RevokeAllForEmail(ctx, "linus@example.com"); err != nil {
+			t.Fatalf("revoke failed: %v", err)
+		}
+		if _, err := Lookup(ctx, other.ID); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected ErrNotFound once signed out everywhere, got %v", err)
+		}
+	})
+
+	t.Run("Revocations by other processes", func(t *testing.T) {
+		sess, _ := Create(ctx, "grace@example.com", 1, "", "")
+		Lookup(ctx, sess.ID)
+		redisclient.DeleteKey(ctx, Key(sess.ID))
+		// as another instance (or myloctl flush-sessions) announces it, once the listener is up
+		deadline := time.Now().Add(2 * time.Second)
+		for {
+			redisclient.Publish(ctx, revokedChannel, "unknown "+sess.ID)
+			time.Sleep(20 * time.Millisecond)
+			if _, err := Lookup(ctx, sess.ID); errors.Is(err, ErrNotFound) {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatal("Expected the announced revocation to drop the session")
+			}
+		}
+	})
+}
+
+func TestFingerprint(t *testing.T) {
+	signedIn := Fingerprint("203.0.113.7", "Firefox/128.0")
+	cases := []struct {
+		ip, userAgent string
+		changed       bool
+	}{
+		{"203.0.113.7", "Firefox/128.0", false},
+		{"203.0.113.200", "Chrome/126.0", false}, // same /24
+		{"198.51.100.1", "Firefox/128.0", false}, // same browser
+		{"198.51.100.1", "Chrome/126.0", true},
+	}
+	for _, c := range cases {
+		if got := FingerprintChanged(signedIn, Fingerprint(c.ip, c.userAgent)); got != c.changed {
+			t.Errorf("FingerprintChanged from %s %s = %v, expected %v", c.ip, c.userAgent, got, c.changed)
+		}
+	}
+	if FingerprintChanged("", Fingerprint("198.51.100.1", "Chrome/126.0")) {
+		t.Error("Expected a session without a fingerprint never to change")
+	}
+	if networkOf("2001:db8:1:2::1") != networkOf("2001:db8:1:ffff::9") {
+		t.Error("Expected IPv6 addresses of a /48 on the same network")
+	}
+}
@@ -0,0 +1,84 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Session stores, selected by SESSION_STORE
+const (
+	StoreRedis    = "redis"
+	StorePostgres = "postgres"
+	StoreMemory   = "memory"
+)
+
+// Store keeps sessions by id, each indexed under its email, until they expire
+type Store interface {
+	// Get returns the session with id, ErrNotFound when it doesn't exist or has expired
+	Get(ctx context.Context, id string) (*Session, error)
+	// Set writes sess, expiring ttl from now, and indexes it under its email
+	Set(ctx context.Context, sess *Session, ttl time.Duration) error
+	// Touch overwrites sess, expiring ttl from now, only if it still exists, and reports whether
+	// it did
+	Touch(ctx context.Context, sess *Session, ttl time.Duration) (bool, error)
+	// Delete removes the sessions of email with ids. Deleting a missing session isn't an error.
+	Delete(ctx context.Context, email string, ids ...string) error
+	// List returns the live sessions of email
+	List(ctx context.Context, email string) ([]Session, error)
+	// All returns every live session, of every user
+	All(ctx context.Context) ([]Session, error)
+	// DeleteAll removes every session and returns how many it removed
+	DeleteAll(ctx context.Context) (int, error)
+	// Prune drops what expired sessions left behind and returns how many entries it dropped
+	Prune(ctx context.Context) (int, error)
+}
+
+// store is where sessions are kept, Redis until Use picks another
+var store Store = NewRedisStore()
+
+// Use makes sessions kept in s and returns a func restoring the previous store. The API calls it
+// once at startup with NewFromEnv; tests use it to run on a store of their own.
+func Use(s Store) (restore func()) {
+	previous := store
+	store = s
+	return func() { store = previous }
+}
+
+// NewFromEnv returns the Store selected by SESSION_STORE: Redis (redis, the default), the
+// sessions table of the database connect opens (postgres), or the memory of the process
+// (memory, lost on restart and not shared between instances). connect is only called for
+// postgres.
+func NewFromEnv(connect func() *gorm.DB) Store {
+	switch name := os.Getenv("SESSION_STORE"); name {
+	case "", StoreRedis:
+		return NewRedisStore()
+	case StorePostgres:
+		return NewPostgresStore(connect())
+	case StoreMemory:
+		return NewMemoryStore()
+	default:
+		log.Printf("[WARN] Unknown SESSION_STORE %q, keeping sessions in Redis", name)
+		return NewRedisStore()
+	}
+}
+
+// encode is sess as stored, without its id
+func encode(sess *Session) (string, error) {
+	raw, err := json.Marshal(sess)
+	return string(raw), err
+}
+
+// decode is the session with id stored as raw
+func decode(id, raw string) (*Session, error) {
+	var sess Session
+	if err := json.Unmarshal([]byte(raw), &sess); err != nil {
+		return nil, err
+	}
+	sess.ID = id
+	return &sess, nil
+}
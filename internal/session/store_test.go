@@ -0,0 +1,113 @@
+package session
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	redisclient "fiber-gorm-api/internal/redis"
+	"fiber-gorm-api/internal/testutil/testenv"
+)
+
+func TestStores(t *testing.T) {
+	t.Setenv("REDIS_HOST", "")
+	redisclient.InitRedis("session")
+	conn := testenv.SQLite(t)
+	stores := map[string]Store{
+		StoreRedis:    NewRedisStore(),
+		StorePostgres: NewPostgresStore(conn),
+		StoreMemory:   NewMemoryStore(),
+	}
+	ctx := redisclient.Ctx
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			if _, err := store.DeleteAll(ctx); err != nil {
+				t.Fatalf("delete all failed: %v", err)
+			}
+			ada := &Session{ID: randomID(16), Email: "ada@example.com", OrgID: 1, CreatedAt: time.Now().UTC()}
+			other := &Session{ID: randomID(16), Email: "ada@example.com"}
+			for _, sess := range []*Session{ada, other} {
+				if err := store.Set(ctx, sess, time.Hour); err != nil {
+					t.Fatalf("set failed: %v", err)
+				}
+			}
+
+			got, err := store.Get(ctx, ada.ID)
+			if err != nil || got.ID != ada.ID || got.Email != ada.Email || got.OrgID != 1 {
+				t.Fatalf("Expected the session back, got %+v (%v)", got, err)
+			}
+			if _, err := store.Get(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Expected ErrNotFound, got %v", err)
+			}
+
+			ada.Role = "viewer"
+			if ok, err := store.Touch(ctx, ada, time.Hour); err != nil || !ok {
+				t.Errorf("Expected a live session touched, got %v (%v)", ok, err)
+			}
+			if got, _ := store.Get(ctx, ada.ID); got == nil || got.Role != "viewer" {
+				t.Errorf("Expected the touched session stored, got %+v", got)
+			}
+			if ok, _ := store.Touch(ctx, &Session{ID: "missing", Email: "ada@example.com"}, time.Hour); ok {
+				t.Error("Expected a missing session left missing")
+			}
+
+			if list, err := store.List(ctx, "ada@example.com"); err != nil || len(list) != 2 {
+				t.Errorf("Expected the 2 sessions of ada, got %d (%v)", len(list), err)
+			}
+			if all, err := store.All(ctx); err != nil || len(all) != 2 {
+				t.Errorf("Expected the 2 sessions in all, got %d (%v)", len(all), err)
+			}
+			// sessions of others aren't deleted under ada's email
+			if err := store.Delete(ctx, "grace@example.com", other.ID); err != nil {
+				t.Fatalf("delete failed: %v", err)
+			}
+			if err := store.Delete(ctx, "ada@example.com", other.ID); err != nil {
+				t.Fatalf("delete failed: %v", err)
+			}
+			if list, _ := store.List(ctx, "ada@example.com"); len(list) != 1 || list[0].ID != ada.ID {
+				t.Errorf("Expected only the remaining session listed, got %+v", list)
+			}
+
+			if n, err := store.DeleteAll(ctx); err != nil || n != 1 {
+				t.Errorf("Expected 1 session deleted, got %d (%v)", n, err)
+			}
+			if _, err := store.Get(ctx, ada.ID); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Expected ErrNotFound once all deleted, got %v", err)
+			}
+		})
+	}
+
+	t.Run("Expired sessions", func(t *testing.T) {
+		for name, store := range map[string]Store{StorePostgres: stores[StorePostgres], StoreMemory: stores[StoreMemory]} {
+			sess := &Session{ID: randomID(16), Email: "linus@example.com"}
+			if err := store.Set(ctx, sess, -time.Second); err != nil {
+				t.Fatalf("%s: set failed: %v", name, err)
+			}
+			if _, err := store.Get(ctx, sess.ID); !errors.Is(err, ErrNotFound) {
+				t.Errorf("%s: expected an expired session not found, got %v", name, err)
+			}
+			if n, err := store.Prune(ctx); err != nil || n != 1 {
+				t.Errorf("%s: expected the expired session pruned, got %d (%v)", name, n, err)
+			}
+		}
+	})
+
+	t.Run("Sessions follow the store in use", func(t *testing.T) {
+		restore := Use(stores[StoreMemory])
+		defer restore()
+		sess, err := Create(ctx, "grace@example.com", 1, "", "")
+		if err != nil {
+			t.Fatalf("create failed: %v", err)
+		}
+		if _, err := stores[StoreMemory].Get(ctx, sess.ID); err != nil {
+			t.Errorf("Expected the session in the memory store, got %v", err)
+		}
+		if err := RevokeAllForEmail(ctx, "grace@example.com"); err != nil {
+			t.Fatalf("revoke failed: %v", err)
+		}
+		if _, err := Get(ctx, sess.ID); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected ErrNotFound once revoked, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,62 @@
+// Package shortlinks makes the codes and URLs of short links (models.ShortLink) and counts their
+// clicks. Short links live at SHORT_LINK_BASE_URL/l/{code}, a branded domain pointed at the API,
+// or the API's own TRACKING_BASE_URL.
+package shortlinks
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"os"
+	"strings"
+	"time"
+
+	"fiber-gorm-api/internal/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultBaseURL = "http://localhost:3517"
+	// codeLength is the length of generated codes: 35 random bits
+	codeLength = 7
+)
+
+// NewCode returns a random code, lowercase letters and digits
+func NewCode() string {
+	raw := make([]byte, 5)
+	_, _ = rand.Read(raw)
+	return strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(raw))[:codeLength]
+}
+
+// BaseURL is the public URL short links are under: SHORT_LINK_BASE_URL, else TRACKING_BASE_URL
+func BaseURL() string {
+	for _, key := range []string{"SHORT_LINK_BASE_URL", "TRACKING_BASE_URL"} {
+		if base := strings.TrimSuffix(os.Getenv(key), "/"); base != "" {
+			return base
+		}
+	}
+	return defaultBaseURL
+}
+
+// URL returns the short link of code
+func URL(code string) string {
+	return BaseURL() + "/l/" + code
+}
+
+// CodeOf returns the code of link when it's a short link, "" otherwise
+func CodeOf(link string) string {
+	code, ok := strings.CutPrefix(link, BaseURL()+"/l/")
+	if !ok || code == "" || strings.ContainsAny(code, "/?#") {
+		return ""
+	}
+	return code
+}
+
+// Click counts a click at now on the short link id, the caller having left bots out
+func Click(conn *gorm.DB, id uint, now time.Time) error {
+	return conn.Model(&models.ShortLink{}).Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{
+			"clicks":          gorm.Expr("clicks + 1"),
+			"last_clicked_at": now,
+		}).Error
+}
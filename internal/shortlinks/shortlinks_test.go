@@ -0,0 +1,40 @@
+package shortlinks
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestNewCode(t *testing.T) {
+	seen := map[string]bool{}
+	for i := 0; i < 100; i++ {
+		code := NewCode()
+		if !regexp.MustCompile(`^[a-z2-7]{7}$`).MatchString(code) || seen[code] {
+			t.Fatalf("Unexpected code %q", code)
+		}
+		seen[code] = true
+	}
+}
+
+func TestURL(t *testing.T) {
+	t.Setenv("TRACKING_BASE_URL", "https://api.example.com/")
+	if url := URL("spring"); url != "https://api.example.com/l/spring" {
+		t.Errorf("Expected the API's URL without SHORT_LINK_BASE_URL, got %s", url)
+	}
+
+	t.Setenv("SHORT_LINK_BASE_URL", "https://go.example.com")
+	if url := URL("spring"); url != "https://go.example.com/l/spring" {
+		t.Errorf("Unexpected URL %s", url)
+	}
+	for link, code := range map[string]string{
+		"https://go.example.com/l/spring":       "spring",
+		"https://go.example.com/l/spring?x=1":   "",
+		"https://go.example.com/l/":             "",
+		"https://api.example.com/l/spring":      "",
+		"https://go.example.com/other/l/spring": "",
+	} {
+		if got := CodeOf(link); got != code {
+			t.Errorf("%s: expected %q, got %q", link, code, got)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+// Package sms sends text messages, such as sign-in codes, through a MessageSender; the API
+// uses Twilio (see TwilioSender).
+package sms
+
+import (
+	"context"
+	"regexp"
+	"strings"
+)
+
+// MessageSender delivers a text message to a phone number in E.164 form
+type MessageSender interface {
+	Send(ctx context.Context, to, body string) error
+}
+
+// Sender is the MessageSender of the API, a variable you can override in tests for mocking.
+var Sender MessageSender = NewTwilioSenderFromEnv()
+
+// e164Regex matches a phone number in E.164 form: +, a country code and at most 15 digits
+var e164Regex = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+
+// NormalizePhone strips the spaces, dots, dashes and parentheses people type in phone numbers
+// and returns the E.164 number, or "" when what's left isn't one
+func NormalizePhone(phone string) string {
+	phone = strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '.', '-', '(', ')':
+			return -1
+		}
+		return r
+	}, phone)
+	if !e164Regex.MatchString(phone) {
+		return ""
+	}
+	return phone
+}
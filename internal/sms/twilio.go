@@ -0,0 +1,73 @@
+package sms
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const defaultTwilioBaseURL = "https://api.twilio.com"
+
+// TwilioSender sends text messages with the Twilio Messages API
+type TwilioSender struct {
+	AccountSID string
+	AuthToken  string
+	// From is the sending number, or a messaging service SID (MG...)
+	From    string
+	BaseURL string
+	Client  *http.Client
+}
+
+// NewTwilioSenderFromEnv configures a TwilioSender from TWILIO_ACCOUNT_SID, TWILIO_AUTH_TOKEN
+// and TWILIO_FROM_NUMBER
+func NewTwilioSenderFromEnv() *TwilioSender {
+	return &TwilioSender{
+		AccountSID: os.Getenv("TWILIO_ACCOUNT_SID"),
+		AuthToken:  os.Getenv("TWILIO_AUTH_TOKEN"),
+		From:       os.Getenv("TWILIO_FROM_NUMBER"),
+		BaseURL:    defaultTwilioBaseURL,
+		Client:     &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Send posts body to Twilio for delivery to the E.164 number to
+func (t *TwilioSender) Send(ctx context.Context, to, body string) error {
+	if t.AccountSID == "" || t.AuthToken == "" || t.From == "" {
+		return fmt.Errorf("TWILIO_ACCOUNT_SID, TWILIO_AUTH_TOKEN or TWILIO_FROM_NUMBER not set, cannot send text message")
+	}
+
+	form := url.Values{"To": {to}, "Body": {body}}
+	if strings.HasPrefix(t.From, "MG") {
+		form.Set("MessagingServiceSid", t.From)
+	} else {
+		form.Set("From", t.From)
+	}
+	endpoint := fmt.Sprintf("%s/2010-04-01/Accounts/%s/Messages.json", t.BaseURL, url.PathEscape(t.AccountSID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(t.AccountSID, t.AuthToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := t.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send text message via twilio: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		var failure struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&failure)
+		return fmt.Errorf("twilio returned status %d (error %d): %s", resp.StatusCode, failure.Code, failure.Message)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package sms
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNormalizePhone(t *testing.T) {
+	cases := map[string]string{
+		"+15551234567":      "+15551234567",
+		"+1 (555) 123-4567": "+15551234567",
+		"+33 6.12.34.56.78": "+33612345678",
+		"5551234567":        "",
+		"+0551234567":       "",
+		"+1555123456789012": "",
+		"+1555abc4567":      "",
+		"":                  "",
+	}
+	for in, want := range cases {
+		if got := NormalizePhone(in); got != want {
+			t.Errorf("NormalizePhone(%q): expected %q, got %q", in, want, got)
+		}
+	}
+}
+
+func TestTwilioSender(t *testing.T) {
+	var got *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		got = r
+		if r.PostForm.Get("To") == "+15550000000" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"code": 21211, "message": "Invalid 'To' Phone Number"}`))
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	sender := &TwilioSender{AccountSID: "AC123", AuthToken: "secret", From: "+15557654321", BaseURL: server.URL, Client: server.Client()}
+	if err := sender.Send(context.Background(), "+15551234567", "Your sign-in code is: 123456"); err != nil {
+		t.Fatalf("Expected the message to be sent, got %v", err)
+	}
+	if got.URL.Path != "/2010-04-01/Accounts/AC123/Messages.json" {
+		t.Errorf("Unexpected path %s", got.URL.Path)
+	}
+	if user, pass, ok := got.BasicAuth(); !ok || user != "AC123" || pass != "secret" {
+		t.Errorf("Expected basic auth with the account SID and token, got %s:%s", user, pass)
+	}
+	if got.PostForm.Get("From") != "+15557654321" || got.PostForm.Get("Body") != "Your sign-in code is: 123456" {
+		t.Errorf("Unexpected form %v", got.PostForm)
+	}
+
+	sender.From = "MG0123"
+	if err := sender.Send(context.Background(), "+15551234567", "hi"); err != nil {
+		t.Fatalf("Expected the message to be sent, got %v", err)
+	}
+	if got.PostForm.Get("MessagingServiceSid") != "MG0123" || got.PostForm.Get("From") != "" {
+		t.Errorf("Expected a messaging service SID instead of From, got %v", got.PostForm)
+	}
+
+	if err := sender.Send(context.Background(), "+15550000000", "hi"); err == nil {
+		t.Errorf("Expected Twilio's error to be returned")
+	}
+	if err := (&TwilioSender{}).Send(context.Background(), "+15551234567", "hi"); err == nil {
+		t.Errorf("Expected an unconfigured sender to fail")
+	}
+}
@@ -0,0 +1,130 @@
+package spam
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	redisclient "fiber-gorm-api/internal/redis"
+)
+
+const (
+	defaultIPSignupsPerHour = 5
+	ipVelocityPoints        = 50
+	gibberishNamePoints     = 40
+	suspiciousTLDPoints     = 20
+	listedDomainPoints      = 50
+)
+
+// suspiciousTLDs are top-level domains mostly seen on throwaway signups. SPAM_SUSPICIOUS_TLDS
+// replaces them (comma separated).
+var suspiciousTLDs = []string{"xyz", "top", "click", "loan", "work", "gq", "tk", "ml", "cf", "ga"}
+
+// IPVelocity fires once an IP signs up more than SPAM_IP_SIGNUPS_PER_HOUR times in an hour.
+// Without Redis it never fires.
+type IPVelocity struct{}
+
+func (IPVelocity) Name() string { return "ip_velocity" }
+
+// Redis key counting the signups from ip this hour
+func ipSignupsKey(ip string) string {
+	return "spam_ip:" + ip
+}
+
+func (IPVelocity) Score(ctx context.Context, s Signup) int {
+	if s.IP == "" || !redisclient.Configured() {
+		return 0
+	}
+	signups, err := redisclient.Increment(ctx, ipSignupsKey(s.IP), time.Hour)
+	if err != nil {
+		return 0
+	}
+	limit := int64(defaultIPSignupsPerHour)
+	if n, err := strconv.Atoi(os.Getenv("SPAM_IP_SIGNUPS_PER_HOUR")); err == nil && n > 0 {
+		limit = int64(n)
+	}
+	if signups <= limit {
+		return 0
+	}
+	return ipVelocityPoints
+}
+
+// GibberishName fires on names that look like keyboard mashing: a long word without vowels,
+// a run of 6 consonants, or case flipping back and forth
+type GibberishName struct{}
+
+func (GibberishName) Name() string { return "gibberish_name" }
+
+func (GibberishName) Score(_ context.Context, s Signup) int {
+	for _, word := range strings.Fields(s.Name) {
+		if isGibberish(word) {
+			return gibberishNamePoints
+		}
+	}
+	return 0
+}
+
+// isGibberish reports whether word, of 6 letters or more, reads like random characters
+func isGibberish(word string) bool {
+	letters, vowels, run, longestRun, flips := 0, 0, 0, 0, 0
+	var prevUpper, seen bool
+	for _, r := range word {
+		if !unicode.IsLetter(r) {
+			run = 0
+			continue
+		}
+		letters++
+		if strings.ContainsRune("aeiouyAEIOUY", r) || r > unicode.MaxASCII {
+			vowels++
+			run = 0
+		} else {
+			run++
+			longestRun = max(longestRun, run)
+		}
+		upper := unicode.IsUpper(r)
+		if seen && upper != prevUpper {
+			flips++
+		}
+		prevUpper, seen = upper, true
+	}
+	if letters < 6 {
+		return false
+	}
+	return vowels == 0 || longestRun >= 6 || flips >= 4
+}
+
+// DomainReputation fires on email domains listed in SPAM_SUSPICIOUS_DOMAINS (comma separated,
+// parent domains match their subdomains) and, with fewer points, on suspicious top-level domains
+type DomainReputation struct{}
+
+func (DomainReputation) Name() string { return "domain_reputation" }
+
+func (DomainReputation) Score(_ context.Context, s Signup) int {
+	at := strings.LastIndexByte(s.Email, '@')
+	if at < 0 {
+		return 0
+	}
+	domain := strings.ToLower(strings.TrimSuffix(s.Email[at+1:], "."))
+
+	for _, listed := range strings.Split(os.Getenv("SPAM_SUSPICIOUS_DOMAINS"), ",") {
+		listed = strings.ToLower(strings.TrimSpace(listed))
+		if listed != "" && (domain == listed || strings.HasSuffix(domain, "."+listed)) {
+			return listedDomainPoints
+		}
+	}
+
+	tlds := suspiciousTLDs
+	if env := os.Getenv("SPAM_SUSPICIOUS_TLDS"); env != "" {
+		tlds = strings.Split(env, ",")
+	}
+	tld := domain[strings.LastIndexByte(domain, '.')+1:]
+	for _, t := range tlds {
+		if strings.TrimPrefix(strings.ToLower(strings.TrimSpace(t)), ".") == tld {
+			return suspiciousTLDPoints
+		}
+	}
+	return 0
+}
@@ -0,0 +1,60 @@
+// Package spam scores public signups through a list of Checks; signups scoring at least the
+// quarantine threshold are kept aside for an admin to review rather than mailed.
+package spam
+
+import (
+	"context"
+	"os"
+	"strconv"
+)
+
+// defaultQuarantineScore needs more than one check to fire with the built-in checks, so a
+// single false positive (a shared office IP, an unusual name) doesn't hold a signup back
+const defaultQuarantineScore = 70
+
+// Signup is what the checks look at
+type Signup struct {
+	OrgID uint
+	Email string
+	Name  string
+	IP    string
+}
+
+// Check scores one aspect of a signup: the points it adds towards spam, 0 when nothing looks off
+type Check interface {
+	Name() string
+	Score(ctx context.Context, s Signup) int
+}
+
+// Checks run on every public signup, a variable you can override in tests or extend with
+// checks of your own
+var Checks = []Check{IPVelocity{}, GibberishName{}, DomainReputation{}}
+
+// Verdict is the outcome of scoring a signup: the total score, the points of every check that
+// fired by name, and whether the signup is quarantined
+type Verdict struct {
+	Score       int
+	Signals     map[string]int
+	Quarantined bool
+}
+
+// quarantineScore is the score from which signups are quarantined (SPAM_QUARANTINE_SCORE)
+func quarantineScore() int {
+	if n, err := strconv.Atoi(os.Getenv("SPAM_QUARANTINE_SCORE")); err == nil && n > 0 {
+		return n
+	}
+	return defaultQuarantineScore
+}
+
+// Score runs Checks on s
+func Score(ctx context.Context, s Signup) Verdict {
+	v := Verdict{Signals: map[string]int{}}
+	for _, check := range Checks {
+		if points := check.Score(ctx, s); points > 0 {
+			v.Score += points
+			v.Signals[check.Name()] = points
+		}
+	}
+	v.Quarantined = v.Score >= quarantineScore()
+	return v
+}
@@ -0,0 +1,92 @@
+package spam
+
+import (
+	"context"
+	"testing"
+
+	redisclient "fiber-gorm-api/internal/redis"
+)
+
+type fixedCheck struct {
+	name   string
+	points int
+}
+
+func (c fixedCheck) Name() string                      { return c.name }
+func (c fixedCheck) Score(context.Context, Signup) int { return c.points }
+
+func TestScore(t *testing.T) {
+	original := Checks
+	t.Cleanup(func() { Checks = original })
+
+	Checks = []Check{fixedCheck{"quiet", 0}, fixedCheck{"loud", 40}}
+	v := Score(context.Background(), Signup{})
+	if v.Score != 40 || v.Quarantined || len(v.Signals) != 1 || v.Signals["loud"] != 40 {
+		t.Errorf("Expected 40 points from loud only, got %+v", v)
+	}
+
+	Checks = append(Checks, fixedCheck{"louder", 30})
+	if v := Score(context.Background(), Signup{}); !v.Quarantined {
+		t.Errorf("Expected 70 points to quarantine, got %+v", v)
+	}
+
+	t.Setenv("SPAM_QUARANTINE_SCORE", "100")
+	if v := Score(context.Background(), Signup{}); v.Quarantined {
+		t.Errorf("Expected the threshold from SPAM_QUARANTINE_SCORE, got %+v", v)
+	}
+}
+
+func TestIPVelocity(t *testing.T) {
+	signup := Signup{IP: "203.0.113.7"}
+	if redisclient.Configured() {
+		t.Fatal("Expected no Redis before InitRedis")
+	}
+	if points := (IPVelocity{}).Score(context.Background(), signup); points != 0 {
+		t.Errorf("Expected no points without Redis, got %d", points)
+	}
+
+	redisclient.InitRedis("session") // in-process unless REDIS_HOST is set
+	redisclient.DeleteKey(context.Background(), ipSignupsKey(signup.IP))
+	t.Setenv("SPAM_IP_SIGNUPS_PER_HOUR", "2")
+	for i := 1; i <= 3; i++ {
+		want := 0
+		if i > 2 {
+			want = ipVelocityPoints
+		}
+		if points := (IPVelocity{}).Score(context.Background(), signup); points != want {
+			t.Errorf("Expected %d points for signup %d, got %d", want, i, points)
+		}
+	}
+}
+
+func TestGibberishName(t *testing.T) {
+	for name, gibberish := range map[string]bool{
+		"Jane Doe":          false,
+		"Ada Lovelace":      false,
+		"Bartholomew Smith": false,
+		"Ronald McDonald":   false,
+		"José Szczepański":  false,
+		"xKqZvBnT":          true,
+		"Jane qwrtpsdf":     true,
+		"hjkl":              false,
+	} {
+		if got := (GibberishName{}).Score(context.Background(), Signup{Name: name}) > 0; got != gibberish {
+			t.Errorf("%q: expected gibberish %t, got %t", name, gibberish, got)
+		}
+	}
+}
+
+func TestDomainReputation(t *testing.T) {
+	t.Setenv("SPAM_SUSPICIOUS_DOMAINS", "spammy.example")
+	for email, want := range map[string]int{
+		"ada@example.com":         0,
+		"ada@spammy.example":      listedDomainPoints,
+		"ada@mail.spammy.example": listedDomainPoints,
+		"ada@cheap-deals.xyz":     suspiciousTLDPoints,
+		"not-an-email":            0,
+	} {
+		if got := (DomainReputation{}).Score(context.Background(), Signup{Email: email}); got != want {
+			t.Errorf("%s: expected %d points, got %d", email, want, got)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Rule deletes the objects under Prefix older than MaxAge
+type Rule struct {
+	Prefix string
+	MaxAge time.Duration
+}
+
+// Rules are the lifecycle rules Cleanup applies: exports are kept STORAGE_EXPORTS_RETENTION_HOURS
+// (48 by default, past the expiry of their download links), import uploads
+// STORAGE_IMPORTS_RETENTION_HOURS (7 days). Attachments stay as long as the emails using them.
+// They catch what the owners of the objects didn't delete themselves, e.g. after a crash.
+func Rules() []Rule {
+	return []Rule{
+		{Prefix: PrefixExports, MaxAge: retention("STORAGE_EXPORTS_RETENTION_HOURS", 48*time.Hour)},
+		{Prefix: PrefixImports, MaxAge: retention("STORAGE_IMPORTS_RETENTION_HOURS", 7*24*time.Hour)},
+	}
+}
+
+func retention(env string, def time.Duration) time.Duration {
+	if n, err := strconv.Atoi(os.Getenv(env)); err == nil && n > 0 {
+		return time.Duration(n) * time.Hour
+	}
+	return def
+}
+
+// Cleanup applies rules to store and returns how many objects it deleted
+func Cleanup(ctx context.Context, store Store, rules []Rule, now time.Time) (int, error) {
+	deleted := 0
+	for _, rule := range rules {
+		objects, err := store.List(ctx, rule.Prefix)
+		if err != nil {
+			return deleted, err
+		}
+		for _, obj := range objects {
+			if now.Sub(obj.LastModified) <= rule.MaxAge {
+				continue
+			}
+			if err := store.Delete(ctx, obj.Key); err != nil {
+				return deleted, err
+			}
+			deleted++
+		}
+	}
+	return deleted, nil
+}
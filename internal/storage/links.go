@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Download links work without a session (e.g. pasted in a browser or handed to curl) but can't
+// be forged or extended. Stores that sign URLs (S3) serve the object directly; otherwise the
+// link points at an API route and carries its expiry and an HMAC of the object key and expiry,
+// which the route checks with VerifyLink.
+
+const defaultLinkTTL = time.Hour
+
+// signingKey signs API download links: STORAGE_SIGNING_KEY, or the JWT secret when unset
+func signingKey() []byte {
+	if key := os.Getenv("STORAGE_SIGNING_KEY"); key != "" {
+		return []byte(key)
+	}
+	if key := os.Getenv("JWT_USER_SECRET_KEY"); key != "" {
+		return []byte(key)
+	}
+	return []byte("devsecret")
+}
+
+// LinkTTL is how long download links are valid (STORAGE_LINK_TTL_MINUTES)
+func LinkTTL() time.Duration {
+	if n, err := strconv.Atoi(os.Getenv("STORAGE_LINK_TTL_MINUTES")); err == nil && n > 0 {
+		return time.Duration(n) * time.Minute
+	}
+	return defaultLinkTTL
+}
+
+func sign(key string, expires int64) string {
+	mac := hmac.New(sha256.New, signingKey())
+	fmt.Fprintf(mac, "%s:%d", key, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// DownloadURL returns a link downloading the object key of store as filename for LinkTTL, at
+// most until notAfter when set, and when the link expires. apiURL is the route serving the
+// object when store can't sign URLs itself.
+func DownloadURL(ctx context.Context, store Store, key, filename, apiURL string, notAfter *time.Time, now time.Time) (string, time.Time, error) {
+	expires := now.Add(LinkTTL())
+	if notAfter != nil && notAfter.Before(expires) {
+		expires = *notAfter
+	}
+	expires = expires.Truncate(time.Second)
+
+	link, err := store.SignedURL(ctx, key, expires.Sub(now), filename)
+	if !errors.Is(err, ErrSigningUnsupported) {
+		return link, expires, err
+	}
+
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expires.Unix(), 10))
+	query.Set("signature", sign(key, expires.Unix()))
+	return apiURL + "?" + query.Encode(), expires, nil
+}
+
+// VerifyLink reports whether signature was issued by DownloadURL for key and expires, and the
+// link hasn't expired at now
+func VerifyLink(key, expires, signature string, now time.Time) bool {
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || now.Unix() > unix {
+		return false
+	}
+	return hmac.Equal([]byte(signature), []byte(sign(key, unix)))
+}
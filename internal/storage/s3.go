@@ -0,0 +1,124 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"mime"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+const defaultS3Endpoint = "s3.amazonaws.com"
+
+// S3 keeps objects in a bucket of S3 or of an S3-compatible server (MinIO, R2...)
+type S3 struct {
+	Client *minio.Client
+	Bucket string
+}
+
+// NewS3FromEnv returns the S3 store of STORAGE_S3_BUCKET on STORAGE_S3_ENDPOINT (host[:port],
+// AWS by default), authenticated with STORAGE_S3_ACCESS_KEY / STORAGE_S3_SECRET_KEY.
+// STORAGE_S3_REGION avoids a region lookup, STORAGE_S3_USE_SSL=false talks plain HTTP (a
+// local MinIO) and STORAGE_S3_PATH_STYLE=true addresses the bucket in the path, as MinIO
+// expects.
+func NewS3FromEnv() (*S3, error) {
+	endpoint := os.Getenv("STORAGE_S3_ENDPOINT")
+	if endpoint == "" {
+		endpoint = defaultS3Endpoint
+	}
+	lookup := minio.BucketLookupAuto
+	if os.Getenv("STORAGE_S3_PATH_STYLE") == "true" {
+		lookup = minio.BucketLookupPath
+	}
+	client, err := minio.New(endpoint, &minio.Options{
+		Creds:        credentials.NewStaticV4(os.Getenv("STORAGE_S3_ACCESS_KEY"), os.Getenv("STORAGE_S3_SECRET_KEY"), ""),
+		Secure:       os.Getenv("STORAGE_S3_USE_SSL") != "false",
+		Region:       os.Getenv("STORAGE_S3_REGION"),
+		BucketLookup: lookup,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &S3{Client: client, Bucket: os.Getenv("STORAGE_S3_BUCKET")}, nil
+}
+
+// notFound maps the S3 errors of a missing object to ErrNotFound
+func notFound(err error) error {
+	if err == nil {
+		return nil
+	}
+	if code := minio.ToErrorResponse(err).Code; code == "NoSuchKey" || code == "NotFound" {
+		return ErrNotFound
+	}
+	return err
+}
+
+func (s *S3) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+	_, err = s.Client.PutObject(ctx, s.Bucket, key, r, size, minio.PutObjectOptions{ContentType: contentType})
+	return err
+}
+
+// Open checks the object exists first: GetObject only fails on the first read
+func (s *S3) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := s.Client.GetObject(ctx, s.Bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, notFound(err)
+	}
+	if _, err := obj.Stat(); err != nil {
+		obj.Close()
+		return nil, notFound(err)
+	}
+	return obj, nil
+}
+
+func (s *S3) Delete(ctx context.Context, key string) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+	// S3 doesn't fail deleting a missing key
+	return s.Client.RemoveObject(ctx, s.Bucket, key, minio.RemoveObjectOptions{})
+}
+
+func (s *S3) List(ctx context.Context, prefix string) ([]Object, error) {
+	// stops the listing goroutine when returning early
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var objects []Object
+	for info := range s.Client.ListObjects(ctx, s.Bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if info.Err != nil {
+			return nil, info.Err
+		}
+		objects = append(objects, Object{Key: info.Key, Size: info.Size, LastModified: info.LastModified})
+	}
+	return objects, nil
+}
+
+// SignedURL presigns a GET of the object, asking S3 to send it as an attachment named filename
+func (s *S3) SignedURL(ctx context.Context, key string, ttl time.Duration, filename string) (string, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	params := url.Values{}
+	if filename != "" {
+		params.Set("response-content-disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	}
+	u, err := s.Client.PresignedGetObject(ctx, s.Bucket, key, ttl, params)
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
@@ -0,0 +1,89 @@
+// Package storage keeps the files the API generates or receives (exports, import uploads,
+// email attachments) in an S3 bucket, or any S3-compatible server such as MinIO, when
+// STORAGE_S3_BUCKET is set, and in a local directory otherwise.
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Key prefixes of the kinds of files kept, each with its own lifecycle rule (see Rules)
+const (
+	PrefixExports     = "exports/"
+	PrefixImports     = "imports/"
+	PrefixAttachments = "attachments/"
+)
+
+var (
+	// ErrNotFound is returned when no object has the key
+	ErrNotFound = errors.New("object not found")
+	// ErrSigningUnsupported is returned by stores that can't hand out direct links; the API
+	// then serves the object itself
+	ErrSigningUnsupported = errors.New("store can't sign URLs")
+	// ErrInvalidKey is returned for keys that are empty or would escape the store
+	ErrInvalidKey = errors.New("invalid object key")
+)
+
+// Object describes a stored object
+type Object struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+}
+
+// Store keeps objects by key. Keys are slash separated paths, such as "exports/12.csv".
+type Store interface {
+	// Put writes size bytes of r under key, replacing any object there
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Open reads the object under key, ErrNotFound when there's none
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the object under key. Deleting a missing object isn't an error.
+	Delete(ctx context.Context, key string) error
+	// List returns the objects whose key starts with prefix
+	List(ctx context.Context, prefix string) ([]Object, error)
+	// SignedURL returns a link downloading the object under key as filename, valid for ttl,
+	// or ErrSigningUnsupported
+	SignedURL(ctx context.Context, key string, ttl time.Duration, filename string) (string, error)
+}
+
+// Default is the Store of the API, a variable you can override in tests.
+var Default Store = NewFromEnv()
+
+// NewFromEnv returns the S3 store configured by STORAGE_S3_* when STORAGE_S3_BUCKET is set,
+// the local store of STORAGE_DIR otherwise. An S3 configuration that can't be used is logged
+// and falls back to the local store rather than stopping the API from starting.
+func NewFromEnv() Store {
+	if os.Getenv("STORAGE_S3_BUCKET") != "" {
+		s3, err := NewS3FromEnv()
+		if err == nil {
+			return s3
+		}
+		log.Printf("[WARN] Storage: S3 is misconfigured, keeping files on local disk: %v", err)
+	}
+	return NewLocal(localDir())
+}
+
+// localDir is the directory of the local store (STORAGE_DIR, one in the OS temp dir by default)
+func localDir() string {
+	if dir := os.Getenv("STORAGE_DIR"); dir != "" {
+		return dir
+	}
+	return filepath.Join(os.TempDir(), "mylo-storage")
+}
+
+// cleanKey checks key and returns it without leading slashes
+func cleanKey(key string) (string, error) {
+	key = strings.TrimLeft(key, "/")
+	if key == "" || path.Clean(key) != key || strings.HasPrefix(key, "../") || key == ".." {
+		return "", ErrInvalidKey
+	}
+	return key, nil
+}
@@ -0,0 +1,116 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLocal(t *testing.T) {
+	ctx := context.Background()
+	store := NewLocal(t.TempDir())
+
+	if err := store.Put(ctx, "exports/1.csv", strings.NewReader("id,email\n"), 9, "text/csv"); err != nil {
+		t.Fatal(err)
+	}
+	f, err := store.Open(ctx, "exports/1.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := io.ReadAll(f)
+	f.Close()
+	if string(raw) != "id,email\n" {
+		t.Errorf("Expected the content back, got %q", raw)
+	}
+
+	objects, err := store.List(ctx, PrefixExports)
+	if err != nil || len(objects) != 1 || objects[0].Key != "exports/1.csv" || objects[0].Size != 9 {
+		t.Errorf("Expected the object listed, got %+v (%v)", objects, err)
+	}
+	if objects, _ := store.List(ctx, PrefixImports); len(objects) != 0 {
+		t.Errorf("Expected nothing under another prefix, got %+v", objects)
+	}
+
+	if _, err := store.SignedURL(ctx, "exports/1.csv", time.Minute, "1.csv"); !errors.Is(err, ErrSigningUnsupported) {
+		t.Errorf("Expected local storage not to sign URLs, got %v", err)
+	}
+
+	if err := store.Delete(ctx, "exports/1.csv"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Open(ctx, "exports/1.csv"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound once deleted, got %v", err)
+	}
+	if err := store.Delete(ctx, "exports/1.csv"); err != nil {
+		t.Errorf("Expected deleting a missing object to succeed, got %v", err)
+	}
+
+	for _, key := range []string{"", "../secret", "exports/../../secret"} {
+		if err := store.Put(ctx, key, strings.NewReader(""), 0, ""); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("%q: expected ErrInvalidKey, got %v", key, err)
+		}
+	}
+}
+
+func TestCleanup(t *testing.T) {
+	ctx := context.Background()
+	store := NewLocal(t.TempDir())
+	now := time.Now()
+	put := func(key string, age time.Duration) {
+		if err := store.Put(ctx, key, strings.NewReader("x"), 1, ""); err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(store.Dir, filepath.FromSlash(key))
+		if err := os.Chtimes(path, now.Add(-age), now.Add(-age)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	put("exports/old.csv", 3*time.Hour)
+	put("exports/new.csv", time.Minute)
+	put("attachments/logo.png", 1000*time.Hour)
+
+	deleted, err := Cleanup(ctx, store, []Rule{{Prefix: PrefixExports, MaxAge: time.Hour}}, now)
+	if err != nil || deleted != 1 {
+		t.Fatalf("Expected the old export deleted, got %d (%v)", deleted, err)
+	}
+	objects, _ := store.List(ctx, "")
+	if len(objects) != 2 {
+		t.Errorf("Expected the new export and the attachment kept, got %+v", objects)
+	}
+}
+
+func TestS3SignedURL(t *testing.T) {
+	t.Setenv("STORAGE_S3_BUCKET", "mylo")
+	t.Setenv("STORAGE_S3_ENDPOINT", "minio.local:9000")
+	t.Setenv("STORAGE_S3_REGION", "us-east-1")
+	t.Setenv("STORAGE_S3_ACCESS_KEY", "access")
+	t.Setenv("STORAGE_S3_SECRET_KEY", "secret")
+	t.Setenv("STORAGE_S3_USE_SSL", "false")
+	t.Setenv("STORAGE_S3_PATH_STYLE", "true")
+
+	store, ok := NewFromEnv().(*S3)
+	if !ok {
+		t.Fatal("Expected an S3 store when STORAGE_S3_BUCKET is set")
+	}
+	link, err := store.SignedURL(context.Background(), "exports/1.csv", time.Hour, "subscribers.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(link)
+	if err != nil || u.Scheme != "http" || u.Host != "minio.local:9000" || u.Path != "/mylo/exports/1.csv" {
+		t.Fatalf("Unexpected link %q (%v)", link, err)
+	}
+	q := u.Query()
+	if q.Get("X-Amz-Signature") == "" || q.Get("X-Amz-Expires") != "3600" {
+		t.Errorf("Expected a presigned link valid an hour, got %q", link)
+	}
+	if q.Get("response-content-disposition") != `attachment; filename=subscribers.csv` {
+		t.Errorf("Expected the download named, got %q", q.Get("response-content-disposition"))
+	}
+}
@@ -0,0 +1,83 @@
+package telemetry
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const defaultServiceName = "mylocal-api"
+
+// tracer creates the spans of this module; a no-op until Init installs an exporter
+var tracer = otel.Tracer("fiber-gorm-api")
+
+// Enabled reports whether traces are exported: OTEL_EXPORTER_OTLP_ENDPOINT (or the traces
+// specific OTEL_EXPORTER_OTLP_TRACES_ENDPOINT) is set
+func Enabled() bool {
+	return os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != ""
+}
+
+// Init exports traces over OTLP/HTTP when Enabled, to the collector configured by the standard
+// OTEL_EXPORTER_OTLP_* variables, as OTEL_SERVICE_NAME (mylocal-api by default). The returned
+// function flushes pending spans and must run before the process exits.
+func Init(ctx context.Context) func(context.Context) error {
+	if !Enabled() {
+		return func(context.Context) error { return nil }
+	}
+
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		log.Fatalf("Could not create the OTLP trace exporter: %v", err)
+	}
+
+	name := os.Getenv("OTEL_SERVICE_NAME")
+	if name == "" {
+		name = defaultServiceName
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(name)))
+	if err != nil {
+		log.Fatalf("Could not describe the service for tracing: %v", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(provider)
+	// continue the traces of callers sending traceparent headers
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	log.Println("Exporting traces over OTLP as", name)
+	return provider.Shutdown
+}
+
+// Start opens a child span of ctx; pair it with End
+func Start(ctx context.Context, name string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name)
+}
+
+// End ends span, marking it failed when *err is set. Deferred with a named error result:
+//
+//	ctx, span := telemetry.Start(ctx, "subscriber.create")
+//	defer telemetry.End(span, &err)
+func End(span trace.Span, err *error) {
+	if err != nil && *err != nil {
+		span.RecordError(*err)
+		span.SetStatus(codes.Error, (*err).Error())
+	}
+	span.End()
+}
+
+// Trace runs fn in a child span of ctx, for calls that don't take a context themselves
+// (e.g. SendGrid)
+func Trace(ctx context.Context, name string, fn func() error) (err error) {
+	_, span := Start(ctx, name)
+	defer End(span, &err)
+	return fn()
+}
@@ -0,0 +1,40 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTrace(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	create := func(ctx context.Context) (err error) {
+		ctx, span := Start(ctx, "subscriber.create")
+		defer End(span, &err)
+		return Trace(ctx, "sendgrid.send_confirmation", func() error { return errors.New("sendgrid down") })
+	}
+	if err := create(context.Background()); err == nil {
+		t.Fatal("Expected the error to be returned")
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 spans, got %d", len(spans))
+	}
+	send, parent := spans[0], spans[1]
+	if send.Name() != "sendgrid.send_confirmation" || send.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Errorf("Expected the send to be a child of the create, got %s under %s", send.Name(), send.Parent().SpanID())
+	}
+	for _, span := range spans {
+		if span.Status().Code != codes.Error {
+			t.Errorf("Expected %s to be marked failed, got %v", span.Name(), span.Status())
+		}
+	}
+}
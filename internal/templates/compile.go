@@ -0,0 +1,98 @@
+package templates
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+
+	"fiber-gorm-api/internal/models"
+)
+
+// Formats a template's HTML is authored in: HTML itself, or Markdown or MJML compiled to it
+const (
+	FormatHTML     = "html"
+	FormatMarkdown = "markdown"
+	FormatMJML     = "mjml"
+)
+
+// Formats lists every valid EmailTemplate.Format
+var Formats = []string{FormatHTML, FormatMarkdown, FormatMJML}
+
+// PartSource is the Markdown or MJML source of a template, where its compilation errors are
+const PartSource = "source"
+
+// templateAction is an action of a Go template, kept as it is through compilation
+var templateAction = regexp.MustCompile(`\{\{.*?\}\}`)
+
+// Actions are swapped for placeholders of private use characters while compiling, so escaping
+// leaves them alone
+const (
+	placeholderStart = "\uE000"
+	placeholderEnd   = "\uE001"
+)
+
+var placeholder = regexp.MustCompile(placeholderStart + `(\d+)` + placeholderEnd)
+
+// protect replaces the actions of source by placeholders, returning them in order
+func protect(source string) (string, []string) {
+	var actions []string
+	out := templateAction.ReplaceAllStringFunc(source, func(action string) string {
+		actions = append(actions, action)
+		return placeholderStart + strconv.Itoa(len(actions)-1) + placeholderEnd
+	})
+	return out, actions
+}
+
+// restore puts back the actions protect replaced
+func restore(compiled string, actions []string) string {
+	return placeholder.ReplaceAllStringFunc(compiled, func(p string) string {
+		i, _ := strconv.Atoi(placeholder.FindStringSubmatch(p)[1])
+		return actions[i]
+	})
+}
+
+// isPlaceholder reports whether s is only the placeholder of an action
+func isPlaceholder(s string) bool {
+	m := placeholder.FindStringIndex(s)
+	return m != nil && m[0] == 0 && m[1] == len(s)
+}
+
+// sourceError is a compilation error at line and column of the source
+func sourceError(line, column int, format string, args ...interface{}) *Error {
+	return &Error{Part: PartSource, Line: line, Column: column, Message: fmt.Sprintf(format, args...)}
+}
+
+// Compile sets the HTML of a template authored in Markdown or MJML from its source, and the text
+// of a Markdown one without any to its source, which reads well as is. It returns the *Error of
+// a source that doesn't compile, with its line and column; HTML templates are left as they are.
+func Compile(t *models.EmailTemplate) error {
+	if t.Format == FormatHTML || t.Format == "" {
+		return nil
+	}
+	// actions are checked on the source, for their errors to point at it
+	if _, err := texttemplate.New(PartSource).Parse(t.Source); err != nil {
+		return locate(PartSource, err)
+	}
+
+	source, actions := protect(t.Source)
+	var compiled string
+	var err error
+	switch t.Format {
+	case FormatMarkdown:
+		compiled, err = markdownHTML(source)
+		if strings.TrimSpace(t.Text) == "" {
+			t.Text = t.Source
+		}
+	case FormatMJML:
+		compiled, err = mjmlHTML(source)
+	default:
+		return fmt.Errorf("unknown template format %q", t.Format)
+	}
+	if err != nil {
+		return err
+	}
+	t.HTML = restore(compiled, actions)
+	return nil
+}
@@ -0,0 +1,169 @@
+package templates
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"fiber-gorm-api/internal/models"
+)
+
+func TestMarkdownHTML(t *testing.T) {
+	for source, want := range map[string]string{
+		"# Market day":                             "<h1>Market day</h1>",
+		"## Stalls ##":                             "<h2>Stalls</h2>",
+		"Hello *you* and **all** of _you_":         "Hello <em>you</em> and <strong>all</strong> of <em>you</em>",
+		"snake_case_name stays":                    "snake_case_name stays",
+		"2 * 3 * 4":                                "2 * 3 * 4",
+		"Tom & Jerry <3 &amp; `a<b`":               "Tom &amp; Jerry &lt;3 &amp; <code style=\"background-color:#f4f4f4;padding:0 4px;\">a&lt;b</code>",
+		`\*not emphasis\*`:                         "*not emphasis*",
+		"[Map](https://example.com/map \"Where\")": `<a href="https://example.com/map" title="Where">Map</a>`,
+		"![Logo](https://example.com/logo.png)":    `<img src="https://example.com/logo.png" alt="Logo" style="max-width:100%;height:auto;border:0;">`,
+		"<https://example.com>":                    `<a href="https://example.com">https://example.com</a>`,
+		"Hi <b>there</b>":                          "Hi <b>there</b>",
+		"one  \ntwo":                               "one<br>\ntwo",
+		"one\ntwo":                                 "one\ntwo",
+		"- a\n- b":                                 "<ul style=\"margin:0 0 16px;padding-left:24px;\">\n<li>a</li>\n<li>b</li>\n</ul>",
+		"3. c\n4. d":                               "<ol start=\"3\" style=\"margin:0 0 16px;padding-left:24px;\">\n<li>c</li>\n<li>d</li>\n</ol>",
+		"- a\n\n- b":                               "<li><p style=\"margin:0 0 16px;\">a</p></li>",
+		"- a\n  - b":                               "<li>a\n<ul style=\"margin:0 0 16px;padding-left:24px;\">\n<li>b</li>\n</ul></li>",
+		"> quoted\n> text":                         "<blockquote style=\"margin:0 0 16px;padding-left:12px;border-left:4px solid #dddddd;color:#555555;\">\n<p style=\"margin:0 0 16px;\">quoted\ntext</p>\n</blockquote>",
+		"```go\nx := <y>\n```":                     "<pre style=\"background-color:#f4f4f4;padding:12px;overflow-x:auto;\"><code class=\"language-go\">x := &lt;y&gt;\n</code></pre>",
+		"---":                                      "<hr",
+		"<table>\n<tr><td>*as is*</td></tr>\n</table>": "<table>\n<tr><td>*as is*</td></tr>\n</table>",
+	} {
+		got, err := markdownHTML(source)
+		if err != nil {
+			t.Errorf("%q: %v", source, err)
+			continue
+		}
+		if !strings.Contains(got, want) {
+			t.Errorf("%q: expected %q in\n%s", source, want, got)
+		}
+	}
+
+	got, _ := markdownHTML("Hello")
+	for _, want := range []string{`name="viewport"`, "max-width:600px", `<p style="margin:0 0 16px;">Hello</p>`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected %q in the layout", want)
+		}
+	}
+}
+
+func TestMarkdownErrors(t *testing.T) {
+	for source, want := range map[string]Error{
+		"Hello\n\n```\ncode":                         {Part: PartSource, Line: 3, Column: 1, Message: "code block opened with ``` is never closed"},
+		"Hi\nclick [here](javascript:alert(1))":      {Part: PartSource, Line: 2, Column: 7},
+		"- item\n  ![x](data:image/png;base64,AAAA)": {Part: PartSource, Line: 2, Column: 3},
+	} {
+		_, err := markdownHTML(source)
+		var got *Error
+		if !errors.As(err, &got) || got.Line != want.Line || got.Column != want.Column || (want.Message != "" && got.Message != want.Message) {
+			t.Errorf("%q: expected %+v, got %v", source, want, err)
+		}
+	}
+}
+
+const market = `<mjml>
+  <mj-head>
+    <mj-title>Market day</mj-title>
+    <mj-preview>Saturday from 9</mj-preview>
+  </mj-head>
+  <mj-body background-color="#eeeeee">
+    <mj-section>
+      <mj-column width="40%">
+        <mj-image src="https://example.com/logo.png" alt="Logo" />
+      </mj-column>
+      <mj-column>
+        <mj-text font-size="16px">Hi <b>{{.FirstName}}</b></mj-text>
+        <mj-button href="{{.PreferencesURL}}">Preferences</mj-button>
+        <mj-divider />
+        <mj-spacer height="10px" />
+      </mj-column>
+    </mj-section>
+  </mj-body>
+</mjml>`
+
+func TestMJMLHTML(t *testing.T) {
+	source, actions := protect(market)
+	compiled, err := mjmlHTML(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := restore(compiled, actions)
+	for _, want := range []string{
+		"<title>Market day</title>",
+		">Saturday from 9</div>",
+		"background-color:#eeeeee;",
+		`class="mj-column-per-40"`,
+		`class="mj-column-per-50"`, // columns without a width share the section
+		".mj-column-per-40 { width: 40% !important; max-width: 40%; }",
+		"@media only screen and (min-width:480px)",
+		`<img alt="Logo" src="https://example.com/logo.png"`,
+		`width="190"`, // 40% of 600px, less the padding
+		"font-size:16px;",
+		"Hi <b>{{.FirstName}}</b></div>",
+		`<a href="{{.PreferencesURL}}"`,
+		"border-top:solid 4px #000000;",
+		"height:10px;",
+		`<td style="vertical-align:top;width:240px;">`,
+		`<td style="vertical-align:top;width:300px;">`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected %q in\n%s", want, got)
+		}
+	}
+}
+
+func TestMJMLErrors(t *testing.T) {
+	for source, want := range map[string]Error{
+		"":                                       {Line: 1, Column: 1, Message: "an MJML template is an <mjml> element"},
+		"<mjml>\n  <mj-head></mj-head>\n</mjml>": {Line: 1, Column: 1, Message: "<mjml> has no <mj-body>"},
+		"<mjml><mj-body>\n  <mj-sectio></mj-sectio>":                                {Line: 2, Column: 3, Message: "unknown element <mj-sectio>"},
+		"<mjml><mj-body>\n  <mj-column>":                                            {Line: 2, Column: 3, Message: "<mj-column> can't be in <mj-body>"},
+		"<mjml><mj-body>\n  <mj-section>\n":                                         {Line: 2, Column: 3, Message: "<mj-section> is never closed"},
+		"<mjml><mj-body><mj-section><mj-column>\n  <mj-text>Hi\n</mj-column>":       {Line: 2, Column: 3, Message: "<mj-text> is never closed"},
+		"<mjml><mj-body><mj-section><mj-column>\n  <mj-button>Go</mj-button>":       {Line: 2, Column: 3, Message: "<mj-button> has no href"},
+		"<mjml><mj-body>\n<mj-section colour=\"red\">":                              {Line: 2, Column: 13, Message: "unknown attribute colour of <mj-section>"},
+		"<mjml><mj-body>\n<mj-section padding=\"0;x:y\">":                           {Line: 2, Column: 13, Message: "attribute padding of <mj-section> isn't a CSS value"},
+		"<mjml><mj-body><mj-section>\n<mj-column width=\"half\">":                   {Line: 2, Column: 12, Message: "attribute width of <mj-column> isn't a width in % or px"},
+		"<mjml><mj-body><mj-section><mj-column>\n<mj-image src=\"javascript:x\" />": {Line: 2, Column: 11, Message: "attribute src of <mj-image> isn't an http(s), mailto or tel link"},
+		"<mjml><mj-body>\n  Hello":                                                  {Line: 2, Column: 3, Message: "text must be in an <mj-text>, <mj-button>, <mj-table> or <mj-raw>"},
+		"<mjml><mj-body></mj-section></mj-body></mjml>":                             {Line: 1, Column: 16, Message: "expected </mj-body>, found </mj-section>"},
+	} {
+		_, err := mjmlHTML(source)
+		var got *Error
+		if !errors.As(err, &got) || got.Part != PartSource || got.Line != want.Line || got.Column != want.Column || got.Message != want.Message {
+			t.Errorf("%q: expected %+v, got %v", source, want, err)
+		}
+	}
+}
+
+func TestCompile(t *testing.T) {
+	tmpl := models.EmailTemplate{Format: FormatMarkdown, Source: "Hi **{{.FirstName}}**, see [your preferences]({{.PreferencesURL}})"}
+	if err := Compile(&tmpl); err != nil {
+		t.Fatal(err)
+	}
+	if tmpl.Text != tmpl.Source {
+		t.Errorf("Expected the text to default to the source, got %q", tmpl.Text)
+	}
+	tmpl.Subject = "Hello"
+	out, err := Render(tmpl, SampleVariables)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.HTML, `Hi <strong>Jane</strong>, see <a href="https://signup.mylocal.ing/preferences">your preferences</a>`) {
+		t.Errorf("Unexpected HTML %s", out.HTML)
+	}
+
+	tmpl = models.EmailTemplate{Format: FormatMJML, Source: "<mjml>\n<mj-body>{{.Name</mj-body></mjml>"}
+	var tmplErr *Error
+	if err := Compile(&tmpl); !errors.As(err, &tmplErr) || tmplErr.Part != PartSource || tmplErr.Line != 2 {
+		t.Errorf("Expected the action error located in the source, got %v", err)
+	}
+
+	tmpl = models.EmailTemplate{HTML: "<p>as is</p>"}
+	if err := Compile(&tmpl); err != nil || tmpl.HTML != "<p>as is</p>" {
+		t.Errorf("Expected an HTML template left as is, got %q (%v)", tmpl.HTML, err)
+	}
+}
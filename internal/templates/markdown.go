@@ -0,0 +1,524 @@
+package templates
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// The Markdown of templates is the common part of CommonMark: headings, paragraphs, emphasis,
+// links and images, lists, quotes, code and rules, plus HTML as is.
+
+var (
+	mdHeading    = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	mdRule       = regexp.MustCompile(`^ {0,3}([-*_])(?:[ \t]*[-*_]){2,}[ \t]*$`)
+	mdFence      = regexp.MustCompile("^( {0,3})(```+|~~~+)[ \t]*([^`\\s]*)")
+	mdQuote      = regexp.MustCompile(`^ {0,3}> ?`)
+	mdListItem   = regexp.MustCompile(`^( {0,3})([-*+]|\d{1,9}[.)])([ \t]+|$)`)
+	mdHTMLBlock  = regexp.MustCompile(`^ {0,3}<(?:/?[a-zA-Z][a-zA-Z0-9-]*(?:[\s/>]|$)|!--)`)
+	mdInlineHTML = regexp.MustCompile(`^(?:</?[a-zA-Z][a-zA-Z0-9-]*(?:\s+[a-zA-Z_:][-a-zA-Z0-9_:.]*(?:\s*=\s*(?:"[^"]*"|'[^']*'|[^\s"'=<>` + "`" + `]+))?)*\s*/?>|<!--[\s\S]*?-->)`)
+	mdAutolink   = regexp.MustCompile(`^<((?:https?://|mailto:)[^\s<>]+)>`)
+	mdEntity     = regexp.MustCompile(`^&(?:[a-zA-Z][a-zA-Z0-9]{1,31}|#[0-9]{1,7}|#[xX][0-9a-fA-F]{1,6});`)
+	mdSafeURL    = regexp.MustCompile(`(?i)^(?:https?://|mailto:|tel:|#|/|\.|[^:/?#]*(?:[/?#]|$))`)
+)
+
+// mdHardBreak ends a line of a paragraph that breaks, while inline Markdown is compiled
+const mdHardBreak = "\x00"
+
+// mdLine is a line of Markdown, with where its text starts in the source
+type mdLine struct {
+	text   string
+	line   int
+	column int
+}
+
+// markdownHTML compiles Markdown to an email laid out for any screen width
+func markdownHTML(source string) (string, error) {
+	var lines []mdLine
+	for i, text := range strings.Split(strings.ReplaceAll(source, "\r\n", "\n"), "\n") {
+		lines = append(lines, mdLine{text: strings.ReplaceAll(text, "\t", "    "), line: i + 1, column: 1})
+	}
+	body, err := mdBlocks(lines, false)
+	if err != nil {
+		return "", err
+	}
+	return markdownLayout(body), nil
+}
+
+func blank(s string) bool {
+	return strings.TrimSpace(s) == ""
+}
+
+// dedent strips up to n spaces from the start of l
+func dedent(l mdLine, n int) mdLine {
+	i := 0
+	for i < n && i < len(l.text) && l.text[i] == ' ' {
+		i++
+	}
+	return mdLine{text: l.text[i:], line: l.line, column: l.column + i}
+}
+
+// interrupts reports whether a line ends the paragraph before it
+func interrupts(text string) bool {
+	if mdHeading.MatchString(text) || mdRule.MatchString(text) || mdFence.MatchString(text) ||
+		mdQuote.MatchString(text) || mdHTMLBlock.MatchString(text) {
+		return true
+	}
+	m := mdListItem.FindStringSubmatch(text)
+	// an ordered list interrupts a paragraph from 1 only, and no empty item does
+	return m != nil && m[3] != "" && (!isDigit(m[2][0]) || strings.HasPrefix(m[2], "1"))
+}
+
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
+
+// mdBlocks compiles the blocks of lines, paragraphs without <p> in tight list items
+func mdBlocks(lines []mdLine, tight bool) (string, error) {
+	var out strings.Builder
+	for i := 0; i < len(lines); {
+		l := lines[i]
+		switch {
+		case blank(l.text):
+			i++
+
+		case mdFence.MatchString(l.text):
+			m := mdFence.FindStringSubmatch(l.text)
+			indent, fence, lang := len(m[1]), m[2], m[3]
+			j := i + 1
+			for j < len(lines) && !isClosingFence(lines[j].text, fence) {
+				j++
+			}
+			if j == len(lines) {
+				return "", sourceError(l.line, l.column+indent, "code block opened with %s is never closed", fence)
+			}
+			out.WriteString(`<pre style="background-color:#f4f4f4;padding:12px;overflow-x:auto;"><code`)
+			if lang != "" {
+				out.WriteString(` class="language-` + html.EscapeString(lang) + `"`)
+			}
+			out.WriteString(">")
+			for _, code := range lines[i+1 : j] {
+				out.WriteString(html.EscapeString(dedent(code, indent).text) + "\n")
+			}
+			out.WriteString("</code></pre>\n")
+			i = j + 1
+
+		case mdHeading.MatchString(l.text):
+			m := mdHeading.FindStringSubmatch(l.text)
+			level := strconv.Itoa(len(m[1]))
+			text, err := mdInline([]mdLine{{text: m[2], line: l.line, column: l.column + strings.Index(l.text, m[2])}})
+			if err != nil {
+				return "", err
+			}
+			out.WriteString("<h" + level + ">" + text + "</h" + level + ">\n")
+			i++
+
+		case mdRule.MatchString(l.text):
+			out.WriteString(`<hr style="border:0;border-top:1px solid #dddddd;margin:24px 0;">` + "\n")
+			i++
+
+		case mdQuote.MatchString(l.text):
+			var quoted []mdLine
+			for ; i < len(lines) && mdQuote.MatchString(lines[i].text); i++ {
+				n := len(mdQuote.FindString(lines[i].text))
+				quoted = append(quoted, mdLine{text: lines[i].text[n:], line: lines[i].line, column: lines[i].column + n})
+			}
+			inner, err := mdBlocks(quoted, false)
+			if err != nil {
+				return "", err
+			}
+			out.WriteString(`<blockquote style="margin:0 0 16px;padding-left:12px;border-left:4px solid #dddddd;color:#555555;">` + "\n" + inner + "</blockquote>\n")
+
+		case mdHTMLBlock.MatchString(l.text):
+			for ; i < len(lines) && !blank(lines[i].text); i++ {
+				out.WriteString(lines[i].text + "\n")
+			}
+
+		case mdListItem.MatchString(l.text):
+			list, next, err := mdList(lines, i)
+			if err != nil {
+				return "", err
+			}
+			out.WriteString(list)
+			i = next
+
+		default:
+			para := []mdLine{dedent(l, 3)}
+			for i++; i < len(lines) && !blank(lines[i].text) && !interrupts(lines[i].text); i++ {
+				para = append(para, dedent(lines[i], len(lines[i].text)))
+			}
+			text, err := mdInline(para)
+			if err != nil {
+				return "", err
+			}
+			if tight {
+				out.WriteString(text + "\n")
+			} else {
+				out.WriteString(`<p style="margin:0 0 16px;">` + text + "</p>\n")
+			}
+		}
+	}
+	return out.String(), nil
+}
+
+func isClosingFence(text, fence string) bool {
+	t := strings.TrimSpace(text)
+	return strings.HasPrefix(t, fence) && strings.Trim(t, fence[:1]) == "" && len(text)-len(strings.TrimLeft(text, " ")) < 4
+}
+
+// mdList compiles the list starting at lines[start], returning the index of the line after it
+func mdList(lines []mdLine, start int) (string, int, error) {
+	first := mdListItem.FindStringSubmatch(lines[start].text)
+	ordered := isDigit(first[2][0])
+	delimiter := first[2][len(first[2])-1:]
+
+	type item struct{ lines []mdLine }
+	var items []item
+	tight := true
+	i := start
+	for i < len(lines) {
+		m := mdListItem.FindStringSubmatch(lines[i].text)
+		if m == nil || isDigit(m[2][0]) != ordered || m[2][len(m[2])-1:] != delimiter {
+			break
+		}
+		width := len(m[0])
+		if m[3] == "" || len(m[3]) > 4 {
+			// an empty item, or one starting with indented code, is indented past one space
+			width = len(m[1]) + len(m[2]) + 1
+		}
+		it := item{lines: []mdLine{{text: lines[i].text[min(width, len(lines[i].text)):], line: lines[i].line, column: lines[i].column + width}}}
+		i++
+		for i < len(lines) {
+			l := lines[i]
+			indent := len(l.text) - len(strings.TrimLeft(l.text, " "))
+			switch {
+			case blank(l.text):
+				// the item goes on if the next line that isn't blank is indented under it
+				j := i
+				for j < len(lines) && blank(lines[j].text) {
+					j++
+				}
+				if j == len(lines) || len(lines[j].text)-len(strings.TrimLeft(lines[j].text, " ")) < width {
+					if j < len(lines) && mdListItem.MatchString(lines[j].text) {
+						tight = false
+					}
+					i = j
+					goto nextItem
+				}
+				tight = false
+				for ; i < j; i++ {
+					it.lines = append(it.lines, mdLine{line: lines[i].line, column: 1})
+				}
+			case indent >= width:
+				it.lines = append(it.lines, dedent(l, width))
+				i++
+			case mdListItem.MatchString(l.text):
+				goto nextItem
+			case !interrupts(l.text) && !blank(it.lines[len(it.lines)-1].text):
+				// lazy continuation of a paragraph
+				it.lines = append(it.lines, dedent(l, indent))
+				i++
+			default:
+				goto nextItem
+			}
+		}
+	nextItem:
+		items = append(items, it)
+		if i < len(lines) && (blank(lines[i].text) || !mdListItem.MatchString(lines[i].text)) {
+			break
+		}
+	}
+
+	var out strings.Builder
+	tag := "ul"
+	if ordered {
+		tag = "ol"
+	}
+	out.WriteString("<" + tag)
+	if n, _ := strconv.Atoi(strings.TrimRight(first[2], ".)")); ordered && n != 1 {
+		out.WriteString(` start="` + strconv.Itoa(n) + `"`)
+	}
+	out.WriteString(` style="margin:0 0 16px;padding-left:24px;">` + "\n")
+	for _, it := range items {
+		inner, err := mdBlocks(it.lines, tight)
+		if err != nil {
+			return "", i, err
+		}
+		out.WriteString("<li>" + strings.TrimSuffix(inner, "\n") + "</li>\n")
+	}
+	out.WriteString("</" + tag + ">\n")
+	return out.String(), i, nil
+}
+
+// mdInline compiles the emphasis, links, images, code and HTML of a paragraph's lines, a line
+// ending with two spaces or a backslash breaking the line
+func mdInline(lines []mdLine) (string, error) {
+	var text strings.Builder
+	// starts[i] is where lines[i] starts in text
+	starts := make([]int, len(lines))
+	for i, l := range lines {
+		starts[i] = text.Len()
+		t := l.text
+		if i < len(lines)-1 {
+			trimmed := strings.TrimRight(t, " ")
+			switch {
+			case len(t)-len(trimmed) >= 2:
+				t = trimmed + mdHardBreak
+			case strings.HasSuffix(trimmed, `\`):
+				t = strings.TrimSuffix(trimmed, `\`) + mdHardBreak
+			default:
+				t = trimmed + "\n"
+			}
+		} else {
+			t = strings.TrimRight(t, " ")
+		}
+		text.WriteString(t)
+	}
+	p := &mdInlineParser{src: text.String(), lines: lines, starts: starts}
+	return p.parse(p.src, 0)
+}
+
+type mdInlineParser struct {
+	src    string
+	lines  []mdLine
+	starts []int
+}
+
+// errorAt is a compilation error at the offset at of the paragraph
+func (p *mdInlineParser) errorAt(at int, format string, args ...interface{}) error {
+	i := len(p.starts) - 1
+	for i > 0 && p.starts[i] > at {
+		i--
+	}
+	return sourceError(p.lines[i].line, p.lines[i].column+at-p.starts[i], format, args...)
+}
+
+// parse compiles s, found at offset base of the paragraph
+func (p *mdInlineParser) parse(s string, base int) (string, error) {
+	var out strings.Builder
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && strings.ContainsRune("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", rune(s[i+1])):
+			out.WriteString(html.EscapeString(s[i+1 : i+2]))
+			i += 2
+
+		case c == '`':
+			n := runLength(s[i:], '`')
+			end := strings.Index(s[i+n:], s[i:i+n])
+			if end < 0 {
+				out.WriteString(s[i : i+n])
+				i += n
+				continue
+			}
+			code := strings.ReplaceAll(s[i+n:i+n+end], "\n", " ")
+			if len(code) > 2 && code[0] == ' ' && code[len(code)-1] == ' ' {
+				code = code[1 : len(code)-1]
+			}
+			out.WriteString(`<code style="background-color:#f4f4f4;padding:0 4px;">` + html.EscapeString(code) + "</code>")
+			i += n + end + n
+
+		case c == '!' && strings.HasPrefix(s[i+1:], "["), c == '[':
+			image := c == '!'
+			open := i
+			if image {
+				open++
+			}
+			label, dest, title, end, ok := parseLink(s, open)
+			if !ok {
+				out.WriteString(html.EscapeString(s[i : open+1]))
+				i = open + 1
+				continue
+			}
+			if !isPlaceholder(dest) && !mdSafeURL.MatchString(dest) {
+				return "", p.errorAt(base+i, "link to %q isn't allowed, only http(s), mailto and tel links", dest)
+			}
+			attrs := ""
+			if title != "" {
+				attrs = ` title="` + html.EscapeString(title) + `"`
+			}
+			if image {
+				out.WriteString(`<img src="` + html.EscapeString(dest) + `" alt="` + html.EscapeString(plainText(label)) + `"` + attrs + ` style="max-width:100%;height:auto;border:0;">`)
+			} else {
+				inner, err := p.parse(label, base+open+1)
+				if err != nil {
+					return "", err
+				}
+				out.WriteString(`<a href="` + html.EscapeString(dest) + `"` + attrs + `>` + inner + "</a>")
+			}
+			i = end
+
+		case c == '<':
+			if m := mdAutolink.FindStringSubmatch(s[i:]); m != nil {
+				out.WriteString(`<a href="` + html.EscapeString(m[1]) + `">` + html.EscapeString(strings.TrimPrefix(m[1], "mailto:")) + "</a>")
+				i += len(m[0])
+			} else if m := mdInlineHTML.FindString(s[i:]); m != "" {
+				out.WriteString(m)
+				i += len(m)
+			} else {
+				out.WriteString("&lt;")
+				i++
+			}
+
+		case c == '&':
+			if m := mdEntity.FindString(s[i:]); m != "" {
+				out.WriteString(m)
+				i += len(m)
+			} else {
+				out.WriteString("&amp;")
+				i++
+			}
+
+		case c == '*' || c == '_':
+			n := min(runLength(s[i:], c), 3)
+			end := closingDelimiter(s, i, n)
+			if end < 0 {
+				out.WriteString(s[i : i+runLength(s[i:], c)])
+				i += runLength(s[i:], c)
+				continue
+			}
+			inner, err := p.parse(s[i+n:end], base+i+n)
+			if err != nil {
+				return "", err
+			}
+			switch n {
+			case 1:
+				inner = "<em>" + inner + "</em>"
+			case 2:
+				inner = "<strong>" + inner + "</strong>"
+			default:
+				inner = "<strong><em>" + inner + "</em></strong>"
+			}
+			out.WriteString(inner)
+			i = end + n
+
+		case c == mdHardBreak[0]:
+			out.WriteString("<br>\n")
+			i++
+
+		default:
+			out.WriteString(html.EscapeString(s[i : i+1]))
+			i++
+		}
+	}
+	return out.String(), nil
+}
+
+func runLength(s string, c byte) int {
+	n := 0
+	for n < len(s) && s[n] == c {
+		n++
+	}
+	return n
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\n' || b == '\t'
+}
+
+func isAlnum(b byte) bool {
+	return isDigit(b) || (b|0x20 >= 'a' && b|0x20 <= 'z')
+}
+
+// closingDelimiter returns where the run of n delimiters opening at s[i] is closed, -1 when it
+// isn't. An opening run isn't followed by a space, a closing one not preceded by one, and
+// underscores don't emphasize within words.
+func closingDelimiter(s string, i, n int) int {
+	c := s[i]
+	after := i + runLength(s[i:], c)
+	if after >= len(s) || isSpace(s[after]) || (c == '_' && i > 0 && isAlnum(s[i-1])) {
+		return -1
+	}
+	for j := i + n; j+n <= len(s); j++ {
+		switch {
+		case s[j] == '`' || s[j] == '\\':
+			// skip escapes and code spans
+			if s[j] == '\\' {
+				j++
+			} else if end := strings.IndexByte(s[j+1:], '`'); end >= 0 {
+				j += end + 1
+			}
+		case s[j] == c:
+			run := runLength(s[j:], c)
+			if run >= n && !isSpace(s[j-1]) && j > i+n && (c != '_' || j+run >= len(s) || !isAlnum(s[j+run])) {
+				// the closing run is the last n of the run
+				return j + run - n
+			}
+			j += run - 1
+		}
+	}
+	return -1
+}
+
+// parseLink reads [label](dest "title") at s[open], returning the offset after it
+func parseLink(s string, open int) (label, dest, title string, end int, ok bool) {
+	depth := 0
+	closing := -1
+	for j := open; j < len(s) && closing < 0; j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case '[':
+			depth++
+		case ']':
+			if depth--; depth == 0 {
+				closing = j
+			}
+		}
+	}
+	if closing < 0 || closing+1 >= len(s) || s[closing+1] != '(' {
+		return "", "", "", 0, false
+	}
+	rest := s[closing+2:]
+	stop := strings.IndexByte(rest, ')')
+	if stop < 0 {
+		return "", "", "", 0, false
+	}
+	inside := strings.TrimSpace(rest[:stop])
+	dest, title = inside, ""
+	if sp := strings.IndexAny(inside, " \n"); sp >= 0 {
+		dest, title = inside[:sp], strings.TrimSpace(inside[sp:])
+		if len(title) < 2 || !(title[0] == '"' && title[len(title)-1] == '"' || title[0] == '\'' && title[len(title)-1] == '\'') {
+			return "", "", "", 0, false
+		}
+		title = title[1 : len(title)-1]
+	}
+	dest = strings.TrimSuffix(strings.TrimPrefix(dest, "<"), ">")
+	return s[open+1 : closing], dest, title, closing + 2 + stop + 1, true
+}
+
+// plainText is the text of inline Markdown, for the alt of images
+var mdMarkup = regexp.MustCompile("[*_`]|!?\\[|\\]\\([^)]*\\)")
+
+func plainText(s string) string {
+	return mdMarkup.ReplaceAllString(s, "")
+}
+
+// markdownLayout lays compiled Markdown out in a column of at most 600px, centered, with the
+// styles inlined for the mail clients that drop <style>
+func markdownLayout(body string) string {
+	return `<!doctype html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width,initial-scale=1">
+<meta http-equiv="X-UA-Compatible" content="IE=edge">
+<style>
+body { margin: 0; padding: 0; }
+h1, h2, h3, h4, h5, h6 { margin: 0 0 12px; line-height: 1.25; }
+a { color: #1a73e8; }
+@media only screen and (max-width: 480px) { .container { padding: 16px !important; } }
+</style>
+</head>
+<body style="margin:0;padding:0;background-color:#f4f4f4;">
+<table role="presentation" width="100%" cellpadding="0" cellspacing="0" border="0" style="background-color:#f4f4f4;">
+<tr><td align="center" style="padding:24px 8px;">
+<div class="container" style="max-width:600px;margin:0 auto;background-color:#ffffff;padding:24px;font-family:Helvetica,Arial,sans-serif;font-size:16px;line-height:1.5;color:#222222;text-align:left;">
+` + body + `</div>
+</td></tr>
+</table>
+</body>
+</html>
+`
+}
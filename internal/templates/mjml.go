@@ -0,0 +1,581 @@
+package templates
+
+import (
+	"html"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The MJML of templates is the core of MJML (https://documentation.mjml.io): sections of
+// columns holding text, buttons, images, dividers, spacers, tables and raw HTML, with a head
+// for the title, preview and styles. It compiles to the same table-based HTML, whose columns
+// stack on narrow screens.
+
+const (
+	mjmlBodyWidth  = 600
+	mjmlBreakpoint = "480px"
+	mjmlFontFamily = "Ubuntu, Helvetica, Arial, sans-serif"
+)
+
+// Kinds of values of MJML attributes
+const (
+	mjmlCSS    = iota // a CSS value, inlined in style
+	mjmlURL           // a link, http(s), mailto or tel
+	mjmlText          // plain text, such as an alt
+	mjmlWidth         // a width in % or px
+	mjmlPixels        // a width in px
+)
+
+// mjmlElements are the elements of MJML, with their parents and attributes
+var mjmlElements = map[string]struct {
+	parents []string
+	attrs   map[string]int
+}{
+	"mjml":       {parents: []string{""}},
+	"mj-head":    {parents: []string{"mjml"}},
+	"mj-title":   {parents: []string{"mj-head"}},
+	"mj-preview": {parents: []string{"mj-head"}},
+	"mj-style":   {parents: []string{"mj-head"}},
+	"mj-body": {parents: []string{"mjml"}, attrs: map[string]int{
+		"width": mjmlPixels, "background-color": mjmlCSS,
+	}},
+	"mj-section": {parents: []string{"mj-body"}, attrs: map[string]int{
+		"background-color": mjmlCSS, "border": mjmlCSS, "padding": mjmlCSS, "text-align": mjmlCSS,
+	}},
+	"mj-column": {parents: []string{"mj-section"}, attrs: map[string]int{
+		"width": mjmlWidth, "background-color": mjmlCSS, "vertical-align": mjmlCSS,
+	}},
+	"mj-text": {parents: []string{"mj-column"}, attrs: map[string]int{
+		"align": mjmlCSS, "color": mjmlCSS, "container-background-color": mjmlCSS, "font-family": mjmlCSS,
+		"font-size": mjmlCSS, "font-style": mjmlCSS, "font-weight": mjmlCSS, "letter-spacing": mjmlCSS,
+		"line-height": mjmlCSS, "padding": mjmlCSS,
+	}},
+	"mj-button": {parents: []string{"mj-column"}, attrs: map[string]int{
+		"href": mjmlURL, "align": mjmlCSS, "background-color": mjmlCSS, "border-radius": mjmlCSS,
+		"color": mjmlCSS, "container-background-color": mjmlCSS, "font-family": mjmlCSS, "font-size": mjmlCSS,
+		"font-weight": mjmlCSS, "inner-padding": mjmlCSS, "padding": mjmlCSS, "target": mjmlText,
+	}},
+	"mj-image": {parents: []string{"mj-column"}, attrs: map[string]int{
+		"src": mjmlURL, "href": mjmlURL, "alt": mjmlText, "title": mjmlText, "width": mjmlPixels,
+		"align": mjmlCSS, "border-radius": mjmlCSS, "container-background-color": mjmlCSS, "padding": mjmlCSS,
+	}},
+	"mj-divider": {parents: []string{"mj-column"}, attrs: map[string]int{
+		"border-color": mjmlCSS, "border-style": mjmlCSS, "border-width": mjmlCSS,
+		"container-background-color": mjmlCSS, "padding": mjmlCSS, "width": mjmlWidth,
+	}},
+	"mj-spacer": {parents: []string{"mj-column"}, attrs: map[string]int{
+		"height": mjmlCSS, "container-background-color": mjmlCSS,
+	}},
+	"mj-table": {parents: []string{"mj-column"}, attrs: map[string]int{
+		"align": mjmlCSS, "cellpadding": mjmlCSS, "cellspacing": mjmlCSS, "color": mjmlCSS,
+		"container-background-color": mjmlCSS, "font-family": mjmlCSS, "font-size": mjmlCSS,
+		"line-height": mjmlCSS, "padding": mjmlCSS, "width": mjmlWidth,
+	}},
+	"mj-raw": {parents: []string{"mj-body", "mj-section", "mj-column"}},
+}
+
+// mjmlRequired are the attributes an element can't go without
+var mjmlRequired = map[string][]string{
+	"mj-button": {"href"},
+	"mj-image":  {"src"},
+}
+
+// mjmlEnding are the elements whose content is HTML or text rather than MJML
+var mjmlEnding = map[string]bool{
+	"mj-title": true, "mj-preview": true, "mj-style": true, "mj-text": true, "mj-button": true,
+	"mj-table": true, "mj-raw": true,
+}
+
+var (
+	mjmlStartTag  = regexp.MustCompile(`^<([a-zA-Z][a-zA-Z0-9-]*)`)
+	mjmlEndTag    = regexp.MustCompile(`^</([a-zA-Z][a-zA-Z0-9-]*)\s*>`)
+	mjmlAttribute = regexp.MustCompile(`^([a-zA-Z][a-zA-Z0-9-]*)(\s*=\s*(?:"([^"]*)"|'([^']*)'))?`)
+	mjmlCSSValue  = regexp.MustCompile(`^[^;{}<>"\\]*$`)
+	mjmlWidthOf   = regexp.MustCompile(`^(\d+(?:\.\d+)?)(%|px)$`)
+	mjmlPixelsOf  = regexp.MustCompile(`^(\d+)px$`)
+)
+
+type mjmlNode struct {
+	name     string
+	attrs    map[string]string
+	children []*mjmlNode
+	content  string
+	at       int
+}
+
+func (n *mjmlNode) attr(name, fallback string) string {
+	if v, ok := n.attrs[name]; ok && v != "" {
+		return v
+	}
+	return fallback
+}
+
+// child returns the first child of n named name, nil when there's none
+func (n *mjmlNode) child(name string) *mjmlNode {
+	for _, c := range n.children {
+		if c.name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+type mjmlParser struct {
+	src        string
+	lineStarts []int
+}
+
+// errorAt is a compilation error at the offset at of the source
+func (p *mjmlParser) errorAt(at int, format string, args ...interface{}) error {
+	line := sort.Search(len(p.lineStarts), func(i int) bool { return p.lineStarts[i] > at })
+	return sourceError(line, at-p.lineStarts[line-1]+1, format, args...)
+}
+
+// mjmlHTML compiles MJML to HTML
+func mjmlHTML(source string) (string, error) {
+	p := &mjmlParser{src: source, lineStarts: []int{0}}
+	for i := 0; i < len(source); i++ {
+		if source[i] == '\n' {
+			p.lineStarts = append(p.lineStarts, i+1)
+		}
+	}
+	root, err := p.parse()
+	if err != nil {
+		return "", err
+	}
+	return renderMJML(root), nil
+}
+
+// parse reads the elements of the source into a tree, checking them on the way
+func (p *mjmlParser) parse() (*mjmlNode, error) {
+	src := p.src
+	root := &mjmlNode{}
+	stack := []*mjmlNode{root}
+	for i := 0; i < len(src); {
+		lt := strings.IndexByte(src[i:], '<')
+		text := src[i:]
+		if lt >= 0 {
+			text = src[i : i+lt]
+		}
+		if trimmed := strings.TrimLeft(text, " \t\r\n"); trimmed != "" {
+			return nil, p.errorAt(i+len(text)-len(trimmed), "text must be in an <mj-text>, <mj-button>, <mj-table> or <mj-raw>")
+		}
+		if lt < 0 {
+			break
+		}
+		i += lt
+
+		if strings.HasPrefix(src[i:], "<!--") {
+			end := strings.Index(src[i:], "-->")
+			if end < 0 {
+				return nil, p.errorAt(i, "comment is never closed")
+			}
+			i += end + len("-->")
+			continue
+		}
+
+		if strings.HasPrefix(src[i:], "</") {
+			m := mjmlEndTag.FindStringSubmatch(src[i:])
+			if m == nil {
+				return nil, p.errorAt(i, "malformed closing tag")
+			}
+			top := stack[len(stack)-1]
+			if top == root {
+				return nil, p.errorAt(i, "</%s> closes no element", m[1])
+			}
+			if m[1] != top.name {
+				return nil, p.errorAt(i, "expected </%s>, found </%s>", top.name, m[1])
+			}
+			stack = stack[:len(stack)-1]
+			i += len(m[0])
+			continue
+		}
+
+		parent := stack[len(stack)-1]
+		node, end, selfClosing, err := p.tag(i, parent)
+		if err != nil {
+			return nil, err
+		}
+		parent.children = append(parent.children, node)
+		i = end
+		switch {
+		case selfClosing:
+		case mjmlEnding[node.name]:
+			closing := regexp.MustCompile(`</` + node.name + `\s*>`).FindStringIndex(src[i:])
+			if closing == nil {
+				return nil, p.errorAt(node.at, "<%s> is never closed", node.name)
+			}
+			node.content = strings.TrimSpace(src[i : i+closing[0]])
+			i += closing[1]
+		default:
+			stack = append(stack, node)
+		}
+	}
+	if len(stack) > 1 {
+		top := stack[len(stack)-1]
+		return nil, p.errorAt(top.at, "<%s> is never closed", top.name)
+	}
+
+	if len(root.children) == 0 {
+		return nil, p.errorAt(0, "an MJML template is an <mjml> element")
+	}
+	mjml := root.children[0]
+	if len(root.children) > 1 {
+		return nil, p.errorAt(root.children[1].at, "an MJML template is one <mjml> element")
+	}
+	seen := map[string]bool{}
+	for _, c := range mjml.children {
+		if seen[c.name] {
+			return nil, p.errorAt(c.at, "<mjml> has more than one <%s>", c.name)
+		}
+		seen[c.name] = true
+	}
+	if !seen["mj-body"] {
+		return nil, p.errorAt(mjml.at, "<mjml> has no <mj-body>")
+	}
+	return root, nil
+}
+
+// tag reads the start tag at src[start] of an element of parent, returning the offset after it
+func (p *mjmlParser) tag(start int, parent *mjmlNode) (node *mjmlNode, end int, selfClosing bool, err error) {
+	src := p.src
+	m := mjmlStartTag.FindStringSubmatch(src[start:])
+	if m == nil {
+		return nil, 0, false, p.errorAt(start, "malformed tag")
+	}
+	node = &mjmlNode{name: m[1], attrs: map[string]string{}, at: start}
+	element, known := mjmlElements[node.name]
+	if !known {
+		return nil, 0, false, p.errorAt(start, "unknown element <%s>", node.name)
+	}
+	allowed := false
+	for _, name := range element.parents {
+		allowed = allowed || name == parent.name
+	}
+	if !allowed {
+		if parent.name == "" {
+			return nil, 0, false, p.errorAt(start, "<%s> must be in <%s>", node.name, strings.Join(element.parents, ">, <"))
+		}
+		return nil, 0, false, p.errorAt(start, "<%s> can't be in <%s>", node.name, parent.name)
+	}
+
+	i := start + len(m[0])
+	for {
+		for i < len(src) && strings.IndexByte(" \t\r\n", src[i]) >= 0 {
+			i++
+		}
+		switch {
+		case i >= len(src):
+			return nil, 0, false, p.errorAt(start, "tag <%s> is never closed", node.name)
+		case src[i] == '>':
+			i++
+		case strings.HasPrefix(src[i:], "/>"):
+			i += 2
+			selfClosing = true
+		default:
+			a := mjmlAttribute.FindStringSubmatch(src[i:])
+			if a == nil {
+				return nil, 0, false, p.errorAt(i, "malformed attribute of <%s>", node.name)
+			}
+			name, value := a[1], a[3]+a[4]
+			kind, ok := element.attrs[name]
+			switch {
+			case !ok:
+				return nil, 0, false, p.errorAt(i, "unknown attribute %s of <%s>", name, node.name)
+			case a[2] == "":
+				return nil, 0, false, p.errorAt(i, "attribute %s of <%s> has no value", name, node.name)
+			}
+			if _, dup := node.attrs[name]; dup {
+				return nil, 0, false, p.errorAt(i, "attribute %s of <%s> is repeated", name, node.name)
+			}
+			if msg := checkMJMLValue(kind, value); msg != "" {
+				return nil, 0, false, p.errorAt(i, "attribute %s of <%s> %s", name, node.name, msg)
+			}
+			node.attrs[name] = value
+			i += len(a[0])
+			continue
+		}
+		break
+	}
+	for _, name := range mjmlRequired[node.name] {
+		if strings.TrimSpace(node.attrs[name]) == "" {
+			return nil, 0, false, p.errorAt(start, "<%s> has no %s", node.name, name)
+		}
+	}
+	return node, i, selfClosing, nil
+}
+
+// checkMJMLValue returns what's wrong with the value of an attribute of kind, "" when nothing
+func checkMJMLValue(kind int, value string) string {
+	switch kind {
+	case mjmlCSS:
+		if !mjmlCSSValue.MatchString(value) {
+			return "isn't a CSS value"
+		}
+	case mjmlURL:
+		if !isPlaceholder(value) && !mdSafeURL.MatchString(value) {
+			return "isn't an http(s), mailto or tel link"
+		}
+	case mjmlWidth:
+		if !mjmlWidthOf.MatchString(value) {
+			return "isn't a width in % or px"
+		}
+	case mjmlPixels:
+		if !mjmlPixelsOf.MatchString(value) {
+			return "isn't a width in px"
+		}
+	}
+	return ""
+}
+
+// style is the inline style of CSS properties and values, leaving out those without a value
+func style(properties ...string) string {
+	var b strings.Builder
+	for i := 0; i+1 < len(properties); i += 2 {
+		if properties[i+1] != "" {
+			b.WriteString(properties[i] + ":" + html.EscapeString(properties[i+1]) + ";")
+		}
+	}
+	return b.String()
+}
+
+// horizontalPadding is the left and right padding of a CSS padding in px, counting 0 for other
+// units
+func horizontalPadding(padding string) int {
+	parts := strings.Fields(padding)
+	px := func(v string) int {
+		n, _ := strconv.Atoi(strings.TrimSuffix(v, "px"))
+		return n
+	}
+	switch len(parts) {
+	case 1:
+		return 2 * px(parts[0])
+	case 2, 3:
+		return 2 * px(parts[1])
+	case 4:
+		return px(parts[1]) + px(parts[3])
+	}
+	return 0
+}
+
+// mjmlColumn is the width of a column, its class and its width in px for Outlook
+type mjmlColumn struct {
+	class  string
+	width  string
+	pixels int
+}
+
+func columnWidth(width string, bodyWidth int) mjmlColumn {
+	m := mjmlWidthOf.FindStringSubmatch(width)
+	n, _ := strconv.ParseFloat(m[1], 64)
+	if m[2] == "px" {
+		return mjmlColumn{class: "mj-column-px-" + m[1], width: width, pixels: int(n)}
+	}
+	return mjmlColumn{
+		class:  "mj-column-per-" + strings.ReplaceAll(m[1], ".", "-"),
+		width:  width,
+		pixels: int(n * float64(bodyWidth) / 100),
+	}
+}
+
+type mjmlRenderer struct {
+	out       strings.Builder
+	bodyWidth int
+	// columns are the widths of the columns, for the media query setting them
+	columns map[string]mjmlColumn
+}
+
+func renderMJML(root *mjmlNode) string {
+	mjml := root.children[0]
+	body := mjml.child("mj-body")
+	r := &mjmlRenderer{columns: map[string]mjmlColumn{}, bodyWidth: mjmlBodyWidth}
+	if m := mjmlPixelsOf.FindStringSubmatch(body.attrs["width"]); m != nil {
+		r.bodyWidth, _ = strconv.Atoi(m[1])
+	}
+	for _, c := range body.children {
+		r.element(c)
+	}
+	content := r.out.String()
+
+	var title, preview, styles string
+	if head := mjml.child("mj-head"); head != nil {
+		for _, c := range head.children {
+			switch c.name {
+			case "mj-title":
+				title = c.content
+			case "mj-preview":
+				preview = c.content
+			case "mj-style":
+				styles += c.content + "\n"
+			}
+		}
+	}
+	classes := make([]string, 0, len(r.columns))
+	for class := range r.columns {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+
+	var b strings.Builder
+	b.WriteString("<!doctype html>\n<html xmlns=\"http://www.w3.org/1999/xhtml\">\n<head>\n")
+	b.WriteString("<title>" + title + "</title>\n")
+	b.WriteString("<meta charset=\"utf-8\">\n<meta name=\"viewport\" content=\"width=device-width,initial-scale=1\">\n")
+	b.WriteString("<meta http-equiv=\"X-UA-Compatible\" content=\"IE=edge\">\n<style type=\"text/css\">\n")
+	b.WriteString("body { margin: 0; padding: 0; -webkit-text-size-adjust: 100%; -ms-text-size-adjust: 100%; }\n")
+	b.WriteString("table, td { border-collapse: collapse; mso-table-lspace: 0pt; mso-table-rspace: 0pt; }\n")
+	b.WriteString("img { border: 0; height: auto; line-height: 100%; outline: none; text-decoration: none; }\n")
+	b.WriteString("@media only screen and (min-width:" + mjmlBreakpoint + ") {\n")
+	for _, class := range classes {
+		w := r.columns[class].width
+		b.WriteString("." + class + " { width: " + w + " !important; max-width: " + w + "; }\n")
+	}
+	b.WriteString("}\n</style>\n")
+	if styles != "" {
+		b.WriteString("<style type=\"text/css\">\n" + styles + "</style>\n")
+	}
+	b.WriteString("</head>\n")
+	background := body.attrs["background-color"]
+	b.WriteString(`<body style="` + style("word-spacing", "normal", "background-color", background) + `">` + "\n")
+	if preview != "" {
+		b.WriteString(`<div style="display:none;font-size:1px;color:#ffffff;line-height:1px;max-height:0px;max-width:0px;opacity:0;overflow:hidden;">` + preview + "</div>\n")
+	}
+	b.WriteString(`<div style="` + style("background-color", background) + `">` + "\n")
+	b.WriteString(content)
+	b.WriteString("</div>\n</body>\n</html>\n")
+	return b.String()
+}
+
+// element renders an element of the body
+func (r *mjmlRenderer) element(n *mjmlNode) {
+	if n.name == "mj-raw" {
+		r.out.WriteString(n.content + "\n")
+		return
+	}
+	r.section(n)
+}
+
+func (r *mjmlRenderer) section(n *mjmlNode) {
+	w := strconv.Itoa(r.bodyWidth)
+	background := n.attrs["background-color"]
+	var columns []*mjmlNode
+	for _, c := range n.children {
+		if c.name == "mj-column" {
+			columns = append(columns, c)
+		}
+	}
+
+	r.out.WriteString(`<!--[if mso | IE]><table align="center" border="0" cellpadding="0" cellspacing="0" role="presentation" width="` + w + `" style="width:` + w + `px;"><tr><td><![endif]-->` + "\n")
+	r.out.WriteString(`<div style="` + style("background", background, "background-color", background, "margin", "0px auto", "max-width", w+"px") + `">` + "\n")
+	r.out.WriteString(`<table align="center" border="0" cellpadding="0" cellspacing="0" role="presentation" style="` + style("background", background, "background-color", background, "width", "100%") + `"><tbody><tr>` + "\n")
+	r.out.WriteString(`<td style="` + style("border", n.attrs["border"], "direction", "ltr", "font-size", "0px", "padding", n.attr("padding", "20px 0"), "text-align", n.attr("text-align", "center")) + `">` + "\n")
+	r.out.WriteString("<!--[if mso | IE]><table role=\"presentation\" border=\"0\" cellpadding=\"0\" cellspacing=\"0\"><tr><![endif]-->\n")
+	for _, c := range n.children {
+		if c.name == "mj-raw" {
+			r.out.WriteString(c.content + "\n")
+			continue
+		}
+		width := c.attrs["width"]
+		if width == "" {
+			width = strconv.FormatFloat(100/float64(len(columns)), 'f', -1, 64)
+			if strings.Contains(width, ".") {
+				width = strconv.FormatFloat(100/float64(len(columns)), 'f', 2, 64)
+			}
+			width += "%"
+		}
+		r.column(c, columnWidth(width, r.bodyWidth))
+	}
+	r.out.WriteString("<!--[if mso | IE]></tr></table><![endif]-->\n")
+	r.out.WriteString("</td>\n</tr></tbody></table>\n</div>\n<!--[if mso | IE]></td></tr></table><![endif]-->\n")
+}
+
+func (r *mjmlRenderer) column(n *mjmlNode, width mjmlColumn) {
+	r.columns[width.class] = width
+	align := n.attr("vertical-align", "top")
+	r.out.WriteString(`<!--[if mso | IE]><td style="` + style("vertical-align", align, "width", strconv.Itoa(width.pixels)+"px") + `"><![endif]-->` + "\n")
+	r.out.WriteString(`<div class="` + width.class + `" style="` + style("font-size", "0px", "text-align", "left", "direction", "ltr", "display", "inline-block", "vertical-align", align, "width", "100%") + `">` + "\n")
+	r.out.WriteString(`<table border="0" cellpadding="0" cellspacing="0" role="presentation" width="100%" style="` + style("background-color", n.attrs["background-color"], "vertical-align", align) + `"><tbody>` + "\n")
+	for _, c := range n.children {
+		r.content(c, width)
+	}
+	r.out.WriteString("</tbody></table>\n</div>\n<!--[if mso | IE]></td><![endif]-->\n")
+}
+
+// content renders an element of a column, in a row of its own
+func (r *mjmlRenderer) content(n *mjmlNode, column mjmlColumn) {
+	padding := n.attr("padding", "10px 25px")
+	align := n.attr("align", "left")
+	switch n.name {
+	case "mj-button", "mj-image", "mj-divider":
+		align = n.attr("align", "center")
+	case "mj-spacer", "mj-raw":
+		padding = ""
+	}
+	r.out.WriteString(`<tr><td align="` + html.EscapeString(align) + `" style="` + style("background", n.attrs["container-background-color"], "font-size", "0px", "padding", padding, "word-break", "break-word") + `">` + "\n")
+
+	switch n.name {
+	case "mj-raw":
+		r.out.WriteString(n.content + "\n")
+
+	case "mj-text":
+		r.out.WriteString(`<div style="` + style(
+			"font-family", n.attr("font-family", mjmlFontFamily), "font-size", n.attr("font-size", "13px"),
+			"font-style", n.attrs["font-style"], "font-weight", n.attrs["font-weight"],
+			"letter-spacing", n.attrs["letter-spacing"], "line-height", n.attr("line-height", "1"),
+			"text-align", align, "color", n.attr("color", "#000000"),
+		) + `">` + n.content + "</div>\n")
+
+	case "mj-button":
+		background := n.attr("background-color", "#414141")
+		radius := n.attr("border-radius", "3px")
+		r.out.WriteString(`<table border="0" cellpadding="0" cellspacing="0" role="presentation" style="border-collapse:separate;line-height:100%;"><tr>` + "\n")
+		r.out.WriteString(`<td align="center" bgcolor="` + html.EscapeString(background) + `" role="presentation" style="` + style("border", "none", "border-radius", radius, "cursor", "auto", "background", background) + `" valign="middle">` + "\n")
+		r.out.WriteString(`<a href="` + html.EscapeString(n.attrs["href"]) + `" style="` + style(
+			"display", "inline-block", "background", background, "color", n.attr("color", "#ffffff"),
+			"font-family", n.attr("font-family", mjmlFontFamily), "font-size", n.attr("font-size", "13px"),
+			"font-weight", n.attr("font-weight", "normal"), "line-height", "120%", "margin", "0",
+			"text-decoration", "none", "text-transform", "none", "padding", n.attr("inner-padding", "10px 25px"),
+			"border-radius", radius,
+		) + `" target="` + html.EscapeString(n.attr("target", "_blank")) + `">` + n.content + "</a>\n")
+		r.out.WriteString("</td>\n</tr></table>\n")
+
+	case "mj-image":
+		width := column.pixels - horizontalPadding(padding)
+		if column.pixels == 0 {
+			width = r.bodyWidth - horizontalPadding(padding)
+		}
+		if m := mjmlPixelsOf.FindStringSubmatch(n.attrs["width"]); m != nil {
+			if w, _ := strconv.Atoi(m[1]); w < width {
+				width = w
+			}
+		}
+		w := strconv.Itoa(width)
+		img := `<img alt="` + html.EscapeString(n.attrs["alt"]) + `" src="` + html.EscapeString(n.attrs["src"]) + `"`
+		if title := n.attrs["title"]; title != "" {
+			img += ` title="` + html.EscapeString(title) + `"`
+		}
+		img += ` style="` + style("border", "0", "border-radius", n.attrs["border-radius"], "display", "block", "outline", "none", "text-decoration", "none", "height", "auto", "width", "100%", "font-size", "13px") + `" width="` + w + `" height="auto">`
+		if href := n.attrs["href"]; href != "" {
+			img = `<a href="` + html.EscapeString(href) + `" target="_blank">` + img + "</a>"
+		}
+		r.out.WriteString(`<table border="0" cellpadding="0" cellspacing="0" role="presentation" style="border-collapse:collapse;border-spacing:0px;"><tbody><tr>` + "\n")
+		r.out.WriteString(`<td style="width:` + w + `px;">` + img + "</td>\n</tr></tbody></table>\n")
+
+	case "mj-divider":
+		border := n.attr("border-style", "solid") + " " + n.attr("border-width", "4px") + " " + n.attr("border-color", "#000000")
+		r.out.WriteString(`<p style="` + style("border-top", border, "font-size", "1px", "margin", "0px auto", "width", n.attr("width", "100%")) + `"></p>` + "\n")
+
+	case "mj-spacer":
+		height := n.attr("height", "20px")
+		r.out.WriteString(`<div style="` + style("height", height, "line-height", height) + `">&#8202;</div>` + "\n")
+
+	case "mj-table":
+		r.out.WriteString(`<table cellpadding="` + html.EscapeString(n.attr("cellpadding", "0")) + `" cellspacing="` + html.EscapeString(n.attr("cellspacing", "0")) + `" width="100%" border="0" style="` + style(
+			"color", n.attr("color", "#000000"), "font-family", n.attr("font-family", mjmlFontFamily),
+			"font-size", n.attr("font-size", "13px"), "line-height", n.attr("line-height", "22px"),
+			"table-layout", "auto", "width", n.attr("width", "100%"), "border", "none",
+		) + `">` + "\n" + n.content + "\n</table>\n")
+	}
+	r.out.WriteString("</td></tr>\n")
+}
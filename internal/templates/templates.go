@@ -0,0 +1,139 @@
+// Package templates renders the email templates of organizations (models.EmailTemplate): Go
+// templates whose subject and text are plain text and whose HTML is escaped as HTML, filled with
+// variables such as {{.FirstName}}. A variable the template uses but isn't given is an error,
+// so a broken template is caught at preview rather than sent. The HTML can also be authored in
+// Markdown or MJML, compiled on save (see Compile).
+package templates
+
+import (
+	htmltemplate "html/template"
+	"regexp"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+
+	"fiber-gorm-api/internal/models"
+)
+
+// Parts of a template
+const (
+	PartSubject = "subject"
+	PartText    = "text"
+	PartHTML    = "html"
+)
+
+// SampleVariables fill a template previewed without variables of its own, as for a subscriber
+var SampleVariables = map[string]interface{}{
+	"Name":           "Jane Doe",
+	"FirstName":      "Jane",
+	"Email":          "jane@example.com",
+	"PreferencesURL": "https://signup.mylocal.ing/preferences",
+}
+
+// Rendered is a template filled with variables
+type Rendered struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Error is a template that doesn't parse or render, located in one of its parts. Line and
+// Column are 1-based, 0 when unknown.
+type Error struct {
+	Part    string
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e *Error) Error() string {
+	if e.Line == 0 {
+		return e.Part + ": " + e.Message
+	}
+	return e.Part + ":" + strconv.Itoa(e.Line) + ": " + e.Message
+}
+
+// goTemplateError matches the errors of text/template and html/template, e.g.
+// `template: html:3:14: executing "html" at <.Nme>: map has no entry for key "Nme"`
+var goTemplateError = regexp.MustCompile(`^(?:html/)?template: \w+:(\d+)(?::(\d+))?: (.*)$`)
+
+// locate makes the error err of part an Error, with its position when Go gave one
+func locate(part string, err error) *Error {
+	out := &Error{Part: part, Message: err.Error()}
+	if m := goTemplateError.FindStringSubmatch(err.Error()); m != nil {
+		out.Line, _ = strconv.Atoi(m[1])
+		out.Column, _ = strconv.Atoi(m[2])
+		out.Message = m[3]
+	}
+	return out
+}
+
+func parseText(part, source string) (*texttemplate.Template, error) {
+	tmpl, err := texttemplate.New(part).Option("missingkey=error").Parse(source)
+	if err != nil {
+		return nil, locate(part, err)
+	}
+	return tmpl, nil
+}
+
+func parseHTML(source string) (*htmltemplate.Template, error) {
+	tmpl, err := htmltemplate.New(PartHTML).Option("missingkey=error").Parse(source)
+	if err != nil {
+		return nil, locate(PartHTML, err)
+	}
+	return tmpl, nil
+}
+
+// Check parses every part of a template, returning the *Error of the first that doesn't
+func Check(t models.EmailTemplate) error {
+	if _, err := parseText(PartSubject, t.Subject); err != nil {
+		return err
+	}
+	if _, err := parseText(PartText, t.Text); err != nil {
+		return err
+	}
+	_, err := parseHTML(t.HTML)
+	return err
+}
+
+// execute renders the part of a template from source with vars
+func execute(part, source string, vars map[string]interface{}) (string, error) {
+	var b strings.Builder
+	var err error
+	if part == PartHTML {
+		var tmpl *htmltemplate.Template
+		if tmpl, err = parseHTML(source); err != nil {
+			return "", err
+		}
+		err = tmpl.Execute(&b, vars)
+	} else {
+		var tmpl *texttemplate.Template
+		if tmpl, err = parseText(part, source); err != nil {
+			return "", err
+		}
+		err = tmpl.Execute(&b, vars)
+	}
+	if err != nil {
+		return "", locate(part, err)
+	}
+	return b.String(), nil
+}
+
+// Render fills every part of a template with vars, returning the *Error of the first that
+// doesn't parse or render
+func Render(t models.EmailTemplate, vars map[string]interface{}) (Rendered, error) {
+	var out Rendered
+	var err error
+	if out.Subject, err = execute(PartSubject, t.Subject, vars); err != nil {
+		return out, err
+	}
+	if out.Text, err = execute(PartText, t.Text, vars); err != nil {
+		return out, err
+	}
+	if out.HTML, err = execute(PartHTML, t.HTML, vars); err != nil {
+		return out, err
+	}
+	// a subject is one line
+	out.Subject = strings.Join(strings.Fields(out.Subject), " ")
+	return out, nil
+}
@@ -0,0 +1,49 @@
+package templates
+
+import (
+	"errors"
+	"testing"
+
+	"fiber-gorm-api/internal/models"
+)
+
+func TestRender(t *testing.T) {
+	tmpl := models.EmailTemplate{
+		Subject: "Hello {{.FirstName}}\n",
+		Text:    "Hi {{.Name}}, see you at {{.Place}}",
+		HTML:    `<p>Hi {{.Name}}</p><a href="{{.PreferencesURL}}">Preferences</a>`,
+	}
+	got, err := Render(tmpl, map[string]interface{}{
+		"FirstName": "Ada", "Name": "Ada <Lovelace>", "Place": "the market",
+		"PreferencesURL": "https://example.com/p?a=1&b=2",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Rendered{
+		Subject: "Hello Ada",
+		Text:    "Hi Ada <Lovelace>, see you at the market",
+		HTML:    `<p>Hi Ada &lt;Lovelace&gt;</p><a href="https://example.com/p?a=1&amp;b=2">Preferences</a>`,
+	}
+	if got != want {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+
+	// a variable missing
+	_, err = Render(tmpl, SampleVariables)
+	var tmplErr *Error
+	if !errors.As(err, &tmplErr) || tmplErr.Part != PartText || tmplErr.Line != 1 || tmplErr.Column == 0 {
+		t.Errorf("Expected the missing variable located in the text, got %#v", err)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	if err := Check(models.EmailTemplate{Subject: "Hi {{.Name}}", HTML: "<p>{{if .Name}}Hi{{end}}</p>"}); err != nil {
+		t.Errorf("Expected the template valid, got %v", err)
+	}
+	err := Check(models.EmailTemplate{Subject: "Hi", HTML: "<p>\n{{if .Name}}Hi</p>"})
+	var tmplErr *Error
+	if !errors.As(err, &tmplErr) || tmplErr.Part != PartHTML || tmplErr.Line != 2 {
+		t.Errorf("Expected the unclosed if located in the HTML, got %#v", err)
+	}
+}
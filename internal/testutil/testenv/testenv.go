@@ -0,0 +1,38 @@
+// Package testenv sets up the database and file storage of a test. It's apart from testutil so
+// the tests of the packages testutil registers the routes of, like exports and imports, can use
+// it without an import cycle.
+package testenv
+
+import (
+	"path/filepath"
+	"testing"
+
+	"fiber-gorm-api/internal/db"
+	"fiber-gorm-api/internal/storage"
+
+	"gorm.io/gorm"
+)
+
+// SQLite opens a fresh, migrated SQLite database in a temporary directory of tb, closed when tb
+// ends
+func SQLite(tb testing.TB) *gorm.DB {
+	tb.Helper()
+	conn, err := db.OpenSQLite(filepath.Join(tb.TempDir(), "test.db"))
+	if err != nil {
+		tb.Fatalf("testenv: opening SQLite: %v", err)
+	}
+	tb.Cleanup(func() {
+		if sqlDB, err := conn.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return conn
+}
+
+// LocalStorage makes storage.Default a temporary directory of tb until tb ends
+func LocalStorage(tb testing.TB) {
+	tb.Helper()
+	original := storage.Default
+	tb.Cleanup(func() { storage.Default = original })
+	storage.Default = storage.NewLocal(tb.TempDir())
+}
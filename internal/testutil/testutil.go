@@ -0,0 +1,110 @@
+// Package testutil starts the API for tests in one call, on a database and a Redis of its own,
+// so they run with `go test` alone rather than against externally provisioned services.
+package testutil
+
+import (
+	"os"
+	"sync"
+	"testing"
+
+	"fiber-gorm-api/internal/db"
+	"fiber-gorm-api/internal/imports"
+	"fiber-gorm-api/internal/middleware"
+	redisclient "fiber-gorm-api/internal/redis"
+	"fiber-gorm-api/internal/routes/admin"
+	"fiber-gorm-api/internal/routes/preferences"
+	"fiber-gorm-api/internal/routes/signin"
+	"fiber-gorm-api/internal/routes/signup"
+	"fiber-gorm-api/internal/routes/webhooks"
+	"fiber-gorm-api/internal/session"
+	"fiber-gorm-api/internal/testutil/testenv"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// Harness is the API with every route registered, on the database DB and the Redis Redis
+type Harness struct {
+	App   *fiber.App
+	DB    *gorm.DB
+	Redis *miniredis.Miniredis
+}
+
+// New starts the API on a fresh, migrated SQLite database and an in-process Redis, both dropped
+// by cleanup, which is also registered with tb.Cleanup. With DB_DRIVER=postgres it runs on the
+// Postgres that DB_* point to instead (the compose test service, or a container started by the
+// caller), which must have migrations/migration.sql applied and is shared by every test.
+//
+// Routes connect on their own, so the harness swaps the connections they get for the length of
+// the test: tests using it can't run in parallel.
+func New(tb testing.TB) (*Harness, func()) {
+	tb.Helper()
+	// no real Redis, whatever the environment says, and no read cache to invalidate
+	tb.Setenv("API_ENV", "test")
+	tb.Setenv("CACHE_ENABLED", "false")
+
+	var conn *gorm.DB
+	if os.Getenv("DB_DRIVER") == db.DriverPostgres {
+		conn = db.Connect(true)
+	} else {
+		conn = testenv.SQLite(tb)
+	}
+	server, err := miniredis.Run()
+	if err != nil {
+		tb.Fatalf("testutil: starting Redis: %v", err)
+	}
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+
+	restoreDB := db.Use(conn)
+	restoreRedis := redisclient.Use("session", client)
+
+	// the middleware of main that changes responses or records, without logging, tracing and
+	// compression
+	app := fiber.New(fiber.Config{BodyLimit: max(fiber.DefaultBodyLimit, imports.MaxUploadBytes())})
+	app.Use(requestid.New())
+	app.Use(middleware.RequestTimeout())
+	app.Use(middleware.Envelope("/swagger", "/.well-known", "/admin/graphql"))
+	app.Use(middleware.Locale())
+	app.Use(middleware.ClientIP())
+
+	signin.RegisterRoutes(app)
+	admin.RegisterAdminRoutes(app)
+	signup.RegisterRoutes(app)
+	preferences.RegisterRoutes(app)
+	webhooks.RegisterRoutes(app)
+	// the sign-in routes initialized the process' Redis meanwhile
+	redisclient.Use("session", client)
+
+	var once sync.Once
+	cleanup := func() {
+		once.Do(func() {
+			restoreRedis()
+			restoreDB()
+			client.Close()
+			server.Close()
+			if sqlDB, err := conn.DB(); err == nil && db.IsSQLite(conn) {
+				sqlDB.Close()
+			}
+		})
+	}
+	tb.Cleanup(cleanup)
+	return &Harness{App: app, DB: conn, Redis: server}, cleanup
+}
+
+// Token signs in email to the organization orgID with every scope, and returns the bearer
+// token of the session
+func (h *Harness) Token(tb testing.TB, email string, orgID uint) string {
+	tb.Helper()
+	sess, err := session.Create(redisclient.Ctx, email, orgID, "", "")
+	if err != nil {
+		tb.Fatalf("testutil: creating a session: %v", err)
+	}
+	token, err := middleware.GenerateJWT(sess.ID)
+	if err != nil {
+		tb.Fatalf("testutil: signing a token: %v", err)
+	}
+	return token
+}
@@ -0,0 +1,49 @@
+package testutil_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"fiber-gorm-api/internal/db"
+	"fiber-gorm-api/internal/models"
+	"fiber-gorm-api/internal/testutil"
+)
+
+func TestNew(t *testing.T) {
+	h, cleanup := testutil.New(t)
+	if !db.IsSQLite(h.DB) {
+		t.Skip("DB_DRIVER=postgres: every harness shares the database")
+	}
+	token := h.Token(t, "harness@example.com", models.DefaultOrgID)
+
+	req := httptest.NewRequest("GET", "/admin/subscribers", nil)
+	if resp, err := h.App.Test(req, -1); err != nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("Expected 401 without a token, got %v (%v)", resp, err)
+	}
+
+	req = httptest.NewRequest("POST", "/admin/subscribers", strings.NewReader(`{"email":"ada@example.com","name":"Ada"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := h.App.Test(req, -1)
+	if err != nil || resp.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected the subscriber created, got %v (%v)", resp, err)
+	}
+	var count int64
+	h.DB.Model(&models.Subscriber{}).Where("email = ?", "ada@example.com").Count(&count)
+	if count != 1 {
+		t.Fatalf("Expected the subscriber in the harness database, found %d", count)
+	}
+	cleanup()
+
+	// the next harness starts from a database and a Redis of its own
+	next, _ := testutil.New(t)
+	next.DB.Model(&models.Subscriber{}).Count(&count)
+	if count != 0 {
+		t.Errorf("Expected an empty database, found %d subscribers", count)
+	}
+	if next.Redis.Exists("user_sessions:harness@example.com") {
+		t.Error("Expected the sessions of the previous harness gone")
+	}
+}
@@ -0,0 +1,152 @@
+// Package tracking signs the tokens of the open pixel and the tracked links of campaign emails,
+// and tells bots from readers. A token names a campaign recipient (models.CampaignSend), the
+// link for clicks, and the day it was issued, with an HMAC of all three: it can't be guessed
+// from other tokens, and only counts for TokenTTL, so an email forwarded or dug up months later
+// doesn't skew the stats. Links keep redirecting after that.
+package tracking
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultBaseURL  = "http://localhost:3517"
+	defaultTokenTTL = 90 * 24 * time.Hour
+	day             = 24 * time.Hour
+)
+
+// Kinds of tokens, also the path segment of their route under /t/
+const (
+	KindOpen  = "o"
+	KindClick = "c"
+)
+
+// Pixel is a transparent 1x1 GIF
+var Pixel = []byte{
+	0x47, 0x49, 0x46, 0x38, 0x39, 0x61, 0x01, 0x00, 0x01, 0x00, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0xff, 0xff, 0xff, 0x21, 0xf9, 0x04, 0x01, 0x00, 0x00, 0x00, 0x00, 0x2c, 0x00, 0x00, 0x00, 0x00,
+	0x01, 0x00, 0x01, 0x00, 0x00, 0x02, 0x02, 0x44, 0x01, 0x00, 0x3b,
+}
+
+// Claims is what a token stands for
+type Claims struct {
+	SendID uint
+	LinkID uint // of clicks, 0 for opens
+	Issued time.Time
+}
+
+// signingKey signs tokens: TRACKING_SIGNING_KEY, or the JWT secret when unset
+func signingKey() []byte {
+	if key := os.Getenv("TRACKING_SIGNING_KEY"); key != "" {
+		return []byte(key)
+	}
+	if key := os.Getenv("JWT_USER_SECRET_KEY"); key != "" {
+		return []byte(key)
+	}
+	return []byte("devsecret")
+}
+
+// TokenTTL is how long events of a token are recorded (TRACKING_TOKEN_TTL_DAYS)
+func TokenTTL() time.Duration {
+	if n, err := strconv.Atoi(os.Getenv("TRACKING_TOKEN_TTL_DAYS")); err == nil && n > 0 {
+		return time.Duration(n) * day
+	}
+	return defaultTokenTTL
+}
+
+func sign(kind, payload string) string {
+	mac := hmac.New(sha256.New, signingKey())
+	fmt.Fprintf(mac, "%s:%s", kind, payload)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:16])
+}
+
+// Token returns the token of kind for the recipient sendID, and linkID for clicks, issued at now
+func Token(kind string, sendID, linkID uint, now time.Time) string {
+	payload := strings.Join([]string{
+		strconv.FormatUint(uint64(sendID), 36),
+		strconv.FormatUint(uint64(linkID), 36),
+		strconv.FormatInt(now.Unix()/int64(day/time.Second), 36),
+	}, ".")
+	return payload + "." + sign(kind, payload)
+}
+
+// Parse returns the claims of a token of kind, false when it wasn't issued by Token
+func Parse(kind, token string) (Claims, bool) {
+	var claims Claims
+	payload, signature, ok := cutLast(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(sign(kind, payload))) {
+		return claims, false
+	}
+	parts := strings.Split(payload, ".")
+	if len(parts) != 3 {
+		return claims, false
+	}
+	sendID, err := strconv.ParseUint(parts[0], 36, 64)
+	if err != nil || sendID == 0 {
+		return claims, false
+	}
+	linkID, err := strconv.ParseUint(parts[1], 36, 64)
+	if err != nil {
+		return claims, false
+	}
+	days, err := strconv.ParseInt(parts[2], 36, 64)
+	if err != nil {
+		return claims, false
+	}
+	claims.SendID, claims.LinkID = uint(sendID), uint(linkID)
+	claims.Issued = time.Unix(days*int64(day/time.Second), 0).UTC()
+	return claims, true
+}
+
+// Fresh reports whether events of the token still count at now
+func (c Claims) Fresh(now time.Time) bool {
+	// Issued is the start of its day
+	return now.Before(c.Issued.Add(TokenTTL() + day))
+}
+
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
+
+// URL returns the link to the route of kind for the recipient sendID, and linkID for clicks.
+// TRACKING_BASE_URL is the public URL of the API the links point at.
+func URL(kind string, sendID, linkID uint, now time.Time) string {
+	base := strings.TrimSuffix(os.Getenv("TRACKING_BASE_URL"), "/")
+	if base == "" {
+		base = defaultBaseURL
+	}
+	return base + "/t/" + kind + "/" + Token(kind, sendID, linkID, now)
+}
+
+// botAgents are parts of the user agents of crawlers, link scanners of mail security gateways
+// and scripts, lowercase. The image proxies of webmails (e.g. GoogleImageProxy) fetch the pixel
+// for a reader, so they count.
+var botAgents = []string{
+	"bot", "crawl", "spider", "slurp", "preview", "facebookexternalhit", "headless", "phantomjs",
+	"scanner", "proofpoint", "mimecast", "barracuda", "python", "curl", "wget", "go-http-client",
+	"java/", "okhttp", "libwww", "httpclient", "node-fetch", "axios",
+}
+
+// IsBot reports whether userAgent is a bot's, or missing
+func IsBot(userAgent string) bool {
+	agent := strings.ToLower(strings.TrimSpace(userAgent))
+	if agent == "" {
+		return true
+	}
+	for _, bot := range botAgents {
+		if strings.Contains(agent, bot) {
+			return true
+		}
+	}
+	return false
+}
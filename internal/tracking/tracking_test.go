@@ -0,0 +1,83 @@
+package tracking
+
+import (
+	"bytes"
+	"image/gif"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestToken(t *testing.T) {
+	t.Setenv("TRACKING_SIGNING_KEY", "secret")
+	now := time.Date(2025, 7, 1, 15, 4, 5, 0, time.UTC)
+	token := Token(KindClick, 1234, 56, now)
+	claims, ok := Parse(KindClick, token)
+	if !ok || claims.SendID != 1234 || claims.LinkID != 56 || !claims.Issued.Equal(time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the claims read back, got %+v (%t)", claims, ok)
+	}
+
+	payload := token[:strings.LastIndex(token, ".")]
+	signature := token[len(payload)+1:]
+	for _, forged := range []string{
+		"", "1234", payload, payload + ".", payload + "." + signature[1:],
+		strings.Replace(payload, "ya", "yb", 1) + "." + signature,
+		Token(KindClick, 1234, 57, now)[:len(payload)] + "." + signature,
+	} {
+		if _, ok := Parse(KindClick, forged); ok {
+			t.Errorf("%q: expected rejected", forged)
+		}
+	}
+	if _, ok := Parse(KindOpen, token); ok {
+		t.Error("Expected a token of another kind rejected")
+	}
+	t.Setenv("TRACKING_SIGNING_KEY", "other")
+	if _, ok := Parse(KindClick, token); ok {
+		t.Error("Expected a token signed with another key rejected")
+	}
+}
+
+func TestFresh(t *testing.T) {
+	t.Setenv("TRACKING_TOKEN_TTL_DAYS", "30")
+	now := time.Date(2025, 7, 1, 23, 0, 0, 0, time.UTC)
+	claims, _ := Parse(KindOpen, Token(KindOpen, 1, 0, now))
+	if !claims.Fresh(now) || !claims.Fresh(now.Add(30*24*time.Hour)) {
+		t.Error("Expected the token counted for 30 days")
+	}
+	if claims.Fresh(now.Add(31 * 24 * time.Hour)) {
+		t.Error("Expected the token not counted after 31 days")
+	}
+}
+
+func TestURL(t *testing.T) {
+	t.Setenv("TRACKING_BASE_URL", "https://api.example.com/")
+	now := time.Now()
+	if url := URL(KindOpen, 7, 0, now); url != "https://api.example.com/t/o/"+Token(KindOpen, 7, 0, now) {
+		t.Errorf("Unexpected URL %s", url)
+	}
+}
+
+func TestIsBot(t *testing.T) {
+	for agent, bot := range map[string]bool{
+		"": true,
+		"Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)":                  true,
+		"Mozilla/5.0 (Windows NT 5.1; rv:11.0) Gecko Firefox/11.0 (via ggpht.com GoogleImageProxy)": false,
+		"python-requests/2.31.0": true,
+		"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko)":        false,
+		"Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) HeadlessChrome/120.0.0.0": true,
+	} {
+		if IsBot(agent) != bot {
+			t.Errorf("%q: expected bot %t", agent, bot)
+		}
+	}
+}
+
+func TestPixel(t *testing.T) {
+	img, err := gif.Decode(bytes.NewReader(Pixel))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != 1 || b.Dy() != 1 {
+		t.Errorf("Expected 1x1, got %v", b)
+	}
+}
@@ -0,0 +1,97 @@
+// Package useragent tells the device type, operating system and browser of a User-Agent header,
+// coarsely: enough to break sessions down on the dashboard, not to identify versions.
+package useragent
+
+import "strings"
+
+// Device types
+const (
+	DeviceDesktop = "desktop"
+	DeviceMobile  = "mobile"
+	DeviceTablet  = "tablet"
+	DeviceBot     = "bot"
+	// DeviceOther is a client that isn't a browser, e.g. curl or an SDK
+	DeviceOther = "other"
+)
+
+// Unknown is the OS or browser of a User-Agent that doesn't tell
+const Unknown = "Other"
+
+// Info is what a User-Agent tells of the client sending it
+type Info struct {
+	Device  string `json:"device"`
+	OS      string `json:"os"`
+	Browser string `json:"browser"`
+}
+
+// Each list is checked in order, the first token found in the User-Agent wins: more specific
+// tokens come first, e.g. Edge and Opera also claim to be Chrome, and Chrome to be Safari.
+type match struct {
+	token, name string
+}
+
+var botTokens = []string{"bot", "crawler", "spider", "slurp", "facebookexternalhit", "headless"}
+
+var operatingSystems = []match{
+	{"windows", "Windows"},
+	{"ipad", "iPadOS"},
+	{"iphone", "iOS"},
+	{"ipod", "iOS"},
+	{"mac os x", "macOS"},
+	{"macintosh", "macOS"},
+	{"android", "Android"},
+	{"cros", "ChromeOS"},
+	{"linux", "Linux"},
+}
+
+var browsers = []match{
+	{"edg", "Edge"},
+	{"opr/", "Opera"},
+	{"opera", "Opera"},
+	{"samsungbrowser", "Samsung Internet"},
+	{"firefox", "Firefox"},
+	{"fxios", "Firefox"},
+	{"crios", "Chrome"},
+	{"chrome", "Chrome"},
+	{"chromium", "Chrome"},
+	{"safari", "Safari"},
+}
+
+// Parse returns what userAgent tells: a browser on a desktop, mobile or tablet, a bot, or another
+// client. Empty headers are other clients.
+func Parse(userAgent string) Info {
+	ua := strings.ToLower(userAgent)
+	info := Info{OS: find(ua, operatingSystems), Browser: find(ua, browsers)}
+
+	switch {
+	case containsAny(ua, botTokens):
+		info.Device = DeviceBot
+	case info.OS == "iPadOS" || (info.OS == "Android" && !strings.Contains(ua, "mobile")) || strings.Contains(ua, "tablet"):
+		info.Device = DeviceTablet
+	case info.OS == "iOS" || info.OS == "Android" || strings.Contains(ua, "mobile"):
+		info.Device = DeviceMobile
+	case strings.HasPrefix(ua, "mozilla/") && info.OS != Unknown:
+		info.Device = DeviceDesktop
+	default:
+		info.Device = DeviceOther
+	}
+	return info
+}
+
+func find(ua string, matches []match) string {
+	for _, m := range matches {
+		if strings.Contains(ua, m.token) {
+			return m.name
+		}
+	}
+	return Unknown
+}
+
+func containsAny(ua string, tokens []string) bool {
+	for _, token := range tokens {
+		if strings.Contains(ua, token) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+// Package widget renders the signup widget, the HTML page partner sites embed in an iframe to
+// show a signup form (see models.SignupForm). It posts the form with a small inline script.
+package widget
+
+import (
+	_ "embed"
+	"html/template"
+	"io"
+
+	"fiber-gorm-api/internal/captcha"
+	"fiber-gorm-api/internal/models"
+)
+
+//go:embed widget.html
+var source string
+
+var page = template.Must(template.New("widget").Parse(source))
+
+// Page is what the widget of a form shows
+type Page struct {
+	Locale string
+	Form   models.SignupForm
+	// SubmitURL is the /signup/forms/{slug}/submit endpoint of the form
+	SubmitURL string
+	// Captcha is rendered when the form requires one and a site key is configured
+	Captcha *captcha.Widget
+	// Honeypot is the hidden field humans leave empty, see SIGNUP_HONEYPOT_FIELD
+	Honeypot string
+	// Nonce allows the inline script under the page's Content-Security-Policy
+	Nonce string
+	// T translates the labels into Locale
+	T func(string) string
+}
+
+// Render writes the widget page p to w
+func Render(w io.Writer, p Page) error {
+	return page.Execute(w, p)
+}
@@ -0,0 +1,35 @@
+# Checks `go test -bench` output against loadtest/budgets.txt:
+#
+#   awk -f loadtest/budgets.awk loadtest/budgets.txt bench.out
+#
+# Fails when a benchmark takes more ns/op than its budget, or has a budget but didn't run.
+FNR == NR {
+	if ($0 !~ /^#/ && NF == 2) budget[$1] = $2
+	next
+}
+/^Benchmark/ {
+	name = $1
+	sub(/-[0-9]+$/, "", name) # the GOMAXPROCS suffix
+}
+# the results of name, on its line or, when the benchmark logged, on a line of their own
+/ ns\/op/ && name != "" {
+	for (i = 1; i < NF; i++) if ($(i + 1) == "ns/op") took[name] = $i
+	name = ""
+}
+END {
+	failed = 0
+	for (name in budget) {
+		if (!(name in took)) {
+			printf "%-44s did not run\n", name
+			failed = 1
+			continue
+		}
+		verdict = "ok"
+		if (took[name] + 0 > budget[name] + 0) {
+			verdict = "OVER BUDGET"
+			failed = 1
+		}
+		printf "%-44s %12.0f ns/op  budget %10d  %s\n", name, took[name], budget[name], verdict
+	}
+	exit failed
+}
@@ -0,0 +1,94 @@
+// Load profile of the hot endpoints for k6 (https://k6.io). Run it against a development
+// deployment (docker-compose up, or make loadtest):
+//
+//   myloctl issue-api-key -name loadtest -scopes read,write    # prints API_KEY
+//   API_KEY=mylo_... BASE_URL=http://localhost:3517 PROFILE=load k6 run loadtest/k6.js
+//
+// PROFILE picks the traffic shape: smoke (a few users, a minute), load (the expected peak,
+// the default) or stress (three times the peak, to find where it breaks). The run fails when a
+// scenario is over its budget below. Signups email their confirmation when SENDGRID_API_KEY is
+// set, so leave it blank on the target.
+import http from 'k6/http';
+import { check } from 'k6';
+import exec from 'k6/execution';
+
+const BASE_URL = __ENV.BASE_URL || 'http://localhost:3517';
+const API_KEY = __ENV.API_KEY || '';
+const PROFILE = __ENV.PROFILE || 'load';
+
+// virtual users of each scenario at the top of the profile
+const peaks = {
+  smoke: { browse: 2, create: 1, signup: 1 },
+  load: { browse: 40, create: 10, signup: 30 },
+  stress: { browse: 120, create: 30, signup: 90 },
+};
+
+// how long each profile ramps up, holds its peak and ramps down
+const durations = {
+  smoke: ['10s', '40s', '10s'],
+  load: ['1m', '5m', '1m'],
+  stress: ['2m', '10m', '2m'],
+};
+
+if (!peaks[PROFILE]) {
+  throw new Error(`Unknown PROFILE ${PROFILE}, expected smoke, load or stress`);
+}
+
+function scenario(name) {
+  const [up, hold, down] = durations[PROFILE];
+  const target = peaks[PROFILE][name];
+  return {
+    executor: 'ramping-vus',
+    exec: name,
+    startVUs: 0,
+    stages: [
+      { duration: up, target },
+      { duration: hold, target },
+      { duration: down, target: 0 },
+    ],
+  };
+}
+
+export const options = {
+  scenarios: {
+    browse: scenario('browse'),
+    create: scenario('create'),
+    signup: scenario('signup'),
+  },
+  // performance budgets: 95th/99th percentile latency in ms, and at most 1% failed requests
+  thresholds: {
+    'http_req_failed': ['rate<0.01'],
+    'http_req_duration{scenario:browse}': ['p(95)<150', 'p(99)<400'],
+    'http_req_duration{scenario:create}': ['p(95)<250', 'p(99)<600'],
+    'http_req_duration{scenario:signup}': ['p(95)<250', 'p(99)<600'],
+  },
+};
+
+const adminHeaders = { 'X-API-Key': API_KEY, 'Content-Type': 'application/json' };
+
+// a new address per iteration, so creates and signups never collide across runs
+function email(prefix) {
+  return `${prefix}-${Date.now()}-${exec.vu.idInTest}-${exec.vu.iterationInScenario}@example.com`;
+}
+
+// browse lists the newest subscribers and loads the dashboard stats, like the admin app
+export function browse() {
+  const list = http.get(`${BASE_URL}/admin/subscribers?limit=50&sort=created_at`, { headers: adminHeaders });
+  check(list, { 'list 200': (r) => r.status === 200 });
+  const stats = http.get(`${BASE_URL}/admin/stats`, { headers: adminHeaders });
+  check(stats, { 'stats 200': (r) => r.status === 200 });
+}
+
+// create adds subscribers from the admin API
+export function create() {
+  const res = http.post(`${BASE_URL}/admin/subscribers`,
+    JSON.stringify({ email: email('admin'), name: 'Load Test' }), { headers: adminHeaders });
+  check(res, { 'create 201': (r) => r.status === 201 });
+}
+
+// signup posts the public signup form of the default organization
+export function signup() {
+  const res = http.post(`${BASE_URL}/signup/subscribers/`,
+    JSON.stringify({ email: email('signup'), name: 'Load Test' }), { headers: { 'Content-Type': 'application/json' } });
+  check(res, { 'signup 2xx': (r) => r.status >= 200 && r.status < 300 });
+}
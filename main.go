@@ -1,18 +1,35 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 
 	_ "fiber-gorm-api/docs" // swagger docs
 
+	"fiber-gorm-api/internal/db"
+	"fiber-gorm-api/internal/emaillog"
+	"fiber-gorm-api/internal/grpcserver"
+	"fiber-gorm-api/internal/imports"
+	"fiber-gorm-api/internal/middleware"
 	"fiber-gorm-api/internal/routes/admin"
+	"fiber-gorm-api/internal/routes/events"
+	"fiber-gorm-api/internal/routes/links"
+	"fiber-gorm-api/internal/routes/preferences"
 	"fiber-gorm-api/internal/routes/signin"
 	"fiber-gorm-api/internal/routes/signup"
+	"fiber-gorm-api/internal/routes/tracking"
+	"fiber-gorm-api/internal/routes/webhooks"
+	"fiber-gorm-api/internal/scheduler"
+	"fiber-gorm-api/internal/session"
+	"fiber-gorm-api/internal/telemetry"
 
+	"github.com/gofiber/contrib/otelfiber"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/logger"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
 	swagger "github.com/gofiber/swagger"
+	"gorm.io/gorm"
 )
 
 // @title           myLocal Headless API
@@ -26,14 +43,48 @@ import (
 // @BasePath        /
 
 func main() {
-	// Fiber app
-	app := fiber.New()
+	// Traces over OTLP when OTEL_EXPORTER_OTLP_ENDPOINT is set
+	shutdownTracing := telemetry.Init(context.Background())
+
+	// Fiber app; bodies may be as large as a subscriber import upload
+	app := fiber.New(fiber.Config{BodyLimit: max(fiber.DefaultBodyLimit, imports.MaxUploadBytes())})
+
+	// An X-Request-ID per request (kept when the caller sends one), logged and returned
+	app.Use(requestid.New())
 
 	// Logger middleware
-	app.Use(logger.New())
+	app.Use(logger.New(logger.Config{
+		Format: "${time} | ${status} | ${latency} | ${ip} | ${method} | ${path} | ${locals:requestid} | ${error}\n",
+	}))
+
+	// A server span per request, parent of the GORM, Redis and SendGrid spans it causes
+	app.Use(otelfiber.Middleware())
+
+	// Brotli/gzip for large text responses (subscriber lists, exports)
+	app.Use(middleware.Compression())
+
+	// Deadline for the DB and Redis calls of a request
+	app.Use(middleware.RequestTimeout())
+
+	// {"data", "meta", "errors"} responses for clients that ask for them (or all, with
+	// RESPONSE_ENVELOPE=true); wraps the body after Locale translated its errors
+	app.Use(middleware.Envelope("/swagger", "/.well-known", "/admin/graphql"))
+
+	// Language of error messages and emails, from ?lang= or Accept-Language
+	app.Use(middleware.Locale())
+
+	// Where records written by a request came from, with GeoIP enabled (GEOIP_DB_PATH)
+	app.Use(middleware.ClientIP())
+
+	// Security headers (HSTS, nosniff, frame options, referrer policy, CSP); the swagger UI
+	// sets its own, with a CSP loose enough for it to run
+	app.Use(middleware.SecurityHeaders("api", middleware.APIContentSecurityPolicy, "/swagger"))
 
 	// Swagger route
-	app.Get("/swagger/*", swagger.HandlerDefault)
+	app.Get("/swagger/*", middleware.SecurityHeaders("swagger", middleware.SwaggerContentSecurityPolicy), swagger.HandlerDefault)
+
+	// Sessions in Redis, or in Postgres or memory with SESSION_STORE
+	session.Use(session.NewFromEnv(func() *gorm.DB { return db.Connect(false) }))
 
 	// Register sign-in routes
 	signin.RegisterRoutes(app)
@@ -44,11 +95,37 @@ func main() {
 	// Register signup routes
 	signup.RegisterRoutes(app)
 
+	// Register the subscriber preferences page routes
+	preferences.RegisterRoutes(app)
+
+	// Register provider webhooks
+	webhooks.RegisterRoutes(app)
+
+	// Register the tracking of campaign emails
+	tracking.RegisterRoutes(app)
+
+	// Register the short links
+	links.RegisterRoutes(app)
+
+	// Register the calendar invites of local events
+	events.RegisterRoutes(app)
+
+	// Every email sent is recorded for /admin/emails
+	emaillog.Install(db.Connect(false))
+
+	// Periodic cleanup of expired data
+	scheduler.Start()
+
+	// gRPC interface for internal services
+	grpcserver.Start()
+
 	// Start
 	port := os.Getenv("APP_PORT")
 	if port == "" {
 		port = "3000"
 	}
 	log.Printf("Starting server on :%s", port)
-	log.Fatal(app.Listen(":" + port))
+	err := app.Listen(":" + port)
+	_ = shutdownTracing(context.Background())
+	log.Fatal(err)
 }
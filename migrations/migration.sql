@@ -74,3 +74,515 @@ CREATE TABLE IF NOT EXISTS api.subscriber_types (
     created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
 );
+
+--fuzzy / partial matching for the admin subscriber search
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE INDEX IF NOT EXISTS subscribers_email_trgm_idx ON api.subscribers USING GIN (email gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS subscribers_name_trgm_idx ON api.subscribers USING GIN (name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS subscriber_types_name_idx ON api.subscriber_types (name, subscriber_id);
+
+--passkeys (webauthn) registered for sign in, keyed by email
+CREATE TABLE IF NOT EXISTS api.webauthn_credentials (
+    id SERIAL PRIMARY KEY,
+    email VARCHAR(255) NOT NULL,
+    credential_id BYTEA NOT NULL UNIQUE,
+    data JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS webauthn_credentials_email_idx ON api.webauthn_credentials (email);
+
+--machine-to-machine api keys for /admin (only the sha256 of the key is stored)
+CREATE TABLE IF NOT EXISTS api.api_keys (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    prefix VARCHAR(16) NOT NULL,
+    key_hash CHAR(64) NOT NULL UNIQUE,
+    scopes VARCHAR(255) NOT NULL,
+    created_by VARCHAR(255),
+    expires_at TIMESTAMP WITH TIME ZONE,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+--gdpr: anonymized subscribers keep their row (and types) for aggregate stats
+ALTER TABLE api.subscribers ADD COLUMN IF NOT EXISTS anonymized_at TIMESTAMP WITH TIME ZONE;
+
+--keyset pagination over subscribers sorted by timestamp (id order uses the primary key)
+CREATE INDEX IF NOT EXISTS subscribers_created_at_id_idx ON api.subscribers (created_at, id);
+CREATE INDEX IF NOT EXISTS subscribers_updated_at_id_idx ON api.subscribers (updated_at, id);
+
+--optimistic locking: bumped on every subscriber update, stale writes are rejected
+ALTER TABLE api.subscribers ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
+
+--soft delete: rows are hard-deleted by the cleanup scheduler after the retention window
+ALTER TABLE api.subscribers ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+CREATE INDEX IF NOT EXISTS subscribers_deleted_at_idx ON api.subscribers (deleted_at);
+
+--multi-tenancy: every subscriber, api key and admin belongs to one organization
+CREATE TABLE IF NOT EXISTS api.organizations (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    slug VARCHAR(64) NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+--the default organization owns all pre-existing data
+INSERT INTO api.organizations (id, name, slug) VALUES (1, 'myLocal', 'default') ON CONFLICT (id) DO NOTHING;
+SELECT setval(pg_get_serial_sequence('api.organizations', 'id'), GREATEST((SELECT MAX(id) FROM api.organizations), 1));
+
+CREATE TABLE IF NOT EXISTS api.admin_users (
+    id SERIAL PRIMARY KEY,
+    org_id INT NOT NULL REFERENCES api.organizations(id),
+    email VARCHAR(255) NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS admin_users_org_id_idx ON api.admin_users (org_id);
+
+ALTER TABLE api.subscribers ADD COLUMN IF NOT EXISTS org_id INT NOT NULL DEFAULT 1 REFERENCES api.organizations(id);
+CREATE INDEX IF NOT EXISTS subscribers_org_id_idx ON api.subscribers (org_id);
+ALTER TABLE api.api_keys ADD COLUMN IF NOT EXISTS org_id INT NOT NULL DEFAULT 1 REFERENCES api.organizations(id);
+CREATE INDEX IF NOT EXISTS api_keys_org_id_idx ON api.api_keys (org_id);
+
+--admin roles and emailed single-use invitations (only the sha256 of the token is stored)
+ALTER TABLE api.admin_users ADD COLUMN IF NOT EXISTS role VARCHAR(32) NOT NULL DEFAULT 'admin';
+CREATE TABLE IF NOT EXISTS api.admin_invitations (
+    id SERIAL PRIMARY KEY,
+    org_id INT NOT NULL REFERENCES api.organizations(id),
+    email VARCHAR(255) NOT NULL,
+    role VARCHAR(32) NOT NULL,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    invited_by VARCHAR(255),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    accepted_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS admin_invitations_org_id_idx ON api.admin_invitations (org_id);
+
+--delivery status and double opt-in (only the sha256 of the confirmation token is stored)
+ALTER TABLE api.subscribers ADD COLUMN IF NOT EXISTS status VARCHAR(16) NOT NULL DEFAULT 'active';
+ALTER TABLE api.subscribers ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE api.subscribers ADD COLUMN IF NOT EXISTS confirm_token_hash CHAR(64) UNIQUE;
+ALTER TABLE api.subscribers ADD COLUMN IF NOT EXISTS confirm_sent_at TIMESTAMP WITH TIME ZONE;
+UPDATE api.subscribers SET status = 'unsubscribed' WHERE anonymized_at IS NOT NULL AND status <> 'unsubscribed';
+CREATE INDEX IF NOT EXISTS subscribers_org_id_status_idx ON api.subscribers (org_id, status);
+
+--email delivery events from the SendGrid event webhook, deduplicated per subscriber by sg_event_id
+CREATE TABLE IF NOT EXISTS api.delivery_events (
+    id SERIAL PRIMARY KEY,
+    subscriber_id INT NOT NULL REFERENCES api.subscribers(id) ON DELETE CASCADE,
+    event VARCHAR(32) NOT NULL,
+    reason TEXT,
+    sg_event_id VARCHAR(64) NOT NULL,
+    sg_message_id VARCHAR(255),
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+CREATE UNIQUE INDEX IF NOT EXISTS delivery_events_subscriber_event_idx ON api.delivery_events (subscriber_id, sg_event_id);
+CREATE INDEX IF NOT EXISTS subscribers_lower_email_idx ON api.subscribers (LOWER(email));
+
+--transactional outbox: side effects of subscriber writes, drained by the background worker
+CREATE TABLE IF NOT EXISTS api.outbox_events (
+    id BIGSERIAL PRIMARY KEY,
+    topic VARCHAR(64) NOT NULL,
+    org_id INT NOT NULL,
+    payload JSONB NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    available_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    processed_at TIMESTAMP WITH TIME ZONE,
+    failed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS outbox_events_pending_idx ON api.outbox_events (available_at, id) WHERE processed_at IS NULL AND failed_at IS NULL;
+
+--free-form subscriber metadata and notes kept by support staff
+ALTER TABLE api.subscribers ADD COLUMN IF NOT EXISTS metadata JSONB NOT NULL DEFAULT '{}';
+CREATE TABLE IF NOT EXISTS api.subscriber_notes (
+    id SERIAL PRIMARY KEY,
+    subscriber_id INT NOT NULL REFERENCES api.subscribers(id) ON DELETE CASCADE,
+    author VARCHAR(255) NOT NULL,
+    text TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS subscriber_notes_subscriber_id_idx ON api.subscriber_notes (subscriber_id);
+
+--per-subscriber revisions: snapshots before and after every write, for history and rollback
+CREATE TABLE IF NOT EXISTS api.subscriber_revisions (
+    id BIGSERIAL PRIMARY KEY,
+    subscriber_id INT NOT NULL REFERENCES api.subscribers(id) ON DELETE CASCADE,
+    version INT NOT NULL,
+    action VARCHAR(32) NOT NULL,
+    author VARCHAR(255),
+    before JSONB,
+    after JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS subscriber_revisions_subscriber_id_idx ON api.subscriber_revisions (subscriber_id, id);
+
+--allowed subscriber_type names move from the ENUM to a table platform admins can extend
+CREATE TABLE IF NOT EXISTS api.subscriber_type_definitions (
+    name VARCHAR(32) PRIMARY KEY,
+    description VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+INSERT INTO api.subscriber_type_definitions (name)
+    VALUES ('shopper'), ('business'), ('driver'), ('champion'), ('donor'), ('developer')
+    ON CONFLICT DO NOTHING;
+ALTER TABLE api.subscriber_types ALTER COLUMN name TYPE VARCHAR(32) USING name::text;
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'subscriber_types_name_fkey') THEN
+        ALTER TABLE api.subscriber_types ADD CONSTRAINT subscriber_types_name_fkey
+            FOREIGN KEY (name) REFERENCES api.subscriber_type_definitions(name);
+    END IF;
+END$$;
+DROP TYPE IF EXISTS api.subscriber_type;
+
+--per-type preferences: how often and on which channel a subscriber hears about each type
+ALTER TABLE api.subscriber_types ADD COLUMN IF NOT EXISTS frequency VARCHAR(16) NOT NULL DEFAULT 'weekly';
+ALTER TABLE api.subscriber_types ADD COLUMN IF NOT EXISTS channel VARCHAR(16) NOT NULL DEFAULT 'email';
+CREATE INDEX IF NOT EXISTS subscriber_types_preferences_idx ON api.subscriber_types (name, frequency, channel);
+
+--language of the emails sent to a subscriber
+ALTER TABLE api.subscribers ADD COLUMN IF NOT EXISTS locale VARCHAR(8) NOT NULL DEFAULT 'en';
+
+--browsers and apps remembered at sign-in, which skip the code until they expire (only the sha256 of the device token is stored)
+CREATE TABLE IF NOT EXISTS api.trusted_devices (
+    id SERIAL PRIMARY KEY,
+    email VARCHAR(255) NOT NULL,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    ip VARCHAR(64),
+    user_agent VARCHAR(512),
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS trusted_devices_email_idx ON api.trusted_devices (email);
+
+--list loading: subscriber_types and notes are read by subscriber, lists and lookups go by organization
+CREATE INDEX IF NOT EXISTS subscriber_types_subscriber_id_idx ON api.subscriber_types (subscriber_id);
+CREATE INDEX IF NOT EXISTS subscribers_org_id_email_idx ON api.subscribers (org_id, email);
+CREATE INDEX IF NOT EXISTS subscribers_org_id_created_at_idx ON api.subscribers (org_id, created_at, id);
+
+--sensitive admin activity emailed to the platform admins, on its own or in a digest
+CREATE TABLE IF NOT EXISTS api.admin_activities (
+    id SERIAL PRIMARY KEY,
+    org_id INT NOT NULL REFERENCES api.organizations(id),
+    kind VARCHAR(32) NOT NULL,
+    actor VARCHAR(255),
+    summary TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    notified_at TIMESTAMP WITH TIME ZONE
+);
+CREATE INDEX IF NOT EXISTS admin_activities_pending_idx ON api.admin_activities (id) WHERE notified_at IS NULL;
+
+--spam scoring of public signups: likely spam is created quarantined, with the score and the points of each check that fired
+ALTER TABLE api.subscribers ADD COLUMN IF NOT EXISTS spam_score INT NOT NULL DEFAULT 0;
+ALTER TABLE api.subscribers ADD COLUMN IF NOT EXISTS spam_signals JSONB NOT NULL DEFAULT '{}';
+
+--subscriber exports generated in the background, downloadable through a signed link until they expire
+CREATE TABLE IF NOT EXISTS api.export_jobs (
+    id SERIAL PRIMARY KEY,
+    org_id INT NOT NULL REFERENCES api.organizations(id),
+    format VARCHAR(8) NOT NULL,
+    filters JSONB NOT NULL DEFAULT '{}',
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    requested_by VARCHAR(255),
+    row_count INT NOT NULL DEFAULT 0,
+    file VARCHAR(512),
+    error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    started_at TIMESTAMP WITH TIME ZONE,
+    finished_at TIMESTAMP WITH TIME ZONE,
+    expires_at TIMESTAMP WITH TIME ZONE
+);
+CREATE INDEX IF NOT EXISTS export_jobs_org_id_idx ON api.export_jobs (org_id);
+CREATE INDEX IF NOT EXISTS export_jobs_status_idx ON api.export_jobs (status);
+
+--subscriber imports: an uploaded file the worker creates or updates subscribers from in batches, with its progress and the report of the rows that failed
+CREATE TABLE IF NOT EXISTS api.import_jobs (
+    id SERIAL PRIMARY KEY,
+    org_id INT NOT NULL REFERENCES api.organizations(id),
+    format VARCHAR(8) NOT NULL,
+    file_name VARCHAR(255),
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    requested_by VARCHAR(255),
+    total_rows INT NOT NULL DEFAULT 0,
+    processed_rows INT NOT NULL DEFAULT 0,
+    created_rows INT NOT NULL DEFAULT 0,
+    updated_rows INT NOT NULL DEFAULT 0,
+    failed_rows INT NOT NULL DEFAULT 0,
+    file VARCHAR(512),
+    error_report VARCHAR(512),
+    error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    started_at TIMESTAMP WITH TIME ZONE,
+    finished_at TIMESTAMP WITH TIME ZONE,
+    expires_at TIMESTAMP WITH TIME ZONE
+);
+CREATE INDEX IF NOT EXISTS import_jobs_org_id_idx ON api.import_jobs (org_id);
+CREATE INDEX IF NOT EXISTS import_jobs_status_idx ON api.import_jobs (status);
+
+--mailing list provider integrations (Mailchimp) and the lists kept in sync with them
+CREATE TABLE IF NOT EXISTS api.integrations (
+    id SERIAL PRIMARY KEY,
+    org_id INT NOT NULL REFERENCES api.organizations(id),
+    provider VARCHAR(32) NOT NULL,
+    api_key VARCHAR(255) NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by VARCHAR(255),
+    syncing_since TIMESTAMP WITH TIME ZONE,
+    last_sync_at TIMESTAMP WITH TIME ZONE,
+    last_error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+CREATE UNIQUE INDEX IF NOT EXISTS integrations_org_id_provider_idx ON api.integrations (org_id, provider);
+CREATE TABLE IF NOT EXISTS api.integration_lists (
+    id SERIAL PRIMARY KEY,
+    integration_id INT NOT NULL REFERENCES api.integrations(id) ON DELETE CASCADE,
+    remote_id VARCHAR(64) NOT NULL,
+    subscriber_type VARCHAR(32),
+    pushed_at TIMESTAMP WITH TIME ZONE,
+    pushed_id INT NOT NULL DEFAULT 0,
+    pulled_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS integration_lists_integration_id_idx ON api.integration_lists (integration_id);
+
+--REST hooks (Zapier) subscriptions to subscriber events
+CREATE TABLE IF NOT EXISTS api.rest_hooks (
+    id SERIAL PRIMARY KEY,
+    org_id INT NOT NULL REFERENCES api.organizations(id),
+    event VARCHAR(64) NOT NULL,
+    target_url TEXT NOT NULL,
+    created_by VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS rest_hooks_org_id_event_idx ON api.rest_hooks (org_id, event);
+
+--queued emails (sign-in codes and links) given up on, for platform admins to look into
+CREATE TABLE IF NOT EXISTS api.email_dead_letters (
+    id BIGSERIAL PRIMARY KEY,
+    outbox_event_id BIGINT NOT NULL,
+    kind VARCHAR(32) NOT NULL,
+    to_email VARCHAR(255) NOT NULL,
+    attempts INT NOT NULL,
+    last_error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+--every email sent (or failed to send), its status moved along by the SendGrid event webhook
+CREATE TABLE IF NOT EXISTS api.email_logs (
+    id BIGSERIAL PRIMARY KEY,
+    type VARCHAR(32) NOT NULL,
+    to_email VARCHAR(255) NOT NULL,
+    message_id VARCHAR(255),
+    status VARCHAR(16) NOT NULL,
+    error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS email_logs_lower_to_email_idx ON api.email_logs (LOWER(to_email));
+CREATE INDEX IF NOT EXISTS email_logs_message_id_idx ON api.email_logs (message_id);
+
+--sessions of signed-in devices, with SESSION_STORE=postgres (Redis keeps them otherwise)
+CREATE TABLE IF NOT EXISTS api.sessions (
+    id VARCHAR(64) PRIMARY KEY,
+    email VARCHAR(255) NOT NULL,
+    data TEXT NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+CREATE INDEX IF NOT EXISTS sessions_email_idx ON api.sessions (email);
+CREATE INDEX IF NOT EXISTS sessions_expires_at_idx ON api.sessions (expires_at);
+--the API signs out with the worker user
+GRANT DELETE ON api.sessions TO api_worker;
+
+--where signups, subscriber writes and admin activity came from, with GeoIP enabled (GEOIP_DB_PATH)
+ALTER TABLE api.subscribers ADD COLUMN IF NOT EXISTS country VARCHAR(2);
+ALTER TABLE api.subscribers ADD COLUMN IF NOT EXISTS region VARCHAR(8);
+ALTER TABLE api.subscriber_revisions ADD COLUMN IF NOT EXISTS country VARCHAR(2);
+ALTER TABLE api.subscriber_revisions ADD COLUMN IF NOT EXISTS region VARCHAR(8);
+ALTER TABLE api.admin_activities ADD COLUMN IF NOT EXISTS country VARCHAR(2);
+ALTER TABLE api.admin_activities ADD COLUMN IF NOT EXISTS region VARCHAR(8);
+CREATE INDEX IF NOT EXISTS subscribers_org_id_country_idx ON api.subscribers (org_id, country);
+
+--channel public signups came from: the utm_* parameters of the link and the host of the referring page
+ALTER TABLE api.subscribers ADD COLUMN IF NOT EXISTS utm_source VARCHAR(100);
+ALTER TABLE api.subscribers ADD COLUMN IF NOT EXISTS utm_medium VARCHAR(100);
+ALTER TABLE api.subscribers ADD COLUMN IF NOT EXISTS utm_campaign VARCHAR(100);
+ALTER TABLE api.subscribers ADD COLUMN IF NOT EXISTS referrer VARCHAR(255);
+
+--public signup forms, each with its own subscriber_types and custom fields
+CREATE TABLE IF NOT EXISTS api.signup_forms (
+    id SERIAL PRIMARY KEY,
+    org_id INT NOT NULL REFERENCES api.organizations(id),
+    slug VARCHAR(64) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    subscriber_types TEXT NOT NULL DEFAULT '',
+    fields JSONB NOT NULL DEFAULT '[]',
+    success_redirect TEXT,
+    captcha BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+CREATE UNIQUE INDEX IF NOT EXISTS signup_forms_org_id_slug_idx ON api.signup_forms (org_id, slug);
+
+--referrals: the code each subscriber shares, and the subscriber whose code a signup came with
+ALTER TABLE api.subscribers ADD COLUMN IF NOT EXISTS referral_code VARCHAR(16);
+ALTER TABLE api.subscribers ADD COLUMN IF NOT EXISTS referred_by_id INT REFERENCES api.subscribers(id) ON DELETE SET NULL;
+UPDATE api.subscribers SET referral_code = substr(md5(random()::text || id::text), 1, 10) WHERE referral_code IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS subscribers_referral_code_idx ON api.subscribers (referral_code);
+CREATE INDEX IF NOT EXISTS subscribers_referred_by_id_idx ON api.subscribers (referred_by_id);
+
+--segments: saved subscriber filters campaigns and exports are targeted at
+CREATE TABLE IF NOT EXISTS api.segments (
+    id SERIAL PRIMARY KEY,
+    org_id INT NOT NULL REFERENCES api.organizations(id),
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    filter JSONB NOT NULL DEFAULT '{}',
+    created_by VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+CREATE UNIQUE INDEX IF NOT EXISTS segments_org_id_name_idx ON api.segments (org_id, name);
+ALTER TABLE api.export_jobs ADD COLUMN IF NOT EXISTS segment_id INT REFERENCES api.segments(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS export_jobs_segment_id_idx ON api.export_jobs (segment_id);
+
+--campaigns: emails sent to a segment or filter, once or on a recurrence, and who each run went to
+CREATE TABLE IF NOT EXISTS api.campaigns (
+    id SERIAL PRIMARY KEY,
+    org_id INT NOT NULL REFERENCES api.organizations(id),
+    name VARCHAR(255) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    text TEXT,
+    html TEXT,
+    segment_id INT REFERENCES api.segments(id) ON DELETE SET NULL,
+    filter JSONB NOT NULL DEFAULT '{}',
+    status VARCHAR(16) NOT NULL DEFAULT 'draft',
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    recurrence VARCHAR(128),
+    next_run_at TIMESTAMP WITH TIME ZONE,
+    run INT NOT NULL DEFAULT 0,
+    last_run_at TIMESTAMP WITH TIME ZONE,
+    sent_count INT NOT NULL DEFAULT 0,
+    error TEXT,
+    created_by VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS campaigns_org_id_idx ON api.campaigns (org_id);
+CREATE INDEX IF NOT EXISTS campaigns_segment_id_idx ON api.campaigns (segment_id);
+CREATE INDEX IF NOT EXISTS campaigns_status_idx ON api.campaigns (status);
+CREATE INDEX IF NOT EXISTS campaigns_next_run_at_idx ON api.campaigns (next_run_at);
+CREATE TABLE IF NOT EXISTS api.campaign_sends (
+    id SERIAL PRIMARY KEY,
+    campaign_id INT NOT NULL REFERENCES api.campaigns(id) ON DELETE CASCADE,
+    run INT NOT NULL,
+    subscriber_id INT NOT NULL REFERENCES api.subscribers(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+CREATE UNIQUE INDEX IF NOT EXISTS campaign_sends_campaign_id_run_subscriber_id_idx ON api.campaign_sends (campaign_id, run, subscriber_id);
+CREATE INDEX IF NOT EXISTS campaign_sends_subscriber_id_idx ON api.campaign_sends (subscriber_id);
+
+--campaigns: A/B tests of two subjects on a share of the audience, the winner going to the rest; opens of each recipient
+ALTER TABLE api.campaigns ADD COLUMN IF NOT EXISTS subject_b VARCHAR(255);
+ALTER TABLE api.campaigns ADD COLUMN IF NOT EXISTS test_percent INT NOT NULL DEFAULT 0;
+ALTER TABLE api.campaigns ADD COLUMN IF NOT EXISTS test_hours INT NOT NULL DEFAULT 0;
+ALTER TABLE api.campaigns ADD COLUMN IF NOT EXISTS test_ends_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE api.campaigns ADD COLUMN IF NOT EXISTS winner VARCHAR(1);
+ALTER TABLE api.campaign_sends ADD COLUMN IF NOT EXISTS variant VARCHAR(1) NOT NULL DEFAULT 'a';
+ALTER TABLE api.campaign_sends ADD COLUMN IF NOT EXISTS test BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE api.campaign_sends ADD COLUMN IF NOT EXISTS opened_at TIMESTAMP WITH TIME ZONE;
+
+--campaigns: clicks through tracked links, and every open and click of each recipient
+ALTER TABLE api.campaign_sends ADD COLUMN IF NOT EXISTS sent_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE api.campaign_sends ADD COLUMN IF NOT EXISTS clicked_at TIMESTAMP WITH TIME ZONE;
+CREATE TABLE IF NOT EXISTS api.campaign_links (
+    id SERIAL PRIMARY KEY,
+    campaign_id INT NOT NULL REFERENCES api.campaigns(id) ON DELETE CASCADE,
+    url VARCHAR(2048) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+CREATE UNIQUE INDEX IF NOT EXISTS campaign_links_campaign_id_url_idx ON api.campaign_links (campaign_id, url);
+CREATE TABLE IF NOT EXISTS api.campaign_events (
+    id SERIAL PRIMARY KEY,
+    campaign_id INT NOT NULL REFERENCES api.campaigns(id) ON DELETE CASCADE,
+    run INT NOT NULL,
+    send_id INT NOT NULL REFERENCES api.campaign_sends(id) ON DELETE CASCADE,
+    type VARCHAR(8) NOT NULL,
+    link_id INT REFERENCES api.campaign_links(id) ON DELETE SET NULL,
+    user_agent VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS campaign_events_campaign_id_run_idx ON api.campaign_events (campaign_id, run);
+CREATE INDEX IF NOT EXISTS campaign_events_send_id_idx ON api.campaign_events (send_id);
+
+--short links: /l/{code} redirects, their clicks counted and attributed to a campaign
+CREATE TABLE IF NOT EXISTS api.short_links (
+    id SERIAL PRIMARY KEY,
+    org_id INT NOT NULL REFERENCES api.organizations(id),
+    code VARCHAR(32) NOT NULL,
+    url VARCHAR(2048) NOT NULL,
+    campaign_id INT REFERENCES api.campaigns(id) ON DELETE SET NULL,
+    clicks INT NOT NULL DEFAULT 0,
+    last_clicked_at TIMESTAMP WITH TIME ZONE,
+    created_by VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+CREATE UNIQUE INDEX IF NOT EXISTS short_links_code_idx ON api.short_links (code);
+CREATE INDEX IF NOT EXISTS short_links_org_id_idx ON api.short_links (org_id);
+CREATE INDEX IF NOT EXISTS short_links_campaign_id_idx ON api.short_links (campaign_id);
+
+--email templates: subject, text and HTML filled with variables, previewed and test-sent
+CREATE TABLE IF NOT EXISTS api.email_templates (
+    id SERIAL PRIMARY KEY,
+    org_id INT NOT NULL REFERENCES api.organizations(id),
+    name VARCHAR(255) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    text TEXT NOT NULL DEFAULT '',
+    html TEXT NOT NULL DEFAULT '',
+    created_by VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS email_templates_org_id_idx ON api.email_templates (org_id);
+
+--email templates: HTML authored in Markdown or MJML, compiled from its source
+ALTER TABLE api.email_templates ADD COLUMN IF NOT EXISTS format VARCHAR(16) NOT NULL DEFAULT 'html';
+ALTER TABLE api.email_templates ADD COLUMN IF NOT EXISTS source TEXT NOT NULL DEFAULT '';
+
+--email log: the files sent with each email, by name, type, size and checksum
+ALTER TABLE api.email_logs ADD COLUMN IF NOT EXISTS attachments JSONB NOT NULL DEFAULT '[]';
+
+--local events, served as calendar invites and attached to the campaigns announcing them
+CREATE TABLE IF NOT EXISTS api.events (
+    id SERIAL PRIMARY KEY,
+    org_id INT NOT NULL REFERENCES api.organizations(id),
+    title VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    location VARCHAR(255) NOT NULL DEFAULT '',
+    url VARCHAR(2048) NOT NULL DEFAULT '',
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ends_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    sequence INT NOT NULL DEFAULT 0,
+    created_by VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS events_org_id_idx ON api.events (org_id);
+CREATE INDEX IF NOT EXISTS events_starts_at_idx ON api.events (starts_at);
+ALTER TABLE api.campaigns ADD COLUMN IF NOT EXISTS event_id INT REFERENCES api.events(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS campaigns_event_id_idx ON api.campaigns (event_id);